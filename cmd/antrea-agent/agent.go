@@ -39,12 +39,14 @@ import (
 	"antrea.io/antrea/pkg/agent/controller/ipseccertificate"
 	"antrea.io/antrea/pkg/agent/controller/networkpolicy"
 	"antrea.io/antrea/pkg/agent/controller/noderoute"
+	"antrea.io/antrea/pkg/agent/controller/packetcapture"
 	"antrea.io/antrea/pkg/agent/controller/serviceexternalip"
 	"antrea.io/antrea/pkg/agent/controller/traceflow"
 	"antrea.io/antrea/pkg/agent/controller/trafficcontrol"
 	"antrea.io/antrea/pkg/agent/externalnode"
 	"antrea.io/antrea/pkg/agent/flowexporter"
 	"antrea.io/antrea/pkg/agent/flowexporter/exporter"
+	"antrea.io/antrea/pkg/agent/flowexporter/l7"
 	"antrea.io/antrea/pkg/agent/interfacestore"
 	"antrea.io/antrea/pkg/agent/memberlist"
 	"antrea.io/antrea/pkg/agent/metrics"
@@ -107,6 +109,7 @@ func run(o *Options) error {
 	egressInformer := crdInformerFactory.Crd().V1alpha2().Egresses()
 	externalIPPoolInformer := crdInformerFactory.Crd().V1alpha2().ExternalIPPools()
 	trafficControlInformer := crdInformerFactory.Crd().V1alpha2().TrafficControls()
+	packetCaptureInformer := crdInformerFactory.Crd().V1alpha1().PacketCaptures()
 	nodeInformer := informerFactory.Core().V1().Nodes()
 	serviceInformer := informerFactory.Core().V1().Services()
 	endpointsInformer := informerFactory.Core().V1().Endpoints()
@@ -147,7 +150,8 @@ func run(o *Options) error {
 		o.config.AntreaProxy.ProxyAll,
 		connectUplinkToBridge,
 		multicastEnabled,
-		features.DefaultFeatureGate.Enabled(features.TrafficControl),
+		// PacketCapture mirrors the captured packets with the TrafficControl pipeline.
+		features.DefaultFeatureGate.Enabled(features.TrafficControl) || features.DefaultFeatureGate.Enabled(features.PacketCapture),
 		features.DefaultFeatureGate.Enabled(features.Multicluster),
 	)
 
@@ -220,10 +224,11 @@ func run(o *Options) error {
 		}
 	}
 	serviceConfig := &config.ServiceConfig{
-		ServiceCIDR:           serviceCIDRNet,
-		ServiceCIDRv6:         serviceCIDRNetv6,
-		NodePortAddressesIPv4: nodePortAddressesIPv4,
-		NodePortAddressesIPv6: nodePortAddressesIPv6,
+		ServiceCIDR:               serviceCIDRNet,
+		ServiceCIDRv6:             serviceCIDRNetv6,
+		NodePortAddressesIPv4:     nodePortAddressesIPv4,
+		NodePortAddressesIPv6:     nodePortAddressesIPv6,
+		EnableLoadBalancerModeDSR: features.DefaultFeatureGate.Enabled(features.LoadBalancerModeDSR),
 	}
 
 	// Initialize agent and node network.
@@ -314,16 +319,17 @@ func run(o *Options) error {
 		proxyAll := o.config.AntreaProxy.ProxyAll
 		skipServices := o.config.AntreaProxy.SkipServices
 		proxyLoadBalancerIPs := *o.config.AntreaProxy.ProxyLoadBalancerIPs
+		loadBalancerAlgorithm := proxytypes.LoadBalancerAlgorithm(o.config.AntreaProxy.LoadBalancerAlgorithm)
 
 		switch {
 		case v4Enabled && v6Enabled:
-			proxier = proxy.NewDualStackProxier(nodeConfig.Name, informerFactory, ofClient, routeClient, nodePortAddressesIPv4, nodePortAddressesIPv6, proxyAll, skipServices, proxyLoadBalancerIPs, v4GroupCounter, v6GroupCounter)
+			proxier = proxy.NewDualStackProxier(nodeConfig.Name, informerFactory, ofClient, routeClient, nodePortAddressesIPv4, nodePortAddressesIPv6, proxyAll, skipServices, proxyLoadBalancerIPs, loadBalancerAlgorithm, v4GroupCounter, v6GroupCounter)
 			groupCounters = append(groupCounters, v4GroupCounter, v6GroupCounter)
 		case v4Enabled:
-			proxier = proxy.NewProxier(nodeConfig.Name, informerFactory, ofClient, false, routeClient, nodePortAddressesIPv4, proxyAll, skipServices, proxyLoadBalancerIPs, v4GroupCounter)
+			proxier = proxy.NewProxier(nodeConfig.Name, informerFactory, ofClient, false, routeClient, nodePortAddressesIPv4, proxyAll, skipServices, proxyLoadBalancerIPs, loadBalancerAlgorithm, v4GroupCounter)
 			groupCounters = append(groupCounters, v4GroupCounter)
 		case v6Enabled:
-			proxier = proxy.NewProxier(nodeConfig.Name, informerFactory, ofClient, true, routeClient, nodePortAddressesIPv6, proxyAll, skipServices, proxyLoadBalancerIPs, v6GroupCounter)
+			proxier = proxy.NewProxier(nodeConfig.Name, informerFactory, ofClient, true, routeClient, nodePortAddressesIPv6, proxyAll, skipServices, proxyLoadBalancerIPs, loadBalancerAlgorithm, v6GroupCounter)
 			groupCounters = append(groupCounters, v6GroupCounter)
 		default:
 			return fmt.Errorf("at least one of IPv4 or IPv6 should be enabled")
@@ -375,6 +381,13 @@ func run(o *Options) error {
 		statusManagerEnabled,
 		multicastEnabled,
 		loggingEnabled,
+		&networkpolicy.AuditLoggingOptions{
+			Sink:             networkpolicy.AuditLogSink(o.config.AuditLogging.Sink),
+			Format:           networkpolicy.AuditLogFormat(o.config.AuditLogging.Format),
+			SyslogAddress:    o.config.AuditLogging.Syslog.Address,
+			SyslogProtocol:   o.config.AuditLogging.Syslog.Protocol,
+			SyslogCACertFile: o.config.AuditLogging.Syslog.CACertFile,
+		},
 		asyncRuleDeleteInterval,
 		o.dnsServerOverride,
 		o.nodeType,
@@ -521,7 +534,11 @@ func run(o *Options) error {
 	}
 
 	var flowExporter *exporter.FlowExporter
+	var l7EventStore *l7.EventStore
 	if features.DefaultFeatureGate.Enabled(features.FlowExporter) {
+		if features.DefaultFeatureGate.Enabled(features.L7FlowExporter) {
+			l7EventStore = l7.NewEventStore(o.staleConnectionTimeout)
+		}
 		flowExporterOptions := &flowexporter.FlowExporterOptions{
 			FlowCollectorAddr:      o.flowCollectorAddr,
 			FlowCollectorProto:     o.flowCollectorProto,
@@ -529,7 +546,9 @@ func run(o *Options) error {
 			IdleFlowTimeout:        o.idleFlowTimeout,
 			StaleConnectionTimeout: o.staleConnectionTimeout,
 			PollInterval:           o.pollInterval,
-			ConnectUplinkToBridge:  connectUplinkToBridge}
+			ConnectUplinkToBridge:  connectUplinkToBridge,
+			SpoolDir:               defaultFlowExportSpoolDir,
+			SpoolSize:              o.flowExportSpoolSize}
 		flowExporter, err = exporter.NewFlowExporter(
 			ifaceStore,
 			proxier,
@@ -544,20 +563,25 @@ func run(o *Options) error {
 			ovsDatapathType,
 			features.DefaultFeatureGate.Enabled(features.AntreaProxy),
 			networkPolicyController,
+			l7EventStore,
 			flowExporterOptions)
 		if err != nil {
 			return fmt.Errorf("error when creating IPFIX flow exporter: %v", err)
 		}
 		networkPolicyController.SetDenyConnStore(flowExporter.GetDenyConnStore())
+		if l7EventStore != nil {
+			networkPolicyController.SetL7EventStore(l7EventStore)
+		}
 	}
 
 	enableNodePortLocal := features.DefaultFeatureGate.Enabled(features.NodePortLocal) && o.config.NodePortLocal.Enable
 
-	// Initialize localPodInformer for NPLAgent, AntreaIPAMController, and secondary network controller.
+	// Initialize localPodInformer for NPLAgent, AntreaIPAMController, secondary network controller, and L7 flow export.
 	var localPodInformer cache.SharedIndexInformer
 	if enableNodePortLocal || enableBridgingMode ||
 		features.DefaultFeatureGate.Enabled(features.SecondaryNetwork) ||
-		features.DefaultFeatureGate.Enabled(features.TrafficControl) {
+		features.DefaultFeatureGate.Enabled(features.TrafficControl) ||
+		l7EventStore != nil {
 		listOptions := func(options *metav1.ListOptions) {
 			options.FieldSelector = fields.OneTermEqualSelector("spec.nodeName", nodeConfig.Name).String()
 		}
@@ -652,6 +676,25 @@ func run(o *Options) error {
 		go tcController.Run(stopCh)
 	}
 
+	if l7EventStore != nil {
+		l7FlowExporterController := l7.NewL7FlowExporterController(l7EventStore,
+			ifaceStore,
+			localPodInformer,
+			podUpdateChannel)
+		go l7FlowExporterController.Run(stopCh)
+	}
+
+	if features.DefaultFeatureGate.Enabled(features.PacketCapture) {
+		pcController := packetcapture.NewPacketCaptureController(k8sClient,
+			crdClient,
+			packetCaptureInformer,
+			ofClient,
+			ovsBridgeClient,
+			ifaceStore,
+			nodeConfig)
+		go pcController.Run(stopCh)
+	}
+
 	//  Start the localPodInformer
 	if localPodInformer != nil {
 		go localPodInformer.Run(stopCh)
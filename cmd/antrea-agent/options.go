@@ -28,6 +28,8 @@ import (
 	"k8s.io/klog/v2"
 
 	"antrea.io/antrea/pkg/agent/config"
+	"antrea.io/antrea/pkg/agent/controller/networkpolicy"
+	proxytypes "antrea.io/antrea/pkg/agent/proxy/types"
 	"antrea.io/antrea/pkg/apis"
 	"antrea.io/antrea/pkg/cni"
 	agentconfig "antrea.io/antrea/pkg/config/agent"
@@ -50,6 +52,8 @@ const (
 	defaultFlowPollInterval        = 5 * time.Second
 	defaultActiveFlowExportTimeout = 30 * time.Second
 	defaultIdleFlowExportTimeout   = 15 * time.Second
+	defaultFlowExportSpoolSize     = 64
+	defaultFlowExportSpoolDir      = "/var/lib/antrea/flow-exporter-spool"
 	defaultIGMPQueryInterval       = 125 * time.Second
 	defaultStaleConnectionTimeout  = 5 * time.Minute
 	defaultNPLPortRange            = "61000-62000"
@@ -71,6 +75,8 @@ type Options struct {
 	activeFlowTimeout time.Duration
 	// Idle flow timeout to export records of inactive flows
 	idleFlowTimeout time.Duration
+	// Maximum size in bytes of the on-disk spool of flow records
+	flowExportSpoolSize int64
 	// Stale connection timeout to delete connections if they are not exported.
 	staleConnectionTimeout time.Duration
 	igmpQueryInterval      time.Duration
@@ -121,6 +127,10 @@ func (o *Options) validate(args []string) error {
 		return fmt.Errorf("OVS datapath type %s is not supported", o.config.OVSDatapathType)
 	}
 
+	if err := o.validateAuditLoggingConfig(); err != nil {
+		return fmt.Errorf("auditLogging configuration is invalid: %w", err)
+	}
+
 	if config.ExternalNode.String() == o.config.NodeType && !features.DefaultFeatureGate.Enabled(features.ExternalNode) {
 		return fmt.Errorf("nodeType %s requires feature gate ExternalNode to be enabled", o.config.NodeType)
 	}
@@ -161,6 +171,15 @@ func (o *Options) setDefaults() {
 	if o.config.NodeType == "" {
 		o.config.NodeType = defaultNodeType.String()
 	}
+	if o.config.AuditLogging.Sink == "" {
+		o.config.AuditLogging.Sink = string(networkpolicy.AuditLogSinkFile)
+	}
+	if o.config.AuditLogging.Format == "" {
+		o.config.AuditLogging.Format = string(networkpolicy.AuditLogFormatText)
+	}
+	if o.config.AuditLogging.Sink == string(networkpolicy.AuditLogSinkSyslog) && o.config.AuditLogging.Syslog.Protocol == "" {
+		o.config.AuditLogging.Syslog.Protocol = "udp"
+	}
 	if o.config.NodeType == config.K8sNode.String() {
 		o.setK8sNodeDefaultOptions()
 	} else {
@@ -168,7 +187,7 @@ func (o *Options) setDefaults() {
 	}
 }
 
-func (o *Options) validateAntreaProxyConfig() error {
+func (o *Options) validateAntreaProxyConfig(encapMode config.TrafficEncapModeType) error {
 	if !features.DefaultFeatureGate.Enabled(features.AntreaProxy) {
 		// Validate service CIDR configuration if AntreaProxy is not enabled.
 		if _, _, err := net.ParseCIDR(o.config.ServiceCIDR); err != nil {
@@ -191,6 +210,21 @@ func (o *Options) validateAntreaProxyConfig() error {
 			}
 		}
 	}
+	if features.DefaultFeatureGate.Enabled(features.AntreaProxy) {
+		switch proxytypes.LoadBalancerAlgorithm(o.config.AntreaProxy.LoadBalancerAlgorithm) {
+		case proxytypes.LoadBalancerAlgorithmHash, proxytypes.LoadBalancerAlgorithmMaglev:
+		default:
+			return fmt.Errorf("unsupported loadBalancerAlgorithm %s", o.config.AntreaProxy.LoadBalancerAlgorithm)
+		}
+	}
+	if features.DefaultFeatureGate.Enabled(features.LoadBalancerModeDSR) {
+		if !features.DefaultFeatureGate.Enabled(features.AntreaProxy) || !o.config.AntreaProxy.ProxyAll {
+			return fmt.Errorf("%s requires AntreaProxy to be enabled with proxyAll", features.LoadBalancerModeDSR)
+		}
+		if encapMode != config.TrafficEncapModeEncap {
+			return fmt.Errorf("%s is only applicable to the %s mode", features.LoadBalancerModeDSR, config.TrafficEncapModeEncap)
+		}
+	}
 	return nil
 }
 
@@ -242,6 +276,37 @@ func (o *Options) validateFlowExporterConfig() error {
 		} else {
 			o.staleConnectionTimeout = defaultStaleConnectionTimeout
 		}
+		if *o.config.FlowExportSpoolSize < 0 {
+			return fmt.Errorf("FlowExportSpoolSize must be greater than or equal to 0")
+		}
+		o.flowExportSpoolSize = int64(*o.config.FlowExportSpoolSize) << 20
+	} else if features.DefaultFeatureGate.Enabled(features.L7FlowExporter) {
+		return fmt.Errorf("%s requires %s to be enabled", features.L7FlowExporter, features.FlowExporter)
+	}
+	return nil
+}
+
+func (o *Options) validateAuditLoggingConfig() error {
+	switch networkpolicy.AuditLogFormat(o.config.AuditLogging.Format) {
+	case networkpolicy.AuditLogFormatText, networkpolicy.AuditLogFormatJSON:
+	default:
+		return fmt.Errorf("unsupported format %s", o.config.AuditLogging.Format)
+	}
+	switch networkpolicy.AuditLogSink(o.config.AuditLogging.Sink) {
+	case networkpolicy.AuditLogSinkFile, networkpolicy.AuditLogSinkStdout:
+	case networkpolicy.AuditLogSinkSyslog:
+		syslogConfig := o.config.AuditLogging.Syslog
+		if syslogConfig.Protocol != "udp" && syslogConfig.Protocol != "tcp" && syslogConfig.Protocol != "tls" {
+			return fmt.Errorf("unsupported syslog protocol %s", syslogConfig.Protocol)
+		}
+		if _, _, err := net.SplitHostPort(syslogConfig.Address); err != nil {
+			return fmt.Errorf("syslog address %s is invalid: %v", syslogConfig.Address, err)
+		}
+		if syslogConfig.CACertFile != "" && syslogConfig.Protocol != "tls" {
+			return fmt.Errorf("syslog caCertFile can only be set when protocol is tls")
+		}
+	default:
+		return fmt.Errorf("unsupported sink %s", o.config.AuditLogging.Sink)
 	}
 	return nil
 }
@@ -306,6 +371,9 @@ func (o *Options) setK8sNodeDefaultOptions() {
 			o.config.AntreaProxy.ProxyLoadBalancerIPs = new(bool)
 			*o.config.AntreaProxy.ProxyLoadBalancerIPs = true
 		}
+		if o.config.AntreaProxy.LoadBalancerAlgorithm == "" {
+			o.config.AntreaProxy.LoadBalancerAlgorithm = string(proxytypes.LoadBalancerAlgorithmHash)
+		}
 	} else {
 		if o.config.ServiceCIDR == "" {
 			o.config.ServiceCIDR = defaultServiceCIDR
@@ -339,6 +407,10 @@ func (o *Options) setK8sNodeDefaultOptions() {
 		if o.config.IdleFlowExportTimeout == "" {
 			o.idleFlowTimeout = defaultIdleFlowExportTimeout
 		}
+		if o.config.FlowExportSpoolSize == nil {
+			o.config.FlowExportSpoolSize = new(int)
+			*o.config.FlowExportSpoolSize = defaultFlowExportSpoolSize
+		}
 	}
 
 	if features.DefaultFeatureGate.Enabled(features.NodePortLocal) {
@@ -412,7 +484,7 @@ func (o *Options) validateK8sNodeOptions() error {
 		// (but SNAT can be done by the primary CNI).
 		o.config.NoSNAT = true
 	}
-	if err := o.validateAntreaProxyConfig(); err != nil {
+	if err := o.validateAntreaProxyConfig(encapMode); err != nil {
 		return fmt.Errorf("proxy config is invalid: %w", err)
 	}
 	if err := o.validateFlowExporterConfig(); err != nil {
@@ -45,6 +45,7 @@ import (
 	crdinformers "antrea.io/antrea/pkg/client/informers/externalversions"
 	"antrea.io/antrea/pkg/clusteridentity"
 	"antrea.io/antrea/pkg/controller/certificatesigningrequest"
+	"antrea.io/antrea/pkg/controller/connectivityprobe"
 	"antrea.io/antrea/pkg/controller/egress"
 	egressstore "antrea.io/antrea/pkg/controller/egress/store"
 	"antrea.io/antrea/pkg/controller/externalippool"
@@ -54,6 +55,7 @@ import (
 	"antrea.io/antrea/pkg/controller/metrics"
 	"antrea.io/antrea/pkg/controller/networkpolicy"
 	"antrea.io/antrea/pkg/controller/networkpolicy/store"
+	"antrea.io/antrea/pkg/controller/packetcapture"
 	"antrea.io/antrea/pkg/controller/querier"
 	"antrea.io/antrea/pkg/controller/serviceexternalip"
 	"antrea.io/antrea/pkg/controller/stats"
@@ -129,6 +131,8 @@ func run(o *Options) error {
 	anpInformer := crdInformerFactory.Crd().V1alpha1().NetworkPolicies()
 	tierInformer := crdInformerFactory.Crd().V1alpha1().Tiers()
 	tfInformer := crdInformerFactory.Crd().V1alpha1().Traceflows()
+	pcInformer := crdInformerFactory.Crd().V1alpha1().PacketCaptures()
+	cpInformer := crdInformerFactory.Crd().V1alpha1().ConnectivityProbes()
 	cgInformer := crdInformerFactory.Crd().V1alpha3().ClusterGroups()
 	grpInformer := crdInformerFactory.Crd().V1alpha3().Groups()
 	egressInformer := crdInformerFactory.Crd().V1alpha2().Egresses()
@@ -219,7 +223,23 @@ func run(o *Options) error {
 
 	var traceflowController *traceflow.Controller
 	if features.DefaultFeatureGate.Enabled(features.Traceflow) {
-		traceflowController = traceflow.NewTraceflowController(crdClient, podInformer, tfInformer)
+		traceflowController = traceflow.NewTraceflowController(crdClient, podInformer, nodeInformer, tfInformer)
+	}
+
+	var packetCaptureController *packetcapture.Controller
+	if features.DefaultFeatureGate.Enabled(features.PacketCapture) {
+		packetCaptureController = packetcapture.NewPacketCaptureController(crdClient, podInformer, pcInformer)
+	}
+
+	// ConnectivityProbes are run with Traceflows, and require the Traceflow
+	// feature to be enabled.
+	enableConnectivityProbe := features.DefaultFeatureGate.Enabled(features.ConnectivityProbe) && features.DefaultFeatureGate.Enabled(features.Traceflow)
+	if features.DefaultFeatureGate.Enabled(features.ConnectivityProbe) && !enableConnectivityProbe {
+		klog.Warning("ConnectivityProbe is disabled because it requires Traceflow to be enabled")
+	}
+	var connectivityProbeController *connectivityprobe.Controller
+	if enableConnectivityProbe {
+		connectivityProbeController = connectivityprobe.NewConnectivityProbeController(crdClient, cpInformer, tfInformer)
 	}
 
 	// statsAggregator takes stats summaries from antrea-agents, aggregates them, and serves the Stats APIs with the
@@ -315,6 +335,14 @@ func run(o *Options) error {
 		go traceflowController.Run(stopCh)
 	}
 
+	if features.DefaultFeatureGate.Enabled(features.PacketCapture) {
+		go packetCaptureController.Run(stopCh)
+	}
+
+	if enableConnectivityProbe {
+		go connectivityProbeController.Run(stopCh)
+	}
+
 	if features.DefaultFeatureGate.Enabled(features.AntreaPolicy) {
 		go networkPolicyStatusController.Run(stopCh)
 	}
@@ -24,6 +24,8 @@ import (
 	"k8s.io/client-go/rest"
 	"k8s.io/klog/v2"
 
+	crdclientset "antrea.io/antrea/pkg/client/clientset/versioned"
+	crdinformers "antrea.io/antrea/pkg/client/informers/externalversions"
 	aggregator "antrea.io/antrea/pkg/flowaggregator"
 	"antrea.io/antrea/pkg/flowaggregator/apiserver"
 	"antrea.io/antrea/pkg/log"
@@ -42,17 +44,24 @@ func run(configFile string) error {
 
 	log.StartLogFileNumberMonitor(stopCh)
 
-	k8sClient, err := createK8sClient()
+	k8sClient, crdClient, err := createK8sClients()
 	if err != nil {
-		return fmt.Errorf("error when creating K8s client: %v", err)
+		return fmt.Errorf("error when creating K8s clients: %v", err)
 	}
 
 	informerFactory := informers.NewSharedInformerFactory(k8sClient, informerDefaultResync)
 	podInformer := informerFactory.Core().V1().Pods()
+	namespaceInformer := informerFactory.Core().V1().Namespaces()
+	crdInformerFactory := crdinformers.NewSharedInformerFactory(crdClient, informerDefaultResync)
+	externalEntityInformer := crdInformerFactory.Crd().V1alpha2().ExternalEntities()
+	egressInformer := crdInformerFactory.Crd().V1alpha2().Egresses()
 
 	flowAggregator, err := aggregator.NewFlowAggregator(
 		k8sClient,
 		podInformer,
+		namespaceInformer,
+		externalEntityInformer,
+		egressInformer,
 		configFile,
 	)
 
@@ -81,6 +90,7 @@ func run(configFile string) error {
 	go apiServer.Run(stopCh)
 
 	informerFactory.Start(stopCh)
+	crdInformerFactory.Start(stopCh)
 
 	<-stopCh
 	klog.InfoS("Stopping flow aggregator")
@@ -88,14 +98,18 @@ func run(configFile string) error {
 	return nil
 }
 
-func createK8sClient() (kubernetes.Interface, error) {
+func createK8sClients() (kubernetes.Interface, crdclientset.Interface, error) {
 	config, err := rest.InClusterConfig()
 	if err != nil {
-		return nil, err
+		return nil, nil, err
 	}
 	k8sClient, err := kubernetes.NewForConfig(config)
 	if err != nil {
-		return nil, err
+		return nil, nil, err
 	}
-	return k8sClient, nil
+	crdClient, err := crdclientset.NewForConfig(config)
+	if err != nil {
+		return nil, nil, err
+	}
+	return k8sClient, crdClient, nil
 }
@@ -113,6 +113,10 @@ fi
 # Generate protobuf code for CNI gRPC service with protoc.
 protoc --go_out=plugins=grpc:. pkg/apis/cni/v1beta1/cni.proto
 
+# Generate protobuf code for the flow records produced to Kafka by the Flow
+# Aggregator.
+protoc --go_out=. pkg/flowaggregator/kafkaproducer/protobuf/flowrecord.proto
+
 # Generate clientset and apis code with K8s codegen tools.
 $GOPATH/bin/client-gen \
   --clientset-name versioned \
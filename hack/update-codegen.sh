@@ -18,7 +18,7 @@ set -o errexit
 set -o pipefail
 
 ANTREA_ROOT="$( cd "$( dirname "${BASH_SOURCE[0]}" )/../" && pwd )"
-IMAGE_NAME="antrea/codegen:kubernetes-1.24.0-build.1"
+IMAGE_NAME="antrea/codegen:kubernetes-1.24.0-build.2"
 
 function docker_run() {
   docker pull ${IMAGE_NAME}
@@ -24,7 +24,7 @@ import (
 
 	v1alpha1 "antrea.io/antrea/multicluster/apis/multicluster/v1alpha1"
 	common "antrea.io/antrea/multicluster/controllers/multicluster/common"
-	gomock "github.com/golang/mock/gomock"
+	gomock "go.uber.org/mock/gomock"
 	meta "k8s.io/apimachinery/pkg/api/meta"
 	runtime "k8s.io/apimachinery/pkg/runtime"
 	client "sigs.k8s.io/controller-runtime/pkg/client"
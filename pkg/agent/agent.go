@@ -305,6 +305,10 @@ func (i *Initializer) initInterfaceStore() error {
 				intf = cniserver.ParseOVSPortInterfaceConfig(port, ovsPort, true)
 			case interfacestore.AntreaTrafficControl:
 				intf = trafficcontrol.ParseTrafficControlInterfaceConfig(port, ovsPort)
+			case interfacestore.AntreaPacketCapture:
+				// The capture ports are not stored in the interfaceStore. The
+				// stale ones are deleted by the PacketCapture controller.
+				intf = nil
 			default:
 				klog.InfoS("Unknown Antrea interface type", "type", interfaceType)
 			}
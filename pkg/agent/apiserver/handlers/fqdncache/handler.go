@@ -0,0 +1,135 @@
+// Copyright 2022 Antrea Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package fqdncache
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"time"
+
+	"k8s.io/utils/clock"
+
+	"antrea.io/antrea/pkg/antctl/transform/common"
+	"antrea.io/antrea/pkg/features"
+	"antrea.io/antrea/pkg/querier"
+)
+
+// Response describes the response struct of fqdncache command. Each Response is an IP address
+// cached for a FQDN matched by a FQDN selector. A FQDN selector without any matched FQDN, or a
+// FQDN without any cached IP address, is described by a Response with the empty fields omitted.
+type Response struct {
+	Selector       string     `json:"selector,omitempty"`
+	FQDN           string     `json:"fqdn,omitempty"`
+	IPAddress      string     `json:"ipAddress,omitempty"`
+	ExpirationTime *time.Time `json:"expirationTime,omitempty"`
+	// RemainingTTL is the number of seconds before the IP address expires.
+	RemainingTTL *int64 `json:"remainingTTL,omitempty"`
+	// RuleIDs are the IDs of the rules using the FQDN selector.
+	RuleIDs []string `json:"ruleIDs,omitempty"`
+}
+
+// HandleFunc creates a http.HandlerFunc which uses an AgentNetworkPolicyInfoQuerier
+// to query the FQDN selectors of FQDN policy rules, the FQDNs they match and the IP
+// addresses cached for these FQDNs.
+func HandleFunc(npq querier.AgentNetworkPolicyInfoQuerier) http.HandlerFunc {
+	return handleFunc(npq, clock.RealClock{})
+}
+
+func handleFunc(npq querier.AgentNetworkPolicyInfoQuerier, clock clock.PassiveClock) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if !features.DefaultFeatureGate.Enabled(features.AntreaPolicy) {
+			http.Error(w, "AntreaPolicy is not enabled", http.StatusServiceUnavailable)
+			return
+		}
+		filter := &querier.FQDNCacheFilter{
+			PodName:       r.URL.Query().Get("pod"),
+			PodNamespace:  r.URL.Query().Get("namespace"),
+			DomainPattern: r.URL.Query().Get("domain"),
+		}
+		if filter.PodName != "" && filter.PodNamespace == "" {
+			http.Error(w, "namespace must be provided", http.StatusBadRequest)
+			return
+		}
+		selectorInfos, err := npq.GetFQDNCache(filter)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		now := clock.Now()
+		response := []Response{}
+		for _, info := range selectorInfos {
+			response = append(response, selectorInfoToResponses(info, now)...)
+		}
+		if err := json.NewEncoder(w).Encode(response); err != nil {
+			http.Error(w, "Failed to encode response: "+err.Error(), http.StatusInternalServerError)
+		}
+	}
+}
+
+func selectorInfoToResponses(info querier.FQDNSelectorInfo, now time.Time) []Response {
+	entriesByFQDN := map[string][]querier.FQDNCacheEntry{}
+	for _, entry := range info.CacheEntries {
+		entriesByFQDN[entry.FQDN] = append(entriesByFQDN[entry.FQDN], entry)
+	}
+	if len(info.MatchedFQDNs) == 0 {
+		return []Response{{Selector: info.Selector, RuleIDs: info.RuleIDs}}
+	}
+	var responses []Response
+	for _, fqdn := range info.MatchedFQDNs {
+		entries := entriesByFQDN[fqdn]
+		if len(entries) == 0 {
+			responses = append(responses, Response{Selector: info.Selector, FQDN: fqdn, RuleIDs: info.RuleIDs})
+			continue
+		}
+		for i := range entries {
+			expirationTime := entries[i].ExpirationTime
+			remainingTTL := int64(expirationTime.Sub(now).Seconds())
+			if remainingTTL < 0 {
+				remainingTTL = 0
+			}
+			responses = append(responses, Response{
+				Selector:       info.Selector,
+				FQDN:           fqdn,
+				IPAddress:      entries[i].IPAddress.String(),
+				ExpirationTime: &expirationTime,
+				RemainingTTL:   &remainingTTL,
+				RuleIDs:        info.RuleIDs,
+			})
+		}
+	}
+	return responses
+}
+
+var _ common.TableOutput = (*Response)(nil)
+
+func (r Response) GetTableHeader() []string {
+	return []string{"SELECTOR", "FQDN", "ADDRESS", "EXPIRATION-TIME", "TTL", "RULES"}
+}
+
+func (r Response) GetTableRow(maxColumnLength int) []string {
+	var expirationTime, remainingTTL string
+	if r.ExpirationTime != nil {
+		expirationTime = r.ExpirationTime.Format(time.RFC3339)
+	}
+	if r.RemainingTTL != nil {
+		remainingTTL = strconv.FormatInt(*r.RemainingTTL, 10)
+	}
+	return []string{r.Selector, r.FQDN, r.IPAddress, expirationTime, remainingTTL, common.GenerateTableElementWithSummary(r.RuleIDs, maxColumnLength)}
+}
+
+func (r Response) SortRows() bool {
+	return false
+}
@@ -0,0 +1,159 @@
+// Copyright 2022 Antrea Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package fqdncache
+
+import (
+	"encoding/json"
+	"fmt"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+	clocktesting "k8s.io/utils/clock/testing"
+
+	"antrea.io/antrea/pkg/querier"
+	queriertest "antrea.io/antrea/pkg/querier/testing"
+)
+
+func TestFQDNCacheQuery(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	now := time.Date(2022, 6, 1, 10, 0, 0, 0, time.UTC)
+	fakeClock := clocktesting.NewFakePassiveClock(now)
+	validExpirationTime, staleExpirationTime := now.Add(90*time.Second), now.Add(-10*time.Second)
+	validTTL, staleTTL := int64(90), int64(0)
+	testcases := map[string]struct {
+		query            string
+		expectedFilter   *querier.FQDNCacheFilter
+		selectorInfos    []querier.FQDNSelectorInfo
+		queryErr         error
+		expectedStatus   int
+		expectedResponse []Response
+	}{
+		"Empty cache": {
+			expectedFilter:   &querier.FQDNCacheFilter{},
+			expectedStatus:   http.StatusOK,
+			expectedResponse: []Response{},
+		},
+		"Cached addresses": {
+			query:          "?pod=pod1&namespace=ns1&domain=*.example.com",
+			expectedFilter: &querier.FQDNCacheFilter{PodName: "pod1", PodNamespace: "ns1", DomainPattern: "*.example.com"},
+			selectorInfos: []querier.FQDNSelectorInfo{
+				{
+					Selector: "matchName:unresolved.example.com",
+					RuleIDs:  []string{"rule2"},
+				},
+				{
+					Selector:     "matchRegex:^.*[.]example[.]com$",
+					RuleIDs:      []string{"rule1", "rule2"},
+					MatchedFQDNs: []string{"a.example.com", "b.example.com"},
+					CacheEntries: []querier.FQDNCacheEntry{
+						{
+							FQDN:           "a.example.com",
+							IPAddress:      net.ParseIP("10.0.0.1"),
+							ExpirationTime: validExpirationTime,
+						},
+						{
+							FQDN:           "a.example.com",
+							IPAddress:      net.ParseIP("fd00::1"),
+							ExpirationTime: staleExpirationTime,
+						},
+					},
+				},
+			},
+			expectedStatus: http.StatusOK,
+			expectedResponse: []Response{
+				{
+					Selector: "matchName:unresolved.example.com",
+					RuleIDs:  []string{"rule2"},
+				},
+				{
+					Selector:       "matchRegex:^.*[.]example[.]com$",
+					FQDN:           "a.example.com",
+					IPAddress:      "10.0.0.1",
+					ExpirationTime: &validExpirationTime,
+					RemainingTTL:   &validTTL,
+					RuleIDs:        []string{"rule1", "rule2"},
+				},
+				{
+					Selector:       "matchRegex:^.*[.]example[.]com$",
+					FQDN:           "a.example.com",
+					IPAddress:      "fd00::1",
+					ExpirationTime: &staleExpirationTime,
+					RemainingTTL:   &staleTTL,
+					RuleIDs:        []string{"rule1", "rule2"},
+				},
+				{
+					Selector: "matchRegex:^.*[.]example[.]com$",
+					FQDN:     "b.example.com",
+					RuleIDs:  []string{"rule1", "rule2"},
+				},
+			},
+		},
+		"Pod without Namespace": {
+			query:          "?pod=pod1",
+			expectedStatus: http.StatusBadRequest,
+		},
+		"Invalid domain pattern": {
+			query:          "?domain=a(",
+			expectedFilter: &querier.FQDNCacheFilter{DomainPattern: "a("},
+			queryErr:       fmt.Errorf("invalid domain pattern"),
+			expectedStatus: http.StatusBadRequest,
+		},
+	}
+	for name, tc := range testcases {
+		t.Run(name, func(t *testing.T) {
+			q := queriertest.NewMockAgentNetworkPolicyInfoQuerier(ctrl)
+			if tc.expectedFilter != nil {
+				q.EXPECT().GetFQDNCache(tc.expectedFilter).Return(tc.selectorInfos, tc.queryErr)
+			}
+			handler := handleFunc(q, fakeClock)
+			req, err := http.NewRequest(http.MethodGet, tc.query, nil)
+			require.NoError(t, err)
+			recorder := httptest.NewRecorder()
+			handler.ServeHTTP(recorder, req)
+			assert.Equal(t, tc.expectedStatus, recorder.Code)
+			if tc.expectedStatus != http.StatusOK {
+				return
+			}
+
+			var received []Response
+			require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &received))
+			assert.Equal(t, tc.expectedResponse, received)
+		})
+	}
+}
+
+func TestResponseTableRow(t *testing.T) {
+	expirationTime := time.Date(2022, 6, 1, 10, 1, 30, 0, time.UTC)
+	remainingTTL := int64(90)
+	r := Response{
+		Selector:       "matchName:example.com",
+		FQDN:           "example.com",
+		IPAddress:      "10.0.0.1",
+		ExpirationTime: &expirationTime,
+		RemainingTTL:   &remainingTTL,
+		RuleIDs:        []string{"rule1", "rule2"},
+	}
+	assert.Equal(t, []string{"matchName:example.com", "example.com", "10.0.0.1", "2022-06-01T10:01:30Z", "90", "rule1,rule2"}, r.GetTableRow(32))
+	r = Response{Selector: "matchName:example.com", RuleIDs: []string{"rule1"}}
+	assert.Equal(t, []string{"matchName:example.com", "", "", "", "", "rule1"}, r.GetTableRow(32))
+}
@@ -0,0 +1,62 @@
+// Copyright 2022 Antrea Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package packetcapture
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"k8s.io/apimachinery/pkg/util/validation"
+	"k8s.io/klog/v2"
+
+	"antrea.io/antrea/pkg/features"
+)
+
+// HandleFunc returns the function which can handle the requests issued by the
+// 'antctl packetcapture' command to download the pcapng file of a
+// PacketCapture, which is stored in captureDir.
+func HandleFunc(captureDir string) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if !features.DefaultFeatureGate.Enabled(features.PacketCapture) {
+			http.Error(w, "PacketCapture is not enabled", http.StatusServiceUnavailable)
+			return
+		}
+		name := r.URL.Query().Get("name")
+		// The name is used as a file name, and PacketCapture names are
+		// DNS subdomains, so it cannot refer to another directory.
+		if errs := validation.IsDNS1123Subdomain(name); len(errs) > 0 {
+			http.Error(w, fmt.Sprintf("invalid PacketCapture name %q: %s", name, strings.Join(errs, "; ")), http.StatusBadRequest)
+			return
+		}
+		f, err := os.Open(filepath.Join(captureDir, name+".pcapng"))
+		if err != nil {
+			if os.IsNotExist(err) {
+				http.Error(w, fmt.Sprintf("capture file of PacketCapture %s not found", name), http.StatusNotFound)
+				return
+			}
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		defer f.Close()
+		w.Header().Set("Content-Type", "application/octet-stream")
+		if _, err := io.Copy(w, f); err != nil {
+			klog.ErrorS(err, "Error when sending capture file", "name", name)
+		}
+	}
+}
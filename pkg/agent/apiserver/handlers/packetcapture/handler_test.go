@@ -0,0 +1,78 @@
+// Copyright 2022 Antrea Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package packetcapture
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	featuregatetesting "k8s.io/component-base/featuregate/testing"
+
+	"antrea.io/antrea/pkg/features"
+)
+
+func TestPacketCaptureDownload(t *testing.T) {
+	captureDir := t.TempDir()
+	content := []byte{0x0a, 0x0d, 0x0d, 0x0a}
+	require.NoError(t, os.WriteFile(filepath.Join(captureDir, "pc1.pcapng"), content, 0600))
+	handler := HandleFunc(captureDir)
+
+	testcases := map[string]struct {
+		featureDisabled  bool
+		query            string
+		expectedStatus   int
+		expectedResponse []byte
+	}{
+		"Feature disabled": {
+			featureDisabled: true,
+			query:           "?name=pc1",
+			expectedStatus:  http.StatusServiceUnavailable,
+		},
+		"Existing file": {
+			query:            "?name=pc1",
+			expectedStatus:   http.StatusOK,
+			expectedResponse: content,
+		},
+		"Missing file": {
+			query:          "?name=pc2",
+			expectedStatus: http.StatusNotFound,
+		},
+		"Missing name": {
+			expectedStatus: http.StatusBadRequest,
+		},
+		"Invalid name": {
+			query:          "?name=../pc1",
+			expectedStatus: http.StatusBadRequest,
+		},
+	}
+	for name, tc := range testcases {
+		t.Run(name, func(t *testing.T) {
+			defer featuregatetesting.SetFeatureGateDuringTest(t, features.DefaultFeatureGate, features.PacketCapture, !tc.featureDisabled)()
+			req, err := http.NewRequest(http.MethodGet, "/packetcapture"+tc.query, nil)
+			require.NoError(t, err)
+			recorder := httptest.NewRecorder()
+			handler.ServeHTTP(recorder, req)
+			assert.Equal(t, tc.expectedStatus, recorder.Code)
+			if tc.expectedResponse != nil {
+				assert.Equal(t, tc.expectedResponse, recorder.Body.Bytes())
+			}
+		})
+	}
+}
@@ -231,4 +231,6 @@ type ServiceConfig struct {
 	ServiceCIDRv6         *net.IPNet // K8s Service ClusterIP CIDR in IPv6
 	NodePortAddressesIPv4 []net.IP
 	NodePortAddressesIPv6 []net.IP
+	// EnableLoadBalancerModeDSR indicates whether the LoadBalancer IPs of Services can be load-balanced in DSR mode.
+	EnableLoadBalancerModeDSR bool
 }
@@ -16,7 +16,9 @@ package egress
 
 import (
 	"context"
+	goerrors "errors"
 	"fmt"
+	"math"
 	"net"
 	"reflect"
 	"strings"
@@ -24,6 +26,7 @@ import (
 	"time"
 
 	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/resource"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/fields"
 	"k8s.io/apimachinery/pkg/labels"
@@ -34,6 +37,7 @@ import (
 	"k8s.io/client-go/util/retry"
 	"k8s.io/client-go/util/workqueue"
 	"k8s.io/klog/v2"
+	"k8s.io/utils/clock"
 
 	"antrea.io/antrea/pkg/agent"
 	"antrea.io/antrea/pkg/agent/interfacestore"
@@ -49,6 +53,7 @@ import (
 	crdlisters "antrea.io/antrea/pkg/client/listers/crd/v1alpha2"
 	"antrea.io/antrea/pkg/controller/metrics"
 	"antrea.io/antrea/pkg/util/channel"
+	utilegress "antrea.io/antrea/pkg/util/egress"
 	"antrea.io/antrea/pkg/util/k8s"
 )
 
@@ -71,6 +76,17 @@ const (
 
 	// egressDummyDevice is the dummy device that holds the Egress IPs configured to the system by antrea-agent.
 	egressDummyDevice = "antrea-egress0"
+
+	// Reasons of the transitions of Egress IPs between Nodes.
+	// failoverReasonNodeUnreachable means the previous Node became unreachable.
+	failoverReasonNodeUnreachable = "NodeUnreachable"
+	// failoverReasonNodeSelectionChanged means the selected Node changed while the previous Node was still reachable,
+	// e.g. a Node joined the cluster or the nodeSelector of the ExternalIPPool changed.
+	failoverReasonNodeSelectionChanged = "NodeSelectionChanged"
+	// failoverReasonIPReassigned means the Egress IP not managed by Antrea was reassigned to another Node manually.
+	failoverReasonIPReassigned = "IPReassigned"
+	// failoverReasonIPReleased means the previous Node released the Egress IP and no Node has taken it over yet.
+	failoverReasonIPReleased = "IPReleased"
 )
 
 var emptyWatch = watch.NewEmptyWatch()
@@ -101,6 +117,16 @@ type egressIPState struct {
 	flowsInstalled bool
 	// Whether its iptables rule has been installed.
 	ruleInstalled bool
+	// The rate limit that has been applied to this Egress IP. nil if no rate limit is applied.
+	qos *egressQoS
+}
+
+// egressQoS is the rate limit applied to the traffic SNAT'd with a local Egress IP.
+type egressQoS struct {
+	// The maximum rate in kilobits per second.
+	rate uint32
+	// The maximum burst size in kilobits.
+	burst uint32
 }
 
 // egressBinding keeps the Egresses applying to a Pod.
@@ -143,6 +169,8 @@ type EgressController struct {
 
 	cluster    *memberlist.Cluster
 	ipAssigner ipassigner.IPAssigner
+	// Added as a member to the struct to allow injection for testing.
+	clock clock.Clock
 }
 
 func NewEgressController(
@@ -175,6 +203,7 @@ func NewEgressController(
 		localIPDetector:      ipassigner.NewLocalIPDetector(),
 		idAllocator:          newIDAllocator(minEgressMark, maxEgressMark),
 		cluster:              cluster,
+		clock:                clock.RealClock{},
 	}
 	ipAssigner, err := ipassigner.NewIPAssigner(nodeTransportInterface, egressDummyDevice)
 	if err != nil {
@@ -213,6 +242,7 @@ func NewEgressController(
 	podUpdateSubscriber.Subscribe(c.processPodUpdate)
 	c.localIPDetector.AddEventHandler(c.onLocalIPUpdate)
 	c.cluster.AddClusterEventHandler(c.enqueueEgressesByExternalIPPool)
+	c.cluster.AddIPsGetter(c.getEgressIPsByExternalIPPool)
 	return c, nil
 }
 
@@ -237,6 +267,7 @@ func (c *EgressController) addEgress(obj interface{}) {
 		return
 	}
 	c.queue.Add(egress.Name)
+	c.notifyEgressIPsChanged(egress)
 	klog.V(2).InfoS("Processed Egress ADD event", "egress", klog.KObj(egress))
 }
 
@@ -249,6 +280,10 @@ func (c *EgressController) updateEgress(old, cur interface{}) {
 		return
 	}
 	c.queue.Add(curEgress.Name)
+	if oldEgress.Spec.EgressIP != curEgress.Spec.EgressIP || oldEgress.Spec.ExternalIPPool != curEgress.Spec.ExternalIPPool {
+		c.notifyEgressIPsChanged(oldEgress)
+		c.notifyEgressIPsChanged(curEgress)
+	}
 	klog.V(2).InfoS("Processed Egress UPDATE event", "egress", klog.KObj(curEgress))
 }
 
@@ -268,9 +303,32 @@ func (c *EgressController) deleteEgress(obj interface{}) {
 		}
 	}
 	c.queue.Add(egress.Name)
+	c.notifyEgressIPsChanged(egress)
 	klog.V(2).InfoS("Processed Egress DELETE event", "egress", klog.KObj(egress))
 }
 
+// notifyEgressIPsChanged notifies the cluster that the IPs allocated from the Egress's ExternalIPPool changed, as
+// the owner Nodes of other Egress IPs may depend on them.
+func (c *EgressController) notifyEgressIPsChanged(egress *crdv1a2.Egress) {
+	if egress.Spec.ExternalIPPool == "" || egress.Spec.EgressIP == "" {
+		return
+	}
+	c.cluster.NotifyIPsChanged(egress.Spec.ExternalIPPool)
+}
+
+// getEgressIPsByExternalIPPool returns the IPs of the Egresses that refer to the provided ExternalIPPool.
+func (c *EgressController) getEgressIPsByExternalIPPool(eipName string) []memberlist.PoolIP {
+	objects, _ := c.egressInformer.GetIndexer().ByIndex(externalIPPoolIndex, eipName)
+	ips := make([]memberlist.PoolIP, 0, len(objects))
+	for _, object := range objects {
+		egress := object.(*crdv1a2.Egress)
+		if egress.Spec.EgressIP != "" {
+			ips = append(ips, memberlist.PoolIP{IP: egress.Spec.EgressIP})
+		}
+	}
+	return ips
+}
+
 func (c *EgressController) onLocalIPUpdate(ip string, added bool) {
 	egresses, _ := c.egressInformer.GetIndexer().ByIndex(egressIPIndex, ip)
 	if len(egresses) == 0 {
@@ -422,8 +480,14 @@ func (c *EgressController) realizeEgressIP(egressName, egressIP string) (uint32,
 			}
 			ipState.ruleInstalled = true
 		}
+		if err := c.syncEgressQoS(ipState, c.getDesiredEgressQoS(egressIP)); err != nil {
+			return 0, err
+		}
 	} else {
 		// Ensure datapath is uninstalled properly.
+		if err := c.syncEgressQoS(ipState, nil); err != nil {
+			return 0, err
+		}
 		if ipState.ruleInstalled {
 			if err := c.routeClient.DeleteSNATRule(ipState.mark); err != nil {
 				return 0, fmt.Errorf("error uninstalling SNAT rule for IP %s: %v", ipState.egressIP, err)
@@ -463,9 +527,16 @@ func (c *EgressController) unrealizeEgressIP(egressName, egressIP string) error
 	// release the mark if installed.
 	ipState.egressNames.Delete(egressName)
 	if len(ipState.egressNames) > 0 {
+		// The rate limit of the Egress IP may be determined by the unlinked Egress.
+		if ipState.mark != 0 {
+			return c.syncEgressQoS(ipState, c.getDesiredEgressQoS(egressIP))
+		}
 		return nil
 	}
 	if ipState.mark != 0 {
+		if err := c.syncEgressQoS(ipState, nil); err != nil {
+			return err
+		}
 		if ipState.ruleInstalled {
 			if err := c.routeClient.DeleteSNATRule(ipState.mark); err != nil {
 				return err
@@ -484,6 +555,83 @@ func (c *EgressController) unrealizeEgressIP(egressName, egressIP string) error
 	return nil
 }
 
+// getDesiredEgressQoS returns the rate limit that should be applied to the Egress IP. Egresses sharing the same Egress
+// IP share the same rate limit, in which case the one with the smallest rate takes effect. It returns nil if none of
+// the Egresses specifies a rate limit.
+func (c *EgressController) getDesiredEgressQoS(egressIP string) *egressQoS {
+	var desired *egressQoS
+	egresses, _ := c.egressInformer.GetIndexer().ByIndex(egressIPIndex, egressIP)
+	for _, obj := range egresses {
+		egress := obj.(*crdv1a2.Egress)
+		if egress.Spec.Bandwidth == nil {
+			continue
+		}
+		qos, err := parseEgressBandwidth(egress.Spec.Bandwidth)
+		if err != nil {
+			klog.ErrorS(err, "Invalid bandwidth of Egress", "egress", klog.KObj(egress))
+			continue
+		}
+		if desired == nil || qos.rate < desired.rate {
+			desired = qos
+		}
+	}
+	return desired
+}
+
+// syncEgressQoS applies the desired rate limit to a local Egress IP, and removes the rate limit if desired is nil.
+func (c *EgressController) syncEgressQoS(ipState *egressIPState, desired *egressQoS) error {
+	if reflect.DeepEqual(ipState.qos, desired) {
+		return nil
+	}
+	if desired == nil {
+		if err := c.ofClient.UninstallEgressQoS(ipState.mark); err != nil {
+			return fmt.Errorf("error uninstalling QoS for IP %s: %v", ipState.egressIP, err)
+		}
+		ipState.qos = nil
+		return nil
+	}
+	if err := c.ofClient.InstallEgressQoS(ipState.mark, desired.rate, desired.burst); err != nil {
+		if goerrors.Is(err, openflow.ErrEgressTrafficShapingNotSupported) {
+			klog.ErrorS(err, "Failed to apply bandwidth of Egress IP", "ip", ipState.egressIP)
+			return nil
+		}
+		return fmt.Errorf("error installing QoS for IP %s: %v", ipState.egressIP, err)
+	}
+	ipState.qos = desired
+	return nil
+}
+
+// parseEgressBandwidth converts the bandwidth of an Egress to the rate in kilobits per second and the burst size in
+// kilobits.
+func parseEgressBandwidth(bandwidth *crdv1a2.Bandwidth) (*egressQoS, error) {
+	toKilobits := func(value string) (uint32, error) {
+		quantity, err := resource.ParseQuantity(value)
+		if err != nil {
+			return 0, fmt.Errorf("invalid quantity %q: %v", value, err)
+		}
+		bits := quantity.Value()
+		if bits <= 0 {
+			return 0, fmt.Errorf("quantity %q must be positive", value)
+		}
+		kilobits := (bits + 999) / 1000
+		if kilobits > math.MaxUint32 {
+			kilobits = math.MaxUint32
+		}
+		return uint32(kilobits), nil
+	}
+	rate, err := toKilobits(bandwidth.Rate)
+	if err != nil {
+		return nil, err
+	}
+	burst := rate
+	if bandwidth.Burst != "" {
+		if burst, err = toKilobits(bandwidth.Burst); err != nil {
+			return nil, err
+		}
+	}
+	return &egressQoS{rate: rate, burst: burst}, nil
+}
+
 func (c *EgressController) getEgressState(egressName string) (*egressState, bool) {
 	c.egressStatesMutex.RLock()
 	defer c.egressStatesMutex.RUnlock()
@@ -555,24 +703,111 @@ func (c *EgressController) unbindPodEgress(pod, egress string) (string, bool) {
 	return "", false
 }
 
+// setEgressStatus updates the status of the provided Egress according to whether the Egress IP is assigned to this
+// Node, and returns whether the status is changed. It only updates the status if the Egress IP is assigned to this Node,
+// or if it was assigned to this Node previously.
+func (c *EgressController) setEgressStatus(egress *crdv1a2.Egress, isLocal bool) bool {
+	status := &egress.Status
+	now := metav1.NewTime(c.clock.Now())
+	var changed bool
+	if isLocal {
+		if status.EgressNode != c.nodeName {
+			fromNode := status.EgressNode
+			// If the previous Node has released the IP, the last transition records which Node held it and is
+			// completed by this Node.
+			var released *crdv1a2.EgressFailover
+			if fromNode == "" && len(status.FailoverHistory) > 0 {
+				last := &status.FailoverHistory[len(status.FailoverHistory)-1]
+				if last.ToNode == "" {
+					released = last
+					fromNode = last.FromNode
+				} else {
+					fromNode = last.ToNode
+				}
+			}
+			if released != nil && fromNode == c.nodeName {
+				// The IP is back to this Node, there is no transition.
+				status.FailoverHistory = status.FailoverHistory[:len(status.FailoverHistory)-1]
+			}
+			if fromNode != "" && fromNode != c.nodeName {
+				reason := c.getFailoverReason(egress, fromNode)
+				failover := crdv1a2.EgressFailover{
+					FromNode: fromNode,
+					ToNode:   c.nodeName,
+					Time:     now,
+					Reason:   reason,
+				}
+				if released != nil {
+					*released = failover
+				} else {
+					status.FailoverHistory = utilegress.AppendFailover(status.FailoverHistory, failover)
+				}
+				nodeUnreachable := crdv1a2.EgressCondition{
+					Type:               crdv1a2.NodeUnreachable,
+					Status:             metav1.ConditionFalse,
+					LastTransitionTime: now,
+					Reason:             reason,
+				}
+				if reason == failoverReasonNodeUnreachable {
+					nodeUnreachable.Status = metav1.ConditionTrue
+					nodeUnreachable.Message = fmt.Sprintf("Node %s became unreachable", fromNode)
+				}
+				status.Conditions, _ = utilegress.SetCondition(status.Conditions, nodeUnreachable)
+			}
+			status.EgressNode = c.nodeName
+			changed = true
+		}
+		var conditionChanged bool
+		status.Conditions, conditionChanged = utilegress.SetCondition(status.Conditions, crdv1a2.EgressCondition{
+			Type:               crdv1a2.IPAssigned,
+			Status:             metav1.ConditionTrue,
+			LastTransitionTime: now,
+			Reason:             "Assigned",
+			Message:            fmt.Sprintf("EgressIP is assigned to Node %s", c.nodeName),
+		})
+		return changed || conditionChanged
+	}
+	// Do nothing if the current EgressNode in status is not this Node.
+	if status.EgressNode != c.nodeName {
+		return false
+	}
+	status.EgressNode = ""
+	// Record that this Node held the IP, so the Node taking it over can complete the transition.
+	status.FailoverHistory = utilegress.AppendFailover(status.FailoverHistory, crdv1a2.EgressFailover{
+		FromNode: c.nodeName,
+		Time:     now,
+		Reason:   failoverReasonIPReleased,
+	})
+	status.Conditions, _ = utilegress.SetCondition(status.Conditions, crdv1a2.EgressCondition{
+		Type:               crdv1a2.IPAssigned,
+		Status:             metav1.ConditionFalse,
+		LastTransitionTime: now,
+		Reason:             "Unassigned",
+		Message:            fmt.Sprintf("EgressIP is unassigned from Node %s", c.nodeName),
+	})
+	return true
+}
+
+// getFailoverReason returns the reason why the Egress IP moved from the provided Node to this Node.
+func (c *EgressController) getFailoverReason(egress *crdv1a2.Egress, fromNode string) string {
+	if egress.Spec.ExternalIPPool == "" {
+		return failoverReasonIPReassigned
+	}
+	if !c.cluster.AliveNodes().Has(fromNode) {
+		return failoverReasonNodeUnreachable
+	}
+	return failoverReasonNodeSelectionChanged
+}
+
 func (c *EgressController) updateEgressStatus(egress *crdv1a2.Egress, isLocal bool) error {
 	toUpdate := egress.DeepCopy()
 	var updateErr, getErr error
 	if err := retry.RetryOnConflict(retry.DefaultRetry, func() error {
-		if isLocal {
-			// Do nothing if the current EgressNode in status is already this Node.
-			if toUpdate.Status.EgressNode == c.nodeName {
-				return nil
-			}
-			toUpdate.Status.EgressNode = c.nodeName
-		} else {
-			// Do nothing if the current EgressNode in status is not this Node.
-			if toUpdate.Status.EgressNode != c.nodeName {
-				return nil
-			}
-			toUpdate.Status.EgressNode = ""
+		oldNode := toUpdate.Status.EgressNode
+		if !c.setEgressStatus(toUpdate, isLocal) {
+			return nil
 		}
-		klog.V(2).InfoS("Updating Egress status", "Egress", egress.Name, "oldNode", egress.Status.EgressNode, "newNode", toUpdate.Status.EgressNode)
+		klog.V(2).InfoS("Updating Egress status", "Egress", egress.Name, "oldNode", oldNode, "newNode", toUpdate.Status.EgressNode)
 		_, updateErr = c.crdClient.CrdV1alpha2().Egresses().UpdateStatus(context.TODO(), toUpdate, metav1.UpdateOptions{})
 		if updateErr != nil && errors.IsConflict(updateErr) {
 			if toUpdate, getErr = c.crdClient.CrdV1alpha2().Egresses().Get(context.TODO(), egress.Name, metav1.GetOptions{}); getErr != nil {
@@ -22,9 +22,9 @@ import (
 	"testing"
 	"time"
 
-	"github.com/golang/mock/gomock"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
 	"k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
@@ -32,6 +32,7 @@ import (
 	"k8s.io/apimachinery/pkg/util/wait"
 	k8stesting "k8s.io/client-go/testing"
 	"k8s.io/client-go/util/workqueue"
+	clock "k8s.io/utils/clock/testing"
 
 	"antrea.io/antrea/pkg/agent/interfacestore"
 	"antrea.io/antrea/pkg/agent/ipassigner"
@@ -56,6 +57,24 @@ const (
 	fakeNode            = "node1"
 )
 
+var fakeTime = time.Date(2022, 1, 1, 0, 0, 0, 0, time.UTC)
+
+// assignedEgressStatus returns the status of an Egress whose IP is assigned to the fake Node at fakeTime.
+func assignedEgressStatus() crdv1a2.EgressStatus {
+	return crdv1a2.EgressStatus{
+		EgressNode: fakeNode,
+		Conditions: []crdv1a2.EgressCondition{
+			{
+				Type:               crdv1a2.IPAssigned,
+				Status:             metav1.ConditionTrue,
+				LastTransitionTime: metav1.NewTime(fakeTime),
+				Reason:             "Assigned",
+				Message:            "EgressIP is assigned to Node node1",
+			},
+		},
+	}
+}
+
 type fakeLocalIPDetector struct {
 	localIPs sets.String
 }
@@ -137,6 +156,7 @@ func newFakeController(t *testing.T, initObjects []runtime.Object) *fakeControll
 		egressStates:         map[string]*egressState{},
 		egressIPStates:       map[string]*egressIPState{},
 		ipAssigner:           mockIPAssigner,
+		clock:                clock.NewFakeClock(fakeTime),
 	}
 	podUpdateChannel.Subscribe(egressController.processPodUpdate)
 	return &fakeController{
@@ -240,7 +260,7 @@ func TestSyncEgress(t *testing.T) {
 				{
 					ObjectMeta: metav1.ObjectMeta{Name: "egressA", UID: "uidA"},
 					Spec:       crdv1a2.EgressSpec{EgressIP: fakeRemoteEgressIP1},
-					Status:     crdv1a2.EgressStatus{EgressNode: fakeNode},
+					Status:     assignedEgressStatus(),
 				},
 			},
 			expectedCalls: func(mockOFClient *openflowtest.MockClient, mockRouteClient *routetest.MockInterface, mockIPAssigner *ipassignertest.MockIPAssigner) {
@@ -287,7 +307,7 @@ func TestSyncEgress(t *testing.T) {
 				{
 					ObjectMeta: metav1.ObjectMeta{Name: "egressA", UID: "uidA"},
 					Spec:       crdv1a2.EgressSpec{EgressIP: fakeLocalEgressIP2},
-					Status:     crdv1a2.EgressStatus{EgressNode: fakeNode},
+					Status:     assignedEgressStatus(),
 				},
 			},
 			expectedCalls: func(mockOFClient *openflowtest.MockClient, mockRouteClient *routetest.MockInterface, mockIPAssigner *ipassignertest.MockIPAssigner) {
@@ -388,7 +408,7 @@ func TestSyncEgress(t *testing.T) {
 				{
 					ObjectMeta: metav1.ObjectMeta{Name: "egressA", UID: "uidA"},
 					Spec:       crdv1a2.EgressSpec{EgressIP: fakeLocalEgressIP1},
-					Status:     crdv1a2.EgressStatus{EgressNode: fakeNode},
+					Status:     assignedEgressStatus(),
 				},
 			},
 			expectedCalls: func(mockOFClient *openflowtest.MockClient, mockRouteClient *routetest.MockInterface, mockIPAssigner *ipassignertest.MockIPAssigner) {
@@ -436,12 +456,12 @@ func TestSyncEgress(t *testing.T) {
 				{
 					ObjectMeta: metav1.ObjectMeta{Name: "egressA", UID: "uidA"},
 					Spec:       crdv1a2.EgressSpec{EgressIP: fakeLocalEgressIP1},
-					Status:     crdv1a2.EgressStatus{EgressNode: fakeNode},
+					Status:     assignedEgressStatus(),
 				},
 				{
 					ObjectMeta: metav1.ObjectMeta{Name: "egressB", UID: "uidB"},
 					Spec:       crdv1a2.EgressSpec{EgressIP: fakeLocalEgressIP2},
-					Status:     crdv1a2.EgressStatus{EgressNode: fakeNode},
+					Status:     assignedEgressStatus(),
 				},
 			},
 			expectedCalls: func(mockOFClient *openflowtest.MockClient, mockRouteClient *routetest.MockInterface, mockIPAssigner *ipassignertest.MockIPAssigner) {
@@ -487,12 +507,12 @@ func TestSyncEgress(t *testing.T) {
 				{
 					ObjectMeta: metav1.ObjectMeta{Name: "egressA", UID: "uidA"},
 					Spec:       crdv1a2.EgressSpec{EgressIP: fakeLocalEgressIP1},
-					Status:     crdv1a2.EgressStatus{EgressNode: fakeNode},
+					Status:     assignedEgressStatus(),
 				},
 				{
 					ObjectMeta: metav1.ObjectMeta{Name: "egressB", UID: "uidB"},
 					Spec:       crdv1a2.EgressSpec{EgressIP: fakeLocalEgressIP1},
-					Status:     crdv1a2.EgressStatus{EgressNode: fakeNode},
+					Status:     assignedEgressStatus(),
 				},
 			},
 			expectedCalls: func(mockOFClient *openflowtest.MockClient, mockRouteClient *routetest.MockInterface, mockIPAssigner *ipassignertest.MockIPAssigner) {
@@ -504,6 +524,88 @@ func TestSyncEgress(t *testing.T) {
 				mockIPAssigner.EXPECT().UnassignIP(fakeLocalEgressIP1).Times(3)
 			},
 		},
+		{
+			name: "Update bandwidth of local Egress",
+			existingEgress: &crdv1a2.Egress{
+				ObjectMeta: metav1.ObjectMeta{Name: "egressA", UID: "uidA"},
+				Spec:       crdv1a2.EgressSpec{EgressIP: fakeLocalEgressIP1, Bandwidth: &crdv1a2.Bandwidth{Rate: "100M"}},
+			},
+			newEgress: &crdv1a2.Egress{
+				ObjectMeta: metav1.ObjectMeta{Name: "egressA", UID: "uidA"},
+				Spec:       crdv1a2.EgressSpec{EgressIP: fakeLocalEgressIP1, Bandwidth: &crdv1a2.Bandwidth{Rate: "10M", Burst: "20M"}},
+			},
+			existingEgressGroup: &cpv1b2.EgressGroup{
+				ObjectMeta: metav1.ObjectMeta{Name: "egressA", UID: "uidA"},
+				GroupMembers: []cpv1b2.GroupMember{
+					{Pod: &cpv1b2.PodReference{Name: "pod1", Namespace: "ns1"}},
+				},
+			},
+			newEgressGroup: &cpv1b2.EgressGroup{
+				ObjectMeta: metav1.ObjectMeta{Name: "egressA", UID: "uidA"},
+				GroupMembers: []cpv1b2.GroupMember{
+					{Pod: &cpv1b2.PodReference{Name: "pod1", Namespace: "ns1"}},
+				},
+			},
+			expectedEgresses: []*crdv1a2.Egress{
+				{
+					ObjectMeta: metav1.ObjectMeta{Name: "egressA", UID: "uidA"},
+					Spec:       crdv1a2.EgressSpec{EgressIP: fakeLocalEgressIP1, Bandwidth: &crdv1a2.Bandwidth{Rate: "10M", Burst: "20M"}},
+					Status:     assignedEgressStatus(),
+				},
+			},
+			expectedCalls: func(mockOFClient *openflowtest.MockClient, mockRouteClient *routetest.MockInterface, mockIPAssigner *ipassignertest.MockIPAssigner) {
+				mockOFClient.EXPECT().InstallSNATMarkFlows(net.ParseIP(fakeLocalEgressIP1), uint32(1))
+				mockOFClient.EXPECT().InstallPodSNATFlows(uint32(1), net.ParseIP(fakeLocalEgressIP1), uint32(1))
+				mockRouteClient.EXPECT().AddSNATRule(net.ParseIP(fakeLocalEgressIP1), uint32(1))
+				mockOFClient.EXPECT().InstallEgressQoS(uint32(1), uint32(100000), uint32(100000))
+				mockOFClient.EXPECT().InstallEgressQoS(uint32(1), uint32(10000), uint32(20000))
+				mockIPAssigner.EXPECT().UnassignIP(fakeLocalEgressIP1).Times(3)
+			},
+		},
+		{
+			name: "Local IP with bandwidth becomes non local",
+			existingEgress: &crdv1a2.Egress{
+				ObjectMeta: metav1.ObjectMeta{Name: "egressA", UID: "uidA"},
+				Spec:       crdv1a2.EgressSpec{EgressIP: fakeLocalEgressIP1, Bandwidth: &crdv1a2.Bandwidth{Rate: "1G"}},
+			},
+			newEgress: &crdv1a2.Egress{
+				ObjectMeta: metav1.ObjectMeta{Name: "egressA", UID: "uidA"},
+				Spec:       crdv1a2.EgressSpec{EgressIP: fakeLocalEgressIP1, Bandwidth: &crdv1a2.Bandwidth{Rate: "1G"}},
+			},
+			existingEgressGroup: &cpv1b2.EgressGroup{
+				ObjectMeta: metav1.ObjectMeta{Name: "egressA", UID: "uidA"},
+				GroupMembers: []cpv1b2.GroupMember{
+					{Pod: &cpv1b2.PodReference{Name: "pod1", Namespace: "ns1"}},
+				},
+			},
+			newEgressGroup: &cpv1b2.EgressGroup{
+				ObjectMeta: metav1.ObjectMeta{Name: "egressA", UID: "uidA"},
+				GroupMembers: []cpv1b2.GroupMember{
+					{Pod: &cpv1b2.PodReference{Name: "pod1", Namespace: "ns1"}},
+				},
+			},
+			newLocalIPs: sets.NewString(),
+			expectedEgresses: []*crdv1a2.Egress{
+				{
+					ObjectMeta: metav1.ObjectMeta{Name: "egressA", UID: "uidA"},
+					Spec:       crdv1a2.EgressSpec{EgressIP: fakeLocalEgressIP1, Bandwidth: &crdv1a2.Bandwidth{Rate: "1G"}},
+				},
+			},
+			expectedCalls: func(mockOFClient *openflowtest.MockClient, mockRouteClient *routetest.MockInterface, mockIPAssigner *ipassignertest.MockIPAssigner) {
+				mockOFClient.EXPECT().InstallSNATMarkFlows(net.ParseIP(fakeLocalEgressIP1), uint32(1))
+				mockOFClient.EXPECT().InstallPodSNATFlows(uint32(1), net.ParseIP(fakeLocalEgressIP1), uint32(1))
+				mockRouteClient.EXPECT().AddSNATRule(net.ParseIP(fakeLocalEgressIP1), uint32(1))
+				mockOFClient.EXPECT().InstallEgressQoS(uint32(1), uint32(1000000), uint32(1000000))
+				mockIPAssigner.EXPECT().UnassignIP(fakeLocalEgressIP1)
+
+				mockOFClient.EXPECT().UninstallEgressQoS(uint32(1))
+				mockOFClient.EXPECT().UninstallSNATMarkFlows(uint32(1))
+				mockRouteClient.EXPECT().DeleteSNATRule(uint32(1))
+				mockOFClient.EXPECT().UninstallPodSNATFlows(uint32(1))
+				mockOFClient.EXPECT().InstallPodSNATFlows(uint32(1), net.ParseIP(fakeLocalEgressIP1), uint32(0))
+				mockIPAssigner.EXPECT().UnassignIP(fakeLocalEgressIP1).Times(2)
+			},
+		},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
@@ -822,7 +924,7 @@ func TestUpdateEgressStatus(t *testing.T) {
 				return true, &egress, nil
 			})
 
-			c := &EgressController{crdClient: fakeClient, nodeName: fakeNode}
+			c := &EgressController{crdClient: fakeClient, nodeName: fakeNode, clock: clock.NewFakeClock(fakeTime)}
 			_, err := c.crdClient.CrdV1alpha2().Egresses().Create(context.TODO(), &egress, metav1.CreateOptions{})
 			assert.NoError(t, err)
 			err = c.updateEgressStatus(&egress, true)
@@ -834,3 +936,237 @@ func TestUpdateEgressStatus(t *testing.T) {
 		})
 	}
 }
+
+func TestSetEgressStatus(t *testing.T) {
+	assignedToOtherNode := crdv1a2.EgressStatus{
+		EgressNode: "node2",
+		Conditions: []crdv1a2.EgressCondition{
+			{
+				Type:               crdv1a2.IPAssigned,
+				Status:             metav1.ConditionTrue,
+				LastTransitionTime: metav1.NewTime(fakeTime.Add(-time.Hour)),
+				Reason:             "Assigned",
+				Message:            "EgressIP is assigned to Node node2",
+			},
+		},
+	}
+	tests := []struct {
+		name            string
+		status          crdv1a2.EgressStatus
+		isLocal         bool
+		expectedChanged bool
+		expectedStatus  crdv1a2.EgressStatus
+	}{
+		{
+			name:            "assigned to this Node",
+			status:          crdv1a2.EgressStatus{},
+			isLocal:         true,
+			expectedChanged: true,
+			expectedStatus:  assignedEgressStatus(),
+		},
+		{
+			name:            "already assigned to this Node",
+			status:          assignedEgressStatus(),
+			isLocal:         true,
+			expectedChanged: false,
+			expectedStatus:  assignedEgressStatus(),
+		},
+		{
+			name:            "reassigned from another Node",
+			status:          assignedToOtherNode,
+			isLocal:         true,
+			expectedChanged: true,
+			expectedStatus: crdv1a2.EgressStatus{
+				EgressNode: fakeNode,
+				Conditions: []crdv1a2.EgressCondition{
+					{
+						Type:               crdv1a2.IPAssigned,
+						Status:             metav1.ConditionTrue,
+						LastTransitionTime: metav1.NewTime(fakeTime.Add(-time.Hour)),
+						Reason:             "Assigned",
+						Message:            "EgressIP is assigned to Node node1",
+					},
+					{
+						Type:               crdv1a2.NodeUnreachable,
+						Status:             metav1.ConditionFalse,
+						LastTransitionTime: metav1.NewTime(fakeTime),
+						Reason:             failoverReasonIPReassigned,
+					},
+				},
+				FailoverHistory: []crdv1a2.EgressFailover{
+					{FromNode: "node2", ToNode: fakeNode, Time: metav1.NewTime(fakeTime), Reason: failoverReasonIPReassigned},
+				},
+			},
+		},
+		{
+			name: "reassigned after another Node released it",
+			status: crdv1a2.EgressStatus{
+				FailoverHistory: []crdv1a2.EgressFailover{
+					{FromNode: fakeNode, ToNode: "node2", Time: metav1.NewTime(fakeTime.Add(-time.Hour)), Reason: failoverReasonIPReassigned},
+				},
+			},
+			isLocal:         true,
+			expectedChanged: true,
+			expectedStatus: crdv1a2.EgressStatus{
+				EgressNode: fakeNode,
+				Conditions: []crdv1a2.EgressCondition{
+					{
+						Type:               crdv1a2.NodeUnreachable,
+						Status:             metav1.ConditionFalse,
+						LastTransitionTime: metav1.NewTime(fakeTime),
+						Reason:             failoverReasonIPReassigned,
+					},
+					assignedEgressStatus().Conditions[0],
+				},
+				FailoverHistory: []crdv1a2.EgressFailover{
+					{FromNode: fakeNode, ToNode: "node2", Time: metav1.NewTime(fakeTime.Add(-time.Hour)), Reason: failoverReasonIPReassigned},
+					{FromNode: "node2", ToNode: fakeNode, Time: metav1.NewTime(fakeTime), Reason: failoverReasonIPReassigned},
+				},
+			},
+		},
+		{
+			name:            "unassigned from this Node",
+			status:          assignedEgressStatus(),
+			isLocal:         false,
+			expectedChanged: true,
+			expectedStatus: crdv1a2.EgressStatus{
+				Conditions: []crdv1a2.EgressCondition{
+					{
+						Type:               crdv1a2.IPAssigned,
+						Status:             metav1.ConditionFalse,
+						LastTransitionTime: metav1.NewTime(fakeTime),
+						Reason:             "Unassigned",
+						Message:            "EgressIP is unassigned from Node node1",
+					},
+				},
+				FailoverHistory: []crdv1a2.EgressFailover{
+					{FromNode: fakeNode, Time: metav1.NewTime(fakeTime), Reason: failoverReasonIPReleased},
+				},
+			},
+		},
+		{
+			name: "taken over after another Node released it",
+			status: crdv1a2.EgressStatus{
+				Conditions: []crdv1a2.EgressCondition{
+					{
+						Type:               crdv1a2.IPAssigned,
+						Status:             metav1.ConditionFalse,
+						LastTransitionTime: metav1.NewTime(fakeTime.Add(-time.Minute)),
+						Reason:             "Unassigned",
+						Message:            "EgressIP is unassigned from Node node2",
+					},
+				},
+				FailoverHistory: []crdv1a2.EgressFailover{
+					{FromNode: "node2", Time: metav1.NewTime(fakeTime.Add(-time.Minute)), Reason: failoverReasonIPReleased},
+				},
+			},
+			isLocal:         true,
+			expectedChanged: true,
+			expectedStatus: crdv1a2.EgressStatus{
+				EgressNode: fakeNode,
+				Conditions: []crdv1a2.EgressCondition{
+					{
+						Type:               crdv1a2.IPAssigned,
+						Status:             metav1.ConditionTrue,
+						LastTransitionTime: metav1.NewTime(fakeTime),
+						Reason:             "Assigned",
+						Message:            "EgressIP is assigned to Node node1",
+					},
+					{
+						Type:               crdv1a2.NodeUnreachable,
+						Status:             metav1.ConditionFalse,
+						LastTransitionTime: metav1.NewTime(fakeTime),
+						Reason:             failoverReasonIPReassigned,
+					},
+				},
+				FailoverHistory: []crdv1a2.EgressFailover{
+					{FromNode: "node2", ToNode: fakeNode, Time: metav1.NewTime(fakeTime), Reason: failoverReasonIPReassigned},
+				},
+			},
+		},
+		{
+			name: "taken back after this Node released it",
+			status: crdv1a2.EgressStatus{
+				FailoverHistory: []crdv1a2.EgressFailover{
+					{FromNode: "node2", ToNode: fakeNode, Time: metav1.NewTime(fakeTime.Add(-time.Hour)), Reason: failoverReasonIPReassigned},
+					{FromNode: fakeNode, Time: metav1.NewTime(fakeTime.Add(-time.Minute)), Reason: failoverReasonIPReleased},
+				},
+			},
+			isLocal:         true,
+			expectedChanged: true,
+			expectedStatus: crdv1a2.EgressStatus{
+				EgressNode: fakeNode,
+				Conditions: assignedEgressStatus().Conditions,
+				FailoverHistory: []crdv1a2.EgressFailover{
+					{FromNode: "node2", ToNode: fakeNode, Time: metav1.NewTime(fakeTime.Add(-time.Hour)), Reason: failoverReasonIPReassigned},
+				},
+			},
+		},
+		{
+			name:            "assigned to another Node",
+			status:          assignedToOtherNode,
+			isLocal:         false,
+			expectedChanged: false,
+			expectedStatus:  assignedToOtherNode,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := &EgressController{nodeName: fakeNode, clock: clock.NewFakeClock(fakeTime)}
+			egress := &crdv1a2.Egress{
+				ObjectMeta: metav1.ObjectMeta{Name: "egressA", UID: "uidA"},
+				Spec:       crdv1a2.EgressSpec{EgressIP: fakeLocalEgressIP1},
+				Status:     *tt.status.DeepCopy(),
+			}
+			changed := c.setEgressStatus(egress, tt.isLocal)
+			assert.Equal(t, tt.expectedChanged, changed)
+			assert.Equal(t, tt.expectedStatus, egress.Status)
+		})
+	}
+}
+
+func TestParseEgressBandwidth(t *testing.T) {
+	tests := []struct {
+		name        string
+		bandwidth   *crdv1a2.Bandwidth
+		expectedQoS *egressQoS
+		expectedErr bool
+	}{
+		{
+			name:        "rate only",
+			bandwidth:   &crdv1a2.Bandwidth{Rate: "100M"},
+			expectedQoS: &egressQoS{rate: 100000, burst: 100000},
+		},
+		{
+			name:        "rate and burst",
+			bandwidth:   &crdv1a2.Bandwidth{Rate: "500k", Burst: "1G"},
+			expectedQoS: &egressQoS{rate: 500, burst: 1000000},
+		},
+		{
+			name:        "rate less than 1 kilobit",
+			bandwidth:   &crdv1a2.Bandwidth{Rate: "100"},
+			expectedQoS: &egressQoS{rate: 1, burst: 1},
+		},
+		{
+			name:        "invalid rate",
+			bandwidth:   &crdv1a2.Bandwidth{Rate: "10Mbps"},
+			expectedErr: true,
+		},
+		{
+			name:        "zero burst",
+			bandwidth:   &crdv1a2.Bandwidth{Rate: "10M", Burst: "0"},
+			expectedErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			qos, err := parseEgressBandwidth(tt.bandwidth)
+			if tt.expectedErr {
+				assert.Error(t, err)
+			} else {
+				require.NoError(t, err)
+				assert.Equal(t, tt.expectedQoS, qos)
+			}
+		})
+	}
+}
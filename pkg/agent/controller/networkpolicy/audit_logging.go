@@ -15,8 +15,11 @@
 package networkpolicy
 
 import (
+	"encoding/json"
 	"fmt"
+	"io"
 	"log"
+	"net"
 	"os"
 	"path/filepath"
 	"strconv"
@@ -25,12 +28,15 @@ import (
 
 	"antrea.io/ofnet/ofctrl"
 	"gopkg.in/natefinch/lumberjack.v2"
+	"k8s.io/apimachinery/pkg/util/wait"
 	"k8s.io/klog/v2"
 
+	"antrea.io/antrea/pkg/agent/interfacestore"
 	"antrea.io/antrea/pkg/agent/openflow"
 	"antrea.io/antrea/pkg/apis/controlplane/v1beta2"
 	binding "antrea.io/antrea/pkg/ovs/openflow"
 	"antrea.io/antrea/pkg/util/ip"
+	"antrea.io/antrea/pkg/util/k8s"
 	"antrea.io/antrea/pkg/util/logdir"
 )
 
@@ -39,6 +45,34 @@ const (
 	logfileName   string = "np.log"
 )
 
+// AuditLogSink is the destination of the audit logs of Antrea-native policies.
+type AuditLogSink string
+
+const (
+	AuditLogSinkFile   AuditLogSink = "file"
+	AuditLogSinkSyslog AuditLogSink = "syslog"
+	AuditLogSinkStdout AuditLogSink = "stdout"
+)
+
+// AuditLogFormat is the format of the audit log records of Antrea-native policies.
+type AuditLogFormat string
+
+const (
+	AuditLogFormatText AuditLogFormat = "text"
+	AuditLogFormatJSON AuditLogFormat = "json"
+)
+
+// AuditLoggingOptions includes the options used to create the AntreaPolicyLogger.
+type AuditLoggingOptions struct {
+	Sink   AuditLogSink
+	Format AuditLogFormat
+	// The address, transport protocol ("udp", "tcp" or "tls") and CA certificate file of the syslog server. They
+	// are used only when Sink is AuditLogSinkSyslog.
+	SyslogAddress    string
+	SyslogProtocol   string
+	SyslogCACertFile string
+}
+
 type Clock interface {
 	Now() time.Time
 	After(d time.Duration) <-chan time.Time
@@ -55,30 +89,71 @@ func (c realClock) After(d time.Duration) <-chan time.Time {
 }
 
 // AntreaPolicyLogger is used for Antrea policy audit logging.
-// Includes a lumberjack logger and a map used for log deduplication.
+// Includes a logger writing to the configured sink and a map used for log deduplication.
 type AntreaPolicyLogger struct {
 	bufferLength     time.Duration
 	clock            Clock // enable the use of a "virtual" clock for unit tests
+	format           AuditLogFormat
 	anpLogger        *log.Logger
 	logDeduplication logRecordDedupMap
+	// suppressionTracker reports the packets which were not logged because they exceeded the log rate of their rules or
+	// were not sampled.
+	// Suppressed packets are not reported if it is nil.
+	suppressionTracker *logSuppressionTracker
 }
 
 // logInfo will be set by retrieving info from packetin and register.
 type logInfo struct {
-	tableName   string // name of the table sending packetin
-	npRef       string // Network Policy name reference for Antrea NetworkPolicy
-	disposition string // Allow/Drop of the rule sending packetin
-	ofPriority  string // openflow priority of the flow sending packetin
-	srcIP       string // source IP of the traffic logged
-	srcPort     string // source port of the traffic logged
-	destIP      string // destination IP of the traffic logged
-	destPort    string // destination port of the traffic logged
-	pktLength   uint16 // packet length of packetin
-	protocolStr string // protocol of the traffic logged
+	tableName   string                          // name of the table sending packetin
+	npRef       string                          // Network Policy name reference for Antrea NetworkPolicy
+	policyRef   *v1beta2.NetworkPolicyReference // Network Policy reference, nil if it is unknown
+	tierName    string                          // name of the Tier of the Network Policy, empty for K8s NetworkPolicy
+	ruleName    string                          // name of the rule sending packetin
+	disposition string                          // Allow/Drop of the rule sending packetin
+	ofPriority  string                          // openflow priority of the flow sending packetin
+	srcIP       string                          // source IP of the traffic logged
+	srcPort     string                          // source port of the traffic logged
+	srcPod      string                          // Namespace/name of the source Pod if it runs on this Node
+	destIP      string                          // destination IP of the traffic logged
+	destPort    string                          // destination port of the traffic logged
+	destPod     string                          // Namespace/name of the destination Pod if it runs on this Node
+	pktLength   uint16                          // packet length of packetin
+	protocolStr string                          // protocol of the traffic logged
+	logLimitID  uint32                          // conjunction ID of the rule if its logging is limited per rule, otherwise 0
+}
+
+// auditLogRecord is the JSON representation of an audit log record.
+type auditLogRecord struct {
+	Timestamp       string `json:"timestamp"`
+	TableName       string `json:"tableName"`
+	PolicyType      string `json:"policyType,omitempty"`
+	PolicyNamespace string `json:"policyNamespace,omitempty"`
+	PolicyName      string `json:"policyName,omitempty"`
+	PolicyUID       string `json:"policyUID,omitempty"`
+	Tier            string `json:"tier,omitempty"`
+	RuleName        string `json:"ruleName,omitempty"`
+	Action          string `json:"action"`
+	OFPriority      string `json:"ofPriority"`
+	SourceIP        string `json:"sourceIP"`
+	SourcePort      int    `json:"sourcePort,omitempty"`
+	SourcePod       string `json:"sourcePod,omitempty"`
+	DestinationIP   string `json:"destinationIP"`
+	DestinationPort int    `json:"destinationPort,omitempty"`
+	DestinationPod  string `json:"destinationPod,omitempty"`
+	Protocol        string `json:"protocol"`
+	PacketLength    uint16 `json:"packetLength"`
+	// Count is the number of packets deduplicated into this record, and Duration is the time elapsed between the
+	// first packet and the time the record is logged. Duration is empty when Count is 1.
+	Count    int64  `json:"count"`
+	Duration string `json:"duration,omitempty"`
+	// SuppressedPackets is the number of packets matching the rule which were not logged since the previous record of
+	// the rule, because they exceeded the log rate of the rule.
+	SuppressedPackets uint64 `json:"suppressedPackets,omitempty"`
 }
 
 // logDedupRecord will be used as 1 sec buffer for log deduplication.
 type logDedupRecord struct {
+	info          *logInfo         // log info of the first packet
 	count         int64            // record count of duplicate log
 	initTime      time.Time        // initial time upon receiving packet log
 	bufferTimerCh <-chan time.Time // 1 sec buffer for each log
@@ -104,28 +179,151 @@ func (l *AntreaPolicyLogger) logAfterTimer(logMsg string) {
 	l.terminateLogKey(logMsg)
 }
 
-// terminateLogKey logs and deletes the log record in logDeduplication map by logMsg.
+// terminateLogKey deletes the log record in logDeduplication map by logMsg and logs it. The record is logged after
+// releasing the mutex, as writing it may dump OVS stats or block on the sink.
 func (l *AntreaPolicyLogger) terminateLogKey(logMsg string) {
 	l.logDeduplication.logMutex.Lock()
-	defer l.logDeduplication.logMutex.Unlock()
 	logRecord := l.logDeduplication.logMap[logMsg]
-	if logRecord.count == 1 {
-		l.anpLogger.Printf(logMsg)
+	delete(l.logDeduplication.logMap, logMsg)
+	l.logDeduplication.logMutex.Unlock()
+	var duration time.Duration
+	if logRecord.count > 1 {
+		duration = time.Since(logRecord.initTime)
+	}
+	l.writeLog(logRecord.info, logMsg, logRecord.count, duration)
+}
+
+// writeLog writes a log record for count packets with the log info ob in the configured format. logMsg is the text
+// representation of ob, and duration is the time elapsed since the first packet if count is larger than 1.
+func (l *AntreaPolicyLogger) writeLog(ob *logInfo, logMsg string, count int64, duration time.Duration) {
+	var suppressed uint64
+	if ob.logLimitID != 0 && l.suppressionTracker != nil {
+		suppressed = l.suppressionTracker.popSuppressedPackets(ob.logLimitID)
+	}
+	msg := logMsg
+	if l.format == AuditLogFormatJSON {
+		record := newAuditLogRecord(ob, l.clock.Now(), count, duration)
+		record.SuppressedPackets = suppressed
+		data, err := json.Marshal(record)
+		if err != nil {
+			klog.ErrorS(err, "Failed to marshal audit log record")
+			return
+		}
+		msg = string(data)
 	} else {
-		l.anpLogger.Printf("%s [%d packets in %s]", logMsg, logRecord.count, time.Since(logRecord.initTime))
+		if count > 1 {
+			msg = fmt.Sprintf("%s [%d packets in %s]", msg, count, duration)
+		}
+		if suppressed > 0 {
+			msg = fmt.Sprintf("%s [%d packets suppressed]", msg, suppressed)
+		}
 	}
-	delete(l.logDeduplication.logMap, logMsg)
+	if err := l.anpLogger.Output(2, msg); err != nil {
+		klog.ErrorS(err, "Failed to write audit log record")
+	}
+}
+
+// newAuditLogRecord returns the JSON representation of the log info ob.
+func newAuditLogRecord(ob *logInfo, now time.Time, count int64, duration time.Duration) *auditLogRecord {
+	record := &auditLogRecord{
+		Timestamp:      now.UTC().Format(time.RFC3339Nano),
+		TableName:      ob.tableName,
+		Tier:           ob.tierName,
+		RuleName:       ob.ruleName,
+		Action:         ob.disposition,
+		OFPriority:     ob.ofPriority,
+		SourceIP:       ob.srcIP,
+		SourcePod:      ob.srcPod,
+		DestinationIP:  ob.destIP,
+		DestinationPod: ob.destPod,
+		Protocol:       ob.protocolStr,
+		PacketLength:   ob.pktLength,
+		Count:          count,
+	}
+	if ob.policyRef != nil {
+		record.PolicyType = string(ob.policyRef.Type)
+		record.PolicyNamespace = ob.policyRef.Namespace
+		record.PolicyName = ob.policyRef.Name
+		record.PolicyUID = string(ob.policyRef.UID)
+	}
+	// Ports are placeholders for packets without port numbers, in which case they are omitted.
+	record.SourcePort, _ = strconv.Atoi(ob.srcPort)
+	record.DestinationPort, _ = strconv.Atoi(ob.destPort)
+	if count > 1 {
+		record.Duration = duration.String()
+	}
+	return record
+}
+
+// logSuppressionTracker tracks the number of packets dropped by the log meters of policy rules or not selected by the
+// log groups of policy rules, i.e. the packets which were not sent to the agent because they exceeded the log rate of
+// the rules or were not sampled. It reports the packets suppressed since the previous report for each rule.
+type logSuppressionTracker struct {
+	mutex sync.Mutex
+	clock Clock
+	// getSuppressedPackets returns the cumulative number of packets suppressed for each rule, keyed by conjunction ID.
+	getSuppressedPackets func() (map[uint32]uint64, error)
+	// suppressedPackets caches the result of getSuppressedPackets to avoid dumping meter and group stats for every log record.
+	suppressedPackets map[uint32]uint64
+	lastUpdateTime    time.Time
+	// reportedPackets is the cumulative number of suppressed packets which have been reported for each rule.
+	reportedPackets map[uint32]uint64
+}
+
+const logSuppressionStatsInterval = time.Second
+
+func newLogSuppressionTracker(getSuppressedPackets func() (map[uint32]uint64, error), clock Clock) *logSuppressionTracker {
+	return &logSuppressionTracker{
+		clock:                clock,
+		getSuppressedPackets: getSuppressedPackets,
+		suppressedPackets:    map[uint32]uint64{},
+		reportedPackets:      map[uint32]uint64{},
+	}
+}
+
+// popSuppressedPackets returns the number of packets suppressed for the rule with the given conjunction ID since the
+// previous call for the same rule.
+func (t *logSuppressionTracker) popSuppressedPackets(conjID uint32) uint64 {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+	now := t.clock.Now()
+	if now.Sub(t.lastUpdateTime) >= logSuppressionStatsInterval {
+		suppressedPackets, err := t.getSuppressedPackets()
+		if err != nil {
+			klog.ErrorS(err, "Failed to get the number of suppressed packets of policy rules")
+		} else {
+			t.suppressedPackets = suppressedPackets
+			t.lastUpdateTime = now
+			// Forget the rules whose meters have been removed.
+			for id := range t.reportedPackets {
+				if _, exists := t.suppressedPackets[id]; !exists {
+					delete(t.reportedPackets, id)
+				}
+			}
+		}
+	}
+	current, exists := t.suppressedPackets[conjID]
+	if !exists {
+		return 0
+	}
+	reported := t.reportedPackets[conjID]
+	// The counter was reset, which happens when the meter is re-installed, e.g. after OVS restarts.
+	if current < reported {
+		reported = 0
+	}
+	t.reportedPackets[conjID] = current
+	return current - reported
 }
 
 // updateLogKey initiates record or increases the count in logDeduplication corresponding to given logMsg.
-func (l *AntreaPolicyLogger) updateLogKey(logMsg string, bufferLength time.Duration) bool {
+func (l *AntreaPolicyLogger) updateLogKey(logMsg string, ob *logInfo, bufferLength time.Duration) bool {
 	l.logDeduplication.logMutex.Lock()
 	defer l.logDeduplication.logMutex.Unlock()
 	_, exists := l.logDeduplication.logMap[logMsg]
 	if exists {
 		l.logDeduplication.logMap[logMsg].count++
 	} else {
-		record := logDedupRecord{1, l.clock.Now(), l.clock.After(bufferLength)}
+		record := logDedupRecord{ob, 1, l.clock.Now(), l.clock.After(bufferLength)}
 		l.logDeduplication.logMap[logMsg] = &record
 	}
 	return exists
@@ -136,10 +334,10 @@ func (l *AntreaPolicyLogger) LogDedupPacket(ob *logInfo) {
 	// Deduplicate non-Allow packet log.
 	logMsg := fmt.Sprintf("%s %s %s %s %s %s %s %s %s %d", ob.tableName, ob.npRef, ob.disposition, ob.ofPriority, ob.srcIP, ob.srcPort, ob.destIP, ob.destPort, ob.protocolStr, ob.pktLength)
 	if ob.disposition == openflow.DispositionToString[openflow.DispositionAllow] {
-		l.anpLogger.Printf(logMsg)
+		l.writeLog(ob, logMsg, 1, 0)
 	} else {
 		// Increase count if duplicated within 1 sec, create buffer otherwise.
-		exists := l.updateLogKey(logMsg, l.bufferLength)
+		exists := l.updateLogKey(logMsg, ob, l.bufferLength)
 		if !exists {
 			// Go routine for logging when buffer timer stops.
 			go l.logAfterTimer(logMsg)
@@ -149,32 +347,55 @@ func (l *AntreaPolicyLogger) LogDedupPacket(ob *logInfo) {
 
 // newAntreaPolicyLogger is called while newing Antrea network policy agent controller.
 // Customize AntreaPolicyLogger specifically for Antrea Policies audit logging.
-func newAntreaPolicyLogger() (*AntreaPolicyLogger, error) {
-	logDir := filepath.Join(logdir.GetLogDir(), logfileSubdir)
-	logFile := filepath.Join(logDir, logfileName)
-	_, err := os.Stat(logDir)
-	if os.IsNotExist(err) {
-		os.Mkdir(logDir, 0755)
-	} else if err != nil {
-		return nil, fmt.Errorf("received error while accessing Antrea network policy log directory: %v", err)
+func newAntreaPolicyLogger(options *AuditLoggingOptions) (*AntreaPolicyLogger, error) {
+	var logOutput io.Writer
+	// The timestamp is included in the record itself for the JSON format, and in the message header for syslog.
+	logFlags := 0
+	if options.Format != AuditLogFormatJSON {
+		logFlags = log.Ldate | log.Lmicroseconds
 	}
+	switch options.Sink {
+	case AuditLogSinkSyslog:
+		writer, err := newSyslogWriter(options.SyslogProtocol, options.SyslogAddress, options.SyslogCACertFile)
+		if err != nil {
+			return nil, fmt.Errorf("error when creating syslog writer for Antrea network policy audit logging: %v", err)
+		}
+		// The writer lives as long as antrea-agent.
+		go writer.run(wait.NeverStop)
+		logOutput = writer
+		logFlags = 0
+		klog.InfoS("Initialized Antrea-native Policy Logger for audit logging", "sink", options.Sink, "format", options.Format, "protocol", options.SyslogProtocol, "address", options.SyslogAddress)
+	case AuditLogSinkStdout:
+		logOutput = os.Stdout
+		klog.InfoS("Initialized Antrea-native Policy Logger for audit logging", "sink", options.Sink, "format", options.Format)
+	default:
+		logDir := filepath.Join(logdir.GetLogDir(), logfileSubdir)
+		logFile := filepath.Join(logDir, logfileName)
+		_, err := os.Stat(logDir)
+		if os.IsNotExist(err) {
+			os.Mkdir(logDir, 0755)
+		} else if err != nil {
+			return nil, fmt.Errorf("received error while accessing Antrea network policy log directory: %v", err)
+		}
 
-	// Use lumberjack log file rotation.
-	logOutput := &lumberjack.Logger{
-		Filename:   logFile,
-		MaxSize:    500,  // allow max 500 megabytes for one log file
-		MaxBackups: 3,    // allow max 3 old log file backups
-		MaxAge:     28,   // allow max 28 days maintenance of old log files
-		Compress:   true, // compress the old log files for backup
+		// Use lumberjack log file rotation.
+		logOutput = &lumberjack.Logger{
+			Filename:   logFile,
+			MaxSize:    500,  // allow max 500 megabytes for one log file
+			MaxBackups: 3,    // allow max 3 old log file backups
+			MaxAge:     28,   // allow max 28 days maintenance of old log files
+			Compress:   true, // compress the old log files for backup
+		}
+		klog.InfoS("Initialized Antrea-native Policy Logger for audit logging", "logFile", logFile, "format", options.Format)
 	}
 
 	antreaPolicyLogger := &AntreaPolicyLogger{
 		bufferLength:     time.Second,
 		clock:            &realClock{},
-		anpLogger:        log.New(logOutput, "", log.Ldate|log.Lmicroseconds),
+		format:           options.Format,
+		anpLogger:        log.New(logOutput, "", logFlags),
 		logDeduplication: logRecordDedupMap{logMap: make(map[string]*logDedupRecord)},
 	}
-	klog.InfoS("Initialized Antrea-native Policy Logger for audit logging", "logFile", logFile)
 	return antreaPolicyLogger, nil
 }
 
@@ -203,14 +424,50 @@ func getNetworkPolicyInfo(pktIn *ofctrl.PacketIn, c *Controller, ob *logInfo) er
 		if err != nil {
 			return fmt.Errorf("received error while unloading conjunction id from reg: %v", err)
 		}
-		ob.npRef, ob.ofPriority = c.ofClient.GetPolicyInfoFromConjunction(info)
+		ob.policyRef, ob.ofPriority, ob.ruleName = c.ofClient.GetPolicyInfoFromConjunction(info)
+		if ob.policyRef != nil {
+			ob.npRef = ob.policyRef.ToString()
+			if policy := c.ruleCache.getNetworkPolicy(string(ob.policyRef.UID)); policy != nil {
+				ob.tierName = policy.Tier
+			}
+			if c.isLogLimited(string(ob.policyRef.UID), ob.ruleName) {
+				ob.logLimitID = info
+			}
+		}
 	} else {
 		// For K8s NetworkPolicy implicit drop action, we cannot get Namespace/name.
 		ob.npRef, ob.ofPriority = string(v1beta2.K8sNetworkPolicy), "-1"
+		ob.policyRef = &v1beta2.NetworkPolicyReference{Type: v1beta2.K8sNetworkPolicy}
 	}
 	return nil
 }
 
+// isLogLimited returns whether the logging of the rule with the given name in the policy is rate limited or sampled.
+func (c *Controller) isLogLimited(policyUID, ruleName string) bool {
+	if ruleName == "" {
+		return false
+	}
+	for _, r := range c.ruleCache.getEffectiveRulesByNetworkPolicy(policyUID) {
+		if r.Name == ruleName && (r.LogRate != nil || r.LogSamplingRatio != nil) {
+			return true
+		}
+	}
+	return false
+}
+
+// getLocalPodName returns the Namespace/name of the Pod running on this Node with the provided IP, or an empty string
+// if there is no such Pod.
+func (c *Controller) getLocalPodName(ip net.IP) string {
+	if c.ifaceStore == nil {
+		return ""
+	}
+	iface, ok := c.ifaceStore.GetInterfaceByIP(ip.String())
+	if !ok || iface.Type != interfacestore.ContainerInterface {
+		return ""
+	}
+	return k8s.NamespacedName(iface.PodNamespace, iface.PodName)
+}
+
 // logPacket retrieves information from openflow reg, controller cache, packet-in
 // packet to log. Log is deduplicated for non-Allow packets from record in logDeduplication.
 // Deduplication is safe guarded by logRecordDedupMap mutex.
@@ -228,6 +485,8 @@ func (c *Controller) logPacket(pktIn *ofctrl.PacketIn) error {
 	}
 	ob.srcIP = packet.SourceIP.String()
 	ob.destIP = packet.DestinationIP.String()
+	ob.srcPod = c.getLocalPodName(packet.SourceIP)
+	ob.destPod = c.getLocalPodName(packet.DestinationIP)
 	ob.pktLength = packet.IPLength
 	ob.protocolStr = ip.IPProtocolNumberToString(packet.IPProto, "UnknownProtocol")
 	if ob.protocolStr == "TCP" || ob.protocolStr == "UDP" {
@@ -0,0 +1,199 @@
+// Copyright 2022 Antrea Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package networkpolicy
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"net"
+	"os"
+	"strings"
+	"time"
+
+	"k8s.io/klog/v2"
+
+	"antrea.io/antrea/pkg/agent/metrics"
+)
+
+const (
+	syslogProtocolUDP = "udp"
+	syslogProtocolTCP = "tcp"
+	syslogProtocolTLS = "tls"
+
+	// The facility is local0 and the severity is informational.
+	syslogPriority = 16*8 + 6
+	syslogVersion  = 1
+	syslogAppName  = "antrea-agent"
+	syslogMsgID    = "networkpolicy"
+	// RFC 5424 allows at most 6 digits for the fractional part of the timestamp.
+	syslogTimestampFormat = "2006-01-02T15:04:05.000000Z07:00"
+
+	syslogDialTimeout  = 5 * time.Second
+	syslogWriteTimeout = 5 * time.Second
+
+	// syslogQueueSize is the maximum number of messages waiting to be sent to the syslog server. Messages written when
+	// the queue is full are dropped.
+	syslogQueueSize = 1024
+	// The initial and maximum delays before reconnecting to the syslog server after a connection failure.
+	syslogMinReconnectDelay = 1 * time.Second
+	syslogMaxReconnectDelay = 60 * time.Second
+)
+
+// syslogWriter implements io.Writer. It sends each written log record as a RFC 5424 syslog message to the syslog
+// server over UDP, TCP or TLS. Messages sent over TCP and TLS are framed with octet counting as described in RFC 5425
+// and RFC 6587. Write only adds the message to a bounded queue, which is consumed by run, so that the audit logging
+// never blocks the packet-in handling on the network. The connection is established lazily and re-established with
+// an exponential backoff after a failure, so that the audit logging doesn't prevent antrea-agent from starting when
+// the syslog server is not available. Messages written when the queue is full are dropped and counted by the metric
+// antrea_agent_networkpolicy_audit_log_dropped_record_count.
+type syslogWriter struct {
+	protocol  string
+	address   string
+	tlsConfig *tls.Config
+	hostname  string
+	procID    string
+	queue     chan []byte
+	// conn and reconnectDelay are only accessed by run.
+	conn           net.Conn
+	reconnectDelay time.Duration
+	now            func() time.Time // returns the timestamp of messages, replaceable in unit tests
+}
+
+func newSyslogWriter(protocol, address, caCertFile string) (*syslogWriter, error) {
+	if _, _, err := net.SplitHostPort(address); err != nil {
+		return nil, fmt.Errorf("invalid syslog server address %s: %v", address, err)
+	}
+	w := &syslogWriter{
+		protocol:       protocol,
+		address:        address,
+		procID:         fmt.Sprint(os.Getpid()),
+		queue:          make(chan []byte, syslogQueueSize),
+		reconnectDelay: syslogMinReconnectDelay,
+		now:            time.Now,
+	}
+	switch protocol {
+	case syslogProtocolUDP, syslogProtocolTCP:
+	case syslogProtocolTLS:
+		w.tlsConfig = &tls.Config{MinVersion: tls.VersionTLS12}
+		if caCertFile != "" {
+			caCert, err := os.ReadFile(caCertFile)
+			if err != nil {
+				return nil, fmt.Errorf("error when reading CA certificate file %s: %v", caCertFile, err)
+			}
+			caPool := x509.NewCertPool()
+			if !caPool.AppendCertsFromPEM(caCert) {
+				return nil, fmt.Errorf("no valid certificate found in CA certificate file %s", caCertFile)
+			}
+			w.tlsConfig.RootCAs = caPool
+		}
+	default:
+		return nil, fmt.Errorf("unsupported syslog protocol %s", protocol)
+	}
+	hostname, err := os.Hostname()
+	if err != nil || hostname == "" {
+		// NILVALUE as defined in RFC 5424.
+		hostname = "-"
+	}
+	w.hostname = hostname
+	return w, nil
+}
+
+func (w *syslogWriter) connect() (net.Conn, error) {
+	dialer := &net.Dialer{Timeout: syslogDialTimeout}
+	if w.protocol == syslogProtocolTLS {
+		return tls.DialWithDialer(dialer, "tcp", w.address, w.tlsConfig)
+	}
+	return dialer.Dial(w.protocol, w.address)
+}
+
+// formatMessage returns the RFC 5424 syslog message for the log record msg, framed according to the transport
+// protocol.
+func (w *syslogWriter) formatMessage(msg string) string {
+	// HEADER SP STRUCTURED-DATA SP MSG, where structured data is NILVALUE.
+	message := fmt.Sprintf("<%d>%d %s %s %s %s %s - %s", syslogPriority, syslogVersion, w.now().Format(syslogTimestampFormat),
+		w.hostname, syslogAppName, w.procID, syslogMsgID, msg)
+	if w.protocol == syslogProtocolUDP {
+		return message
+	}
+	return fmt.Sprintf("%d %s", len(message), message)
+}
+
+// Write queues p to be sent as a syslog message. The trailing newline added by log.Logger is removed. The message is
+// dropped if the queue is full, without returning an error, as log.Logger ignores it anyway.
+func (w *syslogWriter) Write(p []byte) (int, error) {
+	msg := strings.TrimSuffix(string(p), "\n")
+	data := []byte(w.formatMessage(msg))
+	select {
+	case w.queue <- data:
+	default:
+		metrics.NetworkPolicyAuditLogDroppedRecords.Inc()
+	}
+	return len(p), nil
+}
+
+// run sends the queued messages to the syslog server until stopCh is closed.
+func (w *syslogWriter) run(stopCh <-chan struct{}) {
+	defer func() {
+		if w.conn != nil {
+			w.conn.Close()
+		}
+	}()
+	for {
+		select {
+		case <-stopCh:
+			return
+		case data := <-w.queue:
+			if !w.send(data, stopCh) {
+				metrics.NetworkPolicyAuditLogDroppedRecords.Inc()
+			}
+		}
+	}
+}
+
+// send sends data to the syslog server. If the server is not reachable, it keeps reconnecting with an exponential
+// backoff, while the following messages wait in the queue. It retries once with a new connection if the write fails,
+// in case the previous connection was closed by the server, and gives up on the message if the write fails again.
+func (w *syslogWriter) send(data []byte, stopCh <-chan struct{}) bool {
+	for i := 0; i < 2; i++ {
+		for w.conn == nil {
+			conn, err := w.connect()
+			if err == nil {
+				w.conn = conn
+				w.reconnectDelay = syslogMinReconnectDelay
+				break
+			}
+			klog.ErrorS(err, "Failed to connect to syslog server, will retry", "address", w.address, "delay", w.reconnectDelay)
+			select {
+			case <-stopCh:
+				return false
+			case <-time.After(w.reconnectDelay):
+			}
+			w.reconnectDelay *= 2
+			if w.reconnectDelay > syslogMaxReconnectDelay {
+				w.reconnectDelay = syslogMaxReconnectDelay
+			}
+		}
+		w.conn.SetWriteDeadline(time.Now().Add(syslogWriteTimeout))
+		_, err := w.conn.Write(data)
+		if err == nil {
+			return true
+		}
+		klog.ErrorS(err, "Failed to send message to syslog server", "address", w.address)
+		w.conn.Close()
+		w.conn = nil
+	}
+	return false
+}
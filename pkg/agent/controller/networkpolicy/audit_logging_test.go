@@ -15,9 +15,13 @@
 package networkpolicy
 
 import (
+	"bufio"
+	"encoding/json"
 	"errors"
 	"fmt"
+	"io"
 	"log"
+	"net"
 	"strconv"
 	"strings"
 	"sync"
@@ -26,6 +30,8 @@ import (
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
+
+	"antrea.io/antrea/pkg/apis/controlplane/v1beta2"
 )
 
 const (
@@ -101,10 +107,15 @@ func (c *virtualClock) After(d time.Duration) <-chan time.Time {
 }
 
 func newTestAntreaPolicyLogger(bufferLength time.Duration, clock Clock) (*AntreaPolicyLogger, *mockLogger) {
+	return newTestAntreaPolicyLoggerWithFormat(bufferLength, clock, AuditLogFormatText)
+}
+
+func newTestAntreaPolicyLoggerWithFormat(bufferLength time.Duration, clock Clock, format AuditLogFormat) (*AntreaPolicyLogger, *mockLogger) {
 	mockAnpLogger := &mockLogger{logged: make(chan string, 100)}
 	antreaLogger := &AntreaPolicyLogger{
 		bufferLength:     bufferLength,
 		clock:            clock,
+		format:           format,
 		anpLogger:        log.New(mockAnpLogger, "", log.Ldate),
 		logDeduplication: logRecordDedupMap{logMap: make(map[string]*logDedupRecord)},
 	}
@@ -113,12 +124,21 @@ func newTestAntreaPolicyLogger(bufferLength time.Duration, clock Clock) (*Antrea
 
 func newLogInfo(disposition string) (*logInfo, string) {
 	testLogInfo := &logInfo{
-		tableName:   "AntreaPolicyIngressRule",
-		npRef:       "AntreaNetworkPolicy:default/test",
+		tableName: "AntreaPolicyIngressRule",
+		npRef:     "AntreaNetworkPolicy:default/test",
+		policyRef: &v1beta2.NetworkPolicyReference{
+			Type:      v1beta2.AntreaNetworkPolicy,
+			Namespace: "default",
+			Name:      "test",
+			UID:       "uid1",
+		},
+		tierName:    "application",
+		ruleName:    "rule1",
 		ofPriority:  "0",
 		disposition: disposition,
 		srcIP:       "0.0.0.0",
 		srcPort:     "35402",
+		srcPod:      "default/pod1",
 		destIP:      "1.1.1.1",
 		destPort:    "80",
 		protocolStr: "TCP",
@@ -220,3 +240,246 @@ func TestDropPacketMultiDedupLog(t *testing.T) {
 	require.NoError(t, err)
 	assert.Equal(t, 1, c2)
 }
+
+func TestJSONPacketDedupLog(t *testing.T) {
+	clock := NewVirtualClock(time.Now())
+	defer clock.Stop()
+	antreaLogger, mockAnpLogger := newTestAntreaPolicyLoggerWithFormat(testBufferLength, clock, AuditLogFormatJSON)
+	ob, _ := newLogInfo("Drop")
+
+	antreaLogger.LogDedupPacket(ob)
+	clock.Advance(time.Millisecond)
+	antreaLogger.LogDedupPacket(ob)
+	clock.Advance(testBufferLength)
+	actual := <-mockAnpLogger.logged
+	// Remove the date prefix added by the test logger.
+	actual = actual[strings.Index(actual, "{"):]
+	record := &auditLogRecord{}
+	require.NoError(t, json.Unmarshal([]byte(actual), record))
+	assert.NotEmpty(t, record.Timestamp)
+	assert.NotEmpty(t, record.Duration)
+	record.Timestamp, record.Duration = "", ""
+	assert.Equal(t, &auditLogRecord{
+		TableName:       "AntreaPolicyIngressRule",
+		PolicyType:      "AntreaNetworkPolicy",
+		PolicyNamespace: "default",
+		PolicyName:      "test",
+		PolicyUID:       "uid1",
+		Tier:            "application",
+		RuleName:        "rule1",
+		Action:          "Drop",
+		OFPriority:      "0",
+		SourceIP:        "0.0.0.0",
+		SourcePort:      35402,
+		SourcePod:       "default/pod1",
+		DestinationIP:   "1.1.1.1",
+		DestinationPort: 80,
+		Protocol:        "TCP",
+		PacketLength:    60,
+		Count:           2,
+	}, record)
+}
+
+func TestJSONPacketLogWithoutPorts(t *testing.T) {
+	antreaLogger, mockAnpLogger := newTestAntreaPolicyLoggerWithFormat(testBufferLength, &realClock{}, AuditLogFormatJSON)
+	ob, _ := newLogInfo("Allow")
+	ob.protocolStr = "ICMP"
+	ob.srcPort, ob.destPort = "<nil>", "<nil>"
+
+	antreaLogger.LogDedupPacket(ob)
+	actual := <-mockAnpLogger.logged
+	assert.NotContains(t, actual, "sourcePort")
+	assert.NotContains(t, actual, "destinationPort")
+	assert.NotContains(t, actual, "duration")
+	assert.Contains(t, actual, `"protocol":"ICMP"`)
+	assert.Contains(t, actual, `"count":1`)
+}
+
+func TestLogSuppressionTracker(t *testing.T) {
+	clock := NewVirtualClock(time.Now())
+	defer clock.Stop()
+	stats := map[uint32]uint64{1: 10, 2: 5}
+	calls := 0
+	tracker := newLogSuppressionTracker(func() (map[uint32]uint64, error) {
+		calls++
+		result := make(map[uint32]uint64, len(stats))
+		for k, v := range stats {
+			result[k] = v
+		}
+		return result, nil
+	}, clock)
+
+	assert.Equal(t, uint64(10), tracker.popSuppressedPackets(1))
+	assert.Equal(t, uint64(5), tracker.popSuppressedPackets(2))
+	assert.Equal(t, uint64(0), tracker.popSuppressedPackets(3))
+	// The stats are cached within the interval.
+	stats[1] = 15
+	assert.Equal(t, uint64(0), tracker.popSuppressedPackets(1))
+	assert.Equal(t, 1, calls)
+
+	clock.Advance(logSuppressionStatsInterval)
+	assert.Equal(t, uint64(5), tracker.popSuppressedPackets(1))
+	assert.Equal(t, 2, calls)
+	// The counter is reset when the meter is re-installed.
+	stats[1] = 3
+	clock.Advance(logSuppressionStatsInterval)
+	assert.Equal(t, uint64(3), tracker.popSuppressedPackets(1))
+	// Rules whose meters were removed are forgotten.
+	delete(stats, 2)
+	clock.Advance(logSuppressionStatsInterval)
+	assert.Equal(t, uint64(0), tracker.popSuppressedPackets(2))
+	assert.NotContains(t, tracker.reportedPackets, uint32(2))
+}
+
+func TestDropPacketLogWithSuppressedPackets(t *testing.T) {
+	for _, format := range []AuditLogFormat{AuditLogFormatText, AuditLogFormatJSON} {
+		t.Run(string(format), func(t *testing.T) {
+			antreaLogger, mockAnpLogger := newTestAntreaPolicyLoggerWithFormat(testBufferLength, &realClock{}, format)
+			antreaLogger.suppressionTracker = newLogSuppressionTracker(func() (map[uint32]uint64, error) {
+				// OVS stats must not be dumped while holding the deduplication mutex.
+				if assert.True(t, antreaLogger.logDeduplication.logMutex.TryLock()) {
+					antreaLogger.logDeduplication.logMutex.Unlock()
+				}
+				return map[uint32]uint64{7: 20}, nil
+			}, antreaLogger.clock)
+			ob, expected := newLogInfo("Drop")
+			ob.logLimitID = 7
+
+			antreaLogger.LogDedupPacket(ob)
+			actual := <-mockAnpLogger.logged
+			if format == AuditLogFormatJSON {
+				assert.Contains(t, actual, `"suppressedPackets":20`)
+			} else {
+				assert.Contains(t, actual, expected+" [20 packets suppressed]")
+			}
+		})
+	}
+}
+
+func TestSyslogWriter(t *testing.T) {
+	testTime := time.Date(2022, 5, 1, 10, 20, 30, 123456789, time.UTC)
+	expectedMessage := func(w *syslogWriter, msg string) string {
+		return fmt.Sprintf("<134>1 2022-05-01T10:20:30.123456Z %s antrea-agent %s networkpolicy - %s", w.hostname, w.procID, msg)
+	}
+
+	t.Run("udp", func(t *testing.T) {
+		conn, err := net.ListenPacket("udp", "127.0.0.1:0")
+		require.NoError(t, err)
+		defer conn.Close()
+		w, err := newSyslogWriter("udp", conn.LocalAddr().String(), "")
+		require.NoError(t, err)
+		w.now = func() time.Time { return testTime }
+		stopCh := make(chan struct{})
+		defer close(stopCh)
+		go w.run(stopCh)
+
+		_, err = w.Write([]byte("test message\n"))
+		require.NoError(t, err)
+		buf := make([]byte, 1024)
+		conn.SetReadDeadline(time.Now().Add(5 * time.Second))
+		n, _, err := conn.ReadFrom(buf)
+		require.NoError(t, err)
+		assert.Equal(t, expectedMessage(w, "test message"), string(buf[:n]))
+	})
+
+	t.Run("tcp", func(t *testing.T) {
+		listener, err := net.Listen("tcp", "127.0.0.1:0")
+		require.NoError(t, err)
+		defer listener.Close()
+		w, err := newSyslogWriter("tcp", listener.Addr().String(), "")
+		require.NoError(t, err)
+		w.now = func() time.Time { return testTime }
+		stopCh := make(chan struct{})
+		defer close(stopCh)
+		go w.run(stopCh)
+
+		received := make(chan string, 2)
+		go func() {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			defer conn.Close()
+			reader := bufio.NewReader(conn)
+			for i := 0; i < 2; i++ {
+				var length int
+				if _, err := fmt.Fscanf(reader, "%d ", &length); err != nil {
+					return
+				}
+				msg := make([]byte, length)
+				if _, err := io.ReadFull(reader, msg); err != nil {
+					return
+				}
+				received <- string(msg)
+			}
+		}()
+		for _, msg := range []string{"message 1", "message 2"} {
+			_, err = w.Write([]byte(msg + "\n"))
+			require.NoError(t, err)
+		}
+		for _, msg := range []string{"message 1", "message 2"} {
+			select {
+			case actual := <-received:
+				assert.Equal(t, expectedMessage(w, msg), actual)
+			case <-time.After(5 * time.Second):
+				t.Fatalf("did not receive syslog message in time")
+			}
+		}
+	})
+
+	t.Run("server unavailable", func(t *testing.T) {
+		// Reserve a port with no listener, so that the first connection attempt fails.
+		listener, err := net.Listen("tcp", "127.0.0.1:0")
+		require.NoError(t, err)
+		address := listener.Addr().String()
+		listener.Close()
+		w, err := newSyslogWriter("tcp", address, "")
+		require.NoError(t, err)
+		w.now = func() time.Time { return testTime }
+		stopCh := make(chan struct{})
+		defer close(stopCh)
+		go w.run(stopCh)
+
+		// Write must not block while the writer is waiting to reconnect.
+		_, err = w.Write([]byte("queued message\n"))
+		require.NoError(t, err)
+		time.Sleep(100 * time.Millisecond)
+		listener, err = net.Listen("tcp", address)
+		require.NoError(t, err)
+		defer listener.Close()
+		listener.(*net.TCPListener).SetDeadline(time.Now().Add(5 * time.Second))
+		conn, err := listener.Accept()
+		require.NoError(t, err)
+		defer conn.Close()
+		conn.SetReadDeadline(time.Now().Add(5 * time.Second))
+		var length int
+		reader := bufio.NewReader(conn)
+		_, err = fmt.Fscanf(reader, "%d ", &length)
+		require.NoError(t, err)
+		msg := make([]byte, length)
+		_, err = io.ReadFull(reader, msg)
+		require.NoError(t, err)
+		assert.Equal(t, expectedMessage(w, "queued message"), string(msg))
+	})
+
+	t.Run("queue full", func(t *testing.T) {
+		w, err := newSyslogWriter("udp", "127.0.0.1:514", "")
+		require.NoError(t, err)
+		// The writer is not running, so no message is consumed from the queue.
+		for i := 0; i < syslogQueueSize+10; i++ {
+			n, err := w.Write([]byte("message\n"))
+			require.NoError(t, err)
+			assert.Equal(t, len("message\n"), n)
+		}
+		assert.Len(t, w.queue, syslogQueueSize)
+	})
+
+	t.Run("invalid", func(t *testing.T) {
+		_, err := newSyslogWriter("sctp", "127.0.0.1:514", "")
+		assert.Error(t, err)
+		_, err = newSyslogWriter("udp", "127.0.0.1", "")
+		assert.Error(t, err)
+		_, err = newSyslogWriter("tls", "127.0.0.1:6514", "/non-existent/ca.crt")
+		assert.Error(t, err)
+	})
+}
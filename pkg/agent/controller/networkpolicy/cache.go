@@ -96,6 +96,12 @@ type rule struct {
 	SourceRef *v1beta.NetworkPolicyReference
 	// EnableLogging is a boolean indicating whether logging is required for Antrea Policies. Always false for K8s NetworkPolicy.
 	EnableLogging bool
+	// LogRate is the maximum number of packets logged per second for this rule. It's omitted when empty to keep the
+	// IDs of existing rules unchanged.
+	LogRate *int32 `json:",omitempty"`
+	// LogSamplingRatio indicates that one out of every LogSamplingRatio connections is logged for this rule. It's omitted
+	// when empty to keep the IDs of existing rules unchanged.
+	LogSamplingRatio *int32 `json:",omitempty"`
 }
 
 func (r *rule) Less(r2 *rule) bool {
@@ -669,19 +675,21 @@ func toRule(r *v1beta.NetworkPolicyRule, policy *v1beta.NetworkPolicy, maxPriori
 		appliedToGroups = r.AppliedToGroups
 	}
 	rule := &rule{
-		Direction:       r.Direction,
-		From:            r.From,
-		To:              r.To,
-		Services:        r.Services,
-		Action:          r.Action,
-		Priority:        r.Priority,
-		PolicyPriority:  policy.Priority,
-		TierPriority:    policy.TierPriority,
-		AppliedToGroups: appliedToGroups,
-		Name:            r.Name,
-		PolicyUID:       policy.UID,
-		SourceRef:       policy.SourceRef,
-		EnableLogging:   r.EnableLogging,
+		Direction:        r.Direction,
+		From:             r.From,
+		To:               r.To,
+		Services:         r.Services,
+		Action:           r.Action,
+		Priority:         r.Priority,
+		PolicyPriority:   policy.Priority,
+		TierPriority:     policy.TierPriority,
+		AppliedToGroups:  appliedToGroups,
+		Name:             r.Name,
+		PolicyUID:        policy.UID,
+		SourceRef:        policy.SourceRef,
+		EnableLogging:    r.EnableLogging,
+		LogRate:          r.LogRate,
+		LogSamplingRatio: r.LogSamplingRatio,
 	}
 	rule.ID = hashRule(rule)
 	rule.PolicyName = policy.Name
@@ -15,26 +15,32 @@
 package networkpolicy
 
 import (
+	"bytes"
 	"context"
 	"fmt"
 	"math"
 	"net"
 	"os"
 	"regexp"
+	"sort"
 	"strings"
 	"sync"
 	"time"
 
 	"antrea.io/libOpenflow/protocol"
+	"antrea.io/libOpenflow/util"
 	"antrea.io/ofnet/ofctrl"
 	"github.com/miekg/dns"
 	"k8s.io/apimachinery/pkg/util/sets"
 	"k8s.io/client-go/util/workqueue"
 	"k8s.io/klog/v2"
 
+	"antrea.io/antrea/pkg/agent/flowexporter"
+	"antrea.io/antrea/pkg/agent/flowexporter/l7"
 	"antrea.io/antrea/pkg/agent/openflow"
 	"antrea.io/antrea/pkg/agent/types"
 	binding "antrea.io/antrea/pkg/ovs/openflow"
+	"antrea.io/antrea/pkg/querier"
 	utilsets "antrea.io/antrea/pkg/util/sets"
 )
 
@@ -44,6 +50,13 @@ const (
 
 	ruleRealizationTimeout = 2 * time.Second
 	dnsRequestTimeout      = 10 * time.Second
+
+	// dnsRefreshLeadTimeRatio is the portion of the TTL of DNS records remaining when the fqdnController
+	// proactively re-queries the FQDN, so that the cached records are refreshed before they expire.
+	dnsRefreshLeadTimeRatio = 0.1
+	// minDNSRefreshInterval is the minimum interval between two proactive queries of a FQDN, to avoid
+	// querying continuously records with a TTL of 0 or a few seconds.
+	minDNSRefreshInterval = 1 * time.Second
 )
 
 // fqdnSelectorItem is a selector that selects FQDNs,
@@ -71,14 +84,21 @@ func (fs *fqdnSelectorItem) matches(fqdn string) bool {
 
 // dnsMeta stores the name resolution results of a FQDN,
 // including the IP addresses resolved, as well as the
-// expirationTime of the records, which is the DNS response
-// receiving time plus lowest applicable TTL.
+// expirationTime of each IP address.
 type dnsMeta struct {
-	expirationTime time.Time
 	// Key for responseIPs is the string representation of the IP.
 	// It helps to quickly identify IP address updates when a
 	// new DNS response is received.
-	responseIPs map[string]net.IP
+	responseIPs map[string]ipWithExpiration
+}
+
+// ipWithExpiration stores an IP address resolved for a FQDN, and
+// the expirationTime of the record, which is the receiving time of
+// the latest DNS response including the IP plus the lowest
+// applicable TTL of the response.
+type ipWithExpiration struct {
+	ip             net.IP
+	expirationTime time.Time
 }
 
 // subscriber is a entity that subsribes for datapath rule realization
@@ -128,8 +148,10 @@ type fqdnController struct {
 	ruleSyncTracker *ruleSyncTracker
 	// FQDN names this controller is tracking, with their corresponding dnsMeta.
 	dnsEntryCache map[string]dnsMeta
-	// FQDN names that needs to be re-queried after their respective TTLs.
+	// FQDN names that needs to be re-queried before their respective TTLs expire.
 	dnsQueryQueue workqueue.RateLimitingInterface
+	// tcpStreamTracker reassembles the DNS responses sent over TCP.
+	tcpStreamTracker *dnsTCPStreamTracker
 	// idAllocator provides interfaces to allocateForRule and release uint32 id.
 	idAllocator *idAllocator
 
@@ -150,6 +172,9 @@ type fqdnController struct {
 	ipv4Enabled           bool
 	ipv6Enabled           bool
 	gwPort                uint32
+	// l7EventStore is notified of the DNS responses for L7 flow export. It
+	// is nil if L7 flow export is disabled.
+	l7EventStore *l7.EventStore
 }
 
 func newFQDNController(client openflow.Client, allocator *idAllocator, dnsServerOverride string, dirtyRuleHandler func(string), v4Enabled, v6Enabled bool, gwPort uint32) (*fqdnController, error) {
@@ -160,6 +185,7 @@ func newFQDNController(client openflow.Client, allocator *idAllocator, dnsServer
 		idAllocator:            allocator,
 		dnsQueryQueue:          workqueue.NewNamedRateLimitingQueue(workqueue.NewItemExponentialFailureRateLimiter(minRetryDelay, maxRetryDelay), "fqdn"),
 		dnsEntryCache:          map[string]dnsMeta{},
+		tcpStreamTracker:       newDNSTCPStreamTracker(realClock{}),
 		fqdnRuleToSelectedPods: map[string]sets.Int32{},
 		fqdnToSelectorItem:     map[string]map[fqdnSelectorItem]struct{}{},
 		selectorItemToFQDN:     map[fqdnSelectorItem]sets.String{},
@@ -248,8 +274,8 @@ func (f *fqdnController) getIPsForFQDNSelectors(fqdns []string) []net.IP {
 		}
 		for fqdn := range fqdnsMatched {
 			if dnsMeta, ok := f.dnsEntryCache[fqdn]; ok {
-				for _, ip := range dnsMeta.responseIPs {
-					matchedIPs = append(matchedIPs, ip)
+				for _, ipMeta := range dnsMeta.responseIPs {
+					matchedIPs = append(matchedIPs, ipMeta.ip)
 				}
 			}
 		}
@@ -257,6 +283,80 @@ func (f *fqdnController) getIPsForFQDNSelectors(fqdns []string) []net.IP {
 	return matchedIPs
 }
 
+// getFQDNSelectorInfos returns the fqdnSelectorItems known to the controller, with the FQDNs
+// they match, the IP addresses cached for these FQDNs and the rules using them, sorted by
+// selector. If podOFPorts is not nil, only the fqdnSelectorItems used by the rules selecting
+// these Pods are returned. If domainPattern is not empty, only the FQDNs matching it are
+// returned, and the fqdnSelectorItems which don't match any of them are omitted.
+func (f *fqdnController) getFQDNSelectorInfos(podOFPorts sets.Int32, domainPattern string) ([]querier.FQDNSelectorInfo, error) {
+	var domainRegex *regexp.Regexp
+	if domainPattern != "" {
+		var err error
+		if domainRegex, err = regexp.Compile(toRegex(domainPattern)); err != nil {
+			return nil, fmt.Errorf("invalid domain pattern %s: %v", domainPattern, err)
+		}
+	}
+	var podRuleIDs sets.String
+	if podOFPorts != nil {
+		podRuleIDs = sets.NewString()
+		f.fqdnRuleToPodsMutex.Lock()
+		for ruleID, pods := range f.fqdnRuleToSelectedPods {
+			if pods.HasAny(podOFPorts.UnsortedList()...) {
+				podRuleIDs.Insert(ruleID)
+			}
+		}
+		f.fqdnRuleToPodsMutex.Unlock()
+	}
+
+	f.fqdnSelectorMutex.Lock()
+	defer f.fqdnSelectorMutex.Unlock()
+	var infos []querier.FQDNSelectorInfo
+	for selectorItem, ruleIDs := range f.selectorItemToRuleIDs {
+		if podRuleIDs != nil {
+			ruleIDs = ruleIDs.Intersection(podRuleIDs)
+			if len(ruleIDs) == 0 {
+				continue
+			}
+		}
+		info := querier.FQDNSelectorInfo{
+			Selector: selectorItem.String(),
+			RuleIDs:  ruleIDs.List(),
+		}
+		for _, fqdn := range f.selectorItemToFQDN[selectorItem].List() {
+			if domainRegex != nil && !domainRegex.MatchString(fqdn) {
+				continue
+			}
+			info.MatchedFQDNs = append(info.MatchedFQDNs, fqdn)
+			info.CacheEntries = append(info.CacheEntries, f.getCacheEntries(fqdn)...)
+		}
+		if domainRegex != nil && len(info.MatchedFQDNs) == 0 {
+			continue
+		}
+		infos = append(infos, info)
+	}
+	sort.Slice(infos, func(i, j int) bool {
+		return infos[i].Selector < infos[j].Selector
+	})
+	return infos, nil
+}
+
+// getCacheEntries returns the IP addresses cached for a FQDN with their expiration time,
+// sorted by IP address. fqdnSelectorMutex must have been acquired by the caller.
+func (f *fqdnController) getCacheEntries(fqdn string) []querier.FQDNCacheEntry {
+	var entries []querier.FQDNCacheEntry
+	for _, ipMeta := range f.dnsEntryCache[fqdn].responseIPs {
+		entries = append(entries, querier.FQDNCacheEntry{
+			FQDN:           fqdn,
+			IPAddress:      ipMeta.ip,
+			ExpirationTime: ipMeta.expirationTime,
+		})
+	}
+	sort.Slice(entries, func(i, j int) bool {
+		return bytes.Compare(entries[i].IPAddress, entries[j].IPAddress) < 0
+	})
+	return entries
+}
+
 // addFQDNRule adds a new FQDN rule to fqdnSelectorItem mapping, as well as the OFAddresses of
 // Pods selected by the FQDN rule.
 func (f *fqdnController) addFQDNRule(ruleID string, fqdns []string, podOFAddrs sets.Int32) error {
@@ -414,7 +514,11 @@ func (f *fqdnController) onDNSResponse(
 	// addressUpdate is only true if there has been an update in IP addresses
 	// corresponded with the FQDN.
 	mustCacheResponse, addressUpdate := false, false
-	recordTTL := lookupTime.Add(time.Duration(lowestTTL) * time.Second)
+	expirationTime := lookupTime.Add(time.Duration(lowestTTL) * time.Second)
+	cachedIPs := make(map[string]ipWithExpiration, len(responseIPs))
+	for ipStr, ip := range responseIPs {
+		cachedIPs[ipStr] = ipWithExpiration{ip: ip, expirationTime: expirationTime}
+	}
 
 	f.fqdnSelectorMutex.Lock()
 	defer f.fqdnSelectorMutex.Unlock()
@@ -429,16 +533,13 @@ func (f *fqdnController) onDNSResponse(
 		}
 		for oldIPStr, oldIP := range oldDNSMeta.responseIPs {
 			if _, ok := responseIPs[oldIPStr]; !ok {
-				if oldDNSMeta.expirationTime.Before(time.Now()) {
+				if oldIP.expirationTime.Before(time.Now()) {
 					// This IP entry has already expired and not seen in the latest DNS response.
 					// It should be removed from the cache.
 					addressUpdate = true
 				} else {
-					// Add the unexpired IP entry to responseIP and update the lowest applicable TTL if needed.
-					responseIPs[oldIPStr] = oldIP
-					if oldDNSMeta.expirationTime.Before(recordTTL) {
-						recordTTL = oldDNSMeta.expirationTime
-					}
+					// Keep the unexpired IP entry with its own expiration time.
+					cachedIPs[oldIPStr] = oldIP
 				}
 			}
 		}
@@ -453,14 +554,28 @@ func (f *fqdnController) onDNSResponse(
 	}
 	if mustCacheResponse {
 		f.dnsEntryCache[fqdn] = dnsMeta{
-			expirationTime: recordTTL,
-			responseIPs:    responseIPs,
+			responseIPs: cachedIPs,
 		}
-		f.dnsQueryQueue.AddAfter(fqdn, recordTTL.Sub(time.Now()))
+		// Re-query the FQDN before the records expire, so that the IPs of the FQDN are
+		// refreshed without waiting for a Pod to query it again. Expired IPs not seen in
+		// the new responses will be removed from the cache then.
+		f.dnsQueryQueue.AddAfter(fqdn, dnsRefreshDelay(lookupTime, lowestTTL))
 	}
 	f.syncDirtyRules(fqdn, waitCh, addressUpdate)
 }
 
+// dnsRefreshDelay returns the delay after which a FQDN should be re-queried, given the
+// receiving time and the lowest applicable TTL of a DNS response.
+func dnsRefreshDelay(lookupTime time.Time, lowestTTL uint32) time.Duration {
+	ttl := time.Duration(lowestTTL) * time.Second
+	refreshTime := lookupTime.Add(ttl - time.Duration(float64(ttl)*dnsRefreshLeadTimeRatio))
+	delay := time.Until(refreshTime)
+	if delay < minDNSRefreshInterval {
+		return minDNSRefreshInterval
+	}
+	return delay
+}
+
 // onDNSResponseMsg handles a DNS response message intercepted.
 func (f *fqdnController) onDNSResponseMsg(dnsMsg *dns.Msg, lookupTime time.Time, waitCh chan error) {
 	fqdn, responseIPs, lowestTTL, err := f.parseDNSResponse(dnsMsg)
@@ -746,15 +861,47 @@ func (f *fqdnController) HandlePacketIn(pktIn *ofctrl.PacketIn) error {
 func (f *fqdnController) handlePacketIn(pktIn *ofctrl.PacketIn) error {
 	klog.V(4).InfoS("Received a packetIn for DNS response")
 	waitCh := make(chan error, 1)
-	handleUDPData := func(dnsPkt *protocol.UDP) {
+	handleUDPData := func(srcIP, dstIP net.IP, dnsPkt *protocol.UDP) {
 		dnsData := dnsPkt.Data
 		dnsMsg := dns.Msg{}
 		if err := dnsMsg.Unpack(dnsData); err != nil {
 			waitCh <- err
 			return
 		}
+		f.reportDNSResponse(srcIP, dstIP, protocol.Type_UDP, dnsPkt.PortSrc, dnsPkt.PortDst, &dnsMsg)
 		f.onDNSResponseMsg(&dnsMsg, time.Now(), waitCh)
 	}
+	handleTCPData := func(srcIP, dstIP net.IP, tcpPkt *util.Buffer) {
+		segment, err := parseTCPSegment(tcpPkt.Bytes())
+		if err != nil {
+			waitCh <- err
+			return
+		}
+		// A DNS response sent over TCP may span multiple segments. The segments which don't
+		// complete a DNS response are forwarded immediately, while the one completing it is
+		// only forwarded after the rules are synced, which prevents the client from using the
+		// response before that.
+		key := dnsTCPStreamKey{srcIP: srcIP.String(), srcPort: segment.srcPort, dstIP: dstIP.String(), dstPort: segment.dstPort}
+		for _, dnsData := range f.tcpStreamTracker.addSegment(key, segment) {
+			dnsMsg := dns.Msg{}
+			if err := dnsMsg.Unpack(dnsData); err != nil {
+				// The stream may have been tracked from the middle of a DNS response, e.g. after
+				// antrea-agent restarts. Dropping the segment would only make the server resend it,
+				// so restart the reassembly from the next segment and forward this one.
+				klog.V(2).InfoS("Failed to parse DNS response received over TCP, resetting the stream", "connection", key, "err", err)
+				f.tcpStreamTracker.reset(key)
+				break
+			}
+			f.reportDNSResponse(srcIP, dstIP, protocol.Type_TCP, segment.srcPort, segment.dstPort, &dnsMsg)
+			msgWaitCh := make(chan error, 1)
+			f.onDNSResponseMsg(&dnsMsg, time.Now(), msgWaitCh)
+			if err := <-msgWaitCh; err != nil {
+				waitCh <- err
+				return
+			}
+		}
+		waitCh <- nil
+	}
 	go func() {
 		ethernetPkt, err := getEthernetPacket(pktIn)
 		if err != nil {
@@ -764,12 +911,20 @@ func (f *fqdnController) handlePacketIn(pktIn *ofctrl.PacketIn) error {
 		case *protocol.IPv4:
 			switch dnsPkt := ipPkt.Data.(type) {
 			case *protocol.UDP:
-				handleUDPData(dnsPkt)
+				handleUDPData(ipPkt.NWSrc, ipPkt.NWDst, dnsPkt)
+			case *util.Buffer:
+				if ipPkt.Protocol == protocol.Type_TCP {
+					handleTCPData(ipPkt.NWSrc, ipPkt.NWDst, dnsPkt)
+				}
 			}
 		case *protocol.IPv6:
 			switch dnsPkt := ipPkt.Data.(type) {
 			case *protocol.UDP:
-				handleUDPData(dnsPkt)
+				handleUDPData(ipPkt.NWSrc, ipPkt.NWDst, dnsPkt)
+			case *util.Buffer:
+				if ipPkt.NextHeader == protocol.Type_TCP {
+					handleTCPData(ipPkt.NWSrc, ipPkt.NWDst, dnsPkt)
+				}
 			}
 		}
 	}()
@@ -785,10 +940,27 @@ func (f *fqdnController) handlePacketIn(pktIn *ofctrl.PacketIn) error {
 	}
 }
 
+// reportDNSResponse reports a DNS response to the l7EventStore with the tuple
+// of the connection which carried the query.
+func (f *fqdnController) reportDNSResponse(srcIP, dstIP net.IP, proto uint8, srcPort, dstPort uint16, dnsMsg *dns.Msg) {
+	if f.l7EventStore == nil {
+		return
+	}
+	tuple := flowexporter.Tuple{
+		SourceAddress:      dstIP,
+		DestinationAddress: srcIP,
+		Protocol:           proto,
+		SourcePort:         dstPort,
+		DestinationPort:    srcPort,
+	}
+	f.l7EventStore.AddDNSResponse(tuple, dnsMsg)
+}
+
 // sendDNSPacketout forwards the DNS response packet to the original requesting client.
 func (f *fqdnController) sendDNSPacketout(pktIn *ofctrl.PacketIn) error {
 	var (
 		packetData   []byte
+		l4Packet     util.Message
 		srcIP, dstIP string
 		prot         uint8
 		isIPv6       bool
@@ -803,6 +975,7 @@ func (f *fqdnController) sendDNSPacketout(pktIn *ofctrl.PacketIn) error {
 		dstIP = ipPkt.NWDst.String()
 		prot = ipPkt.Protocol
 		isIPv6 = false
+		l4Packet = ipPkt.Data
 		switch dnsPkt := ipPkt.Data.(type) {
 		case *protocol.UDP:
 			packetData = dnsPkt.Data
@@ -812,31 +985,32 @@ func (f *fqdnController) sendDNSPacketout(pktIn *ofctrl.PacketIn) error {
 		dstIP = ipPkt.NWDst.String()
 		prot = ipPkt.NextHeader
 		isIPv6 = true
+		l4Packet = ipPkt.Data
 		switch dnsPkt := ipPkt.Data.(type) {
 		case *protocol.UDP:
 			packetData = dnsPkt.Data
 		}
 	}
-	if prot == protocol.Type_UDP {
-		inPort := f.gwPort
-		if inPort == 0 {
-			// Use the original in_port number in the packetIn message to avoid an invalid input port number. Note that,
-			// this should not happen in container case as antrea-gw0 always exists. This check is for security.
-			matches := pktIn.GetMatches()
-			inPortField := matches.GetMatchByName("OXM_OF_IN_PORT")
-			if inPortField != nil {
-				inPort = inPortField.GetValue().(uint32)
-			}
-		}
-		udpSrcPort, udpDstPort, err := binding.GetUDPHeaderData(ethernetPkt.Data)
-		if err != nil {
-			klog.ErrorS(err, "Failed to get UDP header data")
-			return err
-		}
-		mutatePacketOut := func(packetOutBuilder binding.PacketOutBuilder) binding.PacketOutBuilder {
-			return packetOutBuilder.AddLoadRegMark(openflow.CustomReasonDNSRegMark)
+	if prot != protocol.Type_UDP && prot != protocol.Type_TCP {
+		return nil
+	}
+	inPort := f.gwPort
+	if inPort == 0 {
+		// Use the original in_port number in the packetIn message to avoid an invalid input port number. Note that,
+		// this should not happen in container case as antrea-gw0 always exists. This check is for security.
+		matches := pktIn.GetMatches()
+		inPortField := matches.GetMatchByName("OXM_OF_IN_PORT")
+		if inPortField != nil {
+			inPort = inPortField.GetValue().(uint32)
 		}
-		return f.ofClient.SendUDPPacketOut(
+	}
+	mutatePacketOut := func(packetOutBuilder binding.PacketOutBuilder) binding.PacketOutBuilder {
+		return packetOutBuilder.AddLoadRegMark(openflow.CustomReasonDNSRegMark)
+	}
+	if prot == protocol.Type_TCP {
+		// The TCP segment is forwarded as it is, so that its sequence numbers, options and checksum
+		// are preserved.
+		return f.ofClient.SendL4PacketOut(
 			ethernetPkt.HWSrc.String(),
 			ethernetPkt.HWDst.String(),
 			srcIP,
@@ -844,10 +1018,25 @@ func (f *fqdnController) sendDNSPacketout(pktIn *ofctrl.PacketIn) error {
 			inPort,
 			0,
 			isIPv6,
-			udpSrcPort,
-			udpDstPort,
-			packetData,
+			prot,
+			l4Packet,
 			mutatePacketOut)
 	}
-	return nil
+	udpSrcPort, udpDstPort, err := binding.GetUDPHeaderData(ethernetPkt.Data)
+	if err != nil {
+		klog.ErrorS(err, "Failed to get UDP header data")
+		return err
+	}
+	return f.ofClient.SendUDPPacketOut(
+		ethernetPkt.HWSrc.String(),
+		ethernetPkt.HWDst.String(),
+		srcIP,
+		dstIP,
+		inPort,
+		0,
+		isIPv6,
+		udpSrcPort,
+		udpDstPort,
+		packetData,
+		mutatePacketOut)
 }
@@ -0,0 +1,224 @@
+// Copyright 2022 Antrea Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package networkpolicy
+
+import (
+	"encoding/binary"
+	"fmt"
+	"sync"
+	"time"
+
+	"k8s.io/klog/v2"
+)
+
+const (
+	tcpFlagFIN = 0x01
+	tcpFlagSYN = 0x02
+	tcpFlagRST = 0x04
+
+	// dnsTCPStreamIdleTimeout is the time after which the reassembly state of a TCP connection carrying DNS
+	// responses is discarded, if no segment has been received for the connection.
+	dnsTCPStreamIdleTimeout = 1 * time.Minute
+	// maxDNSTCPStreams is the maximum number of TCP connections carrying DNS responses tracked at the same time.
+	maxDNSTCPStreams = 1024
+	// maxDNSTCPPendingSegments is the maximum number of out-of-order segments buffered for a TCP connection.
+	maxDNSTCPPendingSegments = 64
+)
+
+// tcpSegment is the part of a TCP segment used to reassemble DNS messages.
+type tcpSegment struct {
+	srcPort uint16
+	dstPort uint16
+	seqNum  uint32
+	flags   uint8
+	payload []byte
+}
+
+// parseTCPSegment parses the header of a TCP segment and returns the segment with its payload.
+func parseTCPSegment(data []byte) (*tcpSegment, error) {
+	if len(data) < 20 {
+		return nil, fmt.Errorf("TCP segment is too short: %d bytes", len(data))
+	}
+	headerLen := int(data[12]>>4) * 4
+	if headerLen < 20 || headerLen > len(data) {
+		return nil, fmt.Errorf("invalid TCP header length %d", headerLen)
+	}
+	return &tcpSegment{
+		srcPort: binary.BigEndian.Uint16(data[0:2]),
+		dstPort: binary.BigEndian.Uint16(data[2:4]),
+		seqNum:  binary.BigEndian.Uint32(data[4:8]),
+		flags:   data[13],
+		payload: data[headerLen:],
+	}, nil
+}
+
+// dnsTCPStreamKey identifies the direction of a TCP connection from a DNS server to a client.
+type dnsTCPStreamKey struct {
+	srcIP   string
+	srcPort uint16
+	dstIP   string
+	dstPort uint16
+}
+
+// dnsTCPStream is the reassembly state of the DNS responses sent over a TCP connection.
+type dnsTCPStream struct {
+	// nextSeq is the sequence number of the next byte expected in the stream.
+	nextSeq uint32
+	// buffer holds the bytes received in order which don't make up a complete DNS message yet. As the length of
+	// a DNS message is a 2-byte field, at most 65537 bytes are buffered.
+	buffer []byte
+	// pendingSegments holds the payloads of the segments received out of order, keyed by sequence number.
+	pendingSegments map[uint32][]byte
+	lastSeen        time.Time
+}
+
+// dnsTCPStreamTracker reassembles the DNS messages carried by TCP connections. When sent over TCP, each DNS message
+// is prefixed with a 2-byte length field (RFC 1035 section 4.2.2), and it can span multiple TCP segments.
+type dnsTCPStreamTracker struct {
+	mutex   sync.Mutex
+	clock   Clock
+	streams map[dnsTCPStreamKey]*dnsTCPStream
+	lastGC  time.Time
+}
+
+func newDNSTCPStreamTracker(clock Clock) *dnsTCPStreamTracker {
+	return &dnsTCPStreamTracker{
+		clock:   clock,
+		streams: map[dnsTCPStreamKey]*dnsTCPStream{},
+		lastGC:  clock.Now(),
+	}
+}
+
+// addSegment adds a TCP segment to the stream identified by key, and returns the DNS messages completed by the
+// segment. Retransmitted data is ignored and out-of-order segments are buffered until the missing data is received.
+func (t *dnsTCPStreamTracker) addSegment(key dnsTCPStreamKey, segment *tcpSegment) [][]byte {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+	now := t.clock.Now()
+	t.gc(now)
+
+	if segment.flags&tcpFlagRST != 0 {
+		delete(t.streams, key)
+		return nil
+	}
+	stream, exists := t.streams[key]
+	if !exists || segment.flags&tcpFlagSYN != 0 {
+		if segment.flags&tcpFlagSYN == 0 && len(segment.payload) == 0 {
+			// Nothing to reassemble before the connection carries any data.
+			return nil
+		}
+		if !exists && len(t.streams) >= maxDNSTCPStreams {
+			klog.V(2).InfoS("Too many TCP connections carrying DNS responses, skip tracking the connection", "connection", key)
+			return nil
+		}
+		// The connection may have been established before the agent started, in which case the stream starts at
+		// the first data segment received.
+		stream = &dnsTCPStream{nextSeq: segment.seqNum, pendingSegments: map[uint32][]byte{}}
+		if segment.flags&tcpFlagSYN != 0 {
+			// The SYN flag consumes one sequence number.
+			stream.nextSeq++
+		}
+		t.streams[key] = stream
+	}
+	stream.lastSeen = now
+
+	payload, payloadSeq := segment.payload, segment.seqNum
+	if segment.flags&tcpFlagSYN != 0 {
+		payloadSeq++
+	}
+	if len(payload) > 0 {
+		offset := int32(payloadSeq - stream.nextSeq)
+		if offset > 0 {
+			if len(stream.pendingSegments) < maxDNSTCPPendingSegments {
+				stream.pendingSegments[payloadSeq] = payload
+			}
+			return nil
+		}
+		stream.append(payload, offset)
+		stream.appendPendingSegments()
+	}
+	messages := stream.extractMessages()
+	if segment.flags&tcpFlagFIN != 0 && len(stream.pendingSegments) == 0 {
+		delete(t.streams, key)
+	}
+	return messages
+}
+
+// append appends payload to the buffer of the stream. offset is the position of the payload relative to nextSeq,
+// which must not be positive. The part of payload which has been received already is ignored.
+func (s *dnsTCPStream) append(payload []byte, offset int32) {
+	if int64(len(payload)) <= int64(-offset) {
+		return
+	}
+	payload = payload[-offset:]
+	s.buffer = append(s.buffer, payload...)
+	s.nextSeq += uint32(len(payload))
+}
+
+// appendPendingSegments appends the buffered out-of-order segments which have become in order.
+func (s *dnsTCPStream) appendPendingSegments() {
+	for appended := true; appended; {
+		appended = false
+		for seq, payload := range s.pendingSegments {
+			offset := int32(seq - s.nextSeq)
+			if offset > 0 {
+				continue
+			}
+			delete(s.pendingSegments, seq)
+			s.append(payload, offset)
+			appended = true
+		}
+	}
+}
+
+// extractMessages removes the complete DNS messages from the buffer of the stream and returns them.
+func (s *dnsTCPStream) extractMessages() [][]byte {
+	var messages [][]byte
+	for len(s.buffer) >= 2 {
+		msgLen := int(binary.BigEndian.Uint16(s.buffer[0:2]))
+		if len(s.buffer) < 2+msgLen {
+			break
+		}
+		messages = append(messages, s.buffer[2:2+msgLen])
+		s.buffer = s.buffer[2+msgLen:]
+	}
+	if len(s.buffer) == 0 {
+		// Release the underlying array of the buffer.
+		s.buffer = nil
+	}
+	return messages
+}
+
+// reset discards the reassembly state of the stream identified by key, e.g. when the data received can't be parsed
+// as DNS messages.
+func (t *dnsTCPStreamTracker) reset(key dnsTCPStreamKey) {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+	delete(t.streams, key)
+}
+
+// gc removes the streams which have been idle for longer than dnsTCPStreamIdleTimeout. It runs at most once per
+// dnsTCPStreamIdleTimeout. The caller must hold the mutex.
+func (t *dnsTCPStreamTracker) gc(now time.Time) {
+	if now.Sub(t.lastGC) < dnsTCPStreamIdleTimeout {
+		return
+	}
+	t.lastGC = now
+	for key, stream := range t.streams {
+		if now.Sub(stream.lastSeen) >= dnsTCPStreamIdleTimeout {
+			delete(t.streams, key)
+		}
+	}
+}
@@ -0,0 +1,173 @@
+// Copyright 2022 Antrea Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package networkpolicy
+
+import (
+	"encoding/binary"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// dnsTCPData returns the DNS messages framed with their 2-byte length fields, as sent over TCP.
+func dnsTCPData(messages ...[]byte) []byte {
+	var data []byte
+	for _, msg := range messages {
+		data = binary.BigEndian.AppendUint16(data, uint16(len(msg)))
+		data = append(data, msg...)
+	}
+	return data
+}
+
+func TestParseTCPSegment(t *testing.T) {
+	header := make([]byte, 24)
+	binary.BigEndian.PutUint16(header[0:2], 53)
+	binary.BigEndian.PutUint16(header[2:4], 34567)
+	binary.BigEndian.PutUint32(header[4:8], 1000)
+	// Header length of 24 bytes, including a 4-byte option.
+	header[12] = 6 << 4
+	header[13] = tcpFlagFIN | 0x10
+	segment, err := parseTCPSegment(append(header, []byte("payload")...))
+	require.NoError(t, err)
+	assert.Equal(t, &tcpSegment{
+		srcPort: 53,
+		dstPort: 34567,
+		seqNum:  1000,
+		flags:   tcpFlagFIN | 0x10,
+		payload: []byte("payload"),
+	}, segment)
+
+	_, err = parseTCPSegment(header[:19])
+	assert.Error(t, err)
+	header[12] = 15 << 4
+	_, err = parseTCPSegment(header)
+	assert.Error(t, err)
+}
+
+func TestDNSTCPStreamTracker(t *testing.T) {
+	msg1 := []byte("first DNS response")
+	msg2 := []byte("second DNS response")
+	data := dnsTCPData(msg1, msg2)
+	key := dnsTCPStreamKey{srcIP: "10.96.0.10", srcPort: 53, dstIP: "10.10.0.2", dstPort: 34567}
+	// Use an initial sequence number close to the maximum value to cover wraparounds.
+	isn := uint32(0xfffffff0)
+
+	tests := []struct {
+		name             string
+		segments         []*tcpSegment
+		expectedMessages [][][]byte
+		expectedTracked  bool
+	}{
+		{
+			name: "in order",
+			segments: []*tcpSegment{
+				{seqNum: isn, flags: tcpFlagSYN},
+				{seqNum: isn + 1, payload: data[:5]},
+				{seqNum: isn + 6, payload: data[5:25]},
+				{seqNum: isn + 26, payload: data[25:]},
+			},
+			expectedMessages: [][][]byte{nil, nil, {msg1}, {msg2}},
+			expectedTracked:  true,
+		},
+		{
+			name: "multiple messages in a segment",
+			segments: []*tcpSegment{
+				{seqNum: isn, flags: tcpFlagSYN},
+				{seqNum: isn + 1, payload: data},
+			},
+			expectedMessages: [][][]byte{nil, {msg1, msg2}},
+			expectedTracked:  true,
+		},
+		{
+			name: "out of order",
+			segments: []*tcpSegment{
+				{seqNum: isn, flags: tcpFlagSYN},
+				{seqNum: isn + 21, payload: data[20:30]},
+				{seqNum: isn + 31, payload: data[30:]},
+				{seqNum: isn + 1, payload: data[:20]},
+			},
+			expectedMessages: [][][]byte{nil, nil, nil, {msg1, msg2}},
+			expectedTracked:  true,
+		},
+		{
+			name: "retransmission",
+			segments: []*tcpSegment{
+				{seqNum: isn, flags: tcpFlagSYN},
+				{seqNum: isn + 1, payload: data[:10]},
+				{seqNum: isn + 1, payload: data[:10]},
+				{seqNum: isn + 6, payload: data[5:20]},
+				{seqNum: isn + 21, payload: data[20:]},
+			},
+			expectedMessages: [][][]byte{nil, nil, nil, {msg1}, {msg2}},
+			expectedTracked:  true,
+		},
+		{
+			name: "connection established before tracking",
+			segments: []*tcpSegment{
+				{seqNum: 100, flags: 0x10},
+				{seqNum: 100, payload: data[:20]},
+				{seqNum: 120, payload: data[20:]},
+			},
+			expectedMessages: [][][]byte{nil, {msg1}, {msg2}},
+			expectedTracked:  true,
+		},
+		{
+			name: "FIN",
+			segments: []*tcpSegment{
+				{seqNum: isn, flags: tcpFlagSYN},
+				{seqNum: isn + 1, payload: data, flags: tcpFlagFIN},
+			},
+			expectedMessages: [][][]byte{nil, {msg1, msg2}},
+			expectedTracked:  false,
+		},
+		{
+			name: "RST",
+			segments: []*tcpSegment{
+				{seqNum: isn, flags: tcpFlagSYN},
+				{seqNum: isn + 1, payload: data[:20]},
+				{seqNum: isn + 21, flags: tcpFlagRST},
+			},
+			expectedMessages: [][][]byte{nil, {msg1}, nil},
+			expectedTracked:  false,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tracker := newDNSTCPStreamTracker(NewVirtualClock(time.Now()))
+			for i, segment := range tt.segments {
+				assert.Equal(t, tt.expectedMessages[i], tracker.addSegment(key, segment), "Unexpected DNS messages for segment %d", i)
+			}
+			_, tracked := tracker.streams[key]
+			assert.Equal(t, tt.expectedTracked, tracked)
+		})
+	}
+}
+
+func TestDNSTCPStreamTrackerGC(t *testing.T) {
+	clock := NewVirtualClock(time.Now())
+	tracker := newDNSTCPStreamTracker(clock)
+	key1 := dnsTCPStreamKey{srcIP: "10.96.0.10", srcPort: 53, dstIP: "10.10.0.2", dstPort: 34567}
+	key2 := dnsTCPStreamKey{srcIP: "10.96.0.10", srcPort: 53, dstIP: "10.10.0.3", dstPort: 34567}
+	tracker.addSegment(key1, &tcpSegment{seqNum: 100, flags: tcpFlagSYN})
+	clock.Advance(dnsTCPStreamIdleTimeout / 2)
+	tracker.addSegment(key2, &tcpSegment{seqNum: 100, flags: tcpFlagSYN})
+	clock.Advance(dnsTCPStreamIdleTimeout / 2)
+	// The idle stream of key1 is removed when the next segment is added.
+	tracker.addSegment(key2, &tcpSegment{seqNum: 101, payload: []byte{0}})
+	assert.Len(t, tracker.streams, 1)
+	assert.Contains(t, tracker.streams, key2)
+}
@@ -16,16 +16,18 @@ package networkpolicy
 
 import (
 	"context"
+	"net"
 	"testing"
 	"time"
 
-	"github.com/golang/mock/gomock"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
 	"k8s.io/apimachinery/pkg/util/sets"
 
 	"antrea.io/antrea/pkg/agent/config"
 	openflowtest "antrea.io/antrea/pkg/agent/openflow/testing"
+	"antrea.io/antrea/pkg/querier"
 )
 
 func newMockFQDNController(t *testing.T, controller *gomock.Controller, dnsServer *string) (*fqdnController, *openflowtest.MockClient) {
@@ -305,3 +307,236 @@ func TestLookupIPFallback(t *testing.T) {
 	err := f.lookupIP(ctx, "www.google.com")
 	require.NoError(t, err, "Error when resolving name")
 }
+
+func TestOnDNSResponse(t *testing.T) {
+	fqdn := "test.antrea.io"
+	selectorItem := fqdnSelectorItem{
+		matchName: fqdn,
+	}
+	ip1, ip2 := net.ParseIP("10.0.0.1"), net.ParseIP("10.0.0.2")
+	lookupTime := time.Now()
+	tests := []struct {
+		name             string
+		existingDNSCache map[string]dnsMeta
+		responseIPs      map[string]net.IP
+		expectedDNSCache map[string]dnsMeta
+		expectedDirty    bool
+	}{
+		{
+			name:        "new FQDN",
+			responseIPs: map[string]net.IP{ip1.String(): ip1},
+			expectedDNSCache: map[string]dnsMeta{
+				fqdn: {responseIPs: map[string]ipWithExpiration{
+					ip1.String(): {ip: ip1, expirationTime: lookupTime.Add(60 * time.Second)},
+				}},
+			},
+			expectedDirty: true,
+		},
+		{
+			name: "same IP",
+			existingDNSCache: map[string]dnsMeta{
+				fqdn: {responseIPs: map[string]ipWithExpiration{
+					ip1.String(): {ip: ip1, expirationTime: lookupTime.Add(10 * time.Second)},
+				}},
+			},
+			responseIPs: map[string]net.IP{ip1.String(): ip1},
+			expectedDNSCache: map[string]dnsMeta{
+				fqdn: {responseIPs: map[string]ipWithExpiration{
+					ip1.String(): {ip: ip1, expirationTime: lookupTime.Add(60 * time.Second)},
+				}},
+			},
+			expectedDirty: false,
+		},
+		{
+			name: "new IP and unexpired old IP",
+			existingDNSCache: map[string]dnsMeta{
+				fqdn: {responseIPs: map[string]ipWithExpiration{
+					ip1.String(): {ip: ip1, expirationTime: lookupTime.Add(10 * time.Second)},
+				}},
+			},
+			responseIPs: map[string]net.IP{ip2.String(): ip2},
+			expectedDNSCache: map[string]dnsMeta{
+				fqdn: {responseIPs: map[string]ipWithExpiration{
+					ip1.String(): {ip: ip1, expirationTime: lookupTime.Add(10 * time.Second)},
+					ip2.String(): {ip: ip2, expirationTime: lookupTime.Add(60 * time.Second)},
+				}},
+			},
+			expectedDirty: true,
+		},
+		{
+			name: "expired old IP",
+			existingDNSCache: map[string]dnsMeta{
+				fqdn: {responseIPs: map[string]ipWithExpiration{
+					ip1.String(): {ip: ip1, expirationTime: lookupTime.Add(60 * time.Second)},
+					ip2.String(): {ip: ip2, expirationTime: lookupTime.Add(-10 * time.Second)},
+				}},
+			},
+			responseIPs: map[string]net.IP{ip1.String(): ip1},
+			expectedDNSCache: map[string]dnsMeta{
+				fqdn: {responseIPs: map[string]ipWithExpiration{
+					ip1.String(): {ip: ip1, expirationTime: lookupTime.Add(60 * time.Second)},
+				}},
+			},
+			expectedDirty: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			controller := gomock.NewController(t)
+			defer controller.Finish()
+			f, _ := newMockFQDNController(t, controller, nil)
+			dirtyRules := sets.NewString()
+			f.dirtyRuleHandler = func(rule string) {
+				dirtyRules.Insert(rule)
+			}
+			f.selectorItemToRuleIDs = map[fqdnSelectorItem]sets.String{selectorItem: sets.NewString("mockRule1")}
+			if tt.existingDNSCache != nil {
+				f.dnsEntryCache = tt.existingDNSCache
+				f.fqdnToSelectorItem = map[string]map[fqdnSelectorItem]struct{}{fqdn: {selectorItem: struct{}{}}}
+			}
+			f.onDNSResponse(fqdn, tt.responseIPs, 60, lookupTime, nil)
+			assert.Equal(t, tt.expectedDNSCache, f.dnsEntryCache)
+			assert.Equal(t, tt.expectedDirty, dirtyRules.Has("mockRule1"))
+		})
+	}
+}
+
+func TestDNSRefreshDelay(t *testing.T) {
+	lookupTime := time.Now()
+	tests := []struct {
+		name          string
+		lookupTime    time.Time
+		lowestTTL     uint32
+		expectedDelay time.Duration
+	}{
+		{
+			name:          "refresh before expiration",
+			lookupTime:    lookupTime,
+			lowestTTL:     600,
+			expectedDelay: 540 * time.Second,
+		},
+		{
+			name:          "minimum interval",
+			lookupTime:    lookupTime,
+			lowestTTL:     0,
+			expectedDelay: minDNSRefreshInterval,
+		},
+		{
+			name:          "response received earlier",
+			lookupTime:    lookupTime.Add(-30 * time.Second),
+			lowestTTL:     600,
+			expectedDelay: 510 * time.Second,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			delay := dnsRefreshDelay(tt.lookupTime, tt.lowestTTL)
+			// Allow for the time elapsed since lookupTime.
+			assert.InDelta(t, tt.expectedDelay, delay, float64(time.Second))
+		})
+	}
+}
+
+func TestGetFQDNSelectorInfos(t *testing.T) {
+	controller := gomock.NewController(t)
+	defer controller.Finish()
+	f, c := newMockFQDNController(t, controller, nil)
+	c.EXPECT().AddAddressToDNSConjunction(dnsInterceptRuleID, gomock.Any()).AnyTimes()
+	expirationTime := time.Now().Add(60 * time.Second)
+	ip1, ip2, ip3 := net.ParseIP("10.0.0.1"), net.ParseIP("10.0.0.2"), net.ParseIP("10.0.0.3")
+	f.dnsEntryCache = map[string]dnsMeta{
+		"b.antrea.io": {responseIPs: map[string]ipWithExpiration{
+			ip3.String(): {ip: ip3, expirationTime: expirationTime},
+		}},
+		"a.antrea.io": {responseIPs: map[string]ipWithExpiration{
+			ip2.String(): {ip: ip2, expirationTime: expirationTime},
+			ip1.String(): {ip: ip1, expirationTime: expirationTime.Add(-10 * time.Second)},
+		}},
+	}
+	require.NoError(t, f.addFQDNRule("rule1", []string{"*.antrea.io"}, sets.NewInt32(1)))
+	require.NoError(t, f.addFQDNRule("rule2", []string{"*.antrea.io", "c.antrea.io"}, sets.NewInt32(2)))
+
+	wildcardInfo := querier.FQDNSelectorInfo{
+		Selector:     "matchRegex:^.*[.]antrea[.]io$",
+		RuleIDs:      []string{"rule1", "rule2"},
+		MatchedFQDNs: []string{"a.antrea.io", "b.antrea.io"},
+		CacheEntries: []querier.FQDNCacheEntry{
+			{FQDN: "a.antrea.io", IPAddress: ip1, ExpirationTime: expirationTime.Add(-10 * time.Second)},
+			{FQDN: "a.antrea.io", IPAddress: ip2, ExpirationTime: expirationTime},
+			{FQDN: "b.antrea.io", IPAddress: ip3, ExpirationTime: expirationTime},
+		},
+	}
+	nameInfo := querier.FQDNSelectorInfo{
+		Selector:     "matchName:c.antrea.io",
+		RuleIDs:      []string{"rule2"},
+		MatchedFQDNs: []string{"c.antrea.io"},
+	}
+	tests := []struct {
+		name          string
+		podOFPorts    sets.Int32
+		domainPattern string
+		expectedInfos []querier.FQDNSelectorInfo
+		expectedErr   bool
+	}{
+		{
+			name:          "no filter",
+			expectedInfos: []querier.FQDNSelectorInfo{nameInfo, wildcardInfo},
+		},
+		{
+			name:       "filter by Pod",
+			podOFPorts: sets.NewInt32(1),
+			expectedInfos: []querier.FQDNSelectorInfo{
+				{
+					Selector:     wildcardInfo.Selector,
+					RuleIDs:      []string{"rule1"},
+					MatchedFQDNs: wildcardInfo.MatchedFQDNs,
+					CacheEntries: wildcardInfo.CacheEntries,
+				},
+			},
+		},
+		{
+			name:       "filter by unknown Pod",
+			podOFPorts: sets.NewInt32(),
+		},
+		{
+			name:          "filter by domain name",
+			domainPattern: "b.antrea.io",
+			expectedInfos: []querier.FQDNSelectorInfo{
+				{
+					Selector:     wildcardInfo.Selector,
+					RuleIDs:      wildcardInfo.RuleIDs,
+					MatchedFQDNs: []string{"b.antrea.io"},
+					CacheEntries: wildcardInfo.CacheEntries[2:],
+				},
+			},
+		},
+		{
+			name:          "filter by domain wildcard",
+			domainPattern: "a.*",
+			expectedInfos: []querier.FQDNSelectorInfo{
+				{
+					Selector:     wildcardInfo.Selector,
+					RuleIDs:      wildcardInfo.RuleIDs,
+					MatchedFQDNs: []string{"a.antrea.io"},
+					CacheEntries: wildcardInfo.CacheEntries[:2],
+				},
+			},
+		},
+		{
+			name:          "invalid domain pattern",
+			domainPattern: "a(",
+			expectedErr:   true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			infos, err := f.getFQDNSelectorInfos(tt.podOFPorts, tt.domainPattern)
+			if tt.expectedErr {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.expectedInfos, infos)
+		})
+	}
+}
@@ -25,6 +25,7 @@ import (
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/fields"
 	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/sets"
 	"k8s.io/apimachinery/pkg/util/wait"
 	"k8s.io/apimachinery/pkg/watch"
 	"k8s.io/client-go/util/workqueue"
@@ -33,6 +34,7 @@ import (
 	"antrea.io/antrea/pkg/agent"
 	"antrea.io/antrea/pkg/agent/config"
 	"antrea.io/antrea/pkg/agent/flowexporter/connections"
+	"antrea.io/antrea/pkg/agent/flowexporter/l7"
 	"antrea.io/antrea/pkg/agent/interfacestore"
 	"antrea.io/antrea/pkg/agent/openflow"
 	proxytypes "antrea.io/antrea/pkg/agent/proxy/types"
@@ -129,6 +131,7 @@ func NewNetworkPolicyController(antreaClientGetter agent.AntreaClientProvider,
 	statusManagerEnabled bool,
 	multicastEnabled bool,
 	loggingEnabled bool,
+	auditLoggingOptions *AuditLoggingOptions,
 	asyncRuleDeleteInterval time.Duration,
 	dnsServerOverride string,
 	nodeType config.NodeType,
@@ -177,10 +180,11 @@ func NewNetworkPolicyController(antreaClientGetter agent.AntreaClientProvider,
 		c.ofClient.RegisterPacketInHandler(uint8(openflow.PacketInReasonNP), "networkpolicy", c)
 		if loggingEnabled {
 			// Initiate logger for Antrea Policy audit logging
-			antreaPolicyLogger, err := newAntreaPolicyLogger()
+			antreaPolicyLogger, err := newAntreaPolicyLogger(auditLoggingOptions)
 			if err != nil {
 				return nil, err
 			}
+			antreaPolicyLogger.suppressionTracker = newLogSuppressionTracker(ofClient.GetPolicyLogSuppressedPackets, antreaPolicyLogger.clock)
 			c.antreaPolicyLogger = antreaPolicyLogger
 		}
 	}
@@ -428,6 +432,26 @@ func (c *Controller) GetRuleByFlowID(ruleFlowID uint32) *types.PolicyRule {
 	return rule
 }
 
+// GetFQDNCache returns the FQDN selectors of FQDN policy rules, with the FQDNs they match and
+// the IP addresses resolved for these FQDNs. It returns nil if the fqdnController is not running,
+// i.e. AntreaPolicy is disabled.
+func (c *Controller) GetFQDNCache(fqdnFilter *querier.FQDNCacheFilter) ([]querier.FQDNSelectorInfo, error) {
+	if c.fqdnController == nil {
+		return nil, nil
+	}
+	if fqdnFilter == nil {
+		fqdnFilter = &querier.FQDNCacheFilter{}
+	}
+	var podOFPorts sets.Int32
+	if fqdnFilter.PodName != "" {
+		podOFPorts = sets.NewInt32()
+		for _, iface := range c.ifaceStore.GetContainerInterfacesByPod(fqdnFilter.PodName, fqdnFilter.PodNamespace) {
+			podOFPorts.Insert(iface.OFPort)
+		}
+	}
+	return c.fqdnController.getFQDNSelectorInfos(podOFPorts, fqdnFilter.DomainPattern)
+}
+
 func (c *Controller) GetControllerConnectionStatus() bool {
 	// When the watchers are connected, controller connection status is true. Otherwise, it is false.
 	return c.addressGroupWatcher.isConnected() && c.appliedToGroupWatcher.isConnected() && c.networkPolicyWatcher.isConnected()
@@ -437,6 +461,14 @@ func (c *Controller) SetDenyConnStore(denyConnStore *connections.DenyConnectionS
 	c.denyConnStore = denyConnStore
 }
 
+// SetL7EventStore sets the store to which the DNS responses intercepted by the
+// fqdnController are reported for L7 flow export.
+func (c *Controller) SetL7EventStore(l7EventStore *l7.EventStore) {
+	if c.fqdnController != nil {
+		c.fqdnController.l7EventStore = l7EventStore
+	}
+}
+
 // Run begins watching and processing Antrea AddressGroups, AppliedToGroups
 // and NetworkPolicies, and spawns workers that reconciles NetworkPolicy rules.
 // Run will not return until stopCh is closed.
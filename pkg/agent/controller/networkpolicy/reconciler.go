@@ -529,15 +529,17 @@ func (r *reconciler) computeOFRulesForAdd(rule *CompletedRule, ofPriority *uint1
 		ofPorts := r.getOFPorts(rule.TargetMembers)
 		lastRealized.podOFPorts[igmpServicesKey] = ofPorts
 		ofRuleByServicesMap[igmpServicesKey] = &types.PolicyRule{
-			Direction:     v1beta2.DirectionIn,
-			To:            ofPortsToOFAddresses(ofPorts),
-			Service:       rule.Services,
-			Action:        rule.Action,
-			Name:          rule.Name,
-			Priority:      ofPriority,
-			TableID:       table,
-			PolicyRef:     rule.SourceRef,
-			EnableLogging: rule.EnableLogging,
+			Direction:        v1beta2.DirectionIn,
+			To:               ofPortsToOFAddresses(ofPorts),
+			Service:          rule.Services,
+			Action:           rule.Action,
+			Name:             rule.Name,
+			Priority:         ofPriority,
+			TableID:          table,
+			PolicyRef:        rule.SourceRef,
+			EnableLogging:    rule.EnableLogging,
+			LogRate:          rule.LogRate,
+			LogSamplingRatio: rule.LogSamplingRatio,
 		}
 		return ofRuleByServicesMap, lastRealized
 	} else if isIGMP {
@@ -566,16 +568,18 @@ func (r *reconciler) computeOFRulesForAdd(rule *CompletedRule, ofPriority *uint1
 				lastRealized.podOFPorts[svcKey] = ofPorts
 			}
 			ofRuleByServicesMap[svcKey] = &types.PolicyRule{
-				Direction:     v1beta2.DirectionIn,
-				From:          append(from1, from2...),
-				To:            toAddresses,
-				Service:       filterUnresolvablePort(servicesMap[svcKey]),
-				Action:        rule.Action,
-				Name:          rule.Name,
-				Priority:      ofPriority,
-				TableID:       table,
-				PolicyRef:     rule.SourceRef,
-				EnableLogging: rule.EnableLogging,
+				Direction:        v1beta2.DirectionIn,
+				From:             append(from1, from2...),
+				To:               toAddresses,
+				Service:          filterUnresolvablePort(servicesMap[svcKey]),
+				Action:           rule.Action,
+				Name:             rule.Name,
+				Priority:         ofPriority,
+				TableID:          table,
+				PolicyRef:        rule.SourceRef,
+				EnableLogging:    rule.EnableLogging,
+				LogRate:          rule.LogRate,
+				LogSamplingRatio: rule.LogSamplingRatio,
 			}
 		}
 	} else {
@@ -594,16 +598,18 @@ func (r *reconciler) computeOFRulesForAdd(rule *CompletedRule, ofPriority *uint1
 		memberByServicesMap, servicesMap := groupMembersByServices(rule.Services, rule.ToAddresses)
 		for svcKey, members := range memberByServicesMap {
 			ofRuleByServicesMap[svcKey] = &types.PolicyRule{
-				Direction:     v1beta2.DirectionOut,
-				From:          from,
-				To:            groupMembersToOFAddresses(members),
-				Service:       filterUnresolvablePort(servicesMap[svcKey]),
-				Action:        rule.Action,
-				Priority:      ofPriority,
-				Name:          rule.Name,
-				TableID:       table,
-				PolicyRef:     rule.SourceRef,
-				EnableLogging: rule.EnableLogging,
+				Direction:        v1beta2.DirectionOut,
+				From:             from,
+				To:               groupMembersToOFAddresses(members),
+				Service:          filterUnresolvablePort(servicesMap[svcKey]),
+				Action:           rule.Action,
+				Priority:         ofPriority,
+				Name:             rule.Name,
+				TableID:          table,
+				PolicyRef:        rule.SourceRef,
+				EnableLogging:    rule.EnableLogging,
+				LogRate:          rule.LogRate,
+				LogSamplingRatio: rule.LogSamplingRatio,
 			}
 		}
 
@@ -618,16 +624,18 @@ func (r *reconciler) computeOFRulesForAdd(rule *CompletedRule, ofPriority *uint1
 			// Create a new Openflow rule if the group doesn't exist.
 			if !exists {
 				ofRule = &types.PolicyRule{
-					Direction:     v1beta2.DirectionOut,
-					From:          from,
-					To:            []types.Address{},
-					Service:       filterUnresolvablePort(rule.Services),
-					Action:        rule.Action,
-					Name:          rule.Name,
-					Priority:      nil,
-					TableID:       table,
-					PolicyRef:     rule.SourceRef,
-					EnableLogging: rule.EnableLogging,
+					Direction:        v1beta2.DirectionOut,
+					From:             from,
+					To:               []types.Address{},
+					Service:          filterUnresolvablePort(rule.Services),
+					Action:           rule.Action,
+					Name:             rule.Name,
+					Priority:         nil,
+					TableID:          table,
+					PolicyRef:        rule.SourceRef,
+					EnableLogging:    rule.EnableLogging,
+					LogRate:          rule.LogRate,
+					LogSamplingRatio: rule.LogSamplingRatio,
 				}
 				ofRuleByServicesMap[svcKey] = ofRule
 			}
@@ -716,15 +724,17 @@ func (r *reconciler) update(lastRealized *lastRealized, newRule *CompletedRule,
 		// Install a new Openflow rule if this group doesn't exist, otherwise do incremental update.
 		if !exists {
 			ofRule := &types.PolicyRule{
-				Direction:     v1beta2.DirectionIn,
-				To:            ofPortsToOFAddresses(newOFPorts),
-				Service:       newRule.Services,
-				Action:        newRule.Action,
-				Priority:      ofPriority,
-				FlowID:        ofID,
-				TableID:       table,
-				PolicyRef:     newRule.SourceRef,
-				EnableLogging: newRule.EnableLogging,
+				Direction:        v1beta2.DirectionIn,
+				To:               ofPortsToOFAddresses(newOFPorts),
+				Service:          newRule.Services,
+				Action:           newRule.Action,
+				Priority:         ofPriority,
+				FlowID:           ofID,
+				TableID:          table,
+				PolicyRef:        newRule.SourceRef,
+				EnableLogging:    newRule.EnableLogging,
+				LogRate:          newRule.LogRate,
+				LogSamplingRatio: newRule.LogSamplingRatio,
 			}
 			err := r.idAllocator.allocateForRule(ofRule)
 			if err != nil {
@@ -770,16 +780,18 @@ func (r *reconciler) update(lastRealized *lastRealized, newRule *CompletedRule,
 			// Install a new Openflow rule if this group doesn't exist, otherwise do incremental update.
 			if !exists {
 				ofRule := &types.PolicyRule{
-					Direction:     v1beta2.DirectionIn,
-					From:          append(from1, from2...),
-					To:            toAddresses,
-					Service:       filterUnresolvablePort(servicesMap[svcKey]),
-					Action:        newRule.Action,
-					Priority:      ofPriority,
-					FlowID:        ofID,
-					TableID:       table,
-					PolicyRef:     newRule.SourceRef,
-					EnableLogging: newRule.EnableLogging,
+					Direction:        v1beta2.DirectionIn,
+					From:             append(from1, from2...),
+					To:               toAddresses,
+					Service:          filterUnresolvablePort(servicesMap[svcKey]),
+					Action:           newRule.Action,
+					Priority:         ofPriority,
+					FlowID:           ofID,
+					TableID:          table,
+					PolicyRef:        newRule.SourceRef,
+					EnableLogging:    newRule.EnableLogging,
+					LogRate:          newRule.LogRate,
+					LogSamplingRatio: newRule.LogSamplingRatio,
 				}
 				err := r.idAllocator.allocateForRule(ofRule)
 				if err != nil {
@@ -839,16 +851,18 @@ func (r *reconciler) update(lastRealized *lastRealized, newRule *CompletedRule,
 			ofID, exists := lastRealized.ofIDs[svcKey]
 			if !exists {
 				ofRule := &types.PolicyRule{
-					Direction:     v1beta2.DirectionOut,
-					From:          from,
-					To:            groupMembersToOFAddresses(members),
-					Service:       filterUnresolvablePort(servicesMap[svcKey]),
-					Action:        newRule.Action,
-					Priority:      ofPriority,
-					FlowID:        ofID,
-					TableID:       table,
-					PolicyRef:     newRule.SourceRef,
-					EnableLogging: newRule.EnableLogging,
+					Direction:        v1beta2.DirectionOut,
+					From:             from,
+					To:               groupMembersToOFAddresses(members),
+					Service:          filterUnresolvablePort(servicesMap[svcKey]),
+					Action:           newRule.Action,
+					Priority:         ofPriority,
+					FlowID:           ofID,
+					TableID:          table,
+					PolicyRef:        newRule.SourceRef,
+					EnableLogging:    newRule.EnableLogging,
+					LogRate:          newRule.LogRate,
+					LogSamplingRatio: newRule.LogSamplingRatio,
 				}
 				// If the PolicyRule for the original services doesn't exist and IPBlocks is present, it means the
 				// reconciler hasn't installed flows for IPBlocks, then it must be added to the new PolicyRule.
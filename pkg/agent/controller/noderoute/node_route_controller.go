@@ -58,6 +58,7 @@ const (
 	ovsExternalIDNodeName = "node-name"
 
 	nodeRouteInfoPodCIDRIndexName = "podCIDR"
+	nodeRouteInfoNodeIPIndexName  = "nodeIP"
 )
 
 // Controller is responsible for setting up necessary IP routes and Openflow entries for inter-node traffic.
@@ -116,7 +117,7 @@ func NewNodeRouteController(
 		nodeListerSynced:        nodeInformer.Informer().HasSynced,
 		svcLister:               svcLister.Lister(),
 		queue:                   workqueue.NewNamedRateLimitingQueue(workqueue.NewItemExponentialFailureRateLimiter(minRetryDelay, maxRetryDelay), "noderoute"),
-		installedNodes:          cache.NewIndexer(nodeRouteInfoKeyFunc, cache.Indexers{nodeRouteInfoPodCIDRIndexName: nodeRouteInfoPodCIDRIndexFunc}),
+		installedNodes:          cache.NewIndexer(nodeRouteInfoKeyFunc, cache.Indexers{nodeRouteInfoPodCIDRIndexName: nodeRouteInfoPodCIDRIndexFunc, nodeRouteInfoNodeIPIndexName: nodeRouteInfoNodeIPIndexFunc}),
 		wireGuardClient:         wireguardClient,
 		proxyAll:                proxyAll,
 		ipsecCertificateManager: ipsecCertificateManager,
@@ -150,6 +151,19 @@ func nodeRouteInfoPodCIDRIndexFunc(obj interface{}) ([]string, error) {
 	return podCIDRs, nil
 }
 
+func nodeRouteInfoNodeIPIndexFunc(obj interface{}) ([]string, error) {
+	var nodeIPs []string
+	if ips := obj.(*nodeRouteInfo).nodeIPs; ips != nil {
+		if ips.IPv4 != nil {
+			nodeIPs = append(nodeIPs, ips.IPv4.String())
+		}
+		if ips.IPv6 != nil {
+			nodeIPs = append(nodeIPs, ips.IPv6.String())
+		}
+	}
+	return nodeIPs, nil
+}
+
 // nodeRouteInfo is the route related information extracted from corev1.Node.
 type nodeRouteInfo struct {
 	nodeName           string
@@ -795,6 +809,18 @@ func (c *Controller) IPInPodSubnets(ip net.IP) bool {
 	return len(nodeInCluster) > 0 || ipCIDRStr == curNodeCIDRStr
 }
 
+// IsNodeIP returns true if ip is an IP of the local Node or the transport IP of
+// a peer Node. hostNetwork Pods share the IPs of their Node.
+func (c *Controller) IsNodeIP(ip net.IP) bool {
+	for _, nodeIP := range []*net.IPNet{c.nodeConfig.NodeIPv4Addr, c.nodeConfig.NodeIPv6Addr, c.nodeConfig.NodeTransportIPv4Addr, c.nodeConfig.NodeTransportIPv6Addr} {
+		if nodeIP != nil && nodeIP.IP.Equal(ip) {
+			return true
+		}
+	}
+	nodes, _ := c.installedNodes.ByIndex(nodeRouteInfoNodeIPIndexName, ip.String())
+	return len(nodes) > 0
+}
+
 // getNodeMAC gets Node's br-int MAC from its annotation. It is only for Windows Noencap mode.
 func getNodeMAC(node *corev1.Node) (net.HardwareAddr, error) {
 	macStr := node.Annotations[types.NodeMACAddressAnnotationKey]
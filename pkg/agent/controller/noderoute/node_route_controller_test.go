@@ -21,8 +21,8 @@ import (
 	"time"
 
 	"github.com/containernetworking/plugins/pkg/ip"
-	"github.com/golang/mock/gomock"
 	"github.com/stretchr/testify/assert"
+	"go.uber.org/mock/gomock"
 	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/client-go/informers"
@@ -235,6 +235,51 @@ func TestIPInPodSubnets(t *testing.T) {
 	assert.Equal(t, false, c.Controller.IPInPodSubnets(net.ParseIP("8.8.8.8")))
 }
 
+func TestIsNodeIP(t *testing.T) {
+	c, closeFn := newController(t, &config.NetworkConfig{})
+	defer closeFn()
+	defer c.queue.ShutDown()
+
+	stopCh := make(chan struct{})
+	defer close(stopCh)
+	c.informerFactory.Start(stopCh)
+	c.informerFactory.WaitForCacheSync(stopCh)
+	c.Controller.nodeConfig.NodeIPv4Addr = &net.IPNet{IP: net.ParseIP("10.10.10.1"), Mask: net.CIDRMask(24, 32)}
+
+	node1 := &corev1.Node{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: "node1",
+		},
+		Spec: corev1.NodeSpec{
+			PodCIDR:  podCIDR.String(),
+			PodCIDRs: []string{podCIDR.String()},
+		},
+		Status: corev1.NodeStatus{
+			Addresses: []corev1.NodeAddress{
+				{
+					Type:    corev1.NodeInternalIP,
+					Address: nodeIP1.String(),
+				},
+			},
+		},
+	}
+	c.clientset.CoreV1().Nodes().Create(context.TODO(), node1, metav1.CreateOptions{})
+	c.ofClient.EXPECT().InstallNodeFlows("node1", gomock.Any(), &dsIPs1, uint32(0), nil).Times(1)
+	c.routeClient.EXPECT().AddRoutes(podCIDR, "node1", nodeIP1, podCIDRGateway).Times(1)
+	c.processNextWorkItem()
+
+	assert.True(t, c.Controller.IsNodeIP(net.ParseIP("10.10.10.1")))
+	assert.True(t, c.Controller.IsNodeIP(nodeIP1))
+	assert.False(t, c.Controller.IsNodeIP(nodeIP2))
+	assert.False(t, c.Controller.IsNodeIP(net.ParseIP("1.1.1.1")))
+
+	c.clientset.CoreV1().Nodes().Delete(context.TODO(), node1.Name, metav1.DeleteOptions{})
+	c.ofClient.EXPECT().UninstallNodeFlows("node1").Times(1)
+	c.routeClient.EXPECT().DeleteRoutes(podCIDR).Times(1)
+	c.processNextWorkItem()
+	assert.False(t, c.Controller.IsNodeIP(nodeIP1))
+}
+
 func setup(t *testing.T, ifaces []*interfacestore.InterfaceConfig, authenticationMode config.IPsecAuthenticationMode) (*fakeController, func()) {
 	c, closeFn := newController(t, &config.NetworkConfig{
 		TrafficEncapMode:      0,
@@ -0,0 +1,675 @@
+// Copyright 2022 Antrea Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package packetcapture
+
+import (
+	"context"
+	"fmt"
+	"hash/fnv"
+	"net"
+	"os"
+	"path/filepath"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"antrea.io/libOpenflow/protocol"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"k8s.io/apimachinery/pkg/util/wait"
+	clientset "k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/tools/cache"
+	"k8s.io/client-go/util/workqueue"
+	"k8s.io/klog/v2"
+
+	"antrea.io/antrea/pkg/agent/config"
+	"antrea.io/antrea/pkg/agent/interfacestore"
+	"antrea.io/antrea/pkg/agent/openflow"
+	"antrea.io/antrea/pkg/agent/util"
+	crdv1alpha1 "antrea.io/antrea/pkg/apis/crd/v1alpha1"
+	clientsetversioned "antrea.io/antrea/pkg/client/clientset/versioned"
+	crdinformers "antrea.io/antrea/pkg/client/informers/externalversions/crd/v1alpha1"
+	crdlisters "antrea.io/antrea/pkg/client/listers/crd/v1alpha1"
+	binding "antrea.io/antrea/pkg/ovs/openflow"
+	"antrea.io/antrea/pkg/ovs/ovsconfig"
+	"antrea.io/antrea/pkg/util/pcapng"
+)
+
+const (
+	controllerName = "AntreaAgentPacketCaptureController"
+	// Set resyncPeriod to 0 to disable resyncing.
+	resyncPeriod time.Duration = 0
+	// How long to wait before retrying the processing of a PacketCapture.
+	minRetryDelay = 5 * time.Second
+	maxRetryDelay = 300 * time.Second
+	// Default number of workers processing PacketCapture requests.
+	defaultWorkers = 2
+
+	// CaptureDir is the directory in which the pcapng files are stored.
+	CaptureDir = "/var/run/antrea/packetcapture"
+	// captureFileSuffix is the suffix of the pcapng files.
+	captureFileSuffix = ".pcapng"
+	// capturePortPrefix is the prefix of the names of the OVS internal
+	// ports to which the packets are mirrored.
+	capturePortPrefix = "pcap-"
+
+	// Reasons set to PacketCaptureStatus.Reason.
+	reasonNumberReached    = "Captured the requested number of packets"
+	reasonDurationReached  = "Capture duration elapsed"
+	reasonAgentRestarted   = "Capture was interrupted by Antrea Agent restart"
+	reasonCaptureFailedFmt = "Failed to capture packets: %v"
+)
+
+var (
+	capturePortExternalIDs = map[string]interface{}{
+		interfacestore.AntreaInterfaceTypeKey: interfacestore.AntreaPacketCapture,
+	}
+	// progressUpdateInterval is the interval at which the number of
+	// captured packets is reported in the status of the running
+	// PacketCaptures.
+	progressUpdateInterval = 5 * time.Second
+
+	// The following variables can be overridden in tests.
+	captureDir = CaptureDir
+	setLinkUp  = util.SetLinkUp
+)
+
+// packetSource reads the packets mirrored to a capture port.
+type packetSource interface {
+	// ReadPacket returns the next packet, and its length on the wire which can
+	// be larger than the returned data. It returns a nil packet if no packet is
+	// received within a short timeout, so that the caller can stop reading.
+	ReadPacket() ([]byte, int, error)
+	Close() error
+}
+
+// openPacketSource can be overridden in tests.
+var openPacketSource = newPacketSource
+
+// captureState is the state of a PacketCapture being processed on this Node.
+type captureState struct {
+	name     string
+	portName string
+	portUUID string
+	filePath string
+	// numCaptured is the number of packets captured, accessed atomically.
+	numCaptured int32
+	// numReported is the number of captured packets last reported in the
+	// status. It is only accessed by the progress updater.
+	numReported int32
+	stopCh      chan struct{}
+	doneCh      chan struct{}
+	// The following fields are set by the capture goroutine before closing
+	// doneCh.
+	reason string
+	err    error
+	// finalized is set when the result of the capture has been reported in
+	// the status.
+	finalized bool
+}
+
+func (s *captureState) isDone() bool {
+	select {
+	case <-s.doneCh:
+		return true
+	default:
+		return false
+	}
+}
+
+// Controller is responsible for capturing the packets of the PacketCapture
+// requests whose capture Pod runs on this Node. The matching packets are
+// mirrored by OVS to an internal port created for each request, and the packets
+// received on the port are written to a pcapng file.
+type Controller struct {
+	kubeClient                clientset.Interface
+	crdClient                 clientsetversioned.Interface
+	packetCaptureLister       crdlisters.PacketCaptureLister
+	packetCaptureListerSynced cache.InformerSynced
+	ovsBridgeClient           ovsconfig.OVSBridgeClient
+	ofClient                  openflow.Client
+	interfaceStore            interfacestore.InterfaceStore
+	nodeConfig                *config.NodeConfig
+	queue                     workqueue.RateLimitingInterface
+	capturesMutex             sync.Mutex
+	// captures stores the state of the PacketCaptures processed on this
+	// Node, with the PacketCapture name as the key.
+	captures map[string]*captureState
+}
+
+// NewPacketCaptureController instantiates a new Controller object which will
+// process PacketCapture events.
+func NewPacketCaptureController(
+	kubeClient clientset.Interface,
+	crdClient clientsetversioned.Interface,
+	packetCaptureInformer crdinformers.PacketCaptureInformer,
+	ofClient openflow.Client,
+	ovsBridgeClient ovsconfig.OVSBridgeClient,
+	interfaceStore interfacestore.InterfaceStore,
+	nodeConfig *config.NodeConfig) *Controller {
+	c := &Controller{
+		kubeClient:                kubeClient,
+		crdClient:                 crdClient,
+		packetCaptureLister:       packetCaptureInformer.Lister(),
+		packetCaptureListerSynced: packetCaptureInformer.Informer().HasSynced,
+		ovsBridgeClient:           ovsBridgeClient,
+		ofClient:                  ofClient,
+		interfaceStore:            interfaceStore,
+		nodeConfig:                nodeConfig,
+		queue:                     workqueue.NewNamedRateLimitingQueue(workqueue.NewItemExponentialFailureRateLimiter(minRetryDelay, maxRetryDelay), "packetCapture"),
+		captures:                  make(map[string]*captureState),
+	}
+	packetCaptureInformer.Informer().AddEventHandlerWithResyncPeriod(
+		cache.ResourceEventHandlerFuncs{
+			AddFunc:    c.addPacketCapture,
+			UpdateFunc: c.updatePacketCapture,
+			DeleteFunc: c.deletePacketCapture,
+		},
+		resyncPeriod,
+	)
+	return c
+}
+
+// enqueuePacketCapture adds a PacketCapture to the work queue if it is
+// processed on this Node.
+func (c *Controller) enqueuePacketCapture(pc *crdv1alpha1.PacketCapture) {
+	if pc.Status.Node == c.nodeConfig.Name || c.getCaptureState(pc.Name) != nil {
+		c.queue.Add(pc.Name)
+	}
+}
+
+func (c *Controller) addPacketCapture(obj interface{}) {
+	pc := obj.(*crdv1alpha1.PacketCapture)
+	klog.V(2).InfoS("Processing PacketCapture ADD event", "name", pc.Name)
+	c.enqueuePacketCapture(pc)
+}
+
+func (c *Controller) updatePacketCapture(_, curObj interface{}) {
+	pc := curObj.(*crdv1alpha1.PacketCapture)
+	klog.V(2).InfoS("Processing PacketCapture UPDATE event", "name", pc.Name)
+	c.enqueuePacketCapture(pc)
+}
+
+func (c *Controller) deletePacketCapture(old interface{}) {
+	pc, ok := old.(*crdv1alpha1.PacketCapture)
+	if !ok {
+		tombstone, ok := old.(cache.DeletedFinalStateUnknown)
+		if !ok {
+			klog.Errorf("Error decoding object when deleting PacketCapture, invalid type: %v", old)
+			return
+		}
+		pc, ok = tombstone.Obj.(*crdv1alpha1.PacketCapture)
+		if !ok {
+			klog.Errorf("Error decoding object tombstone when deleting PacketCapture, invalid type: %v", tombstone.Obj)
+			return
+		}
+	}
+	klog.V(2).InfoS("Processing PacketCapture DELETE event", "name", pc.Name)
+	c.enqueuePacketCapture(pc)
+}
+
+// Run will create defaultWorkers workers (go routines) which will process the
+// PacketCapture events from the workqueue.
+func (c *Controller) Run(stopCh <-chan struct{}) {
+	defer c.queue.ShutDown()
+
+	klog.InfoS("Starting controller", "name", controllerName)
+	defer klog.InfoS("Shutting down controller", "name", controllerName)
+
+	if !cache.WaitForNamedCacheSync(controllerName, stopCh, c.packetCaptureListerSynced) {
+		return
+	}
+	c.cleanupStaleCaptures()
+
+	for i := 0; i < defaultWorkers; i++ {
+		go wait.Until(c.worker, time.Second, stopCh)
+	}
+	go wait.Until(c.updateProgress, progressUpdateInterval, stopCh)
+	<-stopCh
+}
+
+// cleanupStaleCaptures deletes the capture ports left by a previous Agent, and
+// the pcapng files of the PacketCaptures which have been deleted.
+func (c *Controller) cleanupStaleCaptures() {
+	ports, ovsErr := c.ovsBridgeClient.GetPortList()
+	if ovsErr != nil {
+		klog.ErrorS(ovsErr, "Failed to list OVS ports")
+	}
+	for _, port := range ports {
+		if port.ExternalIDs[interfacestore.AntreaInterfaceTypeKey] != interfacestore.AntreaPacketCapture {
+			continue
+		}
+		klog.InfoS("Deleting stale capture port", "port", port.Name)
+		if err := c.ovsBridgeClient.DeletePort(port.UUID); err != nil {
+			klog.ErrorS(err, "Failed to delete stale capture port", "port", port.Name)
+		}
+	}
+
+	files, err := filepath.Glob(filepath.Join(captureDir, "*"+captureFileSuffix))
+	if err != nil {
+		klog.ErrorS(err, "Failed to list pcapng files")
+		return
+	}
+	pcs, _ := c.packetCaptureLister.List(labels.Everything())
+	existingFiles := sets.NewString()
+	for _, pc := range pcs {
+		existingFiles.Insert(getCaptureFilePath(pc.Name))
+	}
+	for _, file := range files {
+		if existingFiles.Has(file) {
+			continue
+		}
+		klog.InfoS("Deleting stale pcapng file", "file", file)
+		if err := os.Remove(file); err != nil {
+			klog.ErrorS(err, "Failed to delete stale pcapng file", "file", file)
+		}
+	}
+}
+
+func (c *Controller) worker() {
+	for c.processPacketCaptureItem() {
+	}
+}
+
+func (c *Controller) processPacketCaptureItem() bool {
+	obj, quit := c.queue.Get()
+	if quit {
+		return false
+	}
+	defer c.queue.Done(obj)
+
+	if key, ok := obj.(string); !ok {
+		c.queue.Forget(obj)
+		klog.Errorf("Expected string in work queue but got %#v", obj)
+	} else if err := c.syncPacketCapture(key); err == nil {
+		c.queue.Forget(key)
+	} else {
+		klog.ErrorS(err, "Error syncing PacketCapture", "name", key)
+		c.queue.AddRateLimited(key)
+	}
+	return true
+}
+
+func (c *Controller) getCaptureState(name string) *captureState {
+	c.capturesMutex.Lock()
+	defer c.capturesMutex.Unlock()
+	return c.captures[name]
+}
+
+func (c *Controller) syncPacketCapture(name string) error {
+	pc, err := c.packetCaptureLister.Get(name)
+	if err != nil {
+		if !apierrors.IsNotFound(err) {
+			return err
+		}
+		// The pcapng file is deleted with the PacketCapture.
+		if err := c.stopCapture(name); err != nil {
+			return err
+		}
+		if err := os.Remove(getCaptureFilePath(name)); err != nil && !os.IsNotExist(err) {
+			return err
+		}
+		return nil
+	}
+	if pc.Status.Phase != crdv1alpha1.PacketCaptureRunning || pc.Status.Node != c.nodeConfig.Name {
+		// The PacketCapture is completed, or has been failed by the
+		// Antrea Controller. The pcapng file is kept.
+		return c.stopCapture(name)
+	}
+
+	state := c.getCaptureState(name)
+	if state == nil {
+		if pc.Status.FilePath != "" {
+			// The capture was started by a previous Agent.
+			return c.updatePacketCaptureStatus(pc, crdv1alpha1.PacketCaptureFailed, reasonAgentRestarted, pc.Status.NumberCaptured, pc.Status.FilePath)
+		}
+		return c.startCapture(pc)
+	}
+	if !state.isDone() || state.finalized {
+		return nil
+	}
+	if err := c.cleanupCapture(state); err != nil {
+		return err
+	}
+	phase, reason := crdv1alpha1.PacketCaptureSucceeded, state.reason
+	if state.err != nil {
+		phase, reason = crdv1alpha1.PacketCaptureFailed, fmt.Sprintf(reasonCaptureFailedFmt, state.err)
+	}
+	if err := c.updatePacketCaptureStatus(pc, phase, reason, atomic.LoadInt32(&state.numCaptured), state.filePath); err != nil {
+		return err
+	}
+	state.finalized = true
+	return nil
+}
+
+// startCapture creates the capture port of a PacketCapture, installs the flows
+// mirroring the matching packets to the port, and starts writing the packets
+// received on the port to the pcapng file.
+func (c *Controller) startCapture(pc *crdv1alpha1.PacketCapture) error {
+	podNamespace, podName := pc.Spec.Source.Namespace, pc.Spec.Source.Pod
+	if pc.Spec.CapturePoint == crdv1alpha1.CapturePointDestination {
+		podNamespace, podName = pc.Spec.Destination.Namespace, pc.Spec.Destination.Pod
+	}
+	podInterfaces := c.interfaceStore.GetContainerInterfacesByPod(podName, podNamespace)
+	if len(podInterfaces) == 0 {
+		// The Pod may not have been set up yet. The PacketCapture is
+		// failed by the Antrea Controller if it cannot be started in time.
+		return fmt.Errorf("interface of Pod %s/%s not found", podNamespace, podName)
+	}
+	packet, err := c.preparePacket(pc)
+	if err != nil {
+		return c.updatePacketCaptureStatus(pc, crdv1alpha1.PacketCaptureFailed, fmt.Sprintf(reasonCaptureFailedFmt, err), 0, "")
+	}
+
+	state := &captureState{
+		name:     pc.Name,
+		portName: getCapturePortName(pc.Name),
+		filePath: getCaptureFilePath(pc.Name),
+		stopCh:   make(chan struct{}),
+		doneCh:   make(chan struct{}),
+	}
+	source, writer, file, err := c.setupCapture(state, uint32(podInterfaces[0].OFPort), packet)
+	if err != nil {
+		if cleanupErr := c.cleanupCapture(state); cleanupErr != nil {
+			klog.ErrorS(cleanupErr, "Failed to clean up PacketCapture", "name", pc.Name)
+		}
+		return err
+	}
+	// Record the file path in the status, so that a new Agent knows that the
+	// capture was started if the Agent restarts.
+	if err := c.updatePacketCaptureStatus(pc, crdv1alpha1.PacketCaptureRunning, "", 0, state.filePath); err != nil {
+		source.Close()
+		file.Close()
+		if cleanupErr := c.cleanupCapture(state); cleanupErr != nil {
+			klog.ErrorS(cleanupErr, "Failed to clean up PacketCapture", "name", pc.Name)
+		}
+		return err
+	}
+
+	startTime := time.Now()
+	if pc.Status.StartTime != nil {
+		startTime = pc.Status.StartTime.Time
+	}
+	duration := pc.Spec.CaptureConfig.Duration
+	if duration == 0 {
+		duration = crdv1alpha1.DefaultPacketCaptureDuration
+	}
+	deadline := startTime.Add(time.Duration(duration) * time.Second)
+
+	c.capturesMutex.Lock()
+	c.captures[pc.Name] = state
+	c.capturesMutex.Unlock()
+	klog.InfoS("Started PacketCapture", "name", pc.Name, "port", state.portName, "file", state.filePath)
+	go c.capture(state, source, writer, file, pc.Spec.CaptureConfig.Number, deadline)
+	return nil
+}
+
+// setupCapture creates the capture port and the pcapng file, and installs the
+// flows mirroring the packets to the port.
+func (c *Controller) setupCapture(state *captureState, podOFPort uint32, packet *binding.Packet) (packetSource, *pcapng.Writer, *os.File, error) {
+	portUUID, err := c.createCapturePort(state.portName)
+	if err != nil {
+		return nil, nil, nil, fmt.Errorf("failed to create capture port: %w", err)
+	}
+	state.portUUID = portUUID
+	ofPort, err := c.ovsBridgeClient.GetOFPort(state.portName, false)
+	if err != nil {
+		return nil, nil, nil, fmt.Errorf("failed to get OF port of capture port: %w", err)
+	}
+	// Start reading the port before mirroring the packets to it.
+	source, err := openPacketSource(state.portName)
+	if err != nil {
+		return nil, nil, nil, fmt.Errorf("failed to read capture port: %w", err)
+	}
+	if err := os.MkdirAll(captureDir, 0700); err != nil {
+		source.Close()
+		return nil, nil, nil, err
+	}
+	file, err := os.OpenFile(state.filePath, os.O_CREATE|os.O_TRUNC|os.O_WRONLY, 0600)
+	if err != nil {
+		source.Close()
+		return nil, nil, nil, err
+	}
+	writer, err := pcapng.NewWriter(file)
+	if err == nil {
+		err = c.ofClient.InstallPacketCaptureFlows(state.name, podOFPort, uint32(ofPort), packet)
+	}
+	if err != nil {
+		source.Close()
+		file.Close()
+		return nil, nil, nil, err
+	}
+	return source, writer, file, nil
+}
+
+func (c *Controller) createCapturePort(portName string) (string, error) {
+	portUUID, err := c.ovsBridgeClient.CreateInternalPort(portName, 0, "", capturePortExternalIDs)
+	if err != nil {
+		return "", err
+	}
+	if pollErr := wait.PollImmediate(time.Second, 5*time.Second, func() (bool, error) {
+		_, _, err := setLinkUp(portName)
+		if err == nil {
+			return true, nil
+		}
+		if _, ok := err.(util.LinkNotFound); ok {
+			return false, nil
+		}
+		return false, err
+	}); pollErr != nil {
+		if err := c.ovsBridgeClient.DeletePort(portUUID); err != nil {
+			klog.ErrorS(err, "Failed to delete capture port", "port", portName)
+		}
+		return "", pollErr
+	}
+	return portUUID, nil
+}
+
+// capture writes the packets received on the capture port to the pcapng file,
+// until the requested number of packets is captured, the capture duration
+// elapses, or the capture is stopped.
+func (c *Controller) capture(state *captureState, source packetSource, writer *pcapng.Writer, file *os.File, number int32, deadline time.Time) {
+	defer func() {
+		source.Close()
+		if err := file.Close(); err != nil && state.err == nil {
+			state.err = err
+		}
+		close(state.doneCh)
+		c.queue.Add(state.name)
+	}()
+	for {
+		select {
+		case <-state.stopCh:
+			return
+		default:
+		}
+		if time.Now().After(deadline) {
+			state.reason = reasonDurationReached
+			return
+		}
+		data, length, err := source.ReadPacket()
+		if err != nil {
+			state.err = err
+			return
+		}
+		if data == nil {
+			continue
+		}
+		if err := writer.WritePacket(time.Now(), data, length); err != nil {
+			state.err = err
+			return
+		}
+		if n := atomic.AddInt32(&state.numCaptured, 1); number > 0 && n >= number {
+			state.reason = reasonNumberReached
+			return
+		}
+	}
+}
+
+// stopCapture stops a PacketCapture if it is running, and removes its capture
+// port and flows.
+func (c *Controller) stopCapture(name string) error {
+	state := c.getCaptureState(name)
+	if state == nil {
+		return nil
+	}
+	if !state.isDone() {
+		close(state.stopCh)
+		<-state.doneCh
+	}
+	// The flows and the port of a finalized capture are already removed.
+	if !state.finalized {
+		if err := c.cleanupCapture(state); err != nil {
+			return err
+		}
+	}
+	c.capturesMutex.Lock()
+	delete(c.captures, name)
+	c.capturesMutex.Unlock()
+	klog.InfoS("Stopped PacketCapture", "name", name)
+	return nil
+}
+
+// cleanupCapture removes the flows and the capture port of a PacketCapture.
+func (c *Controller) cleanupCapture(state *captureState) error {
+	if err := c.ofClient.UninstallPacketCaptureFlows(state.name); err != nil {
+		return fmt.Errorf("failed to uninstall PacketCapture flows: %w", err)
+	}
+	if state.portUUID != "" {
+		if err := c.ovsBridgeClient.DeletePort(state.portUUID); err != nil {
+			return fmt.Errorf("failed to delete capture port: %w", err)
+		}
+		state.portUUID = ""
+	}
+	return nil
+}
+
+// updateProgress reports the number of packets captured so far in the status
+// of the running PacketCaptures.
+func (c *Controller) updateProgress() {
+	c.capturesMutex.Lock()
+	states := make([]*captureState, 0, len(c.captures))
+	for _, state := range c.captures {
+		states = append(states, state)
+	}
+	c.capturesMutex.Unlock()
+
+	for _, state := range states {
+		numCaptured := atomic.LoadInt32(&state.numCaptured)
+		if state.isDone() || numCaptured == state.numReported {
+			continue
+		}
+		pc, err := c.packetCaptureLister.Get(state.name)
+		if err != nil || pc.Status.Phase != crdv1alpha1.PacketCaptureRunning {
+			continue
+		}
+		if err := c.updatePacketCaptureStatus(pc, crdv1alpha1.PacketCaptureRunning, "", numCaptured, state.filePath); err != nil {
+			klog.ErrorS(err, "Failed to update PacketCapture progress", "name", state.name)
+			continue
+		}
+		state.numReported = numCaptured
+	}
+}
+
+func (c *Controller) updatePacketCaptureStatus(pc *crdv1alpha1.PacketCapture, phase crdv1alpha1.PacketCapturePhase, reason string, numCaptured int32, filePath string) error {
+	update := pc.DeepCopy()
+	update.Status.Phase = phase
+	update.Status.NumberCaptured = numCaptured
+	update.Status.FilePath = filePath
+	if reason != "" {
+		update.Status.Reason = reason
+	}
+	_, err := c.crdClient.CrdV1alpha1().PacketCaptures().UpdateStatus(context.TODO(), update, metav1.UpdateOptions{})
+	return err
+}
+
+// preparePacket returns the packet matching the packets sent by the capture Pod.
+// Only the IP address of the other end of the traffic is matched, since the
+// packets are matched on the OVS port of the capture Pod.
+func (c *Controller) preparePacket(pc *crdv1alpha1.PacketCapture) (*binding.Packet, error) {
+	spec := &pc.Spec
+	packet := &binding.Packet{IsIPv6: spec.Packet.IPv6Header != nil}
+
+	peerNamespace, peerPod, peerIP := spec.Destination.Namespace, spec.Destination.Pod, spec.Destination.IP
+	if spec.CapturePoint == crdv1alpha1.CapturePointDestination {
+		peerNamespace, peerPod, peerIP = spec.Source.Namespace, spec.Source.Pod, spec.Source.IP
+	}
+	if peerIP != "" {
+		packet.DestinationIP = net.ParseIP(peerIP)
+		if packet.DestinationIP == nil {
+			return nil, fmt.Errorf("invalid IP %s", peerIP)
+		}
+		packet.IsIPv6 = packet.DestinationIP.To4() == nil
+	} else if peerPod != "" {
+		pod, err := c.kubeClient.CoreV1().Pods(peerNamespace).Get(context.TODO(), peerPod, metav1.GetOptions{})
+		if err != nil {
+			return nil, fmt.Errorf("failed to get Pod %s/%s: %w", peerNamespace, peerPod, err)
+		}
+		for _, podIP := range pod.Status.PodIPs {
+			ip := net.ParseIP(podIP.IP)
+			if ip != nil && (ip.To4() == nil) == packet.IsIPv6 {
+				packet.DestinationIP = ip
+				break
+			}
+		}
+		if packet.DestinationIP == nil {
+			return nil, fmt.Errorf("Pod %s/%s has no IP of the requested family", peerNamespace, peerPod)
+		}
+	}
+
+	if spec.Packet.IPv6Header != nil {
+		if spec.Packet.IPv6Header.NextHeader != nil {
+			packet.IPProto = uint8(*spec.Packet.IPv6Header.NextHeader)
+		}
+	} else {
+		packet.IPProto = uint8(spec.Packet.IPHeader.Protocol)
+	}
+	var srcPort, dstPort int32
+	transport := &spec.Packet.TransportHeader
+	if transport.TCP != nil {
+		packet.IPProto = protocol.Type_TCP
+		srcPort, dstPort = transport.TCP.SrcPort, transport.TCP.DstPort
+	} else if transport.UDP != nil {
+		packet.IPProto = protocol.Type_UDP
+		srcPort, dstPort = transport.UDP.SrcPort, transport.UDP.DstPort
+	} else if transport.ICMP != nil {
+		packet.IPProto = protocol.Type_ICMP
+		if packet.IsIPv6 {
+			packet.IPProto = protocol.Type_IPv6ICMP
+		}
+	}
+	// The ports are specified for the packets sent from the source to the
+	// destination.
+	if spec.CapturePoint == crdv1alpha1.CapturePointDestination {
+		srcPort, dstPort = dstPort, srcPort
+	}
+	packet.SourcePort, packet.DestinationPort = uint16(srcPort), uint16(dstPort)
+	return packet, nil
+}
+
+// getCapturePortName returns the name of the capture port of a PacketCapture,
+// which must not be longer than the maximum length of an interface name.
+func getCapturePortName(name string) string {
+	h := fnv.New32a()
+	h.Write([]byte(name))
+	return fmt.Sprintf("%s%08x", capturePortPrefix, h.Sum32())
+}
+
+// getCaptureFilePath returns the path of the pcapng file of a PacketCapture.
+func getCaptureFilePath(name string) string {
+	return filepath.Join(captureDir, name+captureFileSuffix)
+}
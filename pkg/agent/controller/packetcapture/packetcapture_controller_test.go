@@ -0,0 +1,365 @@
+// Copyright 2022 Antrea Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package packetcapture
+
+import (
+	"context"
+	"net"
+	"os"
+	"testing"
+	"time"
+
+	"antrea.io/libOpenflow/protocol"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/kubernetes/fake"
+
+	"antrea.io/antrea/pkg/agent/config"
+	"antrea.io/antrea/pkg/agent/interfacestore"
+	openflowtest "antrea.io/antrea/pkg/agent/openflow/testing"
+	crdv1alpha1 "antrea.io/antrea/pkg/apis/crd/v1alpha1"
+	fakeversioned "antrea.io/antrea/pkg/client/clientset/versioned/fake"
+	crdinformers "antrea.io/antrea/pkg/client/informers/externalversions"
+	binding "antrea.io/antrea/pkg/ovs/openflow"
+	"antrea.io/antrea/pkg/ovs/ovsconfig"
+	ovsconfigtest "antrea.io/antrea/pkg/ovs/ovsconfig/testing"
+)
+
+const (
+	nodeName   = "node1"
+	podOFPort  = int32(1)
+	pcapOFPort = int32(10)
+)
+
+var (
+	pod1 = &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "ns1", Name: "pod1"},
+		Spec:       corev1.PodSpec{NodeName: nodeName},
+		Status:     corev1.PodStatus{PodIPs: []corev1.PodIP{{IP: "10.10.0.1"}, {IP: "fd00::1"}}},
+	}
+	pod2 = &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "ns1", Name: "pod2"},
+		Spec:       corev1.PodSpec{NodeName: "node2"},
+		Status:     corev1.PodStatus{PodIPs: []corev1.PodIP{{IP: "10.10.1.2"}, {IP: "fd00::2"}}},
+	}
+	pod1Interface = &interfacestore.InterfaceConfig{
+		InterfaceName:            "pod1-abcd",
+		Type:                     interfacestore.ContainerInterface,
+		ContainerInterfaceConfig: &interfacestore.ContainerInterfaceConfig{PodName: "pod1", PodNamespace: "ns1", ContainerID: "container1"},
+		OVSPortConfig:            &interfacestore.OVSPortConfig{OFPort: podOFPort},
+	}
+)
+
+// fakePacketSource returns the provided packets, and then no packet.
+type fakePacketSource struct {
+	packets [][]byte
+	closed  bool
+}
+
+func (s *fakePacketSource) ReadPacket() ([]byte, int, error) {
+	if len(s.packets) == 0 {
+		time.Sleep(10 * time.Millisecond)
+		return nil, 0, nil
+	}
+	packet := s.packets[0]
+	s.packets = s.packets[1:]
+	return packet, len(packet), nil
+}
+
+func (s *fakePacketSource) Close() error {
+	s.closed = true
+	return nil
+}
+
+type fakeController struct {
+	*Controller
+	mockOFClient        *openflowtest.MockClient
+	mockOVSBridgeClient *ovsconfigtest.MockOVSBridgeClient
+	crdClient           *fakeversioned.Clientset
+	crdInformerFactory  crdinformers.SharedInformerFactory
+}
+
+func newFakeController(t *testing.T, pcs ...*crdv1alpha1.PacketCapture) *fakeController {
+	controller := gomock.NewController(t)
+	mockOFClient := openflowtest.NewMockClient(controller)
+	mockOVSBridgeClient := ovsconfigtest.NewMockOVSBridgeClient(controller)
+
+	client := fake.NewSimpleClientset(pod1, pod2)
+	var crdObjects []runtime.Object
+	for _, pc := range pcs {
+		crdObjects = append(crdObjects, pc)
+	}
+	crdClient := fakeversioned.NewSimpleClientset(crdObjects...)
+	crdInformerFactory := crdinformers.NewSharedInformerFactory(crdClient, 0)
+
+	interfaceStore := interfacestore.NewInterfaceStore()
+	interfaceStore.AddInterface(pod1Interface)
+
+	c := NewPacketCaptureController(client, crdClient, crdInformerFactory.Crd().V1alpha1().PacketCaptures(),
+		mockOFClient, mockOVSBridgeClient, interfaceStore, &config.NodeConfig{Name: nodeName})
+	return &fakeController{
+		Controller:          c,
+		mockOFClient:        mockOFClient,
+		mockOVSBridgeClient: mockOVSBridgeClient,
+		crdClient:           crdClient,
+		crdInformerFactory:  crdInformerFactory,
+	}
+}
+
+func (c *fakeController) start(t *testing.T) {
+	stopCh := make(chan struct{})
+	t.Cleanup(func() { close(stopCh) })
+	c.crdInformerFactory.Start(stopCh)
+	c.crdInformerFactory.WaitForCacheSync(stopCh)
+}
+
+func (c *fakeController) getPacketCapture(t *testing.T, name string) *crdv1alpha1.PacketCapture {
+	pc, err := c.crdClient.CrdV1alpha1().PacketCaptures().Get(context.TODO(), name, metav1.GetOptions{})
+	require.NoError(t, err)
+	return pc
+}
+
+// waitForLister waits until the lister returns the provided version of a
+// PacketCapture.
+func (c *fakeController) waitForLister(t *testing.T, pc *crdv1alpha1.PacketCapture) {
+	assert.Eventually(t, func() bool {
+		listed, err := c.packetCaptureLister.Get(pc.Name)
+		return err == nil && listed.ResourceVersion == pc.ResourceVersion
+	}, time.Second, 10*time.Millisecond)
+}
+
+func setupTest(t *testing.T, packets [][]byte) *fakePacketSource {
+	source := &fakePacketSource{packets: packets}
+	prevCaptureDir, prevSetLinkUp, prevOpenPacketSource := captureDir, setLinkUp, openPacketSource
+	captureDir = t.TempDir()
+	setLinkUp = func(name string) (net.HardwareAddr, int, error) {
+		return nil, 0, nil
+	}
+	openPacketSource = func(ifName string) (packetSource, error) {
+		return source, nil
+	}
+	t.Cleanup(func() {
+		captureDir, setLinkUp, openPacketSource = prevCaptureDir, prevSetLinkUp, prevOpenPacketSource
+	})
+	return source
+}
+
+func newRunningPacketCapture(name string, number int32) *crdv1alpha1.PacketCapture {
+	startTime := metav1.Now()
+	return &crdv1alpha1.PacketCapture{
+		ObjectMeta: metav1.ObjectMeta{Name: name},
+		Spec: crdv1alpha1.PacketCaptureSpec{
+			Source:        crdv1alpha1.Source{Namespace: "ns1", Pod: "pod1"},
+			Destination:   crdv1alpha1.Destination{Namespace: "ns1", Pod: "pod2"},
+			CaptureConfig: crdv1alpha1.CaptureConfig{Number: number},
+		},
+		Status: crdv1alpha1.PacketCaptureStatus{
+			Phase:     crdv1alpha1.PacketCaptureRunning,
+			StartTime: &startTime,
+			Node:      nodeName,
+		},
+	}
+}
+
+func (c *fakeController) expectStartCapture(pc *crdv1alpha1.PacketCapture) {
+	portName := getCapturePortName(pc.Name)
+	c.mockOVSBridgeClient.EXPECT().CreateInternalPort(portName, int32(0), "", capturePortExternalIDs).Return("port-uuid", nil)
+	c.mockOVSBridgeClient.EXPECT().GetOFPort(portName, false).Return(pcapOFPort, nil)
+	c.mockOFClient.EXPECT().InstallPacketCaptureFlows(pc.Name, uint32(podOFPort), uint32(pcapOFPort), gomock.Any()).Return(nil)
+}
+
+func (c *fakeController) expectCleanupCapture(pc *crdv1alpha1.PacketCapture) {
+	c.mockOFClient.EXPECT().UninstallPacketCaptureFlows(pc.Name).Return(nil)
+	c.mockOVSBridgeClient.EXPECT().DeletePort("port-uuid").Return(nil)
+}
+
+func TestPacketCapture(t *testing.T) {
+	packets := [][]byte{make([]byte, 64), make([]byte, 128), make([]byte, 96)}
+	source := setupTest(t, packets)
+	pc := newRunningPacketCapture("pc1", 2)
+	c := newFakeController(t, pc)
+	c.start(t)
+
+	c.expectStartCapture(pc)
+	require.NoError(t, c.syncPacketCapture(pc.Name))
+	pc = c.getPacketCapture(t, pc.Name)
+	assert.Equal(t, crdv1alpha1.PacketCaptureRunning, pc.Status.Phase)
+	assert.Equal(t, getCaptureFilePath(pc.Name), pc.Status.FilePath)
+
+	state := c.getCaptureState(pc.Name)
+	require.NotNil(t, state)
+	select {
+	case <-state.doneCh:
+	case <-time.After(time.Second):
+		t.Fatal("Capture was not completed in time")
+	}
+	assert.True(t, source.closed)
+
+	c.expectCleanupCapture(pc)
+	c.waitForLister(t, pc)
+	require.NoError(t, c.syncPacketCapture(pc.Name))
+	pc = c.getPacketCapture(t, pc.Name)
+	assert.Equal(t, crdv1alpha1.PacketCaptureSucceeded, pc.Status.Phase)
+	assert.Equal(t, reasonNumberReached, pc.Status.Reason)
+	assert.Equal(t, int32(2), pc.Status.NumberCaptured)
+
+	// The file contains the Section Header Block, the Interface Description
+	// Block, and one Enhanced Packet Block for each captured packet.
+	data, err := os.ReadFile(pc.Status.FilePath)
+	require.NoError(t, err)
+	assert.Equal(t, (28+20)+(12+20+64)+(12+20+128), len(data))
+
+	// The state is removed and the file is kept once the PacketCapture is
+	// completed.
+	c.waitForLister(t, pc)
+	require.NoError(t, c.syncPacketCapture(pc.Name))
+	assert.Nil(t, c.getCaptureState(pc.Name))
+	assert.FileExists(t, pc.Status.FilePath)
+}
+
+func TestDeleteRunningPacketCapture(t *testing.T) {
+	setupTest(t, nil)
+	pc := newRunningPacketCapture("pc1", 0)
+	c := newFakeController(t, pc)
+	c.start(t)
+
+	c.expectStartCapture(pc)
+	require.NoError(t, c.syncPacketCapture(pc.Name))
+	filePath := getCaptureFilePath(pc.Name)
+	assert.FileExists(t, filePath)
+
+	require.NoError(t, c.crdClient.CrdV1alpha1().PacketCaptures().Delete(context.TODO(), pc.Name, metav1.DeleteOptions{}))
+	assert.Eventually(t, func() bool {
+		_, err := c.packetCaptureLister.Get(pc.Name)
+		return err != nil
+	}, time.Second, 10*time.Millisecond)
+
+	c.expectCleanupCapture(pc)
+	require.NoError(t, c.syncPacketCapture(pc.Name))
+	assert.Nil(t, c.getCaptureState(pc.Name))
+	assert.NoFileExists(t, filePath)
+}
+
+func TestPacketCaptureInterruptedByRestart(t *testing.T) {
+	setupTest(t, nil)
+	pc := newRunningPacketCapture("pc1", 0)
+	pc.Status.FilePath = getCaptureFilePath(pc.Name)
+	pc.Status.NumberCaptured = 5
+	c := newFakeController(t, pc)
+	c.start(t)
+
+	require.NoError(t, c.syncPacketCapture(pc.Name))
+	pc = c.getPacketCapture(t, pc.Name)
+	assert.Equal(t, crdv1alpha1.PacketCaptureFailed, pc.Status.Phase)
+	assert.Equal(t, reasonAgentRestarted, pc.Status.Reason)
+	assert.Equal(t, int32(5), pc.Status.NumberCaptured)
+}
+
+func TestPreparePacket(t *testing.T) {
+	protoICMP := int32(1)
+	for _, tc := range []struct {
+		name           string
+		spec           crdv1alpha1.PacketCaptureSpec
+		expectedPacket *binding.Packet
+		expectedErr    string
+	}{
+		{
+			name: "destination Pod",
+			spec: crdv1alpha1.PacketCaptureSpec{
+				Source:      crdv1alpha1.Source{Namespace: "ns1", Pod: "pod1"},
+				Destination: crdv1alpha1.Destination{Namespace: "ns1", Pod: "pod2"},
+				Packet: crdv1alpha1.Packet{
+					TransportHeader: crdv1alpha1.TransportHeader{TCP: &crdv1alpha1.TCPHeader{SrcPort: 10000, DstPort: 80}},
+				},
+			},
+			expectedPacket: &binding.Packet{DestinationIP: net.ParseIP("10.10.1.2"), IPProto: protocol.Type_TCP, SourcePort: 10000, DestinationPort: 80},
+		},
+		{
+			name: "IPv6 destination Pod",
+			spec: crdv1alpha1.PacketCaptureSpec{
+				Source:      crdv1alpha1.Source{Namespace: "ns1", Pod: "pod1"},
+				Destination: crdv1alpha1.Destination{Namespace: "ns1", Pod: "pod2"},
+				Packet: crdv1alpha1.Packet{
+					IPv6Header:      &crdv1alpha1.IPv6Header{},
+					TransportHeader: crdv1alpha1.TransportHeader{ICMP: &crdv1alpha1.ICMPEchoRequestHeader{}},
+				},
+			},
+			expectedPacket: &binding.Packet{DestinationIP: net.ParseIP("fd00::2"), IsIPv6: true, IPProto: protocol.Type_IPv6ICMP},
+		},
+		{
+			name: "capture on destination",
+			spec: crdv1alpha1.PacketCaptureSpec{
+				Source:       crdv1alpha1.Source{IP: "192.168.1.1"},
+				Destination:  crdv1alpha1.Destination{Namespace: "ns1", Pod: "pod1"},
+				CapturePoint: crdv1alpha1.CapturePointDestination,
+				Packet: crdv1alpha1.Packet{
+					TransportHeader: crdv1alpha1.TransportHeader{UDP: &crdv1alpha1.UDPHeader{SrcPort: 10000, DstPort: 53}},
+				},
+			},
+			expectedPacket: &binding.Packet{DestinationIP: net.ParseIP("192.168.1.1"), IPProto: protocol.Type_UDP, SourcePort: 53, DestinationPort: 10000},
+		},
+		{
+			name: "IP protocol only",
+			spec: crdv1alpha1.PacketCaptureSpec{
+				Source: crdv1alpha1.Source{Namespace: "ns1", Pod: "pod1"},
+				Packet: crdv1alpha1.Packet{IPHeader: crdv1alpha1.IPHeader{Protocol: protoICMP}},
+			},
+			expectedPacket: &binding.Packet{IPProto: protocol.Type_ICMP},
+		},
+		{
+			name: "destination Pod not found",
+			spec: crdv1alpha1.PacketCaptureSpec{
+				Source:      crdv1alpha1.Source{Namespace: "ns1", Pod: "pod1"},
+				Destination: crdv1alpha1.Destination{Namespace: "ns1", Pod: "pod3"},
+			},
+			expectedErr: "failed to get Pod ns1/pod3",
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			c := newFakeController(t)
+			packet, err := c.preparePacket(&crdv1alpha1.PacketCapture{Spec: tc.spec})
+			if tc.expectedErr != "" {
+				assert.ErrorContains(t, err, tc.expectedErr)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tc.expectedPacket, packet)
+		})
+	}
+}
+
+func TestCleanupStaleCaptures(t *testing.T) {
+	setupTest(t, nil)
+	pc := newRunningPacketCapture("pc1", 0)
+	c := newFakeController(t, pc)
+	c.start(t)
+
+	for _, name := range []string{"pc1", "pc2"} {
+		require.NoError(t, os.WriteFile(getCaptureFilePath(name), nil, 0600))
+	}
+	c.mockOVSBridgeClient.EXPECT().GetPortList().Return([]ovsconfig.OVSPortData{
+		{UUID: "uuid1", Name: "pcap-1", ExternalIDs: map[string]string{interfacestore.AntreaInterfaceTypeKey: interfacestore.AntreaPacketCapture}},
+		{UUID: "uuid2", Name: "pod1-abcd", ExternalIDs: map[string]string{interfacestore.AntreaInterfaceTypeKey: interfacestore.AntreaContainer}},
+	}, nil)
+	c.mockOVSBridgeClient.EXPECT().DeletePort("uuid1").Return(nil)
+	c.cleanupStaleCaptures()
+
+	assert.FileExists(t, getCaptureFilePath("pc1"))
+	assert.NoFileExists(t, getCaptureFilePath("pc2"))
+}
@@ -0,0 +1,93 @@
+// Copyright 2022 Antrea Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+//go:build linux
+// +build linux
+
+package packetcapture
+
+import (
+	"fmt"
+	"net"
+
+	"golang.org/x/sys/unix"
+
+	"antrea.io/antrea/pkg/util/pcapng"
+)
+
+// readTimeout is the maximum time ReadPacket blocks when no packet is received.
+var readTimeout = unix.Timeval{Usec: 100000}
+
+// afPacketSource reads the packets received on an interface from an AF_PACKET
+// socket.
+type afPacketSource struct {
+	fd  int
+	buf []byte
+}
+
+// htons converts a uint16 from host to network byte order.
+func htons(v uint16) uint16 {
+	return v<<8 | v>>8
+}
+
+func newPacketSource(ifName string) (packetSource, error) {
+	iface, err := net.InterfaceByName(ifName)
+	if err != nil {
+		return nil, err
+	}
+	fd, err := unix.Socket(unix.AF_PACKET, unix.SOCK_RAW|unix.SOCK_CLOEXEC, int(htons(unix.ETH_P_ALL)))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create AF_PACKET socket: %w", err)
+	}
+	addr := &unix.SockaddrLinklayer{Protocol: htons(unix.ETH_P_ALL), Ifindex: iface.Index}
+	if err := unix.Bind(fd, addr); err != nil {
+		unix.Close(fd)
+		return nil, fmt.Errorf("failed to bind AF_PACKET socket to %s: %w", ifName, err)
+	}
+	if err := unix.SetsockoptTimeval(fd, unix.SOL_SOCKET, unix.SO_RCVTIMEO, &readTimeout); err != nil {
+		unix.Close(fd)
+		return nil, err
+	}
+	return &afPacketSource{fd: fd, buf: make([]byte, pcapng.MaxSnapLength)}, nil
+}
+
+func (s *afPacketSource) ReadPacket() ([]byte, int, error) {
+	for {
+		// With MSG_TRUNC, the length of the packet on the wire is returned
+		// even if it is larger than the buffer.
+		n, from, err := unix.Recvfrom(s.fd, s.buf, unix.MSG_TRUNC)
+		if err != nil {
+			if err == unix.EAGAIN || err == unix.EINTR {
+				return nil, 0, nil
+			}
+			return nil, 0, err
+		}
+		// Ignore the packets sent out of the interface, if any. Only the
+		// packets mirrored by OVS to the interface are captured.
+		if ll, ok := from.(*unix.SockaddrLinklayer); ok && ll.Pkttype == unix.PACKET_OUTGOING {
+			continue
+		}
+		captured := n
+		if captured > len(s.buf) {
+			captured = len(s.buf)
+		}
+		data := make([]byte, captured)
+		copy(data, s.buf[:captured])
+		return data, n, nil
+	}
+}
+
+func (s *afPacketSource) Close() error {
+	return unix.Close(s.fd)
+}
@@ -55,6 +55,15 @@ type externalIPState struct {
 	ip           string
 	ipPool       string
 	assignedNode string
+	// endpointNodes are the Nodes having endpoints of the Service if its external traffic policy is Local, the owner
+	// Node of the IP must be one of them. It's nil if the policy is Cluster.
+	endpointNodes sets.String
+}
+
+// sameNodeSelectionInput returns whether the states have the same input for the node selection of their IPs.
+func (s externalIPState) sameNodeSelectionInput(other externalIPState) bool {
+	return s.ip == other.ip && s.ipPool == other.ipPool &&
+		(s.endpointNodes == nil) == (other.endpointNodes == nil) && s.endpointNodes.Equal(other.endpointNodes)
 }
 
 type ServiceExternalIPController struct {
@@ -158,6 +167,7 @@ func NewServiceExternalIPController(
 	)
 
 	c.cluster.AddClusterEventHandler(c.enqueueServicesByExternalIPPool)
+	c.cluster.AddIPsGetter(c.getExternalIPsByExternalIPPool)
 	return c, nil
 }
 
@@ -280,19 +290,57 @@ func (c *ServiceExternalIPController) processNextWorkItem() bool {
 
 func (c *ServiceExternalIPController) deleteService(service apimachinerytypes.NamespacedName) error {
 	c.externalIPStatesMutex.Lock()
-	defer c.externalIPStatesMutex.Unlock()
 	var state externalIPState
 	var exist bool
 	if state, exist = c.externalIPStates[service]; !exist {
+		c.externalIPStatesMutex.Unlock()
 		return nil
 	}
 	if err := c.unassignIP(state.ip, service); err != nil {
+		c.externalIPStatesMutex.Unlock()
 		return err
 	}
 	delete(c.externalIPStates, service)
+	// The lock must be released before notifying the cluster, as the cluster calls getExternalIPsByExternalIPPool
+	// while holding its own lock.
+	c.externalIPStatesMutex.Unlock()
+	c.notifyIPsChanged(state)
 	return nil
 }
 
+// notifyIPsChanged notifies the cluster that the IPs allocated from the ExternalIPPools of the provided states
+// changed, as the owner Nodes of the other IPs of the ExternalIPPools may depend on them.
+func (c *ServiceExternalIPController) notifyIPsChanged(states ...externalIPState) {
+	notified := sets.NewString()
+	for _, state := range states {
+		if state.ip == "" || state.ipPool == "" || notified.Has(state.ipPool) {
+			continue
+		}
+		c.cluster.NotifyIPsChanged(state.ipPool)
+		notified.Insert(state.ipPool)
+	}
+}
+
+// getExternalIPsByExternalIPPool returns the external IPs of the Services allocated from the provided
+// ExternalIPPool. The IPs of the Services with the Local external traffic policy can only be owned by the Nodes
+// having their endpoints.
+func (c *ServiceExternalIPController) getExternalIPsByExternalIPPool(eipName string) []memberlist.PoolIP {
+	c.externalIPStatesMutex.RLock()
+	defer c.externalIPStatesMutex.RUnlock()
+	var ips []memberlist.PoolIP
+	for _, state := range c.externalIPStates {
+		if state.ip == "" || state.ipPool != eipName {
+			continue
+		}
+		ip := memberlist.PoolIP{IP: state.ip}
+		if state.endpointNodes != nil {
+			ip.Filters = []func(string) bool{state.endpointNodes.Has}
+		}
+		ips = append(ips, ip)
+	}
+	return ips
+}
+
 func (c *ServiceExternalIPController) getServiceState(service *corev1.Service) (externalIPState, bool) {
 	c.externalIPStatesMutex.RLock()
 	defer c.externalIPStatesMutex.RUnlock()
@@ -353,7 +401,12 @@ func (c *ServiceExternalIPController) syncService(key apimachinerytypes.Namespac
 		ip:     currentExternalIP,
 		ipPool: ipPool,
 	}
-	defer c.saveServiceState(service, state)
+	defer func() {
+		c.saveServiceState(service, state)
+		if !exist || !prevState.sameNodeSelectionInput(*state) {
+			c.notifyIPsChanged(prevState, *state)
+		}
+	}()
 
 	if currentExternalIP == "" || ipPool == "" {
 		return nil
@@ -362,6 +415,7 @@ func (c *ServiceExternalIPController) syncService(key apimachinerytypes.Namespac
 	var filters []func(string) bool
 	if service.Spec.ExternalTrafficPolicy == corev1.ServiceExternalTrafficPolicyTypeLocal {
 		nodes, err := c.nodesHasHealthyServiceEndpoint(service)
+		state.endpointNodes = nodes
 		if err != nil {
 			return err
 		}
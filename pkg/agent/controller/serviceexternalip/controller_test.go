@@ -19,8 +19,9 @@ import (
 	"sort"
 	"testing"
 
-	"github.com/golang/mock/gomock"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
 	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
@@ -52,6 +53,8 @@ var (
 type fakeMemberlistCluster struct {
 	nodes  []string
 	hashFn func([]string) []string
+	// notifiedPools are the ExternalIPPools passed to NotifyIPsChanged.
+	notifiedPools []string
 }
 
 func fakeHashFn(invert bool) func([]string) []string {
@@ -76,6 +79,13 @@ var _ memberlist.Interface = (*fakeMemberlistCluster)(nil)
 func (f *fakeMemberlistCluster) AddClusterEventHandler(h memberlist.ClusterNodeEventHandler) {
 }
 
+func (f *fakeMemberlistCluster) AddIPsGetter(getter memberlist.IPsGetter) {
+}
+
+func (f *fakeMemberlistCluster) NotifyIPsChanged(externalIPPool string) {
+	f.notifiedPools = append(f.notifiedPools, externalIPPool)
+}
+
 func (f *fakeMemberlistCluster) AliveNodes() sets.String {
 	return sets.NewString(f.nodes...)
 }
@@ -269,9 +279,10 @@ func TestCreateService(t *testing.T) {
 			},
 			expectedExternalIPStates: map[apimachinerytypes.NamespacedName]externalIPState{
 				keyFor(servicePolicyLocal): {
-					ip:           fakeServiceExternalIP1,
-					ipPool:       fakeExternalIPPoolName,
-					assignedNode: fakeNode1,
+					ip:            fakeServiceExternalIP1,
+					ipPool:        fakeExternalIPPoolName,
+					assignedNode:  fakeNode1,
+					endpointNodes: sets.NewString(fakeNode1),
 				},
 			},
 			expectError: false,
@@ -297,9 +308,10 @@ func TestCreateService(t *testing.T) {
 			expectedCalls:  func(mockIPAssigner *ipassignertest.MockIPAssigner) {},
 			expectedExternalIPStates: map[apimachinerytypes.NamespacedName]externalIPState{
 				keyFor(servicePolicyLocal): {
-					ip:           fakeServiceExternalIP1,
-					ipPool:       fakeExternalIPPoolName,
-					assignedNode: fakeNode2,
+					ip:            fakeServiceExternalIP1,
+					ipPool:        fakeExternalIPPoolName,
+					assignedNode:  fakeNode2,
+					endpointNodes: sets.NewString(fakeNode1, fakeNode2),
 				},
 			},
 			expectError: false,
@@ -321,9 +333,10 @@ func TestCreateService(t *testing.T) {
 			expectedCalls:   func(mockIPAssigner *ipassignertest.MockIPAssigner) {},
 			expectedExternalIPStates: map[apimachinerytypes.NamespacedName]externalIPState{
 				keyFor(servicePolicyLocal): {
-					ip:           fakeServiceExternalIP1,
-					ipPool:       fakeExternalIPPoolName,
-					assignedNode: fakeNode2,
+					ip:            fakeServiceExternalIP1,
+					ipPool:        fakeExternalIPPoolName,
+					assignedNode:  fakeNode2,
+					endpointNodes: sets.NewString(fakeNode2),
 				},
 			},
 			expectError: false,
@@ -344,8 +357,9 @@ func TestCreateService(t *testing.T) {
 			expectedCalls:   func(mockIPAssigner *ipassignertest.MockIPAssigner) {},
 			expectedExternalIPStates: map[apimachinerytypes.NamespacedName]externalIPState{
 				keyFor(servicePolicyLocal): {
-					ip:     fakeServiceExternalIP1,
-					ipPool: fakeExternalIPPoolName,
+					ip:            fakeServiceExternalIP1,
+					ipPool:        fakeExternalIPPoolName,
+					endpointNodes: sets.NewString(),
 				},
 			},
 			expectError: true,
@@ -354,7 +368,7 @@ func TestCreateService(t *testing.T) {
 			name:              "new Service created and local Node selected and IP already assigned by other Service",
 			existingEndpoints: nil,
 			previousExternalIPStates: map[apimachinerytypes.NamespacedName]externalIPState{
-				keyFor(servicePolicyLocal): {fakeServiceExternalIP1, fakeExternalIPPoolName, fakeNode1},
+				keyFor(servicePolicyLocal): {fakeServiceExternalIP1, fakeExternalIPPoolName, fakeNode1, nil},
 			},
 			serviceToCreate: servicePolicyCluster,
 			healthyNodes:    []string{fakeNode1, fakeNode2},
@@ -443,10 +457,10 @@ func TestUpdateService(t *testing.T) {
 			endpoints:       nil,
 			serviceToUpdate: serviceExternalTrafficPolicyClusterUpdatedExternalIP,
 			previousExternalIPStates: map[apimachinerytypes.NamespacedName]externalIPState{
-				keyFor(serviceExternalTrafficPolicyClusterUpdatedExternalIP): {fakeServiceExternalIP1, fakeExternalIPPoolName, fakeNode1},
+				keyFor(serviceExternalTrafficPolicyClusterUpdatedExternalIP): {fakeServiceExternalIP1, fakeExternalIPPoolName, fakeNode1, nil},
 			},
 			expectedExternalIPStates: map[apimachinerytypes.NamespacedName]externalIPState{
-				keyFor(serviceExternalTrafficPolicyClusterUpdatedExternalIP): {fakeServiceExternalIP2, fakeExternalIPPoolName, fakeNode1},
+				keyFor(serviceExternalTrafficPolicyClusterUpdatedExternalIP): {fakeServiceExternalIP2, fakeExternalIPPoolName, fakeNode1, nil},
 			},
 			healthyNodes: []string{fakeNode1, fakeNode2},
 			expectedCalls: func(mockIPAssigner *ipassignertest.MockIPAssigner) {
@@ -460,12 +474,12 @@ func TestUpdateService(t *testing.T) {
 			endpoints:       nil,
 			serviceToUpdate: serviceExternalTrafficPolicyClusterUpdatedExternalIP,
 			previousExternalIPStates: map[apimachinerytypes.NamespacedName]externalIPState{
-				keyFor(serviceExternalTrafficPolicyClusterWithSameExternalIP): {fakeServiceExternalIP1, fakeExternalIPPoolName, fakeNode1},
-				keyFor(serviceExternalTrafficPolicyClusterUpdatedExternalIP):  {fakeServiceExternalIP1, fakeExternalIPPoolName, fakeNode1},
+				keyFor(serviceExternalTrafficPolicyClusterWithSameExternalIP): {fakeServiceExternalIP1, fakeExternalIPPoolName, fakeNode1, nil},
+				keyFor(serviceExternalTrafficPolicyClusterUpdatedExternalIP):  {fakeServiceExternalIP1, fakeExternalIPPoolName, fakeNode1, nil},
 			},
 			expectedExternalIPStates: map[apimachinerytypes.NamespacedName]externalIPState{
-				keyFor(serviceExternalTrafficPolicyClusterWithSameExternalIP): {fakeServiceExternalIP1, fakeExternalIPPoolName, fakeNode1},
-				keyFor(serviceExternalTrafficPolicyClusterUpdatedExternalIP):  {fakeServiceExternalIP2, fakeExternalIPPoolName, fakeNode1},
+				keyFor(serviceExternalTrafficPolicyClusterWithSameExternalIP): {fakeServiceExternalIP1, fakeExternalIPPoolName, fakeNode1, nil},
+				keyFor(serviceExternalTrafficPolicyClusterUpdatedExternalIP):  {fakeServiceExternalIP2, fakeExternalIPPoolName, fakeNode1, nil},
 			},
 			healthyNodes: []string{fakeNode1, fakeNode2},
 			expectedCalls: func(mockIPAssigner *ipassignertest.MockIPAssigner) {
@@ -478,10 +492,10 @@ func TestUpdateService(t *testing.T) {
 			endpoints:       nil,
 			serviceToUpdate: serviceExternalTrafficPolicyClusterUpdatedExternalIP,
 			previousExternalIPStates: map[apimachinerytypes.NamespacedName]externalIPState{
-				keyFor(serviceExternalTrafficPolicyClusterUpdatedExternalIP): {fakeServiceExternalIP1, fakeExternalIPPoolName, fakeNode1},
+				keyFor(serviceExternalTrafficPolicyClusterUpdatedExternalIP): {fakeServiceExternalIP1, fakeExternalIPPoolName, fakeNode1, nil},
 			},
 			expectedExternalIPStates: map[apimachinerytypes.NamespacedName]externalIPState{
-				keyFor(serviceExternalTrafficPolicyClusterUpdatedExternalIP): {fakeServiceExternalIP2, fakeExternalIPPoolName, fakeNode2},
+				keyFor(serviceExternalTrafficPolicyClusterUpdatedExternalIP): {fakeServiceExternalIP2, fakeExternalIPPoolName, fakeNode2, nil},
 			},
 			healthyNodes:   []string{fakeNode1, fakeNode2},
 			overrideHashFn: fakeHashFn(true),
@@ -495,7 +509,7 @@ func TestUpdateService(t *testing.T) {
 			endpoints:       nil,
 			serviceToUpdate: serviceChangedType,
 			previousExternalIPStates: map[apimachinerytypes.NamespacedName]externalIPState{
-				keyFor(serviceExternalTrafficPolicyClusterUpdatedExternalIP): {fakeServiceExternalIP1, fakeExternalIPPoolName, fakeNode1},
+				keyFor(serviceExternalTrafficPolicyClusterUpdatedExternalIP): {fakeServiceExternalIP1, fakeExternalIPPoolName, fakeNode1, nil},
 			},
 			expectedExternalIPStates: map[apimachinerytypes.NamespacedName]externalIPState{},
 			healthyNodes:             []string{fakeNode1, fakeNode2},
@@ -509,7 +523,7 @@ func TestUpdateService(t *testing.T) {
 			endpoints:       nil,
 			serviceToUpdate: serviceChangedType,
 			previousExternalIPStates: map[apimachinerytypes.NamespacedName]externalIPState{
-				keyFor(serviceExternalTrafficPolicyClusterUpdatedExternalIP): {fakeServiceExternalIP1, fakeExternalIPPoolName, fakeNode1},
+				keyFor(serviceExternalTrafficPolicyClusterUpdatedExternalIP): {fakeServiceExternalIP1, fakeExternalIPPoolName, fakeNode1, nil},
 			},
 			expectedExternalIPStates: map[apimachinerytypes.NamespacedName]externalIPState{},
 			healthyNodes:             []string{fakeNode1, fakeNode2},
@@ -530,10 +544,10 @@ func TestUpdateService(t *testing.T) {
 			},
 			serviceToUpdate: serviceChangedExternalTrafficPolicy,
 			previousExternalIPStates: map[apimachinerytypes.NamespacedName]externalIPState{
-				keyFor(serviceChangedExternalTrafficPolicy): {fakeServiceExternalIP1, fakeExternalIPPoolName, fakeNode1},
+				keyFor(serviceChangedExternalTrafficPolicy): {fakeServiceExternalIP1, fakeExternalIPPoolName, fakeNode1, nil},
 			},
 			expectedExternalIPStates: map[apimachinerytypes.NamespacedName]externalIPState{
-				keyFor(serviceChangedExternalTrafficPolicy): {fakeServiceExternalIP1, fakeExternalIPPoolName, fakeNode2},
+				keyFor(serviceChangedExternalTrafficPolicy): {fakeServiceExternalIP1, fakeExternalIPPoolName, fakeNode2, sets.NewString(fakeNode2)},
 			},
 			healthyNodes: []string{fakeNode1, fakeNode2},
 			expectedCalls: func(mockIPAssigner *ipassignertest.MockIPAssigner) {
@@ -546,10 +560,10 @@ func TestUpdateService(t *testing.T) {
 			endpoints:       nil,
 			serviceToUpdate: servicePolicyCluster,
 			previousExternalIPStates: map[apimachinerytypes.NamespacedName]externalIPState{
-				keyFor(servicePolicyCluster): {fakeServiceExternalIP1, fakeExternalIPPoolName, fakeNode1},
+				keyFor(servicePolicyCluster): {fakeServiceExternalIP1, fakeExternalIPPoolName, fakeNode1, nil},
 			},
 			expectedExternalIPStates: map[apimachinerytypes.NamespacedName]externalIPState{
-				keyFor(servicePolicyCluster): {fakeServiceExternalIP1, fakeExternalIPPoolName, fakeNode2},
+				keyFor(servicePolicyCluster): {fakeServiceExternalIP1, fakeExternalIPPoolName, fakeNode2, nil},
 			},
 			healthyNodes:   []string{fakeNode1, fakeNode2},
 			overrideHashFn: fakeHashFn(true),
@@ -570,10 +584,10 @@ func TestUpdateService(t *testing.T) {
 			},
 			serviceToUpdate: servicePolicyLocal,
 			previousExternalIPStates: map[apimachinerytypes.NamespacedName]externalIPState{
-				keyFor(servicePolicyLocal): {fakeServiceExternalIP1, fakeExternalIPPoolName, fakeNode1},
+				keyFor(servicePolicyLocal): {fakeServiceExternalIP1, fakeExternalIPPoolName, fakeNode1, nil},
 			},
 			expectedExternalIPStates: map[apimachinerytypes.NamespacedName]externalIPState{
-				keyFor(servicePolicyLocal): {fakeServiceExternalIP1, fakeExternalIPPoolName, fakeNode2},
+				keyFor(servicePolicyLocal): {fakeServiceExternalIP1, fakeExternalIPPoolName, fakeNode2, sets.NewString(fakeNode2)},
 			},
 			healthyNodes: []string{fakeNode1, fakeNode2},
 			expectedCalls: func(mockIPAssigner *ipassignertest.MockIPAssigner) {
@@ -715,7 +729,7 @@ func TestServiceExternalIPController_GetServiceExternalIPStatus(t *testing.T) {
 		{
 			name: "one Service processed",
 			externalIPStates: map[apimachinerytypes.NamespacedName]externalIPState{
-				keyFor(servicePolicyCluster): {fakeServiceExternalIP1, fakeExternalIPPoolName, fakeNode1},
+				keyFor(servicePolicyCluster): {fakeServiceExternalIP1, fakeExternalIPPoolName, fakeNode1, nil},
 			},
 			expectedServiceExternalIPInfo: []querier.ServiceExternalIPInfo{
 				{
@@ -731,8 +745,8 @@ func TestServiceExternalIPController_GetServiceExternalIPStatus(t *testing.T) {
 		{
 			name: "two Services processed",
 			externalIPStates: map[apimachinerytypes.NamespacedName]externalIPState{
-				keyFor(servicePolicyCluster): {fakeServiceExternalIP1, fakeExternalIPPoolName, fakeNode1},
-				keyFor(servicePolicyLocal):   {fakeServiceExternalIP2, fakeExternalIPPoolName, fakeNode2},
+				keyFor(servicePolicyCluster): {fakeServiceExternalIP1, fakeExternalIPPoolName, fakeNode1, nil},
+				keyFor(servicePolicyLocal):   {fakeServiceExternalIP2, fakeExternalIPPoolName, fakeNode2, nil},
 			},
 			expectedServiceExternalIPInfo: []querier.ServiceExternalIPInfo{
 				{
@@ -763,3 +777,44 @@ func TestServiceExternalIPController_GetServiceExternalIPStatus(t *testing.T) {
 		})
 	}
 }
+
+func TestGetExternalIPsByExternalIPPool(t *testing.T) {
+	endpoints := makeEndpoints(servicePolicyLocal.Name, servicePolicyLocal.Namespace,
+		map[string]string{
+			"2.3.4.5": fakeNode1,
+		}, nil)
+	servicePolicyLocalOtherIP := servicePolicyLocal.DeepCopy()
+	servicePolicyLocalOtherIP.Status.LoadBalancer.Ingress[0].IP = fakeServiceExternalIP2
+	c := newFakeController(t, servicePolicyCluster, servicePolicyLocalOtherIP, endpoints)
+	defer c.mockController.Finish()
+	stopCh := make(chan struct{})
+	defer close(stopCh)
+	c.informerFactory.Start(stopCh)
+	c.informerFactory.WaitForCacheSync(stopCh)
+	c.fakeMemberlistCluster.nodes = []string{fakeNode1, fakeNode2}
+
+	c.mockIPAssigner.EXPECT().AssignIP(fakeServiceExternalIP1)
+	c.mockIPAssigner.EXPECT().AssignIP(fakeServiceExternalIP2)
+	require.NoError(t, c.syncService(keyFor(servicePolicyCluster)))
+	require.NoError(t, c.syncService(keyFor(servicePolicyLocalOtherIP)))
+	// The pool is notified once for each new IP, and not when the Service is synced again without change.
+	require.NoError(t, c.syncService(keyFor(servicePolicyCluster)))
+	assert.Equal(t, []string{fakeExternalIPPoolName, fakeExternalIPPoolName}, c.fakeMemberlistCluster.notifiedPools)
+
+	ips := c.getExternalIPsByExternalIPPool(fakeExternalIPPoolName)
+	sort.Slice(ips, func(i, j int) bool {
+		return ips[i].IP < ips[j].IP
+	})
+	require.Len(t, ips, 2)
+	assert.Equal(t, fakeServiceExternalIP1, ips[0].IP)
+	assert.Empty(t, ips[0].Filters)
+	assert.Equal(t, fakeServiceExternalIP2, ips[1].IP)
+	require.Len(t, ips[1].Filters, 1)
+	assert.True(t, ips[1].Filters[0](fakeNode1))
+	assert.False(t, ips[1].Filters[0](fakeNode2))
+	assert.Empty(t, c.getExternalIPsByExternalIPPool("other-pool"))
+
+	c.mockIPAssigner.EXPECT().UnassignIP(fakeServiceExternalIP1)
+	require.NoError(t, c.deleteService(keyFor(servicePolicyCluster)))
+	assert.Len(t, c.fakeMemberlistCluster.notifiedPools, 3)
+}
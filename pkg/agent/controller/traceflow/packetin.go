@@ -38,7 +38,7 @@ func (c *Controller) HandlePacketIn(pktIn *ofctrl.PacketIn) error {
 	if !c.traceflowListerSynced() {
 		return errors.New("traceflow controller is not started")
 	}
-	oldTf, nodeResult, packet, err := c.parsePacketIn(pktIn)
+	oldTf, nodeResult, packet, isReply, err := c.parsePacketIn(pktIn)
 	if err != nil {
 		return fmt.Errorf("parsePacketIn error: %v", err)
 	}
@@ -51,7 +51,11 @@ func (c *Controller) HandlePacketIn(pktIn *ofctrl.PacketIn) error {
 			return err
 		}
 		update := tf.DeepCopy()
-		update.Status.Results = append(update.Status.Results, *nodeResult)
+		if isReply {
+			update.Status.ReplyResults = append(update.Status.ReplyResults, *nodeResult)
+		} else {
+			update.Status.Results = append(update.Status.Results, *nodeResult)
+		}
 		if packet != nil {
 			update.Status.CapturedPacket = packet
 		}
@@ -69,7 +73,7 @@ func (c *Controller) HandlePacketIn(pktIn *ofctrl.PacketIn) error {
 	return nil
 }
 
-func (c *Controller) parsePacketIn(pktIn *ofctrl.PacketIn) (*crdv1alpha1.Traceflow, *crdv1alpha1.NodeResult, *crdv1alpha1.Packet, error) {
+func (c *Controller) parsePacketIn(pktIn *ofctrl.PacketIn) (*crdv1alpha1.Traceflow, *crdv1alpha1.NodeResult, *crdv1alpha1.Packet, bool, error) {
 	matchers := pktIn.GetMatches()
 
 	// Get data plane tag.
@@ -79,54 +83,61 @@ func (c *Controller) parsePacketIn(pktIn *ofctrl.PacketIn) (*crdv1alpha1.Tracefl
 	var ctNwDst, ctNwSrc, ipDst, ipSrc string
 	etherData := new(protocol.Ethernet)
 	if err := etherData.UnmarshalBinary(pktIn.Data.(*util.Buffer).Bytes()); err != nil {
-		return nil, nil, nil, fmt.Errorf("failed to parse Ethernet packet from packet-in message: %v", err)
+		return nil, nil, nil, false, fmt.Errorf("failed to parse Ethernet packet from packet-in message: %v", err)
 	}
 	if etherData.Ethertype == protocol.IPv4_MSG {
 		ipPacket, ok := etherData.Data.(*protocol.IPv4)
 		if !ok {
-			return nil, nil, nil, errors.New("invalid traceflow IPv4 packet")
+			return nil, nil, nil, false, errors.New("invalid traceflow IPv4 packet")
 		}
 		tag = ipPacket.DSCP
 		ctNwDst, err = getCTDstValue(matchers, false)
 		if err != nil {
-			return nil, nil, nil, err
+			return nil, nil, nil, false, err
 		}
 		ctNwSrc, err = getCTSrcValue(matchers, false)
 		if err != nil {
-			return nil, nil, nil, err
+			return nil, nil, nil, false, err
 		}
 		ipDst = ipPacket.NWDst.String()
 		ipSrc = ipPacket.NWSrc.String()
 	} else if etherData.Ethertype == protocol.IPv6_MSG {
 		ipv6Packet, ok := etherData.Data.(*protocol.IPv6)
 		if !ok {
-			return nil, nil, nil, errors.New("invalid traceflow IPv6 packet")
+			return nil, nil, nil, false, errors.New("invalid traceflow IPv6 packet")
 		}
 		tag = ipv6Packet.TrafficClass >> 2
 		ctNwDst, err = getCTDstValue(matchers, true)
 		if err != nil {
-			return nil, nil, nil, err
+			return nil, nil, nil, false, err
 		}
 		ctNwSrc, err = getCTSrcValue(matchers, true)
 		if err != nil {
-			return nil, nil, nil, err
+			return nil, nil, nil, false, err
 		}
 		ipDst = ipv6Packet.NWDst.String()
 		ipSrc = ipv6Packet.NWSrc.String()
 	} else {
-		return nil, nil, nil, fmt.Errorf("unsupported traceflow packet Ethertype: %d", etherData.Ethertype)
+		return nil, nil, nil, false, fmt.Errorf("unsupported traceflow packet Ethertype: %d", etherData.Ethertype)
 	}
 
 	firstPacket := false
+	isReply := false
+	var injectionTime time.Time
 	c.runningTraceflowsMutex.RLock()
 	tfState, exists := c.runningTraceflows[tag]
 	if exists {
+		injectionTime = tfState.injectionTime
 		firstPacket = !tfState.receivedPacket
 		tfState.receivedPacket = true
+		// For bidirectional Traceflow, the packets received after this
+		// Node injected the reply packet, and the packets to the source
+		// Pod on the sender Node, are reply packets.
+		isReply = tfState.bidirectional && (tfState.replyInjected || tfState.isSender && ipDst == tfState.srcIP)
 	}
 	c.runningTraceflowsMutex.RUnlock()
 	if !exists {
-		return nil, nil, nil, fmt.Errorf("Traceflow for dataplane tag %d not found in cache", tag)
+		return nil, nil, nil, false, fmt.Errorf("Traceflow for dataplane tag %d not found in cache", tag)
 	}
 
 	var capturedPacket *crdv1alpha1.Packet
@@ -146,12 +157,17 @@ func (c *Controller) parsePacketIn(pktIn *ofctrl.PacketIn) (*crdv1alpha1.Tracefl
 
 	tf, err := c.traceflowLister.Get(tfState.name)
 	if err != nil {
-		return nil, nil, nil, fmt.Errorf("failed to get Traceflow %s CRD: %v", tfState.name, err)
+		return nil, nil, nil, false, fmt.Errorf("failed to get Traceflow %s CRD: %v", tfState.name, err)
 	}
 
 	obs := []crdv1alpha1.Observation{}
 	tableID := pktIn.TableId
-	if tfState.isSender {
+	// The reply packet is sent by the Node which injected it.
+	isSender := tfState.isSender
+	if isReply {
+		isSender = tfState.replyInjected
+	}
+	if isSender {
 		ob := new(crdv1alpha1.Observation)
 		ob.Component = crdv1alpha1.ComponentSpoofGuard
 		ob.Action = crdv1alpha1.ActionForwarded
@@ -167,8 +183,10 @@ func (c *Controller) parsePacketIn(pktIn *ofctrl.PacketIn) (*crdv1alpha1.Tracefl
 	// - For packet is DNATed only, the final state is that ipDst != ctNwDst (in DNAT CT zone).
 	// - For packet is both DNATed and SNATed, the first state is also ipDst != ctNwDst (in DNAT CT zone), but the final
 	//   state is that ipSrc != ctNwSrc (in SNAT CT zone). The state in DNAT CT zone cannot be recognized in SNAT CT zone.
+	// - The reply packet is translated back with the connection of the request packet, for which the Service
+	//   connection is already collected.
 	if !tfState.receiverOnly {
-		if isValidCtNw(ctNwDst) && ipDst != ctNwDst || isValidCtNw(ctNwSrc) && ipSrc != ctNwSrc {
+		if !isReply && (isValidCtNw(ctNwDst) && ipDst != ctNwDst || isValidCtNw(ctNwSrc) && ipSrc != ctNwSrc) {
 			ob := &crdv1alpha1.Observation{
 				Component:       crdv1alpha1.ComponentLB,
 				Action:          crdv1alpha1.ActionForwarded,
@@ -183,7 +201,7 @@ func (c *Controller) parsePacketIn(pktIn *ofctrl.PacketIn) (*crdv1alpha1.Tracefl
 		if match := getMatchRegField(matchers, openflow.TFEgressConjIDField); match != nil {
 			egressInfo, err := getRegValue(match, nil)
 			if err != nil {
-				return nil, nil, nil, err
+				return nil, nil, nil, false, err
 			}
 			ob := getNetworkPolicyObservation(tableID, false)
 			npRef := c.networkPolicyQuerier.GetNetworkPolicyByRuleFlowID(egressInfo)
@@ -198,7 +216,7 @@ func (c *Controller) parsePacketIn(pktIn *ofctrl.PacketIn) (*crdv1alpha1.Tracefl
 	if match := getMatchRegField(matchers, openflow.TFIngressConjIDField); match != nil {
 		ingressInfo, err := getRegValue(match, nil)
 		if err != nil {
-			return nil, nil, nil, err
+			return nil, nil, nil, false, err
 		}
 		ob := getNetworkPolicyObservation(tableID, true)
 		npRef := c.networkPolicyQuerier.GetNetworkPolicyByRuleFlowID(ingressInfo)
@@ -214,7 +232,7 @@ func (c *Controller) parsePacketIn(pktIn *ofctrl.PacketIn) (*crdv1alpha1.Tracefl
 		if match := getMatchRegField(matchers, openflow.CNPConjIDField); match != nil {
 			notAllowConjInfo, err := getRegValue(match, nil)
 			if err != nil {
-				return nil, nil, nil, err
+				return nil, nil, nil, false, err
 			}
 			if ruleRef := c.networkPolicyQuerier.GetRuleByFlowID(notAllowConjInfo); ruleRef != nil {
 				if npRef := ruleRef.PolicyRef; npRef != nil {
@@ -232,6 +250,7 @@ func (c *Controller) parsePacketIn(pktIn *ofctrl.PacketIn) (*crdv1alpha1.Tracefl
 	}
 
 	// Get output table.
+	var replyInPort uint32
 	if tableID == openflow.L2ForwardingOutTable.GetID() {
 		ob := new(crdv1alpha1.Observation)
 		tunnelDstIP := ""
@@ -239,14 +258,14 @@ func (c *Controller) parsePacketIn(pktIn *ofctrl.PacketIn) (*crdv1alpha1.Tracefl
 		if match := getMatchTunnelDstField(matchers, isIPv6); match != nil {
 			tunnelDstIP, err = getTunnelDstValue(match)
 			if err != nil {
-				return nil, nil, nil, err
+				return nil, nil, nil, false, err
 			}
 		}
 		var outputPort uint32
 		if match := getMatchRegField(matchers, openflow.TargetOFPortField); match != nil {
 			outputPort, err = getRegValue(match, nil)
 			if err != nil {
-				return nil, nil, nil, err
+				return nil, nil, nil, false, err
 			}
 		}
 		gatewayIP := c.nodeConfig.GatewayConfig.IPv4
@@ -267,14 +286,87 @@ func (c *Controller) parsePacketIn(pktIn *ofctrl.PacketIn) (*crdv1alpha1.Tracefl
 		} else {
 			// Output port is Pod port, packet is delivered.
 			ob.Action = crdv1alpha1.ActionDelivered
+			if tfState.bidirectional && !isReply {
+				replyInPort = outputPort
+			}
 		}
 		ob.ComponentInfo = openflow.L2ForwardingOutTable.GetName()
 		ob.Component = crdv1alpha1.ComponentForwarding
 		obs = append(obs, *ob)
 	}
 
-	nodeResult := crdv1alpha1.NodeResult{Node: c.nodeConfig.Name, Timestamp: time.Now().Unix(), Observations: obs}
-	return tf, &nodeResult, capturedPacket, nil
+	if replyInPort != 0 {
+		if err := c.injectReplyPacket(tfState, pktIn, replyInPort); err != nil {
+			klog.ErrorS(err, "Failed to inject reply packet", "Traceflow", tf.Name)
+			c.errorTraceflowCRD(tf.DeepCopy(), fmt.Sprintf("Node: %s, error: failed to inject reply packet: %+v", c.nodeConfig.Name, err))
+		}
+	}
+
+	now := time.Now()
+	nodeResult := crdv1alpha1.NodeResult{Node: c.nodeConfig.Name, Timestamp: now.Unix(), Observations: obs}
+	// The timestamps are only reported for the Traceflow packet, which is
+	// used to compute the latency of ConnectivityProbes.
+	if !isReply {
+		if !injectionTime.IsZero() {
+			nodeResult.InjectionTimestampMilliseconds = injectionTime.UnixMilli()
+		}
+		for _, ob := range obs {
+			if ob.Action == crdv1alpha1.ActionDelivered || ob.Action == crdv1alpha1.ActionForwardedOutOfOverlay {
+				nodeResult.DeliveryTimestampMilliseconds = now.UnixMilli()
+				break
+			}
+		}
+	}
+	return tf, &nodeResult, capturedPacket, isReply, nil
+}
+
+// injectReplyPacket injects the reply of the Traceflow packet delivered to the
+// destination Pod of a bidirectional Traceflow, from the port of the Pod.
+func (c *Controller) injectReplyPacket(tfState *traceflowState, pktIn *ofctrl.PacketIn, inPort uint32) error {
+	pkt, err := binding.ParsePacketIn(pktIn)
+	if err != nil {
+		return err
+	}
+	// Mark the reply packet as injected first, so that its packet-in
+	// messages are handled as reply packets.
+	c.runningTraceflowsMutex.Lock()
+	tfState.replyInjected = true
+	c.runningTraceflowsMutex.Unlock()
+	klog.V(2).InfoS("Injecting reply packet for Traceflow", "Traceflow", tfState.name)
+	return c.ofClient.SendTraceflowPacket(tfState.tag, prepareReplyPacket(pkt), inPort, -1)
+}
+
+// prepareReplyPacket returns the reply of the delivered Traceflow packet: a
+// TCP SYN/ACK (or ACK) packet, a UDP packet, or an ICMP echo reply, sent back
+// to the source of the packet.
+func prepareReplyPacket(pkt *binding.Packet) *binding.Packet {
+	reply := &binding.Packet{
+		IsIPv6:          pkt.IsIPv6,
+		SourceMAC:       pkt.DestinationMAC,
+		DestinationMAC:  pkt.SourceMAC,
+		SourceIP:        pkt.DestinationIP,
+		DestinationIP:   pkt.SourceIP,
+		IPProto:         pkt.IPProto,
+		TTL:             defaultTTL,
+		SourcePort:      pkt.DestinationPort,
+		DestinationPort: pkt.SourcePort,
+	}
+	switch pkt.IPProto {
+	case protocol.Type_TCP:
+		reply.TCPFlags = tcpFlagACK
+		if pkt.TCPFlags&tcpFlagSYN != 0 {
+			reply.TCPFlags |= tcpFlagSYN
+		}
+	case protocol.Type_ICMP:
+		reply.ICMPType = icmpEchoReplyType
+		reply.ICMPEchoID = pkt.ICMPEchoID
+		reply.ICMPEchoSeq = pkt.ICMPEchoSeq
+	case protocol.Type_IPv6ICMP:
+		reply.ICMPType = icmpv6EchoReplyType
+		reply.ICMPEchoID = pkt.ICMPEchoID
+		reply.ICMPEchoSeq = pkt.ICMPEchoSeq
+	}
+	return reply
 }
 
 func getMatchRegField(matchers *ofctrl.Matchers, field *binding.RegField) *ofctrl.MatchField {
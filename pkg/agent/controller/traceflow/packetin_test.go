@@ -26,6 +26,7 @@ import (
 
 	"antrea.io/antrea/pkg/agent/openflow"
 	crdv1alpha1 "antrea.io/antrea/pkg/apis/crd/v1alpha1"
+	binding "antrea.io/antrea/pkg/ovs/openflow"
 )
 
 func prepareMockTables() {
@@ -181,3 +182,55 @@ func TestParseCapturedPacket(t *testing.T) {
 		})
 	}
 }
+
+func TestPrepareReplyPacket(t *testing.T) {
+	srcMAC, _ := net.ParseMAC("aa:bb:cc:dd:ee:01")
+	dstMAC, _ := net.ParseMAC("aa:bb:cc:dd:ee:02")
+	srcIP := net.ParseIP("10.1.1.11")
+	dstIP := net.ParseIP("10.1.2.12")
+
+	tests := []struct {
+		name     string
+		packet   binding.Packet
+		expected binding.Packet
+	}{
+		{
+			name: "tcp syn",
+			packet: binding.Packet{
+				SourceMAC: srcMAC, DestinationMAC: dstMAC, SourceIP: srcIP, DestinationIP: dstIP, TTL: 63,
+				IPProto: protocol.Type_TCP, SourcePort: 1080, DestinationPort: 80, TCPFlags: tcpFlagSYN,
+			},
+			expected: binding.Packet{
+				SourceMAC: dstMAC, DestinationMAC: srcMAC, SourceIP: dstIP, DestinationIP: srcIP, TTL: defaultTTL,
+				IPProto: protocol.Type_TCP, SourcePort: 80, DestinationPort: 1080, TCPFlags: tcpFlagSYN | tcpFlagACK,
+			},
+		},
+		{
+			name: "udp",
+			packet: binding.Packet{
+				SourceMAC: srcMAC, DestinationMAC: dstMAC, SourceIP: srcIP, DestinationIP: dstIP, TTL: 63,
+				IPProto: protocol.Type_UDP, SourcePort: 1080, DestinationPort: 53,
+			},
+			expected: binding.Packet{
+				SourceMAC: dstMAC, DestinationMAC: srcMAC, SourceIP: dstIP, DestinationIP: srcIP, TTL: defaultTTL,
+				IPProto: protocol.Type_UDP, SourcePort: 53, DestinationPort: 1080,
+			},
+		},
+		{
+			name: "icmp echo request",
+			packet: binding.Packet{
+				SourceMAC: srcMAC, DestinationMAC: dstMAC, SourceIP: srcIP, DestinationIP: dstIP, TTL: 63,
+				IPProto: protocol.Type_ICMP, ICMPType: icmpEchoRequestType, ICMPEchoID: 1, ICMPEchoSeq: 123,
+			},
+			expected: binding.Packet{
+				SourceMAC: dstMAC, DestinationMAC: srcMAC, SourceIP: dstIP, DestinationIP: srcIP, TTL: defaultTTL,
+				IPProto: protocol.Type_ICMP, ICMPType: icmpEchoReplyType, ICMPEchoID: 1, ICMPEchoSeq: 123,
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, &tt.expected, prepareReplyPacket(&tt.packet))
+		})
+	}
+}
@@ -68,6 +68,14 @@ const (
 	icmpv6EchoRequestType uint8 = 128
 	icmpEchoRequestCode   uint8 = 0
 
+	// ICMP Echo Reply type, for the reply packet of bidirectional Traceflow.
+	icmpEchoReplyType   uint8 = 0
+	icmpv6EchoReplyType uint8 = 129
+
+	// TCP flags.
+	tcpFlagSYN uint8 = 0x02
+	tcpFlagACK uint8 = 0x10
+
 	defaultTTL uint8 = 64
 )
 
@@ -81,6 +89,16 @@ type traceflowState struct {
 	isSender     bool
 	// Agent received the first Traceflow packet from OVS.
 	receivedPacket bool
+	// Bidirectional Traceflow, for which the destination Node injects the
+	// reply packet.
+	bidirectional bool
+	// Source IP of the packet injected by the sender Node, which is the
+	// destination IP of the reply packet.
+	srcIP string
+	// Agent injected the reply packet from the destination Pod.
+	replyInjected bool
+	// Time at which the sender Node injected the Traceflow packet.
+	injectionTime time.Time
 }
 
 // Controller is responsible for setting up Openflow entries and injecting traceflow packet into
@@ -264,6 +282,12 @@ func (c *Controller) syncTraceflow(traceflowName string) error {
 
 	switch tf.Status.Phase {
 	case crdv1alpha1.Running:
+		if !c.isTagReservedOnNode(tf) {
+			// The packets of the Traceflow cannot traverse this Node, and
+			// the same tag may be used by another Traceflow on this Node.
+			klog.V(2).InfoS("Skipping Traceflow which does not involve this Node", "Traceflow", tf.Name, "nodes", tf.Status.DataplaneTagNodes)
+			return nil
+		}
 		if tf.Status.DataplaneTag != 0 {
 			start := false
 			c.runningTraceflowsMutex.Lock()
@@ -283,6 +307,21 @@ func (c *Controller) syncTraceflow(traceflowName string) error {
 	return err
 }
 
+// isTagReservedOnNode returns whether the data plane tag of a Traceflow is
+// reserved on the current Node. The tag is reserved on all Nodes if the
+// Controller did not restrict it to the Nodes involved in the Traceflow.
+func (c *Controller) isTagReservedOnNode(tf *crdv1alpha1.Traceflow) bool {
+	if len(tf.Status.DataplaneTagNodes) == 0 {
+		return true
+	}
+	for _, node := range tf.Status.DataplaneTagNodes {
+		if node == c.nodeConfig.Name {
+			return true
+		}
+	}
+	return false
+}
+
 // startTraceflow deploys OVS flow entries for Traceflow and inject packet if current Node
 // is Sender Node.
 func (c *Controller) startTraceflow(tf *crdv1alpha1.Traceflow) error {
@@ -298,12 +337,12 @@ func (c *Controller) startTraceflow(tf *crdv1alpha1.Traceflow) error {
 	}
 
 	liveTraffic := tf.Spec.LiveTraffic
-	if tf.Spec.Source.Pod == "" && tf.Spec.Destination.Pod == "" {
+	if liveTraffic && tf.Spec.Source.Pod == "" && tf.Spec.Destination.Pod == "" {
 		klog.Errorf("Traceflow %s has neither source nor destination Pod specified", tf.Name)
 		return nil
 	}
-	if tf.Spec.Source.Pod == "" && !liveTraffic {
-		klog.Errorf("Traceflow %s does not have source Pod specified", tf.Name)
+	if !liveTraffic && tf.Spec.Source.Pod == "" && tf.Spec.Source.Node == "" {
+		klog.Errorf("Traceflow %s does not have source Pod or Node specified", tf.Name)
 		return nil
 	}
 
@@ -312,7 +351,7 @@ func (c *Controller) startTraceflow(tf *crdv1alpha1.Traceflow) error {
 	if tf.Spec.Source.Pod != "" {
 		pod = tf.Spec.Source.Pod
 		ns = tf.Spec.Source.Namespace
-	} else {
+	} else if liveTraffic {
 		// Live-traffic Traceflow with only the Destination Pod specified.
 		pod = tf.Spec.Destination.Pod
 		ns = tf.Spec.Destination.Namespace
@@ -321,12 +360,28 @@ func (c *Controller) startTraceflow(tf *crdv1alpha1.Traceflow) error {
 
 	// TODO: let controller compute the sender/receiver Node, and the sender
 	// /receiver Node can just return an error, if fails to find the Pod.
-	podInterfaces := c.interfaceStore.GetContainerInterfacesByPod(pod, ns)
+	var podInterfaces []*interfacestore.InterfaceConfig
+	if pod != "" {
+		podInterfaces = c.interfaceStore.GetContainerInterfacesByPod(pod, ns)
+	}
 	isSender := len(podInterfaces) > 0 && !receiverOnly
+	// The packet is sent from the host network of this Node, if the source is
+	// this Node or a hostNetwork Pod on this Node.
+	isHostSender := false
+	if !liveTraffic && !isSender {
+		isHostSender = c.isHostSender(tf)
+		isSender = isHostSender
+	}
 
 	var packet, matchPacket *binding.Packet
 	var ofPort uint32
-	if len(podInterfaces) > 0 {
+	if isHostSender {
+		packet, ofPort, err = c.prepareHostPacket(tf)
+		if err != nil {
+			return err
+		}
+		klog.V(2).Infof("Traceflow packet %v", *packet)
+	} else if len(podInterfaces) > 0 {
 		packet, err = c.preparePacket(tf, podInterfaces[0], receiverOnly)
 		if err != nil {
 			return err
@@ -346,7 +401,11 @@ func (c *Controller) startTraceflow(tf *crdv1alpha1.Traceflow) error {
 	tfState := traceflowState{
 		name: tf.Name, tag: tf.Status.DataplaneTag,
 		liveTraffic: liveTraffic, droppedOnly: tf.Spec.DroppedOnly && liveTraffic,
-		receiverOnly: receiverOnly, isSender: isSender}
+		receiverOnly: receiverOnly, isSender: isSender,
+		bidirectional: tf.Spec.Bidirectional && !liveTraffic}
+	if isSender && packet.SourceIP != nil {
+		tfState.srcIP = packet.SourceIP.String()
+	}
 	c.runningTraceflows[tfState.tag] = &tfState
 	c.runningTraceflowsMutex.Unlock()
 
@@ -363,7 +422,7 @@ func (c *Controller) startTraceflow(tf *crdv1alpha1.Traceflow) error {
 
 	// Skip packet injection if the source Pod is not found on the local Node.
 	if !liveTraffic && isSender {
-		if packet.DestinationMAC == nil {
+		if packet.DestinationMAC == nil || isHostSender {
 			// If the destination is Service/IP or the packet will
 			// be sent to remote Node, wait a small period for other
 			// Nodes.
@@ -374,6 +433,12 @@ func (c *Controller) startTraceflow(tf *crdv1alpha1.Traceflow) error {
 			time.Sleep(time.Duration(injectLocalPacketDelay) * time.Millisecond)
 		}
 		klog.V(2).Infof("Injecting packet for Traceflow %s", tf.Name)
+		// Record the injection time before sending the packet, as the
+		// packet-in messages may be received before SendTraceflowPacket
+		// returns.
+		c.runningTraceflowsMutex.Lock()
+		tfState.injectionTime = time.Now()
+		c.runningTraceflowsMutex.Unlock()
 		err = c.ofClient.SendTraceflowPacket(tfState.tag, packet, ofPort, -1)
 	}
 	return err
@@ -402,7 +467,7 @@ func (c *Controller) preparePacket(tf *crdv1alpha1.Traceflow, intf *interfacesto
 	isICMP := false
 	packet := new(binding.Packet)
 	packet.IsIPv6 = tf.Spec.Packet.IPv6Header != nil
-	if !liveTraffic {
+	if !liveTraffic && intf != nil {
 		if packet.IsIPv6 {
 			packet.SourceIP = intf.GetIPv6Addr()
 			if packet.SourceIP == nil {
@@ -569,6 +634,74 @@ func (c *Controller) preparePacket(tf *crdv1alpha1.Traceflow, intf *interfacesto
 	return packet, nil
 }
 
+// isHostSender returns whether the Traceflow packet is sent from the host network
+// of this Node, which is the case when the source is this Node, or a hostNetwork
+// Pod running on this Node. The Antrea Controller records this Node in the
+// Traceflow status.
+func (c *Controller) isHostSender(tf *crdv1alpha1.Traceflow) bool {
+	return tf.Status.HostSenderNode != "" && tf.Status.HostSenderNode == c.nodeConfig.Name
+}
+
+// prepareHostPacket prepares the Traceflow packet sent from the host network of
+// this Node, or from an external source IP through this Node, and returns it
+// with the OVS port to inject it from. The packet is injected from the gateway
+// port, as if it were forwarded to OVS by the host. A packet from an external
+// source IP is injected from the uplink port instead, if the uplink is connected
+// to the OVS bridge.
+func (c *Controller) prepareHostPacket(tf *crdv1alpha1.Traceflow) (*binding.Packet, uint32, error) {
+	packet, err := c.preparePacket(tf, nil, false)
+	if err != nil {
+		return nil, 0, err
+	}
+	gatewayConfig := c.nodeConfig.GatewayConfig
+	inPort := gatewayConfig.OFPort
+	packet.SourceMAC = gatewayConfig.MAC
+	if tf.Spec.Source.IP != "" {
+		packet.SourceIP = net.ParseIP(tf.Spec.Source.IP)
+		if packet.SourceIP == nil {
+			return nil, 0, errors.New("invalid source IP address")
+		}
+		if isIPv6 := packet.SourceIP.To4() == nil; isIPv6 != packet.IsIPv6 {
+			return nil, 0, errors.New("source IP does not match the IP header family")
+		}
+		if uplinkConfig := c.nodeConfig.UplinkNetConfig; uplinkConfig != nil && uplinkConfig.OFPort != 0 {
+			inPort = uplinkConfig.OFPort
+			// The MAC address of the external peer is not known.
+			packet.SourceMAC = openflow.GlobalVirtualMAC
+			if packet.DestinationMAC == nil {
+				packet.DestinationMAC = uplinkConfig.MAC
+			}
+			return packet, inPort, nil
+		}
+	} else if packet.IsIPv6 {
+		packet.SourceIP = gatewayConfig.IPv6
+		if packet.SourceIP == nil {
+			return nil, 0, errors.New("gateway does not have an IPv6 address")
+		}
+	} else {
+		packet.SourceIP = gatewayConfig.IPv4
+		if packet.SourceIP == nil {
+			return nil, 0, errors.New("gateway does not have an IPv4 address")
+		}
+	}
+	// The host sends the packets to local Pods with the Pod MAC, and the other
+	// packets (e.g. to remote Pods and Services) with the virtual MAC.
+	if packet.DestinationMAC == nil {
+		packet.DestinationMAC = openflow.GlobalVirtualMAC
+	}
+	// A NodePort packet to this Node is DNATed to the virtual NodePort DNAT
+	// IP in the host network before it is forwarded to OVS, when AntreaProxy
+	// proxies all Service traffic.
+	if packet.IPProto == protocol.Type_TCP || packet.IPProto == protocol.Type_UDP {
+		if packet.IsIPv6 && c.nodeConfig.NodeIPv6Addr != nil && packet.DestinationIP.Equal(c.nodeConfig.NodeIPv6Addr.IP) {
+			packet.DestinationIP = config.VirtualNodePortDNATIPv6
+		} else if !packet.IsIPv6 && c.nodeConfig.NodeIPv4Addr != nil && packet.DestinationIP.Equal(c.nodeConfig.NodeIPv4Addr.IP) {
+			packet.DestinationIP = config.VirtualNodePortDNATIPv4
+		}
+	}
+	return packet, inPort, nil
+}
+
 func (c *Controller) errorTraceflowCRD(tf *crdv1alpha1.Traceflow, reason string) (*crdv1alpha1.Traceflow, error) {
 	tf.Status.Phase = crdv1alpha1.Failed
 
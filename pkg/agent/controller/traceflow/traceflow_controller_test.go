@@ -0,0 +1,179 @@
+// Copyright 2022 Antrea Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package traceflow
+
+import (
+	"net"
+	"testing"
+
+	"antrea.io/libOpenflow/protocol"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"antrea.io/antrea/pkg/agent/config"
+	"antrea.io/antrea/pkg/agent/interfacestore"
+	"antrea.io/antrea/pkg/agent/openflow"
+	crdv1alpha1 "antrea.io/antrea/pkg/apis/crd/v1alpha1"
+)
+
+func TestIsTagReservedOnNode(t *testing.T) {
+	c := &Controller{nodeConfig: &config.NodeConfig{Name: "node1"}}
+	for _, tc := range []struct {
+		name     string
+		nodes    []string
+		expected bool
+	}{
+		{name: "all Nodes", nodes: nil, expected: true},
+		{name: "local Node", nodes: []string{"node1", "node2"}, expected: true},
+		{name: "other Nodes", nodes: []string{"node2", "node3"}, expected: false},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			tf := &crdv1alpha1.Traceflow{
+				Status: crdv1alpha1.TraceflowStatus{
+					Phase:             crdv1alpha1.Running,
+					DataplaneTag:      7,
+					DataplaneTagNodes: tc.nodes,
+				},
+			}
+			assert.Equal(t, tc.expected, c.isTagReservedOnNode(tf))
+		})
+	}
+}
+
+func TestIsHostSender(t *testing.T) {
+	c := &Controller{nodeConfig: &config.NodeConfig{Name: "node1"}}
+	for _, tc := range []struct {
+		name           string
+		hostSenderNode string
+		expected       bool
+	}{
+		{name: "no host sender", hostSenderNode: "", expected: false},
+		{name: "local Node", hostSenderNode: "node1", expected: true},
+		{name: "other Node", hostSenderNode: "node2", expected: false},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			tf := &crdv1alpha1.Traceflow{
+				Status: crdv1alpha1.TraceflowStatus{
+					Phase:          crdv1alpha1.Running,
+					HostSenderNode: tc.hostSenderNode,
+				},
+			}
+			assert.Equal(t, tc.expected, c.isHostSender(tf))
+		})
+	}
+}
+
+func TestPrepareHostPacket(t *testing.T) {
+	gatewayMAC, _ := net.ParseMAC("00:00:00:00:00:01")
+	podMAC, _ := net.ParseMAC("00:00:00:00:00:02")
+	uplinkMAC, _ := net.ParseMAC("00:00:00:00:00:03")
+	nodeConfig := &config.NodeConfig{
+		Name:         "node1",
+		NodeIPv4Addr: &net.IPNet{IP: net.ParseIP("192.168.0.1"), Mask: net.CIDRMask(24, 32)},
+		GatewayConfig: &config.GatewayConfig{
+			IPv4:   net.ParseIP("10.10.0.1"),
+			MAC:    gatewayMAC,
+			OFPort: 2,
+		},
+	}
+	interfaceStore := interfacestore.NewInterfaceStore()
+	interfaceStore.AddInterface(interfacestore.NewContainerInterface("pod1-abc", "c1", "pod1", "ns1", podMAC, []net.IP{net.ParseIP("10.10.0.2")}, 0))
+	tcpHeader := crdv1alpha1.TransportHeader{TCP: &crdv1alpha1.TCPHeader{SrcPort: 10000, DstPort: 30080, Flags: 2}}
+
+	for _, tc := range []struct {
+		name           string
+		source         crdv1alpha1.Source
+		destinationIP  string
+		transport      crdv1alpha1.TransportHeader
+		uplinkAttached bool
+		expectedInPort uint32
+		expectedSrcIP  string
+		expectedDstIP  string
+		expectedSrcMAC net.HardwareAddr
+		expectedDstMAC net.HardwareAddr
+	}{
+		{
+			name:           "Node to local Pod",
+			source:         crdv1alpha1.Source{Node: "node1"},
+			destinationIP:  "10.10.0.2",
+			expectedInPort: 2,
+			expectedSrcIP:  "10.10.0.1",
+			expectedDstIP:  "10.10.0.2",
+			expectedSrcMAC: gatewayMAC,
+			expectedDstMAC: podMAC,
+		},
+		{
+			name:           "Node to remote Pod",
+			source:         crdv1alpha1.Source{Node: "node1"},
+			destinationIP:  "10.10.1.2",
+			expectedInPort: 2,
+			expectedSrcIP:  "10.10.0.1",
+			expectedDstIP:  "10.10.1.2",
+			expectedSrcMAC: gatewayMAC,
+			expectedDstMAC: openflow.GlobalVirtualMAC,
+		},
+		{
+			name:           "external IP to NodePort",
+			source:         crdv1alpha1.Source{Node: "node1", IP: "172.16.0.1"},
+			destinationIP:  "192.168.0.1",
+			transport:      tcpHeader,
+			expectedInPort: 2,
+			expectedSrcIP:  "172.16.0.1",
+			expectedDstIP:  config.VirtualNodePortDNATIPv4.String(),
+			expectedSrcMAC: gatewayMAC,
+			expectedDstMAC: openflow.GlobalVirtualMAC,
+		},
+		{
+			name:           "external IP through uplink",
+			source:         crdv1alpha1.Source{Node: "node1", IP: "172.16.0.1"},
+			destinationIP:  "192.168.0.1",
+			transport:      tcpHeader,
+			uplinkAttached: true,
+			expectedInPort: 3,
+			expectedSrcIP:  "172.16.0.1",
+			expectedDstIP:  "192.168.0.1",
+			expectedSrcMAC: openflow.GlobalVirtualMAC,
+			expectedDstMAC: uplinkMAC,
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			c := &Controller{nodeConfig: nodeConfig, interfaceStore: interfaceStore}
+			if tc.uplinkAttached {
+				nodeConfig := *nodeConfig
+				nodeConfig.UplinkNetConfig = &config.AdapterNetConfig{MAC: uplinkMAC, OFPort: 3}
+				c.nodeConfig = &nodeConfig
+			}
+			tf := &crdv1alpha1.Traceflow{
+				Spec: crdv1alpha1.TraceflowSpec{
+					Source:      tc.source,
+					Destination: crdv1alpha1.Destination{IP: tc.destinationIP},
+					Packet:      crdv1alpha1.Packet{TransportHeader: tc.transport},
+				},
+			}
+			packet, inPort, err := c.prepareHostPacket(tf)
+			require.NoError(t, err)
+			assert.Equal(t, tc.expectedInPort, inPort)
+			assert.Equal(t, tc.expectedSrcIP, packet.SourceIP.String())
+			assert.Equal(t, tc.expectedDstIP, packet.DestinationIP.String())
+			assert.Equal(t, tc.expectedSrcMAC, packet.SourceMAC)
+			assert.Equal(t, tc.expectedDstMAC, packet.DestinationMAC)
+			if tc.transport.TCP != nil {
+				assert.Equal(t, uint8(protocol.Type_TCP), packet.IPProto)
+			} else {
+				assert.Equal(t, uint8(protocol.Type_ICMP), packet.IPProto)
+			}
+		})
+	}
+}
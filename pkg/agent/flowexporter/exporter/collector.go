@@ -0,0 +1,163 @@
+// Copyright 2022 Antrea Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package exporter
+
+import (
+	"hash/fnv"
+	"net"
+	"sort"
+	"time"
+
+	"k8s.io/apimachinery/pkg/util/sets"
+	"k8s.io/klog/v2"
+
+	"antrea.io/antrea/pkg/agent/flowexporter"
+	"antrea.io/antrea/pkg/ipfix"
+)
+
+// collectorResolveInterval is the interval at which the flow collector address
+// is resolved again, to detect Flow Aggregator replicas being added or removed.
+const collectorResolveInterval = time.Minute
+
+// lookupIP is a variable so that it can be overridden in tests.
+var lookupIP = net.LookupIP
+
+// flowCollector is the exporting process to one of the addresses the flow
+// collector address resolves to. When the Flow Aggregator is deployed with
+// multiple replicas behind a headless Service, each address is a replica.
+type flowCollector struct {
+	address      string
+	process      ipfix.IPFIXExportingProcess
+	templateIDv4 uint16
+	templateIDv6 uint16
+	// lastConnectTime is the last time a connection to the collector was
+	// attempted.
+	lastConnectTime time.Time
+}
+
+func (c *flowCollector) isConnected() bool {
+	return c.process != nil
+}
+
+func (c *flowCollector) close() {
+	if c.process != nil {
+		c.process.CloseConnToCollector()
+		c.process = nil
+	}
+}
+
+// resolveCollectorAddresses returns the sorted addresses the flow collector
+// address resolves to. If the host is not a DNS name or cannot be resolved, the
+// address is returned as is and resolved by the exporting process.
+func resolveCollectorAddresses(address string) []string {
+	host, port, err := net.SplitHostPort(address)
+	if err != nil || net.ParseIP(host) != nil {
+		return []string{address}
+	}
+	ips, err := lookupIP(host)
+	if err != nil || len(ips) == 0 {
+		klog.V(2).InfoS("Cannot resolve flow collector address", "address", address, "err", err)
+		return []string{address}
+	}
+	addresses := sets.NewString()
+	for _, ip := range ips {
+		addresses.Insert(net.JoinHostPort(ip.String(), port))
+	}
+	return addresses.List()
+}
+
+// updateCollectors resolves the flow collector address periodically, and
+// updates the collectors accordingly. The exporting processes of the collectors
+// which are not returned anymore are closed.
+func (exp *FlowExporter) updateCollectors(now time.Time) {
+	if len(exp.collectors) > 0 && now.Sub(exp.collectorsResolvedTime) < collectorResolveInterval {
+		return
+	}
+	exp.collectorsResolvedTime = now
+	addresses := resolveCollectorAddresses(exp.exporterInput.CollectorAddress)
+	existingCollectors := make(map[string]*flowCollector, len(exp.collectors))
+	for _, c := range exp.collectors {
+		existingCollectors[c.address] = c
+	}
+	collectors := make([]*flowCollector, 0, len(addresses))
+	for _, address := range addresses {
+		if c, ok := existingCollectors[address]; ok {
+			collectors = append(collectors, c)
+			delete(existingCollectors, address)
+			continue
+		}
+		klog.InfoS("Adding flow collector", "address", address)
+		collectors = append(collectors, &flowCollector{address: address})
+	}
+	for address, c := range existingCollectors {
+		klog.InfoS("Removing flow collector", "address", address)
+		c.close()
+	}
+	exp.collectors = collectors
+}
+
+func (exp *FlowExporter) closeCollectors() {
+	for _, c := range exp.collectors {
+		c.close()
+	}
+}
+
+func (exp *FlowExporter) numConnectedCollectors() int {
+	count := 0
+	for _, c := range exp.collectors {
+		if c.isConnected() {
+			count++
+		}
+	}
+	return count
+}
+
+// collectorScore returns the rendezvous hashing score of a collector for a
+// connection. The score only depends on the collector address and on the flow
+// key, which is the same for the records exported by the source and destination
+// Nodes of the connection.
+func collectorScore(address string, flowKey *flowexporter.Tuple) uint64 {
+	h := fnv.New64a()
+	h.Write([]byte(address))
+	h.Write(flowKey.SourceAddress.To16())
+	h.Write(flowKey.DestinationAddress.To16())
+	h.Write([]byte{
+		flowKey.Protocol,
+		byte(flowKey.SourcePort >> 8), byte(flowKey.SourcePort),
+		byte(flowKey.DestinationPort >> 8), byte(flowKey.DestinationPort),
+	})
+	return h.Sum64()
+}
+
+// collectorsForConn returns the connected collectors in the order in which they
+// should be used to export the records of a connection. All the Nodes select the
+// same collector for a given connection, so that its records can be correlated
+// by a single Flow Aggregator replica, and they fail over to the same collector
+// if it is unavailable.
+func (exp *FlowExporter) collectorsForConn(conn *flowexporter.Connection) []*flowCollector {
+	collectors := make([]*flowCollector, 0, len(exp.collectors))
+	scores := make(map[*flowCollector]uint64, len(exp.collectors))
+	for _, c := range exp.collectors {
+		if !c.isConnected() {
+			continue
+		}
+		collectors = append(collectors, c)
+		scores[c] = collectorScore(c.address, &conn.FlowKey)
+	}
+	sort.Slice(collectors, func(i, j int) bool {
+		return scores[collectors[i]] > scores[collectors[j]]
+	})
+	return collectors
+}
@@ -0,0 +1,190 @@
+// Copyright 2022 Antrea Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package exporter
+
+import (
+	"fmt"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	ipfixentities "github.com/vmware/go-ipfix/pkg/entities"
+	ipfixentitiestesting "github.com/vmware/go-ipfix/pkg/entities/testing"
+	"github.com/vmware/go-ipfix/pkg/exporter"
+	"go.uber.org/mock/gomock"
+
+	"antrea.io/antrea/pkg/agent/flowexporter"
+	ipfixtest "antrea.io/antrea/pkg/ipfix/testing"
+)
+
+func TestResolveCollectorAddresses(t *testing.T) {
+	lookupIPSaved := lookupIP
+	defer func() {
+		lookupIP = lookupIPSaved
+	}()
+	lookupIP = func(host string) ([]net.IP, error) {
+		switch host {
+		case "flow-aggregator.flow-aggregator.svc":
+			return []net.IP{net.ParseIP("10.10.1.5"), net.ParseIP("10.10.0.5"), net.ParseIP("10.10.1.5")}, nil
+		case "flow-aggregator-v6.flow-aggregator.svc":
+			return []net.IP{net.ParseIP("fd00:10:10::5")}, nil
+		}
+		return nil, fmt.Errorf("no such host")
+	}
+
+	for _, tc := range []struct {
+		address           string
+		expectedAddresses []string
+	}{
+		{"flow-aggregator.flow-aggregator.svc:4739", []string{"10.10.0.5:4739", "10.10.1.5:4739"}},
+		{"flow-aggregator-v6.flow-aggregator.svc:4739", []string{"[fd00:10:10::5]:4739"}},
+		{"10.96.0.10:4739", []string{"10.96.0.10:4739"}},
+		{"unknown.flow-aggregator.svc:4739", []string{"unknown.flow-aggregator.svc:4739"}},
+	} {
+		assert.Equal(t, tc.expectedAddresses, resolveCollectorAddresses(tc.address))
+	}
+}
+
+func TestFlowExporter_updateCollectors(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	lookupIPSaved := lookupIP
+	defer func() {
+		lookupIP = lookupIPSaved
+	}()
+	ips := []net.IP{net.ParseIP("10.10.0.5"), net.ParseIP("10.10.1.5")}
+	lookupIP = func(host string) ([]net.IP, error) {
+		return ips, nil
+	}
+
+	mockIPFIXExpProc := ipfixtest.NewMockIPFIXExportingProcess(ctrl)
+	flowExp := &FlowExporter{
+		exporterInput: exporter.ExporterInput{
+			CollectorAddress: "flow-aggregator.flow-aggregator.svc:4739",
+		},
+	}
+	now := time.Now()
+	flowExp.updateCollectors(now)
+	require.Len(t, flowExp.collectors, 2)
+	flowExp.collectors[0].process = mockIPFIXExpProc
+
+	// The address is not resolved again before collectorResolveInterval.
+	ips = []net.IP{net.ParseIP("10.10.1.5"), net.ParseIP("10.10.2.5")}
+	flowExp.updateCollectors(now.Add(collectorResolveInterval / 2))
+	assert.Equal(t, "10.10.0.5:4739", flowExp.collectors[0].address)
+
+	// The exporting process of a removed collector is closed.
+	mockIPFIXExpProc.EXPECT().CloseConnToCollector()
+	flowExp.updateCollectors(now.Add(collectorResolveInterval))
+	require.Len(t, flowExp.collectors, 2)
+	assert.Equal(t, "10.10.1.5:4739", flowExp.collectors[0].address)
+	assert.Equal(t, "10.10.2.5:4739", flowExp.collectors[1].address)
+	assert.Zero(t, flowExp.numConnectedCollectors())
+}
+
+func TestFlowExporter_collectorsForConn(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	newCollectors := func(addresses ...string) []*flowCollector {
+		collectors := make([]*flowCollector, len(addresses))
+		for i, address := range addresses {
+			collectors[i] = &flowCollector{address: address, process: ipfixtest.NewMockIPFIXExportingProcess(ctrl)}
+		}
+		return collectors
+	}
+	addresses := func(collectors []*flowCollector) []string {
+		result := make([]string, len(collectors))
+		for i, c := range collectors {
+			result[i] = c.address
+		}
+		return result
+	}
+
+	flowExp := &FlowExporter{collectors: newCollectors("10.10.0.5:4739", "10.10.1.5:4739", "10.10.2.5:4739")}
+	// The collectors are selected independently of the order in which
+	// they are stored, so that all the Nodes select the same collector.
+	otherFlowExp := &FlowExporter{collectors: newCollectors("10.10.2.5:4739", "10.10.0.5:4739", "10.10.1.5:4739")}
+	selected := make(map[string]int)
+	for i := 0; i < 300; i++ {
+		conn := &flowexporter.Connection{
+			FlowKey: flowexporter.Tuple{
+				SourceAddress:      net.IP{10, 10, 0, byte(i)},
+				DestinationAddress: net.ParseIP("10.10.1.2"),
+				Protocol:           6,
+				SourcePort:         uint16(30000 + i),
+				DestinationPort:    80,
+			},
+		}
+		collectors := addresses(flowExp.collectorsForConn(conn))
+		require.Len(t, collectors, 3)
+		assert.Equal(t, collectors, addresses(otherFlowExp.collectorsForConn(conn)))
+		selected[collectors[0]]++
+	}
+	// The connections are spread across all the collectors.
+	assert.Len(t, selected, 3)
+
+	// Collectors which are not connected are not selected.
+	flowExp.collectors[1].process = nil
+	conn := getConnection(false, true, 302, 6, "ESTABLISHED")
+	assert.NotContains(t, addresses(flowExp.collectorsForConn(conn)), "10.10.1.5:4739")
+}
+
+func TestFlowExporter_exportConnFailover(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockDataSet := ipfixentitiestesting.NewMockSet(ctrl)
+	elemList := getElemList(IANAInfoElementsIPv4, AntreaInfoElementsIPv4)
+	flowExp := &FlowExporter{
+		elementsListv4: elemList,
+		v4Enabled:      true,
+		ipfixSet:       mockDataSet,
+	}
+	mockIPFIXExpProc1 := ipfixtest.NewMockIPFIXExportingProcess(ctrl)
+	mockIPFIXExpProc2 := ipfixtest.NewMockIPFIXExportingProcess(ctrl)
+	collector1 := newTestCollector(mockIPFIXExpProc1)
+	collector2 := newTestCollector(mockIPFIXExpProc2)
+	collector2.address = "10.10.1.1:4739"
+	flowExp.collectors = []*flowCollector{collector1, collector2}
+	conn := getConnection(false, true, 302, 6, "ESTABLISHED")
+	collectors := flowExp.collectorsForConn(conn)
+	require.Len(t, collectors, 2)
+	first, second := collectors[0].process.(*ipfixtest.MockIPFIXExportingProcess), collectors[1].process.(*ipfixtest.MockIPFIXExportingProcess)
+
+	// The record is sent to the next collector if sending it to the selected
+	// collector fails, and the connection to the failed collector is reset.
+	mockDataSet.EXPECT().ResetSet().Times(2)
+	mockDataSet.EXPECT().PrepareSet(ipfixentities.Data, testTemplateIDv4).Return(nil).Times(2)
+	mockDataSet.EXPECT().AddRecord(ElementListMatcher(elemList), testTemplateIDv4).Return(nil).Times(2)
+	first.EXPECT().SendSet(mockDataSet).Return(0, fmt.Errorf("connection reset"))
+	first.EXPECT().CloseConnToCollector()
+	second.EXPECT().SendSet(mockDataSet).Return(0, nil)
+	require.NoError(t, flowExp.exportConn(conn))
+	assert.Equal(t, uint64(1), flowExp.numDataSetsSent)
+	assert.Equal(t, 1, flowExp.numConnectedCollectors())
+
+	// An error is returned when no collector is available.
+	mockDataSet.EXPECT().ResetSet()
+	mockDataSet.EXPECT().PrepareSet(ipfixentities.Data, testTemplateIDv4).Return(nil)
+	mockDataSet.EXPECT().AddRecord(ElementListMatcher(elemList), testTemplateIDv4).Return(nil)
+	second.EXPECT().SendSet(mockDataSet).Return(0, fmt.Errorf("connection reset"))
+	second.EXPECT().CloseConnToCollector()
+	assert.Error(t, flowExp.exportConn(conn))
+	assert.Zero(t, flowExp.numConnectedCollectors())
+}
@@ -23,6 +23,7 @@ import (
 	ipfixentities "github.com/vmware/go-ipfix/pkg/entities"
 	"github.com/vmware/go-ipfix/pkg/exporter"
 	ipfixregistry "github.com/vmware/go-ipfix/pkg/registry"
+	utilerrors "k8s.io/apimachinery/pkg/util/errors"
 	"k8s.io/client-go/kubernetes"
 	"k8s.io/klog/v2"
 
@@ -30,7 +31,9 @@ import (
 	"antrea.io/antrea/pkg/agent/controller/noderoute"
 	"antrea.io/antrea/pkg/agent/flowexporter"
 	"antrea.io/antrea/pkg/agent/flowexporter/connections"
+	"antrea.io/antrea/pkg/agent/flowexporter/l7"
 	"antrea.io/antrea/pkg/agent/flowexporter/priorityqueue"
+	"antrea.io/antrea/pkg/agent/flowexporter/spool"
 	"antrea.io/antrea/pkg/agent/interfacestore"
 	"antrea.io/antrea/pkg/agent/metrics"
 	"antrea.io/antrea/pkg/agent/openflow"
@@ -53,6 +56,13 @@ import (
 // e.g. min(50 + 0.1 * connectionStore.size(), 200)
 const maxConnsToExport = 64
 
+// nodeRouteController is implemented by noderoute.Controller. It is used to
+// find the type of flows.
+type nodeRouteController interface {
+	IPInPodSubnets(ip net.IP) bool
+	IsNodeIP(ip net.IP) bool
+}
+
 var (
 	IANAInfoElementsCommon = []string{
 		"flowStartSeconds",
@@ -97,31 +107,49 @@ var (
 		"tcpState",
 		"flowType",
 	}
-	AntreaInfoElementsIPv4 = append(antreaInfoElementsCommon, []string{"destinationClusterIPv4"}...)
-	AntreaInfoElementsIPv6 = append(antreaInfoElementsCommon, []string{"destinationClusterIPv6"}...)
+	// antreaL7InfoElements are always exported, so that the records of all
+	// the Agents can be aggregated. They are only set for the connections of
+	// the Pods selected for L7 flow export.
+	antreaL7InfoElements = []string{
+		"appProtocolName",
+		"httpVals",
+		"dnsQueryName",
+		"dnsResponseCode",
+	}
+	AntreaInfoElementsIPv4 = append(antreaInfoElementsCommon, append([]string{"destinationClusterIPv4"}, antreaL7InfoElements...)...)
+	AntreaInfoElementsIPv6 = append(antreaInfoElementsCommon, append([]string{"destinationClusterIPv6"}, antreaL7InfoElements...)...)
 )
 
 type FlowExporter struct {
 	conntrackConnStore     *connections.ConntrackConnectionStore
 	denyConnStore          *connections.DenyConnectionStore
-	process                ipfix.IPFIXExportingProcess
+	collectors             []*flowCollector
+	collectorsResolvedTime time.Time
 	elementsListv4         []ipfixentities.InfoElementWithValue
 	elementsListv6         []ipfixentities.InfoElementWithValue
 	ipfixSet               ipfixentities.Set
 	numDataSetsSent        uint64 // used for unit tests.
-	templateIDv4           uint16
-	templateIDv6           uint16
 	registry               ipfix.IPFIXRegistry
 	v4Enabled              bool
 	v6Enabled              bool
 	exporterInput          exporter.ExporterInput
 	k8sClient              kubernetes.Interface
-	nodeRouteController    *noderoute.Controller
+	nodeRouteController    nodeRouteController
 	isNetworkPolicyOnly    bool
+	ifaceStore             interfacestore.InterfaceStore
+	// localNodeIPs are the IPs of the Node and of the Antrea gateway, which
+	// are used by the Node itself and by its hostNetwork Pods.
+	localNodeIPs           []net.IP
 	nodeName               string
 	conntrackPriorityQueue *priorityqueue.ExpirePriorityQueue
 	denyPriorityQueue      *priorityqueue.ExpirePriorityQueue
 	expiredConns           []flowexporter.Connection
+	// spool stores the flow records which cannot be sent while no flow
+	// collector is available. It is nil if the spool is disabled.
+	spool *spool.Spool
+	// l7EventStore provides the L7 information of the connections. It is
+	// nil if L7 flow export is disabled.
+	l7EventStore *l7.EventStore
 }
 
 func genObservationID(nodeName string) uint32 {
@@ -137,10 +165,10 @@ func prepareExporterInputArgs(collectorAddr, collectorProto, nodeName string) ex
 
 	expInput.CollectorAddress = collectorAddr
 	if collectorProto == "tls" {
-		expInput.IsEncrypted = true
+		// The credentials are set by prepareExporterInput.
+		expInput.TLSClientConfig = &exporter.ExporterTLSClientConfig{}
 		expInput.CollectorProtocol = "tcp"
 	} else {
-		expInput.IsEncrypted = false
 		expInput.CollectorProtocol = collectorProto
 	}
 
@@ -149,7 +177,8 @@ func prepareExporterInputArgs(collectorAddr, collectorProto, nodeName string) ex
 
 func NewFlowExporter(ifaceStore interfacestore.InterfaceStore, proxier proxy.Proxier, k8sClient kubernetes.Interface, nodeRouteController *noderoute.Controller,
 	trafficEncapMode config.TrafficEncapModeType, nodeConfig *config.NodeConfig, v4Enabled, v6Enabled bool, serviceCIDRNet, serviceCIDRNetv6 *net.IPNet,
-	ovsDatapathType ovsconfig.OVSDatapathType, proxyEnabled bool, npQuerier querier.AgentNetworkPolicyInfoQuerier, o *flowexporter.FlowExporterOptions) (*FlowExporter, error) {
+	ovsDatapathType ovsconfig.OVSDatapathType, proxyEnabled bool, npQuerier querier.AgentNetworkPolicyInfoQuerier, l7EventStore *l7.EventStore,
+	o *flowexporter.FlowExporterOptions) (*FlowExporter, error) {
 	// Initialize IPFIX registry
 	registry := ipfix.NewIPFIXRegistry()
 	registry.LoadRegistry()
@@ -165,7 +194,31 @@ func NewFlowExporter(ifaceStore interfacestore.InterfaceStore, proxier proxy.Pro
 	denyConnStore := connections.NewDenyConnectionStore(ifaceStore, proxier, o)
 	conntrackConnStore := connections.NewConntrackConnectionStore(connTrackDumper, v4Enabled, v6Enabled, npQuerier, ifaceStore, proxier, o)
 
-	return &FlowExporter{
+	var recordSpool *spool.Spool
+	if o.SpoolSize > 0 {
+		// The Flow Exporter can still run without the spool, flow records
+		// are then dropped while no flow collector is available.
+		recordSpool, err = spool.New(o.SpoolDir, o.SpoolSize)
+		if err != nil {
+			klog.ErrorS(err, "Failed to open flow record spool, flow records will not be buffered while the flow collector is unavailable")
+		}
+	}
+
+	var localNodeIPs []net.IP
+	for _, nodeIP := range []*net.IPNet{nodeConfig.NodeIPv4Addr, nodeConfig.NodeIPv6Addr, nodeConfig.NodeTransportIPv4Addr, nodeConfig.NodeTransportIPv6Addr} {
+		if nodeIP != nil {
+			localNodeIPs = append(localNodeIPs, nodeIP.IP)
+		}
+	}
+	if nodeConfig.GatewayConfig != nil {
+		for _, gatewayIP := range []net.IP{nodeConfig.GatewayConfig.IPv4, nodeConfig.GatewayConfig.IPv6} {
+			if gatewayIP != nil {
+				localNodeIPs = append(localNodeIPs, gatewayIP)
+			}
+		}
+	}
+
+	flowExp := &FlowExporter{
 		conntrackConnStore:     conntrackConnStore,
 		denyConnStore:          denyConnStore,
 		registry:               registry,
@@ -174,13 +227,22 @@ func NewFlowExporter(ifaceStore interfacestore.InterfaceStore, proxier proxy.Pro
 		exporterInput:          expInput,
 		ipfixSet:               ipfixentities.NewSet(false),
 		k8sClient:              k8sClient,
-		nodeRouteController:    nodeRouteController,
 		isNetworkPolicyOnly:    trafficEncapMode.IsNetworkPolicyOnly(),
+		ifaceStore:             ifaceStore,
+		localNodeIPs:           localNodeIPs,
 		nodeName:               nodeName,
 		conntrackPriorityQueue: conntrackConnStore.GetPriorityQueue(),
 		denyPriorityQueue:      denyConnStore.GetPriorityQueue(),
 		expiredConns:           make([]flowexporter.Connection, 0, maxConnsToExport*2),
-	}, nil
+		spool:                  recordSpool,
+		l7EventStore:           l7EventStore,
+	}
+	// nodeRouteController is nil on ExternalNodes. It must not be stored as a
+	// non-nil interface holding a nil pointer.
+	if nodeRouteController != nil {
+		flowExp.nodeRouteController = nodeRouteController
+	}
+	return flowExp, nil
 }
 
 func (exp *FlowExporter) GetDenyConnStore() *connections.DenyConnectionStore {
@@ -199,27 +261,24 @@ func (exp *FlowExporter) Run(stopCh <-chan struct{}) {
 	for {
 		select {
 		case <-stopCh:
-			if exp.process != nil {
-				exp.process.CloseConnToCollector()
+			exp.closeCollectors()
+			if exp.spool != nil {
+				exp.spool.Close()
 			}
 			expireTimer.Stop()
 			return
 		case <-expireTimer.C:
-			if exp.process == nil {
-				err := exp.initFlowExporter()
-				if err != nil {
-					klog.ErrorS(err, "Error when initializing flow exporter")
-					// There could be other errors while initializing flow exporter
-					// other than connecting to IPFIX collector, therefore closing
-					// the connection and resetting the process.
-					if exp.process != nil {
-						exp.process.CloseConnToCollector()
-						exp.process = nil
-					}
-					// Initializing flow exporter fails, will retry in next cycle.
-					expireTimer.Reset(defaultTimeout)
-					continue
+			if err := exp.initFlowExporter(); err != nil {
+				klog.ErrorS(err, "Error when initializing flow exporter")
+				// Initializing flow exporter fails, will retry in next cycle.
+				// Meanwhile, the expired connections are stored in the spool
+				// if it is enabled, so that their records are not lost if the
+				// connections are deleted.
+				if exp.spool != nil {
+					exp.spoolFlowRecords()
 				}
+				expireTimer.Reset(defaultTimeout)
+				continue
 			}
 			// Pop out the expired connections from the conntrack priority queue
 			// and the deny priority queue, and send the data records.
@@ -227,10 +286,8 @@ func (exp *FlowExporter) Run(stopCh <-chan struct{}) {
 			if err != nil {
 				klog.ErrorS(err, "Error when sending expired flow records")
 				// If there is an error when sending flow records because of intermittent
-				// connectivity, we reset the connection to IPFIX collector and retry
-				// in the next export cycle to reinitialize the connection and send flow records.
-				exp.process.CloseConnToCollector()
-				exp.process = nil
+				// connectivity, the connections to the IPFIX collectors which failed have
+				// been reset, and they are reinitialized in the next export cycle.
 				expireTimer.Reset(defaultTimeout)
 				continue
 			}
@@ -244,11 +301,23 @@ func (exp *FlowExporter) sendFlowRecords() (time.Duration, error) {
 	var expireTime1, expireTime2 time.Duration
 	exp.expiredConns, expireTime1 = exp.conntrackConnStore.GetExpiredConns(exp.expiredConns, currTime, maxConnsToExport)
 	exp.expiredConns, expireTime2 = exp.denyConnStore.GetExpiredConns(exp.expiredConns, currTime, maxConnsToExport)
+	exp.addL7Info(exp.expiredConns)
 	// Select the shorter time out among two connection stores to do the next round of export.
 	nextExpireTime := getMinTime(expireTime1, expireTime2)
+	// The records stored in the spool are older than the expired connections,
+	// so they are sent first.
+	if err := exp.sendSpooledFlowRecords(); err != nil {
+		exp.appendToSpool(exp.expiredConns)
+		exp.expiredConns = exp.expiredConns[:0]
+		return nextExpireTime, err
+	}
 	for i := range exp.expiredConns {
 		if err := exp.exportConn(&exp.expiredConns[i]); err != nil {
 			klog.ErrorS(err, "Error when sending expired flow record")
+			if exp.spool != nil {
+				exp.appendToSpool(exp.expiredConns[i:])
+				exp.expiredConns = exp.expiredConns[:0]
+			}
 			return nextExpireTime, err
 		}
 	}
@@ -257,16 +326,98 @@ func (exp *FlowExporter) sendFlowRecords() (time.Duration, error) {
 	return nextExpireTime, nil
 }
 
+// spoolFlowRecords stores the records of the expired connections in the spool
+// while no flow collector is available.
+func (exp *FlowExporter) spoolFlowRecords() {
+	currTime := time.Now()
+	exp.expiredConns, _ = exp.conntrackConnStore.GetExpiredConns(exp.expiredConns, currTime, maxConnsToExport)
+	exp.expiredConns, _ = exp.denyConnStore.GetExpiredConns(exp.expiredConns, currTime, maxConnsToExport)
+	exp.addL7Info(exp.expiredConns)
+	exp.appendToSpool(exp.expiredConns)
+	exp.expiredConns = exp.expiredConns[:0]
+}
+
+// addL7Info adds the L7 information to the records of the expired connections.
+func (exp *FlowExporter) addL7Info(conns []flowexporter.Connection) {
+	if exp.l7EventStore == nil {
+		return
+	}
+	for i := range conns {
+		exp.l7EventStore.FillConnection(&conns[i])
+	}
+}
+
+func (exp *FlowExporter) appendToSpool(conns []flowexporter.Connection) {
+	for i := range conns {
+		if err := exp.spool.Append(&conns[i]); err != nil {
+			klog.ErrorS(err, "Error when storing flow record in spool", "flowKey", conns[i].FlowKey)
+		}
+	}
+}
+
+// sendSpooledFlowRecords sends the records stored in the spool, in the order in
+// which they were stored.
+func (exp *FlowExporter) sendSpooledFlowRecords() error {
+	if exp.spool == nil || exp.spool.Len() == 0 {
+		return nil
+	}
+	count, err := exp.spool.Replay(exp.exportConn)
+	klog.InfoS("Sent flow records from spool", "count", count, "remaining", exp.spool.Len())
+	if err != nil {
+		return fmt.Errorf("error when sending flow records from spool: %v", err)
+	}
+	return nil
+}
+
+// initFlowExporter connects to the flow collectors which are not connected yet.
+// It only returns an error if no collector is connected.
 func (exp *FlowExporter) initFlowExporter() error {
+	now := time.Now()
+	exp.updateCollectors(now)
+	numConnectedCollectors := exp.numConnectedCollectors()
+	if numConnectedCollectors == len(exp.collectors) {
+		return nil
+	}
+	if err := exp.prepareExporterInput(); err != nil {
+		return err
+	}
+	var errs []error
+	for _, c := range exp.collectors {
+		if c.isConnected() {
+			continue
+		}
+		// While other collectors are available, connecting to a collector
+		// which failed is only retried after collectorResolveInterval, as
+		// it may take a long time to fail.
+		if numConnectedCollectors > 0 && now.Sub(c.lastConnectTime) < collectorResolveInterval {
+			continue
+		}
+		c.lastConnectTime = now
+		if err := exp.initCollector(c); err != nil {
+			// There could be other errors while initializing the collector
+			// other than connecting to it, therefore closing the connection
+			// and resetting the process.
+			c.close()
+			klog.ErrorS(err, "Error when connecting to flow collector", "address", c.address)
+			errs = append(errs, err)
+		}
+	}
+	if exp.numConnectedCollectors() == 0 {
+		return fmt.Errorf("cannot connect to any flow collector: %v", utilerrors.NewAggregate(errs))
+	}
+	return nil
+}
+
+func (exp *FlowExporter) prepareExporterInput() error {
 	var err error
-	if exp.exporterInput.IsEncrypted {
+	if tlsConfig := exp.exporterInput.TLSClientConfig; tlsConfig != nil {
 		// if CA certificate, client certificate and key do not exist during initialization,
 		// it will retry to obtain the credentials in next export cycle
-		exp.exporterInput.CACert, err = getCACert(exp.k8sClient)
+		tlsConfig.CAData, err = getCACert(exp.k8sClient)
 		if err != nil {
 			return fmt.Errorf("cannot retrieve CA cert: %v", err)
 		}
-		exp.exporterInput.ClientCert, exp.exporterInput.ClientKey, err = getClientCertKey(exp.k8sClient)
+		tlsConfig.CertData, tlsConfig.KeyData, err = getClientCertKey(exp.k8sClient)
 		if err != nil {
 			return fmt.Errorf("cannot retrieve client cert and key: %v", err)
 		}
@@ -281,43 +432,47 @@ func (exp *FlowExporter) initFlowExporter() error {
 		// For UDP transport, hardcoding tempRefTimeout value as 1800s.
 		exp.exporterInput.TempRefTimeout = 1800
 	}
-	expProcess, err := ipfix.NewIPFIXExportingProcess(exp.exporterInput)
+	return nil
+}
+
+func (exp *FlowExporter) initCollector(c *flowCollector) error {
+	expInput := exp.exporterInput
+	expInput.CollectorAddress = c.address
+	expProcess, err := ipfix.NewIPFIXExportingProcess(expInput)
 	if err != nil {
 		return fmt.Errorf("error when starting exporter: %v", err)
 	}
-	exp.process = expProcess
+	c.process = expProcess
 	if exp.v4Enabled {
-		templateID := exp.process.NewTemplateID()
-		exp.templateIDv4 = templateID
-		sentBytes, err := exp.sendTemplateSet(false)
+		c.templateIDv4 = c.process.NewTemplateID()
+		sentBytes, err := exp.sendTemplateSet(c, false)
 		if err != nil {
 			return err
 		}
-		klog.V(2).Infof("Initialized flow exporter for IPv4 flow records and sent %d bytes size of template record", sentBytes)
+		klog.V(2).InfoS("Initialized flow exporter for IPv4 flow records and sent template record", "address", c.address, "bytes", sentBytes)
 	}
 	if exp.v6Enabled {
-		templateID := exp.process.NewTemplateID()
-		exp.templateIDv6 = templateID
-		sentBytes, err := exp.sendTemplateSet(true)
+		c.templateIDv6 = c.process.NewTemplateID()
+		sentBytes, err := exp.sendTemplateSet(c, true)
 		if err != nil {
 			return err
 		}
-		klog.V(2).Infof("Initialized flow exporter for IPv6 flow records and sent %d bytes size of template record", sentBytes)
+		klog.V(2).InfoS("Initialized flow exporter for IPv6 flow records and sent template record", "address", c.address, "bytes", sentBytes)
 	}
 	metrics.ReconnectionsToFlowCollector.Inc()
 	return nil
 }
 
-func (exp *FlowExporter) sendTemplateSet(isIPv6 bool) (int, error) {
+func (exp *FlowExporter) sendTemplateSet(c *flowCollector, isIPv6 bool) (int, error) {
 	elements := make([]ipfixentities.InfoElementWithValue, 0)
 
 	IANAInfoElements := IANAInfoElementsIPv4
 	AntreaInfoElements := AntreaInfoElementsIPv4
-	templateID := exp.templateIDv4
+	templateID := c.templateIDv4
 	if isIPv6 {
 		IANAInfoElements = IANAInfoElementsIPv6
 		AntreaInfoElements = AntreaInfoElementsIPv6
-		templateID = exp.templateIDv6
+		templateID = c.templateIDv6
 	}
 	for _, ie := range IANAInfoElements {
 		element, err := exp.registry.GetInfoElement(ie, ipfixregistry.IANAEnterpriseID)
@@ -360,7 +515,7 @@ func (exp *FlowExporter) sendTemplateSet(isIPv6 bool) (int, error) {
 	if err != nil {
 		return 0, fmt.Errorf("error in adding record to template set: %v", err)
 	}
-	sentBytes, err := exp.process.SendSet(exp.ipfixSet)
+	sentBytes, err := c.process.SendSet(exp.ipfixSet)
 	if err != nil {
 		return 0, fmt.Errorf("error in IPFIX exporting process when sending template record: %v", err)
 	}
@@ -375,13 +530,13 @@ func (exp *FlowExporter) sendTemplateSet(isIPv6 bool) (int, error) {
 	return sentBytes, nil
 }
 
-func (exp *FlowExporter) addConnToSet(conn *flowexporter.Connection) error {
+func (exp *FlowExporter) addConnToSet(c *flowCollector, conn *flowexporter.Connection) error {
 	exp.ipfixSet.ResetSet()
 
 	eL := exp.elementsListv4
-	templateID := exp.templateIDv4
+	templateID := c.templateIDv4
 	if conn.FlowKey.SourceAddress.To4() == nil {
-		templateID = exp.templateIDv6
+		templateID = c.templateIDv6
 		eL = exp.elementsListv6
 	}
 	if err := exp.ipfixSet.PrepareSet(ipfixentities.Data, templateID); err != nil {
@@ -454,8 +609,9 @@ func (exp *FlowExporter) addConnToSet(conn *flowexporter.Connection) error {
 		case "sourcePodName":
 			ie.SetStringValue(conn.SourcePodName)
 		case "sourceNodeName":
-			// Add nodeName for only local pods whose pod names are resolved.
-			if conn.SourcePodName != "" {
+			// Add nodeName for only local endpoints: Pods whose names are
+			// resolved, the Node and its hostNetwork Pods, and ExternalEntities.
+			if exp.isLocalEndpoint(conn.FlowKey.SourceAddress, conn.SourcePodName) {
 				ie.SetStringValue(exp.nodeName)
 			} else {
 				ie.SetStringValue("")
@@ -465,8 +621,8 @@ func (exp *FlowExporter) addConnToSet(conn *flowexporter.Connection) error {
 		case "destinationPodName":
 			ie.SetStringValue(conn.DestinationPodName)
 		case "destinationNodeName":
-			// Add nodeName for only local pods whose pod names are resolved.
-			if conn.DestinationPodName != "" {
+			// Same as sourceNodeName.
+			if exp.isLocalEndpoint(conn.FlowKey.DestinationAddress, conn.DestinationPodName) {
 				ie.SetStringValue(exp.nodeName)
 			} else {
 				ie.SetStringValue("")
@@ -519,6 +675,14 @@ func (exp *FlowExporter) addConnToSet(conn *flowexporter.Connection) error {
 			ie.SetStringValue(conn.TCPState)
 		case "flowType":
 			ie.SetUnsigned8Value(exp.findFlowType(*conn))
+		case "appProtocolName":
+			ie.SetStringValue(conn.AppProtocolName)
+		case "httpVals":
+			ie.SetStringValue(conn.HttpVals)
+		case "dnsQueryName":
+			ie.SetStringValue(conn.DNSQueryName)
+		case "dnsResponseCode":
+			ie.SetUnsigned8Value(conn.DNSResponseCode)
 		}
 	}
 	err := exp.ipfixSet.AddRecord(eL, templateID)
@@ -528,8 +692,8 @@ func (exp *FlowExporter) addConnToSet(conn *flowexporter.Connection) error {
 	return nil
 }
 
-func (exp *FlowExporter) sendDataSet() (int, error) {
-	sentBytes, err := exp.process.SendSet(exp.ipfixSet)
+func (exp *FlowExporter) sendDataSet(c *flowCollector) (int, error) {
+	sentBytes, err := c.process.SendSet(exp.ipfixSet)
 	if err != nil {
 		return 0, fmt.Errorf("error when sending data set: %v", err)
 	}
@@ -546,34 +710,88 @@ func (exp *FlowExporter) findFlowType(conn flowexporter.Connection) uint8 {
 		return ipfixregistry.FlowTypeIntraNode
 	}
 
+	srcIP, dstIP := conn.FlowKey.SourceAddress, conn.FlowKey.DestinationAddress
+	// Flows between local endpoints, including the Node and its hostNetwork
+	// Pods, are only exported by this Node.
+	if exp.isLocalEndpoint(srcIP, conn.SourcePodName) && exp.isLocalEndpoint(dstIP, conn.DestinationPodName) {
+		return ipfixregistry.FlowTypeIntraNode
+	}
 	if exp.nodeRouteController == nil {
-		klog.Warningf("Can't find flowType without nodeRouteController")
+		// The agent runs on an ExternalNode, whose flows are between its
+		// ExternalEntities and external endpoints.
+		if exp.isLocalEndpoint(srcIP, "") {
+			return ipfixregistry.FlowTypeToExternal
+		}
+		if exp.isLocalEndpoint(dstIP, "") {
+			return ipfixregistry.FlowTypeFromExternal
+		}
+		klog.V(4).InfoS("Neither source nor destination IP is a local ExternalEntity IP", "sourceIP", srcIP, "destinationIP", dstIP)
 		return 0
 	}
-	if exp.nodeRouteController.IPInPodSubnets(conn.FlowKey.SourceAddress) {
-		if conn.Mark&openflow.ServiceCTMark.GetRange().ToNXRange().ToUint32Mask() == openflow.ServiceCTMark.GetValue() || exp.nodeRouteController.IPInPodSubnets(conn.FlowKey.DestinationAddress) {
-			if conn.SourcePodName == "" || conn.DestinationPodName == "" {
-				return ipfixregistry.FlowTypeInterNode
-			}
-			return ipfixregistry.FlowTypeIntraNode
+	dstInPodNetwork := conn.Mark&openflow.ServiceCTMark.GetRange().ToNXRange().ToUint32Mask() == openflow.ServiceCTMark.GetValue() || exp.nodeRouteController.IPInPodSubnets(dstIP)
+	// Traffic from Nodes and hostNetwork Pods to Pods uses the IP of the Node
+	// or of its Antrea gateway, which is in the Pod subnet of the Node.
+	if exp.nodeRouteController.IPInPodSubnets(srcIP) || exp.nodeRouteController.IsNodeIP(srcIP) {
+		if dstInPodNetwork {
+			return ipfixregistry.FlowTypeInterNode
 		}
+		// This includes flows to other Nodes and their hostNetwork Pods, which
+		// are not seen by the Flow Exporter of the destination Node.
 		return ipfixregistry.FlowTypeToExternal
 	}
-	// We do not support External-To-Pod flows for now.
-	klog.Warningf("Source IP: %s doesn't exist in PodCIDRs", conn.FlowKey.SourceAddress.String())
+	// The source is outside of the cluster, e.g. an external client reaching
+	// a NodePort or LoadBalancer Service. The source address is the original
+	// client IP from the conntrack original tuple.
+	if conn.DestinationPodName != "" || dstInPodNetwork {
+		return ipfixregistry.FlowTypeFromExternal
+	}
+	klog.V(4).InfoS("Neither source nor destination IP exists in PodCIDRs", "sourceIP", srcIP, "destinationIP", dstIP)
 	return 0
 }
 
+// isLocalEndpoint returns whether the endpoint with the provided IP runs on this
+// Node: a local Pod, whose name is resolved, the Node itself or one of its
+// hostNetwork Pods, or an ExternalEntity on an ExternalNode.
+func (exp *FlowExporter) isLocalEndpoint(ip net.IP, podName string) bool {
+	if podName != "" {
+		return true
+	}
+	for _, nodeIP := range exp.localNodeIPs {
+		if nodeIP.Equal(ip) {
+			return true
+		}
+	}
+	if exp.ifaceStore == nil {
+		return false
+	}
+	iface, ok := exp.ifaceStore.GetInterfaceByIP(ip.String())
+	return ok && iface.Type == interfacestore.ExternalEntityInterface
+}
+
 func (exp *FlowExporter) exportConn(conn *flowexporter.Connection) error {
+	// The record is sent to the first collector selected for the connection,
+	// and to the next ones if sending it fails.
+	for _, c := range exp.collectorsForConn(conn) {
+		if err := exp.exportConnToCollector(c, conn); err != nil {
+			klog.ErrorS(err, "Error when sending flow record to flow collector", "address", c.address)
+			c.close()
+			continue
+		}
+		exp.numDataSetsSent = exp.numDataSetsSent + 1
+		klog.V(4).InfoS("Record for connection sent successfully", "flowKey", conn.FlowKey, "connection", conn, "address", c.address)
+		return nil
+	}
+	return fmt.Errorf("no flow collector available to send the record")
+}
+
+func (exp *FlowExporter) exportConnToCollector(c *flowCollector, conn *flowexporter.Connection) error {
 	// TODO: more records per data set will be supported when go-ipfix supports size check when adding records
-	if err := exp.addConnToSet(conn); err != nil {
+	if err := exp.addConnToSet(c, conn); err != nil {
 		return err
 	}
-	if _, err := exp.sendDataSet(); err != nil {
+	if _, err := exp.sendDataSet(c); err != nil {
 		return err
 	}
-	exp.numDataSetsSent = exp.numDataSetsSent + 1
-	klog.V(4).InfoS("Record for connection sent successfully", "flowKey", conn.FlowKey, "connection", conn)
 	return nil
 }
 
@@ -15,25 +15,31 @@
 package exporter
 
 import (
+	"fmt"
 	"net"
 	"strings"
 	"testing"
 	"time"
 
-	"github.com/golang/mock/gomock"
 	"github.com/prometheus/client_golang/prometheus/testutil"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 	ipfixentities "github.com/vmware/go-ipfix/pkg/entities"
 	ipfixentitiestesting "github.com/vmware/go-ipfix/pkg/entities/testing"
 	"github.com/vmware/go-ipfix/pkg/exporter"
 	"github.com/vmware/go-ipfix/pkg/registry"
 	ipfixregistry "github.com/vmware/go-ipfix/pkg/registry"
+	"go.uber.org/mock/gomock"
 	"k8s.io/component-base/metrics/legacyregistry"
 
 	"antrea.io/antrea/pkg/agent/flowexporter"
 	"antrea.io/antrea/pkg/agent/flowexporter/connections"
 	connectionstest "antrea.io/antrea/pkg/agent/flowexporter/connections/testing"
+	"antrea.io/antrea/pkg/agent/flowexporter/spool"
+	"antrea.io/antrea/pkg/agent/interfacestore"
 	"antrea.io/antrea/pkg/agent/metrics"
+	"antrea.io/antrea/pkg/agent/openflow"
+	"antrea.io/antrea/pkg/ipfix"
 	ipfixtest "antrea.io/antrea/pkg/ipfix/testing"
 )
 
@@ -45,7 +51,7 @@ const (
 )
 
 func init() {
-	registry.LoadRegistry()
+	ipfix.LoadRegistry()
 }
 
 func TestFlowExporter_sendTemplateSet(t *testing.T) {
@@ -68,23 +74,30 @@ func testSendTemplateSet(t *testing.T, v4Enabled bool, v6Enabled bool) {
 	mockIPFIXExpProc := ipfixtest.NewMockIPFIXExportingProcess(ctrl)
 	mockIPFIXRegistry := ipfixtest.NewMockIPFIXRegistry(ctrl)
 	flowExp := &FlowExporter{
-		process:      mockIPFIXExpProc,
-		templateIDv4: testTemplateIDv4,
-		templateIDv6: testTemplateIDv6,
-		registry:     mockIPFIXRegistry,
-		v4Enabled:    v4Enabled,
-		v6Enabled:    v6Enabled,
+		registry:  mockIPFIXRegistry,
+		v4Enabled: v4Enabled,
+		v6Enabled: v6Enabled,
 	}
+	collector := newTestCollector(mockIPFIXExpProc)
 
 	if v4Enabled {
-		sendTemplateSet(t, ctrl, mockIPFIXExpProc, mockIPFIXRegistry, flowExp, false)
+		sendTemplateSet(t, ctrl, mockIPFIXExpProc, mockIPFIXRegistry, flowExp, collector, false)
 	}
 	if v6Enabled {
-		sendTemplateSet(t, ctrl, mockIPFIXExpProc, mockIPFIXRegistry, flowExp, true)
+		sendTemplateSet(t, ctrl, mockIPFIXExpProc, mockIPFIXRegistry, flowExp, collector, true)
+	}
+}
+
+func newTestCollector(process *ipfixtest.MockIPFIXExportingProcess) *flowCollector {
+	return &flowCollector{
+		address:      "10.10.0.1:4739",
+		process:      process,
+		templateIDv4: testTemplateIDv4,
+		templateIDv6: testTemplateIDv6,
 	}
 }
 
-func sendTemplateSet(t *testing.T, ctrl *gomock.Controller, mockIPFIXExpProc *ipfixtest.MockIPFIXExportingProcess, mockIPFIXRegistry *ipfixtest.MockIPFIXRegistry, flowExp *FlowExporter, isIPv6 bool) {
+func sendTemplateSet(t *testing.T, ctrl *gomock.Controller, mockIPFIXExpProc *ipfixtest.MockIPFIXExportingProcess, mockIPFIXRegistry *ipfixtest.MockIPFIXRegistry, flowExp *FlowExporter, collector *flowCollector, isIPv6 bool) {
 	var mockTempSet *ipfixentitiestesting.MockSet
 	mockTempSet = ipfixentitiestesting.NewMockSet(ctrl)
 	flowExp.ipfixSet = mockTempSet
@@ -120,7 +133,7 @@ func sendTemplateSet(t *testing.T, ctrl *gomock.Controller, mockIPFIXExpProc *ip
 		mockTempSet.EXPECT().PrepareSet(ipfixentities.Template, testTemplateIDv6).Return(nil)
 	}
 	mockIPFIXExpProc.EXPECT().SendSet(mockTempSet).Return(0, nil)
-	_, err := flowExp.sendTemplateSet(isIPv6)
+	_, err := flowExp.sendTemplateSet(collector, isIPv6)
 	assert.NoError(t, err, "Error in sending template set")
 
 	eL := flowExp.elementsListv4
@@ -231,16 +244,14 @@ func testSendDataSet(t *testing.T, v4Enabled bool, v6Enabled bool) {
 		elemListv6 = getElemList(IANAInfoElementsIPv6, AntreaInfoElementsIPv6)
 	}
 	flowExp := &FlowExporter{
-		process:        mockIPFIXExpProc,
 		elementsListv4: elemListv4,
 		elementsListv6: elemListv6,
-		templateIDv4:   testTemplateIDv4,
-		templateIDv6:   testTemplateIDv6,
 		registry:       mockIPFIXRegistry,
 		v4Enabled:      v4Enabled,
 		v6Enabled:      v6Enabled,
 		ipfixSet:       mockDataSet,
 	}
+	collector := newTestCollector(mockIPFIXExpProc)
 
 	sendDataSet := func(elemList []ipfixentities.InfoElementWithValue, templateID uint16, conn flowexporter.Connection) {
 		mockDataSet.EXPECT().ResetSet()
@@ -248,9 +259,9 @@ func testSendDataSet(t *testing.T, v4Enabled bool, v6Enabled bool) {
 		mockDataSet.EXPECT().AddRecord(ElementListMatcher(elemList), templateID).Return(nil)
 		mockIPFIXExpProc.EXPECT().SendSet(mockDataSet).Return(0, nil)
 
-		err := flowExp.addConnToSet(&conn)
+		err := flowExp.addConnToSet(collector, &conn)
 		assert.NoError(t, err, "Error when adding record to data set")
-		_, err = flowExp.sendDataSet()
+		_, err = flowExp.sendDataSet(collector)
 		assert.NoError(t, err, "Error in sending data set")
 	}
 
@@ -292,7 +303,6 @@ func TestFlowExporter_initFlowExporter(t *testing.T) {
 		{conn2.Addr().Network(), conn2.Addr().String(), uint32(0)},
 	} {
 		exp := &FlowExporter{
-			process: nil,
 			exporterInput: exporter.ExporterInput{
 				CollectorProtocol: tc.protocol,
 				CollectorAddress:  tc.address,
@@ -301,8 +311,11 @@ func TestFlowExporter_initFlowExporter(t *testing.T) {
 		err = exp.initFlowExporter()
 		assert.NoError(t, err)
 		assert.Equal(t, tc.expectedTempRefTimeout, exp.exporterInput.TempRefTimeout)
+		assert.Len(t, exp.collectors, 1)
+		assert.Equal(t, tc.address, exp.collectors[0].address)
 		checkTotalReconnectionsMetric(t)
 		metrics.ReconnectionsToFlowCollector.Dec()
+		exp.closeCollectors()
 	}
 }
 
@@ -358,6 +371,10 @@ func getElemList(ianaIE []string, antreaIE []string) []ipfixentities.InfoElement
 			ie.SetStringValue("")
 		case "ingressNetworkPolicyType", "egressNetworkPolicyType", "ingressNetworkPolicyRuleAction", "egressNetworkPolicyRuleAction":
 			ie.SetUnsigned8Value(uint8(0))
+		case "appProtocolName", "httpVals", "dnsQueryName":
+			ie.SetStringValue("")
+		case "dnsResponseCode":
+			ie.SetUnsigned8Value(uint8(0))
 		}
 		elemList[i] = ie
 	}
@@ -441,8 +458,6 @@ func testSendFlowRecords(t *testing.T, v4Enabled bool, v6Enabled bool) {
 	flowExp := &FlowExporter{
 		elementsListv4: elemListv4,
 		elementsListv6: elemListv6,
-		templateIDv4:   testTemplateIDv4,
-		templateIDv6:   testTemplateIDv6,
 		v4Enabled:      v4Enabled,
 		v6Enabled:      v6Enabled}
 
@@ -460,7 +475,7 @@ func runSendFlowRecordTests(t *testing.T, flowExp *FlowExporter, isIPv6 bool) {
 
 	mockIPFIXExpProc := ipfixtest.NewMockIPFIXExportingProcess(ctrl)
 	mockDataSet := ipfixentitiestesting.NewMockSet(ctrl)
-	flowExp.process = mockIPFIXExpProc
+	flowExp.collectors = []*flowCollector{newTestCollector(mockIPFIXExpProc)}
 	flowExp.ipfixSet = mockDataSet
 	mockConnDumper := connectionstest.NewMockConnTrackDumper(ctrl)
 	startTime := time.Now()
@@ -611,11 +626,11 @@ func runSendFlowRecordTests(t *testing.T, flowExp *FlowExporter, isIPv6 bool) {
 
 			mockDataSet.EXPECT().ResetSet()
 			if !isIPv6 {
-				mockDataSet.EXPECT().PrepareSet(ipfixentities.Data, flowExp.templateIDv4).Return(nil)
-				mockDataSet.EXPECT().AddRecord(flowExp.elementsListv4, flowExp.templateIDv4).Return(nil)
+				mockDataSet.EXPECT().PrepareSet(ipfixentities.Data, testTemplateIDv4).Return(nil)
+				mockDataSet.EXPECT().AddRecord(flowExp.elementsListv4, testTemplateIDv4).Return(nil)
 			} else {
-				mockDataSet.EXPECT().PrepareSet(ipfixentities.Data, flowExp.templateIDv6).Return(nil)
-				mockDataSet.EXPECT().AddRecord(flowExp.elementsListv6, flowExp.templateIDv6).Return(nil)
+				mockDataSet.EXPECT().PrepareSet(ipfixentities.Data, testTemplateIDv6).Return(nil)
+				mockDataSet.EXPECT().AddRecord(flowExp.elementsListv6, testTemplateIDv6).Return(nil)
 			}
 			mockIPFIXExpProc.EXPECT().SendSet(mockDataSet).Return(0, nil)
 			_, err := flowExp.sendFlowRecords()
@@ -648,6 +663,66 @@ func runSendFlowRecordTests(t *testing.T, flowExp *FlowExporter, isIPv6 bool) {
 	}
 }
 
+func TestFlowExporter_sendFlowRecordsWithSpool(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockIPFIXExpProc := ipfixtest.NewMockIPFIXExportingProcess(ctrl)
+	mockDataSet := ipfixentitiestesting.NewMockSet(ctrl)
+	mockConnDumper := connectionstest.NewMockConnTrackDumper(ctrl)
+	o := &flowexporter.FlowExporterOptions{
+		ActiveFlowTimeout:      testActiveFlowTimeout,
+		IdleFlowTimeout:        testIdleFlowTimeout,
+		StaleConnectionTimeout: 1,
+		PollInterval:           1}
+	recordSpool, err := spool.New(t.TempDir(), 1<<20)
+	require.NoError(t, err)
+	defer recordSpool.Close()
+	elemList := getElemList(IANAInfoElementsIPv4, AntreaInfoElementsIPv4)
+	flowExp := &FlowExporter{
+		elementsListv4:     elemList,
+		v4Enabled:          true,
+		ipfixSet:           mockDataSet,
+		conntrackConnStore: connections.NewConntrackConnectionStore(mockConnDumper, true, false, nil, nil, nil, o),
+		denyConnStore:      connections.NewDenyConnectionStore(nil, nil, o),
+		spool:              recordSpool,
+	}
+	flowExp.conntrackPriorityQueue = flowExp.conntrackConnStore.GetPriorityQueue()
+
+	conn := getConnection(false, true, 4, 6, "ESTABLISHED")
+	flowExp.conntrackConnStore.AddOrUpdateConn(conn)
+	pqItem := flowExp.conntrackPriorityQueue.KeyToItem[flowexporter.NewConnectionKey(conn)]
+	pqItem.ActiveExpireTime = time.Now().Add(-testActiveFlowTimeout)
+
+	// The record of the expired connection is stored in the spool while no
+	// flow collector is available.
+	flowExp.spoolFlowRecords()
+	assert.Equal(t, 1, recordSpool.Len())
+	assert.Empty(t, flowExp.expiredConns)
+
+	// The record stays in the spool if sending it fails.
+	flowExp.collectors = []*flowCollector{newTestCollector(mockIPFIXExpProc)}
+	mockDataSet.EXPECT().ResetSet()
+	mockDataSet.EXPECT().PrepareSet(ipfixentities.Data, testTemplateIDv4).Return(nil)
+	mockDataSet.EXPECT().AddRecord(ElementListMatcher(elemList), testTemplateIDv4).Return(nil)
+	mockIPFIXExpProc.EXPECT().SendSet(mockDataSet).Return(0, fmt.Errorf("connection reset"))
+	mockIPFIXExpProc.EXPECT().CloseConnToCollector()
+	_, err = flowExp.sendFlowRecords()
+	assert.Error(t, err)
+	assert.Equal(t, 1, recordSpool.Len())
+
+	// The record is sent once a flow collector is available again.
+	flowExp.collectors = []*flowCollector{newTestCollector(mockIPFIXExpProc)}
+	mockDataSet.EXPECT().ResetSet()
+	mockDataSet.EXPECT().PrepareSet(ipfixentities.Data, testTemplateIDv4).Return(nil)
+	mockDataSet.EXPECT().AddRecord(ElementListMatcher(elemList), testTemplateIDv4).Return(nil)
+	mockIPFIXExpProc.EXPECT().SendSet(mockDataSet).Return(0, nil)
+	_, err = flowExp.sendFlowRecords()
+	assert.NoError(t, err)
+	assert.Zero(t, recordSpool.Len())
+	assert.Equal(t, uint64(1), flowExp.numDataSetsSent)
+}
+
 func getNumOfConntrackConns(connStore *connections.ConntrackConnectionStore) int {
 	count := 0
 	countNumOfConns := func(key flowexporter.ConnectionKey, conn *flowexporter.Connection) error {
@@ -690,11 +765,34 @@ func TestFlowExporter_prepareExporterInputArgs(t *testing.T) {
 		expInput := prepareExporterInputArgs(tc.collectorAddr, tc.collectorProto, tc.nodeName)
 		assert.Equal(t, tc.collectorAddr, expInput.CollectorAddress)
 		assert.Equal(t, tc.expectedObservationDomainID, expInput.ObservationDomainID)
-		assert.Equal(t, tc.expectedIsEncrypted, expInput.IsEncrypted)
+		assert.Equal(t, tc.expectedIsEncrypted, expInput.TLSClientConfig != nil)
 		assert.Equal(t, tc.expectedProto, expInput.CollectorProtocol)
 	}
 }
 
+type fakeNodeRouteController struct {
+	podSubnets []*net.IPNet
+	nodeIPs    []net.IP
+}
+
+func (c *fakeNodeRouteController) IPInPodSubnets(ip net.IP) bool {
+	for _, podSubnet := range c.podSubnets {
+		if podSubnet.Contains(ip) {
+			return true
+		}
+	}
+	return false
+}
+
+func (c *fakeNodeRouteController) IsNodeIP(ip net.IP) bool {
+	for _, nodeIP := range c.nodeIPs {
+		if nodeIP.Equal(ip) {
+			return true
+		}
+	}
+	return false
+}
+
 func TestFlowExporter_findFlowType(t *testing.T) {
 	conn1 := flowexporter.Connection{SourcePodName: "podA", DestinationPodName: "podB"}
 	conn2 := flowexporter.Connection{SourcePodName: "podA", DestinationPodName: ""}
@@ -705,7 +803,7 @@ func TestFlowExporter_findFlowType(t *testing.T) {
 	}{
 		{true, conn1, 1},
 		{true, conn2, 2},
-		{false, conn1, 0},
+		{false, conn1, 1},
 	} {
 		flowExp := &FlowExporter{
 			isNetworkPolicyOnly: tc.isNetworkPolicyOnly,
@@ -714,3 +812,90 @@ func TestFlowExporter_findFlowType(t *testing.T) {
 		assert.Equal(t, tc.expectedFlowType, flowType)
 	}
 }
+
+func TestFlowExporter_findFlowTypeWithEndpoints(t *testing.T) {
+	_, localPodSubnet, _ := net.ParseCIDR("10.10.0.0/24")
+	_, remotePodSubnet, _ := net.ParseCIDR("10.10.1.0/24")
+	localPodIP := net.ParseIP("10.10.0.2")
+	localPodIP2 := net.ParseIP("10.10.0.3")
+	remotePodIP := net.ParseIP("10.10.1.2")
+	localGatewayIP := net.ParseIP("10.10.0.1")
+	remoteGatewayIP := net.ParseIP("10.10.1.1")
+	localNodeIP := net.ParseIP("192.168.0.1")
+	remoteNodeIP := net.ParseIP("192.168.0.2")
+	serviceIP := net.ParseIP("10.96.0.10")
+	externalIP := net.ParseIP("8.8.8.8")
+	serviceMark := openflow.ServiceCTMark.GetValue()
+
+	newConn := func(srcIP, dstIP net.IP, srcPodName, dstPodName string, mark uint32) flowexporter.Connection {
+		return flowexporter.Connection{
+			FlowKey:            flowexporter.Tuple{SourceAddress: srcIP, DestinationAddress: dstIP},
+			SourcePodName:      srcPodName,
+			DestinationPodName: dstPodName,
+			Mark:               mark,
+		}
+	}
+	flowExp := &FlowExporter{
+		nodeRouteController: &fakeNodeRouteController{
+			podSubnets: []*net.IPNet{localPodSubnet, remotePodSubnet},
+			nodeIPs:    []net.IP{localNodeIP, remoteNodeIP},
+		},
+		localNodeIPs: []net.IP{localNodeIP, localGatewayIP},
+	}
+	for _, tc := range []struct {
+		name             string
+		conn             flowexporter.Connection
+		expectedFlowType uint8
+	}{
+		{"local Pod to local Pod", newConn(localPodIP, localPodIP2, "podA", "podB", 0), ipfixregistry.FlowTypeIntraNode},
+		{"local Pod to remote Pod", newConn(localPodIP, remotePodIP, "podA", "", 0), ipfixregistry.FlowTypeInterNode},
+		{"remote Pod to local Pod", newConn(remotePodIP, localPodIP, "", "podB", 0), ipfixregistry.FlowTypeInterNode},
+		{"local Pod to Service with local endpoint", newConn(localPodIP, serviceIP, "podA", "podB", serviceMark), ipfixregistry.FlowTypeIntraNode},
+		{"local Pod to Service with remote endpoint", newConn(localPodIP, serviceIP, "podA", "", serviceMark), ipfixregistry.FlowTypeInterNode},
+		{"local Pod to external", newConn(localPodIP, externalIP, "podA", "", 0), ipfixregistry.FlowTypeToExternal},
+		{"local hostNetwork Pod to local Pod", newConn(localGatewayIP, localPodIP, "", "podB", 0), ipfixregistry.FlowTypeIntraNode},
+		{"local Pod to local hostNetwork Pod", newConn(localPodIP, localNodeIP, "podA", "", 0), ipfixregistry.FlowTypeIntraNode},
+		{"remote hostNetwork Pod to local Pod", newConn(remoteGatewayIP, localPodIP, "", "podB", 0), ipfixregistry.FlowTypeInterNode},
+		{"remote Node IP to local Pod", newConn(remoteNodeIP, localPodIP, "", "podB", 0), ipfixregistry.FlowTypeInterNode},
+		{"local Pod to remote hostNetwork Pod", newConn(localPodIP, remoteNodeIP, "podA", "", 0), ipfixregistry.FlowTypeToExternal},
+		{"external to local Pod", newConn(externalIP, localPodIP, "", "podB", 0), ipfixregistry.FlowTypeFromExternal},
+		{"external to NodePort Service", newConn(externalIP, localNodeIP, "", "", serviceMark), ipfixregistry.FlowTypeFromExternal},
+		{"external to external", newConn(externalIP, net.ParseIP("8.8.4.4"), "", "", 0), 0},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.Equal(t, tc.expectedFlowType, flowExp.findFlowType(tc.conn))
+		})
+	}
+}
+
+func TestFlowExporter_findFlowTypeOnExternalNode(t *testing.T) {
+	entityIP := net.ParseIP("172.16.0.10")
+	entityIP2 := net.ParseIP("172.16.0.11")
+	externalIP := net.ParseIP("8.8.8.8")
+	ifaceStore := interfacestore.NewInterfaceStore()
+	for i, ip := range []net.IP{entityIP, entityIP2} {
+		ifaceStore.AddInterface(&interfacestore.InterfaceConfig{
+			Type:                  interfacestore.ExternalEntityInterface,
+			InterfaceName:         fmt.Sprintf("eth%d", i),
+			IPs:                   []net.IP{ip},
+			EntityInterfaceConfig: &interfacestore.EntityInterfaceConfig{EntityName: fmt.Sprintf("vm1-eth%d", i), EntityNamespace: "vm-ns"},
+		})
+	}
+	// The nodeRouteController is nil on ExternalNodes.
+	flowExp := &FlowExporter{ifaceStore: ifaceStore}
+	for _, tc := range []struct {
+		srcIP            net.IP
+		dstIP            net.IP
+		expectedFlowType uint8
+	}{
+		{entityIP, entityIP2, ipfixregistry.FlowTypeIntraNode},
+		{entityIP, externalIP, ipfixregistry.FlowTypeToExternal},
+		{externalIP, entityIP, ipfixregistry.FlowTypeFromExternal},
+		{externalIP, net.ParseIP("8.8.4.4"), 0},
+	} {
+		conn := flowexporter.Connection{FlowKey: flowexporter.Tuple{SourceAddress: tc.srcIP, DestinationAddress: tc.dstIP}}
+		assert.Equal(t, tc.expectedFlowType, flowExp.findFlowType(conn), "Unexpected flow type for flow from %s to %s", tc.srcIP, tc.dstIP)
+	}
+	assert.True(t, flowExp.isLocalEndpoint(entityIP, ""))
+	assert.False(t, flowExp.isLocalEndpoint(externalIP, ""))
+}
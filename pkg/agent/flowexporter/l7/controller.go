@@ -0,0 +1,343 @@
+// Copyright 2022 Antrea Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package l7
+
+import (
+	"fmt"
+	"net"
+	"sync"
+	"time"
+
+	v1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/util/wait"
+	corelisters "k8s.io/client-go/listers/core/v1"
+	"k8s.io/client-go/tools/cache"
+	"k8s.io/client-go/util/workqueue"
+	"k8s.io/klog/v2"
+
+	"antrea.io/antrea/pkg/agent/flowexporter"
+	"antrea.io/antrea/pkg/agent/interfacestore"
+	"antrea.io/antrea/pkg/agent/types"
+	"antrea.io/antrea/pkg/util/channel"
+	"antrea.io/antrea/pkg/util/k8s"
+)
+
+const (
+	controllerName = "L7FlowExporterController"
+	// L7FlowExporterAnnotationKey is the annotation of the Pods for which the
+	// L7 information is added to the flow records. Its value is the direction
+	// of the traffic: "ingress", "egress" or "both".
+	L7FlowExporterAnnotationKey = "visibility.antrea.io/l7-export"
+
+	// Set resyncPeriod to 0 to disable resyncing.
+	resyncPeriod time.Duration = 0
+	// How long to wait before retrying the processing of a Pod.
+	minRetryDelay = 5 * time.Second
+	maxRetryDelay = 300 * time.Second
+
+	defaultWorkers = 4
+)
+
+// packetSource reads the packets of an interface.
+type packetSource interface {
+	// ReadPacket returns the next packet, or nil if no packet is received
+	// before the read timeout.
+	ReadPacket() ([]byte, error)
+	Close() error
+}
+
+// podCapture captures the HTTP messages of a selected Pod on its interface.
+type podCapture struct {
+	ifName      string
+	containerID string
+	ips         []net.IP
+	direction   Direction
+	stopCh      chan struct{}
+}
+
+// selects returns whether the connections from clientIP to serverIP are in the
+// selected direction of the Pod.
+func (p *podCapture) selects(clientIP, serverIP net.IP) bool {
+	for _, ip := range p.ips {
+		if ip.Equal(clientIP) && p.direction&DirectionEgress != 0 {
+			return true
+		}
+		if ip.Equal(serverIP) && p.direction&DirectionIngress != 0 {
+			return true
+		}
+	}
+	return false
+}
+
+func (p *podCapture) equals(iface *interfacestore.InterfaceConfig, direction Direction) bool {
+	if p.ifName != iface.InterfaceName || p.containerID != iface.ContainerID || p.direction != direction || len(p.ips) != len(iface.IPs) {
+		return false
+	}
+	for i := range p.ips {
+		if !p.ips[i].Equal(iface.IPs[i]) {
+			return false
+		}
+	}
+	return true
+}
+
+// Controller captures the HTTP messages of the local Pods which have the L7
+// flow export annotation, and adds them to the EventStore.
+type Controller struct {
+	store           *EventStore
+	interfaceStore  interfacestore.InterfaceStore
+	podInformer     cache.SharedIndexInformer
+	podLister       corelisters.PodLister
+	podListerSynced cache.InformerSynced
+	queue           workqueue.RateLimitingInterface
+	newPacketSource func(ifName string) (packetSource, error)
+
+	capturesMutex sync.Mutex
+	// captures are the running captures, indexed by the Pod keys.
+	captures map[string]*podCapture
+}
+
+func NewL7FlowExporterController(store *EventStore,
+	interfaceStore interfacestore.InterfaceStore,
+	podInformer cache.SharedIndexInformer,
+	podUpdateSubscriber channel.Subscriber) *Controller {
+	c := &Controller{
+		store:           store,
+		interfaceStore:  interfaceStore,
+		podInformer:     podInformer,
+		podLister:       corelisters.NewPodLister(podInformer.GetIndexer()),
+		podListerSynced: podInformer.HasSynced,
+		queue:           workqueue.NewNamedRateLimitingQueue(workqueue.NewItemExponentialFailureRateLimiter(minRetryDelay, maxRetryDelay), "l7FlowExporter"),
+		newPacketSource: newAFPacketSource,
+		captures:        map[string]*podCapture{},
+	}
+	c.podInformer.AddEventHandlerWithResyncPeriod(
+		cache.ResourceEventHandlerFuncs{
+			AddFunc:    c.addPod,
+			UpdateFunc: c.updatePod,
+			DeleteFunc: c.deletePod,
+		},
+		resyncPeriod,
+	)
+	podUpdateSubscriber.Subscribe(c.processPodUpdate)
+	return c
+}
+
+// processPodUpdate will be called when CNIServer publishes a Pod update event.
+// The capture of the Pod is restarted when its interface changes.
+func (c *Controller) processPodUpdate(e interface{}) {
+	podEvent := e.(types.PodUpdate)
+	c.queue.Add(k8s.NamespacedName(podEvent.PodNamespace, podEvent.PodName))
+}
+
+func (c *Controller) addPod(obj interface{}) {
+	pod := obj.(*v1.Pod)
+	if _, exists := pod.Annotations[L7FlowExporterAnnotationKey]; !exists {
+		return
+	}
+	klog.V(2).InfoS("Processing Pod ADD event", "Pod", klog.KObj(pod))
+	c.queue.Add(k8s.NamespacedName(pod.Namespace, pod.Name))
+}
+
+func (c *Controller) updatePod(oldObj interface{}, obj interface{}) {
+	oldPod := oldObj.(*v1.Pod)
+	pod := obj.(*v1.Pod)
+	if oldPod.Annotations[L7FlowExporterAnnotationKey] == pod.Annotations[L7FlowExporterAnnotationKey] {
+		return
+	}
+	klog.V(2).InfoS("Processing Pod UPDATE event", "Pod", klog.KObj(pod))
+	c.queue.Add(k8s.NamespacedName(pod.Namespace, pod.Name))
+}
+
+func (c *Controller) deletePod(obj interface{}) {
+	pod, ok := obj.(*v1.Pod)
+	if !ok {
+		deletedState, ok := obj.(cache.DeletedFinalStateUnknown)
+		if !ok {
+			klog.Errorf("Received unexpected object: %v", obj)
+			return
+		}
+		pod, ok = deletedState.Obj.(*v1.Pod)
+		if !ok {
+			klog.Errorf("DeletedFinalStateUnknown contains non-Pod object: %v", deletedState.Obj)
+			return
+		}
+	}
+	if _, exists := pod.Annotations[L7FlowExporterAnnotationKey]; !exists {
+		return
+	}
+	klog.V(2).InfoS("Processing Pod DELETE event", "Pod", klog.KObj(pod))
+	c.queue.Add(k8s.NamespacedName(pod.Namespace, pod.Name))
+}
+
+func (c *Controller) Run(stopCh <-chan struct{}) {
+	defer c.queue.ShutDown()
+
+	klog.InfoS("Starting", "controllerName", controllerName)
+	defer klog.InfoS("Shutting down", "controllerName", controllerName)
+
+	if !cache.WaitForNamedCacheSync(controllerName, stopCh, c.podListerSynced) {
+		return
+	}
+
+	go c.store.Run(stopCh)
+
+	for i := 0; i < defaultWorkers; i++ {
+		go wait.Until(c.worker, time.Second, stopCh)
+	}
+
+	<-stopCh
+	c.capturesMutex.Lock()
+	defer c.capturesMutex.Unlock()
+	for key := range c.captures {
+		c.stopCapture(key)
+	}
+}
+
+func (c *Controller) worker() {
+	for c.processNextWorkItem() {
+	}
+}
+
+func (c *Controller) processNextWorkItem() bool {
+	obj, quit := c.queue.Get()
+	if quit {
+		return false
+	}
+	defer c.queue.Done(obj)
+
+	if key, ok := obj.(string); !ok {
+		// As the item in the work queue is actually invalid, we call Forget here else we'd
+		// go into a loop of attempting to process a work item that is invalid.
+		// This should not happen.
+		c.queue.Forget(obj)
+		klog.Errorf("Expected string in work queue but got %#v", obj)
+		return true
+	} else if err := c.syncPod(key); err == nil {
+		// If no error occurs we Forget this item, so it does not get queued again until
+		// another change happens.
+		c.queue.Forget(key)
+	} else {
+		// Put the item back on the work queue to handle any transient errors.
+		c.queue.AddRateLimited(key)
+		klog.ErrorS(err, "Syncing Pod for L7 flow export failed, requeue", "Pod", key)
+	}
+	return true
+}
+
+// syncPod starts the capture of a Pod if it is selected for L7 flow export and
+// its interface is ready, and stops it otherwise.
+func (c *Controller) syncPod(key string) error {
+	namespace, name, err := cache.SplitMetaNamespaceKey(key)
+	if err != nil {
+		return err
+	}
+	pod, err := c.podLister.Pods(namespace).Get(name)
+	if err != nil && !apierrors.IsNotFound(err) {
+		return err
+	}
+	var iface *interfacestore.InterfaceConfig
+	var direction Direction
+	if pod != nil && !pod.Spec.HostNetwork {
+		if value, exists := pod.Annotations[L7FlowExporterAnnotationKey]; exists {
+			var valid bool
+			if direction, valid = ParseDirection(value); !valid {
+				klog.InfoS("Ignoring invalid L7 flow export annotation", "Pod", klog.KObj(pod), "value", value)
+			} else if ifaces := c.interfaceStore.GetContainerInterfacesByPod(name, namespace); len(ifaces) > 0 {
+				iface = ifaces[0]
+			}
+		}
+	}
+
+	c.capturesMutex.Lock()
+	defer c.capturesMutex.Unlock()
+	if capture, exists := c.captures[key]; exists {
+		if iface != nil && capture.equals(iface, direction) {
+			return nil
+		}
+		c.stopCapture(key)
+	}
+	if iface == nil {
+		return nil
+	}
+	source, err := c.newPacketSource(iface.InterfaceName)
+	if err != nil {
+		return fmt.Errorf("failed to capture packets on interface %s: %w", iface.InterfaceName, err)
+	}
+	capture := &podCapture{
+		ifName:      iface.InterfaceName,
+		containerID: iface.ContainerID,
+		ips:         iface.IPs,
+		direction:   direction,
+		stopCh:      make(chan struct{}),
+	}
+	c.captures[key] = capture
+	c.store.setPodDirection(capture.ips, direction)
+	go c.runCapture(capture, source)
+	klog.InfoS("Started capturing L7 traffic", "Pod", key, "interface", capture.ifName)
+	return nil
+}
+
+// stopCapture must be called with capturesMutex held.
+func (c *Controller) stopCapture(key string) {
+	capture := c.captures[key]
+	close(capture.stopCh)
+	c.store.deletePodDirection(capture.ips)
+	delete(c.captures, key)
+	klog.InfoS("Stopped capturing L7 traffic", "Pod", key, "interface", capture.ifName)
+}
+
+func (c *Controller) runCapture(capture *podCapture, source packetSource) {
+	defer source.Close()
+	for {
+		select {
+		case <-capture.stopCh:
+			return
+		default:
+		}
+		data, err := source.ReadPacket()
+		if err != nil {
+			klog.ErrorS(err, "Failed to read packet, stopping the capture", "interface", capture.ifName)
+			return
+		}
+		if data != nil {
+			c.handlePacket(capture, data)
+		}
+	}
+}
+
+// handlePacket adds the HTTP message carried by a packet to the EventStore.
+// Only the HTTP messages starting at the beginning of a TCP segment are
+// recognized.
+func (c *Controller) handlePacket(capture *podCapture, data []byte) {
+	pkt, ok := parseTCPPacket(data)
+	if !ok || len(pkt.payload) == 0 {
+		return
+	}
+	if req, ok := parseHTTPRequest(pkt.payload); ok {
+		if !capture.selects(pkt.srcIP, pkt.dstIP) {
+			return
+		}
+		tuple := flowexporter.Tuple{SourceAddress: pkt.srcIP, DestinationAddress: pkt.dstIP, Protocol: protocolTCP, SourcePort: pkt.srcPort, DestinationPort: pkt.dstPort}
+		c.store.addHTTPRequest(capture.ifName, tuple, pkt.seq, req)
+	} else if status, ok := parseHTTPResponse(pkt.payload); ok {
+		if !capture.selects(pkt.dstIP, pkt.srcIP) {
+			return
+		}
+		tuple := flowexporter.Tuple{SourceAddress: pkt.dstIP, DestinationAddress: pkt.srcIP, Protocol: protocolTCP, SourcePort: pkt.dstPort, DestinationPort: pkt.srcPort}
+		c.store.addHTTPResponse(capture.ifName, tuple, pkt.seq, status)
+	}
+}
@@ -0,0 +1,202 @@
+// Copyright 2022 Antrea Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package l7
+
+import (
+	"net"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	coreinformers "k8s.io/client-go/informers/core/v1"
+	"k8s.io/client-go/kubernetes/fake"
+	"k8s.io/client-go/tools/cache"
+
+	"antrea.io/antrea/pkg/agent/flowexporter"
+	"antrea.io/antrea/pkg/agent/interfacestore"
+	"antrea.io/antrea/pkg/util/channel"
+)
+
+// fakePacketSource returns the packets sent to its channel.
+type fakePacketSource struct {
+	packets chan []byte
+	closed  chan struct{}
+}
+
+func newFakePacketSource() *fakePacketSource {
+	return &fakePacketSource{
+		packets: make(chan []byte, 10),
+		closed:  make(chan struct{}),
+	}
+}
+
+func (s *fakePacketSource) ReadPacket() ([]byte, error) {
+	select {
+	case data := <-s.packets:
+		return data, nil
+	case <-time.After(10 * time.Millisecond):
+		return nil, nil
+	}
+}
+
+func (s *fakePacketSource) Close() error {
+	close(s.closed)
+	return nil
+}
+
+type fakeController struct {
+	*Controller
+	client *fake.Clientset
+	// sources are the packet sources created by the Controller, indexed by
+	// the interface names.
+	sourcesMutex sync.Mutex
+	sources      map[string]*fakePacketSource
+}
+
+func (c *fakeController) getSource(ifName string) *fakePacketSource {
+	c.sourcesMutex.Lock()
+	defer c.sourcesMutex.Unlock()
+	return c.sources[ifName]
+}
+
+func newFakeController(t *testing.T, objects []runtime.Object, interfaces []*interfacestore.InterfaceConfig) *fakeController {
+	client := fake.NewSimpleClientset(objects...)
+	localPodInformer := coreinformers.NewPodInformer(client, metav1.NamespaceAll, 0, cache.Indexers{cache.NamespaceIndex: cache.MetaNamespaceIndexFunc})
+	ifaceStore := interfacestore.NewInterfaceStore()
+	for _, itf := range interfaces {
+		ifaceStore.AddInterface(itf)
+	}
+	podUpdateChannel := channel.NewSubscribableChannel("PodUpdate", 100)
+	store, _ := newFakeEventStore()
+	c := &fakeController{
+		Controller: NewL7FlowExporterController(store, ifaceStore, localPodInformer, podUpdateChannel),
+		client:     client,
+		sources:    map[string]*fakePacketSource{},
+	}
+	c.newPacketSource = func(ifName string) (packetSource, error) {
+		c.sourcesMutex.Lock()
+		defer c.sourcesMutex.Unlock()
+		source := newFakePacketSource()
+		c.sources[ifName] = source
+		return source, nil
+	}
+	stopCh := make(chan struct{})
+	t.Cleanup(func() {
+		close(stopCh)
+		c.capturesMutex.Lock()
+		defer c.capturesMutex.Unlock()
+		for key := range c.captures {
+			c.stopCapture(key)
+		}
+	})
+	go localPodInformer.Run(stopCh)
+	cache.WaitForCacheSync(stopCh, localPodInformer.HasSynced)
+	return c
+}
+
+func newPod(namespace, name string, annotations map[string]string) *v1.Pod {
+	return &v1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace:   namespace,
+			Name:        name,
+			Annotations: annotations,
+		},
+	}
+}
+
+func TestSyncPod(t *testing.T) {
+	pod1 := newPod("ns1", "pod1", map[string]string{L7FlowExporterAnnotationKey: "egress"})
+	pod2 := newPod("ns1", "pod2", map[string]string{L7FlowExporterAnnotationKey: "invalid"})
+	pod3 := newPod("ns1", "pod3", nil)
+	pod4 := newPod("ns1", "pod4", map[string]string{L7FlowExporterAnnotationKey: "both"})
+	interfaces := []*interfacestore.InterfaceConfig{
+		interfacestore.NewContainerInterface("pod1-eth0", "container1", "pod1", "ns1", nil, []net.IP{clientIP}, 0),
+		interfacestore.NewContainerInterface("pod2-eth0", "container2", "pod2", "ns1", nil, []net.IP{net.ParseIP("10.10.0.3")}, 0),
+		interfacestore.NewContainerInterface("pod3-eth0", "container3", "pod3", "ns1", nil, []net.IP{net.ParseIP("10.10.0.4")}, 0),
+	}
+	c := newFakeController(t, []runtime.Object{pod1, pod2, pod3, pod4}, interfaces)
+
+	for _, key := range []string{"ns1/pod1", "ns1/pod2", "ns1/pod3", "ns1/pod4"} {
+		require.NoError(t, c.syncPod(key))
+	}
+	// Only pod1 is captured: the annotation of pod2 is invalid, pod3 has no
+	// annotation and the interface of pod4 is not created yet.
+	assert.Len(t, c.captures, 1)
+	require.Contains(t, c.captures, "ns1/pod1")
+	assert.Equal(t, DirectionEgress, c.captures["ns1/pod1"].direction)
+	assert.Equal(t, map[string]Direction{clientIP.String(): DirectionEgress}, c.store.podDirections)
+	source := c.getSource("pod1-eth0")
+	require.NotNil(t, source)
+
+	// Syncing the Pod again does not restart the capture.
+	require.NoError(t, c.syncPod("ns1/pod1"))
+	assert.Same(t, source, c.getSource("pod1-eth0"))
+
+	// The capture is restarted when the direction changes.
+	pod1.Annotations[L7FlowExporterAnnotationKey] = "both"
+	require.NoError(t, c.podInformer.GetIndexer().Update(pod1))
+	require.NoError(t, c.syncPod("ns1/pod1"))
+	assert.Equal(t, DirectionBoth, c.captures["ns1/pod1"].direction)
+	assert.Equal(t, map[string]Direction{clientIP.String(): DirectionBoth}, c.store.podDirections)
+	select {
+	case <-source.closed:
+	case <-time.After(time.Second):
+		t.Fatal("The packet source of the previous capture was not closed")
+	}
+
+	// The capture is stopped when the Pod is deleted.
+	require.NoError(t, c.podInformer.GetIndexer().Delete(pod1))
+	require.NoError(t, c.syncPod("ns1/pod1"))
+	assert.Empty(t, c.captures)
+	assert.Empty(t, c.store.podDirections)
+}
+
+func TestCaptureHTTP(t *testing.T) {
+	pod1 := newPod("ns1", "pod1", map[string]string{L7FlowExporterAnnotationKey: "ingress"})
+	interfaces := []*interfacestore.InterfaceConfig{
+		interfacestore.NewContainerInterface("pod1-eth0", "container1", "pod1", "ns1", nil, []net.IP{serverIP}, 0),
+	}
+	c := newFakeController(t, []runtime.Object{pod1}, interfaces)
+	require.NoError(t, c.syncPod("ns1/pod1"))
+	source := c.getSource("pod1-eth0")
+	require.NotNil(t, source)
+
+	request := "GET /index.html HTTP/1.1\r\nHost: www.example.com\r\n\r\n"
+	response := "HTTP/1.1 200 OK\r\nContent-Length: 0\r\n\r\n"
+	// The connections initiated by the Pod are not in the selected direction.
+	source.packets <- newTCPFrame(serverIP, clientIP, 40001, 80, 1000, []byte(request))
+	source.packets <- newTCPFrame(clientIP, serverIP, 40000, 80, 1000, []byte(request))
+	source.packets <- newTCPFrame(serverIP, clientIP, 80, 40000, 5000, []byte(response))
+
+	conn := &flowexporter.Connection{FlowKey: clientTuple}
+	assert.Eventually(t, func() bool {
+		c.store.FillConnection(conn)
+		return conn.HttpVals != ""
+	}, time.Second, 10*time.Millisecond)
+	assert.Equal(t, AppProtocolHTTP, conn.AppProtocolName)
+	assert.Equal(t, map[int32]httpTransaction{
+		0: {Hostname: "www.example.com", URL: "/index.html", HTTPMethod: "GET", Protocol: "HTTP/1.1", Status: 200},
+	}, decodeHTTPVals(t, conn.HttpVals))
+
+	reverseTuple := flowexporter.Tuple{SourceAddress: serverIP, DestinationAddress: clientIP, Protocol: protocolTCP, SourcePort: 40001, DestinationPort: 80}
+	conn = &flowexporter.Connection{FlowKey: reverseTuple}
+	c.store.FillConnection(conn)
+	assert.Empty(t, conn.AppProtocolName)
+}
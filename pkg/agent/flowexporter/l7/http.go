@@ -0,0 +1,170 @@
+// Copyright 2022 Antrea Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package l7
+
+import (
+	"bytes"
+	"encoding/binary"
+	"net"
+	"strconv"
+	"strings"
+)
+
+const (
+	etherTypeIPv4 = 0x0800
+	etherTypeIPv6 = 0x86dd
+	etherTypeVLAN = 0x8100
+
+	protocolTCP = 6
+)
+
+// httpMethods are the HTTP/1.x request methods which are recognized at the
+// beginning of a TCP payload.
+var httpMethods = map[string]struct{}{
+	"GET":     {},
+	"HEAD":    {},
+	"POST":    {},
+	"PUT":     {},
+	"DELETE":  {},
+	"CONNECT": {},
+	"OPTIONS": {},
+	"TRACE":   {},
+	"PATCH":   {},
+}
+
+// tcpPacket is the part of a captured TCP packet which is needed to extract
+// the HTTP messages.
+type tcpPacket struct {
+	srcIP   net.IP
+	dstIP   net.IP
+	srcPort uint16
+	dstPort uint16
+	seq     uint32
+	payload []byte
+}
+
+// httpRequest is the request line and the Host header of an HTTP request.
+type httpRequest struct {
+	method   string
+	url      string
+	protocol string
+	hostname string
+}
+
+// parseTCPPacket parses an Ethernet frame carrying an IPv4 or IPv6 TCP
+// packet. IPv6 extension headers are not supported.
+func parseTCPPacket(data []byte) (*tcpPacket, bool) {
+	if len(data) < 14 {
+		return nil, false
+	}
+	etherType := binary.BigEndian.Uint16(data[12:14])
+	data = data[14:]
+	if etherType == etherTypeVLAN {
+		if len(data) < 4 {
+			return nil, false
+		}
+		etherType = binary.BigEndian.Uint16(data[2:4])
+		data = data[4:]
+	}
+	pkt := &tcpPacket{}
+	switch etherType {
+	case etherTypeIPv4:
+		if len(data) < 20 || data[0]>>4 != 4 || data[9] != protocolTCP {
+			return nil, false
+		}
+		headerLen := int(data[0]&0x0f) * 4
+		totalLen := int(binary.BigEndian.Uint16(data[2:4]))
+		// Ignore the fragments except the first one.
+		if binary.BigEndian.Uint16(data[6:8])&0x1fff != 0 {
+			return nil, false
+		}
+		if headerLen < 20 || totalLen < headerLen || len(data) < headerLen {
+			return nil, false
+		}
+		if totalLen < len(data) {
+			// Remove the Ethernet padding.
+			data = data[:totalLen]
+		}
+		pkt.srcIP = net.IP(data[12:16])
+		pkt.dstIP = net.IP(data[16:20])
+		data = data[headerLen:]
+	case etherTypeIPv6:
+		if len(data) < 40 || data[0]>>4 != 6 || data[6] != protocolTCP {
+			return nil, false
+		}
+		payloadLen := int(binary.BigEndian.Uint16(data[4:6]))
+		pkt.srcIP = net.IP(data[8:24])
+		pkt.dstIP = net.IP(data[24:40])
+		data = data[40:]
+		if payloadLen < len(data) {
+			data = data[:payloadLen]
+		}
+	default:
+		return nil, false
+	}
+	if len(data) < 20 {
+		return nil, false
+	}
+	pkt.srcPort = binary.BigEndian.Uint16(data[0:2])
+	pkt.dstPort = binary.BigEndian.Uint16(data[2:4])
+	pkt.seq = binary.BigEndian.Uint32(data[4:8])
+	dataOffset := int(data[12]>>4) * 4
+	if dataOffset < 20 || len(data) < dataOffset {
+		return nil, false
+	}
+	pkt.payload = data[dataOffset:]
+	return pkt, true
+}
+
+// parseHTTPRequest parses the beginning of an HTTP/1.x request. Only the
+// request line and the headers included in the payload are considered.
+func parseHTTPRequest(payload []byte) (*httpRequest, bool) {
+	lines := bytes.Split(payload, []byte("\r\n"))
+	fields := strings.Fields(string(lines[0]))
+	if len(fields) != 3 || !strings.HasPrefix(fields[2], "HTTP/1.") {
+		return nil, false
+	}
+	if _, ok := httpMethods[fields[0]]; !ok {
+		return nil, false
+	}
+	req := &httpRequest{method: fields[0], url: fields[1], protocol: fields[2]}
+	for _, line := range lines[1:] {
+		// An empty line ends the headers.
+		if len(line) == 0 {
+			break
+		}
+		name, value, found := strings.Cut(string(line), ":")
+		if found && strings.EqualFold(name, "Host") {
+			req.hostname = strings.TrimSpace(value)
+			break
+		}
+	}
+	return req, true
+}
+
+// parseHTTPResponse parses the status line of an HTTP/1.x response and returns
+// the status code.
+func parseHTTPResponse(payload []byte) (int, bool) {
+	statusLine, _, _ := bytes.Cut(payload, []byte("\r\n"))
+	fields := strings.Fields(string(statusLine))
+	if len(fields) < 2 || !strings.HasPrefix(fields[0], "HTTP/1.") {
+		return 0, false
+	}
+	status, err := strconv.Atoi(fields[1])
+	if err != nil || status < 100 || status > 999 {
+		return 0, false
+	}
+	return status, true
+}
@@ -0,0 +1,190 @@
+// Copyright 2022 Antrea Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package l7
+
+import (
+	"encoding/binary"
+	"net"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newTCPFrame builds an Ethernet frame carrying a TCP segment with the given
+// payload. The checksums are not computed as they are not checked.
+func newTCPFrame(srcIP, dstIP net.IP, srcPort, dstPort uint16, seq uint32, payload []byte) []byte {
+	tcp := make([]byte, 20, 20+len(payload))
+	binary.BigEndian.PutUint16(tcp[0:2], srcPort)
+	binary.BigEndian.PutUint16(tcp[2:4], dstPort)
+	binary.BigEndian.PutUint32(tcp[4:8], seq)
+	tcp[12] = 5 << 4
+	tcp = append(tcp, payload...)
+
+	frame := make([]byte, 12, 14+40+len(tcp))
+	if srcIP.To4() != nil {
+		frame = binary.BigEndian.AppendUint16(frame, etherTypeIPv4)
+		ip := make([]byte, 20)
+		ip[0] = 4<<4 | 5
+		binary.BigEndian.PutUint16(ip[2:4], uint16(20+len(tcp)))
+		ip[8] = 64
+		ip[9] = protocolTCP
+		copy(ip[12:16], srcIP.To4())
+		copy(ip[16:20], dstIP.To4())
+		frame = append(frame, ip...)
+	} else {
+		frame = binary.BigEndian.AppendUint16(frame, etherTypeIPv6)
+		ip := make([]byte, 40)
+		ip[0] = 6 << 4
+		binary.BigEndian.PutUint16(ip[4:6], uint16(len(tcp)))
+		ip[6] = protocolTCP
+		ip[7] = 64
+		copy(ip[8:24], srcIP.To16())
+		copy(ip[24:40], dstIP.To16())
+		frame = append(frame, ip...)
+	}
+	return append(frame, tcp...)
+}
+
+func TestParseTCPPacket(t *testing.T) {
+	payload := []byte("GET / HTTP/1.1\r\n\r\n")
+	for _, tc := range []struct {
+		name  string
+		srcIP net.IP
+		dstIP net.IP
+	}{
+		{"IPv4", net.ParseIP("10.10.0.1"), net.ParseIP("10.10.0.2")},
+		{"IPv6", net.ParseIP("fd00:10:10::1"), net.ParseIP("fd00:10:10::2")},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			pkt, ok := parseTCPPacket(newTCPFrame(tc.srcIP, tc.dstIP, 40000, 80, 1000, payload))
+			require.True(t, ok)
+			assert.True(t, tc.srcIP.Equal(pkt.srcIP))
+			assert.True(t, tc.dstIP.Equal(pkt.dstIP))
+			assert.Equal(t, uint16(40000), pkt.srcPort)
+			assert.Equal(t, uint16(80), pkt.dstPort)
+			assert.Equal(t, uint32(1000), pkt.seq)
+			assert.Equal(t, payload, pkt.payload)
+		})
+	}
+
+	t.Run("Ethernet padding", func(t *testing.T) {
+		frame := newTCPFrame(net.ParseIP("10.10.0.1"), net.ParseIP("10.10.0.2"), 40000, 80, 1000, nil)
+		frame = append(frame, make([]byte, 6)...)
+		pkt, ok := parseTCPPacket(frame)
+		require.True(t, ok)
+		assert.Empty(t, pkt.payload)
+	})
+
+	t.Run("VLAN", func(t *testing.T) {
+		frame := newTCPFrame(net.ParseIP("10.10.0.1"), net.ParseIP("10.10.0.2"), 40000, 80, 1000, payload)
+		tagged := append([]byte{}, frame[:12]...)
+		tagged = binary.BigEndian.AppendUint16(tagged, etherTypeVLAN)
+		tagged = append(tagged, 0, 10)
+		tagged = append(tagged, frame[12:]...)
+		pkt, ok := parseTCPPacket(tagged)
+		require.True(t, ok)
+		assert.Equal(t, payload, pkt.payload)
+	})
+
+	t.Run("non-first fragment", func(t *testing.T) {
+		frame := newTCPFrame(net.ParseIP("10.10.0.1"), net.ParseIP("10.10.0.2"), 40000, 80, 1000, payload)
+		binary.BigEndian.PutUint16(frame[14+6:14+8], 100)
+		_, ok := parseTCPPacket(frame)
+		assert.False(t, ok)
+	})
+
+	t.Run("UDP", func(t *testing.T) {
+		frame := newTCPFrame(net.ParseIP("10.10.0.1"), net.ParseIP("10.10.0.2"), 40000, 53, 0, nil)
+		frame[14+9] = 17
+		_, ok := parseTCPPacket(frame)
+		assert.False(t, ok)
+	})
+
+	t.Run("truncated", func(t *testing.T) {
+		frame := newTCPFrame(net.ParseIP("10.10.0.1"), net.ParseIP("10.10.0.2"), 40000, 80, 1000, nil)
+		_, ok := parseTCPPacket(frame[:30])
+		assert.False(t, ok)
+	})
+}
+
+func TestParseHTTPRequest(t *testing.T) {
+	for _, tc := range []struct {
+		name     string
+		payload  string
+		expected *httpRequest
+	}{
+		{
+			name:     "GET with Host",
+			payload:  "GET /index.html HTTP/1.1\r\nUser-Agent: curl/7.81.0\r\nhost: www.example.com \r\n\r\n",
+			expected: &httpRequest{method: "GET", url: "/index.html", protocol: "HTTP/1.1", hostname: "www.example.com"},
+		},
+		{
+			name:     "POST without Host",
+			payload:  "POST /api HTTP/1.0\r\nContent-Length: 2\r\n\r\n{}",
+			expected: &httpRequest{method: "POST", url: "/api", protocol: "HTTP/1.0"},
+		},
+		{
+			name:     "Host in body",
+			payload:  "PUT /api HTTP/1.1\r\n\r\nHost: www.example.com\r\n",
+			expected: &httpRequest{method: "PUT", url: "/api", protocol: "HTTP/1.1"},
+		},
+		{
+			name:    "unknown method",
+			payload: "FOO / HTTP/1.1\r\n\r\n",
+		},
+		{
+			name:    "HTTP/2",
+			payload: "PRI * HTTP/2.0\r\n\r\nSM\r\n\r\n",
+		},
+		{
+			name:    "response",
+			payload: "HTTP/1.1 200 OK\r\n\r\n",
+		},
+		{
+			name:    "binary",
+			payload: "\x16\x03\x01\x02\x00\x01\x00\x01\xfc\x03\x03",
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			req, ok := parseHTTPRequest([]byte(tc.payload))
+			assert.Equal(t, tc.expected != nil, ok)
+			assert.Equal(t, tc.expected, req)
+		})
+	}
+}
+
+func TestParseHTTPResponse(t *testing.T) {
+	for _, tc := range []struct {
+		name           string
+		payload        string
+		expectedStatus int
+		expectedOK     bool
+	}{
+		{"OK", "HTTP/1.1 200 OK\r\nContent-Length: 0\r\n\r\n", 200, true},
+		{"no reason phrase", "HTTP/1.0 404\r\n\r\n", 404, true},
+		{"status line only", "HTTP/1.1 503 Service Unavailable", 503, true},
+		{"invalid status", "HTTP/1.1 abc OK\r\n\r\n", 0, false},
+		{"out of range status", "HTTP/1.1 42 OK\r\n\r\n", 0, false},
+		{"request", "GET / HTTP/1.1\r\n\r\n", 0, false},
+		{"HTTP/2", "HTTP/2 200\r\n\r\n", 0, false},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			status, ok := parseHTTPResponse([]byte(tc.payload))
+			assert.Equal(t, tc.expectedOK, ok)
+			assert.Equal(t, tc.expectedStatus, status)
+		})
+	}
+}
@@ -0,0 +1,306 @@
+// Copyright 2022 Antrea Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package l7
+
+import (
+	"encoding/json"
+	"net"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/miekg/dns"
+	"k8s.io/klog/v2"
+	"k8s.io/utils/clock"
+
+	"antrea.io/antrea/pkg/agent/flowexporter"
+)
+
+const (
+	AppProtocolHTTP = "http"
+	AppProtocolDNS  = "dns"
+
+	// maxPendingRequests is the maximum number of HTTP requests of a
+	// connection which are waiting for their responses. The oldest requests
+	// are dropped beyond it.
+	maxPendingRequests = 64
+
+	periodicDeleteInterval = time.Minute
+)
+
+// Direction is the direction of the traffic of a Pod for which the L7
+// information is exported.
+type Direction uint8
+
+const (
+	DirectionIngress Direction = 1 << iota
+	DirectionEgress
+	DirectionBoth = DirectionIngress | DirectionEgress
+)
+
+// ParseDirection parses the value of the L7 flow export annotation of a Pod.
+func ParseDirection(value string) (Direction, bool) {
+	switch strings.ToLower(value) {
+	case "ingress":
+		return DirectionIngress, true
+	case "egress":
+		return DirectionEgress, true
+	case "both":
+		return DirectionBoth, true
+	}
+	return 0, false
+}
+
+// httpTransaction is an HTTP request and its response. It is exported as
+// the JSON value of a transaction in the httpVals information element.
+type httpTransaction struct {
+	Hostname   string `json:"hostname"`
+	URL        string `json:"url"`
+	HTTPMethod string `json:"http_method"`
+	Protocol   string `json:"protocol"`
+	Status     int    `json:"status"`
+}
+
+type pendingRequest struct {
+	id          int32
+	transaction httpTransaction
+}
+
+// connectionEvents are the L7 events of a connection.
+type connectionEvents struct {
+	// observer is the interface on which the HTTP messages of the connection
+	// are captured. A connection between two selected Pods of the Node is
+	// seen on the interfaces of both Pods, and only the first one is used.
+	observer        string
+	appProtocolName string
+	// nextTransactionID is the ID of the next HTTP request of the
+	// connection.
+	nextTransactionID int32
+	pendingRequests   []pendingRequest
+	// The TCP sequence numbers of the last HTTP request and response, which
+	// are used to ignore the retransmitted messages.
+	lastRequestSeq  *uint32
+	lastResponseSeq *uint32
+	// transactions are the completed HTTP transactions which have not been
+	// exported yet, indexed by their IDs.
+	transactions    map[int32]string
+	dnsQueryName    string
+	dnsResponseCode uint8
+	lastUpdateTime  time.Time
+}
+
+// EventStore stores the L7 events of the connections of the Pods selected for
+// L7 flow export, until they are added to the flow records.
+type EventStore struct {
+	mutex  sync.Mutex
+	events map[flowexporter.ConnectionKey]*connectionEvents
+	// podDirections maps the IPs of the selected Pods to the directions of
+	// their traffic.
+	podDirections          map[string]Direction
+	staleConnectionTimeout time.Duration
+	clock                  clock.WithTicker
+}
+
+func NewEventStore(staleConnectionTimeout time.Duration) *EventStore {
+	return newEventStoreWithClock(staleConnectionTimeout, clock.RealClock{})
+}
+
+func newEventStoreWithClock(staleConnectionTimeout time.Duration, clock clock.WithTicker) *EventStore {
+	return &EventStore{
+		events:                 map[flowexporter.ConnectionKey]*connectionEvents{},
+		podDirections:          map[string]Direction{},
+		staleConnectionTimeout: staleConnectionTimeout,
+		clock:                  clock,
+	}
+}
+
+func tupleToKey(tuple flowexporter.Tuple) flowexporter.ConnectionKey {
+	return flowexporter.NewConnectionKey(&flowexporter.Connection{FlowKey: tuple})
+}
+
+func (s *EventStore) setPodDirection(ips []net.IP, direction Direction) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	for _, ip := range ips {
+		s.podDirections[ip.String()] = direction
+	}
+}
+
+func (s *EventStore) deletePodDirection(ips []net.IP) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	for _, ip := range ips {
+		delete(s.podDirections, ip.String())
+	}
+}
+
+// getOrCreateEvents returns the events of a connection. It returns nil if the
+// HTTP messages of the connection are captured on another interface.
+func (s *EventStore) getOrCreateEvents(observer string, tuple flowexporter.Tuple) *connectionEvents {
+	key := tupleToKey(tuple)
+	events, exists := s.events[key]
+	if !exists {
+		events = &connectionEvents{
+			observer:     observer,
+			transactions: map[int32]string{},
+		}
+		s.events[key] = events
+	} else if events.observer == "" {
+		events.observer = observer
+	} else if observer != "" && events.observer != observer {
+		return nil
+	}
+	events.lastUpdateTime = s.clock.Now()
+	return events
+}
+
+// AddDNSResponse adds the query name and the response code of a DNS response
+// to the connection which carried the query. The tuple is the one of the
+// query.
+func (s *EventStore) AddDNSResponse(tuple flowexporter.Tuple, msg *dns.Msg) {
+	if len(msg.Question) == 0 {
+		return
+	}
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	if s.podDirections[tuple.SourceAddress.String()]&DirectionEgress == 0 {
+		return
+	}
+	events := s.getOrCreateEvents("", tuple)
+	events.appProtocolName = AppProtocolDNS
+	events.dnsQueryName = strings.TrimSuffix(strings.ToLower(msg.Question[0].Name), ".")
+	events.dnsResponseCode = uint8(msg.Rcode)
+}
+
+// addHTTPRequest adds an HTTP request to a connection. The tuple is the one
+// from the client to the server.
+func (s *EventStore) addHTTPRequest(observer string, tuple flowexporter.Tuple, seq uint32, req *httpRequest) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	events := s.getOrCreateEvents(observer, tuple)
+	if events == nil || (events.lastRequestSeq != nil && *events.lastRequestSeq == seq) {
+		return
+	}
+	events.lastRequestSeq = &seq
+	events.appProtocolName = AppProtocolHTTP
+	if len(events.pendingRequests) == maxPendingRequests {
+		events.pendingRequests = events.pendingRequests[1:]
+	}
+	events.pendingRequests = append(events.pendingRequests, pendingRequest{
+		id: events.nextTransactionID,
+		transaction: httpTransaction{
+			Hostname:   req.hostname,
+			URL:        req.url,
+			HTTPMethod: req.method,
+			Protocol:   req.protocol,
+		},
+	})
+	events.nextTransactionID++
+}
+
+// addHTTPResponse completes the oldest pending HTTP request of a connection
+// with the status of a response. The tuple is the one from the client to the
+// server.
+func (s *EventStore) addHTTPResponse(observer string, tuple flowexporter.Tuple, seq uint32, status int) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	events, exists := s.events[tupleToKey(tuple)]
+	if !exists || events.observer != observer || len(events.pendingRequests) == 0 {
+		return
+	}
+	if events.lastResponseSeq != nil && *events.lastResponseSeq == seq {
+		return
+	}
+	events.lastResponseSeq = &seq
+	req := events.pendingRequests[0]
+	events.pendingRequests = events.pendingRequests[1:]
+	req.transaction.Status = status
+	value, err := json.Marshal(req.transaction)
+	if err != nil {
+		klog.ErrorS(err, "Failed to encode HTTP transaction", "tuple", tuple)
+		return
+	}
+	events.transactions[req.id] = string(value)
+	events.lastUpdateTime = s.clock.Now()
+}
+
+// lookupEvents returns the events of a connection. The events of a connection
+// to a Service may be stored with the tuple of the Service, e.g. the ones
+// observed on the client side.
+func (s *EventStore) lookupEvents(conn *flowexporter.Connection) *connectionEvents {
+	if events, exists := s.events[flowexporter.NewConnectionKey(conn)]; exists {
+		return events
+	}
+	if conn.DestinationServicePortName == "" || conn.DestinationServiceAddress == nil {
+		return nil
+	}
+	serviceTuple := conn.FlowKey
+	serviceTuple.DestinationAddress = conn.DestinationServiceAddress
+	serviceTuple.DestinationPort = conn.DestinationServicePort
+	return s.events[tupleToKey(serviceTuple)]
+}
+
+// FillConnection adds the L7 information of a connection to its flow record.
+// The HTTP transactions are only added to one flow record, the other fields
+// are added to all the flow records of the connection.
+func (s *EventStore) FillConnection(conn *flowexporter.Connection) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	events := s.lookupEvents(conn)
+	if events == nil {
+		return
+	}
+	// The events are kept as long as the connection is exported.
+	events.lastUpdateTime = s.clock.Now()
+	conn.AppProtocolName = events.appProtocolName
+	conn.DNSQueryName = events.dnsQueryName
+	conn.DNSResponseCode = events.dnsResponseCode
+	if len(events.transactions) == 0 {
+		return
+	}
+	httpVals, err := json.Marshal(events.transactions)
+	if err != nil {
+		klog.ErrorS(err, "Failed to encode HTTP transactions", "flowKey", conn.FlowKey)
+		return
+	}
+	conn.HttpVals = string(httpVals)
+	events.transactions = map[int32]string{}
+}
+
+// Run deletes periodically the events of the connections which have not been
+// updated for staleConnectionTimeout.
+func (s *EventStore) Run(stopCh <-chan struct{}) {
+	ticker := s.clock.NewTicker(periodicDeleteInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-stopCh:
+			return
+		case <-ticker.C():
+			s.deleteStaleEvents()
+		}
+	}
+}
+
+func (s *EventStore) deleteStaleEvents() {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	now := s.clock.Now()
+	for key, events := range s.events {
+		if now.Sub(events.lastUpdateTime) >= s.staleConnectionTimeout {
+			delete(s.events, key)
+		}
+	}
+}
@@ -0,0 +1,218 @@
+// Copyright 2022 Antrea Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package l7
+
+import (
+	"encoding/json"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/miekg/dns"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	clock "k8s.io/utils/clock/testing"
+
+	"antrea.io/antrea/pkg/agent/flowexporter"
+)
+
+const testStaleConnectionTimeout = 5 * time.Minute
+
+var (
+	clientIP  = net.ParseIP("10.10.0.1")
+	serverIP  = net.ParseIP("10.10.0.2")
+	serviceIP = net.ParseIP("10.96.0.10")
+
+	clientTuple = flowexporter.Tuple{SourceAddress: clientIP, DestinationAddress: serverIP, Protocol: protocolTCP, SourcePort: 40000, DestinationPort: 80}
+)
+
+func newFakeEventStore() (*EventStore, *clock.FakeClock) {
+	fakeClock := clock.NewFakeClock(time.Now())
+	return newEventStoreWithClock(testStaleConnectionTimeout, fakeClock), fakeClock
+}
+
+func newDNSResponse(name string, rcode int) *dns.Msg {
+	msg := &dns.Msg{}
+	msg.SetQuestion(name, dns.TypeA)
+	msg.Response = true
+	msg.Rcode = rcode
+	return msg
+}
+
+// decodeHTTPVals decodes the httpVals of a flow record.
+func decodeHTTPVals(t *testing.T, httpVals string) map[int32]httpTransaction {
+	var values map[int32]string
+	require.NoError(t, json.Unmarshal([]byte(httpVals), &values))
+	transactions := make(map[int32]httpTransaction, len(values))
+	for id, value := range values {
+		var transaction httpTransaction
+		require.NoError(t, json.Unmarshal([]byte(value), &transaction))
+		transactions[id] = transaction
+	}
+	return transactions
+}
+
+func TestParseDirection(t *testing.T) {
+	for _, tc := range []struct {
+		value             string
+		expectedDirection Direction
+		expectedValid     bool
+	}{
+		{"ingress", DirectionIngress, true},
+		{"Egress", DirectionEgress, true},
+		{"BOTH", DirectionBoth, true},
+		{"", 0, false},
+		{"all", 0, false},
+	} {
+		direction, valid := ParseDirection(tc.value)
+		assert.Equal(t, tc.expectedDirection, direction, tc.value)
+		assert.Equal(t, tc.expectedValid, valid, tc.value)
+	}
+}
+
+func TestAddDNSResponse(t *testing.T) {
+	dnsTuple := flowexporter.Tuple{SourceAddress: clientIP, DestinationAddress: serviceIP, Protocol: 17, SourcePort: 50000, DestinationPort: 53}
+	for _, tc := range []struct {
+		name              string
+		direction         Direction
+		expectedQueryName string
+		expectedRcode     uint8
+	}{
+		{"egress", DirectionEgress, "www.example.com", dns.RcodeNameError},
+		{"both", DirectionBoth, "www.example.com", dns.RcodeNameError},
+		{"ingress", DirectionIngress, "", 0},
+		{"not selected", 0, "", 0},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			store, _ := newFakeEventStore()
+			if tc.direction != 0 {
+				store.setPodDirection([]net.IP{clientIP}, tc.direction)
+			}
+			store.AddDNSResponse(dnsTuple, newDNSResponse("WWW.Example.com.", dns.RcodeNameError))
+
+			conn := &flowexporter.Connection{FlowKey: dnsTuple}
+			store.FillConnection(conn)
+			if tc.expectedQueryName == "" {
+				assert.Empty(t, conn.AppProtocolName)
+			} else {
+				assert.Equal(t, AppProtocolDNS, conn.AppProtocolName)
+			}
+			assert.Equal(t, tc.expectedQueryName, conn.DNSQueryName)
+			assert.Equal(t, tc.expectedRcode, conn.DNSResponseCode)
+			assert.Empty(t, conn.HttpVals)
+		})
+	}
+}
+
+func TestHTTPTransactions(t *testing.T) {
+	store, _ := newFakeEventStore()
+	getReq := &httpRequest{method: "GET", url: "/", protocol: "HTTP/1.1", hostname: "www.example.com"}
+	postReq := &httpRequest{method: "POST", url: "/api", protocol: "HTTP/1.1", hostname: "www.example.com"}
+
+	store.addHTTPRequest("pod1-eth0", clientTuple, 1000, getReq)
+	// Retransmitted request.
+	store.addHTTPRequest("pod1-eth0", clientTuple, 1000, getReq)
+	// The same request observed on the interface of the server.
+	store.addHTTPRequest("pod2-eth0", clientTuple, 1000, getReq)
+	store.addHTTPRequest("pod1-eth0", clientTuple, 1100, postReq)
+	store.addHTTPResponse("pod1-eth0", clientTuple, 5000, 200)
+	// Retransmitted response.
+	store.addHTTPResponse("pod1-eth0", clientTuple, 5000, 200)
+	store.addHTTPResponse("pod2-eth0", clientTuple, 5000, 200)
+
+	conn := &flowexporter.Connection{FlowKey: clientTuple}
+	store.FillConnection(conn)
+	assert.Equal(t, AppProtocolHTTP, conn.AppProtocolName)
+	assert.Equal(t, map[int32]httpTransaction{
+		0: {Hostname: "www.example.com", URL: "/", HTTPMethod: "GET", Protocol: "HTTP/1.1", Status: 200},
+	}, decodeHTTPVals(t, conn.HttpVals))
+
+	// Only the transactions completed since the last export are added to the
+	// flow record.
+	store.addHTTPResponse("pod1-eth0", clientTuple, 5200, 201)
+	conn = &flowexporter.Connection{FlowKey: clientTuple}
+	store.FillConnection(conn)
+	assert.Equal(t, AppProtocolHTTP, conn.AppProtocolName)
+	assert.Equal(t, map[int32]httpTransaction{
+		1: {Hostname: "www.example.com", URL: "/api", HTTPMethod: "POST", Protocol: "HTTP/1.1", Status: 201},
+	}, decodeHTTPVals(t, conn.HttpVals))
+
+	conn = &flowexporter.Connection{FlowKey: clientTuple}
+	store.FillConnection(conn)
+	assert.Equal(t, AppProtocolHTTP, conn.AppProtocolName)
+	assert.Empty(t, conn.HttpVals)
+}
+
+func TestPendingRequestsLimit(t *testing.T) {
+	store, _ := newFakeEventStore()
+	for i := 0; i <= maxPendingRequests; i++ {
+		store.addHTTPRequest("pod1-eth0", clientTuple, uint32(i), &httpRequest{method: "GET", url: "/", protocol: "HTTP/1.1"})
+	}
+	// The oldest request has been dropped, so the response completes the
+	// second one.
+	store.addHTTPResponse("pod1-eth0", clientTuple, 0, 200)
+	conn := &flowexporter.Connection{FlowKey: clientTuple}
+	store.FillConnection(conn)
+	transactions := decodeHTTPVals(t, conn.HttpVals)
+	require.Len(t, transactions, 1)
+	assert.Contains(t, transactions, int32(1))
+}
+
+func TestFillConnectionWithServiceTuple(t *testing.T) {
+	store, _ := newFakeEventStore()
+	// On the client side, the HTTP messages are observed before DNAT.
+	serviceTuple := clientTuple
+	serviceTuple.DestinationAddress = serviceIP
+	serviceTuple.DestinationPort = 8080
+	store.addHTTPRequest("pod1-eth0", serviceTuple, 1000, &httpRequest{method: "GET", url: "/", protocol: "HTTP/1.1"})
+	store.addHTTPResponse("pod1-eth0", serviceTuple, 5000, 404)
+
+	conn := &flowexporter.Connection{
+		FlowKey:                    clientTuple,
+		DestinationServiceAddress:  serviceIP,
+		DestinationServicePort:     8080,
+		DestinationServicePortName: "ns1/svc1:http",
+	}
+	store.FillConnection(conn)
+	assert.Equal(t, AppProtocolHTTP, conn.AppProtocolName)
+	assert.Equal(t, map[int32]httpTransaction{
+		0: {URL: "/", HTTPMethod: "GET", Protocol: "HTTP/1.1", Status: 404},
+	}, decodeHTTPVals(t, conn.HttpVals))
+
+	// Connections which are not to a Service do not use the Service tuple.
+	conn = &flowexporter.Connection{FlowKey: clientTuple, DestinationServiceAddress: serviceIP, DestinationServicePort: 8080}
+	store.FillConnection(conn)
+	assert.Empty(t, conn.AppProtocolName)
+}
+
+func TestDeleteStaleEvents(t *testing.T) {
+	store, fakeClock := newFakeEventStore()
+	otherTuple := clientTuple
+	otherTuple.SourcePort = 40001
+	store.addHTTPRequest("pod1-eth0", clientTuple, 1000, &httpRequest{method: "GET", url: "/", protocol: "HTTP/1.1"})
+	store.addHTTPRequest("pod1-eth0", otherTuple, 1000, &httpRequest{method: "GET", url: "/", protocol: "HTTP/1.1"})
+
+	fakeClock.Step(testStaleConnectionTimeout / 2)
+	// Exporting the connection keeps its events.
+	store.FillConnection(&flowexporter.Connection{FlowKey: clientTuple})
+	fakeClock.Step(testStaleConnectionTimeout / 2)
+	store.deleteStaleEvents()
+	assert.Len(t, store.events, 1)
+	assert.Contains(t, store.events, tupleToKey(clientTuple))
+
+	fakeClock.Step(testStaleConnectionTimeout)
+	store.deleteStaleEvents()
+	assert.Empty(t, store.events)
+}
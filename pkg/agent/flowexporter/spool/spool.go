@@ -0,0 +1,396 @@
+// Copyright 2022 Antrea Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package spool implements a bounded on-disk buffer for the flow records which
+// cannot be sent by the Flow Exporter while no flow collector is reachable.
+//
+// Records are appended to segment files in the spool directory. Each record is
+// stored with its length and its CRC32 checksum, so that a record partially
+// written when the Agent was killed is detected and discarded when the spool is
+// opened again. A segment file is synced to disk when it is closed, i.e. when it
+// is full or when the spool is closed, so the records of the segment being
+// written may be lost if the Node crashes. Records are replayed in the order in
+// which they were appended, and a segment file is deleted once all its records
+// have been replayed. As the replay position within a segment is not persisted,
+// some records may be sent twice if the Agent is restarted while replaying a
+// segment.
+package spool
+
+import (
+	"encoding/binary"
+	"encoding/json"
+	"fmt"
+	"hash/crc32"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+
+	"k8s.io/klog/v2"
+
+	"antrea.io/antrea/pkg/agent/flowexporter"
+	"antrea.io/antrea/pkg/agent/metrics"
+)
+
+const (
+	segmentFileSuffix = ".seg"
+	// recordHeaderSize is the size of the header of each record, which
+	// includes the length of the record and its CRC32 checksum.
+	recordHeaderSize = 8
+	// numSegments is the number of segments the spool is split into. When
+	// the spool is full, the oldest segment is dropped to make room for new
+	// records.
+	numSegments = 8
+)
+
+type segment struct {
+	id   uint64
+	path string
+	// size is the number of bytes of the records which have not been
+	// replayed yet.
+	size       int64
+	numRecords int
+	// readOffset is the offset of the first record which has not been
+	// replayed yet.
+	readOffset int64
+	// file is only set for the last segment, to which records are appended.
+	file *os.File
+}
+
+// Spool is a bounded on-disk FIFO of flow records. It is not safe for
+// concurrent use.
+type Spool struct {
+	dir         string
+	maxSize     int64
+	segmentSize int64
+	segments    []*segment
+	size        int64
+	numRecords  int
+	nextID      uint64
+}
+
+// New opens the spool stored in dir, creating the directory if needed. The
+// records stored by a previous instance of the spool are kept, and records
+// which were not fully written are discarded. maxSize is the maximum number of
+// bytes used by the spool.
+func New(dir string, maxSize int64) (*Spool, error) {
+	if maxSize <= 0 {
+		return nil, fmt.Errorf("invalid spool size %d", maxSize)
+	}
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		return nil, fmt.Errorf("error when creating spool directory %s: %v", dir, err)
+	}
+	s := &Spool{
+		dir:         dir,
+		maxSize:     maxSize,
+		segmentSize: maxSize / numSegments,
+	}
+	if s.segmentSize < recordHeaderSize {
+		s.segmentSize = maxSize
+	}
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, fmt.Errorf("error when reading spool directory %s: %v", dir, err)
+	}
+	var ids []uint64
+	for _, entry := range entries {
+		name := entry.Name()
+		if entry.IsDir() || !strings.HasSuffix(name, segmentFileSuffix) {
+			continue
+		}
+		id, err := strconv.ParseUint(strings.TrimSuffix(name, segmentFileSuffix), 10, 64)
+		if err != nil {
+			klog.InfoS("Ignoring unexpected file in spool directory", "file", name)
+			continue
+		}
+		ids = append(ids, id)
+	}
+	sort.Slice(ids, func(i, j int) bool { return ids[i] < ids[j] })
+	for _, id := range ids {
+		seg, err := s.loadSegment(id)
+		if err != nil {
+			return nil, err
+		}
+		s.nextID = id + 1
+		if seg.numRecords == 0 {
+			os.Remove(seg.path)
+			continue
+		}
+		s.segments = append(s.segments, seg)
+		s.size += seg.size
+		s.numRecords += seg.numRecords
+	}
+	s.updateMetrics()
+	if s.numRecords > 0 {
+		klog.InfoS("Loaded flow records from spool", "dir", dir, "records", s.numRecords, "bytes", s.size)
+	}
+	return s, nil
+}
+
+func (s *Spool) segmentPath(id uint64) string {
+	return filepath.Join(s.dir, fmt.Sprintf("%020d%s", id, segmentFileSuffix))
+}
+
+// loadSegment counts the valid records in a segment file, and truncates the
+// file after the last valid record. New records are never appended to a segment
+// loaded from disk.
+func (s *Spool) loadSegment(id uint64) (*segment, error) {
+	seg := &segment{id: id, path: s.segmentPath(id)}
+	f, err := os.Open(seg.path)
+	if err != nil {
+		return nil, fmt.Errorf("error when opening spool segment %s: %v", seg.path, err)
+	}
+	defer f.Close()
+	for {
+		payload, err := readRecord(f)
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			klog.InfoS("Discarding incomplete records in spool segment", "file", seg.path, "offset", seg.size, "err", err)
+			if err := os.Truncate(seg.path, seg.size); err != nil {
+				return nil, fmt.Errorf("error when truncating spool segment %s: %v", seg.path, err)
+			}
+			break
+		}
+		seg.size += int64(recordHeaderSize + len(payload))
+		seg.numRecords++
+	}
+	return seg, nil
+}
+
+// readRecord reads the next record from r. It returns io.EOF if there is no
+// record left, and another error if the record is incomplete or corrupted.
+func readRecord(r io.Reader) ([]byte, error) {
+	var header [recordHeaderSize]byte
+	if _, err := io.ReadFull(r, header[:]); err != nil {
+		if err == io.EOF {
+			return nil, io.EOF
+		}
+		return nil, fmt.Errorf("incomplete record header: %v", err)
+	}
+	length := binary.BigEndian.Uint32(header[0:4])
+	checksum := binary.BigEndian.Uint32(header[4:8])
+	payload := make([]byte, length)
+	if _, err := io.ReadFull(r, payload); err != nil {
+		return nil, fmt.Errorf("incomplete record: %v", err)
+	}
+	if crc32.ChecksumIEEE(payload) != checksum {
+		return nil, fmt.Errorf("record checksum mismatch")
+	}
+	return payload, nil
+}
+
+// Append adds the record of a connection at the end of the spool. If the spool
+// is full, the oldest records are dropped to make room for it.
+func (s *Spool) Append(conn *flowexporter.Connection) error {
+	payload, err := json.Marshal(conn)
+	if err != nil {
+		return fmt.Errorf("error when encoding flow record: %v", err)
+	}
+	recordSize := int64(recordHeaderSize + len(payload))
+	if recordSize > s.segmentSize {
+		metrics.FlowExporterSpoolDroppedRecords.Inc()
+		return fmt.Errorf("flow record of %d bytes is larger than the spool segment size", recordSize)
+	}
+	for s.size+recordSize > s.maxSize && len(s.segments) > 0 {
+		s.dropOldestSegment()
+	}
+	seg, err := s.writableSegment(recordSize)
+	if err != nil {
+		return err
+	}
+	record := make([]byte, recordSize)
+	binary.BigEndian.PutUint32(record[0:4], uint32(len(payload)))
+	binary.BigEndian.PutUint32(record[4:8], crc32.ChecksumIEEE(payload))
+	copy(record[recordHeaderSize:], payload)
+	// The record is written with a single call, so that it is either fully
+	// written or detected as incomplete if the Agent is killed.
+	if _, err := seg.file.Write(record); err != nil {
+		// The segment may end with an incomplete record now, so no more
+		// records are appended to it.
+		s.closeSegment(seg)
+		return fmt.Errorf("error when writing to spool segment %s: %v", seg.path, err)
+	}
+	seg.size += recordSize
+	seg.numRecords++
+	s.size += recordSize
+	s.numRecords++
+	s.updateMetrics()
+	return nil
+}
+
+// writableSegment returns the segment to which a record of the provided size
+// can be appended, creating a new segment if needed.
+func (s *Spool) writableSegment(recordSize int64) (*segment, error) {
+	if len(s.segments) > 0 {
+		last := s.segments[len(s.segments)-1]
+		if last.file != nil {
+			if last.readOffset+last.size+recordSize <= s.segmentSize {
+				return last, nil
+			}
+			s.closeSegment(last)
+		}
+	}
+	seg := &segment{id: s.nextID, path: s.segmentPath(s.nextID)}
+	f, err := os.OpenFile(seg.path, os.O_CREATE|os.O_EXCL|os.O_WRONLY|os.O_APPEND, 0600)
+	if err != nil {
+		return nil, fmt.Errorf("error when creating spool segment %s: %v", seg.path, err)
+	}
+	s.nextID++
+	seg.file = f
+	s.syncDir()
+	s.segments = append(s.segments, seg)
+	return seg, nil
+}
+
+// closeSegment syncs the segment file to disk and closes it, so that its
+// records are not lost if the Node crashes afterwards.
+func (s *Spool) closeSegment(seg *segment) {
+	if seg.file != nil {
+		if err := seg.file.Sync(); err != nil {
+			klog.ErrorS(err, "Error when syncing spool segment", "file", seg.path)
+		}
+		seg.file.Close()
+		seg.file = nil
+	}
+}
+
+// syncDir syncs the spool directory to disk, so that a new segment file is not
+// lost if the Node crashes after the segment is synced.
+func (s *Spool) syncDir() {
+	d, err := os.Open(s.dir)
+	if err != nil {
+		klog.ErrorS(err, "Error when opening spool directory", "dir", s.dir)
+		return
+	}
+	defer d.Close()
+	if err := d.Sync(); err != nil {
+		klog.ErrorS(err, "Error when syncing spool directory", "dir", s.dir)
+	}
+}
+
+// removeSegment deletes the first segment, whose records have all been
+// replayed or dropped.
+func (s *Spool) removeSegment() {
+	seg := s.segments[0]
+	// There is no need to sync the file to disk before removing it.
+	if seg.file != nil {
+		seg.file.Close()
+		seg.file = nil
+	}
+	if err := os.Remove(seg.path); err != nil && !os.IsNotExist(err) {
+		klog.ErrorS(err, "Error when removing spool segment", "file", seg.path)
+	}
+	s.segments = s.segments[1:]
+}
+
+func (s *Spool) dropOldestSegment() {
+	seg := s.segments[0]
+	klog.InfoS("Spool is full, dropping oldest flow records", "dir", s.dir, "records", seg.numRecords)
+	metrics.FlowExporterSpoolDroppedRecords.Add(float64(seg.numRecords))
+	s.size -= seg.size
+	s.numRecords -= seg.numRecords
+	s.removeSegment()
+	s.updateMetrics()
+}
+
+// Replay calls sendFn for the records in the spool, in the order in which they
+// were appended. A record is removed from the spool after sendFn succeeds for
+// it. Replay stops at the first error returned by sendFn, and returns it with
+// the number of records which were replayed successfully.
+func (s *Spool) Replay(sendFn func(conn *flowexporter.Connection) error) (int, error) {
+	count := 0
+	for len(s.segments) > 0 {
+		seg := s.segments[0]
+		n, err := s.replaySegment(seg, sendFn)
+		count += n
+		if err != nil {
+			return count, err
+		}
+		s.removeSegment()
+	}
+	return count, nil
+}
+
+func (s *Spool) replaySegment(seg *segment, sendFn func(conn *flowexporter.Connection) error) (int, error) {
+	f, err := os.Open(seg.path)
+	if err != nil {
+		return 0, fmt.Errorf("error when opening spool segment %s: %v", seg.path, err)
+	}
+	defer f.Close()
+	if _, err := f.Seek(seg.readOffset, io.SeekStart); err != nil {
+		return 0, fmt.Errorf("error when reading spool segment %s: %v", seg.path, err)
+	}
+	count := 0
+	defer s.updateMetrics()
+	for seg.numRecords > 0 {
+		payload, err := readRecord(f)
+		if err != nil {
+			// The segment was truncated or corrupted after it was written,
+			// the remaining records are dropped.
+			klog.ErrorS(err, "Error when reading spool segment, dropping remaining flow records", "file", seg.path, "records", seg.numRecords)
+			metrics.FlowExporterSpoolDroppedRecords.Add(float64(seg.numRecords))
+			s.consume(seg, seg.size, seg.numRecords)
+			return count, nil
+		}
+		recordSize := int64(recordHeaderSize + len(payload))
+		conn := &flowexporter.Connection{}
+		if err := json.Unmarshal(payload, conn); err != nil {
+			klog.ErrorS(err, "Error when decoding flow record from spool, dropping it", "file", seg.path)
+			metrics.FlowExporterSpoolDroppedRecords.Inc()
+			s.consume(seg, recordSize, 1)
+			continue
+		}
+		if err := sendFn(conn); err != nil {
+			return count, err
+		}
+		s.consume(seg, recordSize, 1)
+		count++
+	}
+	return count, nil
+}
+
+func (s *Spool) consume(seg *segment, size int64, numRecords int) {
+	seg.readOffset += size
+	seg.size -= size
+	seg.numRecords -= numRecords
+	s.size -= size
+	s.numRecords -= numRecords
+}
+
+// Len returns the number of records in the spool.
+func (s *Spool) Len() int {
+	return s.numRecords
+}
+
+// Size returns the number of bytes used by the records in the spool.
+func (s *Spool) Size() int64 {
+	return s.size
+}
+
+// Close closes the segment the records are appended to. The records are kept
+// on disk, to be replayed by the next instance of the spool.
+func (s *Spool) Close() {
+	for _, seg := range s.segments {
+		s.closeSegment(seg)
+	}
+}
+
+func (s *Spool) updateMetrics() {
+	metrics.FlowExporterSpoolRecords.Set(float64(s.numRecords))
+	metrics.FlowExporterSpoolSizeBytes.Set(float64(s.size))
+}
@@ -0,0 +1,152 @@
+// Copyright 2022 Antrea Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package spool
+
+import (
+	"fmt"
+	"net"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"antrea.io/antrea/pkg/agent/flowexporter"
+)
+
+func newConnection(id uint32) *flowexporter.Connection {
+	return &flowexporter.Connection{
+		ID:        id,
+		StartTime: time.Unix(1660000000, 0).UTC(),
+		StopTime:  time.Unix(1660000060, 0).UTC(),
+		FlowKey: flowexporter.Tuple{
+			SourceAddress:      net.ParseIP("10.10.0.1"),
+			DestinationAddress: net.ParseIP("10.10.1.1"),
+			Protocol:           6,
+			SourcePort:         uint16(30000 + id),
+			DestinationPort:    80,
+		},
+		OriginalPackets:    10,
+		OriginalBytes:      1000,
+		SourcePodName:      "pod1",
+		SourcePodNamespace: "ns1",
+		TCPState:           "ESTABLISHED",
+	}
+}
+
+// replayAll replays all the records in the spool and returns their IDs.
+func replayAll(t *testing.T, s *Spool) []uint32 {
+	var ids []uint32
+	_, err := s.Replay(func(conn *flowexporter.Connection) error {
+		ids = append(ids, conn.ID)
+		return nil
+	})
+	require.NoError(t, err)
+	return ids
+}
+
+func TestSpoolAppendAndReplay(t *testing.T) {
+	s, err := New(t.TempDir(), 1<<20)
+	require.NoError(t, err)
+	defer s.Close()
+
+	for i := uint32(1); i <= 3; i++ {
+		require.NoError(t, s.Append(newConnection(i)))
+	}
+	assert.Equal(t, 3, s.Len())
+	assert.Positive(t, s.Size())
+
+	// Replay stops at the first error, and the records which were not sent
+	// are kept in the spool.
+	var replayed []*flowexporter.Connection
+	count, err := s.Replay(func(conn *flowexporter.Connection) error {
+		if len(replayed) == 2 {
+			return fmt.Errorf("collector unavailable")
+		}
+		replayed = append(replayed, conn)
+		return nil
+	})
+	assert.Error(t, err)
+	assert.Equal(t, 2, count)
+	assert.Equal(t, newConnection(1), replayed[0])
+	assert.Equal(t, newConnection(2), replayed[1])
+	assert.Equal(t, 1, s.Len())
+
+	require.NoError(t, s.Append(newConnection(4)))
+	assert.Equal(t, []uint32{3, 4}, replayAll(t, s))
+	assert.Zero(t, s.Len())
+	assert.Zero(t, s.Size())
+}
+
+func TestSpoolMaxSize(t *testing.T) {
+	record := newConnection(10)
+	s, err := New(t.TempDir(), 1<<20)
+	require.NoError(t, err)
+	require.NoError(t, s.Append(record))
+	recordSize := s.Size()
+	s.Close()
+
+	// Each segment can store 2 records.
+	s, err = New(t.TempDir(), recordSize*2*numSegments)
+	require.NoError(t, err)
+	defer s.Close()
+	for i := uint32(1); i <= 2*numSegments; i++ {
+		require.NoError(t, s.Append(newConnection(i)))
+	}
+	assert.Equal(t, 2*numSegments, s.Len())
+	// The oldest segment is dropped to make room for new records.
+	require.NoError(t, s.Append(newConnection(2*numSegments+1)))
+	assert.Equal(t, 2*numSegments-1, s.Len())
+	assert.LessOrEqual(t, s.Size(), recordSize*2*numSegments)
+	ids := replayAll(t, s)
+	require.Len(t, ids, 2*numSegments-1)
+	assert.Equal(t, uint32(3), ids[0])
+	assert.Equal(t, uint32(2*numSegments+1), ids[len(ids)-1])
+}
+
+func TestSpoolReopen(t *testing.T) {
+	dir := t.TempDir()
+	s, err := New(dir, 1<<20)
+	require.NoError(t, err)
+	for i := uint32(1); i <= 3; i++ {
+		require.NoError(t, s.Append(newConnection(i)))
+	}
+	s.Close()
+
+	// Simulate a record which was partially written when the Agent was killed.
+	segments, err := filepath.Glob(filepath.Join(dir, "*"+segmentFileSuffix))
+	require.NoError(t, err)
+	require.Len(t, segments, 1)
+	f, err := os.OpenFile(segments[0], os.O_WRONLY|os.O_APPEND, 0600)
+	require.NoError(t, err)
+	_, err = f.Write([]byte{0, 0, 1, 0, 1, 2, 3, 4, '{'})
+	require.NoError(t, err)
+	f.Close()
+
+	s, err = New(dir, 1<<20)
+	require.NoError(t, err)
+	defer s.Close()
+	assert.Equal(t, 3, s.Len())
+	// New records are appended after the records loaded from disk.
+	require.NoError(t, s.Append(newConnection(4)))
+	assert.Equal(t, []uint32{1, 2, 3, 4}, replayAll(t, s))
+
+	// Replayed segments are deleted.
+	segments, err = filepath.Glob(filepath.Join(dir, "*"+segmentFileSuffix))
+	require.NoError(t, err)
+	assert.Empty(t, segments)
+}
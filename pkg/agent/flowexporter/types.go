@@ -78,6 +78,13 @@ type Connection struct {
 	PrevReversePackets, PrevReverseBytes uint64
 	TCPState                             string
 	PrevTCPState                         string
+	// Fields specific to the L7 flow export
+	AppProtocolName string
+	// HttpVals are the HTTP transactions of the connection, encoded as a
+	// JSON map from the transaction IDs to the transactions.
+	HttpVals        string
+	DNSQueryName    string
+	DNSResponseCode uint8
 }
 
 type ItemToExpire struct {
@@ -96,4 +103,9 @@ type FlowExporterOptions struct {
 	StaleConnectionTimeout time.Duration
 	PollInterval           time.Duration
 	ConnectUplinkToBridge  bool
+	// SpoolDir is the directory of the on-disk spool of flow records.
+	SpoolDir string
+	// SpoolSize is the maximum size in bytes of the on-disk spool of flow
+	// records. The spool is disabled if it is 0.
+	SpoolSize int64
 }
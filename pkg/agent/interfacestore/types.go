@@ -45,6 +45,7 @@ const (
 	AntreaUplink           = "uplink"
 	AntreaHost             = "host"
 	AntreaTrafficControl   = "traffic-control"
+	AntreaPacketCapture    = "packet-capture"
 	AntreaUnset            = ""
 )
 
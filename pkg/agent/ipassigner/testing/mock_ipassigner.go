@@ -20,7 +20,7 @@
 package testing
 
 import (
-	gomock "github.com/golang/mock/gomock"
+	gomock "go.uber.org/mock/gomock"
 	sets "k8s.io/apimachinery/pkg/util/sets"
 	reflect "reflect"
 )
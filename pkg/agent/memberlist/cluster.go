@@ -86,6 +86,8 @@ type Interface interface {
 	SelectNodeForIP(ip, externalIPPool string, filters ...func(string) bool) (string, error)
 	AliveNodes() sets.String
 	AddClusterEventHandler(handler ClusterNodeEventHandler)
+	AddIPsGetter(getter IPsGetter)
+	NotifyIPsChanged(externalIPPool string)
 }
 
 // Cluster implements ClusterInterface.
@@ -97,8 +99,13 @@ type Cluster struct {
 	mList *memberlist.Memberlist
 	// consistentHash hold the consistentHashMap, when a Node join cluster, use method Add() to add a key to the hash.
 	// when a Node leave the cluster, the consistentHashMap should be update.
-	consistentHashMap     map[string]*consistenthash.Map
+	consistentHashMap map[string]*consistenthash.Map
+	// nodeSelectionMap holds the nodeSelection of the ExternalIPPools which don't use the ConsistentHash strategy.
+	// It's protected by consistentHashRWMutex as well.
+	nodeSelectionMap      map[string]*nodeSelection
 	consistentHashRWMutex sync.RWMutex
+	// ipsGetters return the IPs allocated from an ExternalIPPool, which are used by the LeastLoaded strategy.
+	ipsGetters []IPsGetter
 	// nodeEventsCh, the Node join/leave events will be notified via it.
 	nodeEventsCh chan memberlist.NodeEvent
 
@@ -137,6 +144,7 @@ func NewCluster(
 		bindPort:                        clusterBindPort,
 		nodeName:                        nodeName,
 		consistentHashMap:               make(map[string]*consistenthash.Map),
+		nodeSelectionMap:                make(map[string]*nodeSelection),
 		nodeEventsCh:                    nodeEventCh,
 		nodeInformer:                    nodeInformer,
 		nodeLister:                      nodeInformer.Lister(),
@@ -177,7 +185,8 @@ func NewCluster(
 			UpdateFunc: func(oldObj, newObj interface{}) {
 				oldExternalIPPool := oldObj.(*v1alpha2.ExternalIPPool)
 				curExternalIPPool := newObj.(*v1alpha2.ExternalIPPool)
-				if !reflect.DeepEqual(oldExternalIPPool.Spec.NodeSelector, curExternalIPPool.Spec.NodeSelector) {
+				if !reflect.DeepEqual(oldExternalIPPool.Spec.NodeSelector, curExternalIPPool.Spec.NodeSelector) ||
+					!reflect.DeepEqual(oldExternalIPPool.Spec.NodeSelection, curExternalIPPool.Spec.NodeSelection) {
 					c.enqueueExternalIPPool(newObj)
 				}
 			},
@@ -395,6 +404,7 @@ func (c *Cluster) syncConsistentHash(eipName string) error {
 			c.consistentHashRWMutex.Lock()
 			defer c.consistentHashRWMutex.Unlock()
 			delete(c.consistentHashMap, eipName)
+			delete(c.nodeSelectionMap, eipName)
 			return nil
 		}
 		return err
@@ -424,6 +434,11 @@ func (c *Cluster) syncConsistentHash(eipName string) error {
 		c.consistentHashRWMutex.Lock()
 		defer c.consistentHashRWMutex.Unlock()
 		c.consistentHashMap[eip.Name] = consistentHashMap
+		if selection := newNodeSelection(eip, aliveAndMatchedNodes); selection != nil {
+			c.nodeSelectionMap[eip.Name] = selection
+		} else {
+			delete(c.nodeSelectionMap, eip.Name)
+		}
 		c.notify(eip.Name)
 		return nil
 	}
@@ -476,7 +491,8 @@ func (c *Cluster) AliveNodes() sets.String {
 // ShouldSelectIP returns true if the local Node selected as the owner Node of the IP in the specific
 // ExternalIPPool. The local Node in the cluster holds the same consistent hash ring for each ExternalIPPool,
 // consistentHash.Get gets the closest item (Node name) in the hash to the provided key (IP), if the name of
-// the local Node is equal to the name of the selected Node, returns true.
+// the local Node is equal to the name of the selected Node, returns true. If the ExternalIPPool uses another
+// node selection strategy, the owner Node is selected by the strategy, with the hash ring breaking ties.
 func (c *Cluster) ShouldSelectIP(ip, externalIPPool string, filters ...func(string) bool) (bool, error) {
 	if externalIPPool == "" || ip == "" {
 		return false, nil
@@ -487,14 +503,15 @@ func (c *Cluster) ShouldSelectIP(ip, externalIPPool string, filters ...func(stri
 	if !ok {
 		return false, fmt.Errorf("local Node consistentHashMap has not synced, ExternalIPPool %s", externalIPPool)
 	}
-	node := consistentHash.GetWithFilters(ip, filters...)
+	node := c.selectNode(ip, externalIPPool, consistentHash, filters...)
 	if node == "" && len(filters) > 0 {
 		return false, ErrNoNodeAvailable
 	}
 	return node == c.nodeName, nil
 }
 
-// SelectNodeForIP returns the closest item (Node name) in the hash to the provided key (IP) and ExternalIPPool.
+// SelectNodeForIP returns the closest item (Node name) in the hash to the provided key (IP) and ExternalIPPool, or
+// the Node selected by the node selection strategy of the ExternalIPPool.
 func (c *Cluster) SelectNodeForIP(ip, externalIPPool string, filters ...func(string) bool) (string, error) {
 	if externalIPPool == "" || ip == "" {
 		return "", fmt.Errorf("IP and externalIPPool cannot be empty")
@@ -505,13 +522,42 @@ func (c *Cluster) SelectNodeForIP(ip, externalIPPool string, filters ...func(str
 	if !ok {
 		return "", fmt.Errorf("local Node consistentHashMap has not synced, ExternalIPPool %s", externalIPPool)
 	}
-	node := consistentHash.GetWithFilters(ip, filters...)
+	node := c.selectNode(ip, externalIPPool, consistentHash, filters...)
 	if node == "" {
 		return "", ErrNoNodeAvailable
 	}
 	return node, nil
 }
 
+// selectNode must be called with consistentHashRWMutex held.
+func (c *Cluster) selectNode(ip, externalIPPool string, consistentHash *consistenthash.Map, filters ...func(string) bool) string {
+	poolIPs := func() []PoolIP {
+		var ips []PoolIP
+		for _, getter := range c.ipsGetters {
+			ips = append(ips, getter(externalIPPool)...)
+		}
+		return ips
+	}
+	return selectNode(ip, consistentHash, c.nodeSelectionMap[externalIPPool], poolIPs, filters...)
+}
+
+// AddIPsGetter adds an IPsGetter, which provides the IPs allocated from ExternalIPPools to the node selection
+// strategies depending on them. It must be called before Run.
+func (c *Cluster) AddIPsGetter(getter IPsGetter) {
+	c.ipsGetters = append(c.ipsGetters, getter)
+}
+
+// NotifyIPsChanged should be called when the IPs allocated from the ExternalIPPool change. If the owner Nodes of the
+// IPs depend on the IPs of the ExternalIPPool, the clusterNodeEventHandlers will run to re-evaluate them.
+func (c *Cluster) NotifyIPsChanged(externalIPPool string) {
+	c.consistentHashRWMutex.RLock()
+	defer c.consistentHashRWMutex.RUnlock()
+	if selection, ok := c.nodeSelectionMap[externalIPPool]; ok && selection.strategy == v1alpha2.NodeSelectionStrategyLeastLoaded {
+		selection.resetAssignments()
+		c.notify(externalIPPool)
+	}
+}
+
 func (c *Cluster) notify(objName string) {
 	for _, handler := range c.clusterNodeEventHandlers {
 		handler(objName)
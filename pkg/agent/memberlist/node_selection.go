@@ -0,0 +1,217 @@
+// Copyright 2022 Antrea Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package memberlist
+
+import (
+	"bytes"
+	"net"
+	"sort"
+	"sync"
+
+	"k8s.io/apimachinery/pkg/util/sets"
+
+	"antrea.io/antrea/pkg/agent/consistenthash"
+	"antrea.io/antrea/pkg/apis/crd/v1alpha2"
+)
+
+// PoolIP is an IP allocated from an ExternalIPPool which needs an owner Node.
+type PoolIP struct {
+	IP string
+	// Filters are the filters the owner Node of the IP must pass, e.g. the Nodes having endpoints of a Service.
+	Filters []func(string) bool
+}
+
+// IPsGetter returns the IPs allocated from the provided ExternalIPPool which need an owner Node.
+type IPsGetter func(externalIPPool string) []PoolIP
+
+// nodeSelection holds the state needed to select the owner Node of an IP with a strategy other than
+// ConsistentHash. All inputs are derived from the alive Nodes and the K8s API, so that every Node computes the same
+// owner for an IP.
+type nodeSelection struct {
+	strategy v1alpha2.NodeSelectionStrategy
+	// nodes are the alive Nodes matched by the ExternalIPPool, sorted by name.
+	nodes []string
+	// preferredNodes are the alive Nodes matched by the ExternalIPPool which are in its preference list, from the most
+	// preferred to the least preferred.
+	preferredNodes []string
+
+	// assignments caches the owner Nodes of the IPs of the ExternalIPPool with the LeastLoaded strategy, sorted by IP.
+	// It's computed on demand and reset when the IPs change. assignmentsMutex protects it as selectNode is called
+	// with consistentHashRWMutex read-locked only.
+	assignments      []ipAssignment
+	assignmentsValid bool
+	assignmentsMutex sync.Mutex
+}
+
+type ipAssignment struct {
+	ip   net.IP
+	node string
+}
+
+// newNodeSelection returns the nodeSelection of an ExternalIPPool given its alive and matched Nodes. It returns nil
+// if the ExternalIPPool uses the ConsistentHash strategy.
+func newNodeSelection(eip *v1alpha2.ExternalIPPool, aliveAndMatchedNodes []string) *nodeSelection {
+	if eip.Spec.NodeSelection == nil {
+		return nil
+	}
+	nodes := sets.NewString(aliveAndMatchedNodes...)
+	switch eip.Spec.NodeSelection.Strategy {
+	case v1alpha2.NodeSelectionStrategyLeastLoaded:
+		return &nodeSelection{
+			strategy: v1alpha2.NodeSelectionStrategyLeastLoaded,
+			nodes:    nodes.List(),
+		}
+	case v1alpha2.NodeSelectionStrategyPreferenceList:
+		var preferredNodes []string
+		for _, node := range eip.Spec.NodeSelection.PreferredNodes {
+			if nodes.Has(node) {
+				preferredNodes = append(preferredNodes, node)
+			}
+		}
+		return &nodeSelection{
+			strategy:       v1alpha2.NodeSelectionStrategyPreferenceList,
+			preferredNodes: preferredNodes,
+		}
+	}
+	return nil
+}
+
+// resetAssignments drops the cached owner Nodes of the IPs, which must be called when the IPs change.
+func (s *nodeSelection) resetAssignments() {
+	s.assignmentsMutex.Lock()
+	defer s.assignmentsMutex.Unlock()
+	s.assignments = nil
+	s.assignmentsValid = false
+}
+
+func passFilters(node string, filters []func(string) bool) bool {
+	for _, f := range filters {
+		if !f(node) {
+			return false
+		}
+	}
+	return true
+}
+
+// selectNode returns the owner Node of the IP which passes all filters, or an empty string if no Node is available.
+// poolIPs is only called with the LeastLoaded strategy.
+func selectNode(ip string, consistentHash *consistenthash.Map, selection *nodeSelection, poolIPs func() []PoolIP, filters ...func(string) bool) string {
+	if selection != nil {
+		switch selection.strategy {
+		case v1alpha2.NodeSelectionStrategyPreferenceList:
+			for _, node := range selection.preferredNodes {
+				if passFilters(node, filters) {
+					return node
+				}
+			}
+		case v1alpha2.NodeSelectionStrategyLeastLoaded:
+			if parsedIP := net.ParseIP(ip); parsedIP != nil {
+				return selection.selectLeastLoadedNode(parsedIP, consistentHash, poolIPs, filters...)
+			}
+		}
+	}
+	return consistentHash.GetWithFilters(ip, filters...)
+}
+
+// selectLeastLoadedNode returns the owner Node of the provided IP with the LeastLoaded strategy: the IPs of the pool
+// are assigned in ascending order, each to the Node owning the fewest IPs so far among the Nodes passing its filters,
+// with ties broken via consistent hashing. Only the IPs ordered before the provided IP affect its owner Node, so
+// adding an IP to the pool doesn't move the IPs ordered before it.
+func (s *nodeSelection) selectLeastLoadedNode(ip net.IP, consistentHash *consistenthash.Map, poolIPs func() []PoolIP, filters ...func(string) bool) string {
+	s.assignmentsMutex.Lock()
+	defer s.assignmentsMutex.Unlock()
+	if !s.assignmentsValid {
+		s.assignments = assignLeastLoadedNodes(consistentHash, s.nodes, poolIPs())
+		s.assignmentsValid = true
+	}
+	load := make(map[string]int, len(s.nodes))
+	for _, assignment := range s.assignments {
+		if compareIPs(assignment.ip, ip) >= 0 {
+			break
+		}
+		if assignment.node != "" {
+			load[assignment.node]++
+		}
+	}
+	// The owner Node of the provided IP is computed again as its filters may differ from the ones of the pool IP.
+	return leastLoadedNode(ip.String(), consistentHash, s.nodes, load, filters...)
+}
+
+// assignLeastLoadedNodes assigns the IPs of the pool to Nodes with the LeastLoaded strategy and returns the
+// assignments sorted by IP. The filters of an IP returned several times are combined, so that the result doesn't
+// depend on the order of poolIPs.
+func assignLeastLoadedNodes(consistentHash *consistenthash.Map, nodes []string, poolIPs []PoolIP) []ipAssignment {
+	filtersByIP := make(map[string][]func(string) bool, len(poolIPs))
+	ips := make([]net.IP, 0, len(poolIPs))
+	for _, poolIP := range poolIPs {
+		ip := net.ParseIP(poolIP.IP)
+		if ip == nil {
+			continue
+		}
+		key := ip.String()
+		if _, exists := filtersByIP[key]; !exists {
+			ips = append(ips, ip)
+		}
+		filtersByIP[key] = append(filtersByIP[key], poolIP.Filters...)
+	}
+	sort.Slice(ips, func(i, j int) bool {
+		return compareIPs(ips[i], ips[j]) < 0
+	})
+	load := make(map[string]int, len(nodes))
+	assignments := make([]ipAssignment, 0, len(ips))
+	for _, ip := range ips {
+		key := ip.String()
+		node := leastLoadedNode(key, consistentHash, nodes, load, filtersByIP[key]...)
+		if node != "" {
+			load[node]++
+		}
+		assignments = append(assignments, ipAssignment{ip: ip, node: node})
+	}
+	return assignments
+}
+
+// leastLoadedNode returns the Node owning the fewest IPs among the Nodes passing all filters, using the consistent
+// hash of the key to break ties, or an empty string if no Node passes the filters.
+func leastLoadedNode(key string, consistentHash *consistenthash.Map, nodes []string, load map[string]int, filters ...func(string) bool) string {
+	minLoad := -1
+	for _, node := range nodes {
+		if !passFilters(node, filters) {
+			continue
+		}
+		if minLoad == -1 || load[node] < minLoad {
+			minLoad = load[node]
+		}
+	}
+	if minLoad == -1 {
+		return ""
+	}
+	leastLoadedFilters := make([]func(string) bool, 0, len(filters)+1)
+	leastLoadedFilters = append(leastLoadedFilters, filters...)
+	leastLoadedFilters = append(leastLoadedFilters, func(node string) bool {
+		return load[node] == minLoad
+	})
+	return consistentHash.GetWithFilters(key, leastLoadedFilters...)
+}
+
+// compareIPs compares IPs numerically, with IPv4 addresses ordered before IPv6 addresses.
+func compareIPs(a, b net.IP) int {
+	if a4, b4 := a.To4(), b.To4(); (a4 == nil) != (b4 == nil) {
+		if a4 != nil {
+			return -1
+		}
+		return 1
+	}
+	return bytes.Compare(a.To16(), b.To16())
+}
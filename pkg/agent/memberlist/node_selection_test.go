@@ -0,0 +1,238 @@
+// Copyright 2022 Antrea Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package memberlist
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	crdv1a2 "antrea.io/antrea/pkg/apis/crd/v1alpha2"
+)
+
+func genNodeSelectionCluster(localNodeName, eipName string, nodes []string, spec *crdv1a2.NodeSelection, poolIPs []string) *Cluster {
+	cluster := genLocalNodeCluster(localNodeName, eipName, nodes)
+	eip := &crdv1a2.ExternalIPPool{
+		ObjectMeta: metav1.ObjectMeta{Name: eipName},
+		Spec:       crdv1a2.ExternalIPPoolSpec{NodeSelection: spec},
+	}
+	cluster.nodeSelectionMap = map[string]*nodeSelection{}
+	if selection := newNodeSelection(eip, nodes); selection != nil {
+		cluster.nodeSelectionMap[eipName] = selection
+	}
+	cluster.AddIPsGetter(func(externalIPPool string) []PoolIP {
+		if externalIPPool != eipName {
+			return nil
+		}
+		ips := make([]PoolIP, 0, len(poolIPs))
+		for _, ip := range poolIPs {
+			ips = append(ips, PoolIP{IP: ip})
+		}
+		return ips
+	})
+	return cluster
+}
+
+func TestCluster_NodeSelectionStrategies(t *testing.T) {
+	fakeEIPName := "fakeExternalIPPool"
+	var poolIPs []string
+	for i := 0; i < 6; i++ {
+		poolIPs = append(poolIPs, fmt.Sprintf("10.1.1.%d", i))
+	}
+	testCases := []struct {
+		name                  string
+		nodes                 []string
+		nodeSelection         *crdv1a2.NodeSelection
+		expectedDistributions map[string][]int
+	}{
+		{
+			name:                  "ConsistentHash",
+			nodes:                 []string{"node0", "node1", "node2"},
+			nodeSelection:         &crdv1a2.NodeSelection{Strategy: crdv1a2.NodeSelectionStrategyConsistentHash},
+			expectedDistributions: map[string][]int{"node0": {1, 4}, "node1": {0, 2, 5}, "node2": {3}},
+		},
+		{
+			name:                  "LeastLoaded",
+			nodes:                 []string{"node0", "node1", "node2"},
+			nodeSelection:         &crdv1a2.NodeSelection{Strategy: crdv1a2.NodeSelectionStrategyLeastLoaded},
+			expectedDistributions: map[string][]int{"node0": {1, 4}, "node1": {0, 5}, "node2": {2, 3}},
+		},
+		{
+			name:                  "LeastLoaded after a Node fails",
+			nodes:                 []string{"node1", "node2"},
+			nodeSelection:         &crdv1a2.NodeSelection{Strategy: crdv1a2.NodeSelectionStrategyLeastLoaded},
+			expectedDistributions: map[string][]int{"node1": {0, 2, 4}, "node2": {1, 3, 5}},
+		},
+		{
+			name:  "PreferenceList",
+			nodes: []string{"node0", "node1", "node2"},
+			nodeSelection: &crdv1a2.NodeSelection{
+				Strategy:       crdv1a2.NodeSelectionStrategyPreferenceList,
+				PreferredNodes: []string{"node3", "node2", "node1"},
+			},
+			expectedDistributions: map[string][]int{"node0": {}, "node1": {}, "node2": {0, 1, 2, 3, 4, 5}},
+		},
+		{
+			name:  "PreferenceList with no preferred Node available",
+			nodes: []string{"node0", "node1", "node2"},
+			nodeSelection: &crdv1a2.NodeSelection{
+				Strategy:       crdv1a2.NodeSelectionStrategyPreferenceList,
+				PreferredNodes: []string{"node3"},
+			},
+			expectedDistributions: map[string][]int{"node0": {1, 4}, "node1": {0, 2, 5}, "node2": {3}},
+		},
+	}
+	for _, tt := range testCases {
+		t.Run(tt.name, func(t *testing.T) {
+			actualDistributions := map[string][]int{}
+			for _, node := range tt.nodes {
+				cluster := genNodeSelectionCluster(node, fakeEIPName, tt.nodes, tt.nodeSelection, poolIPs)
+				selectedIPs := []int{}
+				for i, ip := range poolIPs {
+					selected, err := cluster.ShouldSelectIP(ip, fakeEIPName)
+					assert.NoError(t, err)
+					if selected {
+						selectedIPs = append(selectedIPs, i)
+					}
+				}
+				actualDistributions[node] = selectedIPs
+			}
+			assert.Equal(t, tt.expectedDistributions, actualDistributions)
+		})
+	}
+}
+
+func TestSelectLeastLoadedNode(t *testing.T) {
+	poolIPs := []PoolIP{{IP: "10.1.1.0"}, {IP: "10.1.1.1"}, {IP: "10.1.1.2"}, {IP: "10.1.1.3"}}
+	onlyNode := func(name string) func(string) bool {
+		return func(node string) bool {
+			return node == name
+		}
+	}
+	testCases := []struct {
+		name         string
+		nodes        []string
+		poolIPs      []PoolIP
+		ip           string
+		filters      []func(string) bool
+		expectedNode string
+	}{
+		{
+			name:         "IP in the pool IPs",
+			nodes:        []string{"node0", "node1", "node2"},
+			poolIPs:      poolIPs,
+			ip:           "10.1.1.3",
+			expectedNode: "node2",
+		},
+		{
+			name:         "IP not in the pool IPs",
+			nodes:        []string{"node0", "node1", "node2"},
+			poolIPs:      poolIPs,
+			ip:           "10.1.1.4",
+			expectedNode: "node0",
+		},
+		{
+			name:         "IP with filters",
+			nodes:        []string{"node0", "node1", "node2"},
+			poolIPs:      poolIPs,
+			ip:           "10.1.1.3",
+			filters:      []func(string) bool{onlyNode("node1")},
+			expectedNode: "node1",
+		},
+		{
+			name:         "no Node passes filters",
+			nodes:        []string{"node0", "node1", "node2"},
+			poolIPs:      poolIPs,
+			ip:           "10.1.1.3",
+			filters:      []func(string) bool{func(node string) bool { return false }},
+			expectedNode: "",
+		},
+		{
+			name:         "no Node",
+			poolIPs:      poolIPs,
+			ip:           "10.1.1.3",
+			expectedNode: "",
+		},
+		{
+			// "10.1.1.10" is ordered after "10.1.1.9" numerically but before it as a string, "10.1.1.9" is
+			// assigned to node0 first.
+			name:         "IPs ordered numerically",
+			nodes:        []string{"node0", "node1"},
+			poolIPs:      []PoolIP{{IP: "10.1.1.10"}, {IP: "10.1.1.9"}},
+			ip:           "10.1.1.10",
+			expectedNode: "node1",
+		},
+		{
+			name:         "filters of pool IPs",
+			nodes:        []string{"node0", "node1"},
+			poolIPs:      []PoolIP{{IP: "10.1.1.0", Filters: []func(string) bool{onlyNode("node1")}}, {IP: "10.1.1.1", Filters: []func(string) bool{onlyNode("node1")}}},
+			ip:           "10.1.1.2",
+			expectedNode: "node0",
+		},
+		{
+			name:         "IPv4 and IPv6 IPs",
+			nodes:        []string{"node0", "node1"},
+			poolIPs:      []PoolIP{{IP: "2001::1"}, {IP: "10.1.1.0"}},
+			ip:           "2001::1",
+			expectedNode: "node0",
+		},
+	}
+	for _, tt := range testCases {
+		t.Run(tt.name, func(t *testing.T) {
+			consistentHash := newNodeConsistentHashMap()
+			consistentHash.Add(tt.nodes...)
+			selection := &nodeSelection{strategy: crdv1a2.NodeSelectionStrategyLeastLoaded, nodes: tt.nodes}
+			poolIPsCalled := 0
+			getPoolIPs := func() []PoolIP {
+				poolIPsCalled++
+				return tt.poolIPs
+			}
+			assert.Equal(t, tt.expectedNode, selectNode(tt.ip, consistentHash, selection, getPoolIPs, tt.filters...))
+			// The assignments of the pool IPs are cached until they are reset.
+			assert.Equal(t, tt.expectedNode, selectNode(tt.ip, consistentHash, selection, getPoolIPs, tt.filters...))
+			assert.Equal(t, 1, poolIPsCalled)
+			selection.resetAssignments()
+			assert.Equal(t, tt.expectedNode, selectNode(tt.ip, consistentHash, selection, getPoolIPs, tt.filters...))
+			assert.Equal(t, 2, poolIPsCalled)
+		})
+	}
+}
+
+func BenchmarkSelectLeastLoadedNode(b *testing.B) {
+	var nodes []string
+	for i := 0; i < 100; i++ {
+		nodes = append(nodes, fmt.Sprintf("node%d", i))
+	}
+	consistentHash := newNodeConsistentHashMap()
+	consistentHash.Add(nodes...)
+	var poolIPs []PoolIP
+	for i := 0; i < 1000; i++ {
+		poolIPs = append(poolIPs, PoolIP{IP: fmt.Sprintf("10.1.%d.%d", i/256, i%256)})
+	}
+	selection := &nodeSelection{strategy: crdv1a2.NodeSelectionStrategyLeastLoaded, nodes: nodes}
+	getPoolIPs := func() []PoolIP {
+		return poolIPs
+	}
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		// Select the owner Nodes of all IPs after the IPs change, as the agents do when an IP is allocated.
+		selection.resetAssignments()
+		for _, ip := range poolIPs {
+			selectNode(ip.IP, consistentHash, selection, getPoolIPs)
+		}
+	}
+}
@@ -146,6 +146,16 @@ var (
 		},
 	)
 
+	NetworkPolicyAuditLogDroppedRecords = metrics.NewCounter(
+		&metrics.CounterOpts{
+			Namespace:      metricNamespaceAntrea,
+			Subsystem:      metricSubsystemAgent,
+			Name:           "networkpolicy_audit_log_dropped_record_count",
+			Help:           "Number of NetworkPolicy audit log records dropped because the queue of records waiting to be sent to the syslog server was full, or the records could not be sent.",
+			StabilityLevel: metrics.ALPHA,
+		},
+	)
+
 	ReconnectionsToFlowCollector = metrics.NewGauge(
 		&metrics.GaugeOpts{
 			Namespace:      metricNamespaceAntrea,
@@ -165,6 +175,36 @@ var (
 			StabilityLevel: metrics.ALPHA,
 		},
 	)
+
+	FlowExporterSpoolRecords = metrics.NewGauge(
+		&metrics.GaugeOpts{
+			Namespace:      metricNamespaceAntrea,
+			Subsystem:      metricSubsystemAgent,
+			Name:           "flow_exporter_spool_record_count",
+			Help:           "Number of flow records stored in the on-disk spool of the Flow Exporter, waiting to be sent to the flow collector.",
+			StabilityLevel: metrics.ALPHA,
+		},
+	)
+
+	FlowExporterSpoolSizeBytes = metrics.NewGauge(
+		&metrics.GaugeOpts{
+			Namespace:      metricNamespaceAntrea,
+			Subsystem:      metricSubsystemAgent,
+			Name:           "flow_exporter_spool_size_bytes",
+			Help:           "Size in bytes of the flow records stored in the on-disk spool of the Flow Exporter.",
+			StabilityLevel: metrics.ALPHA,
+		},
+	)
+
+	FlowExporterSpoolDroppedRecords = metrics.NewCounter(
+		&metrics.CounterOpts{
+			Namespace:      metricNamespaceAntrea,
+			Subsystem:      metricSubsystemAgent,
+			Name:           "flow_exporter_spool_dropped_record_count",
+			Help:           "Number of flow records dropped by the Flow Exporter because the on-disk spool was full or the records could not be read back.",
+			StabilityLevel: metrics.ALPHA,
+		},
+	)
 )
 
 func InitializePrometheusMetrics() {
@@ -194,6 +234,10 @@ func InitializeNetworkPolicyMetrics() {
 	if err := legacyregistry.Register(NetworkPolicyCount); err != nil {
 		klog.ErrorS(err, "Failed to register metrics with Prometheus", "metrics", "antrea_agent_networkpolicy_count")
 	}
+
+	if err := legacyregistry.Register(NetworkPolicyAuditLogDroppedRecords); err != nil {
+		klog.ErrorS(err, "Failed to register metrics with Prometheus", "metrics", "antrea_agent_networkpolicy_audit_log_dropped_record_count")
+	}
 }
 
 func InitializeOVSMetrics() {
@@ -239,4 +283,13 @@ func InitializeConnectionMetrics() {
 	if err := legacyregistry.Register(MaxConnectionsInConnTrackTable); err != nil {
 		klog.ErrorS(err, "Failed to register metrics with Prometheus", "metrics", "antrea_agent_conntrack_max_connection_count")
 	}
+	if err := legacyregistry.Register(FlowExporterSpoolRecords); err != nil {
+		klog.ErrorS(err, "Failed to register metrics with Prometheus", "metrics", "antrea_agent_flow_exporter_spool_record_count")
+	}
+	if err := legacyregistry.Register(FlowExporterSpoolSizeBytes); err != nil {
+		klog.ErrorS(err, "Failed to register metrics with Prometheus", "metrics", "antrea_agent_flow_exporter_spool_size_bytes")
+	}
+	if err := legacyregistry.Register(FlowExporterSpoolDroppedRecords); err != nil {
+		klog.ErrorS(err, "Failed to register metrics with Prometheus", "metrics", "antrea_agent_flow_exporter_spool_dropped_record_count")
+	}
 }
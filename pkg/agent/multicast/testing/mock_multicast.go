@@ -20,7 +20,7 @@
 package testing
 
 import (
-	gomock "github.com/golang/mock/gomock"
+	gomock "go.uber.org/mock/gomock"
 	net "net"
 	reflect "reflect"
 )
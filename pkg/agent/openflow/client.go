@@ -29,6 +29,7 @@ import (
 	"antrea.io/antrea/pkg/agent/openflow/cookie"
 	"antrea.io/antrea/pkg/agent/types"
 	"antrea.io/antrea/pkg/agent/util"
+	"antrea.io/antrea/pkg/apis/controlplane/v1beta2"
 	"antrea.io/antrea/pkg/apis/crd/v1alpha2"
 	binding "antrea.io/antrea/pkg/ovs/openflow"
 	utilip "antrea.io/antrea/pkg/util/ip"
@@ -84,8 +85,16 @@ type Client interface {
 	// InstallServiceGroup installs a group for Service LB. Each endpoint
 	// is a bucket of the group. For now, each bucket has the same weight.
 	InstallServiceGroup(groupID binding.GroupIDType, withSessionAffinity bool, endpoints []proxy.Endpoint) error
-	// UninstallServiceGroup removes the group and its buckets that are
-	// installed by InstallServiceGroup.
+	// InstallServiceMaglevGroup installs a group for Service LB with the lookup table computed by the Maglev algorithm.
+	// Each entry of the lookup table is a bucket of the group, and a bucket is selected with the hash of the 5-tuple
+	// of the packet instead of the hash computed by the datapath.
+	InstallServiceMaglevGroup(groupID binding.GroupIDType, withSessionAffinity bool, lookupTable []proxy.Endpoint) error
+	// InstallServiceAffinityHashGroup installs a group for Service LB with session affinity maintained by hashing.
+	// Each endpoint is a bucket of the group, and a bucket is selected with the hash of the packet fields in the
+	// session affinity key, so that no learned flow is needed.
+	InstallServiceAffinityHashGroup(groupID binding.GroupIDType, affinityKey types.SessionAffinityKey, endpoints []proxy.Endpoint) error
+	// UninstallServiceGroup removes the group and its buckets that are installed by InstallServiceGroup,
+	// InstallServiceMaglevGroup or InstallServiceAffinityHashGroup.
 	UninstallServiceGroup(groupID binding.GroupIDType) error
 
 	// InstallEndpointFlows installs flows for accessing Endpoints.
@@ -98,12 +107,17 @@ type Client interface {
 
 	// InstallServiceFlows installs flows for accessing Service NodePort, LoadBalancer and ClusterIP. It installs the
 	// flow that uses the group/bucket to do service LB. If the affinityTimeout is not zero, it also installs the flow
-	// which has a learn action to maintain the LB decision. The group with the groupID must be installed before,
-	// otherwise the installation will fail.
+	// which has a learn action to maintain the LB decision, and affinityOptions determines how the LB decision is
+	// maintained. The group with the groupID must be installed before, otherwise the installation will fail.
 	// nodeLocalExternal represents if the externalTrafficPolicy is Local or not. This field is meaningful only when
 	// the svcType is NodePort or LoadBalancer.
-	InstallServiceFlows(groupID binding.GroupIDType, svcIP net.IP, svcPort uint16, protocol binding.Protocol, affinityTimeout uint16, nodeLocalExternal bool, svcType v1.ServiceType) error
-	// UninstallServiceFlows removes flows installed by InstallServiceFlows.
+	InstallServiceFlows(groupID binding.GroupIDType, svcIP net.IP, svcPort uint16, protocol binding.Protocol, affinityTimeout uint16, affinityOptions types.SessionAffinityOptions, nodeLocalExternal bool, svcType v1.ServiceType) error
+	// InstallServiceDSRFlows installs flows for accessing a Service LoadBalancer IP in DSR mode. On the ingress Node, the
+	// group with the groupID is used to select an Endpoint from all Endpoints; for the packets forwarded by the ingress
+	// Node via tunnel, the group with the localGroupID is used to select an Endpoint from local Endpoints. Both groups
+	// must be installed before, otherwise the installation will fail.
+	InstallServiceDSRFlows(groupID, localGroupID binding.GroupIDType, svcIP net.IP, svcPort uint16, protocol binding.Protocol) error
+	// UninstallServiceFlows removes flows installed by InstallServiceFlows and InstallServiceDSRFlows.
 	UninstallServiceFlows(svcIP net.IP, svcPort uint16, protocol binding.Protocol) error
 
 	// GetFlowTableStatus should return an array of flow table status, all existing flow tables should be included in the list.
@@ -154,6 +168,14 @@ type Client interface {
 	// UninstallPodSNATFlows removes the SNAT flows for the local Pod.
 	UninstallPodSNATFlows(ofPort uint32) error
 
+	// InstallEgressQoS installs an OpenFlow meter and the flow to rate limit the traffic SNAT'd with the local SNAT
+	// IP of the provided mark. rate is in kilobits per second and burst is in kilobits.
+	InstallEgressQoS(mark, rate, burst uint32) error
+
+	// UninstallEgressQoS removes the OpenFlow meter and the flow installed to rate limit the traffic SNAT'd with the
+	// local SNAT IP of the provided mark.
+	UninstallEgressQoS(mark uint32) error
+
 	// Disconnect disconnects the connection between client and OFSwitch.
 	Disconnect() error
 
@@ -210,8 +232,12 @@ type Client interface {
 	// Initial tun_metadata0 in TLV map for Traceflow.
 	InitialTLVMap() error
 
-	// Find Network Policy reference and OFpriority by conjunction ID.
-	GetPolicyInfoFromConjunction(ruleID uint32) (string, string)
+	// Find Network Policy reference, OFpriority and rule name by conjunction ID.
+	GetPolicyInfoFromConjunction(ruleID uint32) (*v1beta2.NetworkPolicyReference, string, string)
+
+	// GetPolicyLogSuppressedPackets returns the number of packets which were not logged because they exceeded the
+	// log rate of the policy rule or were not sampled, keyed by the conjunction ID of the rule.
+	GetPolicyLogSuppressedPackets() (map[uint32]uint64, error)
 
 	// RegisterPacketInHandler uses SubscribePacketIn to get PacketIn message and process received
 	// packets through registered handlers.
@@ -270,6 +296,19 @@ type Client interface {
 		udpDstPort uint16,
 		udpData []byte,
 		mutatePacketOut func(builder binding.PacketOutBuilder) binding.PacketOutBuilder) error
+	// SendL4PacketOut sends an IP packet with the provided L4 packet as a packet-out to OVS. The L4 packet
+	// is sent as it is, hence its checksum must have been computed by the caller.
+	SendL4PacketOut(
+		srcMAC string,
+		dstMAC string,
+		srcIP string,
+		dstIP string,
+		inPort uint32,
+		outPort uint32,
+		isIPv6 bool,
+		ipProtocol uint8,
+		l4Packet ofutil.Message,
+		mutatePacketOut func(builder binding.PacketOutBuilder) binding.PacketOutBuilder) error
 	// NewDNSpacketInConjunction creates a policyRuleConjunction for the dns response interception flows.
 	NewDNSpacketInConjunction(id uint32) error
 	// AddAddressToDNSConjunction adds addresses to the toAddresses of the dns packetIn conjunction,
@@ -307,6 +346,13 @@ type Client interface {
 	// UninstallTrafficControlMarkFlows removes the flows for a traffic control rule.
 	UninstallTrafficControlMarkFlows(name string) error
 
+	// InstallPacketCaptureFlows installs the flows to mirror the packets sent from and to a Pod port, which match the
+	// provided packet, to the capture port of a PacketCapture request.
+	InstallPacketCaptureFlows(name string, ofPort, captureOFPort uint32, packet *binding.Packet) error
+
+	// UninstallPacketCaptureFlows removes the flows for a PacketCapture request.
+	UninstallPacketCaptureFlows(name string) error
+
 	// InstallTrafficControlReturnPortFlow installs the flow to classify the packets from a return port.
 	InstallTrafficControlReturnPortFlow(returnOFPort uint32) error
 
@@ -504,6 +550,9 @@ func (c *client) InstallNodeFlows(hostname string,
 		if c.enableEgress {
 			flows = append(flows, c.featureEgress.snatSkipNodeFlow(tunnelPeerIP))
 		}
+		if c.enableProxy && c.featureService.enableDSR && !isIPv6 {
+			flows = append(flows, c.featureService.l3FwdFlowToRemoteEndpointViaTun(localGatewayMAC, *peerPodCIDR, tunnelPeerIP))
+		}
 		if c.connectUplinkToBridge {
 			// flow to catch traffic from AntreaFlexibleIPAM Pod to remote Per-Node IPAM Pod
 			flows = append(flows, c.featurePodConnectivity.l3FwdFlowToRemoteViaUplink(remoteGatewayMAC, *peerPodCIDR, true))
@@ -630,6 +679,30 @@ func (c *client) InstallServiceGroup(groupID binding.GroupIDType, withSessionAff
 	return nil
 }
 
+func (c *client) InstallServiceMaglevGroup(groupID binding.GroupIDType, withSessionAffinity bool, lookupTable []proxy.Endpoint) error {
+	c.replayMutex.RLock()
+	defer c.replayMutex.RUnlock()
+
+	group := c.featureService.serviceMaglevGroup(groupID, withSessionAffinity, lookupTable...)
+	if err := group.Add(); err != nil {
+		return fmt.Errorf("error when installing Service Maglev Group: %w", err)
+	}
+	c.featureService.groupCache.Store(groupID, group)
+	return nil
+}
+
+func (c *client) InstallServiceAffinityHashGroup(groupID binding.GroupIDType, affinityKey types.SessionAffinityKey, endpoints []proxy.Endpoint) error {
+	c.replayMutex.RLock()
+	defer c.replayMutex.RUnlock()
+
+	group := c.featureService.serviceAffinityHashGroup(groupID, affinityKey, endpoints...)
+	if err := group.Add(); err != nil {
+		return fmt.Errorf("error when installing Service affinity hash Group: %w", err)
+	}
+	c.featureService.groupCache.Store(groupID, group)
+	return nil
+}
+
 func (c *client) UninstallServiceGroup(groupID binding.GroupIDType) error {
 	c.replayMutex.RLock()
 	defer c.replayMutex.RUnlock()
@@ -648,6 +721,10 @@ func generateServicePortFlowCacheKey(svcIP net.IP, svcPort uint16, protocol bind
 	return fmt.Sprintf("S%s%s%x", svcIP, protocol, svcPort)
 }
 
+func generateServiceDSRFlowCacheKey(svcIP net.IP, svcPort uint16, protocol binding.Protocol) string {
+	return fmt.Sprintf("D%s%s%x", svcIP, protocol, svcPort)
+}
+
 func (c *client) InstallEndpointFlows(protocol binding.Protocol, endpoints []proxy.Endpoint) error {
 	c.replayMutex.RLock()
 	defer c.replayMutex.RUnlock()
@@ -663,6 +740,8 @@ func (c *client) InstallEndpointFlows(protocol binding.Protocol, endpoints []pro
 		flows = append(flows, c.featureService.endpointDNATFlow(endpointIP, portVal, protocol))
 		if endpoint.GetIsLocal() {
 			flows = append(flows, c.featureService.podHairpinSNATFlow(endpointIP))
+		} else if c.featureService.enableDSR && endpointIP.To4() != nil {
+			flows = append(flows, c.featureService.endpointDSRFlow(endpointIP, portVal, protocol))
 		}
 		keyToFlows[cacheKey] = flows
 	}
@@ -682,28 +761,41 @@ func (c *client) UninstallEndpointFlows(protocol binding.Protocol, endpoint prox
 	return c.deleteFlows(c.featureService.cachedFlows, cacheKey)
 }
 
-func (c *client) InstallServiceFlows(groupID binding.GroupIDType, svcIP net.IP, svcPort uint16, protocol binding.Protocol, affinityTimeout uint16, nodeLocalExternal bool, svcType v1.ServiceType) error {
+func (c *client) InstallServiceFlows(groupID binding.GroupIDType, svcIP net.IP, svcPort uint16, protocol binding.Protocol, affinityTimeout uint16, affinityOptions types.SessionAffinityOptions, nodeLocalExternal bool, svcType v1.ServiceType) error {
 	c.replayMutex.RLock()
 	defer c.replayMutex.RUnlock()
 	var flows []binding.Flow
 	flows = append(flows, c.featureService.serviceLBFlow(groupID, svcIP, svcPort, protocol, affinityTimeout != 0, nodeLocalExternal, svcType))
 	if affinityTimeout != 0 {
-		flows = append(flows, c.featureService.serviceLearnFlow(groupID, svcIP, svcPort, protocol, affinityTimeout, nodeLocalExternal, svcType))
+		flows = append(flows, c.featureService.serviceLearnFlow(groupID, svcIP, svcPort, protocol, affinityTimeout, affinityOptions, nodeLocalExternal, svcType))
 	}
 	cacheKey := generateServicePortFlowCacheKey(svcIP, svcPort, protocol)
 	return c.addFlows(c.featureService.cachedFlows, cacheKey, flows)
 }
 
+func (c *client) InstallServiceDSRFlows(groupID, localGroupID binding.GroupIDType, svcIP net.IP, svcPort uint16, protocol binding.Protocol) error {
+	c.replayMutex.RLock()
+	defer c.replayMutex.RUnlock()
+	flows := c.featureService.serviceDSRFlows(groupID, localGroupID, svcIP, svcPort, protocol)
+	cacheKey := generateServiceDSRFlowCacheKey(svcIP, svcPort, protocol)
+	return c.addFlows(c.featureService.cachedFlows, cacheKey, flows)
+}
+
 func (c *client) UninstallServiceFlows(svcIP net.IP, svcPort uint16, protocol binding.Protocol) error {
 	c.replayMutex.RLock()
 	defer c.replayMutex.RUnlock()
 	cacheKey := generateServicePortFlowCacheKey(svcIP, svcPort, protocol)
-	return c.deleteFlows(c.featureService.cachedFlows, cacheKey)
+	if err := c.deleteFlows(c.featureService.cachedFlows, cacheKey); err != nil {
+		return err
+	}
+	return c.deleteFlows(c.featureService.cachedFlows, generateServiceDSRFlowCacheKey(svcIP, svcPort, protocol))
 }
 
 func (c *client) GetServiceFlowKeys(svcIP net.IP, svcPort uint16, protocol binding.Protocol, endpoints []proxy.Endpoint) []string {
 	cacheKey := generateServicePortFlowCacheKey(svcIP, svcPort, protocol)
 	flowKeys := c.getFlowKeysFromCache(c.featureService.cachedFlows, cacheKey)
+	cacheKey = generateServiceDSRFlowCacheKey(svcIP, svcPort, protocol)
+	flowKeys = append(flowKeys, c.getFlowKeysFromCache(c.featureService.cachedFlows, cacheKey)...)
 	for _, ep := range endpoints {
 		epPort, _ := ep.Port()
 		cacheKey = generateEndpointFlowCacheKey(ep.IP(), epPort, protocol)
@@ -834,7 +926,7 @@ func (c *client) generatePipelines() {
 	c.traceableFeatures = append(c.traceableFeatures, c.featureNetworkPolicy)
 
 	if c.enableEgress {
-		c.featureEgress = newFeatureEgress(c.cookieAllocator, c.ipProtocols, c.nodeConfig, c.egressConfig)
+		c.featureEgress = newFeatureEgress(c.cookieAllocator, c.ipProtocols, c.nodeConfig, c.egressConfig, c.ovsMetersAreSupported)
 		c.activatedFeatures = append(c.activatedFeatures, c.featureEgress)
 	}
 
@@ -930,6 +1022,47 @@ func (c *client) UninstallPodSNATFlows(ofPort uint32) error {
 	return c.deleteFlows(c.featureEgress.cachedFlows, cacheKey)
 }
 
+func (c *client) InstallEgressQoS(mark, rate, burst uint32) error {
+	if !c.featureEgress.enableEgressTrafficShaping {
+		return ErrEgressTrafficShapingNotSupported
+	}
+	c.replayMutex.RLock()
+	defer c.replayMutex.RUnlock()
+
+	meterID := binding.MeterIDType(mark)
+	meter := c.genEgressQoSMeter(meterID, rate, burst)
+	if _, exists := c.featureEgress.meterCache.Load(meterID); exists {
+		if err := meter.Modify(); err != nil {
+			return fmt.Errorf("error when modifying Egress QoS meter %d: %w", meterID, err)
+		}
+	} else if err := meter.Add(); err != nil {
+		return fmt.Errorf("error when installing Egress QoS meter %d: %w", meterID, err)
+	}
+	c.featureEgress.meterCache.Store(meterID, meter)
+
+	cacheKey := fmt.Sprintf("q%x", mark)
+	return c.addFlows(c.featureEgress.cachedFlows, cacheKey, []binding.Flow{c.featureEgress.egressQoSFlow(mark)})
+}
+
+func (c *client) UninstallEgressQoS(mark uint32) error {
+	c.replayMutex.RLock()
+	defer c.replayMutex.RUnlock()
+
+	cacheKey := fmt.Sprintf("q%x", mark)
+	if err := c.deleteFlows(c.featureEgress.cachedFlows, cacheKey); err != nil {
+		return err
+	}
+	meterID := binding.MeterIDType(mark)
+	if _, exists := c.featureEgress.meterCache.Load(meterID); !exists {
+		return nil
+	}
+	if !c.bridge.DeleteMeter(meterID) {
+		return fmt.Errorf("error when deleting Egress QoS meter %d", meterID)
+	}
+	c.featureEgress.meterCache.Delete(meterID)
+	return nil
+}
+
 func (c *client) ReplayFlows() {
 	c.replayMutex.Lock()
 	defer c.replayMutex.Unlock()
@@ -944,6 +1077,12 @@ func (c *client) ReplayFlows() {
 	if c.enableMulticast {
 		c.featureMulticast.replayGroups()
 	}
+	if c.enableEgress {
+		c.featureEgress.replayMeters()
+	}
+	c.featureNetworkPolicy.replayMeters()
+	// The log groups reference the log meters.
+	c.featureNetworkPolicy.replayGroups()
 
 	for _, activeFeature := range c.activatedFeatures {
 		if err := c.ofEntryOperations.AddAll(activeFeature.replayFlows()); err != nil {
@@ -1209,6 +1348,35 @@ func (c *client) SendUDPPacketOut(
 	return c.bridge.SendPacketOut(packetOutObj)
 }
 
+// SendL4PacketOut generates an IP packet with the provided L4 packet as a packet-out and sends it to OVS.
+func (c *client) SendL4PacketOut(
+	srcMAC string,
+	dstMAC string,
+	srcIP string,
+	dstIP string,
+	inPort uint32,
+	outPort uint32,
+	isIPv6 bool,
+	ipProtocol uint8,
+	l4Packet ofutil.Message,
+	mutatePacketOut func(builder binding.PacketOutBuilder) binding.PacketOutBuilder) error {
+	// Generate a base IP PacketOutBuilder.
+	packetOutBuilder, err := setBasePacketOutBuilder(c.bridge.BuildPacketOut(), srcMAC, dstMAC, srcIP, dstIP, inPort, outPort)
+	if err != nil {
+		return err
+	}
+	// Set protocol and L4 packet.
+	packetOutBuilder = packetOutBuilder.SetIPProtocolValue(isIPv6, ipProtocol).
+		SetL4Packet(l4Packet)
+
+	if mutatePacketOut != nil {
+		packetOutBuilder = mutatePacketOut(packetOutBuilder)
+	}
+
+	packetOutObj := packetOutBuilder.Done()
+	return c.bridge.SendPacketOut(packetOutObj)
+}
+
 func (c *client) InstallMulticastInitialFlows(pktInReason uint8) error {
 	flows := c.featureMulticast.igmpPktInFlows(pktInReason)
 	flows = append(flows, c.featureMulticast.externalMulticastReceiverFlow())
@@ -1278,6 +1446,21 @@ func (c *client) UninstallTrafficControlMarkFlows(name string) error {
 	return c.deleteFlows(c.featurePodConnectivity.tcCachedFlows, cacheKey)
 }
 
+func (c *client) InstallPacketCaptureFlows(name string, ofPort, captureOFPort uint32, packet *binding.Packet) error {
+	flows := c.featurePodConnectivity.packetCaptureMarkFlows(ofPort, captureOFPort, packet)
+	cacheKey := fmt.Sprintf("pc_%s", name)
+	c.replayMutex.RLock()
+	defer c.replayMutex.RUnlock()
+	return c.addFlows(c.featurePodConnectivity.tcCachedFlows, cacheKey, flows)
+}
+
+func (c *client) UninstallPacketCaptureFlows(name string) error {
+	cacheKey := fmt.Sprintf("pc_%s", name)
+	c.replayMutex.RLock()
+	defer c.replayMutex.RUnlock()
+	return c.deleteFlows(c.featurePodConnectivity.tcCachedFlows, cacheKey)
+}
+
 func (c *client) InstallTrafficControlReturnPortFlow(returnOFPort uint32) error {
 	cacheKey := fmt.Sprintf("tc_%d", returnOFPort)
 	flows := []binding.Flow{c.featurePodConnectivity.trafficControlReturnClassifierFlow(returnOFPort)}
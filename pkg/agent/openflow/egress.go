@@ -15,13 +15,21 @@
 package openflow
 
 import (
+	"errors"
 	"net"
+	"sync"
+
+	"k8s.io/klog/v2"
 
 	"antrea.io/antrea/pkg/agent/config"
 	"antrea.io/antrea/pkg/agent/openflow/cookie"
 	binding "antrea.io/antrea/pkg/ovs/openflow"
 )
 
+// ErrEgressTrafficShapingNotSupported is returned when the rate limit of an Egress cannot be enforced because the OVS
+// datapath doesn't support OpenFlow meters.
+var ErrEgressTrafficShapingNotSupported = errors.New("Egress traffic shaping is not supported as the OVS datapath doesn't support meters")
+
 type featureEgress struct {
 	cookieAllocator cookie.Allocator
 	ipProtocols     []binding.Protocol
@@ -32,6 +40,12 @@ type featureEgress struct {
 	nodeIPs     map[binding.Protocol]net.IP
 	gatewayMAC  net.HardwareAddr
 
+	// enableEgressTrafficShaping indicates whether the rate limit of Egress can be enforced, which requires the OVS
+	// datapath to support OpenFlow meters.
+	enableEgressTrafficShaping bool
+	// meterCache caches the meters installed for Egress QoS, keyed by the meter ID.
+	meterCache sync.Map
+
 	category cookie.Category
 }
 
@@ -42,7 +56,8 @@ func (f *featureEgress) getFeatureName() string {
 func newFeatureEgress(cookieAllocator cookie.Allocator,
 	ipProtocols []binding.Protocol,
 	nodeConfig *config.NodeConfig,
-	egressConfig *config.EgressConfig) *featureEgress {
+	egressConfig *config.EgressConfig,
+	enableEgressTrafficShaping bool) *featureEgress {
 	exceptCIDRs := make(map[binding.Protocol][]net.IPNet)
 	for _, cidr := range egressConfig.ExceptCIDRs {
 		if cidr.IP.To4() == nil {
@@ -68,6 +83,8 @@ func newFeatureEgress(cookieAllocator cookie.Allocator,
 		nodeIPs:         nodeIPs,
 		gatewayMAC:      nodeConfig.GatewayConfig.MAC,
 		category:        cookie.Egress,
+
+		enableEgressTrafficShaping: enableEgressTrafficShaping,
 	}
 }
 
@@ -84,3 +101,14 @@ func (f *featureEgress) replayFlows() []binding.Flow {
 
 	return flows
 }
+
+func (f *featureEgress) replayMeters() {
+	f.meterCache.Range(func(id, value interface{}) bool {
+		meter := value.(binding.Meter)
+		meter.Reset()
+		if err := meter.Add(); err != nil {
+			klog.ErrorS(err, "Error when replaying cached Egress QoS meter", "meterID", id)
+		}
+		return true
+	})
+}
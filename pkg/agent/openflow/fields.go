@@ -126,6 +126,9 @@ var (
 	TrafficControlActionField     = binding.NewRegField(4, 22, 23, "TrafficControlAction")
 	TrafficControlMirrorRegMark   = binding.NewRegMark(TrafficControlActionField, 0b01)
 	TrafficControlRedirectRegMark = binding.NewRegMark(TrafficControlActionField, 0b10)
+	// reg4[24]: Mark to indicate whether the packet is accessing a LoadBalancer IP of a Service in DSR mode. If the
+	// selected Endpoint is not on the local Node, the packet is forwarded to the Endpoint's Node without DNAT.
+	DSRServiceRegMark = binding.NewOneBitRegMark(4, 24, "DSRService")
 
 	// reg5(NXM_NX_REG5)
 	// Field to cache the Egress conjunction ID hit by TraceFlow packet.
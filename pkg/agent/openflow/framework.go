@@ -257,10 +257,14 @@ func (f *featureService) getRequiredTables() []*Table {
 }
 
 func (f *featureEgress) getRequiredTables() []*Table {
-	return []*Table{
+	tables := []*Table{
 		L3ForwardingTable,
 		EgressMarkTable,
 	}
+	if f.enableEgressTrafficShaping {
+		tables = append(tables, EgressQoSTable)
+	}
+	return tables
 }
 
 func (f *featureMulticast) getRequiredTables() []*Table {
@@ -77,6 +77,7 @@ var (
 	metricFlowIdentifier = fmt.Sprintf("priority=%d,", priorityNormal)
 
 	protocolUDP = v1beta2.ProtocolUDP
+	protocolTCP = v1beta2.ProtocolTCP
 	dnsPort     = intstr.FromInt(53)
 )
 
@@ -639,7 +640,14 @@ type policyRuleConjunction struct {
 	// NetworkPolicy reference information for debugging usage, its value can be nil
 	// for conjunctions that are not built for a specific NetworkPolicy, e.g. DNS packetin Conjunction.
 	npRef       *v1beta2.NetworkPolicyReference
+	ruleName    string
 	ruleTableID uint8
+	// logMeter is the meter rate limiting the packets sent to the controller for logging, its value is nil if the
+	// logging of the rule is not rate limited per rule.
+	logMeter binding.Meter
+	// logGroup is the group sampling and rate limiting the packets sent to the controller for logging, its value is
+	// nil if the logging of the rule is not limited per rule.
+	logGroup binding.Group
 }
 
 // clause groups conjunctive match flows. Matches in a clause represent source addresses(for fromClause), or destination
@@ -677,9 +685,17 @@ func (c *client) NewDNSpacketInConjunction(id uint32) error {
 	if err := c.ofEntryOperations.AddAll(conj.actionFlows); err != nil {
 		return fmt.Errorf("error when adding action flows for the DNS conjunction: %w", err)
 	}
-	udpService := v1beta2.Service{
-		Protocol: &protocolUDP,
-		Port:     &dnsPort,
+	// DNS responses are intercepted for both UDP and TCP, as DNS clients fall back to TCP when the
+	// response is too large to fit in a UDP message.
+	dnsServices := []v1beta2.Service{
+		{
+			Protocol: &protocolUDP,
+			Port:     &dnsPort,
+		},
+		{
+			Protocol: &protocolTCP,
+			Port:     &dnsPort,
+		},
 	}
 	dnsPriority := priorityDNSIntercept
 	conj.serviceClause = conj.newClause(1, 2, getTableByID(conj.ruleTableID), nil)
@@ -687,7 +703,7 @@ func (c *client) NewDNSpacketInConjunction(id uint32) error {
 
 	c.featureNetworkPolicy.conjMatchFlowLock.Lock()
 	defer c.featureNetworkPolicy.conjMatchFlowLock.Unlock()
-	ctxChanges := conj.serviceClause.addServiceFlows(c.featureNetworkPolicy, []v1beta2.Service{udpService}, &dnsPriority, true, false)
+	ctxChanges := conj.serviceClause.addServiceFlows(c.featureNetworkPolicy, dnsServices, &dnsPriority, true, false)
 	if err := c.featureNetworkPolicy.applyConjunctiveMatchFlows(ctxChanges); err != nil {
 		return err
 	}
@@ -1085,10 +1101,16 @@ func (c *client) InstallPolicyRuleFlows(rule *types.PolicyRule) error {
 	defer c.featureNetworkPolicy.conjMatchFlowLock.Unlock()
 	ctxChanges := c.featureNetworkPolicy.calculateMatchFlowChangesForRule(conj, rule)
 
+	// The meter and the group must be installed before the action flows referencing them.
+	if err := c.featureNetworkPolicy.installLogEntries([]*policyRuleConjunction{conj}); err != nil {
+		return err
+	}
 	if err := c.ofEntryOperations.AddAll(conj.metricFlows); err != nil {
+		c.featureNetworkPolicy.uninstallLogEntries([]*policyRuleConjunction{conj})
 		return err
 	}
 	if err := c.ofEntryOperations.AddAll(conj.actionFlows); err != nil {
+		c.featureNetworkPolicy.uninstallLogEntries([]*policyRuleConjunction{conj})
 		return err
 	}
 	if err := c.featureNetworkPolicy.applyConjunctiveMatchFlows(ctxChanges); err != nil {
@@ -1109,8 +1131,9 @@ func (f *featureNetworkPolicy) calculateActionFlowChangesForRule(rule *types.Pol
 		return nil
 	}
 	conj = &policyRuleConjunction{
-		id:    ruleOfID,
-		npRef: rule.PolicyRef,
+		id:       ruleOfID,
+		npRef:    rule.PolicyRef,
+		ruleName: rule.Name,
 	}
 	nClause, ruleTable, dropTable := conj.calculateClauses(rule)
 	conj.ruleTableID = rule.TableID
@@ -1124,17 +1147,19 @@ func (f *featureNetworkPolicy) calculateActionFlowChangesForRule(rule *types.Pol
 		// Install action flows.
 		var actionFlows []binding.Flow
 		var metricFlows []binding.Flow
-		if rule.IsAntreaNetworkPolicyRule() && *rule.Action == crdv1alpha1.RuleActionDrop {
-			metricFlows = append(metricFlows, f.denyRuleMetricFlow(ruleOfID, isIngress, rule.TableID))
-			actionFlows = append(actionFlows, f.conjunctionActionDenyFlow(ruleOfID, ruleTable, rule.Priority, DispositionDrop, rule.EnableLogging))
-		} else if rule.IsAntreaNetworkPolicyRule() && *rule.Action == crdv1alpha1.RuleActionReject {
+		logGroupID := f.calculateLogEntriesForRule(conj, rule)
+		if rule.IsAntreaNetworkPolicyRule() && (*rule.Action == crdv1alpha1.RuleActionDrop || *rule.Action == crdv1alpha1.RuleActionReject) {
+			disposition := uint32(DispositionDrop)
+			if *rule.Action == crdv1alpha1.RuleActionReject {
+				disposition = DispositionRej
+			}
 			metricFlows = append(metricFlows, f.denyRuleMetricFlow(ruleOfID, isIngress, rule.TableID))
-			actionFlows = append(actionFlows, f.conjunctionActionDenyFlow(ruleOfID, ruleTable, rule.Priority, DispositionRej, rule.EnableLogging))
+			actionFlows = append(actionFlows, f.conjunctionActionDenyFlow(ruleOfID, ruleTable, rule.Priority, disposition, rule.EnableLogging, logGroupID))
 		} else if rule.IsAntreaNetworkPolicyRule() && *rule.Action == crdv1alpha1.RuleActionPass {
 			actionFlows = append(actionFlows, f.conjunctionActionPassFlow(ruleOfID, ruleTable, rule.Priority, rule.EnableLogging))
 		} else {
 			metricFlows = append(metricFlows, f.allowRulesMetricFlows(ruleOfID, isIngress, rule.TableID)...)
-			actionFlows = append(actionFlows, f.conjunctionActionFlow(ruleOfID, ruleTable, dropTable.GetNext(), rule.Priority, rule.EnableLogging)...)
+			actionFlows = append(actionFlows, f.conjunctionActionFlow(ruleOfID, ruleTable, dropTable.GetNext(), rule.Priority, rule.EnableLogging, logGroupID)...)
 		}
 		conj.actionFlows = actionFlows
 		conj.metricFlows = metricFlows
@@ -1142,6 +1167,29 @@ func (f *featureNetworkPolicy) calculateActionFlowChangesForRule(rule *types.Pol
 	return conj
 }
 
+// calculateLogEntriesForRule creates the meter and the group limiting the logging of the rule if it's an Antrea-native
+// policy rule which allows, drops or rejects packets with logRate or logSamplingRatio set, and returns the ID of the
+// group. It returns 0 if the logging of the rule is not limited per rule, or if the limits cannot be enforced.
+func (f *featureNetworkPolicy) calculateLogEntriesForRule(conj *policyRuleConjunction, rule *types.PolicyRule) binding.GroupIDType {
+	if !rule.EnableLogging || !rule.IsAntreaNetworkPolicyRule() || *rule.Action == crdv1alpha1.RuleActionPass {
+		return 0
+	}
+	var logMeterID binding.MeterIDType
+	if rule.LogRate != nil && f.ovsMetersAreSupported {
+		logMeterID = policyLogMeterID(conj.id)
+		conj.logMeter = newPacketInMeter(f.bridge, logMeterID, uint32(*rule.LogRate))
+	}
+	var samplingRatio uint16
+	if rule.LogSamplingRatio != nil {
+		samplingRatio = uint16(*rule.LogSamplingRatio)
+	}
+	if logMeterID == 0 && samplingRatio <= 1 {
+		return 0
+	}
+	conj.logGroup = f.policyLogGroup(conj.id, logMeterID, samplingRatio)
+	return policyLogGroupID(conj.id)
+}
+
 // calculateMatchFlowChangesForRule calculates the contextChanges for the policyRule, and updates the context status in case of batch install.
 func (f *featureNetworkPolicy) calculateMatchFlowChangesForRule(conj *policyRuleConjunction, rule *types.PolicyRule) []*conjMatchFlowContextChange {
 	// Calculate the conjMatchFlowContext changes. The changed Openflow entries are included in the conjMatchFlowContext change.
@@ -1249,11 +1297,16 @@ func (c *client) BatchInstallPolicyRuleFlows(ofPolicyRules []*types.PolicyRule)
 		}
 	}
 
+	if err := c.featureNetworkPolicy.installLogEntries(conjunctions); err != nil {
+		c.featureNetworkPolicy.globalConjMatchFlowCache = map[string]*conjMatchFlowContext{}
+		return err
+	}
 	// Send the changed Openflow entries to the OVS bridge.
 	if err := c.ofEntryOperations.AddAll(allFlows); err != nil {
 		// Reset the global conjunctive match flow cache since the OpenFlow bundle, which contains
 		// all the match flows to be installed, was not applied successfully.
 		c.featureNetworkPolicy.globalConjMatchFlowCache = map[string]*conjMatchFlowContext{}
+		c.featureNetworkPolicy.uninstallLogEntries(conjunctions)
 		return err
 	}
 	// Update conjMatchFlowContexts as the expected status.
@@ -1450,16 +1503,43 @@ func (f *featureNetworkPolicy) getPolicyRuleConjunction(ruleID uint32) *policyRu
 	return conj.(*policyRuleConjunction)
 }
 
-func (c *client) GetPolicyInfoFromConjunction(ruleID uint32) (string, string) {
+func (c *client) GetPolicyInfoFromConjunction(ruleID uint32) (*v1beta2.NetworkPolicyReference, string, string) {
 	conjunction := c.featureNetworkPolicy.getPolicyRuleConjunction(ruleID)
 	if conjunction == nil {
-		return "", ""
+		return nil, "", ""
 	}
 	priorities := conjunction.ActionFlowPriorities()
 	if len(priorities) == 0 {
-		return "", ""
+		return nil, "", ""
+	}
+	return conjunction.npRef, priorities[0], conjunction.ruleName
+}
+
+func (c *client) GetPolicyLogSuppressedPackets() (map[uint32]uint64, error) {
+	meterStatsList, err := c.ovsctlClient.DumpMeterStats()
+	if err != nil {
+		return nil, fmt.Errorf("error when dumping meter stats: %v", err)
+	}
+	groupStatsList, err := c.ovsctlClient.DumpGroupStats()
+	if err != nil {
+		return nil, fmt.Errorf("error when dumping group stats: %v", err)
 	}
-	return conjunction.npRef.ToString(), priorities[0]
+	suppressedPackets := map[uint32]uint64{}
+	for _, meterStats := range meterStatsList {
+		if meterStats.ID <= PolicyLogMeterIDBase || len(meterStats.BandPacketCounts) == 0 {
+			continue
+		}
+		// The log meter has a single drop band, which counts the packets exceeding the rate.
+		suppressedPackets[meterStats.ID-PolicyLogMeterIDBase] += meterStats.BandPacketCounts[0]
+	}
+	for _, groupStats := range groupStatsList {
+		if groupStats.ID <= PolicyLogGroupIDBase || len(groupStats.BucketPacketCounts) < 2 {
+			continue
+		}
+		// The second bucket of the log group counts the packets which are not sampled.
+		suppressedPackets[groupStats.ID-PolicyLogGroupIDBase] += groupStats.BucketPacketCounts[1]
+	}
+	return suppressedPackets, nil
 }
 
 // UninstallPolicyRuleFlows removes the Openflow entry relevant to the specified NetworkPolicy rule.
@@ -1482,6 +1562,12 @@ func (c *client) UninstallPolicyRuleFlows(ruleID uint32) ([]string, error) {
 	if err := c.ofEntryOperations.DeleteAll(conj.metricFlows); err != nil {
 		return nil, err
 	}
+	if conj.logGroup != nil && !c.bridge.DeleteGroup(policyLogGroupID(conj.id)) {
+		return nil, fmt.Errorf("error when deleting log group of policy rule %d", conj.id)
+	}
+	if conj.logMeter != nil && !c.bridge.DeleteMeter(policyLogMeterID(conj.id)) {
+		return nil, fmt.Errorf("error when deleting log meter of policy rule %d", conj.id)
+	}
 
 	c.featureNetworkPolicy.conjMatchFlowLock.Lock()
 	defer c.featureNetworkPolicy.conjMatchFlowLock.Unlock()
@@ -1525,6 +1611,63 @@ func (f *featureNetworkPolicy) getStalePriorities(conj *policyRuleConjunction) (
 	return staleOFPriorities
 }
 
+// installLogEntries installs the log meters and the log groups of the given policyRuleConjunctions. If any of them
+// fails to be installed, the installed ones are removed.
+func (f *featureNetworkPolicy) installLogEntries(conjunctions []*policyRuleConjunction) error {
+	for i, conj := range conjunctions {
+		if conj.logMeter != nil {
+			if err := conj.logMeter.Add(); err != nil {
+				f.uninstallLogEntries(conjunctions[:i])
+				return fmt.Errorf("error when installing log meter of policy rule %d: %w", conj.id, err)
+			}
+		}
+		if conj.logGroup != nil {
+			if err := conj.logGroup.Add(); err != nil {
+				f.uninstallLogEntries(conjunctions[:i+1])
+				return fmt.Errorf("error when installing log group of policy rule %d: %w", conj.id, err)
+			}
+		}
+	}
+	return nil
+}
+
+func (f *featureNetworkPolicy) uninstallLogEntries(conjunctions []*policyRuleConjunction) {
+	for _, conj := range conjunctions {
+		if conj.logGroup != nil && !f.bridge.DeleteGroup(policyLogGroupID(conj.id)) {
+			klog.ErrorS(nil, "Error when deleting log group of policy rule", "conjunctionID", conj.id)
+		}
+		if conj.logMeter != nil && !f.bridge.DeleteMeter(policyLogMeterID(conj.id)) {
+			klog.ErrorS(nil, "Error when deleting log meter of policy rule", "conjunctionID", conj.id)
+		}
+	}
+}
+
+func (f *featureNetworkPolicy) replayMeters() {
+	for _, obj := range f.policyCache.List() {
+		conj := obj.(*policyRuleConjunction)
+		if conj.logMeter == nil {
+			continue
+		}
+		conj.logMeter.Reset()
+		if err := conj.logMeter.Add(); err != nil {
+			klog.ErrorS(err, "Error when replaying cached log meter of policy rule", "conjunctionID", conj.id)
+		}
+	}
+}
+
+func (f *featureNetworkPolicy) replayGroups() {
+	for _, obj := range f.policyCache.List() {
+		conj := obj.(*policyRuleConjunction)
+		if conj.logGroup == nil {
+			continue
+		}
+		conj.logGroup.Reset()
+		if err := conj.logGroup.Add(); err != nil {
+			klog.ErrorS(err, "Error when replaying cached log group of policy rule", "conjunctionID", conj.id)
+		}
+	}
+}
+
 func (f *featureNetworkPolicy) replayFlows() []binding.Flow {
 	var flows []binding.Flow
 	addActionFlows := func(conj *policyRuleConjunction) {
@@ -37,13 +37,22 @@ type PacketInHandler interface {
 
 const (
 	// We use OpenFlow Meter for packet-in rate limiting on OVS side.
-	// Meter Entry ID.
-	PacketInMeterIDNP = 1
-	PacketInMeterIDTF = 2
+	// Meter Entry ID. Meter Entry IDs from 1 to 255 are reserved for Egress
+	// QoS, as the ID of an Egress QoS meter is the mark of the Egress IP.
+	PacketInMeterIDNP = 256
+	PacketInMeterIDTF = 257
 	// Meter Entry Rate. It is represented as number of events per second.
 	// Packets which exceed the rate will be dropped.
 	PacketInMeterRateNP = 100
 	PacketInMeterRateTF = 100
+	// Meter Entry IDs greater than PolicyLogMeterIDBase are used to rate limit
+	// the logging of individual Antrea-native policy rules. The ID of such a
+	// meter is PolicyLogMeterIDBase plus the conjunction ID of the rule.
+	PolicyLogMeterIDBase = 1 << 24
+	// Group Entry IDs greater than PolicyLogGroupIDBase are used to sample and
+	// rate limit the logging of individual Antrea-native policy rules. They
+	// don't overlap with the IDs allocated to Service groups.
+	PolicyLogGroupIDBase = 0x20000000
 
 	// PacketIn reasons
 	PacketInReasonTF ofpPacketInReason = 1
@@ -153,6 +153,7 @@ var (
 	// Tables in stageRouting:
 	L3ForwardingTable = newTable("L3Forwarding", stageRouting, pipelineIP)
 	EgressMarkTable   = newTable("EgressMark", stageRouting, pipelineIP)
+	EgressQoSTable    = newTable("EgressQoS", stageRouting, pipelineIP)
 	L3DecTTLTable     = newTable("L3DecTTL", stageRouting, pipelineIP)
 
 	// Tables in stagePostRouting:
@@ -208,6 +209,9 @@ var (
 	priorityDNSIntercept    = uint16(64991)
 	priorityDNSBypass       = uint16(64992)
 
+	// Idle timeout (in seconds) of the flows learned on the ingress Node for the connections of Services in DSR mode.
+	dsrConnectionIdleTimeout = uint16(300)
+
 	// Index for priority cache
 	priorityIndex = "priority"
 
@@ -1727,7 +1731,9 @@ func (f *featurePodConnectivity) ipv6Flows() []binding.Flow {
 
 // For normal traffic, conjunctionActionFlow generates the flow to jump to a specific table if policyRuleConjunction ID is matched. Priority of
 // conjunctionActionFlow is created at priorityLow for k8s network policies, and *priority assigned by PriorityAssigner for AntreaPolicy.
-func (f *featureNetworkPolicy) conjunctionActionFlow(conjunctionID uint32, table binding.Table, nextTable uint8, priority *uint16, enableLogging bool) []binding.Flow {
+// If logGroupID is not zero, the packets are sent to the controller for logging by the log group of the rule.
+func (f *featureNetworkPolicy) conjunctionActionFlow(conjunctionID uint32, table binding.Table, nextTable uint8, priority *uint16,
+	enableLogging bool, logGroupID binding.GroupIDType) []binding.Flow {
 	tableID := table.GetID()
 	cookieID := f.cookieAllocator.Request(f.category).Raw()
 	var ofPriority uint16
@@ -1748,6 +1754,18 @@ func (f *featureNetworkPolicy) conjunctionActionFlow(conjunctionID uint32, table
 		if proto == binding.ProtocolIPv6 {
 			ctZone = CtZoneV6
 		}
+		if enableLogging && logGroupID != 0 {
+			return table.BuildFlow(ofPriority).MatchProtocol(proto).
+				MatchConjID(conjunctionID).
+				Action().LoadToRegField(conjReg, conjunctionID). // Traceflow.
+				Action().LoadRegMark(DispositionAllowRegMark).   // AntreaPolicy.
+				Action().Group(logGroupID).
+				Action().CT(true, nextTable, ctZone, f.ctZoneSrcField). // CT action requires commit flag if actions other than NAT without arguments are specified.
+				LoadToLabelField(uint64(conjunctionID), labelField).
+				CTDone().
+				Cookie(cookieID).
+				Done()
+		}
 		if enableLogging {
 			fb := table.BuildFlow(ofPriority).MatchProtocol(proto).
 				MatchConjID(conjunctionID)
@@ -1794,9 +1812,10 @@ func (f *featureNetworkPolicy) conjunctionActionFlow(conjunctionID uint32, table
 }
 
 // conjunctionActionDenyFlow generates the flow to mark the packet to be denied (dropped or rejected) if policyRuleConjunction
-// ID is matched. Any matched flow will be dropped in corresponding metric tables.
+// ID is matched. Any matched flow will be dropped in corresponding metric tables. If logGroupID is not zero, the packets
+// are sent to the controller for logging by the log group of the rule instead of this flow.
 func (f *featureNetworkPolicy) conjunctionActionDenyFlow(conjunctionID uint32, table binding.Table, priority *uint16,
-	disposition uint32, enableLogging bool) binding.Flow {
+	disposition uint32, enableLogging bool, logGroupID binding.GroupIDType) binding.Flow {
 	ofPriority := *priority
 	metricTable := IngressMetricTable
 	tableID := table.GetID()
@@ -1822,7 +1841,9 @@ func (f *featureNetworkPolicy) conjunctionActionDenyFlow(conjunctionID uint32, t
 			Action().LoadToRegField(APDispositionField, disposition)
 	}
 	if enableLogging {
-		customReason += CustomReasonLogging
+		if logGroupID == 0 {
+			customReason += CustomReasonLogging
+		}
 		flowBuilder = flowBuilder.
 			Action().LoadToRegField(APDispositionField, disposition)
 	}
@@ -1830,7 +1851,7 @@ func (f *featureNetworkPolicy) conjunctionActionDenyFlow(conjunctionID uint32, t
 		customReason += CustomReasonReject
 	}
 
-	if enableLogging || f.enableDenyTracking || disposition == DispositionRej {
+	if customReason != 0 {
 		if f.ovsMetersAreSupported {
 			flowBuilder = flowBuilder.Action().Meter(PacketInMeterIDNP)
 		}
@@ -1838,6 +1859,9 @@ func (f *featureNetworkPolicy) conjunctionActionDenyFlow(conjunctionID uint32, t
 			Action().LoadToRegField(CustomReasonField, uint32(customReason)).
 			Action().SendToController(uint8(PacketInReasonNP))
 	}
+	if enableLogging && logGroupID != 0 {
+		flowBuilder = flowBuilder.Action().Group(logGroupID)
+	}
 
 	// We do not drop the packet immediately but send the packet to the metric table to update the rule metrics.
 	return flowBuilder.Action().GotoTable(metricTable.GetID()).
@@ -2141,14 +2165,36 @@ func (f *featureEgress) snatSkipNodeFlow(nodeIP net.IP) binding.Flow {
 // packet's tunnel destination IP.
 func (f *featureEgress) snatIPFromTunnelFlow(snatIP net.IP, mark uint32) binding.Flow {
 	ipProtocol := getIPProtocol(snatIP)
-	return EgressMarkTable.ofTable.BuildFlow(priorityNormal).
+	fb := EgressMarkTable.ofTable.BuildFlow(priorityNormal).
 		Cookie(f.cookieAllocator.Request(f.category).Raw()).
 		MatchProtocol(ipProtocol).
-		MatchCTStateNew(true).
 		MatchCTStateTrk(true).
 		MatchTunnelDst(snatIP).
 		Action().LoadPktMarkRange(mark, snatPktMarkRange).
-		Action().LoadRegMark(ToGatewayRegMark).
+		Action().LoadRegMark(ToGatewayRegMark)
+	return f.withEgressQoS(fb)
+}
+
+// withEgressQoS completes a flow which marks the packets to be SNAT'd. When Egress traffic shaping is enabled, all the
+// packets of the connection are marked and forwarded to EgressQoSTable, where the rate limit of the Egress IP is
+// applied; otherwise, only the first packet of the connection is marked and forwarded to stageSwitching directly.
+func (f *featureEgress) withEgressQoS(fb binding.FlowBuilder) binding.Flow {
+	if f.enableEgressTrafficShaping {
+		return fb.Action().GotoTable(EgressQoSTable.GetID()).
+			Done()
+	}
+	return fb.MatchCTStateNew(true).
+		Action().GotoStage(stageSwitching).
+		Done()
+}
+
+// egressQoSFlow generates the flow that applies the meter to the packets marked with the given mark of a local Egress
+// IP. The meter ID is the same as the mark.
+func (f *featureEgress) egressQoSFlow(mark uint32) binding.Flow {
+	return EgressQoSTable.ofTable.BuildFlow(priorityNormal).
+		Cookie(f.cookieAllocator.Request(f.category).Raw()).
+		MatchPktMark(mark, &types.SNATIPMarkMask).
+		Action().Meter(mark).
 		Action().GotoStage(stageSwitching).
 		Done()
 }
@@ -2161,16 +2207,14 @@ func (f *featureEgress) snatRuleFlow(ofPort uint32, snatIP net.IP, snatMark uint
 	ipProtocol := getIPProtocol(snatIP)
 	if snatMark != 0 {
 		// Local SNAT IP.
-		return EgressMarkTable.ofTable.BuildFlow(priorityNormal).
+		fb := EgressMarkTable.ofTable.BuildFlow(priorityNormal).
 			Cookie(cookieID).
 			MatchProtocol(ipProtocol).
-			MatchCTStateNew(true).
 			MatchCTStateTrk(true).
 			MatchInPort(ofPort).
 			Action().LoadPktMarkRange(snatMark, snatPktMarkRange).
-			Action().LoadRegMark(ToGatewayRegMark).
-			Action().GotoStage(stageSwitching).
-			Done()
+			Action().LoadRegMark(ToGatewayRegMark)
+		return f.withEgressQoS(fb)
 	}
 	// SNAT IP should be on a remote Node.
 	return EgressMarkTable.ofTable.BuildFlow(priorityNormal).
@@ -2223,6 +2267,7 @@ func (f *featureService) serviceLearnFlow(groupID binding.GroupIDType,
 	svcPort uint16,
 	protocol binding.Protocol,
 	affinityTimeout uint16,
+	affinityOptions types.SessionAffinityOptions,
 	nodeLocalExternal bool,
 	svcType v1.ServiceType) binding.Flow {
 	// Using unique cookie ID here to avoid learned flow cascade deletion.
@@ -2244,26 +2289,25 @@ func (f *featureService) serviceLearnFlow(groupID binding.GroupIDType,
 			MatchDstPort(svcPort, nil)
 	}
 
-	// affinityTimeout is used as the OpenFlow "hard timeout": learned flow will be removed from
-	// OVS after that time regarding of whether traffic is still hitting the flow. This is the
+	// By default, affinityTimeout is used as the OpenFlow "hard timeout": learned flow will be removed
+	// from OVS after that time regarding of whether traffic is still hitting the flow. This is the
 	// desired behavior based on the K8s spec. Note that existing connections will keep going to
 	// the same endpoint because of connection tracking; and that is also the desired behavior.
+	// If the Service requests an idle timeout, affinityTimeout is used as the OpenFlow "idle timeout"
+	// instead, so that the affinity of an active client is never reset.
+	idleTimeout, hardTimeout := uint16(0), affinityTimeout
+	if affinityOptions.IdleTimeout {
+		idleTimeout, hardTimeout = affinityTimeout, 0
+	}
 	learnFlowBuilderLearnAction := flowBuilder.
-		Action().Learn(SessionAffinityTable.GetID(), priorityNormal, 0, affinityTimeout, cookieID).
+		Action().Learn(SessionAffinityTable.GetID(), priorityNormal, idleTimeout, hardTimeout, cookieID).
 		DeleteLearned()
-	switch protocol {
-	case binding.ProtocolTCP:
-		learnFlowBuilderLearnAction = learnFlowBuilderLearnAction.MatchLearnedTCPDstPort()
-	case binding.ProtocolUDP:
-		learnFlowBuilderLearnAction = learnFlowBuilderLearnAction.MatchLearnedUDPDstPort()
-	case binding.ProtocolSCTP:
-		learnFlowBuilderLearnAction = learnFlowBuilderLearnAction.MatchLearnedSCTPDstPort()
-	case binding.ProtocolTCPv6:
-		learnFlowBuilderLearnAction = learnFlowBuilderLearnAction.MatchLearnedTCPv6DstPort()
-	case binding.ProtocolUDPv6:
-		learnFlowBuilderLearnAction = learnFlowBuilderLearnAction.MatchLearnedUDPv6DstPort()
-	case binding.ProtocolSCTPv6:
-		learnFlowBuilderLearnAction = learnFlowBuilderLearnAction.MatchLearnedSCTPv6DstPort()
+	// The destination port is always matched in the learned flow, as the learned flows of all the ports of a Service
+	// share the same table, and the Endpoint port loaded by the learned flow only applies to the current Service port.
+	if affinityOptions.Key.Has(types.SessionAffinityKeySrcPort) {
+		learnFlowBuilderLearnAction = learnFlowBuilderLearnAction.MatchTransportSrcAndDst(protocol)
+	} else {
+		learnFlowBuilderLearnAction = learnFlowBuilderLearnAction.MatchTransportDst(protocol)
 	}
 	// If externalTrafficPolicy of NodePort/LoadBalancer is Cluster, the learned flow which
 	// is used to match the first packet of NodePort/LoadBalancer also requires SNAT.
@@ -2272,10 +2316,13 @@ func (f *featureService) serviceLearnFlow(groupID binding.GroupIDType,
 	}
 
 	ipProtocol := getIPProtocol(svcIP)
+	matchSrcIP := affinityOptions.Key.Has(types.SessionAffinityKeySrcIP)
 	if ipProtocol == binding.ProtocolIP {
+		learnFlowBuilderLearnAction = learnFlowBuilderLearnAction.MatchLearnedDstIP()
+		if matchSrcIP {
+			learnFlowBuilderLearnAction = learnFlowBuilderLearnAction.MatchLearnedSrcIP()
+		}
 		return learnFlowBuilderLearnAction.
-			MatchLearnedDstIP().
-			MatchLearnedSrcIP().
 			LoadFieldToField(EndpointIPField, EndpointIPField).
 			LoadFieldToField(EndpointPortField, EndpointPortField).
 			LoadRegMark(EpSelectedRegMark).
@@ -2285,9 +2332,11 @@ func (f *featureService) serviceLearnFlow(groupID binding.GroupIDType,
 			Action().NextTable().
 			Done()
 	} else if ipProtocol == binding.ProtocolIPv6 {
+		learnFlowBuilderLearnAction = learnFlowBuilderLearnAction.MatchLearnedDstIPv6()
+		if matchSrcIP {
+			learnFlowBuilderLearnAction = learnFlowBuilderLearnAction.MatchLearnedSrcIPv6()
+		}
 		return learnFlowBuilderLearnAction.
-			MatchLearnedDstIPv6().
-			MatchLearnedSrcIPv6().
 			LoadXXRegToXXReg(EndpointIP6Field, EndpointIP6Field).
 			LoadFieldToField(EndpointPortField, EndpointPortField).
 			LoadRegMark(EpSelectedRegMark).
@@ -2351,6 +2400,102 @@ func (f *featureService) serviceLBFlow(groupID binding.GroupIDType,
 		Action().Group(groupID).Done()
 }
 
+// serviceDSRFlows generates the flows which do Endpoint selection for the packets destined for a LoadBalancer IP of a
+// Service in DSR mode. The packets sourced from the Antrea gateway are load-balanced to all Endpoints with the specific
+// group, and loaded with DSRServiceRegMark so that they will not be DNATed if the selected Endpoint is on a remote Node.
+// The packets forwarded by the ingress Node via tunnel are load-balanced to local Endpoints with the local group, then
+// they are DNATed and committed on this Node, and the reply packets are sent to the client directly.
+func (f *featureService) serviceDSRFlows(groupID, localGroupID binding.GroupIDType,
+	svcIP net.IP,
+	svcPort uint16,
+	protocol binding.Protocol) []binding.Flow {
+	cookieID := f.cookieAllocator.Request(f.category).Raw()
+	return []binding.Flow{
+		ServiceLBTable.ofTable.BuildFlow(priorityHigh).
+			Cookie(cookieID).
+			MatchProtocol(protocol).
+			MatchDstPort(svcPort, nil).
+			MatchDstIP(svcIP).
+			MatchRegMark(EpToSelectRegMark, FromGatewayRegMark).
+			Action().LoadRegMark(EpSelectedRegMark, RewriteMACRegMark, DSRServiceRegMark).
+			Action().LoadToRegField(ServiceGroupIDField, uint32(groupID)).
+			Action().Group(groupID).
+			Done(),
+		ServiceLBTable.ofTable.BuildFlow(priorityHigh).
+			Cookie(cookieID).
+			MatchProtocol(protocol).
+			MatchDstPort(svcPort, nil).
+			MatchDstIP(svcIP).
+			MatchRegMark(EpToSelectRegMark, FromTunnelRegMark).
+			Action().LoadRegMark(EpSelectedRegMark, RewriteMACRegMark).
+			Action().LoadToRegField(ServiceGroupIDField, uint32(localGroupID)).
+			Action().Group(localGroupID).
+			Done(),
+	}
+}
+
+// endpointDSRFlow generates the flow which matches the packets of Services in DSR mode whose selected Endpoint is on a
+// remote Node. As the connections are not committed on the ingress Node, instead of performing DNAT, the flow learns a
+// flow matching the connection in SessionAffinityTable to make the subsequent packets select the same Endpoint, then
+// forwards the packets to the next table with the destination IP unchanged.
+func (f *featureService) endpointDSRFlow(endpointIP net.IP, endpointPort uint16, protocol binding.Protocol) binding.Flow {
+	unionVal := (EpSelectedRegMark.GetValue() << EndpointPortField.GetRange().Length()) + uint32(endpointPort)
+	ipVal := binary.BigEndian.Uint32(endpointIP.To4())
+	// Using unique cookie ID here to avoid learned flow cascade deletion.
+	cookieID := f.cookieAllocator.RequestWithObjectID(f.category, ipVal).Raw()
+	return EndpointDNATTable.ofTable.BuildFlow(priorityHigh).
+		Cookie(cookieID).
+		MatchProtocol(protocol).
+		MatchRegMark(DSRServiceRegMark).
+		MatchRegFieldWithValue(EpUnionField, unionVal).
+		MatchRegFieldWithValue(EndpointIPField, ipVal).
+		Action().Learn(SessionAffinityTable.GetID(), priorityHigh, dsrConnectionIdleTimeout, 0, cookieID).
+		DeleteLearned().
+		MatchTransportSrcAndDst(protocol).
+		MatchLearnedSrcIP().
+		MatchLearnedDstIP().
+		LoadFieldToField(EndpointIPField, EndpointIPField).
+		LoadFieldToField(EndpointPortField, EndpointPortField).
+		LoadRegMark(EpSelectedRegMark).
+		LoadRegMark(RewriteMACRegMark).
+		LoadRegMark(DSRServiceRegMark).
+		Done().
+		Action().GotoTable(EndpointDNATTable.GetNext()).
+		Done()
+}
+
+// l3FwdFlowToRemoteEndpointViaTun generates the flow to forward the packets of Services in DSR mode to the remote Node
+// via tunnel if the selected Endpoint is in the Node's Pod subnet. As the packets are not DNATed, the flow matches the
+// network bits of the selected Endpoint IP instead of the destination IP.
+func (f *featureService) l3FwdFlowToRemoteEndpointViaTun(localGatewayMAC net.HardwareAddr, peerSubnet net.IPNet, tunnelPeer net.IP) binding.Flow {
+	prefixLength, bits := peerSubnet.Mask.Size()
+	endpointSubnetField := binding.NewRegField(EndpointIPField.GetRegID(), uint32(bits-prefixLength), uint32(bits-1), "EndpointSubnet")
+	subnetVal := binary.BigEndian.Uint32(peerSubnet.IP.To4()) >> (bits - prefixLength)
+	return L3ForwardingTable.ofTable.BuildFlow(priorityHigh).
+		Cookie(f.cookieAllocator.Request(f.category).Raw()).
+		MatchProtocol(binding.ProtocolIP).
+		MatchRegMark(DSRServiceRegMark).
+		MatchRegFieldWithValue(endpointSubnetField, subnetVal).
+		Action().SetSrcMAC(localGatewayMAC).
+		Action().SetDstMAC(GlobalVirtualMAC).
+		Action().SetTunnelDst(tunnelPeer).
+		Action().LoadRegMark(ToTunnelRegMark).
+		Action().GotoTable(L3DecTTLTable.GetID()).
+		Done()
+}
+
+// dsrServiceNoConntrackFlow generates the flow to match the packets of Services in DSR mode and forward them to the next
+// table directly, so that the connections are not committed on the ingress Node, as the reply packets will not go
+// through it. The connections whose selected Endpoint is on the local Node have been committed when performing DNAT.
+func (f *featureService) dsrServiceNoConntrackFlow() binding.Flow {
+	return ConntrackCommitTable.ofTable.BuildFlow(priorityHigh).
+		Cookie(f.cookieAllocator.Request(f.category).Raw()).
+		MatchProtocol(binding.ProtocolIP).
+		MatchRegMark(DSRServiceRegMark).
+		Action().GotoTable(ConntrackCommitTable.GetNext()).
+		Done()
+}
+
 // endpointDNATFlow generates the flow which transforms the Service Cluster IP to the Endpoint IP according to the Endpoint
 // selection decision which is stored in regs.
 func (f *featureService) endpointDNATFlow(endpointIP net.IP, endpointPort uint16, protocol binding.Protocol) binding.Flow {
@@ -2417,6 +2562,36 @@ func (f *featureService) serviceEndpointGroup(groupID binding.GroupIDType, withS
 	return group
 }
 
+// serviceMaglevGroup generates the group for Service LB with the lookup table computed by the Maglev algorithm. Each
+// entry of the lookup table is a bucket of the group, and a bucket is selected with the hash of the 5-tuple of the
+// packet, so that all Nodes select the same Endpoint for a connection as long as they have the same lookup table.
+func (f *featureService) serviceMaglevGroup(groupID binding.GroupIDType, withSessionAffinity bool, lookupTable ...proxy.Endpoint) binding.Group {
+	ipProtocol := binding.ProtocolIP
+	if len(lookupTable) > 0 {
+		ipProtocol = getIPProtocol(net.ParseIP(lookupTable[0].IP()))
+	}
+	return f.serviceEndpointGroup(groupID, withSessionAffinity, lookupTable...).SelectionMethodHash(ipProtocol, binding.HashFieldsFiveTuple)
+}
+
+// serviceAffinityHashGroup generates the group for Service LB with session affinity maintained by hashing. A bucket
+// is selected with the hash of the packet fields in the session affinity key, so that all packets from a client are
+// sent to the same Endpoint without learned flows. The destination fields are not hashed, so that a client selects
+// the same Endpoint for all the IPs of a Service port.
+func (f *featureService) serviceAffinityHashGroup(groupID binding.GroupIDType, affinityKey types.SessionAffinityKey, endpoints ...proxy.Endpoint) binding.Group {
+	ipProtocol := binding.ProtocolIP
+	if len(endpoints) > 0 {
+		ipProtocol = getIPProtocol(net.ParseIP(endpoints[0].IP()))
+	}
+	var fields binding.HashFields
+	if affinityKey.Has(types.SessionAffinityKeySrcIP) {
+		fields |= binding.HashFieldSrcIP
+	}
+	if affinityKey.Has(types.SessionAffinityKeySrcPort) {
+		fields |= binding.HashFieldSrcPort
+	}
+	return f.serviceEndpointGroup(groupID, false, endpoints...).SelectionMethodHash(ipProtocol, fields)
+}
+
 // decTTLFlows generates the flow to process TTL. For the packets forwarded across Nodes, TTL should be decremented by one;
 // for packets which enter OVS pipeline from the Antrea gateway, as the host IP stack should have decremented the TTL
 // already for such packets, TTL should not be decremented again.
@@ -2500,6 +2675,13 @@ func (f *featureEgress) externalFlows() []binding.Flow {
 		Action().LoadRegMark(ToGatewayRegMark).
 		Action().GotoStage(stageSwitching).
 		Done())
+	if f.enableEgressTrafficShaping {
+		// This generates the flow to forward the packets which are not rate limited by any Egress to stageSwitching.
+		flows = append(flows, EgressQoSTable.ofTable.BuildFlow(priorityMiss).
+			Cookie(cookieID).
+			Action().GotoStage(stageSwitching).
+			Done())
+	}
 
 	return flows
 }
@@ -2521,7 +2703,11 @@ func priorityIndexFunc(obj interface{}) ([]string, error) {
 // `rate` is represented as number of packets per second.
 // Packets which exceed the rate will be dropped.
 func (c *client) genPacketInMeter(meterID binding.MeterIDType, rate uint32) binding.Meter {
-	meter := c.bridge.CreateMeter(meterID, ofctrl.MeterBurst|ofctrl.MeterPktps).ResetMeterBands()
+	return newPacketInMeter(c.bridge, meterID, rate)
+}
+
+func newPacketInMeter(bridge binding.Bridge, meterID binding.MeterIDType, rate uint32) binding.Meter {
+	meter := bridge.CreateMeter(meterID, ofctrl.MeterBurst|ofctrl.MeterPktps).ResetMeterBands()
 	meter = meter.MeterBand().
 		MeterType(ofctrl.MeterDrop).
 		Rate(rate).
@@ -2530,6 +2716,54 @@ func (c *client) genPacketInMeter(meterID binding.MeterIDType, rate uint32) bind
 	return meter
 }
 
+// policyLogMeterID returns the ID of the meter used to rate limit the logging of the policy rule with the given
+// conjunction ID.
+func policyLogMeterID(conjunctionID uint32) binding.MeterIDType {
+	return binding.MeterIDType(PolicyLogMeterIDBase + conjunctionID)
+}
+
+// policyLogGroupID returns the ID of the group used to limit the logging of the policy rule with the given conjunction
+// ID.
+func policyLogGroupID(conjunctionID uint32) binding.GroupIDType {
+	return binding.GroupIDType(PolicyLogGroupIDBase + conjunctionID)
+}
+
+// policyLogGroup generates the group used to limit the logging of the policy rule with the given conjunction ID. As
+// the actions of a group bucket are applied to a copy of the packet, the limits never affect how the packet itself is
+// handled by the rule. The first bucket sends the packet to the controller for logging, rate limited by the log meter
+// if logMeterID is not zero, and then by the packet-in meter shared by all NetworkPolicy rules. If samplingRatio is
+// greater than 1, the second bucket, which does nothing, is selected for samplingRatio-1 out of samplingRatio
+// connections, as the bucket is selected with the hash of the connection.
+func (f *featureNetworkPolicy) policyLogGroup(conjunctionID uint32, logMeterID binding.MeterIDType, samplingRatio uint16) binding.Group {
+	bucket := f.bridge.CreateGroup(policyLogGroupID(conjunctionID)).ResetBuckets().
+		Bucket().Weight(1).
+		LoadToRegField(CustomReasonField, CustomReasonLogging)
+	if logMeterID != 0 {
+		bucket = bucket.Meter(uint32(logMeterID))
+	}
+	if f.ovsMetersAreSupported {
+		bucket = bucket.Meter(PacketInMeterIDNP)
+	}
+	group := bucket.SendToController(uint8(PacketInReasonNP)).Done()
+	if samplingRatio > 1 {
+		group = group.Bucket().Weight(samplingRatio - 1).Done()
+	}
+	return group
+}
+
+// genEgressQoSMeter generates a meter entry with specific meterID, rate and burst for Egress QoS.
+// `rate` is represented as kilobits per second, and `burst` is represented as kilobits.
+// Packets which exceed the rate will be dropped.
+func (c *client) genEgressQoSMeter(meterID binding.MeterIDType, rate, burst uint32) binding.Meter {
+	meter := c.bridge.CreateMeter(meterID, ofctrl.MeterBurst|ofctrl.MeterKbps).ResetMeterBands()
+	meter = meter.MeterBand().
+		MeterType(ofctrl.MeterDrop).
+		Rate(rate).
+		Burst(burst).
+		Done()
+	return meter
+}
+
 func generatePipeline(pipelineID binding.PipelineID, requiredTables []*Table) binding.Pipeline {
 	var ofTables []binding.Table
 	for _, table := range requiredTables {
@@ -49,7 +49,7 @@ func TestBuildPipeline(t *testing.T) {
 				&featurePodConnectivity{ipProtocols: ipStackMap[dualStack]},
 				&featureNetworkPolicy{enableAntreaPolicy: true},
 				&featureService{enableProxy: true, proxyAll: true},
-				&featureEgress{},
+				&featureEgress{enableEgressTrafficShaping: true},
 			},
 			expectedTables: map[binding.PipelineID][]*Table{
 				pipelineRoot: {
@@ -73,6 +73,7 @@ func TestBuildPipeline(t *testing.T) {
 					EgressMetricTable,
 					L3ForwardingTable,
 					EgressMarkTable,
+					EgressQoSTable,
 					L3DecTTLTable,
 					SNATMarkTable,
 					SNATTable,
@@ -17,6 +17,8 @@ package openflow
 import (
 	"net"
 
+	"antrea.io/libOpenflow/protocol"
+
 	"antrea.io/antrea/pkg/agent/config"
 	"antrea.io/antrea/pkg/agent/openflow/cookie"
 	"antrea.io/antrea/pkg/apis/crd/v1alpha2"
@@ -228,6 +230,77 @@ func (f *featurePodConnectivity) trafficControlMarkFlows(sourceOFPorts []uint32,
 	return flows
 }
 
+// packetCaptureMarkFlows generates the flows to mirror the packets of a PacketCapture request to the capture port.
+// The provided packet describes the packets sent from the provided port, and the reply packets sent to the port are
+// mirrored too. The flows have a higher priority than the flows of traffic control rules, so that the packets are
+// captured even if they are redirected or mirrored by a traffic control rule.
+func (f *featurePodConnectivity) packetCaptureMarkFlows(ofPort, captureOFPort uint32, packet *binding.Packet) []binding.Flow {
+	cookieID := f.cookieAllocator.Request(f.category).Raw()
+	matchPacket := func(fb binding.FlowBuilder, srcIP, dstIP net.IP, srcPort, dstPort uint16) binding.FlowBuilder {
+		switch packet.IPProto {
+		case 0:
+			if srcIP != nil || dstIP != nil {
+				if packet.IsIPv6 {
+					fb = fb.MatchProtocol(binding.ProtocolIPv6)
+				} else {
+					fb = fb.MatchProtocol(binding.ProtocolIP)
+				}
+			}
+		case protocol.Type_ICMP:
+			fb = fb.MatchProtocol(binding.ProtocolICMP)
+		case protocol.Type_IPv6ICMP:
+			fb = fb.MatchProtocol(binding.ProtocolICMPv6)
+		case protocol.Type_TCP:
+			if packet.IsIPv6 {
+				fb = fb.MatchProtocol(binding.ProtocolTCPv6)
+			} else {
+				fb = fb.MatchProtocol(binding.ProtocolTCP)
+			}
+		case protocol.Type_UDP:
+			if packet.IsIPv6 {
+				fb = fb.MatchProtocol(binding.ProtocolUDPv6)
+			} else {
+				fb = fb.MatchProtocol(binding.ProtocolUDP)
+			}
+		default:
+			fb = fb.MatchIPProtocolValue(packet.IsIPv6, packet.IPProto)
+		}
+		if srcIP != nil {
+			fb = fb.MatchSrcIP(srcIP)
+		}
+		if dstIP != nil {
+			fb = fb.MatchDstIP(dstIP)
+		}
+		if packet.IPProto == protocol.Type_TCP || packet.IPProto == protocol.Type_UDP {
+			if srcPort != 0 {
+				fb = fb.MatchSrcPort(srcPort, nil)
+			}
+			if dstPort != 0 {
+				fb = fb.MatchDstPort(dstPort, nil)
+			}
+		}
+		return fb
+	}
+	return []binding.Flow{
+		// This generates the flow to mirror the packets sent from the provided port.
+		matchPacket(TrafficControlTable.ofTable.BuildFlow(priorityNormal+1).
+			Cookie(cookieID).
+			MatchInPort(ofPort), packet.SourceIP, packet.DestinationIP, packet.SourcePort, packet.DestinationPort).
+			Action().LoadToRegField(TrafficControlTargetOFPortField, captureOFPort).
+			Action().LoadRegMark(TrafficControlMirrorRegMark).
+			Action().NextTable().
+			Done(),
+		// This generates the flow to mirror the reply packets destined for the provided port.
+		matchPacket(TrafficControlTable.ofTable.BuildFlow(priorityNormal+1).
+			Cookie(cookieID).
+			MatchRegFieldWithValue(TargetOFPortField, ofPort), packet.DestinationIP, packet.SourceIP, packet.DestinationPort, packet.SourcePort).
+			Action().LoadToRegField(TrafficControlTargetOFPortField, captureOFPort).
+			Action().LoadRegMark(TrafficControlMirrorRegMark).
+			Action().NextTable().
+			Done(),
+	}
+}
+
 // trafficControlReturnClassifierFlow generates the flow to mark the packets from traffic control return port and forward
 // the packets to stageRouting directly. Note that, for the packets which are originally to be output to a tunnel port,
 // value of NXM_NX_TUN_IPV4_DST for the returned packets needs to be loaded in stageRouting.
@@ -47,6 +47,7 @@ type featureService struct {
 	enableProxy           bool
 	proxyAll              bool
 	connectUplinkToBridge bool
+	enableDSR             bool
 	ctZoneSrcField        *binding.RegField
 
 	category cookie.Category
@@ -116,6 +117,7 @@ func newFeatureService(
 		enableProxy:            enableProxy,
 		proxyAll:               proxyAll,
 		connectUplinkToBridge:  connectUplinkToBridge,
+		enableDSR:              serviceConfig.EnableLoadBalancerModeDSR,
 		ctZoneSrcField:         getZoneSrcField(connectUplinkToBridge),
 		category:               cookie.Service,
 	}
@@ -137,6 +139,9 @@ func (f *featureService) initFlows() []binding.Flow {
 			// to mark the Service type of the packet as NodePort, and the mark is consumed in table serviceLBTable.
 			flows = append(flows, f.nodePortMarkFlows()...)
 		}
+		if f.enableDSR {
+			flows = append(flows, f.dsrServiceNoConntrackFlow())
+		}
 	} else {
 		// This installs the flows to enable Service connectivity. Upstream kube-proxy is leveraged to provide load-balancing,
 		// and the flows installed by this method ensure that traffic sent from local Pods to any Service address can be
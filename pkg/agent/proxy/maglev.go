@@ -0,0 +1,82 @@
+// Copyright 2022 Antrea Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package proxy
+
+import (
+	"hash/fnv"
+	"sort"
+
+	k8sproxy "antrea.io/antrea/third_party/proxy"
+)
+
+// maglevTableSize is the size of the Maglev lookup table, which must be a prime number. Each entry of the lookup table
+// is installed as a bucket of an OVS group, so the size is limited by the maximum size of an OpenFlow message. As
+// ovs-vswitchd scores every bucket to select one for a new connection (see BenchmarkMaglevBucketSelection), the size
+// is also a trade-off between the balance of the Endpoints and the cost of new connections.
+const maglevTableSize = 509
+
+// newMaglevLookupTable computes the lookup table of the Maglev consistent hashing algorithm for the given Endpoints,
+// as described in "Maglev: A Fast and Reliable Software Network Load Balancer". Each Endpoint fills the table
+// following its own permutation of the table entries, which only depends on the Endpoint itself. Therefore, all
+// Nodes compute the same lookup table for the same set of Endpoints, and when an Endpoint is added or removed, only a
+// small fraction of the entries are changed. The size must be a prime number larger than the number of Endpoints.
+func newMaglevLookupTable(endpoints []k8sproxy.Endpoint, size int) []k8sproxy.Endpoint {
+	n := len(endpoints)
+	if n == 0 {
+		return nil
+	}
+	// Sort the Endpoints so that the lookup table doesn't depend on the order in which they are provided.
+	sorted := make([]k8sproxy.Endpoint, n)
+	copy(sorted, endpoints)
+	sort.Slice(sorted, func(i, j int) bool {
+		return sorted[i].String() < sorted[j].String()
+	})
+
+	offsets := make([]uint64, n)
+	skips := make([]uint64, n)
+	for i, endpoint := range sorted {
+		h1 := fnv.New64a()
+		h1.Write([]byte(endpoint.String()))
+		h2 := fnv.New64()
+		h2.Write([]byte(endpoint.String()))
+		offsets[i] = h1.Sum64() % uint64(size)
+		skips[i] = h2.Sum64()%uint64(size-1) + 1
+	}
+
+	entries := make([]int, size)
+	for i := range entries {
+		entries[i] = -1
+	}
+	next := make([]uint64, n)
+	filled := 0
+	for filled < size {
+		for i := 0; i < n && filled < size; i++ {
+			c := (offsets[i] + next[i]*skips[i]) % uint64(size)
+			for entries[c] >= 0 {
+				next[i]++
+				c = (offsets[i] + next[i]*skips[i]) % uint64(size)
+			}
+			entries[c] = i
+			next[i]++
+			filled++
+		}
+	}
+
+	lookupTable := make([]k8sproxy.Endpoint, size)
+	for i, e := range entries {
+		lookupTable[i] = sorted[e]
+	}
+	return lookupTable
+}
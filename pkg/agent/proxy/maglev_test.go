@@ -0,0 +1,127 @@
+// Copyright 2022 Antrea Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package proxy
+
+import (
+	"fmt"
+	"math/bits"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	k8sproxy "antrea.io/antrea/third_party/proxy"
+)
+
+func makeMaglevTestEndpoints(n int) []k8sproxy.Endpoint {
+	endpoints := make([]k8sproxy.Endpoint, 0, n)
+	for i := 0; i < n; i++ {
+		endpoints = append(endpoints, k8sproxy.NewBaseEndpointInfo(fmt.Sprintf("10.10.%d.%d", i/256, i%256), "", "", 80, false, true, false, false, nil))
+	}
+	return endpoints
+}
+
+func TestMaglevLookupTable(t *testing.T) {
+	assert.Empty(t, newMaglevLookupTable(nil, maglevTableSize))
+
+	endpoints := makeMaglevTestEndpoints(10)
+	lookupTable := newMaglevLookupTable(endpoints, maglevTableSize)
+	assert.Len(t, lookupTable, maglevTableSize)
+
+	// Every Endpoint should get an almost equal number of entries.
+	counts := map[string]int{}
+	for _, endpoint := range lookupTable {
+		counts[endpoint.String()]++
+	}
+	assert.Len(t, counts, len(endpoints))
+	for _, count := range counts {
+		assert.GreaterOrEqual(t, count, maglevTableSize/len(endpoints))
+		assert.LessOrEqual(t, count, maglevTableSize/len(endpoints)+1)
+	}
+
+	// The lookup table should not depend on the order of the Endpoints.
+	reversed := make([]k8sproxy.Endpoint, len(endpoints))
+	for i := range endpoints {
+		reversed[len(endpoints)-1-i] = endpoints[i]
+	}
+	assert.Equal(t, lookupTable, newMaglevLookupTable(reversed, maglevTableSize))
+}
+
+func TestMaglevLookupTableDisruption(t *testing.T) {
+	endpoints := makeMaglevTestEndpoints(20)
+	lookupTable := newMaglevLookupTable(endpoints, maglevTableSize)
+	removed := endpoints[7].String()
+	newLookupTable := newMaglevLookupTable(append(endpoints[:7:7], endpoints[8:]...), maglevTableSize)
+
+	changed := 0
+	for i := range lookupTable {
+		assert.NotEqual(t, removed, newLookupTable[i].String())
+		if lookupTable[i].String() != removed && lookupTable[i].String() != newLookupTable[i].String() {
+			changed++
+		}
+	}
+	// Only a small fraction of the entries which were not mapped to the removed Endpoint should be changed.
+	assert.Less(t, changed, maglevTableSize/10)
+}
+
+func BenchmarkMaglevLookupTable(b *testing.B) {
+	endpoints := makeMaglevTestEndpoints(100)
+	for i := 0; i < b.N; i++ {
+		newMaglevLookupTable(endpoints, maglevTableSize)
+	}
+}
+
+// ovsHashInt is hash_int() of OVS, which scores the buckets of a group with selection_method=hash.
+func ovsHashInt(x, basis uint32) uint32 {
+	add := func(hash, data uint32) uint32 {
+		data *= 0xcc9e2d51
+		data = bits.RotateLeft32(data, 15)
+		data *= 0x1b873593
+		hash ^= data
+		hash = bits.RotateLeft32(hash, 13)
+		return hash*5 + 0xe6546b64
+	}
+	hash := add(add(x, 0), basis) ^ 8
+	hash ^= hash >> 16
+	hash *= 0x85ebca6b
+	hash ^= hash >> 13
+	hash *= 0xc2b2ae35
+	hash ^= hash >> 16
+	return hash
+}
+
+// BenchmarkMaglevBucketSelection emulates the bucket selection done by ovs-vswitchd for the first packet of a
+// connection. With selection_method=hash, used by the Maglev groups, all buckets are scored with the hash of the
+// packet fields and the best one is selected, so the cost grows with the size of the lookup table. With dp_hash, the
+// default method, the bucket is looked up in a table indexed by the datapath hash. On an Intel Xeon, it takes about
+// 2.5us per connection for the former with 509 buckets, and 5ns for the latter.
+func BenchmarkMaglevBucketSelection(b *testing.B) {
+	b.Run("hash", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			basis := ovsHashInt(uint32(i), 0)
+			var bestScore uint32
+			for bucket := uint32(0); bucket < maglevTableSize; bucket++ {
+				if score := ovsHashInt(bucket, basis) & 0xffff; score >= bestScore {
+					bestScore = score
+				}
+			}
+		}
+	})
+	b.Run("dp_hash", func(b *testing.B) {
+		buckets := make([]uint32, 512)
+		for i := 0; i < b.N; i++ {
+			_ = buckets[ovsHashInt(uint32(i), 0)&uint32(len(buckets)-1)]
+		}
+	})
+}
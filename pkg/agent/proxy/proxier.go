@@ -40,6 +40,7 @@ import (
 	"antrea.io/antrea/pkg/agent/proxy/metrics"
 	"antrea.io/antrea/pkg/agent/proxy/types"
 	"antrea.io/antrea/pkg/agent/route"
+	agenttypes "antrea.io/antrea/pkg/agent/types"
 	"antrea.io/antrea/pkg/features"
 	binding "antrea.io/antrea/pkg/ovs/openflow"
 	k8sproxy "antrea.io/antrea/third_party/proxy"
@@ -129,6 +130,9 @@ type proxier struct {
 	endpointSliceEnabled      bool
 	proxyLoadBalancerIPs      bool
 	topologyAwareHintsEnabled bool
+	loadBalancerDSREnabled    bool
+	// defaultLBAlgorithm is the algorithm used by the Services which don't specify one.
+	defaultLBAlgorithm types.LoadBalancerAlgorithm
 }
 
 func (p *proxier) SyncedOnce() bool {
@@ -181,7 +185,7 @@ func (p *proxier) removeStaleServices() {
 			}
 		}
 		// Remove Service group whose Endpoints are local.
-		if svcInfo.NodeLocalExternal() {
+		if svcInfo.NodeLocalExternal() || p.isLoadBalancerModeDSR(svcInfo) {
 			if groupIDLocal, exist := p.groupCounter.Get(svcPortName, true); exist {
 				if err := p.ofClient.UninstallServiceGroup(groupIDLocal); err != nil {
 					klog.ErrorS(err, "Failed to remove Group of local Endpoints for Service", "Service", svcPortName)
@@ -267,6 +271,47 @@ func (p *proxier) removeStaleEndpoints() {
 	}
 }
 
+// isLoadBalancerModeDSR returns whether the traffic destined for the LoadBalancer IPs of the Service should be
+// load-balanced in DSR mode. DSR mode is only supported for IPv4 Services whose externalTrafficPolicy is Cluster and
+// which don't use session affinity, and it requires proxyAll to be enabled. Otherwise, the traffic is load-balanced in
+// NAT mode.
+func (p *proxier) isLoadBalancerModeDSR(svcInfo *types.ServiceInfo) bool {
+	return p.loadBalancerDSREnabled && p.proxyAll && p.proxyLoadBalancerIPs && !p.isIPv6 &&
+		svcInfo.LoadBalancerMode() == types.LoadBalancerModeDSR &&
+		len(svcInfo.LoadBalancerIPStrings()) > 0 &&
+		!svcInfo.NodeLocalExternal() &&
+		svcInfo.SessionAffinityType() == corev1.ServiceAffinityNone
+}
+
+// loadBalancerAlgorithm returns the algorithm used to select an Endpoint for the traffic of the Service.
+func (p *proxier) loadBalancerAlgorithm(svcInfo *types.ServiceInfo) types.LoadBalancerAlgorithm {
+	if algorithm := svcInfo.LoadBalancerAlgorithm(); algorithm != "" {
+		return algorithm
+	}
+	return p.defaultLBAlgorithm
+}
+
+// installServiceGroup installs the group with the given Endpoints for the Service. If the Service uses the Maglev
+// algorithm, the group is installed with the Maglev lookup table computed from the Endpoints. If the session affinity
+// of the Service is maintained by hashing, the group selects an Endpoint with the hash of the session affinity key.
+func (p *proxier) installServiceGroup(svcInfo *types.ServiceInfo, groupID binding.GroupIDType, withSessionAffinity bool, endpoints []k8sproxy.Endpoint) error {
+	useMaglev := p.loadBalancerAlgorithm(svcInfo) == types.LoadBalancerAlgorithmMaglev
+	if useMaglev && len(endpoints) >= maglevTableSize {
+		klog.InfoS("Too many Endpoints for the Maglev lookup table, falling back to the default algorithm", "Service", svcInfo.String(), "endpoints", len(endpoints))
+		useMaglev = false
+	}
+	if withSessionAffinity && svcInfo.SessionAffinityOptions().Hash {
+		if useMaglev {
+			endpoints = newMaglevLookupTable(endpoints, maglevTableSize)
+		}
+		return p.ofClient.InstallServiceAffinityHashGroup(groupID, svcInfo.SessionAffinityOptions().Key, endpoints)
+	}
+	if useMaglev {
+		return p.ofClient.InstallServiceMaglevGroup(groupID, withSessionAffinity, newMaglevLookupTable(endpoints, maglevTableSize))
+	}
+	return p.ofClient.InstallServiceGroup(groupID, withSessionAffinity, endpoints)
+}
+
 func serviceIdentityChanged(svcInfo, pSvcInfo *types.ServiceInfo) bool {
 	return svcInfo.ClusterIP().String() != pSvcInfo.ClusterIP().String() ||
 		svcInfo.Port() != pSvcInfo.Port() ||
@@ -296,12 +341,12 @@ func smallSliceDifference(s1, s2 []string) []string {
 	return diff
 }
 
-func (p *proxier) installNodePortService(groupID binding.GroupIDType, svcPort uint16, protocol binding.Protocol, affinityTimeout uint16, nodeLocalExternal bool) error {
+func (p *proxier) installNodePortService(groupID binding.GroupIDType, svcPort uint16, protocol binding.Protocol, affinityTimeout uint16, affinityOptions agenttypes.SessionAffinityOptions, nodeLocalExternal bool) error {
 	svcIP := agentconfig.VirtualNodePortDNATIPv4
 	if p.isIPv6 {
 		svcIP = agentconfig.VirtualNodePortDNATIPv6
 	}
-	if err := p.ofClient.InstallServiceFlows(groupID, svcIP, svcPort, protocol, affinityTimeout, nodeLocalExternal, corev1.ServiceTypeNodePort); err != nil {
+	if err := p.ofClient.InstallServiceFlows(groupID, svcIP, svcPort, protocol, affinityTimeout, affinityOptions, nodeLocalExternal, corev1.ServiceTypeNodePort); err != nil {
 		return fmt.Errorf("failed to install Service NodePort load balancing flows: %w", err)
 	}
 	if err := p.routeClient.AddNodePort(p.nodePortAddresses, svcPort, protocol); err != nil {
@@ -324,12 +369,20 @@ func (p *proxier) uninstallNodePortService(svcPort uint16, protocol binding.Prot
 	return nil
 }
 
-func (p *proxier) installLoadBalancerService(groupID binding.GroupIDType, loadBalancerIPStrings []string, svcPort uint16, protocol binding.Protocol, affinityTimeout uint16, nodeLocalExternal bool) error {
+// installLoadBalancerService installs the flows and configurations for the LoadBalancer IPs of a Service. If isDSR is
+// true, it also installs the flows to load-balance the traffic in DSR mode, which uses the group with localGroupID to
+// select a local Endpoint for the traffic forwarded by the ingress Node.
+func (p *proxier) installLoadBalancerService(groupID, localGroupID binding.GroupIDType, loadBalancerIPStrings []string, svcPort uint16, protocol binding.Protocol, affinityTimeout uint16, affinityOptions agenttypes.SessionAffinityOptions, nodeLocalExternal, isDSR bool) error {
 	for _, ingress := range loadBalancerIPStrings {
 		if ingress != "" {
-			if err := p.ofClient.InstallServiceFlows(groupID, net.ParseIP(ingress), svcPort, protocol, affinityTimeout, nodeLocalExternal, corev1.ServiceTypeLoadBalancer); err != nil {
+			if err := p.ofClient.InstallServiceFlows(groupID, net.ParseIP(ingress), svcPort, protocol, affinityTimeout, affinityOptions, nodeLocalExternal, corev1.ServiceTypeLoadBalancer); err != nil {
 				return fmt.Errorf("failed to install Service LoadBalancer load balancing flows: %w", err)
 			}
+			if isDSR {
+				if err := p.ofClient.InstallServiceDSRFlows(groupID, localGroupID, net.ParseIP(ingress), svcPort, protocol); err != nil {
+					return fmt.Errorf("failed to install Service LoadBalancer DSR flows: %w", err)
+				}
+			}
 		}
 	}
 	if p.proxyAll {
@@ -378,13 +431,19 @@ func (p *proxier) installServices() {
 		installedSvcPort, ok := p.serviceInstalledMap[svcPortName]
 		var pSvcInfo *types.ServiceInfo
 		var needRemoval, needUpdateService, needUpdateEndpoints bool
+		isDSR := p.isLoadBalancerModeDSR(svcInfo)
 		if ok { // Need to update.
 			pSvcInfo = installedSvcPort.(*types.ServiceInfo)
-			needRemoval = serviceIdentityChanged(svcInfo, pSvcInfo) || (svcInfo.SessionAffinityType() != pSvcInfo.SessionAffinityType())
+			pIsDSR := p.isLoadBalancerModeDSR(pSvcInfo)
+			needRemoval = serviceIdentityChanged(svcInfo, pSvcInfo) || (svcInfo.SessionAffinityType() != pSvcInfo.SessionAffinityType()) ||
+				svcInfo.SessionAffinityOptions() != pSvcInfo.SessionAffinityOptions() || isDSR != pIsDSR
 			needUpdateService = needRemoval || (svcInfo.StickyMaxAgeSeconds() != pSvcInfo.StickyMaxAgeSeconds())
 			needUpdateEndpoints = pSvcInfo.SessionAffinityType() != svcInfo.SessionAffinityType() ||
 				pSvcInfo.NodeLocalExternal() != svcInfo.NodeLocalExternal() ||
-				pSvcInfo.NodeLocalInternal() != svcInfo.NodeLocalInternal()
+				pSvcInfo.NodeLocalInternal() != svcInfo.NodeLocalInternal() ||
+				isDSR != pIsDSR ||
+				p.loadBalancerAlgorithm(pSvcInfo) != p.loadBalancerAlgorithm(svcInfo) ||
+				pSvcInfo.SessionAffinityOptions() != svcInfo.SessionAffinityOptions()
 		} else { // Need to install.
 			needUpdateService = true
 		}
@@ -411,6 +470,13 @@ func (p *proxier) installServices() {
 			}
 		}
 
+		sessionAffinity := affinityTimeout != 0
+		if sessionAffinity && svcInfo.SessionAffinityOptions().Hash {
+			// The Endpoint is selected by the group with the hash of the session affinity key, so no learned flow
+			// is needed to remember it.
+			affinityTimeout = 0
+		}
+
 		var internalNodeLocal, externalNodeLocal bool
 		if svcInfo.NodeLocalInternal() {
 			internalNodeLocal = true
@@ -531,25 +597,26 @@ func (p *proxier) installServices() {
 				klog.ErrorS(err, "Error when installing Endpoints flows")
 				continue
 			}
-			if internalNodeLocal != externalNodeLocal {
-				if svcInfo.NodePort() > 0 {
+			if internalNodeLocal != externalNodeLocal || isDSR {
+				if svcInfo.NodePort() > 0 || isDSR {
 					// If the type of the Service is NodePort or LoadBalancer, when internalTrafficPolicy and externalTrafficPolicy
 					// of the Service are different, install two groups. One group has all Endpoints, the other has only
-					// local Endpoints.
+					// local Endpoints. A LoadBalancer Service in DSR mode also requires both groups, as the traffic
+					// forwarded by the ingress Node is load-balanced to local Endpoints.
 					groupID := p.groupCounter.AllocateIfNotExist(svcPortName, true)
-					if err = p.ofClient.InstallServiceGroup(groupID, affinityTimeout != 0, localEndpointUpdateList); err != nil {
+					if err = p.installServiceGroup(svcInfo, groupID, sessionAffinity, localEndpointUpdateList); err != nil {
 						klog.ErrorS(err, "Error when installing Group of local Endpoints for Service", "Service", svcPortName)
 						continue
 					}
 					groupID = p.groupCounter.AllocateIfNotExist(svcPortName, false)
-					if err = p.ofClient.InstallServiceGroup(groupID, affinityTimeout != 0, allEndpointUpdateList); err != nil {
+					if err = p.installServiceGroup(svcInfo, groupID, sessionAffinity, allEndpointUpdateList); err != nil {
 						klog.ErrorS(err, "Error when installing Group of all Endpoints for Service", "Service", svcPortName)
 						continue
 					}
 				} else {
 					// If the type of the Service is ClusterIP, install a group according to internalTrafficPolicy.
 					groupID := p.groupCounter.AllocateIfNotExist(svcPortName, internalNodeLocal)
-					if err = p.ofClient.InstallServiceGroup(groupID, affinityTimeout != 0, endpointUpdateList); err != nil {
+					if err = p.installServiceGroup(svcInfo, groupID, sessionAffinity, endpointUpdateList); err != nil {
 						klog.ErrorS(err, "Error when installing Group of Endpoints for Service", "Service", svcPortName)
 						continue
 					}
@@ -563,7 +630,7 @@ func (p *proxier) installServices() {
 				// only local Endpoints. Note that, if a group doesn't exist on OVS, then the return value will be nil.
 				nodeLocalVal := internalNodeLocal && externalNodeLocal
 				groupID := p.groupCounter.AllocateIfNotExist(svcPortName, nodeLocalVal)
-				if err = p.ofClient.InstallServiceGroup(groupID, affinityTimeout != 0, endpointUpdateList); err != nil {
+				if err = p.installServiceGroup(svcInfo, groupID, sessionAffinity, endpointUpdateList); err != nil {
 					klog.ErrorS(err, "Error when installing Group of local Endpoints for Service", "Service", svcPortName)
 					continue
 				}
@@ -615,7 +682,7 @@ func (p *proxier) installServices() {
 
 			// Install ClusterIP flows for the Service.
 			groupID := p.groupCounter.AllocateIfNotExist(svcPortName, internalNodeLocal)
-			if err := p.ofClient.InstallServiceFlows(groupID, svcInfo.ClusterIP(), uint16(svcInfo.Port()), svcInfo.OFProtocol, uint16(affinityTimeout), externalNodeLocal, corev1.ServiceTypeClusterIP); err != nil {
+			if err := p.ofClient.InstallServiceFlows(groupID, svcInfo.ClusterIP(), uint16(svcInfo.Port()), svcInfo.OFProtocol, uint16(affinityTimeout), svcInfo.SessionAffinityOptions(), externalNodeLocal, corev1.ServiceTypeClusterIP); err != nil {
 				klog.Errorf("Error when installing Service flows: %v", err)
 				continue
 			}
@@ -634,7 +701,7 @@ func (p *proxier) installServices() {
 				// If previous Service is nil or NodePort flows and configurations of previous Service have been removed,
 				// install NodePort flows and configurations for current Service.
 				if svcInfo.NodePort() > 0 && (pSvcInfo == nil || needRemoval) {
-					if err := p.installNodePortService(nGroupID, uint16(svcInfo.NodePort()), svcInfo.OFProtocol, uint16(affinityTimeout), svcInfo.NodeLocalExternal()); err != nil {
+					if err := p.installNodePortService(nGroupID, uint16(svcInfo.NodePort()), svcInfo.OFProtocol, uint16(affinityTimeout), svcInfo.SessionAffinityOptions(), svcInfo.NodeLocalExternal()); err != nil {
 						klog.ErrorS(err, "Failed to install NodePort flows and configurations of Service", "Service", svcPortName)
 						continue
 					}
@@ -661,7 +728,11 @@ func (p *proxier) installServices() {
 				}
 				// Install LoadBalancer flows and configurations.
 				if len(toAdd) > 0 {
-					if err := p.installLoadBalancerService(nGroupID, toAdd, uint16(svcInfo.Port()), svcInfo.OFProtocol, uint16(affinityTimeout), svcInfo.NodeLocalExternal()); err != nil {
+					var localGroupID binding.GroupIDType
+					if isDSR {
+						localGroupID = p.groupCounter.AllocateIfNotExist(svcPortName, true)
+					}
+					if err := p.installLoadBalancerService(nGroupID, localGroupID, toAdd, uint16(svcInfo.Port()), svcInfo.OFProtocol, uint16(affinityTimeout), svcInfo.SessionAffinityOptions(), svcInfo.NodeLocalExternal(), isDSR); err != nil {
 						klog.ErrorS(err, "Failed to install LoadBalancer flows and configurations of Service", "Service", svcPortName)
 						continue
 					}
@@ -975,6 +1046,7 @@ func NewProxier(
 	proxyAllEnabled bool,
 	skipServices []string,
 	proxyLoadBalancerIPs bool,
+	defaultLoadBalancerAlgorithm types.LoadBalancerAlgorithm,
 	groupCounter types.GroupCounter) *proxier {
 	recorder := record.NewBroadcaster().NewRecorder(
 		runtime.NewScheme(),
@@ -985,6 +1057,7 @@ func NewProxier(
 
 	endpointSliceEnabled := features.DefaultFeatureGate.Enabled(features.EndpointSlice)
 	topologyAwareHintsEnabled := features.DefaultFeatureGate.Enabled(features.TopologyAwareHints)
+	loadBalancerDSREnabled := features.DefaultFeatureGate.Enabled(features.LoadBalancerModeDSR)
 	ipFamily := corev1.IPv4Protocol
 	if isIPv6 {
 		ipFamily = corev1.IPv6Protocol
@@ -1020,6 +1093,8 @@ func NewProxier(
 		proxyAll:                  proxyAllEnabled,
 		endpointSliceEnabled:      endpointSliceEnabled,
 		topologyAwareHintsEnabled: topologyAwareHintsEnabled,
+		loadBalancerDSREnabled:    loadBalancerDSREnabled,
+		defaultLBAlgorithm:        defaultLoadBalancerAlgorithm,
 		proxyLoadBalancerIPs:      proxyLoadBalancerIPs,
 		hostname:                  hostname,
 		serviceHealthServer:       serviceHealthServer,
@@ -1082,14 +1157,15 @@ func NewDualStackProxier(
 	proxyAllEnabled bool,
 	skipServices []string,
 	proxyLoadBalancerIPs bool,
+	defaultLoadBalancerAlgorithm types.LoadBalancerAlgorithm,
 	v4groupCounter types.GroupCounter,
 	v6groupCounter types.GroupCounter) *metaProxierWrapper {
 
 	// Create an IPv4 instance of the single-stack proxier.
-	ipv4Proxier := NewProxier(hostname, informerFactory, ofClient, false, routeClient, nodePortAddressesIPv4, proxyAllEnabled, skipServices, proxyLoadBalancerIPs, v4groupCounter)
+	ipv4Proxier := NewProxier(hostname, informerFactory, ofClient, false, routeClient, nodePortAddressesIPv4, proxyAllEnabled, skipServices, proxyLoadBalancerIPs, defaultLoadBalancerAlgorithm, v4groupCounter)
 
 	// Create an IPv6 instance of the single-stack proxier.
-	ipv6Proxier := NewProxier(hostname, informerFactory, ofClient, true, routeClient, nodePortAddressesIPv6, proxyAllEnabled, skipServices, proxyLoadBalancerIPs, v6groupCounter)
+	ipv6Proxier := NewProxier(hostname, informerFactory, ofClient, true, routeClient, nodePortAddressesIPv6, proxyAllEnabled, skipServices, proxyLoadBalancerIPs, defaultLoadBalancerAlgorithm, v6groupCounter)
 
 	// Create a meta-proxier that dispatch calls between the two
 	// single-stack proxier instances.
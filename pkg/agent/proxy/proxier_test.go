@@ -21,8 +21,8 @@ import (
 	"testing"
 	"time"
 
-	"github.com/golang/mock/gomock"
 	"github.com/stretchr/testify/assert"
+	"go.uber.org/mock/gomock"
 	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
@@ -37,6 +37,7 @@ import (
 	"antrea.io/antrea/pkg/agent/proxy/types"
 	"antrea.io/antrea/pkg/agent/route"
 	routemock "antrea.io/antrea/pkg/agent/route/testing"
+	agenttypes "antrea.io/antrea/pkg/agent/types"
 	binding "antrea.io/antrea/pkg/ovs/openflow"
 	k8sproxy "antrea.io/antrea/third_party/proxy"
 )
@@ -102,8 +103,10 @@ func makeTestEndpoints(namespace, name string, eptFunc func(*corev1.Endpoints))
 }
 
 type proxyOptions struct {
-	proxyAllEnabled      bool
-	proxyLoadBalancerIPs bool
+	proxyAllEnabled        bool
+	proxyLoadBalancerIPs   bool
+	loadBalancerDSREnabled bool
+	defaultLBAlgorithm     types.LoadBalancerAlgorithm
 }
 
 type proxyOptionsFn func(*proxyOptions)
@@ -116,6 +119,14 @@ func withoutProxyLoadBalancerIPs(o *proxyOptions) {
 	o.proxyLoadBalancerIPs = false
 }
 
+func withLoadBalancerDSR(o *proxyOptions) {
+	o.loadBalancerDSREnabled = true
+}
+
+func withDefaultLBAlgorithmMaglev(o *proxyOptions) {
+	o.defaultLBAlgorithm = types.LoadBalancerAlgorithmMaglev
+}
+
 func NewFakeProxier(routeClient route.Interface, ofClient openflow.Client, nodePortAddresses []net.IP, groupIDAllocator openflow.GroupAllocator, isIPv6 bool, options ...proxyOptionsFn) *proxier {
 	hostname := "localhost"
 	eventBroadcaster := record.NewBroadcaster()
@@ -132,6 +143,7 @@ func NewFakeProxier(routeClient route.Interface, ofClient openflow.Client, nodeP
 	o := &proxyOptions{
 		proxyAllEnabled:      false,
 		proxyLoadBalancerIPs: true,
+		defaultLBAlgorithm:   types.LoadBalancerAlgorithmHash,
 	}
 
 	for _, fn := range options {
@@ -154,6 +166,8 @@ func NewFakeProxier(routeClient route.Interface, ofClient openflow.Client, nodeP
 		nodePortAddresses:        nodePortAddresses,
 		proxyAll:                 o.proxyAllEnabled,
 		proxyLoadBalancerIPs:     o.proxyLoadBalancerIPs,
+		loadBalancerDSREnabled:   o.loadBalancerDSREnabled,
+		defaultLBAlgorithm:       o.defaultLBAlgorithm,
 		numLocalEndpoints:        map[apimachinerytypes.NamespacedName]int{},
 	}
 	p.runner = k8sproxy.NewBoundedFrequencyRunner(componentName, p.syncProxyRules, time.Second, 30*time.Second, 2)
@@ -230,7 +244,7 @@ func testClusterIP(t *testing.T, svcIP net.IP, ep1IP, ep2IP net.IP, isIPv6, node
 	groupID := fp.groupCounter.AllocateIfNotExist(svcPortName, nodeLocalInternal)
 	mockOFClient.EXPECT().InstallEndpointFlows(bindingProtocol, gomock.InAnyOrder(expectedAllEps)).Times(1)
 	mockOFClient.EXPECT().InstallServiceGroup(groupID, false, gomock.InAnyOrder(expectedAllEps)).Times(1)
-	mockOFClient.EXPECT().InstallServiceFlows(groupID, svcIP, uint16(svcPort), bindingProtocol, uint16(0), false, corev1.ServiceTypeClusterIP).Times(1)
+	mockOFClient.EXPECT().InstallServiceFlows(groupID, svcIP, uint16(svcPort), bindingProtocol, uint16(0), agenttypes.DefaultSessionAffinityOptions, false, corev1.ServiceTypeClusterIP).Times(1)
 	mockRouteClient.EXPECT().AddClusterIPRoute(svcIP).Times(1)
 
 	fp.syncProxyRules()
@@ -331,21 +345,21 @@ func testLoadBalancer(t *testing.T, nodePortAddresses []net.IP, svcIP, ep1IP, ep
 		}
 		groupID := fp.groupCounter.AllocateIfNotExist(svcPortName, nodeLocalInternal)
 		mockOFClient.EXPECT().InstallServiceGroup(groupID, false, gomock.InAnyOrder(clusterIPEps)).Times(1)
-		mockOFClient.EXPECT().InstallServiceFlows(groupID, svcIP, uint16(svcPort), bindingProtocol, uint16(0), nodeLocalExternal, corev1.ServiceTypeClusterIP).Times(1)
+		mockOFClient.EXPECT().InstallServiceFlows(groupID, svcIP, uint16(svcPort), bindingProtocol, uint16(0), agenttypes.DefaultSessionAffinityOptions, nodeLocalExternal, corev1.ServiceTypeClusterIP).Times(1)
 		groupID = fp.groupCounter.AllocateIfNotExist(svcPortName, nodeLocalExternal)
 		mockOFClient.EXPECT().InstallServiceGroup(groupID, false, gomock.InAnyOrder(nodePortEps)).Times(1)
-		mockOFClient.EXPECT().InstallServiceFlows(groupID, gomock.Any(), uint16(svcNodePort), bindingProtocol, uint16(0), nodeLocalExternal, corev1.ServiceTypeNodePort).Times(1)
+		mockOFClient.EXPECT().InstallServiceFlows(groupID, gomock.Any(), uint16(svcNodePort), bindingProtocol, uint16(0), agenttypes.DefaultSessionAffinityOptions, nodeLocalExternal, corev1.ServiceTypeNodePort).Times(1)
 		if proxyLoadBalancerIPs {
-			mockOFClient.EXPECT().InstallServiceFlows(groupID, loadBalancerIP, uint16(svcPort), bindingProtocol, uint16(0), nodeLocalExternal, corev1.ServiceTypeLoadBalancer).Times(1)
+			mockOFClient.EXPECT().InstallServiceFlows(groupID, loadBalancerIP, uint16(svcPort), bindingProtocol, uint16(0), agenttypes.DefaultSessionAffinityOptions, nodeLocalExternal, corev1.ServiceTypeLoadBalancer).Times(1)
 		}
 	} else {
 		nodeLocalVal := nodeLocalInternal && nodeLocalExternal
 		groupID := fp.groupCounter.AllocateIfNotExist(svcPortName, nodeLocalVal)
 		mockOFClient.EXPECT().InstallServiceGroup(groupID, false, gomock.InAnyOrder(expectedAllEps)).Times(1)
-		mockOFClient.EXPECT().InstallServiceFlows(groupID, svcIP, uint16(svcPort), bindingProtocol, uint16(0), nodeLocalExternal, corev1.ServiceTypeClusterIP).Times(1)
-		mockOFClient.EXPECT().InstallServiceFlows(groupID, gomock.Any(), uint16(svcNodePort), bindingProtocol, uint16(0), nodeLocalExternal, corev1.ServiceTypeNodePort).Times(1)
+		mockOFClient.EXPECT().InstallServiceFlows(groupID, svcIP, uint16(svcPort), bindingProtocol, uint16(0), agenttypes.DefaultSessionAffinityOptions, nodeLocalExternal, corev1.ServiceTypeClusterIP).Times(1)
+		mockOFClient.EXPECT().InstallServiceFlows(groupID, gomock.Any(), uint16(svcNodePort), bindingProtocol, uint16(0), agenttypes.DefaultSessionAffinityOptions, nodeLocalExternal, corev1.ServiceTypeNodePort).Times(1)
 		if proxyLoadBalancerIPs {
-			mockOFClient.EXPECT().InstallServiceFlows(groupID, loadBalancerIP, uint16(svcPort), bindingProtocol, uint16(0), nodeLocalExternal, corev1.ServiceTypeLoadBalancer).Times(1)
+			mockOFClient.EXPECT().InstallServiceFlows(groupID, loadBalancerIP, uint16(svcPort), bindingProtocol, uint16(0), agenttypes.DefaultSessionAffinityOptions, nodeLocalExternal, corev1.ServiceTypeLoadBalancer).Times(1)
 		}
 		groupID = fp.groupCounter.AllocateIfNotExist(svcPortName, !nodeLocalVal)
 		mockOFClient.EXPECT().UninstallServiceGroup(groupID).Times(1)
@@ -359,6 +373,197 @@ func testLoadBalancer(t *testing.T, nodePortAddresses []net.IP, svcIP, ep1IP, ep
 	fp.syncProxyRules()
 }
 
+func testLoadBalancerDSR(t *testing.T, nodeLocalInternal, dsrEnabled bool, affinityType corev1.ServiceAffinity) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	mockOFClient := ofmock.NewMockClient(ctrl)
+	mockRouteClient := routemock.NewMockInterface(ctrl)
+	groupAllocator := openflow.NewGroupAllocator(false)
+	options := []proxyOptionsFn{withProxyAll}
+	if dsrEnabled {
+		options = append(options, withLoadBalancerDSR)
+	}
+	fp := NewFakeProxier(mockRouteClient, mockOFClient, nodePortAddressesIPv4, groupAllocator, false, options...)
+
+	svcPort := 80
+	svcNodePort := 30008
+	svcPortName := k8sproxy.ServicePortName{
+		NamespacedName: makeNamespaceName("ns1", "svc1"),
+		Port:           "80",
+		Protocol:       corev1.ProtocolTCP,
+	}
+	internalTrafficPolicy := corev1.ServiceInternalTrafficPolicyCluster
+	if nodeLocalInternal {
+		internalTrafficPolicy = corev1.ServiceInternalTrafficPolicyLocal
+	}
+	affinitySeconds := int32(10)
+
+	makeServiceMap(fp,
+		makeTestService(svcPortName.Namespace, svcPortName.Name, func(svc *corev1.Service) {
+			svc.Annotations = map[string]string{agenttypes.ServiceLoadBalancerModeAnnotationKey: string(types.LoadBalancerModeDSR)}
+			svc.Spec.ClusterIP = svcIPv4.String()
+			svc.Spec.Type = corev1.ServiceTypeLoadBalancer
+			svc.Status.LoadBalancer.Ingress = []corev1.LoadBalancerIngress{{IP: loadBalancerIPv4.String()}}
+			svc.Spec.Ports = []corev1.ServicePort{{
+				NodePort: int32(svcNodePort),
+				Name:     svcPortName.Port,
+				Port:     int32(svcPort),
+				Protocol: corev1.ProtocolTCP,
+			}}
+			svc.Spec.ExternalTrafficPolicy = corev1.ServiceExternalTrafficPolicyTypeCluster
+			svc.Spec.InternalTrafficPolicy = &internalTrafficPolicy
+			svc.Spec.SessionAffinity = affinityType
+			if affinityType == corev1.ServiceAffinityClientIP {
+				svc.Spec.SessionAffinityConfig = &corev1.SessionAffinityConfig{
+					ClientIP: &corev1.ClientIPConfig{TimeoutSeconds: &affinitySeconds},
+				}
+			}
+		}),
+	)
+
+	epFunc := func(ept *corev1.Endpoints) {
+		ept.Subsets = []corev1.EndpointSubset{{
+			Addresses: []corev1.EndpointAddress{
+				{IP: ep1IPv4.String()},
+				{IP: ep2IPv4.String(), NodeName: &hostname},
+			},
+			Ports: []corev1.EndpointPort{{
+				Name:     svcPortName.Port,
+				Port:     int32(svcPort),
+				Protocol: corev1.ProtocolTCP,
+			}},
+		}}
+	}
+	makeEndpointsMap(fp, makeTestEndpoints(svcPortName.Namespace, svcPortName.Name, epFunc))
+
+	expectedLocalEps := []k8sproxy.Endpoint{k8sproxy.NewBaseEndpointInfo(ep2IPv4.String(), "", "", svcPort, true, true, false, false, nil)}
+	expectedAllEps := append(expectedLocalEps, k8sproxy.NewBaseEndpointInfo(ep1IPv4.String(), "", "", svcPort, false, true, false, false, nil))
+	isDSR := dsrEnabled && affinityType == corev1.ServiceAffinityNone
+	affinityTimeout := uint16(0)
+	if affinityType == corev1.ServiceAffinityClientIP {
+		affinityTimeout = uint16(affinitySeconds)
+	}
+
+	mockOFClient.EXPECT().InstallEndpointFlows(binding.ProtocolTCP, gomock.InAnyOrder(expectedAllEps)).Times(1)
+	localGroupID := fp.groupCounter.AllocateIfNotExist(svcPortName, true)
+	allGroupID := fp.groupCounter.AllocateIfNotExist(svcPortName, false)
+	clusterIPGroupID := allGroupID
+	if isDSR || nodeLocalInternal {
+		mockOFClient.EXPECT().InstallServiceGroup(localGroupID, affinityTimeout != 0, gomock.InAnyOrder(expectedLocalEps)).Times(1)
+		mockOFClient.EXPECT().InstallServiceGroup(allGroupID, affinityTimeout != 0, gomock.InAnyOrder(expectedAllEps)).Times(1)
+		if nodeLocalInternal {
+			clusterIPGroupID = localGroupID
+		}
+	} else {
+		mockOFClient.EXPECT().InstallServiceGroup(allGroupID, affinityTimeout != 0, gomock.InAnyOrder(expectedAllEps)).Times(1)
+		mockOFClient.EXPECT().UninstallServiceGroup(localGroupID).Times(1)
+	}
+	mockOFClient.EXPECT().InstallServiceFlows(clusterIPGroupID, svcIPv4, uint16(svcPort), binding.ProtocolTCP, affinityTimeout, agenttypes.DefaultSessionAffinityOptions, false, corev1.ServiceTypeClusterIP).Times(1)
+	mockOFClient.EXPECT().InstallServiceFlows(allGroupID, gomock.Any(), uint16(svcNodePort), binding.ProtocolTCP, affinityTimeout, agenttypes.DefaultSessionAffinityOptions, false, corev1.ServiceTypeNodePort).Times(1)
+	mockOFClient.EXPECT().InstallServiceFlows(allGroupID, loadBalancerIPv4, uint16(svcPort), binding.ProtocolTCP, affinityTimeout, agenttypes.DefaultSessionAffinityOptions, false, corev1.ServiceTypeLoadBalancer).Times(1)
+	if isDSR {
+		mockOFClient.EXPECT().InstallServiceDSRFlows(allGroupID, localGroupID, loadBalancerIPv4, uint16(svcPort), binding.ProtocolTCP).Times(1)
+	}
+	mockRouteClient.EXPECT().AddClusterIPRoute(svcIPv4).Times(1)
+	mockRouteClient.EXPECT().AddLoadBalancer([]string{loadBalancerIPv4.String()}).Times(1)
+	mockRouteClient.EXPECT().AddNodePort(nodePortAddressesIPv4, uint16(svcNodePort), binding.ProtocolTCP).Times(1)
+
+	fp.syncProxyRules()
+}
+
+func TestLoadBalancerDSR(t *testing.T) {
+	t.Run("InternalTrafficPolicy:Cluster", func(t *testing.T) {
+		testLoadBalancerDSR(t, false, true, corev1.ServiceAffinityNone)
+	})
+	t.Run("InternalTrafficPolicy:Local", func(t *testing.T) {
+		testLoadBalancerDSR(t, true, true, corev1.ServiceAffinityNone)
+	})
+	t.Run("Feature disabled", func(t *testing.T) {
+		testLoadBalancerDSR(t, false, false, corev1.ServiceAffinityNone)
+	})
+	t.Run("SessionAffinity:ClientIP", func(t *testing.T) {
+		testLoadBalancerDSR(t, false, true, corev1.ServiceAffinityClientIP)
+	})
+}
+
+func testLoadBalancerAlgorithm(t *testing.T, annotation string, defaultMaglev, expectMaglev bool) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	mockOFClient := ofmock.NewMockClient(ctrl)
+	mockRouteClient := routemock.NewMockInterface(ctrl)
+	groupAllocator := openflow.NewGroupAllocator(false)
+	options := []proxyOptionsFn{withProxyAll}
+	if defaultMaglev {
+		options = append(options, withDefaultLBAlgorithmMaglev)
+	}
+	fp := NewFakeProxier(mockRouteClient, mockOFClient, nil, groupAllocator, false, options...)
+
+	svcPort := 80
+	svcPortName := k8sproxy.ServicePortName{
+		NamespacedName: makeNamespaceName("ns1", "svc1"),
+		Port:           "80",
+		Protocol:       corev1.ProtocolTCP,
+	}
+	makeServiceMap(fp,
+		makeTestService(svcPortName.Namespace, svcPortName.Name, func(svc *corev1.Service) {
+			if annotation != "" {
+				svc.Annotations = map[string]string{agenttypes.ServiceLoadBalancerAlgorithmAnnotationKey: annotation}
+			}
+			svc.Spec.ClusterIP = svcIPv4.String()
+			svc.Spec.Ports = []corev1.ServicePort{{
+				Name:     svcPortName.Port,
+				Port:     int32(svcPort),
+				Protocol: corev1.ProtocolTCP,
+			}}
+		}),
+	)
+	epFunc := func(ept *corev1.Endpoints) {
+		ept.Subsets = []corev1.EndpointSubset{{
+			Addresses: []corev1.EndpointAddress{{IP: ep1IPv4.String()}, {IP: ep2IPv4.String()}},
+			Ports: []corev1.EndpointPort{{
+				Name:     svcPortName.Port,
+				Port:     int32(svcPort),
+				Protocol: corev1.ProtocolTCP,
+			}},
+		}}
+	}
+	makeEndpointsMap(fp, makeTestEndpoints(svcPortName.Namespace, svcPortName.Name, epFunc))
+
+	expectedEps := []k8sproxy.Endpoint{
+		k8sproxy.NewBaseEndpointInfo(ep1IPv4.String(), "", "", svcPort, false, true, false, false, nil),
+		k8sproxy.NewBaseEndpointInfo(ep2IPv4.String(), "", "", svcPort, false, true, false, false, nil),
+	}
+	groupID := fp.groupCounter.AllocateIfNotExist(svcPortName, false)
+	mockOFClient.EXPECT().InstallEndpointFlows(binding.ProtocolTCP, gomock.InAnyOrder(expectedEps)).Times(1)
+	if expectMaglev {
+		mockOFClient.EXPECT().InstallServiceMaglevGroup(groupID, false, newMaglevLookupTable(expectedEps, maglevTableSize)).Times(1)
+	} else {
+		mockOFClient.EXPECT().InstallServiceGroup(groupID, false, gomock.InAnyOrder(expectedEps)).Times(1)
+	}
+	mockOFClient.EXPECT().InstallServiceFlows(groupID, svcIPv4, uint16(svcPort), binding.ProtocolTCP, uint16(0), agenttypes.DefaultSessionAffinityOptions, false, corev1.ServiceTypeClusterIP).Times(1)
+	mockRouteClient.EXPECT().AddClusterIPRoute(svcIPv4).Times(1)
+
+	fp.syncProxyRules()
+}
+
+func TestLoadBalancerAlgorithm(t *testing.T) {
+	t.Run("Default hash", func(t *testing.T) {
+		testLoadBalancerAlgorithm(t, "", false, false)
+	})
+	t.Run("Default maglev", func(t *testing.T) {
+		testLoadBalancerAlgorithm(t, "", true, true)
+	})
+	t.Run("Annotation maglev", func(t *testing.T) {
+		testLoadBalancerAlgorithm(t, "Maglev", false, true)
+	})
+	t.Run("Annotation hash", func(t *testing.T) {
+		testLoadBalancerAlgorithm(t, "hash", true, false)
+	})
+	t.Run("Invalid annotation", func(t *testing.T) {
+		testLoadBalancerAlgorithm(t, "random", true, true)
+	})
+}
+
 func testNodePort(t *testing.T, nodePortAddresses []net.IP, svcIP, ep1IP, ep2IP net.IP, isIPv6, nodeLocalInternal, nodeLocalExternal bool) {
 	ctrl := gomock.NewController(t)
 	defer ctrl.Finish()
@@ -454,17 +659,17 @@ func testNodePort(t *testing.T, nodePortAddresses []net.IP, svcIP, ep1IP, ep2IP
 		}
 		groupID := fp.groupCounter.AllocateIfNotExist(svcPortName, nodeLocalInternal)
 		mockOFClient.EXPECT().InstallServiceGroup(groupID, false, gomock.InAnyOrder(clusterIPEps)).Times(1)
-		mockOFClient.EXPECT().InstallServiceFlows(groupID, svcIP, uint16(svcPort), bindingProtocol, uint16(0), nodeLocalExternal, corev1.ServiceTypeClusterIP).Times(1)
+		mockOFClient.EXPECT().InstallServiceFlows(groupID, svcIP, uint16(svcPort), bindingProtocol, uint16(0), agenttypes.DefaultSessionAffinityOptions, nodeLocalExternal, corev1.ServiceTypeClusterIP).Times(1)
 
 		groupID = fp.groupCounter.AllocateIfNotExist(svcPortName, nodeLocalExternal)
 		mockOFClient.EXPECT().InstallServiceGroup(groupID, false, gomock.InAnyOrder(nodePortEps)).Times(1)
-		mockOFClient.EXPECT().InstallServiceFlows(groupID, gomock.Any(), uint16(svcNodePort), bindingProtocol, uint16(0), nodeLocalExternal, corev1.ServiceTypeNodePort).Times(1)
+		mockOFClient.EXPECT().InstallServiceFlows(groupID, gomock.Any(), uint16(svcNodePort), bindingProtocol, uint16(0), agenttypes.DefaultSessionAffinityOptions, nodeLocalExternal, corev1.ServiceTypeNodePort).Times(1)
 	} else {
 		nodeLocalVal := nodeLocalInternal && nodeLocalExternal
 		groupID := fp.groupCounter.AllocateIfNotExist(svcPortName, nodeLocalVal)
 		mockOFClient.EXPECT().InstallServiceGroup(groupID, false, gomock.InAnyOrder(expectedAllEps)).Times(1)
-		mockOFClient.EXPECT().InstallServiceFlows(groupID, svcIP, uint16(svcPort), bindingProtocol, uint16(0), nodeLocalExternal, corev1.ServiceTypeClusterIP).Times(1)
-		mockOFClient.EXPECT().InstallServiceFlows(groupID, gomock.Any(), uint16(svcNodePort), bindingProtocol, uint16(0), nodeLocalExternal, corev1.ServiceTypeNodePort).Times(1)
+		mockOFClient.EXPECT().InstallServiceFlows(groupID, svcIP, uint16(svcPort), bindingProtocol, uint16(0), agenttypes.DefaultSessionAffinityOptions, nodeLocalExternal, corev1.ServiceTypeClusterIP).Times(1)
+		mockOFClient.EXPECT().InstallServiceFlows(groupID, gomock.Any(), uint16(svcNodePort), bindingProtocol, uint16(0), agenttypes.DefaultSessionAffinityOptions, nodeLocalExternal, corev1.ServiceTypeNodePort).Times(1)
 
 		groupID = fp.groupCounter.AllocateIfNotExist(svcPortName, !nodeLocalVal)
 		mockOFClient.EXPECT().UninstallServiceGroup(groupID).Times(1)
@@ -689,11 +894,11 @@ func TestDualStackService(t *testing.T) {
 
 	mockOFClient.EXPECT().InstallServiceGroup(groupIDv4, false, gomock.Any()).Times(1)
 	mockOFClient.EXPECT().InstallEndpointFlows(binding.ProtocolTCP, gomock.Any()).Times(1)
-	mockOFClient.EXPECT().InstallServiceFlows(groupIDv4, svcIPv4, uint16(svcPort), binding.ProtocolTCP, uint16(0), false, corev1.ServiceTypeClusterIP).Times(1)
+	mockOFClient.EXPECT().InstallServiceFlows(groupIDv4, svcIPv4, uint16(svcPort), binding.ProtocolTCP, uint16(0), agenttypes.DefaultSessionAffinityOptions, false, corev1.ServiceTypeClusterIP).Times(1)
 
 	mockOFClient.EXPECT().InstallServiceGroup(groupIDv6, false, gomock.Any()).Times(1)
 	mockOFClient.EXPECT().InstallEndpointFlows(binding.ProtocolTCPv6, gomock.Any()).Times(1)
-	mockOFClient.EXPECT().InstallServiceFlows(groupIDv6, svcIPv6, uint16(svcPort), binding.ProtocolTCPv6, uint16(0), false, corev1.ServiceTypeClusterIP).Times(1)
+	mockOFClient.EXPECT().InstallServiceFlows(groupIDv6, svcIPv6, uint16(svcPort), binding.ProtocolTCPv6, uint16(0), agenttypes.DefaultSessionAffinityOptions, false, corev1.ServiceTypeClusterIP).Times(1)
 
 	fpv4.syncProxyRules()
 	fpv6.syncProxyRules()
@@ -745,7 +950,7 @@ func testClusterIPRemoval(t *testing.T, svcIP net.IP, epIP net.IP, isIPv6 bool)
 	groupID := fp.groupCounter.AllocateIfNotExist(svcPortName, false)
 	mockOFClient.EXPECT().InstallServiceGroup(groupID, false, gomock.Any()).Times(1)
 	mockOFClient.EXPECT().InstallEndpointFlows(bindingProtocol, gomock.Any()).Times(1)
-	mockOFClient.EXPECT().InstallServiceFlows(groupID, svcIP, uint16(svcPort), bindingProtocol, uint16(0), false, corev1.ServiceTypeClusterIP).Times(1)
+	mockOFClient.EXPECT().InstallServiceFlows(groupID, svcIP, uint16(svcPort), bindingProtocol, uint16(0), agenttypes.DefaultSessionAffinityOptions, false, corev1.ServiceTypeClusterIP).Times(1)
 	mockRouteClient.EXPECT().AddClusterIPRoute(svcIP).Times(1)
 	mockOFClient.EXPECT().UninstallServiceFlows(svcIP, uint16(svcPort), bindingProtocol).Times(1)
 	mockOFClient.EXPECT().UninstallEndpointFlows(bindingProtocol, gomock.Any()).Times(1)
@@ -880,8 +1085,8 @@ func testClusterIPRemoveSamePortEndpoint(t *testing.T, svcIP net.IP, epIP net.IP
 	mockOFClient.EXPECT().InstallServiceGroup(groupIDUDP, false, gomock.Any()).Times(2)
 	mockOFClient.EXPECT().InstallEndpointFlows(protocolTCP, gomock.Any()).Times(1)
 	mockOFClient.EXPECT().InstallEndpointFlows(protocolUDP, gomock.Any()).Times(2)
-	mockOFClient.EXPECT().InstallServiceFlows(groupID, svcIP, uint16(svcPort), protocolTCP, uint16(0), false, corev1.ServiceTypeClusterIP).Times(1)
-	mockOFClient.EXPECT().InstallServiceFlows(groupIDUDP, svcIP, uint16(svcPort), protocolUDP, uint16(0), false, corev1.ServiceTypeClusterIP).Times(1)
+	mockOFClient.EXPECT().InstallServiceFlows(groupID, svcIP, uint16(svcPort), protocolTCP, uint16(0), agenttypes.DefaultSessionAffinityOptions, false, corev1.ServiceTypeClusterIP).Times(1)
+	mockOFClient.EXPECT().InstallServiceFlows(groupIDUDP, svcIP, uint16(svcPort), protocolUDP, uint16(0), agenttypes.DefaultSessionAffinityOptions, false, corev1.ServiceTypeClusterIP).Times(1)
 	mockOFClient.EXPECT().UninstallEndpointFlows(protocolUDP, gomock.Any()).Times(1)
 	fp.syncProxyRules()
 
@@ -942,7 +1147,7 @@ func testClusterIPRemoveEndpoints(t *testing.T, svcIP net.IP, epIP net.IP, isIPv
 	groupID := fp.groupCounter.AllocateIfNotExist(svcPortName, false)
 	mockOFClient.EXPECT().InstallServiceGroup(groupID, false, gomock.Any()).Times(2)
 	mockOFClient.EXPECT().InstallEndpointFlows(bindingProtocol, gomock.Any()).Times(2)
-	mockOFClient.EXPECT().InstallServiceFlows(groupID, svcIP, uint16(svcPort), bindingProtocol, uint16(0), false, corev1.ServiceTypeClusterIP).Times(1)
+	mockOFClient.EXPECT().InstallServiceFlows(groupID, svcIP, uint16(svcPort), bindingProtocol, uint16(0), agenttypes.DefaultSessionAffinityOptions, false, corev1.ServiceTypeClusterIP).Times(1)
 	mockOFClient.EXPECT().UninstallEndpointFlows(bindingProtocol, gomock.Any()).Times(1)
 	fp.syncProxyRules()
 
@@ -958,7 +1163,7 @@ func TestClusterIPRemoveEndpointsIPv6(t *testing.T) {
 	testClusterIPRemoveEndpoints(t, net.ParseIP("10:20::41"), net.ParseIP("10:180::1"), true)
 }
 
-func testSessionAffinity(t *testing.T, svcExternalIPs net.IP, svcIP net.IP, epIP net.IP, affinitySeconds int32, isIPv6 bool) {
+func testSessionAffinity(t *testing.T, svcExternalIPs net.IP, svcIP net.IP, epIP net.IP, affinitySeconds int32, isIPv6 bool, annotations map[string]string, expectedOptions agenttypes.SessionAffinityOptions) {
 	ctrl := gomock.NewController(t)
 	defer ctrl.Finish()
 	mockOFClient := ofmock.NewMockClient(ctrl)
@@ -976,6 +1181,7 @@ func testSessionAffinity(t *testing.T, svcExternalIPs net.IP, svcIP net.IP, epIP
 
 	makeServiceMap(fp,
 		makeTestService(svcPortName.Namespace, svcPortName.Name, func(svc *corev1.Service) {
+			svc.Annotations = annotations
 			svc.Spec.Type = corev1.ServiceTypeNodePort
 			svc.Spec.ClusterIP = svcIP.String()
 			svc.Spec.ExternalIPs = []string{svcExternalIPs.String()}
@@ -1012,34 +1218,78 @@ func testSessionAffinity(t *testing.T, svcExternalIPs net.IP, svcIP net.IP, epIP
 		bindingProtocol = binding.ProtocolTCPv6
 	}
 	groupID := fp.groupCounter.AllocateIfNotExist(svcPortName, false)
-	mockOFClient.EXPECT().InstallServiceGroup(groupID, true, gomock.Any()).Times(1)
 	mockOFClient.EXPECT().InstallEndpointFlows(bindingProtocol, gomock.Any()).Times(1)
 	var expectedAffinity uint16
-	if affinitySeconds > math.MaxUint16 {
-		expectedAffinity = math.MaxUint16
+	if expectedOptions.Hash {
+		// No learned flow is installed when the Endpoint is selected with the hash of the session affinity key.
+		mockOFClient.EXPECT().InstallServiceAffinityHashGroup(groupID, expectedOptions.Key, gomock.Any()).Times(1)
 	} else {
-		expectedAffinity = uint16(affinitySeconds)
+		mockOFClient.EXPECT().InstallServiceGroup(groupID, true, gomock.Any()).Times(1)
+		if affinitySeconds > math.MaxUint16 {
+			expectedAffinity = math.MaxUint16
+		} else {
+			expectedAffinity = uint16(affinitySeconds)
+		}
 	}
-	mockOFClient.EXPECT().InstallServiceFlows(groupID, svcIP, uint16(svcPort), bindingProtocol, expectedAffinity, false, corev1.ServiceTypeClusterIP).Times(1)
+	mockOFClient.EXPECT().InstallServiceFlows(groupID, svcIP, uint16(svcPort), bindingProtocol, expectedAffinity, expectedOptions, false, corev1.ServiceTypeClusterIP).Times(1)
 
 	fp.syncProxyRules()
 }
 
 func TestSessionAffinityIPv4(t *testing.T) {
 	affinitySeconds := corev1.DefaultClientIPServiceAffinitySeconds
-	testSessionAffinity(t, net.ParseIP("50.60.70.81"), net.ParseIP("10.20.30.41"), net.ParseIP("10.180.0.1"), affinitySeconds, false)
+	testSessionAffinity(t, net.ParseIP("50.60.70.81"), net.ParseIP("10.20.30.41"), net.ParseIP("10.180.0.1"), affinitySeconds, false, nil, agenttypes.DefaultSessionAffinityOptions)
 }
 
 func TestSessionAffinityIPv6(t *testing.T) {
 	affinitySeconds := corev1.DefaultClientIPServiceAffinitySeconds
-	testSessionAffinity(t, net.ParseIP("5060:70::81"), net.ParseIP("10:20::41"), net.ParseIP("10:180::1"), affinitySeconds, true)
+	testSessionAffinity(t, net.ParseIP("5060:70::81"), net.ParseIP("10:20::41"), net.ParseIP("10:180::1"), affinitySeconds, true, nil, agenttypes.DefaultSessionAffinityOptions)
 }
 
 func TestSessionAffinityOverflow(t *testing.T) {
 	// Ensure that the SessionAffinity timeout is truncated to the max supported value, instead
 	// of wrapping around.
 	affinitySeconds := int32(math.MaxUint16 + 10)
-	testSessionAffinity(t, net.ParseIP("50.60.70.81"), net.ParseIP("10.20.30.41"), net.ParseIP("10.180.0.1"), affinitySeconds, false)
+	testSessionAffinity(t, net.ParseIP("50.60.70.81"), net.ParseIP("10.20.30.41"), net.ParseIP("10.180.0.1"), affinitySeconds, false, nil, agenttypes.DefaultSessionAffinityOptions)
+}
+
+func TestSessionAffinityOptions(t *testing.T) {
+	affinitySeconds := corev1.DefaultClientIPServiceAffinitySeconds
+	svcExternalIP, svcIP, epIP := net.ParseIP("50.60.70.81"), net.ParseIP("10.20.30.41"), net.ParseIP("10.180.0.1")
+	t.Run("Source IP and port", func(t *testing.T) {
+		annotations := map[string]string{agenttypes.ServiceSessionAffinityKeyAnnotationKey: "srcIP,srcPort"}
+		expectedOptions := agenttypes.SessionAffinityOptions{Key: agenttypes.SessionAffinityKeySrcIP | agenttypes.SessionAffinityKeySrcPort}
+		testSessionAffinity(t, svcExternalIP, svcIP, epIP, affinitySeconds, false, annotations, expectedOptions)
+	})
+	t.Run("Source port and destination port with idle timeout", func(t *testing.T) {
+		annotations := map[string]string{
+			agenttypes.ServiceSessionAffinityKeyAnnotationKey:         "srcPort,dstPort",
+			agenttypes.ServiceSessionAffinityTimeoutTypeAnnotationKey: "idle",
+		}
+		expectedOptions := agenttypes.SessionAffinityOptions{Key: agenttypes.SessionAffinityKeySrcPort, IdleTimeout: true}
+		testSessionAffinity(t, svcExternalIP, svcIP, epIP, affinitySeconds, false, annotations, expectedOptions)
+	})
+	t.Run("Destination port only", func(t *testing.T) {
+		// A key without any source field would make all clients share the same Endpoint, so it is rejected.
+		annotations := map[string]string{agenttypes.ServiceSessionAffinityKeyAnnotationKey: "dstPort"}
+		testSessionAffinity(t, svcExternalIP, svcIP, epIP, affinitySeconds, false, annotations, agenttypes.DefaultSessionAffinityOptions)
+	})
+	t.Run("Hash mode", func(t *testing.T) {
+		annotations := map[string]string{
+			agenttypes.ServiceSessionAffinityKeyAnnotationKey:  "srcIP,srcPort",
+			agenttypes.ServiceSessionAffinityModeAnnotationKey: "hash",
+		}
+		expectedOptions := agenttypes.SessionAffinityOptions{Key: agenttypes.SessionAffinityKeySrcIP | agenttypes.SessionAffinityKeySrcPort, Hash: true}
+		testSessionAffinity(t, svcExternalIP, svcIP, epIP, affinitySeconds, false, annotations, expectedOptions)
+	})
+	t.Run("Invalid annotations", func(t *testing.T) {
+		annotations := map[string]string{
+			agenttypes.ServiceSessionAffinityKeyAnnotationKey:         "srcMAC",
+			agenttypes.ServiceSessionAffinityTimeoutTypeAnnotationKey: "soft",
+			agenttypes.ServiceSessionAffinityModeAnnotationKey:        "random",
+		}
+		testSessionAffinity(t, svcExternalIP, svcIP, epIP, affinitySeconds, false, annotations, agenttypes.DefaultSessionAffinityOptions)
+	})
 }
 
 func testSessionAffinityNoEndpoint(t *testing.T, svcExternalIPs net.IP, svcIP net.IP, isIPv6 bool) {
@@ -1138,9 +1388,9 @@ func testPortChange(t *testing.T, svcIP net.IP, epIP net.IP, isIPv6 bool) {
 	groupID := fp.groupCounter.AllocateIfNotExist(svcPortName, false)
 	mockOFClient.EXPECT().InstallServiceGroup(groupID, false, gomock.Any()).Times(1)
 	mockOFClient.EXPECT().InstallEndpointFlows(bindingProtocol, gomock.Any()).Times(1)
-	mockOFClient.EXPECT().InstallServiceFlows(groupID, svcIP, uint16(svcPort1), bindingProtocol, uint16(0), false, corev1.ServiceTypeClusterIP)
+	mockOFClient.EXPECT().InstallServiceFlows(groupID, svcIP, uint16(svcPort1), bindingProtocol, uint16(0), agenttypes.DefaultSessionAffinityOptions, false, corev1.ServiceTypeClusterIP)
 	mockOFClient.EXPECT().UninstallServiceFlows(svcIP, uint16(svcPort1), bindingProtocol)
-	mockOFClient.EXPECT().InstallServiceFlows(groupID, svcIP, uint16(svcPort2), bindingProtocol, uint16(0), false, corev1.ServiceTypeClusterIP)
+	mockOFClient.EXPECT().InstallServiceFlows(groupID, svcIP, uint16(svcPort2), bindingProtocol, uint16(0), agenttypes.DefaultSessionAffinityOptions, false, corev1.ServiceTypeClusterIP)
 
 	fp.syncProxyRules()
 
@@ -1228,8 +1478,8 @@ func TestServicesWithSameEndpoints(t *testing.T) {
 	mockOFClient.EXPECT().InstallServiceGroup(groupID2, false, gomock.Any()).Times(1)
 	bindingProtocol := binding.ProtocolTCP
 	mockOFClient.EXPECT().InstallEndpointFlows(bindingProtocol, gomock.Any()).Times(2)
-	mockOFClient.EXPECT().InstallServiceFlows(groupID1, svcIP1, uint16(svcPort), bindingProtocol, uint16(0), false, corev1.ServiceTypeClusterIP).Times(1)
-	mockOFClient.EXPECT().InstallServiceFlows(groupID2, svcIP2, uint16(svcPort), bindingProtocol, uint16(0), false, corev1.ServiceTypeClusterIP).Times(1)
+	mockOFClient.EXPECT().InstallServiceFlows(groupID1, svcIP1, uint16(svcPort), bindingProtocol, uint16(0), agenttypes.DefaultSessionAffinityOptions, false, corev1.ServiceTypeClusterIP).Times(1)
+	mockOFClient.EXPECT().InstallServiceFlows(groupID2, svcIP2, uint16(svcPort), bindingProtocol, uint16(0), agenttypes.DefaultSessionAffinityOptions, false, corev1.ServiceTypeClusterIP).Times(1)
 	mockOFClient.EXPECT().UninstallServiceFlows(svcIP1, uint16(svcPort), bindingProtocol).Times(1)
 	mockOFClient.EXPECT().UninstallServiceFlows(svcIP2, uint16(svcPort), bindingProtocol).Times(1)
 	mockOFClient.EXPECT().UninstallServiceGroup(groupID1).Times(1)
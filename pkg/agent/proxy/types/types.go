@@ -15,18 +15,97 @@
 package types
 
 import (
+	"strings"
+
 	corev1 "k8s.io/api/core/v1"
+	"k8s.io/klog/v2"
 	utilnet "k8s.io/utils/net"
 
+	"antrea.io/antrea/pkg/agent/types"
 	"antrea.io/antrea/pkg/ovs/openflow"
 	k8sproxy "antrea.io/antrea/third_party/proxy"
 )
 
+// LoadBalancerMode is the mode in which the traffic destined for the LoadBalancer IPs of a Service is load-balanced.
+type LoadBalancerMode string
+
+const (
+	// LoadBalancerModeNAT is the default mode, in which the request packets are DNATed on the ingress Node, and the
+	// reply packets go back through the ingress Node.
+	LoadBalancerModeNAT LoadBalancerMode = "nat"
+	// LoadBalancerModeDSR is the Direct Server Return mode, in which the request packets are forwarded to the Node of
+	// the selected Endpoint with the destination IP unchanged, and the reply packets are sent to the client from that
+	// Node directly.
+	LoadBalancerModeDSR LoadBalancerMode = "dsr"
+)
+
+// LoadBalancerAlgorithm is the algorithm used to select an Endpoint for the traffic of a Service.
+type LoadBalancerAlgorithm string
+
+const (
+	// LoadBalancerAlgorithmHash is the default algorithm, in which OVS selects an Endpoint with the hash computed by
+	// the datapath. Different Nodes may select different Endpoints for the same connection.
+	LoadBalancerAlgorithmHash LoadBalancerAlgorithm = "hash"
+	// LoadBalancerAlgorithmMaglev is the Maglev consistent hashing algorithm, in which all Nodes select the same
+	// Endpoint for the same connection, and only a small fraction of connections are remapped when the Endpoints of
+	// the Service change.
+	LoadBalancerAlgorithmMaglev LoadBalancerAlgorithm = "maglev"
+)
+
 // ServiceInfo is the internal struct for caching service information.
 type ServiceInfo struct {
 	*k8sproxy.BaseServiceInfo
 	// cache for performance
 	OFProtocol openflow.Protocol
+	// loadBalancerMode is specified by the annotation "service.antrea.io/load-balancer-mode" of the Service.
+	loadBalancerMode LoadBalancerMode
+	// loadBalancerAlgorithm is specified by the annotation "service.antrea.io/load-balancer-algorithm" of the Service.
+	// It is empty if the annotation is not set, in which case the default algorithm should be used.
+	loadBalancerAlgorithm LoadBalancerAlgorithm
+	// sessionAffinityOptions is specified by the annotations "service.antrea.io/session-affinity-key",
+	// "service.antrea.io/session-affinity-timeout-type" and "service.antrea.io/session-affinity-mode" of the Service.
+	sessionAffinityOptions types.SessionAffinityOptions
+}
+
+// LoadBalancerMode returns the mode in which the traffic destined for the LoadBalancer IPs of the Service is
+// load-balanced.
+func (info *ServiceInfo) LoadBalancerMode() LoadBalancerMode {
+	return info.loadBalancerMode
+}
+
+// LoadBalancerAlgorithm returns the algorithm used to select an Endpoint for the traffic of the Service. It returns an
+// empty string if the algorithm is not specified for the Service.
+func (info *ServiceInfo) LoadBalancerAlgorithm() LoadBalancerAlgorithm {
+	return info.loadBalancerAlgorithm
+}
+
+// SessionAffinityOptions returns how the session affinity of the Service is maintained. It is only meaningful when the
+// session affinity type of the Service is ClientIP.
+func (info *ServiceInfo) SessionAffinityOptions() types.SessionAffinityOptions {
+	return info.sessionAffinityOptions
+}
+
+// parseSessionAffinityKey parses the comma-separated packet fields specified by the annotation
+// "service.antrea.io/session-affinity-key". The supported fields are "srcIP", "srcPort" and "dstPort". The destination
+// port is always used to identify a client, so "dstPort" is accepted but has no effect. A key which includes neither
+// "srcIP" nor "srcPort" is invalid, as it would make all clients share the same Endpoint.
+func parseSessionAffinityKey(value string) (types.SessionAffinityKey, bool) {
+	var key types.SessionAffinityKey
+	for _, field := range strings.Split(value, ",") {
+		switch strings.ToLower(strings.TrimSpace(field)) {
+		case "srcip":
+			key |= types.SessionAffinityKeySrcIP
+		case "srcport":
+			key |= types.SessionAffinityKeySrcPort
+		case "dstport":
+		default:
+			return 0, false
+		}
+	}
+	if key == 0 {
+		return 0, false
+	}
+	return key, true
 }
 
 // NewServiceInfo returns a new k8sproxy.ServicePort which abstracts a serviceInfo.
@@ -47,6 +126,49 @@ func NewServiceInfo(port *corev1.ServicePort, service *corev1.Service, baseInfo
 			info.OFProtocol = openflow.ProtocolSCTP
 		}
 	}
+	info.loadBalancerMode = LoadBalancerModeNAT
+	if mode, ok := service.Annotations[types.ServiceLoadBalancerModeAnnotationKey]; ok {
+		switch m := LoadBalancerMode(strings.ToLower(mode)); m {
+		case LoadBalancerModeNAT, LoadBalancerModeDSR:
+			info.loadBalancerMode = m
+		default:
+			klog.InfoS("Unsupported load balancer mode, falling back to NAT mode", "service", klog.KObj(service), "mode", mode)
+		}
+	}
+	if algorithm, ok := service.Annotations[types.ServiceLoadBalancerAlgorithmAnnotationKey]; ok {
+		switch a := LoadBalancerAlgorithm(strings.ToLower(algorithm)); a {
+		case LoadBalancerAlgorithmHash, LoadBalancerAlgorithmMaglev:
+			info.loadBalancerAlgorithm = a
+		default:
+			klog.InfoS("Unsupported load balancer algorithm, falling back to the default algorithm", "service", klog.KObj(service), "algorithm", algorithm)
+		}
+	}
+	info.sessionAffinityOptions = types.DefaultSessionAffinityOptions
+	if value, ok := service.Annotations[types.ServiceSessionAffinityKeyAnnotationKey]; ok {
+		if key, valid := parseSessionAffinityKey(value); valid {
+			info.sessionAffinityOptions.Key = key
+		} else {
+			klog.InfoS("Unsupported session affinity key, falling back to the source IP", "service", klog.KObj(service), "key", value)
+		}
+	}
+	if timeoutType, ok := service.Annotations[types.ServiceSessionAffinityTimeoutTypeAnnotationKey]; ok {
+		switch strings.ToLower(timeoutType) {
+		case "idle":
+			info.sessionAffinityOptions.IdleTimeout = true
+		case "hard":
+		default:
+			klog.InfoS("Unsupported session affinity timeout type, falling back to hard timeout", "service", klog.KObj(service), "timeoutType", timeoutType)
+		}
+	}
+	if mode, ok := service.Annotations[types.ServiceSessionAffinityModeAnnotationKey]; ok {
+		switch strings.ToLower(mode) {
+		case "hash":
+			info.sessionAffinityOptions.Hash = true
+		case "learn":
+		default:
+			klog.InfoS("Unsupported session affinity mode, falling back to learn mode", "service", klog.KObj(service), "mode", mode)
+		}
+	}
 	return info
 }
 
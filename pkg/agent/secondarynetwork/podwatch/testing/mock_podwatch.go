@@ -21,7 +21,7 @@ package testing
 
 import (
 	current "github.com/containernetworking/cni/pkg/types/current"
-	gomock "github.com/golang/mock/gomock"
+	gomock "go.uber.org/mock/gomock"
 	reflect "reflect"
 )
 
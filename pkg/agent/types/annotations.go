@@ -26,4 +26,24 @@ const (
 
 	// ServiceExternalIPPoolAnnotationKey is the key of the Service annotation that specifies the Service's desired external IP pool.
 	ServiceExternalIPPoolAnnotationKey string = "service.antrea.io/external-ip-pool"
+
+	// ServiceLoadBalancerModeAnnotationKey is the key of the Service annotation that specifies the mode in which the
+	// traffic destined for the Service's LoadBalancer IPs is load-balanced.
+	ServiceLoadBalancerModeAnnotationKey string = "service.antrea.io/load-balancer-mode"
+
+	// ServiceLoadBalancerAlgorithmAnnotationKey is the key of the Service annotation that specifies the algorithm used
+	// to select an Endpoint for the Service's traffic.
+	ServiceLoadBalancerAlgorithmAnnotationKey string = "service.antrea.io/load-balancer-algorithm"
+
+	// ServiceSessionAffinityKeyAnnotationKey is the key of the Service annotation that specifies the packet fields used
+	// to identify a client for the Service's session affinity.
+	ServiceSessionAffinityKeyAnnotationKey string = "service.antrea.io/session-affinity-key"
+
+	// ServiceSessionAffinityTimeoutTypeAnnotationKey is the key of the Service annotation that specifies whether the
+	// timeout of the Service's session affinity is an idle timeout or a hard timeout.
+	ServiceSessionAffinityTimeoutTypeAnnotationKey string = "service.antrea.io/session-affinity-timeout-type"
+
+	// ServiceSessionAffinityModeAnnotationKey is the key of the Service annotation that specifies whether the Service's
+	// session affinity is maintained with learned flows or with the hash of the packet fields identifying a client.
+	ServiceSessionAffinityModeAnnotationKey string = "service.antrea.io/session-affinity-mode"
 )
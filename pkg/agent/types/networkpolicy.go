@@ -74,17 +74,19 @@ type Address interface {
 
 // PolicyRule groups configurations to set up conjunctive match for egress/ingress policy rules.
 type PolicyRule struct {
-	Direction     v1beta2.Direction
-	From          []Address
-	To            []Address
-	Service       []v1beta2.Service
-	Action        *secv1alpha1.RuleAction
-	Priority      *uint16
-	Name          string
-	FlowID        uint32
-	TableID       uint8
-	PolicyRef     *v1beta2.NetworkPolicyReference
-	EnableLogging bool
+	Direction        v1beta2.Direction
+	From             []Address
+	To               []Address
+	Service          []v1beta2.Service
+	Action           *secv1alpha1.RuleAction
+	Priority         *uint16
+	Name             string
+	FlowID           uint32
+	TableID          uint8
+	PolicyRef        *v1beta2.NetworkPolicyReference
+	EnableLogging    bool
+	LogRate          *int32
+	LogSamplingRatio *int32
 }
 
 // IsAntreaNetworkPolicyRule returns if a PolicyRule is created for Antrea NetworkPolicy types.
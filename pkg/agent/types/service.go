@@ -0,0 +1,49 @@
+// Copyright 2022 Antrea Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package types
+
+// SessionAffinityKey is the set of packet fields used to identify a client for Service session affinity. The
+// destination IP, the protocol and the destination port of the packet are always used, so that the affinity is
+// maintained per Service port.
+type SessionAffinityKey uint8
+
+const (
+	// SessionAffinityKeySrcIP indicates that the source IP of the packet is used to identify a client.
+	SessionAffinityKeySrcIP SessionAffinityKey = 1 << iota
+	// SessionAffinityKeySrcPort indicates that the source port of the packet is used to identify a client.
+	SessionAffinityKeySrcPort
+)
+
+// Has returns whether the key includes the given fields.
+func (k SessionAffinityKey) Has(fields SessionAffinityKey) bool {
+	return k&fields == fields
+}
+
+// SessionAffinityOptions describes how the session affinity of a Service is maintained.
+type SessionAffinityOptions struct {
+	// Key is the set of packet fields used to identify a client.
+	Key SessionAffinityKey
+	// IdleTimeout indicates that the affinity of a client expires only after the client has been idle for the
+	// timeout. Otherwise, the affinity expires after the timeout since it was created, regardless of the traffic.
+	IdleTimeout bool
+	// Hash indicates that the Endpoint is selected with the hash of the packet fields identifying a client, instead
+	// of being remembered with learned flows. The affinity doesn't expire, but a client may be moved to another
+	// Endpoint when the Endpoints of the Service change.
+	Hash bool
+}
+
+// DefaultSessionAffinityOptions are the options of the ClientIP session affinity defined by Kubernetes: a client is
+// identified by its source IP, and the affinity expires after the timeout since it was created.
+var DefaultSessionAffinityOptions = SessionAffinityOptions{Key: SessionAffinityKeySrcIP}
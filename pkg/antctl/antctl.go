@@ -19,6 +19,7 @@ import (
 	"reflect"
 
 	"antrea.io/antrea/pkg/agent/apiserver/handlers/agentinfo"
+	"antrea.io/antrea/pkg/agent/apiserver/handlers/fqdncache"
 	"antrea.io/antrea/pkg/agent/apiserver/handlers/multicast"
 	"antrea.io/antrea/pkg/agent/apiserver/handlers/ovsflows"
 	"antrea.io/antrea/pkg/agent/apiserver/handlers/podinterface"
@@ -27,6 +28,7 @@ import (
 	fallbackversion "antrea.io/antrea/pkg/antctl/fallback/version"
 	"antrea.io/antrea/pkg/antctl/raw/featuregates"
 	"antrea.io/antrea/pkg/antctl/raw/multicluster"
+	"antrea.io/antrea/pkg/antctl/raw/packetcapture"
 	"antrea.io/antrea/pkg/antctl/raw/proxy"
 	"antrea.io/antrea/pkg/antctl/raw/set"
 	"antrea.io/antrea/pkg/antctl/raw/supportbundle"
@@ -568,6 +570,42 @@ $ antctl get podmulticaststats pod -n namespace`,
 			},
 			transformedResponse: reflect.TypeOf(serviceexternalip.Response{}),
 		},
+		{
+			use:   "fqdncache",
+			short: "Print the FQDN cache of the local Antrea agent",
+			long:  "Print the FQDN selectors of FQDN policy rules, the FQDNs they match, the IP addresses resolved for these FQDNs with the expiration time and remaining TTL of each address, and the rules using each selector",
+			example: `  Print the whole FQDN cache
+  $ antctl get fqdncache
+  Print the FQDN cache used by the rules applied to a local Pod
+  $ antctl get fqdncache -p pod1 -n ns1
+  Print the FQDN cache for the FQDNs matching a wildcard domain
+  $ antctl get fqdncache -d *.example.com`,
+			commandGroup: get,
+			agentEndpoint: &endpoint{
+				nonResourceEndpoint: &nonResourceEndpoint{
+					path: "/fqdncache",
+					params: []flagInfo{
+						{
+							name:      "namespace",
+							usage:     "Namespace of the Pod",
+							shorthand: "n",
+						},
+						{
+							name:      "pod",
+							usage:     "Name of a local Pod. If present, Namespace must be provided.",
+							shorthand: "p",
+						},
+						{
+							name:      "domain",
+							usage:     "Only print the FQDNs matching the domain name, which can contain * as wildcard",
+							shorthand: "d",
+						},
+					},
+					outputType: multiple,
+				},
+			},
+			transformedResponse: reflect.TypeOf(fqdncache.Response{}),
+		},
 	},
 	rawCommands: []rawCommand{
 		{
@@ -580,6 +618,11 @@ $ antctl get podmulticaststats pod -n namespace`,
 			supportAgent:      true,
 			supportController: true,
 		},
+		{
+			cobraCommand:      packetcapture.Command,
+			supportAgent:      true,
+			supportController: true,
+		},
 		{
 			cobraCommand:      proxy.Command,
 			supportAgent:      false,
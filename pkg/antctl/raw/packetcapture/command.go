@@ -0,0 +1,249 @@
+// Copyright 2022 Antrea Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package packetcapture
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/rand"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"k8s.io/client-go/rest"
+	"k8s.io/klog/v2"
+
+	"antrea.io/antrea/pkg/antctl/raw"
+	"antrea.io/antrea/pkg/antctl/raw/traceflow"
+	"antrea.io/antrea/pkg/apis/crd/v1alpha1"
+	antrea "antrea.io/antrea/pkg/client/clientset/versioned"
+)
+
+const (
+	defaultDuration = 60 * time.Second
+	maxDuration     = time.Hour
+	// waitGracePeriod is the time given to the Antrea Agent to report the
+	// result of the PacketCapture after the capture duration has elapsed.
+	waitGracePeriod = 30 * time.Second
+)
+
+var (
+	Command *cobra.Command
+	option  = &struct {
+		source       string
+		destination  string
+		flow         string
+		number       int32
+		duration     time.Duration
+		capturePoint string
+		outputFile   string
+		nowait       bool
+	}{}
+)
+
+func init() {
+	Command = &cobra.Command{
+		Use:     "packetcapture",
+		Short:   "Capture packets of a Pod",
+		Long:    "Capture the packets of a Pod to or from another Pod or IP, and save them to a pcapng file.",
+		Aliases: []string{"pcap", "packetcaptures"},
+		Example: `  Capture the packets from pod1 to pod2, both Pods are in Namespace default, for 60 seconds
+  $antctl packetcapture -S pod1 -D pod2
+  Capture 10 TCP packets from pod1 in Namespace ns1 to port 80 of a destination IP, and save them to capture.pcapng
+  $antctl packetcapture -S ns1/pod1 -D 10.10.0.10 -f tcp,tcp_dst=80 -n 10 -o capture.pcapng
+  Capture the UDP packets from an IP to pod1 on pod1 for 5 minutes
+  $antctl packetcapture -S 10.10.0.10 -D pod1 -f udp --capture-point Destination --duration 5m
+`,
+		RunE: runE,
+		Args: cobra.NoArgs,
+	}
+
+	Command.Flags().StringVarP(&option.source, "source", "S", "", "source of the packets: Namespace/Pod, Pod, or IP")
+	Command.Flags().StringVarP(&option.destination, "destination", "D", "", "destination of the packets: Namespace/Pod, Pod, or IP")
+	Command.Flags().StringVarP(&option.flow, "flow", "f", "", "specify the packet headers to match, including tcp_src, tcp_dst, udp_src, udp_dst, ipv6")
+	Command.Flags().Int32VarP(&option.number, "number", "n", 0, "number of packets to capture, no limit if not set")
+	Command.Flags().DurationVarP(&option.duration, "duration", "", defaultDuration, "maximum duration of the capture")
+	Command.Flags().StringVarP(&option.capturePoint, "capture-point", "", string(v1alpha1.CapturePointSource), "Pod on which the packets are captured: Source or Destination")
+	Command.Flags().StringVarP(&option.outputFile, "output", "o", "", "file to save the captured packets to, <PacketCapture name>.pcapng if not set")
+	Command.Flags().BoolVarP(&option.nowait, "nowait", "", false, "if set, command returns without waiting for the captured packets")
+}
+
+func runE(cmd *cobra.Command, _ []string) error {
+	if option.duration <= 0 || option.duration > maxDuration {
+		return fmt.Errorf("duration must be positive and no longer than %v", maxDuration)
+	}
+	if option.number < 0 {
+		return errors.New("number of packets cannot be negative")
+	}
+	pc, err := newPacketCapture()
+	if err != nil {
+		return fmt.Errorf("error when filling up PacketCapture config: %w", err)
+	}
+
+	kubeconfig, err := raw.ResolveKubeconfig(cmd)
+	if err != nil {
+		return err
+	}
+	_, client, err := raw.SetupClients(kubeconfig)
+	if err != nil {
+		return fmt.Errorf("failed to create clientset: %w", err)
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if _, err := client.CrdV1alpha1().PacketCaptures().Create(ctx, pc, metav1.CreateOptions{}); err != nil {
+		return fmt.Errorf("error when creating PacketCapture, is PacketCapture feature gate enabled? %w", err)
+	}
+	if option.nowait {
+		fmt.Fprintf(cmd.OutOrStdout(), "PacketCapture %s created\n", pc.Name)
+		return nil
+	}
+	defer func() {
+		if err := client.CrdV1alpha1().PacketCaptures().Delete(context.TODO(), pc.Name, metav1.DeleteOptions{}); err != nil {
+			klog.ErrorS(err, "Error when deleting PacketCapture", "name", pc.Name)
+		}
+	}()
+
+	var res *v1alpha1.PacketCapture
+	err = wait.Poll(time.Second, option.duration+waitGracePeriod, func() (bool, error) {
+		res, err = client.CrdV1alpha1().PacketCaptures().Get(context.TODO(), pc.Name, metav1.GetOptions{})
+		if err != nil {
+			return false, err
+		}
+		return res.Status.Phase == v1alpha1.PacketCaptureSucceeded || res.Status.Phase == v1alpha1.PacketCaptureFailed, nil
+	})
+	if err == wait.ErrWaitTimeout {
+		return errors.New("timeout waiting for PacketCapture done")
+	} else if err != nil {
+		return fmt.Errorf("error when retrieving PacketCapture: %w", err)
+	}
+	if res.Status.Phase == v1alpha1.PacketCaptureFailed {
+		return fmt.Errorf("PacketCapture failed: %s", res.Status.Reason)
+	}
+
+	outputFile := option.outputFile
+	if outputFile == "" {
+		outputFile = pc.Name + ".pcapng"
+	}
+	if err := download(client, kubeconfig, res, outputFile); err != nil {
+		return err
+	}
+	fmt.Fprintf(cmd.OutOrStdout(), "Captured %d packets to %s: %s\n", res.Status.NumberCaptured, outputFile, res.Status.Reason)
+	return nil
+}
+
+// download downloads the pcapng file of a PacketCapture from the Antrea Agent
+// of the Node on which the packets were captured.
+func download(client antrea.Interface, kubeconfig *rest.Config, pc *v1alpha1.PacketCapture, outputFile string) error {
+	restconfigTmpl := rest.CopyConfig(kubeconfig)
+	raw.SetupKubeconfig(restconfigTmpl)
+	k8sClientset, _, err := raw.SetupClients(kubeconfig)
+	if err != nil {
+		return fmt.Errorf("failed to create clientset: %w", err)
+	}
+	agentCfg, err := raw.CreateAgentClientCfg(k8sClientset, client, restconfigTmpl, pc.Status.Node)
+	if err != nil {
+		return fmt.Errorf("error when creating Agent client config: %w", err)
+	}
+	agentClient, err := rest.UnversionedRESTClientFor(agentCfg)
+	if err != nil {
+		return fmt.Errorf("error when creating Agent client: %w", err)
+	}
+	stream, err := agentClient.Get().AbsPath("/packetcapture").Param("name", pc.Name).Stream(context.TODO())
+	if err != nil {
+		return fmt.Errorf("error when downloading the captured packets: %w", err)
+	}
+	defer stream.Close()
+	f, err := os.Create(outputFile)
+	if err != nil {
+		return fmt.Errorf("error when creating the output file: %w", err)
+	}
+	defer f.Close()
+	if _, err := io.Copy(f, stream); err != nil {
+		return fmt.Errorf("error when downloading the captured packets: %w", err)
+	}
+	return nil
+}
+
+// parseEndpoint parses a Namespace/Pod, Pod or IP, and returns its Namespace,
+// Pod name and IP, as well as the name used for the PacketCapture name.
+func parseEndpoint(endpoint string) (namespace, pod, ip, name string, err error) {
+	if parsedIP := net.ParseIP(endpoint); parsedIP != nil {
+		return "", "", parsedIP.String(), parsedIP.String(), nil
+	}
+	split := strings.Split(endpoint, "/")
+	if len(split) == 1 && len(split[0]) != 0 {
+		return "default", split[0], "", split[0], nil
+	} else if len(split) == 2 && len(split[0]) != 0 && len(split[1]) != 0 {
+		return split[0], split[1], "", fmt.Sprintf("%s-%s", split[0], split[1]), nil
+	}
+	return "", "", "", "", fmt.Errorf("%s should be in the format of Namespace/Pod or Pod, or an IP address", endpoint)
+}
+
+func newPacketCapture() (*v1alpha1.PacketCapture, error) {
+	srcName, dstName := "any", "any"
+	var src v1alpha1.Source
+	var dst v1alpha1.Destination
+	var err error
+	if option.source != "" {
+		if src.Namespace, src.Pod, src.IP, srcName, err = parseEndpoint(option.source); err != nil {
+			return nil, fmt.Errorf("invalid source: %w", err)
+		}
+	}
+	if option.destination != "" {
+		if dst.Namespace, dst.Pod, dst.IP, dstName, err = parseEndpoint(option.destination); err != nil {
+			return nil, fmt.Errorf("invalid destination: %w", err)
+		}
+	}
+	capturePoint := v1alpha1.CapturePoint(option.capturePoint)
+	switch capturePoint {
+	case v1alpha1.CapturePointSource:
+		if src.Pod == "" {
+			return nil, errors.New("source must be a Pod when capturing packets on the source")
+		}
+	case v1alpha1.CapturePointDestination:
+		if dst.Pod == "" {
+			return nil, errors.New("destination must be a Pod when capturing packets on the destination")
+		}
+	default:
+		return nil, fmt.Errorf("capture point must be %s or %s", v1alpha1.CapturePointSource, v1alpha1.CapturePointDestination)
+	}
+
+	pkt, err := traceflow.ParseFlow(option.flow)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse flow: %w", err)
+	}
+	pc := &v1alpha1.PacketCapture{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: fmt.Sprintf("%s-to-%s-%s", srcName, dstName, rand.String(8)),
+		},
+		Spec: v1alpha1.PacketCaptureSpec{
+			Source:      src,
+			Destination: dst,
+			Packet:      *pkt,
+			CaptureConfig: v1alpha1.CaptureConfig{
+				Number:   option.number,
+				Duration: uint16(option.duration.Seconds()),
+			},
+			CapturePoint: capturePoint,
+		},
+	}
+	return pc, nil
+}
@@ -0,0 +1,113 @@
+// Copyright 2022 Antrea Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package packetcapture
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"antrea.io/antrea/pkg/apis/crd/v1alpha1"
+)
+
+func TestNewPacketCapture(t *testing.T) {
+	protocolTCP := int32(6)
+	for _, tc := range []struct {
+		name         string
+		source       string
+		destination  string
+		flow         string
+		number       int32
+		duration     time.Duration
+		capturePoint string
+		expectedName string
+		expectedSpec v1alpha1.PacketCaptureSpec
+		expectedErr  string
+	}{
+		{
+			name:         "Pod to Pod",
+			source:       "pod1",
+			destination:  "ns2/pod2",
+			flow:         "tcp,tcp_dst=80",
+			number:       10,
+			duration:     30 * time.Second,
+			capturePoint: "Source",
+			expectedName: "pod1-to-ns2-pod2-",
+			expectedSpec: v1alpha1.PacketCaptureSpec{
+				Source:      v1alpha1.Source{Namespace: "default", Pod: "pod1"},
+				Destination: v1alpha1.Destination{Namespace: "ns2", Pod: "pod2"},
+				Packet: v1alpha1.Packet{
+					IPHeader:        v1alpha1.IPHeader{Protocol: protocolTCP},
+					TransportHeader: v1alpha1.TransportHeader{TCP: &v1alpha1.TCPHeader{DstPort: 80}},
+				},
+				CaptureConfig: v1alpha1.CaptureConfig{Number: 10, Duration: 30},
+				CapturePoint:  v1alpha1.CapturePointSource,
+			},
+		},
+		{
+			name:         "IP to Pod",
+			source:       "10.10.0.10",
+			destination:  "pod1",
+			duration:     time.Minute,
+			capturePoint: "Destination",
+			expectedName: "10.10.0.10-to-pod1-",
+			expectedSpec: v1alpha1.PacketCaptureSpec{
+				Source:        v1alpha1.Source{IP: "10.10.0.10"},
+				Destination:   v1alpha1.Destination{Namespace: "default", Pod: "pod1"},
+				CaptureConfig: v1alpha1.CaptureConfig{Duration: 60},
+				CapturePoint:  v1alpha1.CapturePointDestination,
+			},
+		},
+		{
+			name:         "source is not a Pod",
+			source:       "10.10.0.10",
+			destination:  "pod1",
+			capturePoint: "Source",
+			expectedErr:  "source must be a Pod when capturing packets on the source",
+		},
+		{
+			name:         "invalid capture point",
+			source:       "pod1",
+			capturePoint: "Middle",
+			expectedErr:  "capture point must be Source or Destination",
+		},
+		{
+			name:         "invalid destination",
+			source:       "pod1",
+			destination:  "ns1/",
+			capturePoint: "Source",
+			expectedErr:  "invalid destination",
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			option.source = tc.source
+			option.destination = tc.destination
+			option.flow = tc.flow
+			option.number = tc.number
+			option.duration = tc.duration
+			option.capturePoint = tc.capturePoint
+			pc, err := newPacketCapture()
+			if tc.expectedErr != "" {
+				assert.ErrorContains(t, err, tc.expectedErr)
+				return
+			}
+			require.NoError(t, err)
+			assert.Contains(t, pc.Name, tc.expectedName)
+			assert.Equal(t, tc.expectedSpec, pc.Spec)
+		})
+	}
+}
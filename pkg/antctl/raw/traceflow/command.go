@@ -43,14 +43,16 @@ const defaultTimeout time.Duration = time.Second * 10
 var (
 	Command *cobra.Command
 	option  = &struct {
-		source      string
-		destination string
-		outputType  string
-		flow        string
-		liveTraffic bool
-		droppedOnly bool
-		timeout     time.Duration
-		nowait      bool
+		source        string
+		sourceNode    string
+		destination   string
+		outputType    string
+		flow          string
+		liveTraffic   bool
+		droppedOnly   bool
+		bidirectional bool
+		timeout       time.Duration
+		nowait        bool
 	}{}
 )
 
@@ -77,6 +79,7 @@ type Response struct {
 	Source         string                  `json:"source,omitempty" yaml:"source,omitempty"`                 // Traceflow source, e.g. "default/pod0"
 	Destination    string                  `json:"destination,omitempty" yaml:"destination,omitempty"`       // Traceflow destination, e.g. "default/pod1"
 	NodeResults    []v1alpha1.NodeResult   `json:"results,omitempty" yaml:"results,omitempty"`               // Traceflow node results
+	ReplyResults   []v1alpha1.NodeResult   `json:"replyResults,omitempty" yaml:"replyResults,omitempty"`     // Traceflow node results of the reply packet
 	CapturedPacket *CapturedPacket         `json:"capturedPacket,omitempty" yaml:"capturedPacket,omitempty"` // Captured packet in live-traffic Traceflow
 }
 
@@ -94,6 +97,12 @@ func init() {
   $antctl traceflow -S pod1 -D ns1/svc1 -f tcp,tcp_dst=80
   Start a Traceflow from pod1 to pod2, with a UDP packet to destination port 1234
   $antctl traceflow -S pod1 -D pod2 -f udp,udp_dst=1234
+  Start a Traceflow from pod1 to pod2 which also traces the TCP SYN/ACK reply packet
+  $antctl traceflow -S pod1 -D pod2 -f tcp,tcp_dst=80 --bidirectional
+  Start a Traceflow from Node node1 to pod1, with a TCP packet to destination port 80
+  $antctl traceflow --source-node node1 -D pod1 -f tcp,tcp_dst=80
+  Start a Traceflow from an external IP to NodePort 30080 of Node node1 (192.168.0.1), entering the cluster through node1
+  $antctl traceflow -S 172.16.0.1 --source-node node1 -D 192.168.0.1 -f tcp,tcp_dst=30080
   Start a Traceflow for live TCP traffic from pod1 to svc1, with 1 minute timeout
   $antctl traceflow -S pod1 -D svc1 -f tcp --live-traffic -t 1m
   Start a Traceflow to capture the first dropped TCP packet to pod1 on port 80, within 10 minutes
@@ -104,11 +113,13 @@ func init() {
 	}
 
 	Command.Flags().StringVarP(&option.source, "source", "S", "", "source of the Traceflow: Namespace/Pod, Pod, or IP")
+	Command.Flags().StringVarP(&option.sourceNode, "source-node", "", "", "source Node of the Traceflow, from which the packet is sent, or through which the packet from the source IP enters the cluster")
 	Command.Flags().StringVarP(&option.destination, "destination", "D", "", "destination of the Traceflow: Namespace/Pod, Pod, Namespace/Service, Service or IP")
 	Command.Flags().StringVarP(&option.outputType, "output", "o", "yaml", "output type: yaml (default), json")
 	Command.Flags().StringVarP(&option.flow, "flow", "f", "", "specify the flow (packet headers) of the Traceflow packet, including tcp_src, tcp_dst, tcp_flags, udp_src, udp_dst, ipv6")
 	Command.Flags().BoolVarP(&option.liveTraffic, "live-traffic", "L", false, "if set, the Traceflow will trace the first packet of the matched live traffic flow")
 	Command.Flags().BoolVarP(&option.droppedOnly, "dropped-only", "", false, "if set, capture only the dropped packet in a live-traffic Traceflow")
+	Command.Flags().BoolVarP(&option.bidirectional, "bidirectional", "", false, "if set, the reply packet will also be generated by the destination and traced")
 	Command.Flags().BoolVarP(&option.nowait, "nowait", "", false, "if set, command returns without retrieving results")
 }
 
@@ -122,7 +133,7 @@ func runE(cmd *cobra.Command, _ []string) error {
 		option.timeout = defaultTimeout
 	}
 
-	if !option.liveTraffic && option.source == "" {
+	if !option.liveTraffic && option.source == "" && option.sourceNode == "" {
 		fmt.Println("Please provide source")
 		return nil
 	}
@@ -142,6 +153,16 @@ func runE(cmd *cobra.Command, _ []string) error {
 		return nil
 	}
 
+	if option.liveTraffic && option.sourceNode != "" {
+		fmt.Println("--source-node does not work with live-traffic Traceflow")
+		return nil
+	}
+
+	if option.liveTraffic && option.bidirectional {
+		fmt.Println("--bidirectional does not work with live-traffic Traceflow")
+		return nil
+	}
+
 	kubeconfig, err := raw.ResolveKubeconfig(cmd)
 	if err != nil {
 		return err
@@ -205,14 +226,20 @@ func newTraceflow(client kubernetes.Interface) (*v1alpha1.Traceflow, error) {
 	var srcName, dstName string
 	var src v1alpha1.Source
 
+	if option.sourceNode != "" {
+		src.Node = option.sourceNode
+		srcName = src.Node
+	}
 	if option.source != "" {
 		srcIP := net.ParseIP(option.source)
 		if srcIP != nil {
-			if !option.liveTraffic {
-				return nil, errors.New("source must be a Pod if not a live-traffic Traceflow")
+			if !option.liveTraffic && src.Node == "" {
+				return nil, errors.New("source must be a Pod, or an IP with the source Node if not a live-traffic Traceflow")
 			}
 			src.IP = srcIP.String()
 			srcName = src.IP
+		} else if src.Node != "" {
+			return nil, errors.New("source must be an IP if the source Node is provided")
 		} else {
 			split := strings.Split(option.source, "/")
 			if len(split) == 1 {
@@ -227,7 +254,7 @@ func newTraceflow(client kubernetes.Interface) (*v1alpha1.Traceflow, error) {
 				return nil, errors.New("source should be in the format of Namespace/Pod or Pod, or an IP address")
 			}
 		}
-	} else {
+	} else if src.Node == "" {
 		srcName = "any"
 	}
 
@@ -266,7 +293,7 @@ func newTraceflow(client kubernetes.Interface) (*v1alpha1.Traceflow, error) {
 		dstName = "any"
 	}
 
-	if src.Pod == "" && dst.Pod == "" {
+	if src.Pod == "" && src.Node == "" && dst.Pod == "" {
 		return nil, errors.New("one of source and destination must be a Pod")
 	}
 
@@ -281,12 +308,13 @@ func newTraceflow(client kubernetes.Interface) (*v1alpha1.Traceflow, error) {
 			Name: name,
 		},
 		Spec: v1alpha1.TraceflowSpec{
-			Source:      src,
-			Destination: dst,
-			Packet:      *pkt,
-			LiveTraffic: option.liveTraffic,
-			DroppedOnly: option.droppedOnly,
-			Timeout:     uint16(option.timeout.Seconds()),
+			Source:        src,
+			Destination:   dst,
+			Packet:        *pkt,
+			LiveTraffic:   option.liveTraffic,
+			DroppedOnly:   option.droppedOnly,
+			Timeout:       uint16(option.timeout.Seconds()),
+			Bidirectional: option.bidirectional,
 		},
 	}
 	return tf, nil
@@ -306,7 +334,13 @@ func dstIsPod(client kubernetes.Interface, ns string, name string) (bool, error)
 }
 
 func parseFlow() (*v1alpha1.Packet, error) {
-	cleanFlow := strings.ReplaceAll(option.flow, " ", "")
+	return ParseFlow(option.flow)
+}
+
+// ParseFlow parses the packet headers specified with the --flow flag, e.g.
+// "tcp,tcp_dst=80", which is also used by the packetcapture command.
+func ParseFlow(flow string) (*v1alpha1.Packet, error) {
+	cleanFlow := strings.ReplaceAll(flow, " ", "")
 	fields, err := getPortFields(cleanFlow)
 	if err != nil {
 		return nil, fmt.Errorf("error when parsing the flow: %w", err)
@@ -383,11 +417,19 @@ func getPortFields(cleanFlow string) (map[string]int, error) {
 
 func output(tf *v1alpha1.Traceflow) error {
 	r := Response{
-		Name:        tf.Name,
-		Phase:       tf.Status.Phase,
-		Reason:      tf.Status.Reason,
-		Source:      fmt.Sprintf("%s/%s", tf.Spec.Source.Namespace, tf.Spec.Source.Pod),
-		NodeResults: tf.Status.Results,
+		Name:         tf.Name,
+		Phase:        tf.Status.Phase,
+		Reason:       tf.Status.Reason,
+		Source:       fmt.Sprintf("%s/%s", tf.Spec.Source.Namespace, tf.Spec.Source.Pod),
+		NodeResults:  tf.Status.Results,
+		ReplyResults: tf.Status.ReplyResults,
+	}
+	if len(tf.Spec.Source.Node) > 0 {
+		if len(tf.Spec.Source.IP) > 0 {
+			r.Source = fmt.Sprintf("%s (through Node %s)", tf.Spec.Source.IP, tf.Spec.Source.Node)
+		} else {
+			r.Source = fmt.Sprintf("Node %s", tf.Spec.Source.Node)
+		}
 	}
 	if len(tf.Spec.Destination.IP) > 0 {
 		r.Destination = tf.Spec.Destination.IP
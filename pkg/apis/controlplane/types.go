@@ -217,6 +217,9 @@ type NetworkPolicy struct {
 	// TierPriority represents the priority of the Tier associated with this NetworkPolicy.
 	// The TierPriority will remain nil for K8s NetworkPolicy.
 	TierPriority *int32
+	// Tier is the name of the Tier associated with this NetworkPolicy.
+	// The Tier will remain empty for K8s NetworkPolicy.
+	Tier string
 	// Reference to the original NetworkPolicy that the internal NetworkPolicy is created for.
 	SourceRef *NetworkPolicyReference
 }
@@ -254,6 +257,12 @@ type NetworkPolicyRule struct {
 	// EnableLogging is used to indicate if agent should generate logs
 	// when rules are matched. Should be default to false.
 	EnableLogging bool
+	// LogRate is the maximum number of packets matching this rule that are logged
+	// per second on each Node. nil means the rate is not limited per rule.
+	LogRate *int32
+	// LogSamplingRatio indicates that only one out of every LogSamplingRatio connections
+	// matching this rule is logged. nil means all connections are logged.
+	LogSamplingRatio *int32
 	// AppliedToGroups is a list of names of AppliedToGroups to which this rule applies.
 	// Cannot be set in conjunction with NetworkPolicy.AppliedToGroups of the NetworkPolicy
 	// that this Rule is referred to.
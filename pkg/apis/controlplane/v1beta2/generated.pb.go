@@ -983,143 +983,147 @@ func init() {
 }
 
 var fileDescriptor_fbaa7d016762fa1d = []byte{
-	// 2176 bytes of a gzipped FileDescriptorProto
-	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0xec, 0x1a, 0xcd, 0x6f, 0x1b, 0x59,
-	0xbd, 0xe3, 0x8f, 0x24, 0xfe, 0xd9, 0x69, 0x9d, 0x97, 0x96, 0x9a, 0xa5, 0xd8, 0xdd, 0x59, 0x40,
-	0x3d, 0xc0, 0x78, 0x13, 0xda, 0x6d, 0x60, 0xb7, 0x0b, 0x71, 0x92, 0x46, 0x96, 0xda, 0xd4, 0xbc,
-	0x64, 0x55, 0x09, 0xe8, 0xb2, 0x93, 0x99, 0x67, 0xe7, 0x11, 0x7b, 0x66, 0x98, 0x79, 0x0e, 0x8d,
-	0x90, 0xd0, 0x22, 0xe0, 0xb0, 0x80, 0x04, 0x37, 0xc4, 0x0d, 0x4e, 0x5c, 0xf8, 0x0b, 0xf6, 0xc6,
-	0xad, 0xe2, 0xb4, 0x08, 0x21, 0xf6, 0x64, 0x51, 0x23, 0x40, 0x1c, 0xe0, 0x0f, 0x08, 0x17, 0xf4,
-	0xde, 0xbc, 0x99, 0x79, 0x63, 0x27, 0x4d, 0x9d, 0xa4, 0x41, 0x82, 0x3d, 0xc5, 0x7e, 0xbf, 0xef,
-	0xf7, 0xfb, 0x7e, 0x0e, 0xbc, 0x69, 0x3a, 0xcc, 0x27, 0xa6, 0x41, 0xdd, 0x7a, 0xf8, 0xa9, 0xee,
-	0xed, 0x76, 0xea, 0xa6, 0x47, 0x83, 0xba, 0xe5, 0x3a, 0xcc, 0x77, 0xbb, 0x5e, 0xd7, 0x74, 0x48,
-	0x7d, 0x6f, 0x61, 0x9b, 0x30, 0x73, 0xb1, 0xde, 0x21, 0x0e, 0xf1, 0x4d, 0x46, 0x6c, 0xc3, 0xf3,
-	0x5d, 0xe6, 0x22, 0x23, 0xa4, 0xfa, 0x06, 0x75, 0xe5, 0x27, 0xc3, 0xdb, 0xed, 0x18, 0x9c, 0xde,
-	0x50, 0xe9, 0x0d, 0x49, 0xff, 0xd2, 0xd2, 0xd1, 0xf2, 0x02, 0x66, 0xb2, 0xa0, 0xbe, 0xb7, 0x60,
-	0x76, 0xbd, 0x1d, 0x73, 0x61, 0x54, 0xd2, 0x4b, 0x9f, 0xeb, 0x50, 0xb6, 0xd3, 0xdf, 0x36, 0x2c,
-	0xb7, 0x57, 0xef, 0xb8, 0x1d, 0xb7, 0x2e, 0x8e, 0xb7, 0xfb, 0x6d, 0xf1, 0x4d, 0x7c, 0x11, 0x9f,
-	0x24, 0xfa, 0xcd, 0xdd, 0xa5, 0x40, 0x48, 0xf1, 0x68, 0xcf, 0xb4, 0x76, 0xa8, 0x43, 0xfc, 0xfd,
-	0x44, 0x56, 0x8f, 0x30, 0xb3, 0xbe, 0x37, 0x2e, 0xa4, 0x7e, 0x14, 0x95, 0xdf, 0x77, 0x18, 0xed,
-	0x91, 0x31, 0x82, 0xd7, 0x8e, 0x23, 0x08, 0xac, 0x1d, 0xd2, 0x33, 0xc7, 0xe8, 0x3e, 0x7f, 0x14,
-	0x5d, 0x9f, 0xd1, 0x6e, 0x9d, 0x3a, 0x2c, 0x60, 0xfe, 0x28, 0x91, 0xfe, 0x77, 0x0d, 0x4a, 0xcb,
-	0xb6, 0xed, 0x93, 0x20, 0x58, 0xf7, 0xdd, 0xbe, 0x87, 0xde, 0x81, 0x19, 0x6e, 0x89, 0x6d, 0x32,
-	0xb3, 0xa2, 0x5d, 0xd7, 0x6e, 0x14, 0x17, 0x5f, 0x35, 0x42, 0xc6, 0x86, 0xca, 0x38, 0xf1, 0x09,
-	0xc7, 0x36, 0xf6, 0x16, 0x8c, 0x07, 0xdb, 0xdf, 0x24, 0x16, 0xbb, 0x4f, 0x98, 0xd9, 0x40, 0x4f,
-	0x06, 0xb5, 0x0b, 0xc3, 0x41, 0x0d, 0x92, 0x33, 0x1c, 0x73, 0x45, 0x7d, 0x28, 0x75, 0xb8, 0xa8,
-	0xfb, 0xa4, 0xb7, 0x4d, 0xfc, 0xa0, 0x92, 0xb9, 0x9e, 0xbd, 0x51, 0x5c, 0x7c, 0x7d, 0x42, 0xb7,
-	0x1b, 0xeb, 0x09, 0x8f, 0xc6, 0x65, 0x29, 0xb0, 0xa4, 0x1c, 0x06, 0x38, 0x25, 0x46, 0xff, 0x83,
-	0x06, 0x65, 0xd5, 0xd2, 0x7b, 0x34, 0x60, 0xe8, 0xeb, 0x63, 0xd6, 0x1a, 0xcf, 0x67, 0x2d, 0xa7,
-	0x16, 0xb6, 0x96, 0xa5, 0xe8, 0x99, 0xe8, 0x44, 0xb1, 0xd4, 0x84, 0x3c, 0x65, 0xa4, 0x17, 0x99,
-	0xf8, 0xc6, 0xa4, 0x26, 0xaa, 0xea, 0x36, 0x66, 0xa5, 0xa0, 0x7c, 0x93, 0xb3, 0xc4, 0x21, 0x67,
-	0xfd, 0xbd, 0x2c, 0xcc, 0xa9, 0x68, 0x2d, 0x93, 0x59, 0x3b, 0xe7, 0xe0, 0xc4, 0x1f, 0x68, 0x30,
-	0x67, 0xda, 0x36, 0xb1, 0xd7, 0xcf, 0xd8, 0x95, 0x1f, 0x97, 0x62, 0xb9, 0x55, 0x69, 0xee, 0x78,
-	0x5c, 0x20, 0xfa, 0x91, 0x06, 0xf3, 0x3e, 0xe9, 0xb9, 0x7b, 0x23, 0x8a, 0x64, 0x4f, 0xaf, 0xc8,
-	0x27, 0xa4, 0x22, 0xf3, 0x78, 0x9c, 0x3f, 0x3e, 0x4c, 0xa8, 0xfe, 0x0f, 0x0d, 0x2e, 0x2e, 0x7b,
-	0x5e, 0x97, 0x12, 0x7b, 0xcb, 0xfd, 0x1f, 0xcf, 0xa6, 0x3f, 0x69, 0x80, 0xd2, 0xb6, 0x9e, 0x43,
-	0x3e, 0x59, 0xe9, 0x7c, 0x7a, 0x73, 0xe2, 0x7c, 0x4a, 0x29, 0x7c, 0x44, 0x46, 0xfd, 0x38, 0x0b,
-	0xf3, 0x69, 0xc4, 0x8f, 0x72, 0xea, 0xbf, 0x97, 0x53, 0xbf, 0xcc, 0xc1, 0xfc, 0x4a, 0xb7, 0x1f,
-	0x30, 0xe2, 0xa7, 0x94, 0x7c, 0xf1, 0xde, 0xf8, 0x9e, 0x06, 0x65, 0xd2, 0x6e, 0x13, 0x8b, 0xd1,
-	0x3d, 0x72, 0x86, 0xce, 0xa8, 0x48, 0xa9, 0xe5, 0xb5, 0x11, 0xe6, 0x78, 0x4c, 0x1c, 0xfa, 0x2e,
-	0xcc, 0xc5, 0x67, 0xcd, 0x56, 0xa3, 0xeb, 0x5a, 0xbb, 0x91, 0x1f, 0x6e, 0x4d, 0xaa, 0x43, 0xb3,
-	0xb5, 0x41, 0x58, 0x12, 0x0a, 0x6b, 0xa3, 0x7c, 0xf1, 0xb8, 0x28, 0xb4, 0x04, 0x25, 0xe6, 0x32,
-	0xb3, 0x1b, 0x99, 0x9f, 0xbb, 0xae, 0xdd, 0xc8, 0x26, 0xf5, 0x61, 0x4b, 0x81, 0xe1, 0x14, 0x26,
-	0x5a, 0x04, 0x10, 0xdf, 0x5b, 0x66, 0x87, 0x04, 0x95, 0xbc, 0xa0, 0x8b, 0xef, 0x7b, 0x2b, 0x86,
-	0x60, 0x05, 0x0b, 0xdd, 0x82, 0xa2, 0xd5, 0xf7, 0x7d, 0xe2, 0x30, 0xfe, 0xbd, 0x32, 0x25, 0x88,
-	0xe6, 0x25, 0x51, 0x71, 0x25, 0x01, 0x61, 0x15, 0x4f, 0xff, 0x9b, 0x06, 0xc5, 0xb5, 0xce, 0xff,
-	0xc1, 0x04, 0xf3, 0x7b, 0x0d, 0x2e, 0x29, 0x86, 0x9e, 0x43, 0xc1, 0x7d, 0x27, 0x5d, 0x70, 0x27,
-	0xb6, 0x50, 0xd1, 0xf6, 0x88, 0x6a, 0xfb, 0x93, 0x2c, 0x94, 0x15, 0xac, 0xb0, 0xd4, 0xda, 0x00,
-	0x6e, 0x7c, 0xef, 0x67, 0xea, 0x43, 0x85, 0xef, 0x47, 0xe5, 0xf6, 0x90, 0x72, 0xdb, 0x85, 0xab,
-	0x6b, 0x8f, 0x19, 0xf1, 0x1d, 0xb3, 0xbb, 0xe6, 0x30, 0xca, 0xf6, 0x31, 0x69, 0x13, 0x9f, 0x38,
-	0x16, 0x41, 0xd7, 0x21, 0xe7, 0x98, 0x3d, 0x22, 0xdc, 0x51, 0x68, 0x94, 0x24, 0xeb, 0xdc, 0x86,
-	0xd9, 0x23, 0x58, 0x40, 0x50, 0x1d, 0x0a, 0xfc, 0x6f, 0xe0, 0x99, 0x16, 0xa9, 0x64, 0x04, 0xda,
-	0x9c, 0x44, 0x2b, 0x6c, 0x44, 0x00, 0x9c, 0xe0, 0xe8, 0xff, 0xd6, 0xa0, 0x2c, 0xc4, 0x2f, 0x07,
-	0x81, 0x6b, 0x51, 0x93, 0x51, 0xd7, 0x39, 0x9f, 0x3e, 0x5b, 0x36, 0xa5, 0x44, 0x69, 0xff, 0x89,
-	0x47, 0x0a, 0x41, 0x1d, 0x5f, 0x52, 0x52, 0xdc, 0x97, 0x47, 0xf8, 0xe3, 0x31, 0x89, 0xfa, 0xfb,
-	0x39, 0x28, 0x2a, 0x97, 0x8f, 0x1e, 0x42, 0xd6, 0x73, 0x6d, 0x69, 0xf3, 0xc4, 0xbb, 0x42, 0xcb,
-	0xb5, 0x13, 0x35, 0xa6, 0x87, 0x83, 0x5a, 0x96, 0x9f, 0x70, 0x8e, 0xe8, 0xfb, 0x1a, 0x5c, 0x24,
-	0x29, 0xaf, 0x0a, 0xef, 0x14, 0x17, 0xd7, 0x27, 0xce, 0xe7, 0xc3, 0x63, 0xa3, 0x81, 0x86, 0x83,
-	0xda, 0xc5, 0x11, 0xe0, 0x88, 0x48, 0xf4, 0x19, 0xc8, 0x52, 0x2f, 0x0c, 0xeb, 0x52, 0xe3, 0x32,
-	0x57, 0xb0, 0xd9, 0x0a, 0x0e, 0x06, 0xb5, 0x42, 0xb3, 0x25, 0x17, 0x18, 0xcc, 0x11, 0xd0, 0xdb,
-	0x90, 0xf7, 0x5c, 0x9f, 0xf1, 0x66, 0xc3, 0x3d, 0xf2, 0x85, 0x49, 0x75, 0xe4, 0x91, 0x66, 0xb7,
-	0x5c, 0x9f, 0x25, 0x15, 0x87, 0x7f, 0x0b, 0x70, 0xc8, 0x16, 0x7d, 0x0d, 0x72, 0x8e, 0x6b, 0x13,
-	0xd1, 0x93, 0x8a, 0x8b, 0x77, 0x26, 0x66, 0xef, 0xda, 0x24, 0x31, 0x7c, 0x46, 0xa4, 0x00, 0x3f,
-	0x12, 0x4c, 0x51, 0x07, 0xa6, 0x03, 0xe2, 0xef, 0x51, 0x2b, 0x6c, 0x5f, 0xc5, 0xc5, 0x2f, 0x4f,
-	0xca, 0x7f, 0x33, 0x24, 0x4f, 0x44, 0x14, 0x87, 0x83, 0xda, 0x74, 0x74, 0x1a, 0x71, 0xd7, 0x7f,
-	0xad, 0xc1, 0xc5, 0x74, 0xec, 0xa5, 0xd3, 0x4f, 0x3b, 0x3e, 0xfd, 0xe2, 0x8c, 0xce, 0x1c, 0x99,
-	0xd1, 0x0d, 0xc8, 0xf6, 0xa9, 0x5d, 0xc9, 0x0a, 0x84, 0x57, 0x25, 0x42, 0xf6, 0xad, 0xe6, 0xea,
-	0xc1, 0xa0, 0xf6, 0xf2, 0x51, 0xcf, 0x0d, 0x6c, 0xdf, 0x23, 0x81, 0xf1, 0x56, 0x73, 0x15, 0x73,
-	0x62, 0xfd, 0xb7, 0x1a, 0x4c, 0xcb, 0x81, 0x02, 0x3d, 0x84, 0x9c, 0x45, 0x6d, 0x5f, 0xc6, 0xf8,
-	0x09, 0x47, 0x98, 0x58, 0xd1, 0x95, 0xe6, 0x2a, 0xc6, 0x82, 0x21, 0x7a, 0x04, 0x53, 0xe4, 0xb1,
-	0x45, 0x3c, 0x26, 0xf3, 0xf8, 0x84, 0xac, 0x2f, 0x4a, 0xd6, 0x53, 0x6b, 0x82, 0x19, 0x96, 0x4c,
-	0xf5, 0x36, 0xe4, 0x05, 0x02, 0x7a, 0x05, 0x32, 0xd4, 0x13, 0xea, 0x97, 0x1a, 0xf3, 0xc3, 0x41,
-	0x2d, 0xd3, 0x6c, 0xa5, 0x43, 0x38, 0x43, 0x3d, 0x3e, 0x35, 0x79, 0x3e, 0x69, 0xd3, 0xc7, 0xf7,
-	0x88, 0xd3, 0x61, 0x3b, 0xe2, 0x7e, 0xf3, 0x49, 0x87, 0x6f, 0x29, 0x30, 0x9c, 0xc2, 0xd4, 0x7f,
-	0xa1, 0x01, 0xba, 0xdf, 0xef, 0x32, 0x6a, 0x99, 0x01, 0x13, 0xee, 0x6d, 0x3a, 0x6d, 0x17, 0xbd,
-	0x02, 0x79, 0x31, 0x08, 0x48, 0xaf, 0xc6, 0x71, 0x1d, 0x06, 0x40, 0x08, 0x43, 0x6f, 0x43, 0xce,
-	0x73, 0xed, 0x13, 0xbf, 0x35, 0xa4, 0xea, 0x47, 0x7c, 0xc5, 0x2d, 0xd7, 0x0e, 0xb0, 0xe0, 0xab,
-	0xbf, 0xa7, 0x41, 0x21, 0xce, 0x2d, 0x1e, 0x3b, 0x3c, 0x9d, 0x84, 0x46, 0x79, 0x15, 0xdf, 0x67,
-	0x58, 0x40, 0x9e, 0x23, 0xba, 0x96, 0x60, 0x46, 0x3c, 0x42, 0x59, 0x6e, 0x57, 0x86, 0xd8, 0xb5,
-	0x68, 0x16, 0x69, 0xc9, 0xf3, 0x03, 0xe5, 0x33, 0x8e, 0xb1, 0xf5, 0x7f, 0x66, 0x61, 0x76, 0x83,
-	0xb0, 0x6f, 0xbb, 0xfe, 0x6e, 0xcb, 0xed, 0x52, 0x6b, 0xff, 0x1c, 0xba, 0x46, 0x1b, 0xf2, 0x7e,
-	0xbf, 0x4b, 0xa2, 0x0b, 0x5e, 0x9e, 0xb8, 0x70, 0xa8, 0xfa, 0xe2, 0x7e, 0x97, 0x24, 0x7e, 0xe4,
-	0xdf, 0x02, 0x1c, 0xb2, 0x47, 0x77, 0xe0, 0x92, 0x99, 0x5a, 0x3f, 0xc3, 0x9a, 0x59, 0x10, 0xf1,
-	0x76, 0x29, 0xbd, 0x99, 0x06, 0x78, 0x14, 0x17, 0xdd, 0xe0, 0x97, 0x4a, 0x5d, 0x9f, 0x57, 0x79,
-	0x3e, 0xae, 0x6b, 0x8d, 0x52, 0x78, 0xa1, 0xe1, 0x19, 0x8e, 0xa1, 0xe8, 0x26, 0x94, 0x18, 0x25,
-	0x7e, 0x04, 0x11, 0x05, 0x31, 0xdf, 0x28, 0x8b, 0xc1, 0x5e, 0x39, 0xc7, 0x29, 0x2c, 0x14, 0x40,
-	0x21, 0x70, 0xfb, 0xbe, 0xa8, 0x50, 0xb2, 0xc6, 0xdd, 0x3d, 0xdd, 0x55, 0xc4, 0x51, 0x37, 0xcb,
-	0x2b, 0xd5, 0x66, 0xc4, 0x1c, 0x27, 0x72, 0xf4, 0x3f, 0x6a, 0x30, 0x97, 0x22, 0x3a, 0x87, 0xd9,
-	0x77, 0x3b, 0x3d, 0xfb, 0xde, 0x39, 0x95, 0x91, 0x47, 0x4c, 0xbf, 0xdf, 0x81, 0xab, 0x29, 0x34,
-	0xde, 0x49, 0x36, 0x99, 0xc9, 0xfa, 0x01, 0xfa, 0x2c, 0xcc, 0xf0, 0x8e, 0xb2, 0x91, 0x8c, 0x5c,
-	0xb1, 0xb2, 0x1b, 0xf2, 0x1c, 0xc7, 0x18, 0x7c, 0xdd, 0x92, 0x2f, 0xbb, 0xd4, 0x75, 0x44, 0xca,
-	0x29, 0xeb, 0xd6, 0x7a, 0x0c, 0xc1, 0x0a, 0x96, 0xfe, 0xbb, 0xcc, 0xc8, 0xa5, 0xb6, 0x08, 0xf1,
-	0xd1, 0x6d, 0x98, 0x35, 0x95, 0xf7, 0xc4, 0xa0, 0xa2, 0x89, 0xe0, 0x9b, 0x1b, 0x0e, 0x6a, 0xb3,
-	0xea, 0x43, 0x63, 0x80, 0xd3, 0x78, 0x88, 0xc0, 0x0c, 0xf5, 0xe4, 0x8a, 0x1a, 0x5e, 0xd9, 0xed,
-	0xc9, 0x8b, 0xb0, 0xa0, 0x4f, 0x2c, 0x8d, 0x77, 0xd3, 0x98, 0x35, 0xaa, 0x41, 0xbe, 0xfd, 0x2d,
-	0xdb, 0x89, 0x92, 0xa2, 0xc0, 0xef, 0xf4, 0xee, 0x57, 0x56, 0x37, 0x02, 0x1c, 0x9e, 0x23, 0xc6,
-	0x37, 0x4f, 0xd9, 0x2f, 0xa3, 0x21, 0xe2, 0xf4, 0x5d, 0x58, 0xd9, 0x5d, 0x23, 0xde, 0x58, 0x91,
-	0xc3, 0x97, 0xd0, 0x8f, 0x1d, 0x1e, 0xd6, 0xe8, 0x16, 0xe4, 0x78, 0x4b, 0x94, 0x5e, 0x7c, 0x39,
-	0x2a, 0x84, 0x5b, 0xfb, 0x1e, 0x39, 0x18, 0xd4, 0xd2, 0x2e, 0xe0, 0x87, 0x58, 0xa0, 0x4f, 0x3c,
-	0x4d, 0xc7, 0x05, 0x37, 0x7b, 0x5c, 0x3b, 0xcf, 0x9d, 0xa6, 0x9d, 0xff, 0x2a, 0x3f, 0x12, 0x35,
-	0xbc, 0x78, 0xa1, 0x37, 0xa0, 0x60, 0x53, 0x9f, 0x58, 0x22, 0xfc, 0x42, 0x43, 0xab, 0x91, 0xb2,
-	0xab, 0x11, 0xe0, 0x40, 0xfd, 0x82, 0x13, 0x02, 0x64, 0x41, 0xae, 0xed, 0xbb, 0x3d, 0x39, 0x95,
-	0x9e, 0xae, 0xb2, 0xf2, 0x20, 0x4e, 0x8c, 0xbf, 0xeb, 0xbb, 0x3d, 0x2c, 0x98, 0xa3, 0x47, 0x90,
-	0x61, 0xae, 0xb8, 0x9c, 0x33, 0x11, 0x01, 0x52, 0x44, 0x66, 0xcb, 0xc5, 0x19, 0xe6, 0xf2, 0xf0,
-	0x0f, 0xd2, 0x41, 0x77, 0xfb, 0x84, 0x41, 0x97, 0x84, 0x7f, 0x1c, 0x69, 0x31, 0x6b, 0x5e, 0x16,
-	0xbc, 0x91, 0x82, 0x9d, 0xf4, 0xcc, 0xb1, 0x12, 0xff, 0x10, 0xa6, 0xcc, 0xd0, 0x27, 0x53, 0xc2,
-	0x27, 0x5f, 0xe2, 0xb3, 0xcd, 0x72, 0xe4, 0x8c, 0x85, 0x67, 0xfc, 0x50, 0xe7, 0xdb, 0xf1, 0xcf,
-	0x66, 0x06, 0xf7, 0x70, 0x48, 0x84, 0x25, 0x3b, 0xf4, 0x3a, 0xcc, 0x12, 0xc7, 0xdc, 0xee, 0x92,
-	0x7b, 0x6e, 0xa7, 0x43, 0x9d, 0x4e, 0x65, 0xfa, 0xba, 0x76, 0x63, 0xa6, 0x71, 0x45, 0xea, 0x32,
-	0xbb, 0xa6, 0x02, 0x71, 0x1a, 0xf7, 0xb0, 0x0e, 0x37, 0x33, 0x41, 0x87, 0x8b, 0xe2, 0xbc, 0x70,
-	0x54, 0x9c, 0xeb, 0x3f, 0xcd, 0x02, 0x4a, 0x79, 0x8c, 0xd7, 0xd4, 0x80, 0xef, 0x41, 0xb3, 0x8e,
-	0x7a, 0x2c, 0xbb, 0xc6, 0x59, 0xf5, 0xaf, 0xd8, 0xfa, 0x34, 0x3c, 0x2d, 0x13, 0x79, 0x50, 0x62,
-	0xbe, 0xd9, 0x6e, 0x53, 0x4b, 0x68, 0x25, 0x83, 0xfe, 0xb5, 0x67, 0xe8, 0x20, 0x7e, 0xc5, 0x34,
-	0x62, 0x77, 0x6c, 0x29, 0xd4, 0xca, 0x5b, 0x9c, 0x72, 0x8a, 0x53, 0x12, 0xd0, 0xbb, 0x1a, 0x94,
-	0xf9, 0x6c, 0xa1, 0xa2, 0xc8, 0xe7, 0x85, 0x2f, 0x3e, 0xbf, 0x58, 0x3c, 0xc2, 0x21, 0xd9, 0x75,
-	0x47, 0x21, 0x78, 0x4c, 0x9a, 0xfe, 0x57, 0x0d, 0xe6, 0xc7, 0x3c, 0xd2, 0x3f, 0x8f, 0x67, 0xdc,
-	0x2e, 0xe4, 0x79, 0x97, 0x8c, 0x7a, 0xd2, 0xfa, 0xa9, 0x7c, 0x9d, 0xf4, 0xe7, 0xa4, 0xa1, 0xf3,
-	0xb3, 0x00, 0x87, 0x42, 0xf4, 0x05, 0x98, 0x4d, 0x2d, 0x88, 0xc7, 0xbf, 0x9a, 0xe8, 0xef, 0xe7,
-	0xa1, 0x1c, 0xf1, 0x0d, 0x36, 0xfb, 0xbd, 0x9e, 0xe9, 0x9f, 0xc7, 0x38, 0xfb, 0x43, 0x0d, 0x2e,
-	0xa9, 0x81, 0x49, 0xe3, 0x2b, 0x6a, 0x9c, 0xea, 0x8a, 0xc2, 0xd8, 0xb8, 0x2a, 0x65, 0x5f, 0xda,
-	0x48, 0x8b, 0xc0, 0xa3, 0x32, 0xd1, 0x6f, 0x34, 0xb8, 0x16, 0x4a, 0x91, 0xcf, 0xfc, 0x23, 0x14,
-	0x32, 0x50, 0xcf, 0x42, 0xa9, 0x4f, 0x49, 0xa5, 0xae, 0x2d, 0x3f, 0x43, 0x1e, 0x7e, 0xa6, 0x36,
-	0xe8, 0xe7, 0x1a, 0x5c, 0x09, 0x11, 0x46, 0xf5, 0xcc, 0x9d, 0x99, 0x9e, 0x9f, 0x94, 0x7a, 0x5e,
-	0x59, 0x3e, 0x4c, 0x10, 0x3e, 0x5c, 0x3e, 0x1f, 0xcc, 0x7b, 0xd1, 0xea, 0x58, 0xc9, 0x9f, 0x4c,
-	0x99, 0xf1, 0xdd, 0x33, 0x99, 0x39, 0x62, 0x18, 0x4e, 0xe4, 0xe8, 0x8f, 0xe0, 0x72, 0xcb, 0xec,
-	0x50, 0x47, 0x4c, 0x94, 0xeb, 0x84, 0x3d, 0xf0, 0xf8, 0x07, 0x51, 0xa3, 0x3d, 0xb3, 0x13, 0x86,
-	0x7d, 0x56, 0x59, 0x0f, 0xcd, 0x0e, 0xc1, 0x02, 0xc2, 0x77, 0xda, 0x2e, 0xed, 0x51, 0x26, 0x87,
-	0xd5, 0x38, 0x9d, 0xee, 0xf1, 0x43, 0x1c, 0xc2, 0x74, 0x13, 0x4a, 0xea, 0x5e, 0xfa, 0x22, 0xde,
-	0x20, 0xff, 0x95, 0x81, 0xe8, 0x75, 0x05, 0xdd, 0x54, 0x16, 0xd2, 0x50, 0x44, 0xe5, 0xf8, 0x65,
-	0x14, 0x6d, 0xc8, 0x55, 0x38, 0x73, 0x4c, 0x9e, 0xf6, 0x19, 0xed, 0x1a, 0xe1, 0xbf, 0x61, 0x18,
-	0x4d, 0x87, 0x3d, 0xf0, 0x37, 0x99, 0x4f, 0x9d, 0x4e, 0xf8, 0x86, 0xa4, 0x2c, 0xce, 0x9f, 0x86,
-	0x69, 0xe2, 0x88, 0x2d, 0x5b, 0x4c, 0x2b, 0xf9, 0xf0, 0x05, 0x68, 0x2d, 0x3c, 0xc2, 0x11, 0x8c,
-	0x2f, 0x7a, 0xd4, 0xea, 0x79, 0x7c, 0x62, 0x14, 0x13, 0x5d, 0x3e, 0x5c, 0xf4, 0x9a, 0x2b, 0xf7,
-	0x5b, 0x62, 0x8a, 0x8c, 0xa1, 0x11, 0xe6, 0x4a, 0xf4, 0xea, 0xa5, 0x60, 0xf2, 0x33, 0x1c, 0x43,
-	0x05, 0x66, 0x47, 0xf2, 0x9c, 0x52, 0x30, 0xd7, 0x63, 0x9e, 0x12, 0x8a, 0x96, 0xe4, 0x4f, 0x20,
-	0x72, 0x25, 0x10, 0xfd, 0xbf, 0x30, 0xf2, 0x2b, 0x46, 0xf4, 0x26, 0x92, 0xc2, 0xd4, 0x09, 0x94,
-	0x47, 0xa7, 0xeb, 0x17, 0xe0, 0xd7, 0xc6, 0xd6, 0x93, 0xa7, 0xd5, 0x0b, 0x1f, 0x3c, 0xad, 0x5e,
-	0xf8, 0xf0, 0x69, 0xf5, 0xc2, 0xbb, 0xc3, 0xaa, 0xf6, 0x64, 0x58, 0xd5, 0x3e, 0x18, 0x56, 0xb5,
-	0x0f, 0x87, 0x55, 0xed, 0xcf, 0xc3, 0xaa, 0xf6, 0xb3, 0xbf, 0x54, 0x2f, 0x7c, 0xd5, 0x98, 0xec,
-	0x7f, 0x95, 0xfe, 0x13, 0x00, 0x00, 0xff, 0xff, 0xca, 0x4d, 0x24, 0x0a, 0xdc, 0x24, 0x00, 0x00,
+	// 2229 bytes of a gzipped FileDescriptorProto
+	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0xec, 0x1a, 0xcd, 0x6f, 0x1c, 0x57,
+	0x3d, 0xb3, 0x1f, 0xfe, 0xf8, 0xed, 0x3a, 0x59, 0x3f, 0x37, 0x64, 0x29, 0xc1, 0x9b, 0x4e, 0x29,
+	0xca, 0x01, 0x66, 0x6b, 0x93, 0x34, 0x81, 0x36, 0xa5, 0xde, 0xc4, 0xb1, 0x56, 0x72, 0x9c, 0xe5,
+	0xd9, 0x55, 0x24, 0x20, 0xa5, 0xe3, 0x99, 0xb7, 0xe3, 0x21, 0xb3, 0xf3, 0x86, 0x99, 0xb7, 0x26,
+	0x11, 0x12, 0x2a, 0x02, 0x0e, 0x2d, 0x48, 0xe5, 0x86, 0xb8, 0x71, 0xe3, 0xc2, 0x5f, 0xd0, 0x1b,
+	0xb7, 0x88, 0x53, 0x11, 0x42, 0xf4, 0xb4, 0x22, 0x8b, 0x00, 0x71, 0xe1, 0x0f, 0x30, 0x17, 0xf4,
+	0xde, 0xbc, 0x99, 0x79, 0xb3, 0x6b, 0xc7, 0x59, 0xdb, 0x31, 0x12, 0xf4, 0xe4, 0xdd, 0xdf, 0xf7,
+	0x7b, 0xbf, 0xef, 0xb7, 0x86, 0x37, 0x4d, 0x9f, 0x85, 0xc4, 0x34, 0x5c, 0xda, 0x8c, 0x3f, 0x35,
+	0x83, 0x07, 0x4e, 0xd3, 0x0c, 0xdc, 0xa8, 0x69, 0x51, 0x9f, 0x85, 0xd4, 0x0b, 0x3c, 0xd3, 0x27,
+	0xcd, 0xdd, 0xa5, 0x6d, 0xc2, 0xcc, 0xe5, 0xa6, 0x43, 0x7c, 0x12, 0x9a, 0x8c, 0xd8, 0x46, 0x10,
+	0x52, 0x46, 0x91, 0x11, 0x73, 0x7d, 0xc7, 0xa5, 0xf2, 0x93, 0x11, 0x3c, 0x70, 0x0c, 0xce, 0x6f,
+	0xa8, 0xfc, 0x86, 0xe4, 0x7f, 0xf1, 0xfa, 0xc1, 0xfa, 0x22, 0x66, 0xb2, 0xa8, 0xb9, 0xbb, 0x64,
+	0x7a, 0xc1, 0x8e, 0xb9, 0x34, 0xaa, 0xe9, 0xc5, 0x2f, 0x3b, 0x2e, 0xdb, 0xe9, 0x6f, 0x1b, 0x16,
+	0xed, 0x35, 0x1d, 0xea, 0xd0, 0xa6, 0x00, 0x6f, 0xf7, 0xbb, 0xe2, 0x9b, 0xf8, 0x22, 0x3e, 0x49,
+	0xf2, 0x2b, 0x0f, 0xae, 0x47, 0x42, 0x4b, 0xe0, 0xf6, 0x4c, 0x6b, 0xc7, 0xf5, 0x49, 0xf8, 0x28,
+	0xd3, 0xd5, 0x23, 0xcc, 0x6c, 0xee, 0x8e, 0x2b, 0x69, 0x1e, 0xc4, 0x15, 0xf6, 0x7d, 0xe6, 0xf6,
+	0xc8, 0x18, 0xc3, 0x6b, 0x87, 0x31, 0x44, 0xd6, 0x0e, 0xe9, 0x99, 0x63, 0x7c, 0x5f, 0x39, 0x88,
+	0xaf, 0xcf, 0x5c, 0xaf, 0xe9, 0xfa, 0x2c, 0x62, 0xe1, 0x28, 0x93, 0xfe, 0x0f, 0x0d, 0xaa, 0x2b,
+	0xb6, 0x1d, 0x92, 0x28, 0x5a, 0x0b, 0x69, 0x3f, 0x40, 0xef, 0xc2, 0x0c, 0x3f, 0x89, 0x6d, 0x32,
+	0xb3, 0xae, 0x5d, 0xd2, 0x2e, 0x57, 0x96, 0x5f, 0x35, 0x62, 0xc1, 0x86, 0x2a, 0x38, 0xf3, 0x09,
+	0xa7, 0x36, 0x76, 0x97, 0x8c, 0xbb, 0xdb, 0xdf, 0x25, 0x16, 0xbb, 0x43, 0x98, 0xd9, 0x42, 0x8f,
+	0x07, 0x8d, 0x33, 0xc3, 0x41, 0x03, 0x32, 0x18, 0x4e, 0xa5, 0xa2, 0x3e, 0x54, 0x1d, 0xae, 0xea,
+	0x0e, 0xe9, 0x6d, 0x93, 0x30, 0xaa, 0x17, 0x2e, 0x15, 0x2f, 0x57, 0x96, 0x5f, 0x9f, 0xd0, 0xed,
+	0xc6, 0x5a, 0x26, 0xa3, 0xf5, 0x82, 0x54, 0x58, 0x55, 0x80, 0x11, 0xce, 0xa9, 0xd1, 0xff, 0xa8,
+	0x41, 0x4d, 0x3d, 0xe9, 0xba, 0x1b, 0x31, 0xf4, 0xed, 0xb1, 0xd3, 0x1a, 0xcf, 0x76, 0x5a, 0xce,
+	0x2d, 0xce, 0x5a, 0x93, 0xaa, 0x67, 0x12, 0x88, 0x72, 0x52, 0x13, 0xca, 0x2e, 0x23, 0xbd, 0xe4,
+	0x88, 0x6f, 0x4c, 0x7a, 0x44, 0xd5, 0xdc, 0xd6, 0x9c, 0x54, 0x54, 0x6e, 0x73, 0x91, 0x38, 0x96,
+	0xac, 0xbf, 0x5f, 0x84, 0x79, 0x95, 0xac, 0x63, 0x32, 0x6b, 0xe7, 0x14, 0x9c, 0xf8, 0x13, 0x0d,
+	0xe6, 0x4d, 0xdb, 0x26, 0xf6, 0xda, 0x09, 0xbb, 0xf2, 0xb3, 0x52, 0x2d, 0x3f, 0x55, 0x5e, 0x3a,
+	0x1e, 0x57, 0x88, 0x3e, 0xd0, 0x60, 0x21, 0x24, 0x3d, 0xba, 0x3b, 0x62, 0x48, 0xf1, 0xf8, 0x86,
+	0x7c, 0x4e, 0x1a, 0xb2, 0x80, 0xc7, 0xe5, 0xe3, 0xfd, 0x94, 0xea, 0xff, 0xd4, 0xe0, 0xec, 0x4a,
+	0x10, 0x78, 0x2e, 0xb1, 0xb7, 0xe8, 0xff, 0x78, 0x36, 0xfd, 0x59, 0x03, 0x94, 0x3f, 0xeb, 0x29,
+	0xe4, 0x93, 0x95, 0xcf, 0xa7, 0x37, 0x27, 0xce, 0xa7, 0x9c, 0xc1, 0x07, 0x64, 0xd4, 0xcf, 0x8a,
+	0xb0, 0x90, 0x27, 0xfc, 0x34, 0xa7, 0xfe, 0x7b, 0x39, 0xf5, 0xeb, 0x12, 0x2c, 0xdc, 0xf4, 0xfa,
+	0x11, 0x23, 0x61, 0xce, 0xc8, 0xe7, 0xef, 0x8d, 0x1f, 0x69, 0x50, 0x23, 0xdd, 0x2e, 0xb1, 0x98,
+	0xbb, 0x4b, 0x4e, 0xd0, 0x19, 0x75, 0xa9, 0xb5, 0xb6, 0x3a, 0x22, 0x1c, 0x8f, 0xa9, 0x43, 0x3f,
+	0x84, 0xf9, 0x14, 0xd6, 0xee, 0xb4, 0x3c, 0x6a, 0x3d, 0x48, 0xfc, 0x70, 0x75, 0x52, 0x1b, 0xda,
+	0x9d, 0x0d, 0xc2, 0xb2, 0x50, 0x58, 0x1d, 0x95, 0x8b, 0xc7, 0x55, 0xa1, 0xeb, 0x50, 0x65, 0x94,
+	0x99, 0x5e, 0x72, 0xfc, 0xd2, 0x25, 0xed, 0x72, 0x31, 0xab, 0x0f, 0x5b, 0x0a, 0x0e, 0xe7, 0x28,
+	0xd1, 0x32, 0x80, 0xf8, 0xde, 0x31, 0x1d, 0x12, 0xd5, 0xcb, 0x82, 0x2f, 0xbd, 0xef, 0xad, 0x14,
+	0x83, 0x15, 0x2a, 0x74, 0x15, 0x2a, 0x56, 0x3f, 0x0c, 0x89, 0xcf, 0xf8, 0xf7, 0xfa, 0x94, 0x60,
+	0x5a, 0x90, 0x4c, 0x95, 0x9b, 0x19, 0x0a, 0xab, 0x74, 0xfa, 0xdf, 0x35, 0xa8, 0xac, 0x3a, 0xff,
+	0x07, 0x13, 0xcc, 0x1f, 0x34, 0x38, 0xa7, 0x1c, 0xf4, 0x14, 0x0a, 0xee, 0xbb, 0xf9, 0x82, 0x3b,
+	0xf1, 0x09, 0x15, 0x6b, 0x0f, 0xa8, 0xb6, 0x3f, 0x2f, 0x42, 0x4d, 0xa1, 0x8a, 0x4b, 0xad, 0x0d,
+	0x40, 0xd3, 0x7b, 0x3f, 0x51, 0x1f, 0x2a, 0x72, 0x3f, 0x2d, 0xb7, 0xfb, 0x94, 0x5b, 0x0f, 0x2e,
+	0xac, 0x3e, 0x64, 0x24, 0xf4, 0x4d, 0x6f, 0xd5, 0x67, 0x2e, 0x7b, 0x84, 0x49, 0x97, 0x84, 0xc4,
+	0xb7, 0x08, 0xba, 0x04, 0x25, 0xdf, 0xec, 0x11, 0xe1, 0x8e, 0xd9, 0x56, 0x55, 0x8a, 0x2e, 0x6d,
+	0x98, 0x3d, 0x82, 0x05, 0x06, 0x35, 0x61, 0x96, 0xff, 0x8d, 0x02, 0xd3, 0x22, 0xf5, 0x82, 0x20,
+	0x9b, 0x97, 0x64, 0xb3, 0x1b, 0x09, 0x02, 0x67, 0x34, 0xfa, 0xbf, 0x35, 0xa8, 0x09, 0xf5, 0x2b,
+	0x51, 0x44, 0x2d, 0xd7, 0x64, 0x2e, 0xf5, 0x4f, 0xa7, 0xcf, 0xd6, 0x4c, 0xa9, 0x51, 0x9e, 0xff,
+	0xc8, 0x23, 0x85, 0xe0, 0x4e, 0x2f, 0x29, 0x2b, 0xee, 0x2b, 0x23, 0xf2, 0xf1, 0x98, 0x46, 0xfd,
+	0xa3, 0x12, 0x54, 0x94, 0xcb, 0x47, 0xf7, 0xa0, 0x18, 0x50, 0x5b, 0x9e, 0x79, 0xe2, 0x5d, 0xa1,
+	0x43, 0xed, 0xcc, 0x8c, 0xe9, 0xe1, 0xa0, 0x51, 0xe4, 0x10, 0x2e, 0x11, 0xfd, 0x58, 0x83, 0xb3,
+	0x24, 0xe7, 0x55, 0xe1, 0x9d, 0xca, 0xf2, 0xda, 0xc4, 0xf9, 0xbc, 0x7f, 0x6c, 0xb4, 0xd0, 0x70,
+	0xd0, 0x38, 0x3b, 0x82, 0x1c, 0x51, 0x89, 0xbe, 0x08, 0x45, 0x37, 0x88, 0xc3, 0xba, 0xda, 0x7a,
+	0x81, 0x1b, 0xd8, 0xee, 0x44, 0x7b, 0x83, 0xc6, 0x6c, 0xbb, 0x23, 0x17, 0x18, 0xcc, 0x09, 0xd0,
+	0x3b, 0x50, 0x0e, 0x68, 0xc8, 0x78, 0xb3, 0xe1, 0x1e, 0xf9, 0xea, 0xa4, 0x36, 0xf2, 0x48, 0xb3,
+	0x3b, 0x34, 0x64, 0x59, 0xc5, 0xe1, 0xdf, 0x22, 0x1c, 0x8b, 0x45, 0xdf, 0x82, 0x92, 0x4f, 0x6d,
+	0x22, 0x7a, 0x52, 0x65, 0xf9, 0xc6, 0xc4, 0xe2, 0xa9, 0x4d, 0xb2, 0x83, 0xcf, 0x88, 0x14, 0xe0,
+	0x20, 0x21, 0x14, 0x39, 0x30, 0x1d, 0x91, 0x70, 0xd7, 0xb5, 0xe2, 0xf6, 0x55, 0x59, 0x7e, 0x6b,
+	0x52, 0xf9, 0x9b, 0x31, 0x7b, 0xa6, 0xa2, 0x32, 0x1c, 0x34, 0xa6, 0x13, 0x68, 0x22, 0x5d, 0xff,
+	0x8d, 0x06, 0x67, 0xf3, 0xb1, 0x97, 0x4f, 0x3f, 0xed, 0xf0, 0xf4, 0x4b, 0x33, 0xba, 0x70, 0x60,
+	0x46, 0xb7, 0xa0, 0xd8, 0x77, 0xed, 0x7a, 0x51, 0x10, 0xbc, 0x2a, 0x09, 0x8a, 0x6f, 0xb7, 0x6f,
+	0xed, 0x0d, 0x1a, 0x2f, 0x1d, 0xf4, 0xdc, 0xc0, 0x1e, 0x05, 0x24, 0x32, 0xde, 0x6e, 0xdf, 0xc2,
+	0x9c, 0x59, 0xff, 0x9d, 0x06, 0xd3, 0x72, 0xa0, 0x40, 0xf7, 0xa0, 0x64, 0xb9, 0x76, 0x28, 0x63,
+	0xfc, 0x88, 0x23, 0x4c, 0x6a, 0xe8, 0xcd, 0xf6, 0x2d, 0x8c, 0x85, 0x40, 0x74, 0x1f, 0xa6, 0xc8,
+	0x43, 0x8b, 0x04, 0x4c, 0xe6, 0xf1, 0x11, 0x45, 0x9f, 0x95, 0xa2, 0xa7, 0x56, 0x85, 0x30, 0x2c,
+	0x85, 0xea, 0x5d, 0x28, 0x0b, 0x02, 0xf4, 0x32, 0x14, 0xdc, 0x40, 0x98, 0x5f, 0x6d, 0x2d, 0x0c,
+	0x07, 0x8d, 0x42, 0xbb, 0x93, 0x0f, 0xe1, 0x82, 0x1b, 0xf0, 0xa9, 0x29, 0x08, 0x49, 0xd7, 0x7d,
+	0xb8, 0x4e, 0x7c, 0x87, 0xed, 0x88, 0xfb, 0x2d, 0x67, 0x1d, 0xbe, 0xa3, 0xe0, 0x70, 0x8e, 0x52,
+	0xff, 0x95, 0x06, 0xe8, 0x4e, 0xdf, 0x63, 0xae, 0x65, 0x46, 0x4c, 0xb8, 0xb7, 0xed, 0x77, 0x29,
+	0x7a, 0x19, 0xca, 0x62, 0x10, 0x90, 0x5e, 0x4d, 0xe3, 0x3a, 0x0e, 0x80, 0x18, 0x87, 0xde, 0x81,
+	0x52, 0x40, 0xed, 0x23, 0xbf, 0x35, 0xe4, 0xea, 0x47, 0x7a, 0xc5, 0x1d, 0x6a, 0x47, 0x58, 0xc8,
+	0xd5, 0xdf, 0xd7, 0x60, 0x36, 0xcd, 0x2d, 0x1e, 0x3b, 0x3c, 0x9d, 0x84, 0x45, 0x65, 0x95, 0x3e,
+	0x64, 0x58, 0x60, 0x9e, 0x21, 0xba, 0xae, 0xc3, 0x8c, 0x78, 0x84, 0xb2, 0xa8, 0x27, 0x43, 0xec,
+	0x62, 0x32, 0x8b, 0x74, 0x24, 0x7c, 0x4f, 0xf9, 0x8c, 0x53, 0x6a, 0xfd, 0xc3, 0x12, 0xcc, 0x6d,
+	0x10, 0xf6, 0x7d, 0x1a, 0x3e, 0xe8, 0x50, 0xcf, 0xb5, 0x1e, 0x9d, 0x42, 0xd7, 0xe8, 0x42, 0x39,
+	0xec, 0x7b, 0x24, 0xb9, 0xe0, 0x95, 0x89, 0x0b, 0x87, 0x6a, 0x2f, 0xee, 0x7b, 0x24, 0xf3, 0x23,
+	0xff, 0x16, 0xe1, 0x58, 0x3c, 0xba, 0x01, 0xe7, 0xcc, 0xdc, 0xfa, 0x19, 0xd7, 0xcc, 0x59, 0x11,
+	0x6f, 0xe7, 0xf2, 0x9b, 0x69, 0x84, 0x47, 0x69, 0xd1, 0x65, 0x7e, 0xa9, 0x2e, 0x0d, 0x79, 0x95,
+	0xe7, 0xe3, 0xba, 0xd6, 0xaa, 0xc6, 0x17, 0x1a, 0xc3, 0x70, 0x8a, 0x45, 0x57, 0xa0, 0xca, 0x5c,
+	0x12, 0x26, 0x18, 0x51, 0x10, 0xcb, 0xad, 0x9a, 0x18, 0xec, 0x15, 0x38, 0xce, 0x51, 0xa1, 0x08,
+	0x66, 0x23, 0xda, 0x0f, 0x45, 0x85, 0x92, 0x35, 0xee, 0xf6, 0xf1, 0xae, 0x22, 0x8d, 0xba, 0x39,
+	0x5e, 0xa9, 0x36, 0x13, 0xe1, 0x38, 0xd3, 0xc3, 0x63, 0x89, 0x1b, 0x51, 0x9f, 0xce, 0xc7, 0x12,
+	0x37, 0x13, 0x0b, 0x8c, 0xfe, 0x27, 0x0d, 0xe6, 0x73, 0x62, 0x4f, 0x61, 0x3a, 0xde, 0xce, 0x4f,
+	0xc7, 0x37, 0x8e, 0x75, 0x0d, 0x07, 0xcc, 0xc7, 0x3f, 0x80, 0x0b, 0x39, 0x32, 0xde, 0x6b, 0x36,
+	0x99, 0xc9, 0xfa, 0x11, 0xfa, 0x12, 0xcc, 0xf0, 0x9e, 0xb3, 0x91, 0x0d, 0x65, 0xa9, 0xb1, 0x1b,
+	0x12, 0x8e, 0x53, 0x0a, 0xbe, 0x90, 0xc9, 0xb7, 0x5f, 0x97, 0xfa, 0x22, 0x29, 0x95, 0x85, 0x6c,
+	0x2d, 0xc5, 0x60, 0x85, 0x4a, 0xff, 0x7d, 0x61, 0xe4, 0x52, 0x3b, 0x84, 0x84, 0xe8, 0x1a, 0xcc,
+	0x99, 0xca, 0x8b, 0x63, 0x54, 0xd7, 0x44, 0x78, 0xce, 0x0f, 0x07, 0x8d, 0x39, 0xf5, 0x29, 0x32,
+	0xc2, 0x79, 0x3a, 0x44, 0x60, 0xc6, 0x0d, 0xe4, 0x12, 0x1b, 0x5f, 0xd9, 0xb5, 0xc9, 0xcb, 0xb4,
+	0xe0, 0xcf, 0x4e, 0x9a, 0x6e, 0xaf, 0xa9, 0x68, 0xd4, 0x80, 0x72, 0xf7, 0x7b, 0xb6, 0x9f, 0xa4,
+	0xcd, 0x2c, 0xbf, 0xd3, 0xdb, 0xdf, 0xb8, 0xb5, 0x11, 0xe1, 0x18, 0x8e, 0x18, 0xdf, 0x4d, 0x65,
+	0x47, 0x4d, 0xc6, 0x8c, 0xe3, 0xf7, 0x69, 0x65, 0xbb, 0x4d, 0x64, 0x63, 0x45, 0x0f, 0x5f, 0x53,
+	0x3f, 0xb3, 0x7f, 0xe0, 0xa3, 0xab, 0x50, 0xe2, 0x4d, 0x53, 0x7a, 0xf1, 0xa5, 0x34, 0xbc, 0x1f,
+	0x05, 0x64, 0x6f, 0xd0, 0xc8, 0xbb, 0x80, 0x03, 0xb1, 0x20, 0x9f, 0x78, 0xde, 0x4e, 0x4b, 0x72,
+	0xf1, 0xb0, 0x86, 0x5f, 0x3a, 0x4e, 0xc3, 0xff, 0x60, 0x6a, 0x24, 0x6a, 0x78, 0x79, 0x43, 0x6f,
+	0xc0, 0xac, 0xed, 0x86, 0xc4, 0x12, 0xe1, 0x17, 0x1f, 0x74, 0x31, 0x31, 0xf6, 0x56, 0x82, 0xd8,
+	0x53, 0xbf, 0xe0, 0x8c, 0x01, 0x59, 0x50, 0xea, 0x86, 0xb4, 0x27, 0xe7, 0xd6, 0xe3, 0xd5, 0x5e,
+	0x1e, 0xc4, 0xd9, 0xe1, 0x6f, 0x87, 0xb4, 0x87, 0x85, 0x70, 0x74, 0x1f, 0x0a, 0x8c, 0x8a, 0xcb,
+	0x39, 0x11, 0x15, 0x20, 0x55, 0x14, 0xb6, 0x28, 0x2e, 0x30, 0xca, 0xc3, 0x3f, 0xca, 0x07, 0xdd,
+	0xb5, 0x23, 0x06, 0x5d, 0x16, 0xfe, 0x69, 0xa4, 0xa5, 0xa2, 0x79, 0x59, 0x08, 0x46, 0x4a, 0x7a,
+	0xd6, 0x55, 0xc7, 0x9a, 0xc0, 0x3d, 0x98, 0x32, 0x63, 0x9f, 0x4c, 0x09, 0x9f, 0x7c, 0x9d, 0x4f,
+	0x3f, 0x2b, 0x89, 0x33, 0x96, 0x9e, 0xf2, 0x53, 0x5e, 0x68, 0xa7, 0x3f, 0xac, 0x19, 0xdc, 0xc3,
+	0x31, 0x13, 0x96, 0xe2, 0xd0, 0xeb, 0x30, 0x47, 0x7c, 0x73, 0xdb, 0x23, 0xeb, 0xd4, 0x71, 0x5c,
+	0xdf, 0x11, 0xb5, 0x7b, 0xa6, 0x75, 0x5e, 0xda, 0x32, 0xb7, 0xaa, 0x22, 0x71, 0x9e, 0x76, 0xbf,
+	0x1e, 0x38, 0x33, 0x41, 0x0f, 0x4c, 0xe2, 0x7c, 0xf6, 0xc0, 0x38, 0x7f, 0x05, 0xa6, 0x3d, 0xea,
+	0x60, 0x93, 0x91, 0x3a, 0x88, 0x3b, 0x12, 0x53, 0xf6, 0x7a, 0x0c, 0xc2, 0x09, 0x0e, 0xbd, 0x05,
+	0x35, 0x8f, 0x3a, 0x9b, 0x66, 0x2f, 0xf0, 0xb8, 0x95, 0xbc, 0x2a, 0xd6, 0x2b, 0xf1, 0x34, 0xc7,
+	0x97, 0xbc, 0xf5, 0x11, 0x1c, 0x1e, 0xa3, 0xd6, 0x3f, 0x2c, 0x02, 0xca, 0x85, 0x06, 0x2f, 0xde,
+	0x11, 0x5f, 0xc9, 0xe6, 0x7c, 0x15, 0x2c, 0xdb, 0xd3, 0x49, 0xb5, 0xd2, 0xf4, 0x9a, 0xf3, 0xf8,
+	0xbc, 0x4e, 0x14, 0x40, 0x95, 0x85, 0x66, 0xb7, 0xeb, 0x5a, 0xc2, 0x2a, 0x99, 0x5d, 0xaf, 0x3d,
+	0xc5, 0x06, 0xf1, 0x83, 0xaa, 0x91, 0xfa, 0x7d, 0x4b, 0xe1, 0x56, 0x9e, 0x05, 0x15, 0x28, 0xce,
+	0x69, 0x40, 0xef, 0x69, 0x50, 0xe3, 0x63, 0x8e, 0x4a, 0x22, 0x5f, 0x3a, 0xbe, 0xf6, 0xec, 0x6a,
+	0xf1, 0x88, 0x84, 0x6c, 0xed, 0x1e, 0xc5, 0xe0, 0x31, 0x6d, 0xfa, 0xdf, 0x34, 0x58, 0x18, 0xf3,
+	0x48, 0xff, 0x34, 0x5e, 0x94, 0x3d, 0x28, 0xf3, 0x76, 0x9c, 0x34, 0xbf, 0xb5, 0x63, 0xf9, 0x3a,
+	0x1b, 0x04, 0xb2, 0xc9, 0x81, 0xc3, 0x22, 0x1c, 0x2b, 0xd1, 0x97, 0x60, 0x2e, 0xb7, 0xab, 0x1e,
+	0xfe, 0x80, 0xa3, 0x7f, 0x54, 0x86, 0x5a, 0x22, 0x37, 0xda, 0xec, 0xf7, 0x7a, 0x66, 0x78, 0x1a,
+	0x93, 0xf5, 0x4f, 0x35, 0x38, 0xa7, 0x06, 0xa6, 0x9b, 0x5e, 0x51, 0xeb, 0x58, 0x57, 0x14, 0xc7,
+	0xc6, 0x05, 0xa9, 0xfb, 0xdc, 0x46, 0x5e, 0x05, 0x1e, 0xd5, 0x89, 0x7e, 0xab, 0xc1, 0xc5, 0x58,
+	0x8b, 0xfc, 0xc5, 0x61, 0x84, 0x43, 0x06, 0xea, 0x49, 0x18, 0xf5, 0x05, 0x69, 0xd4, 0xc5, 0x95,
+	0xa7, 0xe8, 0xc3, 0x4f, 0xb5, 0x06, 0xfd, 0x52, 0x83, 0xf3, 0x31, 0xc1, 0xa8, 0x9d, 0xa5, 0x13,
+	0xb3, 0xf3, 0xf3, 0xd2, 0xce, 0xf3, 0x2b, 0xfb, 0x29, 0xc2, 0xfb, 0xeb, 0xe7, 0x3b, 0x42, 0x2f,
+	0xd9, 0x62, 0xeb, 0xe5, 0xa3, 0x19, 0x33, 0xbe, 0x06, 0x67, 0xc3, 0x4d, 0x8a, 0xc3, 0x99, 0x1e,
+	0xfd, 0x3e, 0xbc, 0xd0, 0x31, 0x1d, 0xd7, 0x17, 0xa3, 0xeb, 0x1a, 0x61, 0x77, 0x03, 0xfe, 0x41,
+	0x34, 0x83, 0xc0, 0x74, 0xe2, 0xb0, 0x2f, 0x2a, 0x9b, 0xaa, 0xe9, 0x10, 0x2c, 0x30, 0x7c, 0xbd,
+	0xf6, 0xdc, 0x9e, 0xcb, 0xe4, 0x54, 0x9c, 0xa6, 0xd3, 0x3a, 0x07, 0xe2, 0x18, 0xa7, 0x9b, 0x50,
+	0x55, 0x57, 0xe4, 0xe7, 0xf1, 0x1c, 0xfa, 0xaf, 0x02, 0x24, 0x0f, 0x3d, 0xe8, 0x8a, 0xb2, 0x1b,
+	0xc7, 0x2a, 0xea, 0x87, 0xef, 0xc5, 0x68, 0x43, 0x6e, 0xe5, 0x85, 0x43, 0xf2, 0xb4, 0xcf, 0x5c,
+	0xcf, 0x88, 0xff, 0x23, 0xc4, 0x68, 0xfb, 0xec, 0x6e, 0xb8, 0xc9, 0x42, 0xd7, 0x77, 0xe2, 0xe7,
+	0x2c, 0x65, 0x87, 0x7f, 0x05, 0xa6, 0x89, 0x2f, 0x16, 0x7e, 0x31, 0x16, 0xc9, 0x36, 0xb9, 0x1a,
+	0x83, 0x70, 0x82, 0xe3, 0x3b, 0xa7, 0x6b, 0xf5, 0x02, 0x3e, 0x9a, 0x8a, 0xd1, 0xb1, 0x1c, 0xef,
+	0x9c, 0xed, 0x9b, 0x77, 0x3a, 0x62, 0x5c, 0x4d, 0xb1, 0x09, 0xe5, 0xcd, 0xe4, 0x01, 0x4e, 0xa1,
+	0xe4, 0x30, 0x9c, 0x62, 0x05, 0xa5, 0x23, 0x65, 0x4e, 0x29, 0x94, 0x6b, 0xa9, 0x4c, 0x89, 0x45,
+	0xd7, 0xe5, 0xaf, 0x31, 0x72, 0xf7, 0x90, 0x4b, 0x62, 0xfe, 0x07, 0x95, 0xe4, 0x79, 0x26, 0x47,
+	0xa9, 0x13, 0xa8, 0x8d, 0x8e, 0xf1, 0xcf, 0xc1, 0xaf, 0xad, 0xad, 0xc7, 0x4f, 0x16, 0xcf, 0x7c,
+	0xfc, 0x64, 0xf1, 0xcc, 0x27, 0x4f, 0x16, 0xcf, 0xbc, 0x37, 0x5c, 0xd4, 0x1e, 0x0f, 0x17, 0xb5,
+	0x8f, 0x87, 0x8b, 0xda, 0x27, 0xc3, 0x45, 0xed, 0x2f, 0xc3, 0x45, 0xed, 0x17, 0x7f, 0x5d, 0x3c,
+	0xf3, 0x4d, 0x63, 0xb2, 0x7f, 0x9b, 0xfa, 0x4f, 0x00, 0x00, 0x00, 0xff, 0xff, 0x58, 0x94, 0xf9,
+	0x87, 0x67, 0x25, 0x00, 0x00,
 }
 
 func (m *AddressGroup) Marshal() (dAtA []byte, err error) {
@@ -2047,6 +2051,11 @@ func (m *NetworkPolicy) MarshalToSizedBuffer(dAtA []byte) (int, error) {
 	_ = i
 	var l int
 	_ = l
+	i -= len(m.Tier)
+	copy(dAtA[i:], m.Tier)
+	i = encodeVarintGenerated(dAtA, i, uint64(len(m.Tier)))
+	i--
+	dAtA[i] = 0x3a
 	if m.SourceRef != nil {
 		{
 			size, err := m.SourceRef.MarshalToSizedBuffer(dAtA[:i])
@@ -2316,6 +2325,16 @@ func (m *NetworkPolicyRule) MarshalToSizedBuffer(dAtA []byte) (int, error) {
 	_ = i
 	var l int
 	_ = l
+	if m.LogSamplingRatio != nil {
+		i = encodeVarintGenerated(dAtA, i, uint64(*m.LogSamplingRatio))
+		i--
+		dAtA[i] = 0x58
+	}
+	if m.LogRate != nil {
+		i = encodeVarintGenerated(dAtA, i, uint64(*m.LogRate))
+		i--
+		dAtA[i] = 0x50
+	}
 	i -= len(m.Name)
 	copy(dAtA[i:], m.Name)
 	i = encodeVarintGenerated(dAtA, i, uint64(len(m.Name)))
@@ -3155,6 +3174,8 @@ func (m *NetworkPolicy) Size() (n int) {
 		l = m.SourceRef.Size()
 		n += 1 + l + sovGenerated(uint64(l))
 	}
+	l = len(m.Tier)
+	n += 1 + l + sovGenerated(uint64(l))
 	return n
 }
 
@@ -3269,6 +3290,12 @@ func (m *NetworkPolicyRule) Size() (n int) {
 	}
 	l = len(m.Name)
 	n += 1 + l + sovGenerated(uint64(l))
+	if m.LogRate != nil {
+		n += 1 + sovGenerated(uint64(*m.LogRate))
+	}
+	if m.LogSamplingRatio != nil {
+		n += 1 + sovGenerated(uint64(*m.LogSamplingRatio))
+	}
 	return n
 }
 
@@ -3745,6 +3772,7 @@ func (this *NetworkPolicy) String() string {
 		`Priority:` + valueToStringGenerated(this.Priority) + `,`,
 		`TierPriority:` + valueToStringGenerated(this.TierPriority) + `,`,
 		`SourceRef:` + strings.Replace(this.SourceRef.String(), "NetworkPolicyReference", "NetworkPolicyReference", 1) + `,`,
+		`Tier:` + fmt.Sprintf("%v", this.Tier) + `,`,
 		`}`,
 	}, "")
 	return s
@@ -3831,6 +3859,8 @@ func (this *NetworkPolicyRule) String() string {
 		`EnableLogging:` + fmt.Sprintf("%v", this.EnableLogging) + `,`,
 		`AppliedToGroups:` + fmt.Sprintf("%v", this.AppliedToGroups) + `,`,
 		`Name:` + fmt.Sprintf("%v", this.Name) + `,`,
+		`LogRate:` + valueToStringGenerated(this.LogRate) + `,`,
+		`LogSamplingRatio:` + valueToStringGenerated(this.LogSamplingRatio) + `,`,
 		`}`,
 	}, "")
 	return s
@@ -6634,6 +6664,38 @@ func (m *NetworkPolicy) Unmarshal(dAtA []byte) error {
 				return err
 			}
 			iNdEx = postIndex
+		case 7:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Tier", wireType)
+			}
+			var stringLen uint64
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowGenerated
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				stringLen |= uint64(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			intStringLen := int(stringLen)
+			if intStringLen < 0 {
+				return ErrInvalidLengthGenerated
+			}
+			postIndex := iNdEx + intStringLen
+			if postIndex < 0 {
+				return ErrInvalidLengthGenerated
+			}
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.Tier = string(dAtA[iNdEx:postIndex])
+			iNdEx = postIndex
 		default:
 			iNdEx = preIndex
 			skippy, err := skipGenerated(dAtA[iNdEx:])
@@ -7530,6 +7592,46 @@ func (m *NetworkPolicyRule) Unmarshal(dAtA []byte) error {
 			}
 			m.Name = string(dAtA[iNdEx:postIndex])
 			iNdEx = postIndex
+		case 10:
+			if wireType != 0 {
+				return fmt.Errorf("proto: wrong wireType = %d for field LogRate", wireType)
+			}
+			var v int32
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowGenerated
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				v |= int32(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			m.LogRate = &v
+		case 11:
+			if wireType != 0 {
+				return fmt.Errorf("proto: wrong wireType = %d for field LogSamplingRatio", wireType)
+			}
+			var v int32
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowGenerated
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				v |= int32(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			m.LogSamplingRatio = &v
 		default:
 			iNdEx = preIndex
 			skippy, err := skipGenerated(dAtA[iNdEx:])
@@ -218,6 +218,9 @@ type NetworkPolicy struct {
 	TierPriority *int32 `json:"tierPriority,omitempty" protobuf:"varint,5,opt,name=tierPriority"`
 	// Reference to the original NetworkPolicy that the internal NetworkPolicy is created for.
 	SourceRef *NetworkPolicyReference `json:"sourceRef,omitempty" protobuf:"bytes,6,opt,name=sourceRef"`
+	// Tier is the name of the Tier associated with this Network Policy.
+	// The Tier will remain empty for K8s NetworkPolicy.
+	Tier string `json:"tier,omitempty" protobuf:"bytes,7,opt,name=tier"`
 }
 
 // Direction defines traffic direction of NetworkPolicyRule.
@@ -256,6 +259,12 @@ type NetworkPolicyRule struct {
 	// Name describes the intention of this rule.
 	// Name should be unique within the policy.
 	Name string `json:"name,omitempty" protobuf:"bytes,9,opt,name=name"`
+	// LogRate is the maximum number of packets matching this rule that are logged
+	// per second on each Node. nil means the rate is not limited per rule.
+	LogRate *int32 `json:"logRate,omitempty" protobuf:"varint,10,opt,name=logRate"`
+	// LogSamplingRatio indicates that only one out of every LogSamplingRatio connections
+	// matching this rule is logged. nil means all connections are logged.
+	LogSamplingRatio *int32 `json:"logSamplingRatio,omitempty" protobuf:"varint,11,opt,name=logSamplingRatio"`
 }
 
 // Protocol defines network protocols supported for things like container ports.
@@ -1070,6 +1070,7 @@ func autoConvert_v1beta2_NetworkPolicy_To_controlplane_NetworkPolicy(in *Network
 	out.Priority = (*float64)(unsafe.Pointer(in.Priority))
 	out.TierPriority = (*int32)(unsafe.Pointer(in.TierPriority))
 	out.SourceRef = (*controlplane.NetworkPolicyReference)(unsafe.Pointer(in.SourceRef))
+	out.Tier = in.Tier
 	return nil
 }
 
@@ -1094,6 +1095,7 @@ func autoConvert_controlplane_NetworkPolicy_To_v1beta2_NetworkPolicy(in *control
 	out.AppliedToGroups = *(*[]string)(unsafe.Pointer(&in.AppliedToGroups))
 	out.Priority = (*float64)(unsafe.Pointer(in.Priority))
 	out.TierPriority = (*int32)(unsafe.Pointer(in.TierPriority))
+	out.Tier = in.Tier
 	out.SourceRef = (*NetworkPolicyReference)(unsafe.Pointer(in.SourceRef))
 	return nil
 }
@@ -1233,6 +1235,8 @@ func autoConvert_v1beta2_NetworkPolicyRule_To_controlplane_NetworkPolicyRule(in
 	out.EnableLogging = in.EnableLogging
 	out.AppliedToGroups = *(*[]string)(unsafe.Pointer(&in.AppliedToGroups))
 	out.Name = in.Name
+	out.LogRate = (*int32)(unsafe.Pointer(in.LogRate))
+	out.LogSamplingRatio = (*int32)(unsafe.Pointer(in.LogSamplingRatio))
 	return nil
 }
 
@@ -1254,6 +1258,8 @@ func autoConvert_controlplane_NetworkPolicyRule_To_v1beta2_NetworkPolicyRule(in
 	out.Priority = in.Priority
 	out.Action = (*v1alpha1.RuleAction)(unsafe.Pointer(in.Action))
 	out.EnableLogging = in.EnableLogging
+	out.LogRate = (*int32)(unsafe.Pointer(in.LogRate))
+	out.LogSamplingRatio = (*int32)(unsafe.Pointer(in.LogSamplingRatio))
 	out.AppliedToGroups = *(*[]string)(unsafe.Pointer(&in.AppliedToGroups))
 	return nil
 }
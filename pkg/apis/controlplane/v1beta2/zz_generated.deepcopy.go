@@ -779,6 +779,16 @@ func (in *NetworkPolicyRule) DeepCopyInto(out *NetworkPolicyRule) {
 		*out = make([]string, len(*in))
 		copy(*out, *in)
 	}
+	if in.LogRate != nil {
+		in, out := &in.LogRate, &out.LogRate
+		*out = new(int32)
+		**out = **in
+	}
+	if in.LogSamplingRatio != nil {
+		in, out := &in.LogSamplingRatio, &out.LogSamplingRatio
+		*out = new(int32)
+		**out = **in
+	}
 	return
 }
 
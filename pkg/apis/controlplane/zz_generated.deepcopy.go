@@ -774,6 +774,16 @@ func (in *NetworkPolicyRule) DeepCopyInto(out *NetworkPolicyRule) {
 		*out = new(v1alpha1.RuleAction)
 		**out = **in
 	}
+	if in.LogRate != nil {
+		in, out := &in.LogRate, &out.LogRate
+		*out = new(int32)
+		**out = **in
+	}
+	if in.LogSamplingRatio != nil {
+		in, out := &in.LogSamplingRatio, &out.LogSamplingRatio
+		*out = new(int32)
+		**out = **in
+	}
 	if in.AppliedToGroups != nil {
 		in, out := &in.AppliedToGroups, &out.AppliedToGroups
 		*out = make([]string, len(*in))
@@ -61,6 +61,10 @@ func addKnownTypes(scheme *runtime.Scheme) error {
 		&ExternalNodeList{},
 		&SupportBundleCollection{},
 		&SupportBundleCollectionList{},
+		&PacketCapture{},
+		&PacketCaptureList{},
+		&ConnectivityProbe{},
+		&ConnectivityProbeList{},
 	)
 
 	metav1.AddToGroupVersion(
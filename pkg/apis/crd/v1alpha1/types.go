@@ -126,6 +126,11 @@ type TraceflowSpec struct {
 	// Timeout specifies the timeout of the Traceflow in seconds. Defaults
 	// to 20 seconds if not set.
 	Timeout uint16 `json:"timeout,omitempty"`
+	// Bidirectional indicates the reply packet (TCP SYN/ACK, UDP, or ICMP
+	// echo reply) should also be generated by the destination and traced
+	// back to the source, when set to true. It is not supported for
+	// live-traffic Traceflow.
+	Bidirectional bool `json:"bidirectional,omitempty"`
 }
 
 // Source describes the source spec of the traceflow.
@@ -134,9 +139,14 @@ type Source struct {
 	Namespace string `json:"namespace,omitempty"`
 	// Pod is the source pod.
 	Pod string `json:"pod,omitempty"`
-	// IP is the source IPv4 or IPv6 address. IP as the source is supported
-	// only for live-traffic Traceflow.
+	// IP is the source IPv4 or IPv6 address. For non-live-traffic Traceflow,
+	// IP is an external source IP, and Node must be set to the Node through
+	// which the packet enters the cluster.
 	IP string `json:"ip,omitempty"`
+	// Node is the source Node. The packet is sent from the host network of
+	// the Node, or from the external source IP through the Node. Node as the
+	// source is supported only for non-live-traffic Traceflow.
+	Node string `json:"node,omitempty"`
 }
 
 // Destination describes the destination spec of the traceflow.
@@ -233,8 +243,18 @@ type TraceflowStatus struct {
 	StartTime *metav1.Time `json:"startTime,omitempty"`
 	// DataplaneTag is a tag to identify a traceflow session across Nodes.
 	DataplaneTag uint8 `json:"dataplaneTag,omitempty"`
+	// DataplaneTagNodes are the Nodes on which DataplaneTag is reserved for
+	// the Traceflow. The same tag can be used by other Traceflows on other
+	// Nodes. If empty, the tag is reserved on all Nodes.
+	DataplaneTagNodes []string `json:"dataplaneTagNodes,omitempty"`
+	// HostSenderNode is the Node from whose host network the packet is
+	// injected, when the source is a Node or a hostNetwork Pod.
+	HostSenderNode string `json:"hostSenderNode,omitempty"`
 	// Results is the collection of all observations on different nodes.
 	Results []NodeResult `json:"results,omitempty"`
+	// ReplyResults is the collection of all observations of the reply
+	// packet on different nodes, for bidirectional Traceflow.
+	ReplyResults []NodeResult `json:"replyResults,omitempty"`
 	// CapturedPacket is the captured packet in live-traffic Traceflow.
 	CapturedPacket *Packet `json:"capturedPacket,omitempty"`
 }
@@ -246,6 +266,15 @@ type NodeResult struct {
 	Role string `json:"role,omitempty" yaml:"role,omitempty"`
 	// Timestamp is the timestamp of the observations on the node.
 	Timestamp int64 `json:"timestamp,omitempty" yaml:"timestamp,omitempty"`
+	// InjectionTimestampMilliseconds is the Unix time in milliseconds at
+	// which the Traceflow packet was injected. It is only set by the sender
+	// node.
+	InjectionTimestampMilliseconds int64 `json:"injectionTimestampMilliseconds,omitempty" yaml:"injectionTimestampMilliseconds,omitempty"`
+	// DeliveryTimestampMilliseconds is the Unix time in milliseconds at
+	// which the Traceflow packet was observed being delivered to the
+	// destination, or forwarded out of the overlay. It is only set by the
+	// node delivering the packet.
+	DeliveryTimestampMilliseconds int64 `json:"deliveryTimestampMilliseconds,omitempty" yaml:"deliveryTimestampMilliseconds,omitempty"`
 	// Observations includes all observations from sender nodes, receiver ones, etc.
 	Observations []Observation `json:"observations,omitempty" yaml:"observations,omitempty"`
 }
@@ -416,6 +445,21 @@ type Rule struct {
 	// when rules are matched. Should be default to false.
 	// +optional
 	EnableLogging bool `json:"enableLogging"`
+	// LogRate is the maximum number of packets matching this rule that are
+	// logged per second on each Node. Packets exceeding the rate are not sent
+	// to the agent for logging, and the number of them is reported in the next
+	// log record of this rule. It can only be set when EnableLogging is true
+	// and the action of the rule is Allow, Drop or Reject.
+	// +optional
+	LogRate *int32 `json:"logRate,omitempty"`
+	// LogSamplingRatio indicates that only one out of every LogSamplingRatio
+	// connections matching this rule is logged on each Node. It must be between
+	// 1 and 100. The number of packets which are not logged as their connections
+	// are not sampled is reported in the next log record of this rule. It can
+	// only be set when EnableLogging is true and the action of the rule is
+	// Allow, Drop or Reject.
+	// +optional
+	LogSamplingRatio *int32 `json:"logSamplingRatio,omitempty"`
 	// Select workloads on which this rule will be applied to. Cannot be set in
 	// conjunction with NetworkPolicySpec/ClusterNetworkPolicySpec.AppliedTo.
 	// +optional
@@ -833,3 +877,236 @@ type BundleServerAuthConfiguration struct {
 	// AuthSecret is a Secret reference which stores the authentication value.
 	AuthSecret *v1.SecretReference `json:"authSecret"`
 }
+
+type PacketCapturePhase string
+
+const (
+	PacketCaptureRunning   PacketCapturePhase = "Running"
+	PacketCaptureSucceeded PacketCapturePhase = "Succeeded"
+	PacketCaptureFailed    PacketCapturePhase = "Failed"
+)
+
+// CapturePoint is the Pod on which the packets of a PacketCapture are
+// captured.
+type CapturePoint string
+
+const (
+	CapturePointSource      CapturePoint = "Source"
+	CapturePointDestination CapturePoint = "Destination"
+)
+
+// Default duration of a PacketCapture in seconds.
+const DefaultPacketCaptureDuration uint16 = 60
+
+// +genclient
+// +genclient:nonNamespaced
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+
+type PacketCapture struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   PacketCaptureSpec   `json:"spec,omitempty"`
+	Status PacketCaptureStatus `json:"status,omitempty"`
+}
+
+// PacketCaptureSpec describes the spec of the PacketCapture.
+type PacketCaptureSpec struct {
+	// Source is the source of the packets to capture. Source.Node is not
+	// supported.
+	Source Source `json:"source,omitempty"`
+	// Destination is the destination of the packets to capture.
+	// Destination.Service is not supported.
+	Destination Destination `json:"destination,omitempty"`
+	// Packet filters the packets to capture by protocol and ports. The reply
+	// packets of the matching packets are captured too.
+	Packet Packet `json:"packet,omitempty"`
+	// CaptureConfig specifies when to stop capturing packets.
+	CaptureConfig CaptureConfig `json:"captureConfig,omitempty"`
+	// CapturePoint is the Pod, source or destination, whose packets are
+	// captured on its Node. Defaults to Source.
+	CapturePoint CapturePoint `json:"capturePoint,omitempty"`
+}
+
+// CaptureConfig specifies the limits of a PacketCapture. The capture stops
+// when either limit is reached.
+type CaptureConfig struct {
+	// Number is the number of packets to capture. No limit if not set.
+	Number int32 `json:"number,omitempty"`
+	// Duration is the maximum duration of the capture in seconds. Defaults
+	// to 60 seconds if not set.
+	Duration uint16 `json:"duration,omitempty"`
+}
+
+// PacketCaptureStatus describes current status of the PacketCapture.
+type PacketCaptureStatus struct {
+	// Phase is the PacketCapture phase.
+	Phase PacketCapturePhase `json:"phase,omitempty"`
+	// Reason is a message indicating the reason of the PacketCapture's
+	// current phase.
+	Reason string `json:"reason,omitempty"`
+	// StartTime is the time at which the PacketCapture was started by the
+	// Antrea Controller.
+	StartTime *metav1.Time `json:"startTime,omitempty"`
+	// Node is the Node on which the packets are captured.
+	Node string `json:"node,omitempty"`
+	// NumberCaptured is the number of packets captured so far.
+	NumberCaptured int32 `json:"numberCaptured,omitempty"`
+	// FilePath is the path of the pcapng file on the Node. The file can be
+	// retrieved through the Antrea Agent API of the Node, and is deleted
+	// with the PacketCapture.
+	FilePath string `json:"filePath,omitempty"`
+}
+
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+
+type PacketCaptureList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+
+	Items []PacketCapture `json:"items"`
+}
+
+// ProbeResultType is the result of a single probe of a ConnectivityProbe.
+type ProbeResultType string
+
+const (
+	// ProbeSucceeded means that the probe packet reached the destination.
+	ProbeSucceeded ProbeResultType = "Succeeded"
+	// ProbeFailed means that the probe packet was dropped, or was not
+	// observed at the destination before the timeout.
+	ProbeFailed ProbeResultType = "Failed"
+	// ProbeError means that the probe packet could not be injected, for
+	// example because the source Pod does not exist or because all the
+	// Traceflow data plane tags were in use until the timeout.
+	ProbeError ProbeResultType = "Error"
+)
+
+type ConnectivityProbeConditionType string
+
+const (
+	// ConnectivityProbeReachable is True if the last probe succeeded, False
+	// if it failed, and Unknown if the packet could not be injected.
+	ConnectivityProbeReachable ConnectivityProbeConditionType = "Reachable"
+)
+
+const (
+	// Default interval between two probes of a ConnectivityProbe in seconds.
+	DefaultConnectivityProbeInterval int32 = 60
+	// Default timeout of a single probe of a ConnectivityProbe in seconds.
+	DefaultConnectivityProbeTimeout int32 = 10
+	// Default number of probe results kept in the ConnectivityProbe status.
+	DefaultConnectivityProbeHistoryLimit int32 = 10
+)
+
+// +genclient
+// +genclient:nonNamespaced
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+
+// ConnectivityProbe periodically checks the connectivity from a source to a
+// destination, by injecting a Traceflow packet at each interval.
+type ConnectivityProbe struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   ConnectivityProbeSpec   `json:"spec,omitempty"`
+	Status ConnectivityProbeStatus `json:"status,omitempty"`
+}
+
+// ConnectivityProbeSpec describes the spec of the ConnectivityProbe.
+type ConnectivityProbeSpec struct {
+	// Source is the source of the probe packet, as in a Traceflow.
+	Source Source `json:"source,omitempty"`
+	// Destination is the destination of the probe packet, as in a
+	// Traceflow.
+	Destination Destination `json:"destination,omitempty"`
+	// Packet is the probe packet, as in a Traceflow.
+	Packet Packet `json:"packet,omitempty"`
+	// IntervalSeconds is the interval between two probes. Defaults to 60
+	// seconds.
+	IntervalSeconds int32 `json:"intervalSeconds,omitempty"`
+	// TimeoutSeconds is the time after which a probe is considered failed
+	// if its packet has not been observed at the destination. It must be
+	// smaller than IntervalSeconds. Defaults to 10 seconds.
+	TimeoutSeconds int32 `json:"timeoutSeconds,omitempty"`
+	// HistoryLimit is the number of the most recent probe results kept in
+	// the status. Defaults to 10.
+	HistoryLimit int32 `json:"historyLimit,omitempty"`
+}
+
+// ConnectivityProbeStatus describes the results of the ConnectivityProbe.
+type ConnectivityProbeStatus struct {
+	// LastProbeTime is the time at which the last probe was started.
+	LastProbeTime *metav1.Time `json:"lastProbeTime,omitempty"`
+	// SucceededProbes is the number of probes which succeeded.
+	SucceededProbes int64 `json:"succeededProbes,omitempty"`
+	// FailedProbes is the number of probes which failed or could not be
+	// injected.
+	FailedProbes int64 `json:"failedProbes,omitempty"`
+	// Results are the results of the most recent probes, the most recent
+	// first.
+	Results []ProbeResult `json:"results,omitempty"`
+	// Represents the latest available observations of the ConnectivityProbe
+	// current state.
+	Conditions []ConnectivityProbeCondition `json:"conditions,omitempty"`
+}
+
+// ProbeResult is the result of a single probe.
+type ProbeResult struct {
+	// StartTime is the time at which the probe was started.
+	StartTime metav1.Time `json:"startTime"`
+	// Result is the result of the probe.
+	Result ProbeResultType `json:"result"`
+	// Reason is a message indicating why the probe failed.
+	Reason string `json:"reason,omitempty"`
+	// LatencyMilliseconds is the time from the injection of the probe packet
+	// on the source Node to its delivery on the destination Node, as
+	// reported by the Antrea Agents. When the source and destination Nodes
+	// differ, its accuracy depends on the synchronization of their clocks.
+	// It is only set for the probes which succeeded, when the Antrea Agents
+	// report the timestamps.
+	LatencyMilliseconds *int64 `json:"latencyMilliseconds,omitempty"`
+	// DropPoint is where the probe packet was dropped, if it was dropped.
+	DropPoint *ProbeDropPoint `json:"dropPoint,omitempty"`
+}
+
+// ProbeDropPoint is the Traceflow observation of a dropped probe packet.
+type ProbeDropPoint struct {
+	// Node is the Node on which the packet was dropped.
+	Node string `json:"node,omitempty"`
+	// Component is the component which dropped the packet.
+	Component TraceflowComponent `json:"component,omitempty"`
+	// ComponentInfo is the extension of Component field.
+	ComponentInfo string `json:"componentInfo,omitempty"`
+	// Action is the action to the packet, Dropped or Rejected.
+	Action TraceflowAction `json:"action,omitempty"`
+	// NetworkPolicy is the NetworkPolicy which dropped the packet, if any.
+	NetworkPolicy string `json:"networkPolicy,omitempty"`
+}
+
+// ConnectivityProbeCondition describes the state of a ConnectivityProbe at a
+// certain point.
+type ConnectivityProbeCondition struct {
+	// Type of ConnectivityProbe condition.
+	Type ConnectivityProbeConditionType `json:"type"`
+	// Status of the condition, one of True, False, Unknown.
+	Status metav1.ConditionStatus `json:"status"`
+	// Last time the condition transitioned from one status to another.
+	// +optional
+	LastTransitionTime metav1.Time `json:"lastTransitionTime,omitempty"`
+	// The reason for the condition's last transition.
+	// +optional
+	Reason string `json:"reason,omitempty"`
+	// A human-readable message indicating details about the transition.
+	// +optional
+	Message string `json:"message,omitempty"`
+}
+
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+
+type ConnectivityProbeList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+
+	Items []ConnectivityProbe `json:"items"`
+}
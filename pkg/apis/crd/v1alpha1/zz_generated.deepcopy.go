@@ -115,6 +115,22 @@ func (in *BundleServerAuthConfiguration) DeepCopy() *BundleServerAuthConfigurati
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *CaptureConfig) DeepCopyInto(out *CaptureConfig) {
+	*out = *in
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CaptureConfig.
+func (in *CaptureConfig) DeepCopy() *CaptureConfig {
+	if in == nil {
+		return nil
+	}
+	out := new(CaptureConfig)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *ClusterNetworkPolicy) DeepCopyInto(out *ClusterNetworkPolicy) {
 	*out = *in
@@ -213,6 +229,137 @@ func (in *ClusterNetworkPolicySpec) DeepCopy() *ClusterNetworkPolicySpec {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ConnectivityProbe) DeepCopyInto(out *ConnectivityProbe) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	in.Spec.DeepCopyInto(&out.Spec)
+	in.Status.DeepCopyInto(&out.Status)
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ConnectivityProbe.
+func (in *ConnectivityProbe) DeepCopy() *ConnectivityProbe {
+	if in == nil {
+		return nil
+	}
+	out := new(ConnectivityProbe)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *ConnectivityProbe) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ConnectivityProbeCondition) DeepCopyInto(out *ConnectivityProbeCondition) {
+	*out = *in
+	in.LastTransitionTime.DeepCopyInto(&out.LastTransitionTime)
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ConnectivityProbeCondition.
+func (in *ConnectivityProbeCondition) DeepCopy() *ConnectivityProbeCondition {
+	if in == nil {
+		return nil
+	}
+	out := new(ConnectivityProbeCondition)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ConnectivityProbeList) DeepCopyInto(out *ConnectivityProbeList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]ConnectivityProbe, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ConnectivityProbeList.
+func (in *ConnectivityProbeList) DeepCopy() *ConnectivityProbeList {
+	if in == nil {
+		return nil
+	}
+	out := new(ConnectivityProbeList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *ConnectivityProbeList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ConnectivityProbeSpec) DeepCopyInto(out *ConnectivityProbeSpec) {
+	*out = *in
+	out.Source = in.Source
+	out.Destination = in.Destination
+	in.Packet.DeepCopyInto(&out.Packet)
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ConnectivityProbeSpec.
+func (in *ConnectivityProbeSpec) DeepCopy() *ConnectivityProbeSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(ConnectivityProbeSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ConnectivityProbeStatus) DeepCopyInto(out *ConnectivityProbeStatus) {
+	*out = *in
+	if in.LastProbeTime != nil {
+		in, out := &in.LastProbeTime, &out.LastProbeTime
+		*out = (*in).DeepCopy()
+	}
+	if in.Results != nil {
+		in, out := &in.Results, &out.Results
+		*out = make([]ProbeResult, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.Conditions != nil {
+		in, out := &in.Conditions, &out.Conditions
+		*out = make([]ConnectivityProbeCondition, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ConnectivityProbeStatus.
+func (in *ConnectivityProbeStatus) DeepCopy() *ConnectivityProbeStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(ConnectivityProbeStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *Destination) DeepCopyInto(out *Destination) {
 	*out = *in
@@ -776,6 +923,107 @@ func (in *Packet) DeepCopy() *Packet {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *PacketCapture) DeepCopyInto(out *PacketCapture) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	in.Spec.DeepCopyInto(&out.Spec)
+	in.Status.DeepCopyInto(&out.Status)
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PacketCapture.
+func (in *PacketCapture) DeepCopy() *PacketCapture {
+	if in == nil {
+		return nil
+	}
+	out := new(PacketCapture)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *PacketCapture) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *PacketCaptureList) DeepCopyInto(out *PacketCaptureList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]PacketCapture, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PacketCaptureList.
+func (in *PacketCaptureList) DeepCopy() *PacketCaptureList {
+	if in == nil {
+		return nil
+	}
+	out := new(PacketCaptureList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *PacketCaptureList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *PacketCaptureSpec) DeepCopyInto(out *PacketCaptureSpec) {
+	*out = *in
+	out.Source = in.Source
+	out.Destination = in.Destination
+	in.Packet.DeepCopyInto(&out.Packet)
+	out.CaptureConfig = in.CaptureConfig
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PacketCaptureSpec.
+func (in *PacketCaptureSpec) DeepCopy() *PacketCaptureSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(PacketCaptureSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *PacketCaptureStatus) DeepCopyInto(out *PacketCaptureStatus) {
+	*out = *in
+	if in.StartTime != nil {
+		in, out := &in.StartTime, &out.StartTime
+		*out = (*in).DeepCopy()
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PacketCaptureStatus.
+func (in *PacketCaptureStatus) DeepCopy() *PacketCaptureStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(PacketCaptureStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *PeerNamespaces) DeepCopyInto(out *PeerNamespaces) {
 	*out = *in
@@ -792,6 +1040,49 @@ func (in *PeerNamespaces) DeepCopy() *PeerNamespaces {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ProbeDropPoint) DeepCopyInto(out *ProbeDropPoint) {
+	*out = *in
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ProbeDropPoint.
+func (in *ProbeDropPoint) DeepCopy() *ProbeDropPoint {
+	if in == nil {
+		return nil
+	}
+	out := new(ProbeDropPoint)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ProbeResult) DeepCopyInto(out *ProbeResult) {
+	*out = *in
+	in.StartTime.DeepCopyInto(&out.StartTime)
+	if in.LatencyMilliseconds != nil {
+		in, out := &in.LatencyMilliseconds, &out.LatencyMilliseconds
+		*out = new(int64)
+		**out = **in
+	}
+	if in.DropPoint != nil {
+		in, out := &in.DropPoint, &out.DropPoint
+		*out = new(ProbeDropPoint)
+		**out = **in
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ProbeResult.
+func (in *ProbeResult) DeepCopy() *ProbeResult {
+	if in == nil {
+		return nil
+	}
+	out := new(ProbeResult)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *Rule) DeepCopyInto(out *Rule) {
 	*out = *in
@@ -833,6 +1124,16 @@ func (in *Rule) DeepCopyInto(out *Rule) {
 		*out = make([]NamespacedName, len(*in))
 		copy(*out, *in)
 	}
+	if in.LogRate != nil {
+		in, out := &in.LogRate, &out.LogRate
+		*out = new(int32)
+		**out = **in
+	}
+	if in.LogSamplingRatio != nil {
+		in, out := &in.LogSamplingRatio, &out.LogSamplingRatio
+		*out = new(int32)
+		**out = **in
+	}
 	if in.AppliedTo != nil {
 		in, out := &in.AppliedTo, &out.AppliedTo
 		*out = make([]NetworkPolicyPeer, len(*in))
@@ -1177,6 +1478,11 @@ func (in *TraceflowStatus) DeepCopyInto(out *TraceflowStatus) {
 		in, out := &in.StartTime, &out.StartTime
 		*out = (*in).DeepCopy()
 	}
+	if in.DataplaneTagNodes != nil {
+		in, out := &in.DataplaneTagNodes, &out.DataplaneTagNodes
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
 	if in.Results != nil {
 		in, out := &in.Results, &out.Results
 		*out = make([]NodeResult, len(*in))
@@ -1184,6 +1490,13 @@ func (in *TraceflowStatus) DeepCopyInto(out *TraceflowStatus) {
 			(*in)[i].DeepCopyInto(&(*out)[i])
 		}
 	}
+	if in.ReplyResults != nil {
+		in, out := &in.ReplyResults, &out.ReplyResults
+		*out = make([]NodeResult, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
 	if in.CapturedPacket != nil {
 		in, out := &in.CapturedPacket, &out.CapturedPacket
 		*out = new(Packet)
@@ -202,6 +202,61 @@ type Egress struct {
 type EgressStatus struct {
 	// The name of the Node that holds the Egress IP.
 	EgressNode string `json:"egressNode"`
+	// Represents the latest available observations of the Egress's current state.
+	// +optional
+	Conditions []EgressCondition `json:"conditions,omitempty"`
+	// FailoverHistory records the most recent transitions of the Egress IP between Nodes, in chronological order.
+	// At most MaxEgressFailoverHistory transitions are kept.
+	// +optional
+	FailoverHistory []EgressFailover `json:"failoverHistory,omitempty"`
+}
+
+// MaxEgressFailoverHistory is the maximum number of transitions kept in EgressStatus.FailoverHistory.
+const MaxEgressFailoverHistory = 10
+
+type EgressConditionType string
+
+const (
+	// IPAllocated is added in an Egress by antrea-controller when its EgressIP has been allocated from the
+	// ExternalIPPool.
+	IPAllocated EgressConditionType = "IPAllocated"
+	// IPAssigned is added in an Egress by antrea-agent when its EgressIP has been assigned to a Node.
+	IPAssigned EgressConditionType = "IPAssigned"
+	// NodeUnreachable is added in an Egress by antrea-agent when its EgressIP failed over to another Node because the
+	// previous Node became unreachable.
+	NodeUnreachable EgressConditionType = "NodeUnreachable"
+)
+
+// EgressCondition describes the state of an Egress at a certain point.
+type EgressCondition struct {
+	// Type of Egress condition.
+	Type EgressConditionType `json:"type"`
+	// Status of the condition, one of True, False, Unknown.
+	Status metav1.ConditionStatus `json:"status"`
+	// Last time the condition transitioned from one status to another.
+	// +optional
+	LastTransitionTime metav1.Time `json:"lastTransitionTime,omitempty"`
+	// The reason for the condition's last transition.
+	// +optional
+	Reason string `json:"reason,omitempty"`
+	// A human-readable message indicating details about the transition.
+	// +optional
+	Message string `json:"message,omitempty"`
+}
+
+// EgressFailover describes a transition of the Egress IP from one Node to another.
+type EgressFailover struct {
+	// The name of the Node that held the Egress IP before the transition. It is empty if no Node held the IP.
+	// +optional
+	FromNode string `json:"fromNode,omitempty"`
+	// The name of the Node that holds the Egress IP after the transition. It is empty if the previous Node released
+	// the IP and no Node has taken it over yet.
+	// +optional
+	ToNode string `json:"toNode,omitempty"`
+	// The time when the transition happened.
+	Time metav1.Time `json:"time"`
+	// The reason for the transition.
+	Reason string `json:"reason"`
 }
 
 // EgressSpec defines the desired state for Egress.
@@ -218,6 +273,21 @@ type EgressSpec struct {
 	// If it is non-empty, the EgressIP will be assigned to a Node specified by the pool automatically and will failover
 	// to a different Node when the Node becomes unreachable.
 	ExternalIPPool string `json:"externalIPPool"`
+	// Bandwidth specifies the rate limit of the traffic sent to the external network through this Egress. The limit
+	// applies to the aggregated traffic of all selected Pods, regardless of which Nodes they are running on.
+	// Egresses sharing the same EgressIP share the same limit, in which case the smallest rate takes effect.
+	// +optional
+	Bandwidth *Bandwidth `json:"bandwidth,omitempty"`
+}
+
+// Bandwidth defines the rate limit of the traffic of an Egress.
+type Bandwidth struct {
+	// Rate specifies the maximum traffic rate in bits per second, e.g. 500k, 100M, 1G.
+	Rate string `json:"rate"`
+	// Burst specifies the maximum burst size in bits when the traffic exceeds the rate, e.g. 500k, 100M, 1G.
+	// If it is empty, the value of Rate is used.
+	// +optional
+	Burst string `json:"burst,omitempty"`
 }
 
 // +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
@@ -253,6 +323,31 @@ type ExternalIPPoolSpec struct {
 	IPRanges []IPRange `json:"ipRanges"`
 	// The Nodes that the external IPs can be assigned to. If empty, it means all Nodes.
 	NodeSelector metav1.LabelSelector `json:"nodeSelector"`
+	// NodeSelection determines how the owner Node of each external IP is selected among the alive Nodes
+	// matched by NodeSelector. If nil, the ConsistentHash strategy is used.
+	NodeSelection *NodeSelection `json:"nodeSelection,omitempty"`
+}
+
+type NodeSelectionStrategy string
+
+const (
+	// NodeSelectionStrategyConsistentHash selects the owner Node of an IP via consistent hashing.
+	NodeSelectionStrategyConsistentHash NodeSelectionStrategy = "ConsistentHash"
+	// NodeSelectionStrategyLeastLoaded selects the Node which owns the fewest IPs of the pool as the owner Node of an
+	// IP. Ties are broken via consistent hashing.
+	NodeSelectionStrategyLeastLoaded NodeSelectionStrategy = "LeastLoaded"
+	// NodeSelectionStrategyPreferenceList selects the first available Node of PreferredNodes as the owner Node of
+	// all IPs. The IPs move back to a more preferred Node once it becomes available again. If none of the preferred
+	// Nodes is available, the owner Node is selected via consistent hashing.
+	NodeSelectionStrategyPreferenceList NodeSelectionStrategy = "PreferenceList"
+)
+
+type NodeSelection struct {
+	// The strategy used to select the owner Node of each IP.
+	Strategy NodeSelectionStrategy `json:"strategy"`
+	// The names of the preferred Nodes, from the most preferred to the least preferred. It must be set if Strategy
+	// is PreferenceList.
+	PreferredNodes []string `json:"preferredNodes,omitempty"`
 }
 
 // IPRange is a set of contiguous IP addresses, represented by a CIDR or a pair of start and end IPs.
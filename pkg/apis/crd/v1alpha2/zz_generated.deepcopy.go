@@ -56,6 +56,22 @@ func (in *AppliedTo) DeepCopy() *AppliedTo {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *Bandwidth) DeepCopyInto(out *Bandwidth) {
+	*out = *in
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new Bandwidth.
+func (in *Bandwidth) DeepCopy() *Bandwidth {
+	if in == nil {
+		return nil
+	}
+	out := new(Bandwidth)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *ClusterGroup) DeepCopyInto(out *ClusterGroup) {
 	*out = *in
@@ -159,7 +175,7 @@ func (in *Egress) DeepCopyInto(out *Egress) {
 	out.TypeMeta = in.TypeMeta
 	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
 	in.Spec.DeepCopyInto(&out.Spec)
-	out.Status = in.Status
+	in.Status.DeepCopyInto(&out.Status)
 	return
 }
 
@@ -181,6 +197,40 @@ func (in *Egress) DeepCopyObject() runtime.Object {
 	return nil
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *EgressCondition) DeepCopyInto(out *EgressCondition) {
+	*out = *in
+	in.LastTransitionTime.DeepCopyInto(&out.LastTransitionTime)
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new EgressCondition.
+func (in *EgressCondition) DeepCopy() *EgressCondition {
+	if in == nil {
+		return nil
+	}
+	out := new(EgressCondition)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *EgressFailover) DeepCopyInto(out *EgressFailover) {
+	*out = *in
+	in.Time.DeepCopyInto(&out.Time)
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new EgressFailover.
+func (in *EgressFailover) DeepCopy() *EgressFailover {
+	if in == nil {
+		return nil
+	}
+	out := new(EgressFailover)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *EgressList) DeepCopyInto(out *EgressList) {
 	*out = *in
@@ -218,6 +268,11 @@ func (in *EgressList) DeepCopyObject() runtime.Object {
 func (in *EgressSpec) DeepCopyInto(out *EgressSpec) {
 	*out = *in
 	in.AppliedTo.DeepCopyInto(&out.AppliedTo)
+	if in.Bandwidth != nil {
+		in, out := &in.Bandwidth, &out.Bandwidth
+		*out = new(Bandwidth)
+		**out = **in
+	}
 	return
 }
 
@@ -234,6 +289,20 @@ func (in *EgressSpec) DeepCopy() *EgressSpec {
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *EgressStatus) DeepCopyInto(out *EgressStatus) {
 	*out = *in
+	if in.Conditions != nil {
+		in, out := &in.Conditions, &out.Conditions
+		*out = make([]EgressCondition, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.FailoverHistory != nil {
+		in, out := &in.FailoverHistory, &out.FailoverHistory
+		*out = make([]EgressFailover, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
 	return
 }
 
@@ -419,6 +488,11 @@ func (in *ExternalIPPoolSpec) DeepCopyInto(out *ExternalIPPoolSpec) {
 		copy(*out, *in)
 	}
 	in.NodeSelector.DeepCopyInto(&out.NodeSelector)
+	if in.NodeSelection != nil {
+		in, out := &in.NodeSelection, &out.NodeSelection
+		*out = new(NodeSelection)
+		(*in).DeepCopyInto(*out)
+	}
 	return
 }
 
@@ -774,6 +848,27 @@ func (in *NetworkDevice) DeepCopy() *NetworkDevice {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *NodeSelection) DeepCopyInto(out *NodeSelection) {
+	*out = *in
+	if in.PreferredNodes != nil {
+		in, out := &in.PreferredNodes, &out.PreferredNodes
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new NodeSelection.
+func (in *NodeSelection) DeepCopy() *NodeSelection {
+	if in == nil {
+		return nil
+	}
+	out := new(NodeSelection)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *OVSInternalPort) DeepCopyInto(out *OVSInternalPort) {
 	*out = *in
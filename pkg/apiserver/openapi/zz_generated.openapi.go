@@ -1282,6 +1282,13 @@ func schema_pkg_apis_controlplane_v1beta2_NetworkPolicy(ref common.ReferenceCall
 							Ref:         ref("antrea.io/antrea/pkg/apis/controlplane/v1beta2.NetworkPolicyReference"),
 						},
 					},
+					"tier": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Tier is the name of the Tier associated with this Network Policy. The Tier will remain empty for K8s NetworkPolicy.",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
 				},
 			},
 		},
@@ -1565,6 +1572,20 @@ func schema_pkg_apis_controlplane_v1beta2_NetworkPolicyRule(ref common.Reference
 							Format:      "",
 						},
 					},
+					"logRate": {
+						SchemaProps: spec.SchemaProps{
+							Description: "LogRate is the maximum number of packets matching this rule that are logged per second on each Node. nil means the rate is not limited per rule.",
+							Type:        []string{"integer"},
+							Format:      "int32",
+						},
+					},
+					"logSamplingRatio": {
+						SchemaProps: spec.SchemaProps{
+							Description: "LogSamplingRatio indicates that only one out of every LogSamplingRatio connections matching this rule is logged. nil means all connections are logged.",
+							Type:        []string{"integer"},
+							Format:      "int32",
+						},
+					},
 				},
 				Required: []string{"enableLogging"},
 			},
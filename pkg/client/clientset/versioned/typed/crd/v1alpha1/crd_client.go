@@ -27,8 +27,10 @@ import (
 type CrdV1alpha1Interface interface {
 	RESTClient() rest.Interface
 	ClusterNetworkPoliciesGetter
+	ConnectivityProbesGetter
 	ExternalNodesGetter
 	NetworkPoliciesGetter
+	PacketCapturesGetter
 	SupportBundleCollectionsGetter
 	TiersGetter
 	TraceflowsGetter
@@ -43,6 +45,10 @@ func (c *CrdV1alpha1Client) ClusterNetworkPolicies() ClusterNetworkPolicyInterfa
 	return newClusterNetworkPolicies(c)
 }
 
+func (c *CrdV1alpha1Client) ConnectivityProbes() ConnectivityProbeInterface {
+	return newConnectivityProbes(c)
+}
+
 func (c *CrdV1alpha1Client) ExternalNodes(namespace string) ExternalNodeInterface {
 	return newExternalNodes(c, namespace)
 }
@@ -51,6 +57,10 @@ func (c *CrdV1alpha1Client) NetworkPolicies(namespace string) NetworkPolicyInter
 	return newNetworkPolicies(c, namespace)
 }
 
+func (c *CrdV1alpha1Client) PacketCaptures() PacketCaptureInterface {
+	return newPacketCaptures(c)
+}
+
 func (c *CrdV1alpha1Client) SupportBundleCollections() SupportBundleCollectionInterface {
 	return newSupportBundleCollections(c)
 }
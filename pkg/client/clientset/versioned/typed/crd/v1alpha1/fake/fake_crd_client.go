@@ -30,6 +30,10 @@ func (c *FakeCrdV1alpha1) ClusterNetworkPolicies() v1alpha1.ClusterNetworkPolicy
 	return &FakeClusterNetworkPolicies{c}
 }
 
+func (c *FakeCrdV1alpha1) ConnectivityProbes() v1alpha1.ConnectivityProbeInterface {
+	return &FakeConnectivityProbes{c}
+}
+
 func (c *FakeCrdV1alpha1) ExternalNodes(namespace string) v1alpha1.ExternalNodeInterface {
 	return &FakeExternalNodes{c, namespace}
 }
@@ -38,6 +42,10 @@ func (c *FakeCrdV1alpha1) NetworkPolicies(namespace string) v1alpha1.NetworkPoli
 	return &FakeNetworkPolicies{c, namespace}
 }
 
+func (c *FakeCrdV1alpha1) PacketCaptures() v1alpha1.PacketCaptureInterface {
+	return &FakePacketCaptures{c}
+}
+
 func (c *FakeCrdV1alpha1) SupportBundleCollections() v1alpha1.SupportBundleCollectionInterface {
 	return &FakeSupportBundleCollections{c}
 }
@@ -18,10 +18,14 @@ package v1alpha1
 
 type ClusterNetworkPolicyExpansion interface{}
 
+type ConnectivityProbeExpansion interface{}
+
 type ExternalNodeExpansion interface{}
 
 type NetworkPolicyExpansion interface{}
 
+type PacketCaptureExpansion interface{}
+
 type SupportBundleCollectionExpansion interface{}
 
 type TierExpansion interface{}
@@ -24,10 +24,14 @@ import (
 type Interface interface {
 	// ClusterNetworkPolicies returns a ClusterNetworkPolicyInformer.
 	ClusterNetworkPolicies() ClusterNetworkPolicyInformer
+	// ConnectivityProbes returns a ConnectivityProbeInformer.
+	ConnectivityProbes() ConnectivityProbeInformer
 	// ExternalNodes returns a ExternalNodeInformer.
 	ExternalNodes() ExternalNodeInformer
 	// NetworkPolicies returns a NetworkPolicyInformer.
 	NetworkPolicies() NetworkPolicyInformer
+	// PacketCaptures returns a PacketCaptureInformer.
+	PacketCaptures() PacketCaptureInformer
 	// SupportBundleCollections returns a SupportBundleCollectionInformer.
 	SupportBundleCollections() SupportBundleCollectionInformer
 	// Tiers returns a TierInformer.
@@ -52,6 +56,11 @@ func (v *version) ClusterNetworkPolicies() ClusterNetworkPolicyInformer {
 	return &clusterNetworkPolicyInformer{factory: v.factory, tweakListOptions: v.tweakListOptions}
 }
 
+// ConnectivityProbes returns a ConnectivityProbeInformer.
+func (v *version) ConnectivityProbes() ConnectivityProbeInformer {
+	return &connectivityProbeInformer{factory: v.factory, tweakListOptions: v.tweakListOptions}
+}
+
 // ExternalNodes returns a ExternalNodeInformer.
 func (v *version) ExternalNodes() ExternalNodeInformer {
 	return &externalNodeInformer{factory: v.factory, namespace: v.namespace, tweakListOptions: v.tweakListOptions}
@@ -62,6 +71,11 @@ func (v *version) NetworkPolicies() NetworkPolicyInformer {
 	return &networkPolicyInformer{factory: v.factory, namespace: v.namespace, tweakListOptions: v.tweakListOptions}
 }
 
+// PacketCaptures returns a PacketCaptureInformer.
+func (v *version) PacketCaptures() PacketCaptureInformer {
+	return &packetCaptureInformer{factory: v.factory, tweakListOptions: v.tweakListOptions}
+}
+
 // SupportBundleCollections returns a SupportBundleCollectionInformer.
 func (v *version) SupportBundleCollections() SupportBundleCollectionInformer {
 	return &supportBundleCollectionInformer{factory: v.factory, tweakListOptions: v.tweakListOptions}
@@ -56,10 +56,14 @@ func (f *sharedInformerFactory) ForResource(resource schema.GroupVersionResource
 	// Group=crd.antrea.io, Version=v1alpha1
 	case v1alpha1.SchemeGroupVersion.WithResource("clusternetworkpolicies"):
 		return &genericInformer{resource: resource.GroupResource(), informer: f.Crd().V1alpha1().ClusterNetworkPolicies().Informer()}, nil
+	case v1alpha1.SchemeGroupVersion.WithResource("connectivityprobes"):
+		return &genericInformer{resource: resource.GroupResource(), informer: f.Crd().V1alpha1().ConnectivityProbes().Informer()}, nil
 	case v1alpha1.SchemeGroupVersion.WithResource("externalnodes"):
 		return &genericInformer{resource: resource.GroupResource(), informer: f.Crd().V1alpha1().ExternalNodes().Informer()}, nil
 	case v1alpha1.SchemeGroupVersion.WithResource("networkpolicies"):
 		return &genericInformer{resource: resource.GroupResource(), informer: f.Crd().V1alpha1().NetworkPolicies().Informer()}, nil
+	case v1alpha1.SchemeGroupVersion.WithResource("packetcaptures"):
+		return &genericInformer{resource: resource.GroupResource(), informer: f.Crd().V1alpha1().PacketCaptures().Informer()}, nil
 	case v1alpha1.SchemeGroupVersion.WithResource("supportbundlecollections"):
 		return &genericInformer{resource: resource.GroupResource(), informer: f.Crd().V1alpha1().SupportBundleCollections().Informer()}, nil
 	case v1alpha1.SchemeGroupVersion.WithResource("tiers"):
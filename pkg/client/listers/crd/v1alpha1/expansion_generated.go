@@ -20,6 +20,10 @@ package v1alpha1
 // ClusterNetworkPolicyLister.
 type ClusterNetworkPolicyListerExpansion interface{}
 
+// ConnectivityProbeListerExpansion allows custom methods to be added to
+// ConnectivityProbeLister.
+type ConnectivityProbeListerExpansion interface{}
+
 // ExternalNodeListerExpansion allows custom methods to be added to
 // ExternalNodeLister.
 type ExternalNodeListerExpansion interface{}
@@ -36,6 +40,10 @@ type NetworkPolicyListerExpansion interface{}
 // NetworkPolicyNamespaceLister.
 type NetworkPolicyNamespaceListerExpansion interface{}
 
+// PacketCaptureListerExpansion allows custom methods to be added to
+// PacketCaptureLister.
+type PacketCaptureListerExpansion interface{}
+
 // SupportBundleCollectionListerExpansion allows custom methods to be added to
 // SupportBundleCollectionLister.
 type SupportBundleCollectionListerExpansion interface{}
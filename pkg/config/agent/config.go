@@ -156,6 +156,12 @@ type AgentConfig struct {
 	// Defaults to "15s". Valid time units are "ns", "us" (or "µs"), "ms", "s",
 	// "m", "h".
 	IdleFlowExportTimeout string `yaml:"idleFlowExportTimeout,omitempty"`
+	// Provide the maximum size in MiB of the on-disk spool in which the flow
+	// exporter stores the flow records which cannot be sent while the flow
+	// collector is unreachable. The records are sent in order once the flow
+	// collector is reachable again. Setting it to 0 disables the spool.
+	// Defaults to 64.
+	FlowExportSpoolSize *int `yaml:"flowExportSpoolSize,omitempty"`
 	// Deprecated. Use the NodePortLocal config options instead.
 	NPLPortRange string `yaml:"nplPortRange,omitempty"`
 	// NodePortLocal (NPL) configuration options.
@@ -199,6 +205,8 @@ type AgentConfig struct {
 	Egress EgressConfig `yaml:"egress"`
 	// IPsec related configurations.
 	IPsec IPsecConfig `yaml:"ipsec"`
+	// Audit logging configuration options for Antrea-native policies.
+	AuditLogging AuditLoggingConfig `yaml:"auditLogging,omitempty"`
 	// Multicluster configuration options.
 	Multicluster MulticlusterConfig `yaml:"multicluster,omitempty"`
 	// NodeType is type of the Node where Antrea Agent is running.
@@ -228,6 +236,13 @@ type AntreaProxyConfig struct {
 	// kube-proxy is removed from the cluser, otherwise kube-proxy will still load-balance this traffic.
 	// Defaults to true.
 	ProxyLoadBalancerIPs *bool `yaml:"proxyLoadBalancerIPs,omitempty"`
+	// The default algorithm used to select an Endpoint for the traffic of a Service. It can be overridden for a
+	// Service with the annotation "service.antrea.io/load-balancer-algorithm". Supported values are:
+	// - hash:   OVS selects an Endpoint with the hash computed by the datapath.
+	// - maglev: all Nodes select the same Endpoint for a connection using the Maglev consistent hashing algorithm,
+	//           which minimizes the number of connections remapped when the Endpoints of the Service change.
+	// Defaults to "hash".
+	LoadBalancerAlgorithm string `yaml:"loadBalancerAlgorithm,omitempty"`
 }
 
 type WireGuardConfig struct {
@@ -268,6 +283,33 @@ type IPsecConfig struct {
 	AuthenticationMode string `yaml:"authenticationMode,omitempty"`
 }
 
+type AuditLoggingConfig struct {
+	// The destination of the audit logs of Antrea-native policies. It has the following options:
+	// - file (default): Write logs to the local file "networkpolicy/np.log" in the log directory of antrea-agent,
+	//                   which is rotated automatically.
+	// - syslog:         Send logs to the syslog server specified by Syslog, using the RFC 5424 format.
+	// - stdout:         Write logs to the standard output of antrea-agent.
+	Sink string `yaml:"sink,omitempty"`
+	// The format of each audit log record. It has the following options:
+	// - text (default): A line of space-separated fields.
+	// - json:           A JSON object, which includes the policy name, Tier priority, rule name, action, local Pod
+	//                   identities, 5-tuple and the number of deduplicated packets.
+	Format string `yaml:"format,omitempty"`
+	// Syslog server configuration. It is used only when Sink is "syslog".
+	Syslog AuditLoggingSyslogConfig `yaml:"syslog,omitempty"`
+}
+
+type AuditLoggingSyslogConfig struct {
+	// The address of the syslog server, in the format of "<host>:<port>".
+	Address string `yaml:"address,omitempty"`
+	// The transport protocol used to connect to the syslog server. Supported values are "udp" (default), "tcp"
+	// and "tls".
+	Protocol string `yaml:"protocol,omitempty"`
+	// The path of the CA certificate bundle used to verify the syslog server when Protocol is "tls". If it is not
+	// set, the system root CAs are used.
+	CACertFile string `yaml:"caCertFile,omitempty"`
+}
+
 type MulticlusterConfig struct {
 	// Enable Multicluster which allow cross-cluster traffic between member clusters
 	// in a ClusterSet.
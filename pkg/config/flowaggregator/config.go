@@ -55,6 +55,13 @@ type FlowAggregatorConfig struct {
 	ClickHouse ClickHouseConfig `yaml:"clickHouse,omitempty"`
 	// s3Uploader contains configuration options for uploading flow records to AWS S3.
 	S3Uploader S3UploaderConfig `yaml:"s3Uploader,omitempty"`
+	// kafka contains configuration options for producing flow records to Kafka.
+	Kafka KafkaConfig `yaml:"kafka,omitempty"`
+	// fileExporter contains configuration options for writing flow records to local files.
+	FileExporter FileExporterConfig `yaml:"fileExporter,omitempty"`
+	// flowMetrics contains configuration options for exposing Prometheus metrics computed
+	// from flow records.
+	FlowMetrics FlowMetricsConfig `yaml:"flowMetrics,omitempty"`
 }
 
 type RecordContentsConfig struct {
@@ -87,6 +94,9 @@ type FlowCollectorConfig struct {
 	// Provide format for records sent to the configured flow collector. Supported formats are IPFIX and JSON.
 	// Defaults to "IPFIX"
 	RecordFormat string `yaml:"recordFormat,omitempty"`
+	// Filters are the rules used to select the flow records sent to the flow collector. All flow
+	// records are sent if empty.
+	Filters []FlowFilterRule `yaml:"filters,omitempty"`
 }
 
 type ClickHouseConfig struct {
@@ -105,6 +115,9 @@ type ClickHouseConfig struct {
 	// Defaults to "8s". Valid time units are "ns", "us" (or "µs"), "ms", "s", "m", "h".
 	// Min value allowed is "1s".
 	CommitInterval string `yaml:"commitInterval,omitempty"`
+	// Filters are the rules used to select the flow records committed to ClickHouse. All flow
+	// records are committed if empty.
+	Filters []FlowFilterRule `yaml:"filters,omitempty"`
 }
 
 type S3UploaderConfig struct {
@@ -125,14 +138,168 @@ type S3UploaderConfig struct {
 	// belongs to. If region is omitted, the value of the AWS_REGION environment variable will
 	// be used, and if it is missing, we will default to "us-west-2".
 	Region string `yaml:"region,omitempty"`
-	// RecordFormat defines the format of the flow records uploaded to S3. Only "CSV" is
-	// supported at the moment.
+	// RecordFormat defines the format of the flow records uploaded to S3. Supported formats
+	// are "CSV", "JSON" (one JSON object per line) and "Parquet". Defaults to "CSV".
 	RecordFormat string `yaml:"recordFormat,omitempty"`
-	// Compress enables gzip compression when uploading files to S3. Defaults to true.
+	// Compress enables gzip compression when uploading files to S3. For the Parquet format,
+	// the data pages are compressed instead of the whole file. Defaults to true.
 	Compress *bool `yaml:"compress,omitempty"`
-	// MaxRecordsPerFile is the maximum number of records per file uploaded. It is not recommended
-	// to change this value. Defaults to 1,000,000.
+	// MaxRecordsPerFile is the maximum number of records per file uploaded. As the records of
+	// a Parquet file are kept in memory until the file is uploaded, a smaller value may be
+	// used with the Parquet format to reduce memory usage. Defaults to 1,000,000.
 	MaxRecordsPerFile int32 `yaml:"maxRecordsPerFile,omitempty"`
+	// KeyTemplate is the Go template used to generate the key of each file under BucketPrefix.
+	// The available fields are {{.Year}}, {{.Month}}, {{.Day}}, {{.Hour}} and {{.Minute}}
+	// (time at which the file was started, in UTC), {{.ClusterUUID}}, {{.Random}} (a random
+	// string, which must be included) and {{.Extension}} (e.g. "csv.gz"). A new file is
+	// started whenever the time fields used by the template change, so that Hive-style
+	// partitions (e.g. "year={{.Year}}/month={{.Month}}/...") only contain records from
+	// that period. Defaults to "records-{{.Random}}.{{.Extension}}".
+	KeyTemplate string `yaml:"keyTemplate,omitempty"`
 	// UploadInterval is the duration between each file upload to S3.
 	UploadInterval string `yaml:"uploadInterval,omitempty"`
+	// Filters are the rules used to select the flow records uploaded to S3. All flow
+	// records are uploaded if empty.
+	Filters []FlowFilterRule `yaml:"filters,omitempty"`
+}
+
+type KafkaConfig struct {
+	// Enable is the switch to enable producing flow records to Kafka.
+	// The SASL credentials and the CA certificate are read from the KAFKA_SASL_USERNAME,
+	// KAFKA_SASL_PASSWORD and KAFKA_CA_CERT environment variables.
+	Enable bool `yaml:"enable,omitempty"`
+	// Brokers is the list of bootstrap brokers used to discover the Kafka cluster, in the
+	// format "<host>:<port>". If this field is empty, initialization will fail.
+	Brokers []string `yaml:"brokers,omitempty"`
+	// Topic is the Kafka topic to which flow records will be produced. The topic must
+	// exist. If this field is empty, initialization will fail.
+	Topic string `yaml:"topic,omitempty"`
+	// Encoding defines the encoding of the flow records. Supported encodings are "JSON" and
+	// "Protobuf". Defaults to "JSON".
+	Encoding string `yaml:"encoding,omitempty"`
+	// PartitionBy defines how flow records are distributed to the partitions of the topic.
+	// Supported values are "None" (round-robin), "SourceNamespace" and "SourceNode". Records
+	// with the same source Namespace or Node are produced to the same partition, which
+	// preserves their order. Defaults to "None".
+	PartitionBy string `yaml:"partitionBy,omitempty"`
+	// MaxBatchSize is the maximum number of flow records produced in a single request.
+	// Defaults to 1000.
+	MaxBatchSize int32 `yaml:"maxBatchSize,omitempty"`
+	// FlushInterval is the maximum duration a flow record waits before being produced, if
+	// the batch is not full. Defaults to "1s". Valid time units are "ns", "us" (or "µs"),
+	// "ms", "s", "m", "h". Min value allowed is "100ms".
+	FlushInterval string `yaml:"flushInterval,omitempty"`
+	// MaxBufferedRecords is the maximum number of flow records kept in memory when they
+	// cannot be produced, e.g. because Kafka is unavailable. They are retried on each flush.
+	// When the limit is reached, the oldest records are dropped. Defaults to 100,000.
+	MaxBufferedRecords int32 `yaml:"maxBufferedRecords,omitempty"`
+	// TLS contains the options to connect to Kafka brokers over TLS.
+	TLS KafkaTLSConfig `yaml:"tls,omitempty"`
+	// SASL contains the options to authenticate to Kafka brokers.
+	SASL KafkaSASLConfig `yaml:"sasl,omitempty"`
+	// Filters are the rules used to select the flow records produced to Kafka. All flow
+	// records are produced if empty.
+	Filters []FlowFilterRule `yaml:"filters,omitempty"`
+}
+
+type KafkaTLSConfig struct {
+	// Enable is the switch to connect to Kafka brokers over TLS.
+	Enable bool `yaml:"enable,omitempty"`
+	// InsecureSkipVerify disables the verification of the brokers' certificates. It should
+	// only be used for testing.
+	InsecureSkipVerify bool `yaml:"insecureSkipVerify,omitempty"`
+}
+
+type FileExporterConfig struct {
+	// Enable is the switch to enable writing flow records to local files.
+	Enable bool `yaml:"enable,omitempty"`
+	// Path is the directory in which flow records are written. The current file is named
+	// "flows.csv" or "flows.json" depending on RecordFormat, and rotated files are kept in
+	// the same directory, with their rotation time added to their name. Defaults to
+	// "/var/log/antrea/flow-aggregator/flows".
+	Path string `yaml:"path,omitempty"`
+	// RecordFormat defines the format of the flow records written to files. Supported formats
+	// are "CSV" and "JSON" (one JSON object per line). Defaults to "CSV".
+	RecordFormat string `yaml:"recordFormat,omitempty"`
+	// Compress enables gzip compression of rotated files. Defaults to true.
+	Compress *bool `yaml:"compress,omitempty"`
+	// MaxFileSize is the maximum size in megabytes of a file before it is rotated.
+	// Defaults to 100.
+	MaxFileSize int32 `yaml:"maxFileSize,omitempty"`
+	// RotationInterval is the maximum duration during which records are written to the same
+	// file before it is rotated, regardless of its size. Time-based rotation is disabled if
+	// this field is empty. Valid time units are "ns", "us" (or "µs"), "ms", "s", "m", "h".
+	// Min value allowed is "1m".
+	RotationInterval string `yaml:"rotationInterval,omitempty"`
+	// MaxBackups is the maximum number of rotated files to retain. Defaults to 10.
+	MaxBackups int32 `yaml:"maxBackups,omitempty"`
+	// MaxAge is the maximum number of days to retain rotated files. Rotated files are not
+	// removed based on their age if this field is 0.
+	MaxAge int32 `yaml:"maxAge,omitempty"`
+	// Filters are the rules used to select the flow records written to files. All flow
+	// records are written if empty.
+	Filters []FlowFilterRule `yaml:"filters,omitempty"`
+}
+
+type FlowMetricsConfig struct {
+	// Enable is the switch to enable Prometheus metrics computed from flow records. The
+	// metrics are served by the Flow Aggregator API server at the /metrics endpoint.
+	Enable bool `yaml:"enable,omitempty"`
+	// Dimensions are the flow record fields used as labels of the metrics. Supported
+	// dimensions are "sourcePodNamespace", "destinationPodNamespace",
+	// "destinationServicePortName", "ingressNetworkPolicyRuleAction",
+	// "egressNetworkPolicyRuleAction" and "flowType". Defaults to "sourcePodNamespace",
+	// "destinationPodNamespace" and "destinationServicePortName".
+	Dimensions []string `yaml:"dimensions,omitempty"`
+	// MaxSeries is the maximum number of label sets for which metrics are maintained. Once
+	// this limit is reached, flow records with a new label set are accounted for in a single
+	// overflow series, whose labels are all set to "_other". Defaults to 10000.
+	MaxSeries int32 `yaml:"maxSeries,omitempty"`
+	// Filters are the rules used to select the flow records accounted for in the metrics. All
+	// flow records are accounted for if empty.
+	Filters []FlowFilterRule `yaml:"filters,omitempty"`
+}
+
+type KafkaSASLConfig struct {
+	// Mechanism is the SASL mechanism used to authenticate to Kafka brokers. Supported
+	// mechanisms are "PLAIN", "SCRAM-SHA-256" and "SCRAM-SHA-512". SASL authentication is
+	// disabled if this field is empty.
+	Mechanism string `yaml:"mechanism,omitempty"`
+}
+
+// FlowFilterRule is a rule used to select the flow records sent to an exporter. The rules
+// of an exporter are evaluated in order, and the first rule matching a flow record decides
+// whether it is exported. Flow records which do not match any rule are exported. A rule
+// matches a flow record if all its non-empty fields match, and a rule with no field set
+// matches all flow records.
+type FlowFilterRule struct {
+	// Action is the action applied to the flow records matching the rule. Supported actions
+	// are "Include" and "Exclude". Defaults to "Include".
+	Action string `yaml:"action,omitempty"`
+	// SampleRatio is the fraction of the flows matching an "Include" rule which are exported,
+	// between 0 and 1. Flows are sampled based on their 5-tuple, so that all the records of a
+	// sampled flow are exported. All matching flow records are exported if it is 0 or omitted.
+	SampleRatio float64 `yaml:"sampleRatio,omitempty"`
+	// Namespaces matches flow records whose source or destination Pod is in one of the
+	// Namespaces.
+	Namespaces []string `yaml:"namespaces,omitempty"`
+	// PodSelector is a label selector (e.g. "app=web,tier in (frontend,backend)") matching
+	// flow records whose source or destination Pod has matching labels. It requires
+	// recordContents.podLabels to be enabled.
+	PodSelector string `yaml:"podSelector,omitempty"`
+	// Services matches flow records whose destination is one of the Services, in the format
+	// "<namespace>/<name>" or "<namespace>/<name>:<port name>".
+	Services []string `yaml:"services,omitempty"`
+	// Ports matches flow records whose destination port or destination Service port is one
+	// of the ports.
+	Ports []int32 `yaml:"ports,omitempty"`
+	// Protocols matches flow records whose protocol is one of the protocols. Supported
+	// protocols are "TCP", "UDP", "SCTP", "ICMP" and "ICMPv6".
+	Protocols []string `yaml:"protocols,omitempty"`
+	// PolicyActions matches flow records whose ingress or egress Antrea-native policy rule
+	// action is one of the actions. Supported actions are "Allow", "Drop" and "Reject".
+	PolicyActions []string `yaml:"policyActions,omitempty"`
+	// FlowTypes matches flow records whose type is one of the types. Supported types are
+	// "IntraNode", "InterNode", "ToExternal" and "FromExternal".
+	FlowTypes []string `yaml:"flowTypes,omitempty"`
 }
@@ -36,9 +36,24 @@ const (
 	DefaultS3RecordFormat                 = "CSV"
 	DefaultS3MaxRecordsPerFile            = 1000000
 	DefaultS3UploadInterval               = "60s"
+	DefaultS3KeyTemplate                  = "records-{{.Random}}.{{.Extension}}"
 	MinS3CommitInterval                   = 1 * time.Second
+	DefaultKafkaEncoding                  = "JSON"
+	DefaultKafkaPartitionBy               = "None"
+	DefaultKafkaMaxBatchSize              = 1000
+	DefaultKafkaFlushInterval             = "1s"
+	MinKafkaFlushInterval                 = 100 * time.Millisecond
+	DefaultKafkaMaxBufferedRecords        = 100000
+	DefaultFileExporterPath               = "/var/log/antrea/flow-aggregator/flows"
+	DefaultFileExporterRecordFormat       = "CSV"
+	DefaultFileExporterMaxFileSize        = 100
+	DefaultFileExporterMaxBackups         = 10
+	MinFileExporterRotationInterval       = 1 * time.Minute
+	DefaultFlowMetricsMaxSeries           = 10000
 )
 
+var DefaultFlowMetricsDimensions = []string{"sourcePodNamespace", "destinationPodNamespace", "destinationServicePortName"}
+
 func SetConfigDefaults(flowAggregatorConf *FlowAggregatorConfig) {
 	if flowAggregatorConf.ActiveFlowRecordTimeout == "" {
 		flowAggregatorConf.ActiveFlowRecordTimeout = DefaultActiveFlowRecordTimeout
@@ -84,4 +99,44 @@ func SetConfigDefaults(flowAggregatorConf *FlowAggregatorConfig) {
 	if flowAggregatorConf.S3Uploader.UploadInterval == "" {
 		flowAggregatorConf.S3Uploader.UploadInterval = DefaultS3UploadInterval
 	}
+	if flowAggregatorConf.S3Uploader.KeyTemplate == "" {
+		flowAggregatorConf.S3Uploader.KeyTemplate = DefaultS3KeyTemplate
+	}
+	if flowAggregatorConf.Kafka.Encoding == "" {
+		flowAggregatorConf.Kafka.Encoding = DefaultKafkaEncoding
+	}
+	if flowAggregatorConf.Kafka.PartitionBy == "" {
+		flowAggregatorConf.Kafka.PartitionBy = DefaultKafkaPartitionBy
+	}
+	if flowAggregatorConf.Kafka.MaxBatchSize == 0 {
+		flowAggregatorConf.Kafka.MaxBatchSize = DefaultKafkaMaxBatchSize
+	}
+	if flowAggregatorConf.Kafka.FlushInterval == "" {
+		flowAggregatorConf.Kafka.FlushInterval = DefaultKafkaFlushInterval
+	}
+	if flowAggregatorConf.Kafka.MaxBufferedRecords == 0 {
+		flowAggregatorConf.Kafka.MaxBufferedRecords = DefaultKafkaMaxBufferedRecords
+	}
+	if flowAggregatorConf.FileExporter.Path == "" {
+		flowAggregatorConf.FileExporter.Path = DefaultFileExporterPath
+	}
+	if flowAggregatorConf.FileExporter.RecordFormat == "" {
+		flowAggregatorConf.FileExporter.RecordFormat = DefaultFileExporterRecordFormat
+	}
+	if flowAggregatorConf.FileExporter.Compress == nil {
+		flowAggregatorConf.FileExporter.Compress = new(bool)
+		*flowAggregatorConf.FileExporter.Compress = true
+	}
+	if flowAggregatorConf.FileExporter.MaxFileSize == 0 {
+		flowAggregatorConf.FileExporter.MaxFileSize = DefaultFileExporterMaxFileSize
+	}
+	if flowAggregatorConf.FileExporter.MaxBackups == 0 {
+		flowAggregatorConf.FileExporter.MaxBackups = DefaultFileExporterMaxBackups
+	}
+	if len(flowAggregatorConf.FlowMetrics.Dimensions) == 0 {
+		flowAggregatorConf.FlowMetrics.Dimensions = append([]string(nil), DefaultFlowMetricsDimensions...)
+	}
+	if flowAggregatorConf.FlowMetrics.MaxSeries == 0 {
+		flowAggregatorConf.FlowMetrics.MaxSeries = DefaultFlowMetricsMaxSeries
+	}
 }
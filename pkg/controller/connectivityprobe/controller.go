@@ -0,0 +1,572 @@
+// Copyright 2022 Antrea Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package connectivityprobe
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"k8s.io/client-go/tools/cache"
+	"k8s.io/client-go/util/workqueue"
+	"k8s.io/klog/v2"
+	"k8s.io/utils/clock"
+
+	crdv1alpha1 "antrea.io/antrea/pkg/apis/crd/v1alpha1"
+	"antrea.io/antrea/pkg/client/clientset/versioned"
+	crdinformers "antrea.io/antrea/pkg/client/informers/externalversions/crd/v1alpha1"
+	crdlisters "antrea.io/antrea/pkg/client/listers/crd/v1alpha1"
+	"antrea.io/antrea/pkg/controller/metrics"
+)
+
+const (
+	controllerName = "ConnectivityProbeController"
+
+	// Set resyncPeriod to 0 to disable resyncing.
+	resyncPeriod time.Duration = 0
+
+	// How long to wait before retrying the processing of a ConnectivityProbe.
+	minRetryDelay = 5 * time.Second
+	maxRetryDelay = 300 * time.Second
+
+	// Default number of workers processing ConnectivityProbes.
+	defaultWorkers = 2
+
+	// ProbeLabelKey is the label set on the Traceflows created for a
+	// ConnectivityProbe, whose value is the name of the ConnectivityProbe.
+	ProbeLabelKey = "connectivityprobe.antrea.io/name"
+
+	// traceflowTimeoutReason is the reason set by the Traceflow controller
+	// when the packet of a Traceflow has not been observed at the
+	// destination in time.
+	traceflowTimeoutReason = "Traceflow timeout"
+
+	// Reasons of the Reachable condition.
+	reasonProbeSucceeded = "ProbeSucceeded"
+	reasonPacketDropped  = "PacketDropped"
+	reasonProbeTimeout   = "ProbeTimeout"
+	reasonProbeError     = "ProbeError"
+	reasonInvalidSpec    = "InvalidSpec"
+)
+
+var (
+	// timeoutGracePeriod is the time given to the Traceflow controller to
+	// complete a Traceflow after the probe timeout has elapsed, before the
+	// probe is completed by this controller. The Traceflow controller
+	// checks the timeout of the running Traceflows every 10 seconds.
+	timeoutGracePeriod = 15 * time.Second
+)
+
+// Controller runs the ConnectivityProbes. For each ConnectivityProbe, it
+// creates a Traceflow at every interval, records the result of the Traceflow
+// in the ConnectivityProbe status and in the Prometheus metrics once it is
+// completed or timed out, and deletes the Traceflow.
+type Controller struct {
+	client                        versioned.Interface
+	connectivityProbeLister       crdlisters.ConnectivityProbeLister
+	connectivityProbeListerSynced cache.InformerSynced
+	traceflowLister               crdlisters.TraceflowLister
+	traceflowListerSynced         cache.InformerSynced
+	queue                         workqueue.RateLimitingInterface
+	clock                         clock.Clock
+
+	// probeStartTimes stores the time at which the Traceflows of the
+	// probes were created, with a better precision than their
+	// CreationTimestamp, to detect the timeout of the probes.
+	probeStartTimesMutex sync.Mutex
+	probeStartTimes      map[string]time.Time
+}
+
+// NewConnectivityProbeController creates a new ConnectivityProbe controller.
+func NewConnectivityProbeController(client versioned.Interface, connectivityProbeInformer crdinformers.ConnectivityProbeInformer, traceflowInformer crdinformers.TraceflowInformer) *Controller {
+	c := &Controller{
+		client:                        client,
+		connectivityProbeLister:       connectivityProbeInformer.Lister(),
+		connectivityProbeListerSynced: connectivityProbeInformer.Informer().HasSynced,
+		traceflowLister:               traceflowInformer.Lister(),
+		traceflowListerSynced:         traceflowInformer.Informer().HasSynced,
+		queue:                         workqueue.NewNamedRateLimitingQueue(workqueue.NewItemExponentialFailureRateLimiter(minRetryDelay, maxRetryDelay), "connectivityProbe"),
+		clock:                         clock.RealClock{},
+		probeStartTimes:               map[string]time.Time{},
+	}
+	connectivityProbeInformer.Informer().AddEventHandlerWithResyncPeriod(
+		cache.ResourceEventHandlerFuncs{
+			AddFunc:    c.addConnectivityProbe,
+			UpdateFunc: c.updateConnectivityProbe,
+			DeleteFunc: c.deleteConnectivityProbe,
+		},
+		resyncPeriod,
+	)
+	traceflowInformer.Informer().AddEventHandlerWithResyncPeriod(
+		cache.ResourceEventHandlerFuncs{
+			UpdateFunc: c.updateTraceflow,
+		},
+		resyncPeriod,
+	)
+	return c
+}
+
+func (c *Controller) addConnectivityProbe(obj interface{}) {
+	probe := obj.(*crdv1alpha1.ConnectivityProbe)
+	klog.V(2).InfoS("Processing ConnectivityProbe ADD event", "name", probe.Name)
+	c.queue.Add(probe.Name)
+}
+
+func (c *Controller) updateConnectivityProbe(oldObj, curObj interface{}) {
+	oldProbe := oldObj.(*crdv1alpha1.ConnectivityProbe)
+	curProbe := curObj.(*crdv1alpha1.ConnectivityProbe)
+	// The status updates of the ConnectivityProbe are made by this
+	// controller, and do not need to be processed.
+	if curProbe.Generation == oldProbe.Generation {
+		return
+	}
+	klog.V(2).InfoS("Processing ConnectivityProbe UPDATE event", "name", curProbe.Name)
+	c.queue.Add(curProbe.Name)
+}
+
+func (c *Controller) deleteConnectivityProbe(old interface{}) {
+	probe, ok := old.(*crdv1alpha1.ConnectivityProbe)
+	if !ok {
+		tombstone, ok := old.(cache.DeletedFinalStateUnknown)
+		if !ok {
+			klog.Errorf("Error decoding object when deleting ConnectivityProbe, invalid type: %v", old)
+			return
+		}
+		probe, ok = tombstone.Obj.(*crdv1alpha1.ConnectivityProbe)
+		if !ok {
+			klog.Errorf("Error decoding object tombstone when deleting ConnectivityProbe, invalid type: %v", tombstone.Obj)
+			return
+		}
+	}
+	klog.V(2).InfoS("Processing ConnectivityProbe DELETE event", "name", probe.Name)
+	c.queue.Add(probe.Name)
+}
+
+func (c *Controller) updateTraceflow(_, curObj interface{}) {
+	tf := curObj.(*crdv1alpha1.Traceflow)
+	probeName, ok := tf.Labels[ProbeLabelKey]
+	if !ok {
+		return
+	}
+	if tf.Status.Phase == crdv1alpha1.Succeeded || tf.Status.Phase == crdv1alpha1.Failed {
+		c.queue.Add(probeName)
+	}
+}
+
+func (c *Controller) Run(stopCh <-chan struct{}) {
+	defer c.queue.ShutDown()
+
+	klog.InfoS("Starting controller", "name", controllerName)
+	defer klog.InfoS("Shutting down controller", "name", controllerName)
+
+	if !cache.WaitForNamedCacheSync(controllerName, stopCh, c.connectivityProbeListerSynced, c.traceflowListerSynced) {
+		return
+	}
+
+	for i := 0; i < defaultWorkers; i++ {
+		go wait.Until(c.worker, time.Second, stopCh)
+	}
+	<-stopCh
+}
+
+func (c *Controller) worker() {
+	for c.processNextWorkItem() {
+	}
+}
+
+func (c *Controller) processNextWorkItem() bool {
+	obj, quit := c.queue.Get()
+	if quit {
+		return false
+	}
+	defer c.queue.Done(obj)
+
+	if key, ok := obj.(string); !ok {
+		c.queue.Forget(obj)
+		klog.Errorf("Expected string in work queue but got %#v", obj)
+	} else if err := c.syncConnectivityProbe(key); err == nil {
+		c.queue.Forget(key)
+	} else {
+		klog.ErrorS(err, "Error syncing ConnectivityProbe", "name", key)
+		c.queue.AddRateLimited(key)
+	}
+	return true
+}
+
+func (c *Controller) syncConnectivityProbe(name string) error {
+	probe, err := c.connectivityProbeLister.Get(name)
+	if err != nil {
+		if apierrors.IsNotFound(err) {
+			return c.cleanupConnectivityProbe(name)
+		}
+		return err
+	}
+	tfs, err := c.listProbeTraceflows(name)
+	if err != nil {
+		return err
+	}
+	now := c.clock.Now()
+	update := probe.DeepCopy()
+	var results []crdv1alpha1.ProbeResult
+	var completedTraceflows []string
+	inFlight := false
+	// requeueAfter is the time after which the ConnectivityProbe must be
+	// processed again, to complete the running probe or to start the next
+	// one.
+	var requeueAfter time.Duration
+
+	for _, tf := range tfs {
+		if !metav1.IsControlledBy(tf, probe) {
+			// The Traceflow was created for a deleted ConnectivityProbe
+			// with the same name, and is garbage collected.
+			continue
+		}
+		startTime := c.getProbeStartTime(tf)
+		result, completed := completeProbe(probe, tf, startTime, now)
+		if !completed {
+			inFlight = true
+			requeueAfter = startTime.Add(getTimeout(probe) + timeoutGracePeriod).Sub(now)
+			continue
+		}
+		completedTraceflows = append(completedTraceflows, tf.Name)
+		if !hasResult(probe, startTime) {
+			results = append(results, *result)
+		}
+	}
+	sort.Slice(results, func(i, j int) bool {
+		return results[i].StartTime.Before(&results[j].StartTime)
+	})
+	for i := range results {
+		recordResult(update, &results[i], now)
+	}
+
+	if err := validateConnectivityProbe(probe); err != nil {
+		// No new probe is started until the spec is fixed.
+		setReachableCondition(update, metav1.ConditionUnknown, reasonInvalidSpec, err.Error(), now)
+	} else if !inFlight {
+		nextProbeTime := now
+		if probe.Status.LastProbeTime != nil {
+			nextProbeTime = probe.Status.LastProbeTime.Add(getInterval(probe))
+		}
+		if !nextProbeTime.After(now) {
+			if err := c.startProbe(probe, now); err != nil {
+				return err
+			}
+			lastProbeTime := metav1.NewTime(now)
+			update.Status.LastProbeTime = &lastProbeTime
+			requeueAfter = getTimeout(probe) + timeoutGracePeriod
+		} else {
+			requeueAfter = nextProbeTime.Sub(now)
+		}
+	}
+
+	if !reflect.DeepEqual(probe.Status, update.Status) {
+		if _, err := c.client.CrdV1alpha1().ConnectivityProbes().UpdateStatus(context.TODO(), update, metav1.UpdateOptions{}); err != nil {
+			return err
+		}
+	}
+	for i := range results {
+		updateMetrics(probe.Name, &results[i])
+	}
+	for _, tfName := range completedTraceflows {
+		if err := c.deleteTraceflow(tfName); err != nil {
+			return err
+		}
+	}
+	if requeueAfter > 0 {
+		c.queue.AddAfter(name, requeueAfter)
+	}
+	return nil
+}
+
+func (c *Controller) listProbeTraceflows(probeName string) ([]*crdv1alpha1.Traceflow, error) {
+	return c.traceflowLister.List(labels.SelectorFromSet(labels.Set{ProbeLabelKey: probeName}))
+}
+
+// startProbe creates the Traceflow of a new probe of the ConnectivityProbe.
+func (c *Controller) startProbe(probe *crdv1alpha1.ConnectivityProbe, now time.Time) error {
+	isController := true
+	tf := &crdv1alpha1.Traceflow{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:   fmt.Sprintf("%s-%d", probe.Name, now.Unix()),
+			Labels: map[string]string{ProbeLabelKey: probe.Name},
+			OwnerReferences: []metav1.OwnerReference{
+				{
+					APIVersion: crdv1alpha1.SchemeGroupVersion.String(),
+					Kind:       "ConnectivityProbe",
+					Name:       probe.Name,
+					UID:        probe.UID,
+					Controller: &isController,
+				},
+			},
+		},
+		Spec: crdv1alpha1.TraceflowSpec{
+			Source:      probe.Spec.Source,
+			Destination: probe.Spec.Destination,
+			Packet:      *probe.Spec.Packet.DeepCopy(),
+			Timeout:     uint16(getTimeout(probe) / time.Second),
+		},
+	}
+	if _, err := c.client.CrdV1alpha1().Traceflows().Create(context.TODO(), tf, metav1.CreateOptions{}); err != nil {
+		if !apierrors.IsAlreadyExists(err) {
+			return fmt.Errorf("error creating Traceflow for ConnectivityProbe %s: %w", probe.Name, err)
+		}
+		// The Traceflow was created in a previous attempt.
+		return nil
+	}
+	klog.V(2).InfoS("Started probe", "connectivityProbe", probe.Name, "traceflow", tf.Name)
+	c.probeStartTimesMutex.Lock()
+	defer c.probeStartTimesMutex.Unlock()
+	c.probeStartTimes[tf.Name] = now
+	return nil
+}
+
+// getProbeStartTime returns the time at which the Traceflow of a probe was
+// created. The CreationTimestamp of the Traceflow is used if the Traceflow was
+// created before the Antrea Controller restarted.
+func (c *Controller) getProbeStartTime(tf *crdv1alpha1.Traceflow) time.Time {
+	c.probeStartTimesMutex.Lock()
+	defer c.probeStartTimesMutex.Unlock()
+	if startTime, ok := c.probeStartTimes[tf.Name]; ok {
+		return startTime
+	}
+	return tf.CreationTimestamp.Time
+}
+
+func (c *Controller) deleteTraceflow(name string) error {
+	if err := c.client.CrdV1alpha1().Traceflows().Delete(context.TODO(), name, metav1.DeleteOptions{}); err != nil && !apierrors.IsNotFound(err) {
+		return fmt.Errorf("error deleting Traceflow %s: %w", name, err)
+	}
+	c.probeStartTimesMutex.Lock()
+	defer c.probeStartTimesMutex.Unlock()
+	delete(c.probeStartTimes, name)
+	return nil
+}
+
+// cleanupConnectivityProbe deletes the Traceflows and the metrics of a deleted
+// ConnectivityProbe. The Traceflows are also garbage collected by Kubernetes
+// as they are owned by the ConnectivityProbe.
+func (c *Controller) cleanupConnectivityProbe(name string) error {
+	tfs, err := c.listProbeTraceflows(name)
+	if err != nil {
+		return err
+	}
+	for _, tf := range tfs {
+		if err := c.deleteTraceflow(tf.Name); err != nil {
+			return err
+		}
+	}
+	for _, result := range []crdv1alpha1.ProbeResultType{crdv1alpha1.ProbeSucceeded, crdv1alpha1.ProbeFailed, crdv1alpha1.ProbeError} {
+		metrics.ConnectivityProbeResults.Delete(map[string]string{"probe": name, "result": string(result)})
+	}
+	metrics.ConnectivityProbeLatency.Delete(map[string]string{"probe": name})
+	metrics.ConnectivityProbeReachable.Delete(map[string]string{"probe": name})
+	return nil
+}
+
+// completeProbe returns the result of the probe run by the provided Traceflow,
+// or false if the Traceflow is still running and the probe has not timed out.
+func completeProbe(probe *crdv1alpha1.ConnectivityProbe, tf *crdv1alpha1.Traceflow, startTime time.Time, now time.Time) (*crdv1alpha1.ProbeResult, bool) {
+	result := &crdv1alpha1.ProbeResult{StartTime: metav1.NewTime(startTime)}
+	if dropPoint := getDropPoint(tf); dropPoint != nil {
+		result.Result = crdv1alpha1.ProbeFailed
+		result.Reason = fmt.Sprintf("Packet was %s on Node %s", strings.ToLower(string(dropPoint.Action)), dropPoint.Node)
+		result.DropPoint = dropPoint
+		return result, true
+	}
+	switch tf.Status.Phase {
+	case crdv1alpha1.Succeeded:
+		result.Result = crdv1alpha1.ProbeSucceeded
+		result.LatencyMilliseconds = getLatency(tf)
+		return result, true
+	case crdv1alpha1.Failed:
+		if tf.Status.Reason == traceflowTimeoutReason {
+			result.Result = crdv1alpha1.ProbeFailed
+			result.Reason = "Packet was not observed at the destination before the timeout"
+		} else {
+			result.Result = crdv1alpha1.ProbeError
+			result.Reason = tf.Status.Reason
+		}
+		return result, true
+	}
+	if now.Before(startTime.Add(getTimeout(probe) + timeoutGracePeriod)) {
+		return nil, false
+	}
+	// The Traceflow controller fails the running Traceflows which time out,
+	// but leaves the Traceflows which cannot be started pending.
+	if tf.Status.Phase == crdv1alpha1.Running {
+		result.Result = crdv1alpha1.ProbeFailed
+		result.Reason = "Packet was not observed at the destination before the timeout"
+	} else {
+		result.Result = crdv1alpha1.ProbeError
+		result.Reason = "Traceflow could not be started before the timeout"
+	}
+	return result, true
+}
+
+// getLatency returns the time in milliseconds from the injection of the
+// Traceflow packet on the sender Node to its delivery, from the timestamps
+// reported by the Antrea Agents, or nil if they were not reported. A negative
+// latency caused by the clock skew between the Nodes is reported as 0.
+func getLatency(tf *crdv1alpha1.Traceflow) *int64 {
+	var injectionTimestamp, deliveryTimestamp int64
+	for _, nodeResult := range tf.Status.Results {
+		if nodeResult.InjectionTimestampMilliseconds != 0 {
+			injectionTimestamp = nodeResult.InjectionTimestampMilliseconds
+		}
+		if nodeResult.DeliveryTimestampMilliseconds != 0 {
+			deliveryTimestamp = nodeResult.DeliveryTimestampMilliseconds
+		}
+	}
+	if injectionTimestamp == 0 || deliveryTimestamp == 0 {
+		return nil
+	}
+	latency := deliveryTimestamp - injectionTimestamp
+	if latency < 0 {
+		latency = 0
+	}
+	return &latency
+}
+
+// getDropPoint returns the observation of the Traceflow in which the packet
+// was dropped or rejected, if any.
+func getDropPoint(tf *crdv1alpha1.Traceflow) *crdv1alpha1.ProbeDropPoint {
+	for _, nodeResult := range tf.Status.Results {
+		for _, ob := range nodeResult.Observations {
+			if ob.Action == crdv1alpha1.ActionDropped || ob.Action == crdv1alpha1.ActionRejected {
+				return &crdv1alpha1.ProbeDropPoint{
+					Node:          nodeResult.Node,
+					Component:     ob.Component,
+					ComponentInfo: ob.ComponentInfo,
+					Action:        ob.Action,
+					NetworkPolicy: ob.NetworkPolicy,
+				}
+			}
+		}
+	}
+	return nil
+}
+
+// hasResult returns whether the result of the probe started at the provided
+// time is already recorded in the status, which happens if deleting its
+// Traceflow failed after the status was updated.
+func hasResult(probe *crdv1alpha1.ConnectivityProbe, startTime time.Time) bool {
+	for _, result := range probe.Status.Results {
+		// The times in the status are serialized with a second precision.
+		if result.StartTime.Unix() == startTime.Unix() {
+			return true
+		}
+	}
+	return false
+}
+
+// recordResult adds the result of a probe to the status of the
+// ConnectivityProbe, and updates the counters and the Reachable condition.
+func recordResult(probe *crdv1alpha1.ConnectivityProbe, result *crdv1alpha1.ProbeResult, now time.Time) {
+	status := &probe.Status
+	status.Results = append([]crdv1alpha1.ProbeResult{*result}, status.Results...)
+	if historyLimit := getHistoryLimit(probe); len(status.Results) > historyLimit {
+		status.Results = status.Results[:historyLimit]
+	}
+	switch result.Result {
+	case crdv1alpha1.ProbeSucceeded:
+		status.SucceededProbes++
+		setReachableCondition(probe, metav1.ConditionTrue, reasonProbeSucceeded, "", now)
+	case crdv1alpha1.ProbeFailed:
+		status.FailedProbes++
+		reason := reasonProbeTimeout
+		if result.DropPoint != nil {
+			reason = reasonPacketDropped
+		}
+		setReachableCondition(probe, metav1.ConditionFalse, reason, result.Reason, now)
+	default:
+		status.FailedProbes++
+		setReachableCondition(probe, metav1.ConditionUnknown, reasonProbeError, result.Reason, now)
+	}
+}
+
+func setReachableCondition(probe *crdv1alpha1.ConnectivityProbe, status metav1.ConditionStatus, reason, message string, now time.Time) {
+	condition := crdv1alpha1.ConnectivityProbeCondition{
+		Type:               crdv1alpha1.ConnectivityProbeReachable,
+		Status:             status,
+		LastTransitionTime: metav1.NewTime(now),
+		Reason:             reason,
+		Message:            message,
+	}
+	for i := range probe.Status.Conditions {
+		existing := &probe.Status.Conditions[i]
+		if existing.Type != condition.Type {
+			continue
+		}
+		if existing.Status == condition.Status {
+			condition.LastTransitionTime = existing.LastTransitionTime
+		}
+		*existing = condition
+		return
+	}
+	probe.Status.Conditions = append(probe.Status.Conditions, condition)
+}
+
+func updateMetrics(probeName string, result *crdv1alpha1.ProbeResult) {
+	metrics.ConnectivityProbeResults.WithLabelValues(probeName, string(result.Result)).Inc()
+	switch result.Result {
+	case crdv1alpha1.ProbeSucceeded:
+		if result.LatencyMilliseconds != nil {
+			metrics.ConnectivityProbeLatency.WithLabelValues(probeName).Observe(float64(*result.LatencyMilliseconds))
+		}
+		metrics.ConnectivityProbeReachable.WithLabelValues(probeName).Set(1)
+	case crdv1alpha1.ProbeFailed:
+		metrics.ConnectivityProbeReachable.WithLabelValues(probeName).Set(0)
+	}
+}
+
+func validateConnectivityProbe(probe *crdv1alpha1.ConnectivityProbe) error {
+	if getTimeout(probe) >= getInterval(probe) {
+		return fmt.Errorf("timeout (%v) must be smaller than interval (%v)", getTimeout(probe), getInterval(probe))
+	}
+	return nil
+}
+
+func getInterval(probe *crdv1alpha1.ConnectivityProbe) time.Duration {
+	interval := probe.Spec.IntervalSeconds
+	if interval == 0 {
+		interval = crdv1alpha1.DefaultConnectivityProbeInterval
+	}
+	return time.Duration(interval) * time.Second
+}
+
+func getTimeout(probe *crdv1alpha1.ConnectivityProbe) time.Duration {
+	timeout := probe.Spec.TimeoutSeconds
+	if timeout == 0 {
+		timeout = crdv1alpha1.DefaultConnectivityProbeTimeout
+	}
+	return time.Duration(timeout) * time.Second
+}
+
+func getHistoryLimit(probe *crdv1alpha1.ConnectivityProbe) int {
+	historyLimit := probe.Spec.HistoryLimit
+	if historyLimit == 0 {
+		historyLimit = crdv1alpha1.DefaultConnectivityProbeHistoryLimit
+	}
+	return int(historyLimit)
+}
@@ -0,0 +1,409 @@
+// Copyright 2022 Antrea Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package connectivityprobe
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	clocktesting "k8s.io/utils/clock/testing"
+
+	crdv1alpha1 "antrea.io/antrea/pkg/apis/crd/v1alpha1"
+	fakeversioned "antrea.io/antrea/pkg/client/clientset/versioned/fake"
+	crdinformers "antrea.io/antrea/pkg/client/informers/externalversions"
+)
+
+var startTime = time.Unix(1660000000, 0)
+
+type fakeController struct {
+	*Controller
+	crdClient          *fakeversioned.Clientset
+	crdInformerFactory crdinformers.SharedInformerFactory
+}
+
+func newFakeController(now time.Time, objects ...runtime.Object) *fakeController {
+	crdClient := fakeversioned.NewSimpleClientset(objects...)
+	crdInformerFactory := crdinformers.NewSharedInformerFactory(crdClient, 0)
+	c := NewConnectivityProbeController(crdClient,
+		crdInformerFactory.Crd().V1alpha1().ConnectivityProbes(),
+		crdInformerFactory.Crd().V1alpha1().Traceflows())
+	c.clock = clocktesting.NewFakeClock(now)
+	return &fakeController{
+		Controller:         c,
+		crdClient:          crdClient,
+		crdInformerFactory: crdInformerFactory,
+	}
+}
+
+func (c *fakeController) start(t *testing.T) {
+	stopCh := make(chan struct{})
+	t.Cleanup(func() { close(stopCh) })
+	c.crdInformerFactory.Start(stopCh)
+	c.crdInformerFactory.WaitForCacheSync(stopCh)
+}
+
+func (c *fakeController) getStatus(t *testing.T, name string) crdv1alpha1.ConnectivityProbeStatus {
+	probe, err := c.crdClient.CrdV1alpha1().ConnectivityProbes().Get(context.TODO(), name, metav1.GetOptions{})
+	require.NoError(t, err)
+	return probe.Status
+}
+
+func (c *fakeController) listTraceflows(t *testing.T) []crdv1alpha1.Traceflow {
+	tfs, err := c.crdClient.CrdV1alpha1().Traceflows().List(context.TODO(), metav1.ListOptions{})
+	require.NoError(t, err)
+	return tfs.Items
+}
+
+func newProbe(intervalSeconds, timeoutSeconds int32, lastProbeTime *time.Time) *crdv1alpha1.ConnectivityProbe {
+	probe := &crdv1alpha1.ConnectivityProbe{
+		ObjectMeta: metav1.ObjectMeta{Name: "probe1", UID: "uid1"},
+		Spec: crdv1alpha1.ConnectivityProbeSpec{
+			Source:          crdv1alpha1.Source{Namespace: "ns1", Pod: "pod1"},
+			Destination:     crdv1alpha1.Destination{Namespace: "ns1", Service: "svc1"},
+			IntervalSeconds: intervalSeconds,
+			TimeoutSeconds:  timeoutSeconds,
+		},
+	}
+	if lastProbeTime != nil {
+		t := metav1.NewTime(*lastProbeTime)
+		probe.Status.LastProbeTime = &t
+	}
+	return probe
+}
+
+func newProbeTraceflow(probe *crdv1alpha1.ConnectivityProbe, status crdv1alpha1.TraceflowStatus) *crdv1alpha1.Traceflow {
+	isController := true
+	return &crdv1alpha1.Traceflow{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:              "probe1-1660000000",
+			Labels:            map[string]string{ProbeLabelKey: probe.Name},
+			CreationTimestamp: metav1.NewTime(startTime),
+			OwnerReferences: []metav1.OwnerReference{
+				{Kind: "ConnectivityProbe", Name: probe.Name, UID: probe.UID, Controller: &isController},
+			},
+		},
+		Status: status,
+	}
+}
+
+func TestStartProbe(t *testing.T) {
+	probe := newProbe(0, 5, nil)
+	c := newFakeController(startTime, probe)
+	c.start(t)
+
+	require.NoError(t, c.syncConnectivityProbe(probe.Name))
+	tfs := c.listTraceflows(t)
+	require.Len(t, tfs, 1)
+	tf := tfs[0]
+	assert.Equal(t, "probe1-1660000000", tf.Name)
+	assert.Equal(t, map[string]string{ProbeLabelKey: probe.Name}, tf.Labels)
+	assert.True(t, metav1.IsControlledBy(&tf, probe))
+	assert.Equal(t, probe.Spec.Source, tf.Spec.Source)
+	assert.Equal(t, probe.Spec.Destination, tf.Spec.Destination)
+	assert.Equal(t, uint16(5), tf.Spec.Timeout)
+	status := c.getStatus(t, probe.Name)
+	require.NotNil(t, status.LastProbeTime)
+	assert.True(t, status.LastProbeTime.Time.Equal(startTime))
+
+	// The next probe is not started until the interval has elapsed.
+	require.NoError(t, c.deleteTraceflow(tf.Name))
+	require.Eventually(t, func() bool {
+		cachedProbe, err := c.connectivityProbeLister.Get(probe.Name)
+		require.NoError(t, err)
+		tfs, err := c.listProbeTraceflows(probe.Name)
+		require.NoError(t, err)
+		return cachedProbe.Status.LastProbeTime != nil && len(tfs) == 0
+	}, time.Second, 10*time.Millisecond)
+	c.clock.(*clocktesting.FakeClock).SetTime(startTime.Add(30 * time.Second))
+	require.NoError(t, c.syncConnectivityProbe(probe.Name))
+	assert.Empty(t, c.listTraceflows(t))
+	c.clock.(*clocktesting.FakeClock).SetTime(startTime.Add(60 * time.Second))
+	require.NoError(t, c.syncConnectivityProbe(probe.Name))
+	assert.Len(t, c.listTraceflows(t), 1)
+}
+
+func TestCompleteProbe(t *testing.T) {
+	for _, tc := range []struct {
+		name              string
+		timeoutSeconds    int32
+		traceflowStatus   crdv1alpha1.TraceflowStatus
+		expectedResult    *crdv1alpha1.ProbeResult
+		expectedCondition *crdv1alpha1.ConnectivityProbeCondition
+	}{
+		{
+			name: "succeeded",
+			traceflowStatus: crdv1alpha1.TraceflowStatus{
+				Phase: crdv1alpha1.Succeeded,
+				Results: []crdv1alpha1.NodeResult{
+					{Node: "node1", InjectionTimestampMilliseconds: 1660041720100, Observations: []crdv1alpha1.Observation{{Component: crdv1alpha1.ComponentSpoofGuard, Action: crdv1alpha1.ActionForwarded}}},
+					{Node: "node2", DeliveryTimestampMilliseconds: 1660041720103, Observations: []crdv1alpha1.Observation{{Component: crdv1alpha1.ComponentForwarding, Action: crdv1alpha1.ActionDelivered}}},
+				},
+			},
+			// The latency is computed from the timestamps reported by
+			// the Agents.
+			expectedResult: &crdv1alpha1.ProbeResult{
+				Result:              crdv1alpha1.ProbeSucceeded,
+				LatencyMilliseconds: int64Ptr(3),
+			},
+			expectedCondition: &crdv1alpha1.ConnectivityProbeCondition{
+				Status: metav1.ConditionTrue,
+				Reason: reasonProbeSucceeded,
+			},
+		},
+		{
+			name: "succeeded with clock skew",
+			traceflowStatus: crdv1alpha1.TraceflowStatus{
+				Phase: crdv1alpha1.Succeeded,
+				Results: []crdv1alpha1.NodeResult{
+					{Node: "node1", InjectionTimestampMilliseconds: 1660041720100, Observations: []crdv1alpha1.Observation{{Component: crdv1alpha1.ComponentSpoofGuard, Action: crdv1alpha1.ActionForwarded}}},
+					{Node: "node2", DeliveryTimestampMilliseconds: 1660041720090, Observations: []crdv1alpha1.Observation{{Component: crdv1alpha1.ComponentForwarding, Action: crdv1alpha1.ActionDelivered}}},
+				},
+			},
+			expectedResult: &crdv1alpha1.ProbeResult{
+				Result:              crdv1alpha1.ProbeSucceeded,
+				LatencyMilliseconds: int64Ptr(0),
+			},
+			expectedCondition: &crdv1alpha1.ConnectivityProbeCondition{
+				Status: metav1.ConditionTrue,
+				Reason: reasonProbeSucceeded,
+			},
+		},
+		{
+			name: "succeeded without timestamps",
+			traceflowStatus: crdv1alpha1.TraceflowStatus{
+				Phase: crdv1alpha1.Succeeded,
+				Results: []crdv1alpha1.NodeResult{
+					{Node: "node1", Observations: []crdv1alpha1.Observation{{Component: crdv1alpha1.ComponentSpoofGuard, Action: crdv1alpha1.ActionForwarded}}},
+					{Node: "node2", Observations: []crdv1alpha1.Observation{{Component: crdv1alpha1.ComponentForwarding, Action: crdv1alpha1.ActionDelivered}}},
+				},
+			},
+			expectedResult: &crdv1alpha1.ProbeResult{
+				Result: crdv1alpha1.ProbeSucceeded,
+			},
+			expectedCondition: &crdv1alpha1.ConnectivityProbeCondition{
+				Status: metav1.ConditionTrue,
+				Reason: reasonProbeSucceeded,
+			},
+		},
+		{
+			name: "dropped",
+			traceflowStatus: crdv1alpha1.TraceflowStatus{
+				Phase: crdv1alpha1.Succeeded,
+				Results: []crdv1alpha1.NodeResult{
+					{Node: "node1", Observations: []crdv1alpha1.Observation{
+						{Component: crdv1alpha1.ComponentSpoofGuard, Action: crdv1alpha1.ActionForwarded},
+						{Component: crdv1alpha1.ComponentNetworkPolicy, ComponentInfo: "EgressMetric", Action: crdv1alpha1.ActionDropped, NetworkPolicy: "AntreaNetworkPolicy:ns1/deny"},
+					}},
+				},
+			},
+			expectedResult: &crdv1alpha1.ProbeResult{
+				Result: crdv1alpha1.ProbeFailed,
+				Reason: "Packet was dropped on Node node1",
+				DropPoint: &crdv1alpha1.ProbeDropPoint{
+					Node:          "node1",
+					Component:     crdv1alpha1.ComponentNetworkPolicy,
+					ComponentInfo: "EgressMetric",
+					Action:        crdv1alpha1.ActionDropped,
+					NetworkPolicy: "AntreaNetworkPolicy:ns1/deny",
+				},
+			},
+			expectedCondition: &crdv1alpha1.ConnectivityProbeCondition{
+				Status:  metav1.ConditionFalse,
+				Reason:  reasonPacketDropped,
+				Message: "Packet was dropped on Node node1",
+			},
+		},
+		{
+			name: "Traceflow timeout",
+			traceflowStatus: crdv1alpha1.TraceflowStatus{
+				Phase:  crdv1alpha1.Failed,
+				Reason: traceflowTimeoutReason,
+			},
+			expectedResult: &crdv1alpha1.ProbeResult{
+				Result: crdv1alpha1.ProbeFailed,
+				Reason: "Packet was not observed at the destination before the timeout",
+			},
+			expectedCondition: &crdv1alpha1.ConnectivityProbeCondition{
+				Status:  metav1.ConditionFalse,
+				Reason:  reasonProbeTimeout,
+				Message: "Packet was not observed at the destination before the timeout",
+			},
+		},
+		{
+			name: "invalid Traceflow",
+			traceflowStatus: crdv1alpha1.TraceflowStatus{
+				Phase:  crdv1alpha1.Failed,
+				Reason: "Invalid Traceflow request, err: requested source Pod ns1/pod1 not found",
+			},
+			expectedResult: &crdv1alpha1.ProbeResult{
+				Result: crdv1alpha1.ProbeError,
+				Reason: "Invalid Traceflow request, err: requested source Pod ns1/pod1 not found",
+			},
+			expectedCondition: &crdv1alpha1.ConnectivityProbeCondition{
+				Status:  metav1.ConditionUnknown,
+				Reason:  reasonProbeError,
+				Message: "Invalid Traceflow request, err: requested source Pod ns1/pod1 not found",
+			},
+		},
+		{
+			name: "running Traceflow timed out",
+			traceflowStatus: crdv1alpha1.TraceflowStatus{
+				Phase: crdv1alpha1.Running,
+			},
+			expectedResult: &crdv1alpha1.ProbeResult{
+				Result: crdv1alpha1.ProbeFailed,
+				Reason: "Packet was not observed at the destination before the timeout",
+			},
+			expectedCondition: &crdv1alpha1.ConnectivityProbeCondition{
+				Status:  metav1.ConditionFalse,
+				Reason:  reasonProbeTimeout,
+				Message: "Packet was not observed at the destination before the timeout",
+			},
+		},
+		{
+			name:            "pending Traceflow timed out",
+			traceflowStatus: crdv1alpha1.TraceflowStatus{},
+			expectedResult: &crdv1alpha1.ProbeResult{
+				Result: crdv1alpha1.ProbeError,
+				Reason: "Traceflow could not be started before the timeout",
+			},
+			expectedCondition: &crdv1alpha1.ConnectivityProbeCondition{
+				Status:  metav1.ConditionUnknown,
+				Reason:  reasonProbeError,
+				Message: "Traceflow could not be started before the timeout",
+			},
+		},
+		{
+			name:           "running Traceflow not timed out",
+			timeoutSeconds: 40,
+			traceflowStatus: crdv1alpha1.TraceflowStatus{
+				Phase: crdv1alpha1.Running,
+			},
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			probe := newProbe(60, tc.timeoutSeconds, &startTime)
+			tf := newProbeTraceflow(probe, tc.traceflowStatus)
+			c := newFakeController(startTime.Add(30*time.Second), probe, tf)
+			c.start(t)
+
+			require.NoError(t, c.syncConnectivityProbe(probe.Name))
+			status := c.getStatus(t, probe.Name)
+			if tc.expectedResult == nil {
+				assert.Empty(t, status.Results)
+				assert.Empty(t, status.Conditions)
+				assert.Len(t, c.listTraceflows(t), 1)
+				return
+			}
+			tc.expectedResult.StartTime = metav1.NewTime(startTime)
+			assert.Equal(t, []crdv1alpha1.ProbeResult{*tc.expectedResult}, status.Results)
+			if tc.expectedResult.Result == crdv1alpha1.ProbeSucceeded {
+				assert.Equal(t, int64(1), status.SucceededProbes)
+				assert.Equal(t, int64(0), status.FailedProbes)
+			} else {
+				assert.Equal(t, int64(0), status.SucceededProbes)
+				assert.Equal(t, int64(1), status.FailedProbes)
+			}
+			tc.expectedCondition.Type = crdv1alpha1.ConnectivityProbeReachable
+			tc.expectedCondition.LastTransitionTime = metav1.NewTime(startTime.Add(30 * time.Second))
+			assert.Equal(t, []crdv1alpha1.ConnectivityProbeCondition{*tc.expectedCondition}, status.Conditions)
+			// The Traceflow is deleted, and the next probe is not started
+			// until the interval has elapsed.
+			assert.Empty(t, c.listTraceflows(t))
+		})
+	}
+}
+
+func TestRecordResult(t *testing.T) {
+	probe := newProbe(0, 0, &startTime)
+	probe.Spec.HistoryLimit = 2
+	probe.Status.Results = []crdv1alpha1.ProbeResult{
+		{StartTime: metav1.NewTime(startTime.Add(-60 * time.Second)), Result: crdv1alpha1.ProbeFailed},
+		{StartTime: metav1.NewTime(startTime.Add(-120 * time.Second)), Result: crdv1alpha1.ProbeFailed},
+	}
+	probe.Status.FailedProbes = 2
+	transitionTime := metav1.NewTime(startTime.Add(-60 * time.Second))
+	probe.Status.Conditions = []crdv1alpha1.ConnectivityProbeCondition{
+		{Type: crdv1alpha1.ConnectivityProbeReachable, Status: metav1.ConditionFalse, LastTransitionTime: transitionTime, Reason: reasonProbeTimeout},
+	}
+
+	now := startTime.Add(time.Second)
+	recordResult(probe, &crdv1alpha1.ProbeResult{StartTime: metav1.NewTime(startTime), Result: crdv1alpha1.ProbeFailed, Reason: "timeout"}, now)
+	// The oldest result is dropped.
+	require.Len(t, probe.Status.Results, 2)
+	assert.Equal(t, metav1.NewTime(startTime), probe.Status.Results[0].StartTime)
+	assert.Equal(t, metav1.NewTime(startTime.Add(-60*time.Second)), probe.Status.Results[1].StartTime)
+	assert.Equal(t, int64(3), probe.Status.FailedProbes)
+	// The status of the condition has not changed.
+	assert.Equal(t, transitionTime, probe.Status.Conditions[0].LastTransitionTime)
+	assert.Equal(t, "timeout", probe.Status.Conditions[0].Message)
+
+	recordResult(probe, &crdv1alpha1.ProbeResult{StartTime: metav1.NewTime(startTime.Add(60 * time.Second)), Result: crdv1alpha1.ProbeSucceeded}, now)
+	assert.Equal(t, int64(1), probe.Status.SucceededProbes)
+	assert.Equal(t, metav1.ConditionTrue, probe.Status.Conditions[0].Status)
+	assert.Equal(t, metav1.NewTime(now), probe.Status.Conditions[0].LastTransitionTime)
+}
+
+func TestResultAlreadyRecorded(t *testing.T) {
+	probe := newProbe(60, 10, &startTime)
+	probe.Status.Results = []crdv1alpha1.ProbeResult{{StartTime: metav1.NewTime(startTime), Result: crdv1alpha1.ProbeSucceeded}}
+	probe.Status.SucceededProbes = 1
+	tf := newProbeTraceflow(probe, crdv1alpha1.TraceflowStatus{Phase: crdv1alpha1.Succeeded})
+	c := newFakeController(startTime.Add(30*time.Second), probe, tf)
+	c.start(t)
+
+	// The Traceflow whose result is already recorded is deleted.
+	require.NoError(t, c.syncConnectivityProbe(probe.Name))
+	status := c.getStatus(t, probe.Name)
+	assert.Len(t, status.Results, 1)
+	assert.Equal(t, int64(1), status.SucceededProbes)
+	assert.Empty(t, c.listTraceflows(t))
+}
+
+func TestInvalidConnectivityProbe(t *testing.T) {
+	probe := newProbe(10, 10, nil)
+	c := newFakeController(startTime, probe)
+	c.start(t)
+
+	require.NoError(t, c.syncConnectivityProbe(probe.Name))
+	status := c.getStatus(t, probe.Name)
+	require.Len(t, status.Conditions, 1)
+	assert.Equal(t, metav1.ConditionUnknown, status.Conditions[0].Status)
+	assert.Equal(t, reasonInvalidSpec, status.Conditions[0].Reason)
+	assert.Equal(t, "timeout (10s) must be smaller than interval (10s)", status.Conditions[0].Message)
+	assert.Nil(t, status.LastProbeTime)
+	assert.Empty(t, c.listTraceflows(t))
+}
+
+func TestCleanupConnectivityProbe(t *testing.T) {
+	probe := newProbe(60, 10, &startTime)
+	tf := newProbeTraceflow(probe, crdv1alpha1.TraceflowStatus{Phase: crdv1alpha1.Running})
+	c := newFakeController(startTime, tf)
+	c.start(t)
+
+	require.NoError(t, c.syncConnectivityProbe(probe.Name))
+	_, err := c.crdClient.CrdV1alpha1().Traceflows().Get(context.TODO(), tf.Name, metav1.GetOptions{})
+	assert.True(t, apierrors.IsNotFound(err))
+}
+
+func int64Ptr(i int64) *int64 {
+	return &i
+}
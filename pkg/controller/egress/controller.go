@@ -24,12 +24,14 @@ import (
 	"time"
 
 	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/labels"
 	"k8s.io/apimachinery/pkg/types"
 	"k8s.io/apimachinery/pkg/util/sets"
 	"k8s.io/apimachinery/pkg/util/wait"
 	"k8s.io/client-go/tools/cache"
+	"k8s.io/client-go/util/retry"
 	"k8s.io/client-go/util/workqueue"
 	"k8s.io/klog/v2"
 
@@ -42,6 +44,7 @@ import (
 	"antrea.io/antrea/pkg/controller/externalippool"
 	"antrea.io/antrea/pkg/controller/grouping"
 	antreatypes "antrea.io/antrea/pkg/controller/types"
+	utilegress "antrea.io/antrea/pkg/util/egress"
 )
 
 const (
@@ -238,6 +241,7 @@ func (c *EgressController) syncEgressIP(egress *egressv1alpha2.Egress) (net.IP,
 	if exists {
 		// The EgressIP and the ExternalIPPool don't change, do nothing.
 		if prevIP.String() == egress.Spec.EgressIP && prevIPPool == egress.Spec.ExternalIPPool && c.externalIPAllocator.IPPoolExists(egress.Spec.ExternalIPPool) {
+			c.setIPAllocated(egress, prevIP)
 			return prevIP, nil
 		}
 		// Either EgressIP or ExternalIPPool changes, release the previous one first.
@@ -254,11 +258,14 @@ func (c *EgressController) syncEgressIP(egress *egressv1alpha2.Egress) (net.IP,
 	if !c.externalIPAllocator.IPPoolExists(egress.Spec.ExternalIPPool) {
 		// The IP pool has been deleted, reclaim the IP from the Egress API.
 		if egress.Spec.EgressIP != "" {
-			if err := c.updateEgressIP(egress, ""); err != nil {
+			var err error
+			if egress, err = c.updateEgressIP(egress, ""); err != nil {
 				return nil, err
 			}
 		}
-		return nil, fmt.Errorf("ExternalIPPool %s not exists", egress.Spec.ExternalIPPool)
+		err := fmt.Errorf("ExternalIPPool %s not exists", egress.Spec.ExternalIPPool)
+		c.setIPNotAllocated(egress, "ExternalIPPoolNotFound", err)
+		return nil, err
 	}
 
 	var ip net.IP
@@ -268,15 +275,17 @@ func (c *EgressController) syncEgressIP(egress *egressv1alpha2.Egress) (net.IP,
 	if egress.Spec.EgressIP != "" {
 		ip = net.ParseIP(egress.Spec.EgressIP)
 		if err := c.externalIPAllocator.UpdateIPAllocation(egress.Spec.ExternalIPPool, ip); err != nil {
+			c.setIPNotAllocated(egress, "AllocationFailed", err)
 			return nil, fmt.Errorf("error when allocating IP %v for Egress %s from ExternalIPPool %s: %v", ip, egress.Name, egress.Spec.ExternalIPPool, err)
 		}
 	} else {
 		var err error
 		// User doesn't specify the Egress IP, allocate one.
 		if ip, err = c.externalIPAllocator.AllocateIPFromPool(egress.Spec.ExternalIPPool); err != nil {
+			c.setIPNotAllocated(egress, "AllocationFailed", err)
 			return nil, err
 		}
-		if err = c.updateEgressIP(egress, ip.String()); err != nil {
+		if egress, err = c.updateEgressIP(egress, ip.String()); err != nil {
 			if rerr := c.externalIPAllocator.ReleaseIP(egress.Spec.ExternalIPPool, ip); rerr != nil &&
 				rerr != externalippool.ErrExternalIPPoolNotFound {
 				klog.ErrorS(rerr, "Failed to release IP", "ip", ip, "pool", egress.Spec.ExternalIPPool)
@@ -286,11 +295,12 @@ func (c *EgressController) syncEgressIP(egress *egressv1alpha2.Egress) (net.IP,
 	}
 	c.setIPAllocation(egress.Name, ip, egress.Spec.ExternalIPPool)
 	klog.InfoS("Allocated EgressIP", "egress", egress.Name, "ip", ip, "pool", egress.Spec.ExternalIPPool)
+	c.setIPAllocated(egress, ip)
 	return ip, nil
 }
 
-// updateEgressIP updates the Egress's EgressIP in Kubernetes API.
-func (c *EgressController) updateEgressIP(egress *egressv1alpha2.Egress, ip string) error {
+// updateEgressIP updates the Egress's EgressIP in Kubernetes API and returns the updated Egress.
+func (c *EgressController) updateEgressIP(egress *egressv1alpha2.Egress, ip string) (*egressv1alpha2.Egress, error) {
 	var egressIPPtr *string
 	if len(ip) > 0 {
 		egressIPPtr = &ip
@@ -301,8 +311,56 @@ func (c *EgressController) updateEgressIP(egress *egressv1alpha2.Egress, ip stri
 		},
 	}
 	patchBytes, _ := json.Marshal(patch)
-	if _, err := c.crdClient.CrdV1alpha2().Egresses().Patch(context.TODO(), egress.Name, types.MergePatchType, patchBytes, metav1.PatchOptions{}); err != nil {
-		return fmt.Errorf("error when updating EgressIP for Egress %s: %v", egress.Name, err)
+	updatedEgress, err := c.crdClient.CrdV1alpha2().Egresses().Patch(context.TODO(), egress.Name, types.MergePatchType, patchBytes, metav1.PatchOptions{})
+	if err != nil {
+		return egress, fmt.Errorf("error when updating EgressIP for Egress %s: %v", egress.Name, err)
+	}
+	return updatedEgress, nil
+}
+
+// setIPAllocated sets the IPAllocated condition of the Egress to true. The condition only reports the allocation, so
+// the error of updating it is only logged and doesn't prevent the Egress from being realized.
+func (c *EgressController) setIPAllocated(egress *egressv1alpha2.Egress, ip net.IP) {
+	if err := c.updateIPAllocatedCondition(egress, metav1.ConditionTrue, "Allocated",
+		fmt.Sprintf("EgressIP %s is allocated from ExternalIPPool %s", ip, egress.Spec.ExternalIPPool)); err != nil {
+		klog.ErrorS(err, "Failed to update Egress status", "egress", egress.Name)
+	}
+}
+
+// setIPNotAllocated sets the IPAllocated condition of the Egress to false. As it's called when the allocation fails,
+// which will be retried anyway, the error of updating the condition is only logged.
+func (c *EgressController) setIPNotAllocated(egress *egressv1alpha2.Egress, reason string, allocationErr error) {
+	if err := c.updateIPAllocatedCondition(egress, metav1.ConditionFalse, reason, allocationErr.Error()); err != nil {
+		klog.ErrorS(err, "Failed to update Egress status", "egress", egress.Name)
+	}
+}
+
+// updateIPAllocatedCondition updates the IPAllocated condition of the Egress in Kubernetes API if it changes.
+func (c *EgressController) updateIPAllocatedCondition(egress *egressv1alpha2.Egress, status metav1.ConditionStatus, reason, message string) error {
+	condition := egressv1alpha2.EgressCondition{
+		Type:               egressv1alpha2.IPAllocated,
+		Status:             status,
+		LastTransitionTime: metav1.Now(),
+		Reason:             reason,
+		Message:            message,
+	}
+	toUpdate := egress.DeepCopy()
+	var updateErr, getErr error
+	if err := retry.RetryOnConflict(retry.DefaultRetry, func() error {
+		var changed bool
+		if toUpdate.Status.Conditions, changed = utilegress.SetCondition(toUpdate.Status.Conditions, condition); !changed {
+			return nil
+		}
+		_, updateErr = c.crdClient.CrdV1alpha2().Egresses().UpdateStatus(context.TODO(), toUpdate, metav1.UpdateOptions{})
+		if updateErr != nil && errors.IsConflict(updateErr) {
+			if toUpdate, getErr = c.crdClient.CrdV1alpha2().Egresses().Get(context.TODO(), egress.Name, metav1.GetOptions{}); getErr != nil {
+				return getErr
+			}
+		}
+		// Return the error from UPDATE.
+		return updateErr
+	}); err != nil {
+		return fmt.Errorf("error when updating IPAllocated condition for Egress %s: %v", egress.Name, err)
 	}
 	return nil
 }
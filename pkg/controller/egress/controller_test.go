@@ -24,6 +24,7 @@ import (
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 	v1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/fields"
 	"k8s.io/apimachinery/pkg/runtime"
@@ -32,6 +33,7 @@ import (
 	"k8s.io/client-go/informers"
 	"k8s.io/client-go/kubernetes"
 	"k8s.io/client-go/kubernetes/fake"
+	k8stesting "k8s.io/client-go/testing"
 	"k8s.io/client-go/tools/cache"
 
 	"antrea.io/antrea/pkg/apis/controlplane"
@@ -43,6 +45,7 @@ import (
 	"antrea.io/antrea/pkg/controller/egress/store"
 	"antrea.io/antrea/pkg/controller/externalippool"
 	"antrea.io/antrea/pkg/controller/grouping"
+	utilegress "antrea.io/antrea/pkg/util/egress"
 )
 
 var (
@@ -504,6 +507,7 @@ func TestSyncEgressIP(t *testing.T) {
 		inputEgress                *v1alpha2.Egress
 		expectedEgressIP           string
 		expectedExternalIPPoolUsed int
+		expectedIPAllocated        metav1.ConditionStatus
 		expectErr                  bool
 	}{
 		{
@@ -536,6 +540,7 @@ func TestSyncEgressIP(t *testing.T) {
 			},
 			expectedEgressIP:           "1.1.2.10",
 			expectedExternalIPPoolUsed: 3,
+			expectedIPAllocated:        metav1.ConditionTrue,
 			expectErr:                  false,
 		},
 		{
@@ -550,6 +555,7 @@ func TestSyncEgressIP(t *testing.T) {
 			},
 			expectedEgressIP:           "",
 			expectedExternalIPPoolUsed: 0,
+			expectedIPAllocated:        metav1.ConditionFalse,
 			expectErr:                  true,
 		},
 		{
@@ -563,6 +569,7 @@ func TestSyncEgressIP(t *testing.T) {
 			},
 			expectedEgressIP:           "2021:2::aaa1",
 			expectedExternalIPPoolUsed: 1,
+			expectedIPAllocated:        metav1.ConditionTrue,
 			expectErr:                  false,
 		},
 		{
@@ -577,6 +584,7 @@ func TestSyncEgressIP(t *testing.T) {
 			},
 			expectedEgressIP:           "1.1.1.2",
 			expectedExternalIPPoolUsed: 1,
+			expectedIPAllocated:        metav1.ConditionTrue,
 			expectErr:                  false,
 		},
 		{
@@ -591,6 +599,7 @@ func TestSyncEgressIP(t *testing.T) {
 			},
 			expectedEgressIP:           "",
 			expectedExternalIPPoolUsed: 0,
+			expectedIPAllocated:        metav1.ConditionFalse,
 			expectErr:                  true,
 		},
 		{
@@ -614,6 +623,7 @@ func TestSyncEgressIP(t *testing.T) {
 			},
 			expectedEgressIP:           "1.1.1.3",
 			expectedExternalIPPoolUsed: 1,
+			expectedIPAllocated:        metav1.ConditionTrue,
 			expectErr:                  false,
 		},
 		{
@@ -637,6 +647,7 @@ func TestSyncEgressIP(t *testing.T) {
 			},
 			expectedEgressIP:           "1.1.1.2",
 			expectedExternalIPPoolUsed: 1,
+			expectedIPAllocated:        metav1.ConditionTrue,
 			expectErr:                  false,
 		},
 		{
@@ -660,6 +671,7 @@ func TestSyncEgressIP(t *testing.T) {
 			},
 			expectedEgressIP:           "",
 			expectedExternalIPPoolUsed: 1,
+			expectedIPAllocated:        metav1.ConditionFalse,
 			expectErr:                  true,
 		},
 		{
@@ -706,11 +718,49 @@ func TestSyncEgressIP(t *testing.T) {
 				assert.NoError(t, err)
 			}
 			assert.Equal(t, net.ParseIP(tt.expectedEgressIP), gotEgressIP)
+			gotEgress, err := controller.crdClient.CrdV1alpha2().Egresses().Get(context.TODO(), tt.inputEgress.Name, metav1.GetOptions{})
+			require.NoError(t, err)
+			if condition := utilegress.GetCondition(gotEgress.Status.Conditions, v1alpha2.IPAllocated); tt.expectedIPAllocated == "" {
+				assert.Nil(t, condition)
+			} else if assert.NotNil(t, condition) {
+				assert.Equal(t, tt.expectedIPAllocated, condition.Status)
+			}
 			checkExternalIPPoolUsed(t, controller, tt.existingExternalIPPool.Name, tt.expectedExternalIPPoolUsed)
 		})
 	}
 }
 
+func TestSyncEgressIPStatusUpdateFailure(t *testing.T) {
+	stopCh := make(chan struct{})
+	defer close(stopCh)
+	egress := newEgress("egressA", "", "ipPoolA", nil, nil)
+	pool := newExternalIPPool("ipPoolA", "1.1.1.0/30", "", "")
+	controller := newController(nil, []runtime.Object{egress, pool})
+	// Updating the status of Egresses is forbidden, the allocation should still succeed.
+	controller.crdClient.(*fakeversioned.Clientset).PrependReactor("update", "egresses", func(action k8stesting.Action) (bool, runtime.Object, error) {
+		if action.GetSubresource() != "status" {
+			return false, nil, nil
+		}
+		return true, nil, apierrors.NewForbidden(v1alpha2.Resource("egresses"), egress.Name, fmt.Errorf("no permission"))
+	})
+	controller.informerFactory.Start(stopCh)
+	controller.crdInformerFactory.Start(stopCh)
+	controller.informerFactory.WaitForCacheSync(stopCh)
+	controller.crdInformerFactory.WaitForCacheSync(stopCh)
+	go controller.externalIPAllocator.Run(stopCh)
+	require.True(t, cache.WaitForCacheSync(stopCh, controller.externalIPAllocator.HasSynced))
+	controller.restoreIPAllocations(nil)
+
+	gotEgressIP, err := controller.syncEgressIP(egress)
+	require.NoError(t, err)
+	assert.Equal(t, net.ParseIP("1.1.1.1"), gotEgressIP)
+	gotEgress, err := controller.crdClient.CrdV1alpha2().Egresses().Get(context.TODO(), egress.Name, metav1.GetOptions{})
+	require.NoError(t, err)
+	assert.Equal(t, "1.1.1.1", gotEgress.Spec.EgressIP)
+	assert.Nil(t, utilegress.GetCondition(gotEgress.Status.Conditions, v1alpha2.IPAllocated))
+	checkExternalIPPoolUsed(t, controller, pool.Name, 1)
+}
+
 func checkExternalIPPoolUsed(t *testing.T, controller *egressController, poolName string, used int) {
 	exists := controller.externalIPAllocator.IPPoolExists(poolName)
 	require.True(t, exists)
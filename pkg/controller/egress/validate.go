@@ -20,6 +20,7 @@ import (
 	"net"
 
 	admv1 "k8s.io/api/admission/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/klog/v2"
 
@@ -47,6 +48,11 @@ func (c *EgressController) ValidateEgress(review *admv1.AdmissionReview) *admv1.
 	}
 
 	shouldAllow := func(oldEgress, newEgress *crdv1alpha2.Egress) (bool, string) {
+		if newEgress.Spec.Bandwidth != nil {
+			if valid, msg := validateBandwidth(newEgress.Spec.Bandwidth); !valid {
+				return false, msg
+			}
+		}
 		// Allow it if EgressIP and ExternalIPPool don't change.
 		if newEgress.Spec.EgressIP == oldEgress.Spec.EgressIP && newEgress.Spec.ExternalIPPool == oldEgress.Spec.ExternalIPPool {
 			return true, ""
@@ -92,6 +98,27 @@ func (c *EgressController) ValidateEgress(review *admv1.AdmissionReview) *admv1.
 	}
 }
 
+// validateBandwidth checks whether the rate and the burst of the Bandwidth are positive quantities.
+func validateBandwidth(bandwidth *crdv1alpha2.Bandwidth) (bool, string) {
+	validate := func(field, value string) (bool, string) {
+		quantity, err := resource.ParseQuantity(value)
+		if err != nil {
+			return false, fmt.Sprintf("Bandwidth %s %s is not valid: %v", field, value, err)
+		}
+		if quantity.Sign() <= 0 {
+			return false, fmt.Sprintf("Bandwidth %s %s must be positive", field, value)
+		}
+		return true, ""
+	}
+	if valid, msg := validate("rate", bandwidth.Rate); !valid {
+		return false, msg
+	}
+	if bandwidth.Burst != "" {
+		return validate("burst", bandwidth.Burst)
+	}
+	return true, ""
+}
+
 func newAdmissionResponseForErr(err error) *admv1.AdmissionResponse {
 	return &admv1.AdmissionResponse{
 		Result: &metav1.Status{
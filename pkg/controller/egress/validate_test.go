@@ -120,6 +120,40 @@ func TestEgressControllerValidateEgress(t *testing.T) {
 			},
 			expectedResponse: &admv1.AdmissionResponse{Allowed: true},
 		},
+		{
+			name:                   "Requesting invalid bandwidth should not be allowed",
+			existingExternalIPPool: newExternalIPPool("bar", "10.10.10.0/24", "", ""),
+			request: &admv1.AdmissionRequest{
+				Name:      "foo",
+				Operation: "CREATE",
+				Object: runtime.RawExtension{Raw: marshal(func() *crdv1alpha2.Egress {
+					egress := newEgress("foo", "10.10.10.1", "bar", nil, nil)
+					egress.Spec.Bandwidth = &crdv1alpha2.Bandwidth{Rate: "10M", Burst: "0"}
+					return egress
+				}())},
+			},
+			expectedResponse: &admv1.AdmissionResponse{
+				Allowed: false,
+				Result: &metav1.Status{
+					Message: "Bandwidth burst 0 must be positive",
+				},
+			},
+		},
+		{
+			name:                   "Updating bandwidth should be allowed",
+			existingExternalIPPool: newExternalIPPool("bar", "10.10.10.0/24", "", ""),
+			request: &admv1.AdmissionRequest{
+				Name:      "foo",
+				Operation: "UPDATE",
+				OldObject: runtime.RawExtension{Raw: marshal(newEgress("foo", "10.10.10.1", "bar", nil, nil))},
+				Object: runtime.RawExtension{Raw: marshal(func() *crdv1alpha2.Egress {
+					egress := newEgress("foo", "10.10.10.1", "bar", nil, nil)
+					egress.Spec.Bandwidth = &crdv1alpha2.Bandwidth{Rate: "100M", Burst: "200M"}
+					return egress
+				}())},
+			},
+			expectedResponse: &admv1.AdmissionResponse{Allowed: true},
+		},
 		{
 			name: "DELETE operation should be allowed",
 			request: &admv1.AdmissionRequest{
@@ -117,6 +117,27 @@ var (
 		Help:           "The total number of actual status updates performed for Antrea ClusterNetworkPolicy Custom Resources",
 		StabilityLevel: metrics.ALPHA,
 	})
+	ConnectivityProbeResults = metrics.NewCounterVec(&metrics.CounterOpts{
+		Namespace:      metricNamespaceAntrea,
+		Subsystem:      metricSubsystemController,
+		Name:           "connectivity_probe_results",
+		Help:           "The total number of ConnectivityProbe results, partitioned by ConnectivityProbe and result (Succeeded, Failed and Error).",
+		StabilityLevel: metrics.ALPHA,
+	}, []string{"probe", "result"})
+	ConnectivityProbeLatency = metrics.NewHistogramVec(&metrics.HistogramOpts{
+		Namespace:      metricNamespaceAntrea,
+		Subsystem:      metricSubsystemController,
+		Name:           "connectivity_probe_latency_milliseconds",
+		Help:           "The time from the injection of the probe packet to its delivery for the succeeded probes of a ConnectivityProbe, partitioned by ConnectivityProbe.",
+		StabilityLevel: metrics.ALPHA,
+	}, []string{"probe"})
+	ConnectivityProbeReachable = metrics.NewGaugeVec(&metrics.GaugeOpts{
+		Namespace:      metricNamespaceAntrea,
+		Subsystem:      metricSubsystemController,
+		Name:           "connectivity_probe_reachable",
+		Help:           "Whether the destination of a ConnectivityProbe was reachable (1) or not (0) in the last probe which could be injected, partitioned by ConnectivityProbe.",
+		StabilityLevel: metrics.ALPHA,
+	}, []string{"probe"})
 )
 
 // Initialize Prometheus metrics collection.
@@ -156,4 +177,13 @@ func InitializePrometheusMetrics() {
 	if err := legacyregistry.Register(AntreaClusterNetworkPolicyStatusUpdates); err != nil {
 		klog.Errorf("Failed to register antrea_controller_acnp_status_updates with Prometheus: %s", err.Error())
 	}
+	if err := legacyregistry.Register(ConnectivityProbeResults); err != nil {
+		klog.Errorf("Failed to register antrea_controller_connectivity_probe_results with Prometheus: %s", err.Error())
+	}
+	if err := legacyregistry.Register(ConnectivityProbeLatency); err != nil {
+		klog.Errorf("Failed to register antrea_controller_connectivity_probe_latency_milliseconds with Prometheus: %s", err.Error())
+	}
+	if err := legacyregistry.Register(ConnectivityProbeReachable); err != nil {
+		klog.Errorf("Failed to register antrea_controller_connectivity_probe_reachable with Prometheus: %s", err.Error())
+	}
 }
@@ -99,14 +99,16 @@ func (n *NetworkPolicyController) processAntreaNetworkPolicy(np *crdv1alpha1.Net
 		peer, ags := n.toAntreaPeerForCRD(ingressRule.From, np, controlplane.DirectionIn, namedPortExists)
 		addressGroups = mergeAddressGroups(addressGroups, ags...)
 		rules = append(rules, controlplane.NetworkPolicyRule{
-			Direction:       controlplane.DirectionIn,
-			From:            *peer,
-			Services:        services,
-			Name:            ingressRule.Name,
-			Action:          ingressRule.Action,
-			Priority:        int32(idx),
-			EnableLogging:   ingressRule.EnableLogging,
-			AppliedToGroups: getAppliedToGroupNames(atgs),
+			Direction:        controlplane.DirectionIn,
+			From:             *peer,
+			Services:         services,
+			Name:             ingressRule.Name,
+			Action:           ingressRule.Action,
+			Priority:         int32(idx),
+			EnableLogging:    ingressRule.EnableLogging,
+			LogRate:          ingressRule.LogRate,
+			LogSamplingRatio: ingressRule.LogSamplingRatio,
+			AppliedToGroups:  getAppliedToGroupNames(atgs),
 		})
 	}
 	// Compute NetworkPolicyRule for Egress Rule.
@@ -125,14 +127,16 @@ func (n *NetworkPolicyController) processAntreaNetworkPolicy(np *crdv1alpha1.Net
 			addressGroups = mergeAddressGroups(addressGroups, ags...)
 		}
 		rules = append(rules, controlplane.NetworkPolicyRule{
-			Direction:       controlplane.DirectionOut,
-			To:              *peer,
-			Services:        services,
-			Name:            egressRule.Name,
-			Action:          egressRule.Action,
-			Priority:        int32(idx),
-			EnableLogging:   egressRule.EnableLogging,
-			AppliedToGroups: getAppliedToGroupNames(atgs),
+			Direction:        controlplane.DirectionOut,
+			To:               *peer,
+			Services:         services,
+			Name:             egressRule.Name,
+			Action:           egressRule.Action,
+			Priority:         int32(idx),
+			EnableLogging:    egressRule.EnableLogging,
+			LogRate:          egressRule.LogRate,
+			LogSamplingRatio: egressRule.LogSamplingRatio,
+			AppliedToGroups:  getAppliedToGroupNames(atgs),
 		})
 	}
 	tierPriority := n.getTierPriority(np.Spec.Tier)
@@ -150,6 +154,7 @@ func (n *NetworkPolicyController) processAntreaNetworkPolicy(np *crdv1alpha1.Net
 		Rules:            rules,
 		Priority:         &np.Spec.Priority,
 		TierPriority:     &tierPriority,
+		Tier:             getTierName(np.Spec.Tier),
 		AppliedToPerRule: appliedToPerRule,
 	}
 	return internalNetworkPolicy, appliedToGroups, addressGroups
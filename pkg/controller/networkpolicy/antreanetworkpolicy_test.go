@@ -115,6 +115,7 @@ func TestProcessAntreaNetworkPolicy(t *testing.T) {
 				},
 				Priority:     &p10,
 				TierPriority: &DefaultTierPriority,
+				Tier:         "application",
 				Rules: []controlplane.NetworkPolicyRule{
 					{
 						Direction: controlplane.DirectionIn,
@@ -200,6 +201,7 @@ func TestProcessAntreaNetworkPolicy(t *testing.T) {
 				},
 				Priority:     &p10,
 				TierPriority: &DefaultTierPriority,
+				Tier:         "application",
 				Rules: []controlplane.NetworkPolicyRule{
 					{
 						Direction: controlplane.DirectionIn,
@@ -293,6 +295,7 @@ func TestProcessAntreaNetworkPolicy(t *testing.T) {
 				},
 				Priority:     &p10,
 				TierPriority: &DefaultTierPriority,
+				Tier:         "application",
 				Rules: []controlplane.NetworkPolicyRule{
 					{
 						Direction:       controlplane.DirectionIn,
@@ -374,6 +377,7 @@ func TestProcessAntreaNetworkPolicy(t *testing.T) {
 				},
 				Priority:     &p10,
 				TierPriority: &DefaultTierPriority,
+				Tier:         "application",
 				Rules: []controlplane.NetworkPolicyRule{
 					{
 						Direction: controlplane.DirectionIn,
@@ -429,6 +433,7 @@ func TestProcessAntreaNetworkPolicy(t *testing.T) {
 				},
 				Priority:     &p10,
 				TierPriority: &DefaultTierPriority,
+				Tier:         "application",
 				Rules: []controlplane.NetworkPolicyRule{
 					{
 						Direction: controlplane.DirectionOut,
@@ -486,6 +491,7 @@ func TestProcessAntreaNetworkPolicy(t *testing.T) {
 				},
 				Priority:     &p10,
 				TierPriority: &DefaultTierPriority,
+				Tier:         "application",
 				Rules: []controlplane.NetworkPolicyRule{
 					{
 						Direction: controlplane.DirectionOut,
@@ -547,6 +553,7 @@ func TestProcessAntreaNetworkPolicy(t *testing.T) {
 				},
 				Priority:     &p10,
 				TierPriority: &DefaultTierPriority,
+				Tier:         "application",
 				Rules: []controlplane.NetworkPolicyRule{
 					{
 						Direction: controlplane.DirectionOut,
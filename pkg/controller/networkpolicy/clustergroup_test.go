@@ -984,6 +984,7 @@ func TestSyncInternalGroup(t *testing.T) {
 		},
 		Priority:     &p10,
 		TierPriority: &DefaultTierPriority,
+		Tier:         "application",
 		Rules: []controlplane.NetworkPolicyRule{
 			{
 				Direction: controlplane.DirectionIn,
@@ -1035,6 +1036,7 @@ func TestSyncInternalGroup(t *testing.T) {
 		},
 		Priority:     &p20,
 		TierPriority: &DefaultTierPriority,
+		Tier:         "application",
 		Rules: []controlplane.NetworkPolicyRule{
 			{
 				Direction: controlplane.DirectionIn,
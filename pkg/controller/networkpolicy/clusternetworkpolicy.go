@@ -364,13 +364,15 @@ func (n *NetworkPolicyController) processClusterNetworkPolicy(cnp *crdv1alpha1.C
 			clusterPeers, perNSPeers := splitPeersByScope(cnpRule, direction)
 			addRule := func(peer *controlplane.NetworkPolicyPeer, ruleAddressGroups []*antreatypes.AddressGroup, dir controlplane.Direction, ruleAppliedTos []*antreatypes.AppliedToGroup) {
 				rule := controlplane.NetworkPolicyRule{
-					Direction:       dir,
-					Services:        services,
-					Name:            cnpRule.Name,
-					Action:          cnpRule.Action,
-					Priority:        int32(idx),
-					EnableLogging:   cnpRule.EnableLogging,
-					AppliedToGroups: getAppliedToGroupNames(ruleAppliedTos),
+					Direction:        dir,
+					Services:         services,
+					Name:             cnpRule.Name,
+					Action:           cnpRule.Action,
+					Priority:         int32(idx),
+					EnableLogging:    cnpRule.EnableLogging,
+					LogRate:          cnpRule.LogRate,
+					LogSamplingRatio: cnpRule.LogSamplingRatio,
+					AppliedToGroups:  getAppliedToGroupNames(ruleAppliedTos),
 				}
 				if dir == controlplane.DirectionIn {
 					rule.From = *peer
@@ -451,6 +453,7 @@ func (n *NetworkPolicyController) processClusterNetworkPolicy(cnp *crdv1alpha1.C
 		Rules:            rules,
 		Priority:         &cnp.Spec.Priority,
 		TierPriority:     &tierPriority,
+		Tier:             getTierName(cnp.Spec.Tier),
 		AppliedToPerRule: appliedToPerRule,
 	}
 	return internalNetworkPolicy, appliedToGroups, addressGroups
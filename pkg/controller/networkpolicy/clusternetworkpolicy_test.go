@@ -157,6 +157,7 @@ func TestProcessClusterNetworkPolicy(t *testing.T) {
 				},
 				Priority:     &p10,
 				TierPriority: &DefaultTierPriority,
+				Tier:         "application",
 				Rules: []controlplane.NetworkPolicyRule{
 					{
 						Direction: controlplane.DirectionIn,
@@ -241,6 +242,7 @@ func TestProcessClusterNetworkPolicy(t *testing.T) {
 				},
 				Priority:     &p10,
 				TierPriority: &DefaultTierPriority,
+				Tier:         "application",
 				Rules: []controlplane.NetworkPolicyRule{
 					{
 						Direction: controlplane.DirectionIn,
@@ -308,6 +310,7 @@ func TestProcessClusterNetworkPolicy(t *testing.T) {
 				},
 				Priority:     &p10,
 				TierPriority: &tierA.Spec.Priority,
+				Tier:         tierA.Name,
 				Rules: []controlplane.NetworkPolicyRule{
 					{
 						Direction: controlplane.DirectionIn,
@@ -361,6 +364,7 @@ func TestProcessClusterNetworkPolicy(t *testing.T) {
 				},
 				Priority:     &p10,
 				TierPriority: &DefaultTierPriority,
+				Tier:         "application",
 				Rules: []controlplane.NetworkPolicyRule{
 					{
 						Direction: controlplane.DirectionIn,
@@ -441,6 +445,7 @@ func TestProcessClusterNetworkPolicy(t *testing.T) {
 				},
 				Priority:     &p10,
 				TierPriority: &DefaultTierPriority,
+				Tier:         "application",
 				Rules: []controlplane.NetworkPolicyRule{
 					{
 						Direction:       controlplane.DirectionIn,
@@ -533,6 +538,7 @@ func TestProcessClusterNetworkPolicy(t *testing.T) {
 				},
 				Priority:     &p10,
 				TierPriority: &DefaultTierPriority,
+				Tier:         "application",
 				Rules: []controlplane.NetworkPolicyRule{
 					{
 						Direction: controlplane.DirectionIn,
@@ -626,6 +632,7 @@ func TestProcessClusterNetworkPolicy(t *testing.T) {
 				},
 				Priority:     &p10,
 				TierPriority: &DefaultTierPriority,
+				Tier:         "application",
 				Rules: []controlplane.NetworkPolicyRule{
 					{
 						Direction:       controlplane.DirectionIn,
@@ -717,6 +724,7 @@ func TestProcessClusterNetworkPolicy(t *testing.T) {
 				},
 				Priority:     &p10,
 				TierPriority: &DefaultTierPriority,
+				Tier:         "application",
 				Rules: []controlplane.NetworkPolicyRule{
 					{
 						Direction:       controlplane.DirectionIn,
@@ -836,6 +844,7 @@ func TestProcessClusterNetworkPolicy(t *testing.T) {
 				},
 				Priority:     &p10,
 				TierPriority: &DefaultTierPriority,
+				Tier:         "application",
 				Rules: []controlplane.NetworkPolicyRule{
 					{
 						Direction:       controlplane.DirectionIn,
@@ -909,6 +918,7 @@ func TestProcessClusterNetworkPolicy(t *testing.T) {
 				},
 				Priority:     &p10,
 				TierPriority: &DefaultTierPriority,
+				Tier:         "application",
 				Rules: []controlplane.NetworkPolicyRule{
 					{
 						Direction: controlplane.DirectionOut,
@@ -966,6 +976,7 @@ func TestProcessClusterNetworkPolicy(t *testing.T) {
 				},
 				Priority:     &p10,
 				TierPriority: &DefaultTierPriority,
+				Tier:         "application",
 				Rules: []controlplane.NetworkPolicyRule{
 					{
 						Direction: controlplane.DirectionOut,
@@ -1022,6 +1033,7 @@ func TestProcessClusterNetworkPolicy(t *testing.T) {
 				},
 				Priority:     &p10,
 				TierPriority: &DefaultTierPriority,
+				Tier:         "application",
 				Rules: []controlplane.NetworkPolicyRule{
 					{
 						Direction: controlplane.DirectionOut,
@@ -1073,6 +1085,7 @@ func TestProcessClusterNetworkPolicy(t *testing.T) {
 				},
 				Priority:     &p10,
 				TierPriority: &DefaultTierPriority,
+				Tier:         "application",
 				Rules: []controlplane.NetworkPolicyRule{
 					{
 						Direction: controlplane.DirectionOut,
@@ -1136,6 +1149,7 @@ func TestProcessClusterNetworkPolicy(t *testing.T) {
 				},
 				Priority:     &p10,
 				TierPriority: &DefaultTierPriority,
+				Tier:         "application",
 				Rules: []controlplane.NetworkPolicyRule{
 					{
 						Direction: controlplane.DirectionOut,
@@ -1215,6 +1229,7 @@ func TestProcessClusterNetworkPolicy(t *testing.T) {
 				},
 				Priority:     &p10,
 				TierPriority: &DefaultTierPriority,
+				Tier:         "application",
 				Rules: []controlplane.NetworkPolicyRule{
 					{
 						Direction: controlplane.DirectionIn,
@@ -1304,6 +1319,7 @@ func TestProcessClusterNetworkPolicy(t *testing.T) {
 				},
 				Priority:     &p10,
 				TierPriority: &DefaultTierPriority,
+				Tier:         "application",
 				Rules: []controlplane.NetworkPolicyRule{
 					{
 						Direction: controlplane.DirectionIn,
@@ -1373,6 +1389,7 @@ func TestProcessClusterNetworkPolicy(t *testing.T) {
 				},
 				Priority:     &p10,
 				TierPriority: &DefaultTierPriority,
+				Tier:         "application",
 				Rules: []controlplane.NetworkPolicyRule{
 					{
 						Direction: controlplane.DirectionIn,
@@ -1432,6 +1449,7 @@ func TestProcessClusterNetworkPolicy(t *testing.T) {
 				},
 				Priority:     &p10,
 				TierPriority: &DefaultTierPriority,
+				Tier:         "application",
 				Rules: []controlplane.NetworkPolicyRule{
 					{
 						Direction: controlplane.DirectionOut,
@@ -1496,6 +1514,7 @@ func TestProcessClusterNetworkPolicy(t *testing.T) {
 				},
 				Priority:     &p10,
 				TierPriority: &DefaultTierPriority,
+				Tier:         "application",
 				Rules: []controlplane.NetworkPolicyRule{
 					{
 						Direction:       controlplane.DirectionIn,
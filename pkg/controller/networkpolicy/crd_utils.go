@@ -265,6 +265,22 @@ func (n *NetworkPolicyController) getTierPriority(tier string) int32 {
 	if tier == "" {
 		return DefaultTierPriority
 	}
+	tier = getTierName(tier)
+	t, err := n.tierLister.Get(tier)
+	if err != nil {
+		// This error should ideally not occur as we perform validation.
+		klog.Errorf("Failed to retrieve Tier %s. Setting default tier priority: %v", tier, err)
+		return DefaultTierPriority
+	}
+	return t.Spec.Priority
+}
+
+// getTierName retrieves the name of the Tier CRD associated with the input Tier
+// name. If the Tier name is empty, by default, the Application Tier is returned.
+func getTierName(tier string) string {
+	if tier == "" {
+		return defaultTierName
+	}
 	// If the tier name is part of the static tier name set, we need to convert
 	// tier name to lowercase to match the corresponding Tier CRD name. This is
 	// possible in case of upgrade where in a previously created Antrea Policy
@@ -272,15 +288,9 @@ func (n *NetworkPolicyController) getTierPriority(tier string) int32 {
 	// release 0.9.0 and deprecated in 0.10.0. So any upgrade from 0.9.0 to a
 	// later release will undergo this conversion.
 	if staticTierSet.Has(tier) {
-		tier = strings.ToLower(tier)
+		return strings.ToLower(tier)
 	}
-	t, err := n.tierLister.Get(tier)
-	if err != nil {
-		// This error should ideally not occur as we perform validation.
-		klog.Errorf("Failed to retrieve Tier %s. Setting default tier priority: %v", tier, err)
-		return DefaultTierPriority
-	}
-	return t.Spec.Priority
+	return tier
 }
 
 // getNormalizedNameForSelector retrieves the normalized name for GroupSelector.
@@ -2945,6 +2945,7 @@ func TestSyncInternalNetworkPolicy(t *testing.T) {
 		},
 		Priority:     &p10,
 		TierPriority: &DefaultTierPriority,
+		Tier:         "application",
 		Rules: []controlplane.NetworkPolicyRule{
 			{
 				Direction: controlplane.DirectionIn,
@@ -3193,6 +3194,7 @@ func TestSyncInternalNetworkPolicyWithGroups(t *testing.T) {
 				},
 				Priority:     &p10,
 				TierPriority: &DefaultTierPriority,
+				Tier:         "application",
 				Rules: []controlplane.NetworkPolicyRule{
 					{
 						Direction: controlplane.DirectionIn,
@@ -3242,6 +3244,7 @@ func TestSyncInternalNetworkPolicyWithGroups(t *testing.T) {
 				},
 				Priority:     &p10,
 				TierPriority: &DefaultTierPriority,
+				Tier:         "application",
 				Rules: []controlplane.NetworkPolicyRule{
 					{
 						Direction: controlplane.DirectionIn,
@@ -3286,6 +3289,7 @@ func TestSyncInternalNetworkPolicyWithGroups(t *testing.T) {
 				},
 				Priority:     &p10,
 				TierPriority: &DefaultTierPriority,
+				Tier:         "application",
 				Rules: []controlplane.NetworkPolicyRule{
 					{
 						Direction: controlplane.DirectionIn,
@@ -3335,6 +3339,7 @@ func TestSyncInternalNetworkPolicyWithGroups(t *testing.T) {
 				},
 				Priority:     &p10,
 				TierPriority: &DefaultTierPriority,
+				Tier:         "application",
 				Rules: []controlplane.NetworkPolicyRule{
 					{
 						Direction: controlplane.DirectionIn,
@@ -120,6 +120,7 @@ func ToNetworkPolicyMsg(in *types.NetworkPolicy, out *controlplane.NetworkPolicy
 	}
 	out.Priority = in.Priority
 	out.TierPriority = in.TierPriority
+	out.Tier = in.Tier
 }
 
 // NetworkPolicyKeyFunc knows how to get the key of a NetworkPolicy.
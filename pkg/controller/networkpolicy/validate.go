@@ -448,6 +448,10 @@ func (v *antreaPolicyValidator) createValidate(curObj interface{}, userInfo auth
 	if !allowed {
 		return reason, allowed
 	}
+	reason, allowed = v.validateLogLimits(ingress, egress)
+	if !allowed {
+		return reason, allowed
+	}
 	if err := v.validatePort(ingress, egress); err != nil {
 		return err.Error(), false
 	}
@@ -772,6 +776,30 @@ func (v *antreaPolicyValidator) validateFQDNSelectors(egressRules []crdv1alpha1.
 	return "", true
 }
 
+// validateLogLimits validates that logRate and logSamplingRatio are only set for rules which enable logging and allow,
+// drop or reject the matched traffic. Pass rules are not supported as the logging of the packets is done by the rules
+// they are passed to.
+func (v *antreaPolicyValidator) validateLogLimits(ingress, egress []crdv1alpha1.Rule) (string, bool) {
+	for _, r := range append(ingress, egress...) {
+		if r.LogRate == nil && r.LogSamplingRatio == nil {
+			continue
+		}
+		if r.LogRate != nil && *r.LogRate <= 0 {
+			return fmt.Sprintf("logRate must be a positive integer, got %d", *r.LogRate), false
+		}
+		if r.LogSamplingRatio != nil && (*r.LogSamplingRatio < 1 || *r.LogSamplingRatio > 100) {
+			return fmt.Sprintf("logSamplingRatio must be between 1 and 100, got %d", *r.LogSamplingRatio), false
+		}
+		if !r.EnableLogging {
+			return "logRate and logSamplingRatio can only be set when enableLogging is true", false
+		}
+		if r.Action == nil || *r.Action == crdv1alpha1.RuleActionPass {
+			return "logRate and logSamplingRatio can only be set for rules with action Allow, Drop or Reject", false
+		}
+	}
+	return "", true
+}
+
 // updateValidate validates the UPDATE events of Antrea-native policies.
 func (v *antreaPolicyValidator) updateValidate(curObj, oldObj interface{}, userInfo authenticationv1.UserInfo) (string, bool) {
 	var tier string
@@ -814,6 +842,10 @@ func (v *antreaPolicyValidator) updateValidate(curObj, oldObj interface{}, userI
 	if !allowed {
 		return reason, allowed
 	}
+	reason, allowed = v.validateLogLimits(ingress, egress)
+	if !allowed {
+		return reason, allowed
+	}
 	if err := v.validatePort(ingress, egress); err != nil {
 		return err.Error(), false
 	}
@@ -0,0 +1,263 @@
+// Copyright 2022 Antrea Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package packetcapture
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"time"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/wait"
+	coreinformers "k8s.io/client-go/informers/core/v1"
+	corelisters "k8s.io/client-go/listers/core/v1"
+	"k8s.io/client-go/tools/cache"
+	"k8s.io/client-go/util/workqueue"
+	"k8s.io/klog/v2"
+
+	crdv1alpha1 "antrea.io/antrea/pkg/apis/crd/v1alpha1"
+	"antrea.io/antrea/pkg/client/clientset/versioned"
+	crdinformers "antrea.io/antrea/pkg/client/informers/externalversions/crd/v1alpha1"
+	crdlisters "antrea.io/antrea/pkg/client/listers/crd/v1alpha1"
+)
+
+const (
+	controllerName = "PacketCaptureController"
+
+	// Set resyncPeriod to 0 to disable resyncing.
+	resyncPeriod time.Duration = 0
+
+	// How long to wait before retrying the processing of a PacketCapture.
+	minRetryDelay = 5 * time.Second
+	maxRetryDelay = 300 * time.Second
+
+	// Default number of workers processing PacketCapture requests.
+	defaultWorkers = 2
+
+	// String set to PacketCaptureStatus.Reason.
+	packetCaptureTimeout = "PacketCapture timeout"
+)
+
+var (
+	// timeoutGracePeriod is the time given to the Antrea Agent to report
+	// the result of a PacketCapture after its duration has elapsed, before
+	// the PacketCapture is considered to have timed out.
+	timeoutGracePeriod = 30 * time.Second
+)
+
+// Controller validates the PacketCapture requests, selects the Node on which
+// the packets are captured, and fails the PacketCaptures which are not
+// completed in time by the Antrea Agent of the Node.
+type Controller struct {
+	client                    versioned.Interface
+	podLister                 corelisters.PodLister
+	podListerSynced           cache.InformerSynced
+	packetCaptureLister       crdlisters.PacketCaptureLister
+	packetCaptureListerSynced cache.InformerSynced
+	queue                     workqueue.RateLimitingInterface
+}
+
+// NewPacketCaptureController creates a new PacketCapture controller.
+func NewPacketCaptureController(client versioned.Interface, podInformer coreinformers.PodInformer, packetCaptureInformer crdinformers.PacketCaptureInformer) *Controller {
+	c := &Controller{
+		client:                    client,
+		podLister:                 podInformer.Lister(),
+		podListerSynced:           podInformer.Informer().HasSynced,
+		packetCaptureLister:       packetCaptureInformer.Lister(),
+		packetCaptureListerSynced: packetCaptureInformer.Informer().HasSynced,
+		queue:                     workqueue.NewNamedRateLimitingQueue(workqueue.NewItemExponentialFailureRateLimiter(minRetryDelay, maxRetryDelay), "packetCapture"),
+	}
+	packetCaptureInformer.Informer().AddEventHandlerWithResyncPeriod(
+		cache.ResourceEventHandlerFuncs{
+			AddFunc:    c.addPacketCapture,
+			UpdateFunc: c.updatePacketCapture,
+		},
+		resyncPeriod,
+	)
+	return c
+}
+
+func (c *Controller) addPacketCapture(obj interface{}) {
+	pc := obj.(*crdv1alpha1.PacketCapture)
+	klog.V(2).InfoS("Processing PacketCapture ADD event", "name", pc.Name)
+	c.queue.Add(pc.Name)
+}
+
+func (c *Controller) updatePacketCapture(_, curObj interface{}) {
+	pc := curObj.(*crdv1alpha1.PacketCapture)
+	klog.V(2).InfoS("Processing PacketCapture UPDATE event", "name", pc.Name)
+	c.queue.Add(pc.Name)
+}
+
+func (c *Controller) Run(stopCh <-chan struct{}) {
+	defer c.queue.ShutDown()
+
+	klog.InfoS("Starting controller", "name", controllerName)
+	defer klog.InfoS("Shutting down controller", "name", controllerName)
+
+	if !cache.WaitForNamedCacheSync(controllerName, stopCh, c.podListerSynced, c.packetCaptureListerSynced) {
+		return
+	}
+
+	for i := 0; i < defaultWorkers; i++ {
+		go wait.Until(c.worker, time.Second, stopCh)
+	}
+	<-stopCh
+}
+
+func (c *Controller) worker() {
+	for c.processNextWorkItem() {
+	}
+}
+
+func (c *Controller) processNextWorkItem() bool {
+	obj, quit := c.queue.Get()
+	if quit {
+		return false
+	}
+	defer c.queue.Done(obj)
+
+	if key, ok := obj.(string); !ok {
+		c.queue.Forget(obj)
+		klog.Errorf("Expected string in work queue but got %#v", obj)
+	} else if err := c.syncPacketCapture(key); err == nil {
+		c.queue.Forget(key)
+	} else {
+		klog.ErrorS(err, "Error syncing PacketCapture", "name", key)
+		c.queue.AddRateLimited(key)
+	}
+	return true
+}
+
+func (c *Controller) syncPacketCapture(name string) error {
+	pc, err := c.packetCaptureLister.Get(name)
+	if err != nil {
+		if apierrors.IsNotFound(err) {
+			return nil
+		}
+		return err
+	}
+	switch pc.Status.Phase {
+	case "":
+		return c.startPacketCapture(pc)
+	case crdv1alpha1.PacketCaptureRunning:
+		return c.checkPacketCaptureTimeout(pc)
+	}
+	return nil
+}
+
+func (c *Controller) startPacketCapture(pc *crdv1alpha1.PacketCapture) error {
+	nodeName, err := c.validatePacketCapture(pc)
+	if err != nil {
+		klog.ErrorS(err, "Invalid PacketCapture request", "name", pc.Name)
+		return c.updatePacketCaptureStatus(pc, crdv1alpha1.PacketCaptureFailed, fmt.Sprintf("Invalid PacketCapture request, err: %v", err), "")
+	}
+	return c.updatePacketCaptureStatus(pc, crdv1alpha1.PacketCaptureRunning, "", nodeName)
+}
+
+// checkPacketCaptureTimeout fails a running PacketCapture if the Antrea Agent
+// has not completed it in time, and requeues it to be checked again otherwise.
+func (c *Controller) checkPacketCaptureTimeout(pc *crdv1alpha1.PacketCapture) error {
+	if pc.Status.StartTime == nil {
+		return nil
+	}
+	remaining := time.Until(pc.Status.StartTime.Add(getDuration(pc) + timeoutGracePeriod))
+	if remaining > 0 {
+		c.queue.AddAfter(pc.Name, remaining)
+		return nil
+	}
+	return c.updatePacketCaptureStatus(pc, crdv1alpha1.PacketCaptureFailed, packetCaptureTimeout, pc.Status.Node)
+}
+
+// getDuration returns the maximum duration of a PacketCapture.
+func getDuration(pc *crdv1alpha1.PacketCapture) time.Duration {
+	duration := pc.Spec.CaptureConfig.Duration
+	if duration == 0 {
+		duration = crdv1alpha1.DefaultPacketCaptureDuration
+	}
+	return time.Duration(duration) * time.Second
+}
+
+// validatePacketCapture validates a PacketCapture request and returns the name
+// of the Node on which the packets should be captured.
+func (c *Controller) validatePacketCapture(pc *crdv1alpha1.PacketCapture) (string, error) {
+	source, destination := pc.Spec.Source, pc.Spec.Destination
+	if source.Node != "" {
+		return "", errors.New("using Node as source is not supported")
+	}
+	if destination.Service != "" {
+		return "", errors.New("using Service as destination is not supported")
+	}
+	if source.Pod != "" && source.IP != "" {
+		return "", errors.New("source Pod and source IP cannot be specified together")
+	}
+	if destination.Pod != "" && destination.IP != "" {
+		return "", errors.New("destination Pod and destination IP cannot be specified together")
+	}
+	if source.IP != "" && net.ParseIP(source.IP) == nil {
+		return "", fmt.Errorf("source IP is not valid: %s", source.IP)
+	}
+	if destination.IP != "" && net.ParseIP(destination.IP) == nil {
+		return "", fmt.Errorf("destination IP is not valid: %s", destination.IP)
+	}
+
+	var namespace, podName string
+	switch pc.Spec.CapturePoint {
+	case "", crdv1alpha1.CapturePointSource:
+		if source.Pod == "" {
+			return "", errors.New("source Pod must be specified when capturing packets on the source")
+		}
+		namespace, podName = source.Namespace, source.Pod
+	case crdv1alpha1.CapturePointDestination:
+		if destination.Pod == "" {
+			return "", errors.New("destination Pod must be specified when capturing packets on the destination")
+		}
+		namespace, podName = destination.Namespace, destination.Pod
+	default:
+		return "", fmt.Errorf("capture point is not valid: %s", pc.Spec.CapturePoint)
+	}
+	pod, err := c.podLister.Pods(namespace).Get(podName)
+	if err != nil {
+		if apierrors.IsNotFound(err) {
+			return "", fmt.Errorf("requested Pod %s/%s not found", namespace, podName)
+		}
+		return "", err
+	}
+	if pod.Spec.HostNetwork {
+		return "", fmt.Errorf("capturing packets of hostNetwork Pod %s/%s is not supported", namespace, podName)
+	}
+	if pod.Spec.NodeName == "" {
+		return "", fmt.Errorf("requested Pod %s/%s is not scheduled to a Node", namespace, podName)
+	}
+	return pod.Spec.NodeName, nil
+}
+
+func (c *Controller) updatePacketCaptureStatus(pc *crdv1alpha1.PacketCapture, phase crdv1alpha1.PacketCapturePhase, reason string, nodeName string) error {
+	update := pc.DeepCopy()
+	update.Status.Phase = phase
+	if phase == crdv1alpha1.PacketCaptureRunning && pc.Status.StartTime == nil {
+		t := metav1.Now()
+		update.Status.StartTime = &t
+	}
+	update.Status.Node = nodeName
+	if reason != "" {
+		update.Status.Reason = reason
+	}
+	_, err := c.client.CrdV1alpha1().PacketCaptures().UpdateStatus(context.TODO(), update, metav1.UpdateOptions{})
+	return err
+}
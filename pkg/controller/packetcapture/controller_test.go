@@ -0,0 +1,215 @@
+// Copyright 2022 Antrea Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package packetcapture
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/informers"
+	"k8s.io/client-go/kubernetes/fake"
+
+	crdv1alpha1 "antrea.io/antrea/pkg/apis/crd/v1alpha1"
+	fakeversioned "antrea.io/antrea/pkg/client/clientset/versioned/fake"
+	crdinformers "antrea.io/antrea/pkg/client/informers/externalversions"
+)
+
+var (
+	pod1 = &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "ns1", Name: "pod1"},
+		Spec:       corev1.PodSpec{NodeName: "node1"},
+	}
+	pod2 = &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "ns1", Name: "pod2"},
+		Spec:       corev1.PodSpec{NodeName: "node2"},
+	}
+	hostNetworkPod = &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "ns1", Name: "pod3"},
+		Spec:       corev1.PodSpec{NodeName: "node1", HostNetwork: true},
+	}
+)
+
+type fakeController struct {
+	*Controller
+	crdClient          *fakeversioned.Clientset
+	informerFactory    informers.SharedInformerFactory
+	crdInformerFactory crdinformers.SharedInformerFactory
+}
+
+func newFakeController(objects ...*crdv1alpha1.PacketCapture) *fakeController {
+	client := fake.NewSimpleClientset(pod1, pod2, hostNetworkPod)
+	var crdObjects []runtime.Object
+	for _, obj := range objects {
+		crdObjects = append(crdObjects, obj)
+	}
+	crdClient := fakeversioned.NewSimpleClientset(crdObjects...)
+	informerFactory := informers.NewSharedInformerFactory(client, 0)
+	crdInformerFactory := crdinformers.NewSharedInformerFactory(crdClient, 0)
+	c := NewPacketCaptureController(crdClient,
+		informerFactory.Core().V1().Pods(),
+		crdInformerFactory.Crd().V1alpha1().PacketCaptures())
+	return &fakeController{
+		Controller:         c,
+		crdClient:          crdClient,
+		informerFactory:    informerFactory,
+		crdInformerFactory: crdInformerFactory,
+	}
+}
+
+func (c *fakeController) start(t *testing.T) {
+	stopCh := make(chan struct{})
+	t.Cleanup(func() { close(stopCh) })
+	c.informerFactory.Start(stopCh)
+	c.crdInformerFactory.Start(stopCh)
+	c.informerFactory.WaitForCacheSync(stopCh)
+	c.crdInformerFactory.WaitForCacheSync(stopCh)
+}
+
+func (c *fakeController) getStatus(t *testing.T, name string) crdv1alpha1.PacketCaptureStatus {
+	pc, err := c.crdClient.CrdV1alpha1().PacketCaptures().Get(context.TODO(), name, metav1.GetOptions{})
+	require.NoError(t, err)
+	return pc.Status
+}
+
+func TestStartPacketCapture(t *testing.T) {
+	for _, tc := range []struct {
+		name           string
+		spec           crdv1alpha1.PacketCaptureSpec
+		expectedPhase  crdv1alpha1.PacketCapturePhase
+		expectedNode   string
+		expectedReason string
+	}{
+		{
+			name: "capture on source Pod",
+			spec: crdv1alpha1.PacketCaptureSpec{
+				Source:      crdv1alpha1.Source{Namespace: "ns1", Pod: "pod1"},
+				Destination: crdv1alpha1.Destination{Namespace: "ns1", Pod: "pod2"},
+			},
+			expectedPhase: crdv1alpha1.PacketCaptureRunning,
+			expectedNode:  "node1",
+		},
+		{
+			name: "capture on destination Pod",
+			spec: crdv1alpha1.PacketCaptureSpec{
+				Source:       crdv1alpha1.Source{IP: "10.10.0.10"},
+				Destination:  crdv1alpha1.Destination{Namespace: "ns1", Pod: "pod2"},
+				CapturePoint: crdv1alpha1.CapturePointDestination,
+			},
+			expectedPhase: crdv1alpha1.PacketCaptureRunning,
+			expectedNode:  "node2",
+		},
+		{
+			name: "capture Pod not specified",
+			spec: crdv1alpha1.PacketCaptureSpec{
+				Source:       crdv1alpha1.Source{Namespace: "ns1", Pod: "pod1"},
+				Destination:  crdv1alpha1.Destination{IP: "10.10.0.10"},
+				CapturePoint: crdv1alpha1.CapturePointDestination,
+			},
+			expectedPhase:  crdv1alpha1.PacketCaptureFailed,
+			expectedReason: "Invalid PacketCapture request, err: destination Pod must be specified when capturing packets on the destination",
+		},
+		{
+			name: "capture Pod not found",
+			spec: crdv1alpha1.PacketCaptureSpec{
+				Source: crdv1alpha1.Source{Namespace: "ns1", Pod: "pod4"},
+			},
+			expectedPhase:  crdv1alpha1.PacketCaptureFailed,
+			expectedReason: "Invalid PacketCapture request, err: requested Pod ns1/pod4 not found",
+		},
+		{
+			name: "hostNetwork Pod",
+			spec: crdv1alpha1.PacketCaptureSpec{
+				Source: crdv1alpha1.Source{Namespace: "ns1", Pod: "pod3"},
+			},
+			expectedPhase:  crdv1alpha1.PacketCaptureFailed,
+			expectedReason: "Invalid PacketCapture request, err: capturing packets of hostNetwork Pod ns1/pod3 is not supported",
+		},
+		{
+			name: "Service destination",
+			spec: crdv1alpha1.PacketCaptureSpec{
+				Source:      crdv1alpha1.Source{Namespace: "ns1", Pod: "pod1"},
+				Destination: crdv1alpha1.Destination{Namespace: "ns1", Service: "svc1"},
+			},
+			expectedPhase:  crdv1alpha1.PacketCaptureFailed,
+			expectedReason: "Invalid PacketCapture request, err: using Service as destination is not supported",
+		},
+		{
+			name: "invalid destination IP",
+			spec: crdv1alpha1.PacketCaptureSpec{
+				Source:      crdv1alpha1.Source{Namespace: "ns1", Pod: "pod1"},
+				Destination: crdv1alpha1.Destination{IP: "10.10.0"},
+			},
+			expectedPhase:  crdv1alpha1.PacketCaptureFailed,
+			expectedReason: "Invalid PacketCapture request, err: destination IP is not valid: 10.10.0",
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			pc := &crdv1alpha1.PacketCapture{
+				ObjectMeta: metav1.ObjectMeta{Name: "pc1"},
+				Spec:       tc.spec,
+			}
+			c := newFakeController(pc)
+			c.start(t)
+
+			require.NoError(t, c.syncPacketCapture(pc.Name))
+			status := c.getStatus(t, pc.Name)
+			assert.Equal(t, tc.expectedPhase, status.Phase)
+			assert.Equal(t, tc.expectedNode, status.Node)
+			assert.Equal(t, tc.expectedReason, status.Reason)
+			if tc.expectedPhase == crdv1alpha1.PacketCaptureRunning {
+				assert.NotNil(t, status.StartTime)
+			}
+		})
+	}
+}
+
+func TestCheckPacketCaptureTimeout(t *testing.T) {
+	startTime := metav1.NewTime(time.Now().Add(-2 * time.Minute))
+	newPacketCapture := func(name string, duration uint16) *crdv1alpha1.PacketCapture {
+		return &crdv1alpha1.PacketCapture{
+			ObjectMeta: metav1.ObjectMeta{Name: name},
+			Spec: crdv1alpha1.PacketCaptureSpec{
+				Source:        crdv1alpha1.Source{Namespace: "ns1", Pod: "pod1"},
+				CaptureConfig: crdv1alpha1.CaptureConfig{Duration: duration},
+			},
+			Status: crdv1alpha1.PacketCaptureStatus{
+				Phase:     crdv1alpha1.PacketCaptureRunning,
+				StartTime: &startTime,
+				Node:      "node1",
+			},
+		}
+	}
+	// The default duration and the grace period have elapsed.
+	pc1 := newPacketCapture("pc1", 0)
+	// The duration has not elapsed yet.
+	pc2 := newPacketCapture("pc2", 300)
+	c := newFakeController(pc1, pc2)
+	c.start(t)
+
+	require.NoError(t, c.syncPacketCapture(pc1.Name))
+	status := c.getStatus(t, pc1.Name)
+	assert.Equal(t, crdv1alpha1.PacketCaptureFailed, status.Phase)
+	assert.Equal(t, packetCaptureTimeout, status.Reason)
+	assert.Equal(t, "node1", status.Node)
+
+	require.NoError(t, c.syncPacketCapture(pc2.Name))
+	assert.Equal(t, crdv1alpha1.PacketCaptureRunning, c.getStatus(t, pc2.Name).Phase)
+}
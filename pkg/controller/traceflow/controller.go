@@ -18,6 +18,7 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"net"
 	"sync"
 	"time"
 
@@ -25,6 +26,7 @@ import (
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/util/sets"
 	"k8s.io/apimachinery/pkg/util/wait"
 	coreinformers "k8s.io/client-go/informers/core/v1"
 	corelisters "k8s.io/client-go/listers/core/v1"
@@ -55,10 +57,18 @@ const (
 	// Min and max data plane tag for traceflow. minTagNum is 7 (0b000111), maxTagNum is 59 (0b111011).
 	// As per RFC2474, 16 different DSCP values are we reserved for Experimental or Local Use, which we use as the 16 possible data plane tag values.
 	// tagStep is 4 (0b100) to keep last 2 bits at 0b11.
+	// A tag only needs to be unique among the Traceflows whose packets can traverse the same Nodes, so more
+	// Traceflows than the number of tags can run at the same time if they involve different Nodes.
 	tagStep   uint8 = 0b100
 	minTagNum uint8 = 0b1*tagStep + 0b11
 	maxTagNum uint8 = 0b1110*tagStep + 0b11
 
+	// IP protocol numbers of the packets whose reply can be traced.
+	protocolICMP   int32 = 1
+	protocolTCP    int32 = 6
+	protocolUDP    int32 = 17
+	protocolICMPv6 int32 = 58
+
 	// PodIP index name for Pod cache.
 	podIPsIndex = "podIPs"
 
@@ -78,25 +88,48 @@ type Controller struct {
 	client                 versioned.Interface
 	podInformer            coreinformers.PodInformer
 	podLister              corelisters.PodLister
+	nodeLister             corelisters.NodeLister
+	nodeListerSynced       cache.InformerSynced
 	traceflowInformer      crdinformers.TraceflowInformer
 	traceflowLister        crdlisters.TraceflowLister
 	traceflowListerSynced  cache.InformerSynced
 	queue                  workqueue.RateLimitingInterface
 	runningTraceflowsMutex sync.Mutex
-	runningTraceflows      map[uint8]string // tag->traceflowName if tf.Status.Phase is Running.
+	runningTraceflows      map[string]*tagAllocation // traceflowName->tagAllocation if tf.Status.Phase is Running.
+}
+
+// tagAllocation is the data plane tag allocated to a running Traceflow, and the
+// Nodes on which the tag is reserved. A nil nodes set means all Nodes.
+type tagAllocation struct {
+	tag   uint8
+	nodes sets.String
+}
+
+// conflictsWith returns whether the tag cannot be allocated on the provided
+// Nodes, because it is already reserved on one of them.
+func (a *tagAllocation) conflictsWith(tag uint8, nodes sets.String) bool {
+	if a.tag != tag {
+		return false
+	}
+	if a.nodes == nil || nodes == nil {
+		return true
+	}
+	return a.nodes.HasAny(nodes.UnsortedList()...)
 }
 
 // NewTraceflowController creates a new traceflow controller and adds podIP indexer to podInformer.
-func NewTraceflowController(client versioned.Interface, podInformer coreinformers.PodInformer, traceflowInformer crdinformers.TraceflowInformer) *Controller {
+func NewTraceflowController(client versioned.Interface, podInformer coreinformers.PodInformer, nodeInformer coreinformers.NodeInformer, traceflowInformer crdinformers.TraceflowInformer) *Controller {
 	c := &Controller{
 		client:                client,
 		podInformer:           podInformer,
 		podLister:             podInformer.Lister(),
+		nodeLister:            nodeInformer.Lister(),
+		nodeListerSynced:      nodeInformer.Informer().HasSynced,
 		traceflowInformer:     traceflowInformer,
 		traceflowLister:       traceflowInformer.Lister(),
 		traceflowListerSynced: traceflowInformer.Informer().HasSynced,
 		queue:                 workqueue.NewNamedRateLimitingQueue(workqueue.NewItemExponentialFailureRateLimiter(minRetryDelay, maxRetryDelay), "traceflow"),
-		runningTraceflows:     make(map[uint8]string)}
+		runningTraceflows:     make(map[string]*tagAllocation)}
 	// Add handlers for ClusterNetworkPolicy events.
 	traceflowInformer.Informer().AddEventHandlerWithResyncPeriod(
 		cache.ResourceEventHandlerFuncs{
@@ -138,7 +171,7 @@ func (c *Controller) Run(stopCh <-chan struct{}) {
 	klog.Infof("Starting %s", controllerName)
 	defer klog.Infof("Shutting down %s", controllerName)
 
-	if !cache.WaitForNamedCacheSync(controllerName, stopCh, c.traceflowListerSynced) {
+	if !cache.WaitForNamedCacheSync(controllerName, stopCh, c.traceflowListerSynced, c.nodeListerSynced) {
 		return
 	}
 
@@ -193,7 +226,7 @@ func (c *Controller) worker() {
 func (c *Controller) checkTraceflowTimeout() {
 	c.runningTraceflowsMutex.Lock()
 	tfs := make([]string, 0, len(c.runningTraceflows))
-	for _, tfName := range c.runningTraceflows {
+	for tfName := range c.runningTraceflows {
 		tfs = append(tfs, tfName)
 	}
 	c.runningTraceflowsMutex.Unlock()
@@ -271,10 +304,11 @@ func (c *Controller) syncTraceflow(traceflowName string) error {
 func (c *Controller) startTraceflow(tf *crdv1alpha1.Traceflow) error {
 	if err := c.validateTraceflow(tf); err != nil {
 		klog.ErrorS(err, "Invalid Traceflow request", "request", tf)
-		return c.updateTraceflowStatus(tf, crdv1alpha1.Failed, fmt.Sprintf("Invalid Traceflow request, err: %+v", err), 0)
+		return c.updateTraceflowStatus(tf, crdv1alpha1.Failed, fmt.Sprintf("Invalid Traceflow request, err: %+v", err), 0, nil, "")
 	}
 	// Allocate data plane tag.
-	tag, err := c.allocateTag(tf.Name)
+	tagNodes := c.getTagNodes(tf)
+	tag, err := c.allocateTag(tf.Name, tagNodes)
 	if err != nil {
 		return err
 	}
@@ -282,7 +316,11 @@ func (c *Controller) startTraceflow(tf *crdv1alpha1.Traceflow) error {
 		return nil
 	}
 
-	err = c.updateTraceflowStatus(tf, crdv1alpha1.Running, "", tag)
+	var tagNodeNames []string
+	if tagNodes != nil {
+		tagNodeNames = tagNodes.List()
+	}
+	err = c.updateTraceflowStatus(tf, crdv1alpha1.Running, "", tag, tagNodeNames, c.getHostSenderNode(tf))
 	if err != nil {
 		c.deallocateTag(tf.Name, tag)
 	}
@@ -301,17 +339,18 @@ func (c *Controller) checkTraceflowStatus(tf *crdv1alpha1.Traceflow) error {
 	} else {
 		sender := false
 		receiver := false
+		delivered := false
 		for i, nodeResult := range tf.Status.Results {
 			for j, ob := range nodeResult.Observations {
 				if ob.Component == crdv1alpha1.ComponentSpoofGuard {
 					sender = true
 				}
-				if ob.Action == crdv1alpha1.ActionDelivered ||
-					ob.Action == crdv1alpha1.ActionDropped ||
-					ob.Action == crdv1alpha1.ActionRejected ||
-					ob.Action == crdv1alpha1.ActionForwardedOutOfOverlay {
+				if isReceiverObservation(&ob) {
 					receiver = true
 				}
+				if ob.Action == crdv1alpha1.ActionDelivered {
+					delivered = true
+				}
 				if ob.TranslatedDstIP != "" {
 					// Add Pod ns/name to observation if TranslatedDstIP (a.k.a. Service Endpoint address) is Pod IP.
 					pods, err := c.podInformer.Informer().GetIndexer().ByIndex(podIPsIndex, ob.TranslatedDstIP)
@@ -328,15 +367,34 @@ func (c *Controller) checkTraceflowStatus(tf *crdv1alpha1.Traceflow) error {
 				}
 			}
 		}
-		// When the Source Pod is specified, the Traceflow should receive
-		// results from both the sender and the receiver. When the Source
-		// Pod is not specified (in live-traffic Traceflow), only the
+		// When the Source Pod or Node is specified, the Traceflow should
+		// receive results from both the sender and the receiver. When the
+		// Source Pod is not specified in live-traffic Traceflow, only the
 		// receiver Node will report the results.
-		succeeded = (sender && receiver) || (receiver && tf.Spec.Source.Pod == "")
+		succeeded = (sender && receiver) || (receiver && tf.Spec.LiveTraffic && tf.Spec.Source.Pod == "")
+		// For bidirectional Traceflow, the reply packet is injected by the
+		// destination Node when the packet is delivered to the destination
+		// Pod, and the Traceflow should also receive the results of the
+		// reply packet from its sender and receiver.
+		if succeeded && tf.Spec.Bidirectional && delivered {
+			replySender := false
+			replyReceiver := false
+			for _, nodeResult := range tf.Status.ReplyResults {
+				for _, ob := range nodeResult.Observations {
+					if ob.Component == crdv1alpha1.ComponentSpoofGuard {
+						replySender = true
+					}
+					if isReceiverObservation(&ob) {
+						replyReceiver = true
+					}
+				}
+			}
+			succeeded = replySender && replyReceiver
+		}
 	}
 	if succeeded {
 		c.deallocateTagForTF(tf)
-		return c.updateTraceflowStatus(tf, crdv1alpha1.Succeeded, "", 0)
+		return c.updateTraceflowStatus(tf, crdv1alpha1.Succeeded, "", 0, nil, tf.Status.HostSenderNode)
 	}
 
 	var timeout time.Duration
@@ -356,12 +414,21 @@ func (c *Controller) checkTraceflowStatus(tf *crdv1alpha1.Traceflow) error {
 	}
 	if startTime.Add(timeout).Before(time.Now()) {
 		c.deallocateTagForTF(tf)
-		return c.updateTraceflowStatus(tf, crdv1alpha1.Failed, traceflowTimeout, 0)
+		return c.updateTraceflowStatus(tf, crdv1alpha1.Failed, traceflowTimeout, 0, nil, tf.Status.HostSenderNode)
 	}
 	return nil
 }
 
-func (c *Controller) updateTraceflowStatus(tf *crdv1alpha1.Traceflow, phase crdv1alpha1.TraceflowPhase, reason string, dataPlaneTag uint8) error {
+// isReceiverObservation returns whether the observation is reported by the last
+// Node the Traceflow packet traverses.
+func isReceiverObservation(ob *crdv1alpha1.Observation) bool {
+	return ob.Action == crdv1alpha1.ActionDelivered ||
+		ob.Action == crdv1alpha1.ActionDropped ||
+		ob.Action == crdv1alpha1.ActionRejected ||
+		ob.Action == crdv1alpha1.ActionForwardedOutOfOverlay
+}
+
+func (c *Controller) updateTraceflowStatus(tf *crdv1alpha1.Traceflow, phase crdv1alpha1.TraceflowPhase, reason string, dataPlaneTag uint8, dataPlaneTagNodes []string, hostSenderNode string) error {
 	update := tf.DeepCopy()
 	update.Status.Phase = phase
 	if phase == crdv1alpha1.Running && tf.Status.StartTime == nil {
@@ -369,6 +436,8 @@ func (c *Controller) updateTraceflowStatus(tf *crdv1alpha1.Traceflow, phase crdv
 		update.Status.StartTime = &t
 	}
 	update.Status.DataplaneTag = dataPlaneTag
+	update.Status.DataplaneTagNodes = dataPlaneTagNodes
+	update.Status.HostSenderNode = hostSenderNode
 	if reason != "" {
 		update.Status.Reason = reason
 	}
@@ -381,36 +450,49 @@ func (c *Controller) occupyTag(tf *crdv1alpha1.Traceflow) error {
 	if tag < minTagNum || tag > maxTagNum {
 		return errors.New("this Traceflow CRD's data plane tag is out of range")
 	}
+	var nodes sets.String
+	if len(tf.Status.DataplaneTagNodes) > 0 {
+		nodes = sets.NewString(tf.Status.DataplaneTagNodes...)
+	}
 
 	c.runningTraceflowsMutex.Lock()
 	defer c.runningTraceflowsMutex.Unlock()
-	if existingTraceflowName, ok := c.runningTraceflows[tag]; ok {
-		if tf.Name == existingTraceflowName {
-			return nil
+	if _, ok := c.runningTraceflows[tf.Name]; ok {
+		return nil
+	}
+	for _, allocation := range c.runningTraceflows {
+		if allocation.conflictsWith(tag, nodes) {
+			return errors.New("this Traceflow's CRD data plane tag is already taken")
 		}
-		return errors.New("this Traceflow's CRD data plane tag is already taken")
 	}
 
-	c.runningTraceflows[tag] = tf.Name
+	c.runningTraceflows[tf.Name] = &tagAllocation{tag: tag, nodes: nodes}
 	return nil
 }
 
-// Allocates a tag. If the Traceflow request has been allocated with a tag
-// already, 0 is returned. If number of existing Traceflow requests reaches
-// the upper limit, an error is returned.
-func (c *Controller) allocateTag(name string) (uint8, error) {
+// Allocates a tag which is not reserved by other running Traceflows on the
+// provided Nodes, or on any Node if nodes is nil. If the Traceflow request has
+// been allocated with a tag already, 0 is returned. If number of existing
+// Traceflow requests on these Nodes reaches the upper limit, an error is
+// returned.
+func (c *Controller) allocateTag(name string, nodes sets.String) (uint8, error) {
 	c.runningTraceflowsMutex.Lock()
 	defer c.runningTraceflowsMutex.Unlock()
 
-	for _, n := range c.runningTraceflows {
-		if n == name {
-			// The Traceflow request has been processed already.
-			return 0, nil
-		}
+	if _, ok := c.runningTraceflows[name]; ok {
+		// The Traceflow request has been processed already.
+		return 0, nil
 	}
 	for i := minTagNum; i <= maxTagNum; i += tagStep {
-		if _, ok := c.runningTraceflows[i]; !ok {
-			c.runningTraceflows[i] = name
+		available := true
+		for _, allocation := range c.runningTraceflows {
+			if allocation.conflictsWith(i, nodes) {
+				available = false
+				break
+			}
+		}
+		if available {
+			c.runningTraceflows[name] = &tagAllocation{tag: i, nodes: nodes}
 			return i, nil
 		}
 	}
@@ -427,25 +509,165 @@ func (c *Controller) deallocateTagForTF(tf *crdv1alpha1.Traceflow) {
 func (c *Controller) deallocateTag(name string, tag uint8) {
 	c.runningTraceflowsMutex.Lock()
 	defer c.runningTraceflowsMutex.Unlock()
-	if existingTraceflowName, ok := c.runningTraceflows[tag]; ok {
-		if name == existingTraceflowName {
-			delete(c.runningTraceflows, tag)
+	if allocation, ok := c.runningTraceflows[name]; ok {
+		if tag == allocation.tag {
+			delete(c.runningTraceflows, name)
+		}
+	}
+}
+
+// getTagNodes returns the Nodes which the packets carrying the data plane tag
+// of a Traceflow can traverse, or nil if they can traverse any Node. Packets are
+// tagged on the source Node (the Node of the source Pod, or the Node specified
+// as the source), or on the destination Node for a live-traffic Traceflow with
+// only the destination Pod specified, and a tagged packet only reaches the Node
+// of the destination if it is a Pod. If the Node of a Pod is not known yet, the
+// tag is reserved on all Nodes.
+func (c *Controller) getTagNodes(tf *crdv1alpha1.Traceflow) sets.String {
+	var dstNode string
+	if tf.Spec.Destination.Pod != "" {
+		dstNode = c.getPodNodeName(tf.Spec.Destination.Namespace, tf.Spec.Destination.Pod)
+	} else if tf.Spec.Destination.IP != "" {
+		dstNode = c.getPodNodeNameByIP(tf.Spec.Destination.IP)
+	}
+	if dstNode == "" {
+		// The destination is a Service, an IP which is not a Pod IP, or a
+		// Pod whose Node is not known. In the last case, the tag is also
+		// reserved on all Nodes for a live-traffic Traceflow with only the
+		// destination Pod specified.
+		return nil
+	}
+	var srcNode string
+	if tf.Spec.Source.Node != "" {
+		srcNode = tf.Spec.Source.Node
+	} else if tf.Spec.Source.Pod != "" {
+		srcNode = c.getSourcePodNodeName(tf.Spec.Source.Namespace, tf.Spec.Source.Pod)
+		if srcNode == "" {
+			return nil
 		}
+	} else {
+		return sets.NewString(dstNode)
 	}
+	return sets.NewString(srcNode, dstNode)
+}
+
+// getHostSenderNode returns the Node from whose host network the packet of a
+// non-live-traffic Traceflow is injected, i.e. the source Node, or the Node of a
+// hostNetwork source Pod. The Node is recorded in the Traceflow status, so that
+// the Agents do not have to look up the source Pod.
+func (c *Controller) getHostSenderNode(tf *crdv1alpha1.Traceflow) string {
+	if tf.Spec.LiveTraffic {
+		return ""
+	}
+	if tf.Spec.Source.Node != "" {
+		return tf.Spec.Source.Node
+	}
+	pod, err := c.podLister.Pods(tf.Spec.Source.Namespace).Get(tf.Spec.Source.Pod)
+	if err != nil || !pod.Spec.HostNetwork {
+		return ""
+	}
+	return pod.Spec.NodeName
+}
+
+// getSourcePodNodeName returns the Node of a source Pod. Unlike for a destination
+// Pod, the Node of a hostNetwork Pod is returned, as the packets of the Pod are
+// sent from that Node.
+func (c *Controller) getSourcePodNodeName(namespace, name string) string {
+	pod, err := c.podLister.Pods(namespace).Get(name)
+	if err != nil {
+		return ""
+	}
+	return pod.Spec.NodeName
+}
+
+func (c *Controller) getPodNodeName(namespace, name string) string {
+	pod, err := c.podLister.Pods(namespace).Get(name)
+	if err != nil || pod.Spec.HostNetwork {
+		return ""
+	}
+	return pod.Spec.NodeName
+}
+
+func (c *Controller) getPodNodeNameByIP(ip string) string {
+	pods, err := c.podInformer.Informer().GetIndexer().ByIndex(podIPsIndex, ip)
+	if err != nil || len(pods) != 1 {
+		return ""
+	}
+	pod, ok := pods[0].(*corev1.Pod)
+	if !ok || pod.Spec.HostNetwork {
+		return ""
+	}
+	return pod.Spec.NodeName
 }
 
 func (c *Controller) validateTraceflow(tf *crdv1alpha1.Traceflow) error {
-	if !tf.Spec.LiveTraffic {
-		srcPod, err := c.podLister.Pods(tf.Spec.Source.Namespace).Get(tf.Spec.Source.Pod)
-		if err != nil {
+	if tf.Spec.LiveTraffic {
+		if tf.Spec.Source.Node != "" {
+			return errors.New("using Node as source in live-traffic Traceflow is not supported")
+		}
+	} else if tf.Spec.Source.Node != "" {
+		if tf.Spec.Source.Pod != "" {
+			return errors.New("source Pod and source Node cannot be specified together")
+		}
+		if tf.Spec.Source.IP != "" && net.ParseIP(tf.Spec.Source.IP) == nil {
+			return fmt.Errorf("source IP is not valid: %s", tf.Spec.Source.IP)
+		}
+		if _, err := c.nodeLister.Get(tf.Spec.Source.Node); err != nil {
+			if apierrors.IsNotFound(err) {
+				err = fmt.Errorf("requested source Node %s not found", tf.Spec.Source.Node)
+			}
+			return err
+		}
+	} else {
+		if tf.Spec.Source.IP != "" {
+			return errors.New("source Node must be specified with an external source IP in non-live-traffic Traceflow")
+		}
+		if tf.Spec.Source.Pod == "" {
+			return errors.New("source Pod or Node must be specified in non-live-traffic Traceflow")
+		}
+		// The packets of a hostNetwork Pod are sent from the host network of
+		// its Node.
+		if _, err := c.podLister.Pods(tf.Spec.Source.Namespace).Get(tf.Spec.Source.Pod); err != nil {
 			if apierrors.IsNotFound(err) {
 				err = fmt.Errorf("requested source Pod %s not found", k8s.NamespacedName(tf.Spec.Source.Namespace, tf.Spec.Source.Pod))
 			}
 			return err
 		}
-		if srcPod.Spec.HostNetwork {
-			return fmt.Errorf("using hostNetwork Pod as source in non-live-traffic Traceflow is not supported")
+	}
+	if tf.Spec.Bidirectional {
+		if tf.Spec.LiveTraffic {
+			return errors.New("bidirectional Traceflow is not supported for live-traffic Traceflow")
+		}
+		// The reply packet is injected from the destination Pod, so an IP
+		// destination must be the IP of a (non-hostNetwork) Pod.
+		if tf.Spec.Destination.IP != "" && c.getPodNodeNameByIP(tf.Spec.Destination.IP) == "" {
+			return fmt.Errorf("destination IP %s of bidirectional Traceflow is not a Pod IP", tf.Spec.Destination.IP)
+		}
+		if !isReplySupported(&tf.Spec.Packet) {
+			return errors.New("bidirectional Traceflow is only supported for TCP, UDP and ICMP packets")
 		}
 	}
 	return nil
 }
+
+// isReplySupported returns whether the reply of the Traceflow packet can be
+// generated. The packet defaults to an ICMP echo request if neither the IP
+// protocol nor the transport header is specified.
+func isReplySupported(packet *crdv1alpha1.Packet) bool {
+	if packet.TransportHeader.TCP != nil || packet.TransportHeader.UDP != nil || packet.TransportHeader.ICMP != nil {
+		return true
+	}
+	var proto int32
+	if packet.IPv6Header != nil {
+		if packet.IPv6Header.NextHeader != nil {
+			proto = *packet.IPv6Header.NextHeader
+		}
+	} else {
+		proto = packet.IPHeader.Protocol
+	}
+	switch proto {
+	case 0, protocolICMP, protocolTCP, protocolUDP, protocolICMPv6:
+		return true
+	}
+	return false
+}
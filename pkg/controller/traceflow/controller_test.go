@@ -16,6 +16,7 @@ package traceflow
 
 import (
 	"context"
+	"fmt"
 	"reflect"
 	"testing"
 	"time"
@@ -25,6 +26,7 @@ import (
 	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/sets"
 	"k8s.io/apimachinery/pkg/util/wait"
 	"k8s.io/client-go/informers"
 	clientset "k8s.io/client-go/kubernetes"
@@ -56,6 +58,7 @@ func newController() *traceflowController {
 	crdInformerFactory := crdinformers.NewSharedInformerFactory(crdClient, informerDefaultResync)
 	controller := NewTraceflowController(crdClient,
 		informerFactory.Core().V1().Pods(),
+		informerFactory.Core().V1().Nodes(),
 		crdInformerFactory.Crd().V1alpha1().Traceflows())
 	controller.traceflowListerSynced = alwaysReady
 	return &traceflowController{
@@ -135,6 +138,37 @@ func TestTraceflow(t *testing.T) {
 		tfc.client.CrdV1alpha1().Traceflows().Delete(context.TODO(), "tf1", metav1.DeleteOptions{})
 	})
 
+	t.Run("bidirectionalTraceflow", func(t *testing.T) {
+		tf3 := tf1.DeepCopy()
+		tf3.Name = "tf3"
+		tf3.Spec.Bidirectional = true
+		tfc.client.CrdV1alpha1().Traceflows().Create(context.TODO(), tf3, metav1.CreateOptions{})
+		res, _ := tfc.waitForTraceflow("tf3", crdv1alpha1.Running, time.Second)
+		require.NotNil(t, res)
+
+		// The Traceflow is not complete until the results of the reply
+		// packet are received.
+		res.Status.Results = []crdv1alpha1.NodeResult{
+			{Observations: []crdv1alpha1.Observation{{Component: crdv1alpha1.ComponentSpoofGuard}}},
+			{Observations: []crdv1alpha1.Observation{{Action: crdv1alpha1.ActionDelivered}}},
+		}
+		res, err := tfc.client.CrdV1alpha1().Traceflows().Update(context.TODO(), res, metav1.UpdateOptions{})
+		require.NoError(t, err)
+		_, err = tfc.waitForTraceflow("tf3", crdv1alpha1.Succeeded, time.Second)
+		assert.Error(t, err)
+		assert.Equal(t, numRunningTraceflows(), 1)
+
+		res.Status.ReplyResults = []crdv1alpha1.NodeResult{
+			{Observations: []crdv1alpha1.Observation{{Component: crdv1alpha1.ComponentSpoofGuard}}},
+			{Observations: []crdv1alpha1.Observation{{Action: crdv1alpha1.ActionDelivered}}},
+		}
+		tfc.client.CrdV1alpha1().Traceflows().Update(context.TODO(), res, metav1.UpdateOptions{})
+		res, _ = tfc.waitForTraceflow("tf3", crdv1alpha1.Succeeded, time.Second)
+		assert.NotNil(t, res)
+		assert.Equal(t, numRunningTraceflows(), 0)
+		tfc.client.CrdV1alpha1().Traceflows().Delete(context.TODO(), "tf3", metav1.DeleteOptions{})
+	})
+
 	t.Run("timeoutTraceflow", func(t *testing.T) {
 		startTime := time.Now()
 		tfc.client.CrdV1alpha1().Traceflows().Create(context.TODO(), &tf1, metav1.CreateOptions{})
@@ -148,7 +182,7 @@ func TestTraceflow(t *testing.T) {
 		assert.Equal(t, numRunningTraceflows(), 0)
 	})
 
-	t.Run("timeoutHostnetworkTraceflow", func(t *testing.T) {
+	t.Run("hostNetworkTraceflow", func(t *testing.T) {
 		tf2 := crdv1alpha1.Traceflow{
 			ObjectMeta: metav1.ObjectMeta{Name: "tf2", UID: "uid2"},
 			Spec: crdv1alpha1.TraceflowSpec{
@@ -162,17 +196,68 @@ func TestTraceflow(t *testing.T) {
 				Name:      "pod2",
 				Namespace: "ns1",
 			},
-			Spec: corev1.PodSpec{HostNetwork: true},
+			Spec: corev1.PodSpec{NodeName: "node1", HostNetwork: true},
 		}
 
 		tfc.kubeClient.CoreV1().Pods("ns1").Create(context.TODO(), &pod2, metav1.CreateOptions{})
 		createdPod, _ := tfc.waitForPodInNamespace("ns1", "pod2", time.Second)
 		require.NotNil(t, createdPod)
 		tfc.client.CrdV1alpha1().Traceflows().Create(context.TODO(), &tf2, metav1.CreateOptions{})
-		res, _ := tfc.waitForTraceflow("tf2", crdv1alpha1.Failed, time.Second)
+		res, _ := tfc.waitForTraceflow("tf2", crdv1alpha1.Running, time.Second)
 		require.NotNil(t, res)
-		// DataplaneTag should not be allocated by Controller.
-		assert.True(t, res.Status.DataplaneTag == 0)
+		// DataplaneTag should be allocated by Controller.
+		assert.True(t, res.Status.DataplaneTag > 0)
+		assert.Equal(t, "node1", res.Status.HostSenderNode)
+		assert.Equal(t, numRunningTraceflows(), 1)
+
+		// The packet is sent from the Node of the hostNetwork Pod, which
+		// reports the results of the sender.
+		res.Status.Results = []crdv1alpha1.NodeResult{
+			{Node: "node1", Observations: []crdv1alpha1.Observation{{Component: crdv1alpha1.ComponentSpoofGuard}}},
+			{Observations: []crdv1alpha1.Observation{{Action: crdv1alpha1.ActionDelivered}}},
+		}
+		tfc.client.CrdV1alpha1().Traceflows().Update(context.TODO(), res, metav1.UpdateOptions{})
+		res, _ = tfc.waitForTraceflow("tf2", crdv1alpha1.Succeeded, time.Second)
+		assert.NotNil(t, res)
+		assert.Equal(t, numRunningTraceflows(), 0)
+	})
+
+	t.Run("nodeSourceTraceflow", func(t *testing.T) {
+		tf4 := crdv1alpha1.Traceflow{
+			ObjectMeta: metav1.ObjectMeta{Name: "tf4", UID: "uid4"},
+			Spec: crdv1alpha1.TraceflowSpec{
+				Source:      crdv1alpha1.Source{Node: "node1"},
+				Destination: crdv1alpha1.Destination{Namespace: "ns2", Pod: "pod2"},
+				Timeout:     2, // 2 seconds timeout
+			},
+		}
+		node1 := corev1.Node{ObjectMeta: metav1.ObjectMeta{Name: "node1"}}
+		tfc.kubeClient.CoreV1().Nodes().Create(context.TODO(), &node1, metav1.CreateOptions{})
+		require.NoError(t, wait.PollImmediate(10*time.Millisecond, time.Second, func() (bool, error) {
+			_, err := tfc.nodeLister.Get("node1")
+			return err == nil, nil
+		}))
+		tfc.client.CrdV1alpha1().Traceflows().Create(context.TODO(), &tf4, metav1.CreateOptions{})
+		res, _ := tfc.waitForTraceflow("tf4", crdv1alpha1.Running, time.Second)
+		require.NotNil(t, res)
+		assert.Equal(t, "node1", res.Status.HostSenderNode)
+
+		// The results of the source Node are required, unlike for a
+		// live-traffic Traceflow without the source Pod.
+		res.Status.Results = []crdv1alpha1.NodeResult{
+			{Observations: []crdv1alpha1.Observation{{Action: crdv1alpha1.ActionDelivered}}},
+		}
+		res, err := tfc.client.CrdV1alpha1().Traceflows().Update(context.TODO(), res, metav1.UpdateOptions{})
+		require.NoError(t, err)
+		_, err = tfc.waitForTraceflow("tf4", crdv1alpha1.Succeeded, time.Second)
+		assert.Error(t, err)
+
+		res.Status.Results = append(res.Status.Results, crdv1alpha1.NodeResult{
+			Node: "node1", Observations: []crdv1alpha1.Observation{{Component: crdv1alpha1.ComponentSpoofGuard}},
+		})
+		tfc.client.CrdV1alpha1().Traceflows().Update(context.TODO(), res, metav1.UpdateOptions{})
+		res, _ = tfc.waitForTraceflow("tf4", crdv1alpha1.Succeeded, time.Second)
+		assert.NotNil(t, res)
 		assert.Equal(t, numRunningTraceflows(), 0)
 	})
 
@@ -295,3 +380,302 @@ func Test_podIPsIndexFunc(t *testing.T) {
 		})
 	}
 }
+
+func TestAllocateTag(t *testing.T) {
+	tfc := newController()
+	numTags := int((maxTagNum-minTagNum)/tagStep) + 1
+
+	// All the tags can be allocated to Traceflows involving the same Nodes.
+	nodes12 := sets.NewString("node1", "node2")
+	allocated := sets.NewInt()
+	for i := 0; i < numTags; i++ {
+		tag, err := tfc.allocateTag(fmt.Sprintf("tf-%d", i), nodes12)
+		require.NoError(t, err)
+		allocated.Insert(int(tag))
+	}
+	assert.Equal(t, numTags, allocated.Len())
+	_, err := tfc.allocateTag("tf-overlapping", sets.NewString("node2", "node3"))
+	assert.Error(t, err)
+	_, err = tfc.allocateTag("tf-all-nodes", nil)
+	assert.Error(t, err)
+
+	// The same tags can be allocated to Traceflows involving other Nodes.
+	tag, err := tfc.allocateTag("tf-other-nodes", sets.NewString("node3", "node4"))
+	require.NoError(t, err)
+	assert.Equal(t, minTagNum, tag)
+	// The Traceflow request has been processed already.
+	tag, err = tfc.allocateTag("tf-other-nodes", sets.NewString("node3", "node4"))
+	require.NoError(t, err)
+	assert.Zero(t, tag)
+
+	// A tag reserved on all Nodes can only be allocated if no other Traceflow
+	// uses it.
+	tfc.deallocateTag("tf-1", minTagNum+tagStep)
+	tag, err = tfc.allocateTag("tf-all-nodes", nil)
+	require.NoError(t, err)
+	assert.Equal(t, minTagNum+tagStep, tag)
+	_, err = tfc.allocateTag("tf-other-nodes-2", sets.NewString("node5"))
+	require.NoError(t, err)
+	tfc.deallocateTag("tf-all-nodes", minTagNum+tagStep)
+
+	// Tags of running Traceflows are loaded when the Controller starts.
+	tf := &crdv1alpha1.Traceflow{
+		ObjectMeta: metav1.ObjectMeta{Name: "tf-loaded"},
+		Status: crdv1alpha1.TraceflowStatus{
+			Phase:             crdv1alpha1.Running,
+			DataplaneTag:      minTagNum,
+			DataplaneTagNodes: []string{"node6"},
+		},
+	}
+	assert.NoError(t, tfc.occupyTag(tf))
+	tf.Name = "tf-loaded-conflict"
+	tf.Status.DataplaneTagNodes = []string{"node1"}
+	assert.Error(t, tfc.occupyTag(tf))
+}
+
+func TestGetTagNodes(t *testing.T) {
+	tfc := newController()
+	pods := []*corev1.Pod{
+		{
+			ObjectMeta: metav1.ObjectMeta{Name: "pod1", Namespace: "ns1"},
+			Spec:       corev1.PodSpec{NodeName: "node1"},
+			Status:     corev1.PodStatus{PodIPs: []corev1.PodIP{{IP: "10.10.0.1"}}},
+		},
+		{
+			ObjectMeta: metav1.ObjectMeta{Name: "pod2", Namespace: "ns2"},
+			Spec:       corev1.PodSpec{NodeName: "node2"},
+			Status:     corev1.PodStatus{PodIPs: []corev1.PodIP{{IP: "10.10.1.1"}}},
+		},
+		{
+			ObjectMeta: metav1.ObjectMeta{Name: "pod3", Namespace: "ns2"},
+			Spec:       corev1.PodSpec{NodeName: "node2", HostNetwork: true},
+			Status:     corev1.PodStatus{PodIPs: []corev1.PodIP{{IP: "192.168.0.2"}}},
+		},
+	}
+	for _, pod := range pods {
+		require.NoError(t, tfc.podInformer.Informer().GetIndexer().Add(pod))
+	}
+
+	for _, tc := range []struct {
+		name          string
+		spec          crdv1alpha1.TraceflowSpec
+		expectedNodes sets.String
+	}{
+		{
+			name: "Pod to Pod",
+			spec: crdv1alpha1.TraceflowSpec{
+				Source:      crdv1alpha1.Source{Namespace: "ns1", Pod: "pod1"},
+				Destination: crdv1alpha1.Destination{Namespace: "ns2", Pod: "pod2"},
+			},
+			expectedNodes: sets.NewString("node1", "node2"),
+		},
+		{
+			name: "Pod to Pod IP",
+			spec: crdv1alpha1.TraceflowSpec{
+				Source:      crdv1alpha1.Source{Namespace: "ns1", Pod: "pod1"},
+				Destination: crdv1alpha1.Destination{IP: "10.10.1.1"},
+			},
+			expectedNodes: sets.NewString("node1", "node2"),
+		},
+		{
+			name: "Pod to hostNetwork Pod IP",
+			spec: crdv1alpha1.TraceflowSpec{
+				Source:      crdv1alpha1.Source{Namespace: "ns1", Pod: "pod1"},
+				Destination: crdv1alpha1.Destination{IP: "192.168.0.2"},
+			},
+		},
+		{
+			name: "Pod to Service",
+			spec: crdv1alpha1.TraceflowSpec{
+				Source:      crdv1alpha1.Source{Namespace: "ns1", Pod: "pod1"},
+				Destination: crdv1alpha1.Destination{Namespace: "ns2", Service: "svc2"},
+			},
+		},
+		{
+			name: "unknown source Pod",
+			spec: crdv1alpha1.TraceflowSpec{
+				Source:      crdv1alpha1.Source{Namespace: "ns1", Pod: "pod4"},
+				Destination: crdv1alpha1.Destination{Namespace: "ns2", Pod: "pod2"},
+			},
+		},
+		{
+			name: "hostNetwork Pod to Pod",
+			spec: crdv1alpha1.TraceflowSpec{
+				Source:      crdv1alpha1.Source{Namespace: "ns2", Pod: "pod3"},
+				Destination: crdv1alpha1.Destination{Namespace: "ns1", Pod: "pod1"},
+			},
+			expectedNodes: sets.NewString("node1", "node2"),
+		},
+		{
+			name: "Node to Pod",
+			spec: crdv1alpha1.TraceflowSpec{
+				Source:      crdv1alpha1.Source{Node: "node3"},
+				Destination: crdv1alpha1.Destination{Namespace: "ns1", Pod: "pod1"},
+			},
+			expectedNodes: sets.NewString("node1", "node3"),
+		},
+		{
+			name: "external IP to Service",
+			spec: crdv1alpha1.TraceflowSpec{
+				Source:      crdv1alpha1.Source{Node: "node3", IP: "192.168.1.1"},
+				Destination: crdv1alpha1.Destination{Namespace: "ns2", Service: "svc2"},
+			},
+		},
+		{
+			name: "live traffic to destination Pod",
+			spec: crdv1alpha1.TraceflowSpec{
+				Destination: crdv1alpha1.Destination{Namespace: "ns2", Pod: "pod2"},
+				LiveTraffic: true,
+			},
+			expectedNodes: sets.NewString("node2"),
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			tf := &crdv1alpha1.Traceflow{Spec: tc.spec}
+			assert.Equal(t, tc.expectedNodes, tfc.getTagNodes(tf))
+		})
+	}
+}
+
+func TestValidateTraceflowSource(t *testing.T) {
+	tfc := newController()
+	pods := []*corev1.Pod{
+		{
+			ObjectMeta: metav1.ObjectMeta{Name: "pod1", Namespace: "ns1"},
+			Spec:       corev1.PodSpec{NodeName: "node1"},
+		},
+		{
+			ObjectMeta: metav1.ObjectMeta{Name: "pod2", Namespace: "ns1"},
+			Spec:       corev1.PodSpec{NodeName: "node2", HostNetwork: true},
+		},
+	}
+	for _, pod := range pods {
+		require.NoError(t, tfc.podInformer.Informer().GetIndexer().Add(pod))
+	}
+	node1 := &corev1.Node{ObjectMeta: metav1.ObjectMeta{Name: "node1"}}
+	require.NoError(t, tfc.informerFactory.Core().V1().Nodes().Informer().GetIndexer().Add(node1))
+
+	for _, tc := range []struct {
+		name        string
+		source      crdv1alpha1.Source
+		liveTraffic bool
+		expectedErr bool
+	}{
+		{name: "Pod", source: crdv1alpha1.Source{Namespace: "ns1", Pod: "pod1"}},
+		{name: "hostNetwork Pod", source: crdv1alpha1.Source{Namespace: "ns1", Pod: "pod2"}},
+		{name: "unknown Pod", source: crdv1alpha1.Source{Namespace: "ns1", Pod: "pod3"}, expectedErr: true},
+		{name: "Node", source: crdv1alpha1.Source{Node: "node1"}},
+		{name: "unknown Node", source: crdv1alpha1.Source{Node: "node2"}, expectedErr: true},
+		{name: "external IP", source: crdv1alpha1.Source{Node: "node1", IP: "192.168.1.1"}},
+		{name: "invalid external IP", source: crdv1alpha1.Source{Node: "node1", IP: "192.168.1"}, expectedErr: true},
+		{name: "external IP without Node", source: crdv1alpha1.Source{IP: "192.168.1.1"}, expectedErr: true},
+		{name: "Pod and Node", source: crdv1alpha1.Source{Namespace: "ns1", Pod: "pod1", Node: "node1"}, expectedErr: true},
+		{name: "no source", expectedErr: true},
+		{name: "live-traffic IP", source: crdv1alpha1.Source{IP: "192.168.1.1"}, liveTraffic: true},
+		{name: "live-traffic Node", source: crdv1alpha1.Source{Node: "node1"}, liveTraffic: true, expectedErr: true},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			tf := &crdv1alpha1.Traceflow{
+				ObjectMeta: metav1.ObjectMeta{Name: "tf"},
+				Spec: crdv1alpha1.TraceflowSpec{
+					Source:      tc.source,
+					Destination: crdv1alpha1.Destination{Namespace: "ns1", Pod: "pod1"},
+					LiveTraffic: tc.liveTraffic,
+				},
+			}
+			err := tfc.validateTraceflow(tf)
+			if tc.expectedErr {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
+
+func TestValidateBidirectionalTraceflow(t *testing.T) {
+	tfc := newController()
+	pods := []*corev1.Pod{
+		{
+			ObjectMeta: metav1.ObjectMeta{Name: "pod1", Namespace: "ns1"},
+			Spec:       corev1.PodSpec{NodeName: "node1"},
+			Status:     corev1.PodStatus{PodIPs: []corev1.PodIP{{IP: "10.10.0.1"}}},
+		},
+		{
+			ObjectMeta: metav1.ObjectMeta{Name: "pod2", Namespace: "ns1"},
+			Spec:       corev1.PodSpec{NodeName: "node2"},
+			Status:     corev1.PodStatus{PodIPs: []corev1.PodIP{{IP: "10.10.1.1"}}},
+		},
+	}
+	for _, pod := range pods {
+		require.NoError(t, tfc.podInformer.Informer().GetIndexer().Add(pod))
+	}
+	nextHeaderTCP := protocolTCP
+
+	for _, tc := range []struct {
+		name        string
+		spec        crdv1alpha1.TraceflowSpec
+		expectedErr bool
+	}{
+		{
+			name: "ICMP by default",
+			spec: crdv1alpha1.TraceflowSpec{
+				Destination: crdv1alpha1.Destination{Namespace: "ns1", Pod: "pod2"},
+			},
+		},
+		{
+			name: "TCP to Pod IP",
+			spec: crdv1alpha1.TraceflowSpec{
+				Destination: crdv1alpha1.Destination{IP: "10.10.1.1"},
+				Packet: crdv1alpha1.Packet{
+					TransportHeader: crdv1alpha1.TransportHeader{TCP: &crdv1alpha1.TCPHeader{DstPort: 80, Flags: 2}},
+				},
+			},
+		},
+		{
+			name: "IPv6 TCP",
+			spec: crdv1alpha1.TraceflowSpec{
+				Destination: crdv1alpha1.Destination{Namespace: "ns1", Service: "svc1"},
+				Packet:      crdv1alpha1.Packet{IPv6Header: &crdv1alpha1.IPv6Header{NextHeader: &nextHeaderTCP}},
+			},
+		},
+		{
+			name: "SCTP",
+			spec: crdv1alpha1.TraceflowSpec{
+				Destination: crdv1alpha1.Destination{Namespace: "ns1", Pod: "pod2"},
+				Packet:      crdv1alpha1.Packet{IPHeader: crdv1alpha1.IPHeader{Protocol: 132}},
+			},
+			expectedErr: true,
+		},
+		{
+			name: "non-Pod IP",
+			spec: crdv1alpha1.TraceflowSpec{
+				Destination: crdv1alpha1.Destination{IP: "8.8.8.8"},
+			},
+			expectedErr: true,
+		},
+		{
+			name: "live traffic",
+			spec: crdv1alpha1.TraceflowSpec{
+				Destination: crdv1alpha1.Destination{Namespace: "ns1", Pod: "pod2"},
+				LiveTraffic: true,
+			},
+			expectedErr: true,
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			tf := &crdv1alpha1.Traceflow{
+				ObjectMeta: metav1.ObjectMeta{Name: "tf"},
+				Spec:       tc.spec,
+			}
+			tf.Spec.Source = crdv1alpha1.Source{Namespace: "ns1", Pod: "pod1"}
+			tf.Spec.Bidirectional = true
+			err := tfc.validateTraceflow(tf)
+			if tc.expectedErr {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
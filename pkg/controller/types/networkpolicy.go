@@ -100,6 +100,8 @@ type NetworkPolicy struct {
 	// TierPriority represents the priority of the Tier associated with this Network
 	// Policy.
 	TierPriority *int32
+	// Tier is the name of the Tier associated with this Network Policy.
+	Tier string
 	// AppliedToPerRule tracks if appliedTo is set per rule basis rather than in policy spec.
 	// Must be false for K8s NetworkPolicy.
 	AppliedToPerRule bool
@@ -112,6 +112,22 @@ const (
 	// alpha: v1.8
 	// Enable running agent on an unmanaged VM/BM.
 	ExternalNode featuregate.Feature = "ExternalNode"
+
+	// alpha: v1.9
+	// Enable Direct Server Return (DSR) mode for the LoadBalancer Services annotated with it in AntreaProxy.
+	LoadBalancerModeDSR featuregate.Feature = "LoadBalancerModeDSR"
+
+	// alpha: v1.9
+	// Enable capturing the packets of Pods to pcapng files on their Nodes.
+	PacketCapture featuregate.Feature = "PacketCapture"
+
+	// alpha: v1.9
+	// Enable probing the connectivity between Pods or Services periodically with Traceflow.
+	ConnectivityProbe featuregate.Feature = "ConnectivityProbe"
+
+	// alpha: v1.9
+	// Enable exporting the HTTP and DNS information of the connections of the Pods annotated with it.
+	L7FlowExporter featuregate.Feature = "L7FlowExporter"
 )
 
 var (
@@ -126,24 +142,28 @@ var (
 	// To add a new feature, define a key for it above and add it here. The features will be
 	// available throughout Antrea binaries.
 	DefaultAntreaFeatureGates = map[featuregate.Feature]featuregate.FeatureSpec{
-		AntreaPolicy:       {Default: true, PreRelease: featuregate.Beta},
-		AntreaProxy:        {Default: true, PreRelease: featuregate.Beta},
-		Egress:             {Default: true, PreRelease: featuregate.Beta},
-		EndpointSlice:      {Default: false, PreRelease: featuregate.Alpha},
-		TopologyAwareHints: {Default: false, PreRelease: featuregate.Alpha},
-		Traceflow:          {Default: true, PreRelease: featuregate.Beta},
-		AntreaIPAM:         {Default: false, PreRelease: featuregate.Alpha},
-		FlowExporter:       {Default: false, PreRelease: featuregate.Alpha},
-		NetworkPolicyStats: {Default: true, PreRelease: featuregate.Beta},
-		NodePortLocal:      {Default: true, PreRelease: featuregate.Beta},
-		NodeIPAM:           {Default: false, PreRelease: featuregate.Alpha},
-		Multicast:          {Default: false, PreRelease: featuregate.Alpha},
-		Multicluster:       {Default: false, PreRelease: featuregate.Alpha},
-		SecondaryNetwork:   {Default: false, PreRelease: featuregate.Alpha},
-		ServiceExternalIP:  {Default: false, PreRelease: featuregate.Alpha},
-		TrafficControl:     {Default: false, PreRelease: featuregate.Alpha},
-		IPsecCertAuth:      {Default: false, PreRelease: featuregate.Alpha},
-		ExternalNode:       {Default: false, PreRelease: featuregate.Alpha},
+		AntreaPolicy:        {Default: true, PreRelease: featuregate.Beta},
+		AntreaProxy:         {Default: true, PreRelease: featuregate.Beta},
+		Egress:              {Default: true, PreRelease: featuregate.Beta},
+		EndpointSlice:       {Default: false, PreRelease: featuregate.Alpha},
+		TopologyAwareHints:  {Default: false, PreRelease: featuregate.Alpha},
+		Traceflow:           {Default: true, PreRelease: featuregate.Beta},
+		AntreaIPAM:          {Default: false, PreRelease: featuregate.Alpha},
+		FlowExporter:        {Default: false, PreRelease: featuregate.Alpha},
+		NetworkPolicyStats:  {Default: true, PreRelease: featuregate.Beta},
+		NodePortLocal:       {Default: true, PreRelease: featuregate.Beta},
+		NodeIPAM:            {Default: false, PreRelease: featuregate.Alpha},
+		Multicast:           {Default: false, PreRelease: featuregate.Alpha},
+		Multicluster:        {Default: false, PreRelease: featuregate.Alpha},
+		SecondaryNetwork:    {Default: false, PreRelease: featuregate.Alpha},
+		ServiceExternalIP:   {Default: false, PreRelease: featuregate.Alpha},
+		TrafficControl:      {Default: false, PreRelease: featuregate.Alpha},
+		IPsecCertAuth:       {Default: false, PreRelease: featuregate.Alpha},
+		ExternalNode:        {Default: false, PreRelease: featuregate.Alpha},
+		LoadBalancerModeDSR: {Default: false, PreRelease: featuregate.Alpha},
+		PacketCapture:       {Default: false, PreRelease: featuregate.Alpha},
+		ConnectivityProbe:   {Default: false, PreRelease: featuregate.Alpha},
+		L7FlowExporter:      {Default: false, PreRelease: featuregate.Alpha},
 	}
 
 	// UnsupportedFeaturesOnWindows records the features not supported on
@@ -157,12 +177,15 @@ var (
 	// can have different FeatureSpecs between Linux and Windows, we should
 	// still define a separate defaultAntreaFeatureGates map for Windows.
 	unsupportedFeaturesOnWindows = map[featuregate.Feature]struct{}{
-		Egress:            {},
-		AntreaIPAM:        {},
-		Multicast:         {},
-		SecondaryNetwork:  {},
-		ServiceExternalIP: {},
-		IPsecCertAuth:     {},
+		Egress:              {},
+		AntreaIPAM:          {},
+		Multicast:           {},
+		SecondaryNetwork:    {},
+		ServiceExternalIP:   {},
+		IPsecCertAuth:       {},
+		LoadBalancerModeDSR: {},
+		PacketCapture:       {},
+		L7FlowExporter:      {},
 		// Multicluster feature is not validated on Windows yet. This can removed
 		// in the future if it's fully tested on Windows.
 		Multicluster: {},
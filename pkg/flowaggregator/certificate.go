@@ -35,27 +35,44 @@ import (
 )
 
 const (
-	CAConfigMapName       = "flow-aggregator-ca"
-	CAConfigMapKey        = "ca.crt"
-	CAConfigMapNamespace  = "flow-aggregator"
+	CAConfigMapName      = "flow-aggregator-ca"
+	CAConfigMapKey       = "ca.crt"
+	CAConfigMapNamespace = "flow-aggregator"
+	// The CA certificate and key are stored in a Secret, so that all the
+	// Flow Aggregator replicas sign their server certificate with the same
+	// CA. The Secret has the same name as the ConfigMap which exposes the CA
+	// certificate to the Flow Exporters.
+	// #nosec G101: false positive triggered by variable name which includes "Secret"
+	CASecretName          = "flow-aggregator-ca"
+	CASecretNamespace     = "flow-aggregator"
 	ClientSecretNamespace = "flow-aggregator"
 	// #nosec G101: false positive triggered by variable name which includes "Secret"
 	ClientSecretName = "flow-aggregator-client-tls"
 )
 
 var (
-	validFrom = time.Now().Add(-time.Hour) // valid an hour earlier to avoid flakes due to clock skew
-	maxAge    = time.Hour * 24 * 365       // one year self-signed certs
+	maxAge = time.Hour * 24 * 365 // one year self-signed certs
+	// The shared CA is replaced when it expires within caRenewBefore.
+	caRenewBefore = time.Hour * 24 * 30
 )
 
+// validFrom returns the start of the validity period of a new certificate. The
+// certificate is valid an hour earlier to avoid flakes due to clock skew. It is
+// computed for each certificate, as the CA can be renewed long after the Flow
+// Aggregator has started.
+func validFrom() time.Time {
+	return time.Now().Add(-time.Hour)
+}
+
 func generateCACertKey() (*x509.Certificate, *rsa.PrivateKey, []byte, error) {
+	notBefore := validFrom()
 	cert := &x509.Certificate{
 		SerialNumber: big.NewInt(1),
 		Subject: pkix.Name{
 			CommonName: fmt.Sprintf("flow-aggregator-ca@%d", time.Now().Unix()),
 		},
-		NotBefore:             validFrom,
-		NotAfter:              validFrom.Add(maxAge),
+		NotBefore:             notBefore,
+		NotAfter:              notBefore.Add(maxAge),
 		IsCA:                  true,
 		ExtKeyUsage:           []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth, x509.ExtKeyUsageServerAuth},
 		KeyUsage:              x509.KeyUsageDigitalSignature | x509.KeyUsageCertSign,
@@ -80,16 +97,110 @@ func generateCACertKey() (*x509.Certificate, *rsa.PrivateKey, []byte, error) {
 	return cert, caKey, caPEM.Bytes(), err
 }
 
-func generateCertKey(caCert *x509.Certificate, caKey *rsa.PrivateKey, isServer bool, flowAggregatorAddress string) ([]byte, []byte, error) {
+func encodeCAKey(caKey *rsa.PrivateKey) []byte {
+	caKeyPEM := new(bytes.Buffer)
+	pem.Encode(caKeyPEM, &pem.Block{
+		Type:  "RSA PRIVATE KEY",
+		Bytes: x509.MarshalPKCS1PrivateKey(caKey),
+	})
+	return caKeyPEM.Bytes()
+}
+
+func parseCACertKey(secret *v1.Secret) (*x509.Certificate, *rsa.PrivateKey, []byte, error) {
+	caPEM := secret.Data[v1.TLSCertKey]
+	certBlock, _ := pem.Decode(caPEM)
+	if certBlock == nil {
+		return nil, nil, nil, fmt.Errorf("no PEM data found in %s", v1.TLSCertKey)
+	}
+	caCert, err := x509.ParseCertificate(certBlock.Bytes)
+	if err != nil {
+		return nil, nil, nil, fmt.Errorf("error parsing CA certificate: %v", err)
+	}
+	keyBlock, _ := pem.Decode(secret.Data[v1.TLSPrivateKeyKey])
+	if keyBlock == nil {
+		return nil, nil, nil, fmt.Errorf("no PEM data found in %s", v1.TLSPrivateKeyKey)
+	}
+	caKey, err := x509.ParsePKCS1PrivateKey(keyBlock.Bytes)
+	if err != nil {
+		return nil, nil, nil, fmt.Errorf("error parsing CA key: %v", err)
+	}
+	return caCert, caKey, caPEM, nil
+}
+
+// getOrCreateCACertKey returns the CA shared by all the Flow Aggregator
+// replicas. The first replica to start generates the CA and stores it in a
+// Secret, and the other replicas load it from the Secret. The API server
+// arbitrates concurrent creations and updates of the Secret.
+func getOrCreateCACertKey(k8sClient kubernetes.Interface) (*x509.Certificate, *rsa.PrivateKey, []byte, error) {
+	const maxAttempts = 3
+	for i := 0; i < maxAttempts; i++ {
+		secret, err := k8sClient.CoreV1().Secrets(CASecretNamespace).Get(context.TODO(), CASecretName, metav1.GetOptions{})
+		exists := true
+		if err != nil {
+			if !errors.IsNotFound(err) {
+				return nil, nil, nil, fmt.Errorf("error getting Secret %s: %v", CASecretName, err)
+			}
+			exists = false
+		} else {
+			caCert, caKey, caPEM, err := parseCACertKey(secret)
+			if err == nil && time.Now().Add(caRenewBefore).Before(caCert.NotAfter) {
+				klog.InfoS("Loaded CA certificate from Secret", "secret", klog.KObj(secret))
+				return caCert, caKey, caPEM, nil
+			}
+			if err != nil {
+				klog.ErrorS(err, "Invalid CA certificate in Secret, generating a new one", "secret", klog.KObj(secret))
+			} else {
+				klog.InfoS("CA certificate in Secret is about to expire, generating a new one", "secret", klog.KObj(secret))
+			}
+		}
+		caCert, caKey, caPEM, err := generateCACertKey()
+		if err != nil {
+			return nil, nil, nil, err
+		}
+		data := map[string][]byte{
+			v1.TLSCertKey:       caPEM,
+			v1.TLSPrivateKeyKey: encodeCAKey(caKey),
+		}
+		if exists {
+			secret.Data = data
+			_, err = k8sClient.CoreV1().Secrets(CASecretNamespace).Update(context.TODO(), secret, metav1.UpdateOptions{})
+		} else {
+			secret = &v1.Secret{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      CASecretName,
+					Namespace: CASecretNamespace,
+					Labels: map[string]string{
+						"app": "flow-aggregator",
+					},
+				},
+				Type: v1.SecretTypeTLS,
+				Data: data,
+			}
+			_, err = k8sClient.CoreV1().Secrets(CASecretNamespace).Create(context.TODO(), secret, metav1.CreateOptions{})
+		}
+		if err == nil {
+			return caCert, caKey, caPEM, nil
+		}
+		// Another replica created or updated the Secret concurrently, use its CA.
+		if errors.IsAlreadyExists(err) || errors.IsConflict(err) {
+			continue
+		}
+		return nil, nil, nil, fmt.Errorf("error storing CA certificate in Secret %s: %v", CASecretName, err)
+	}
+	return nil, nil, nil, fmt.Errorf("failed to store CA certificate in Secret %s after %d attempts", CASecretName, maxAttempts)
+}
+
+func generateCertKey(caCert *x509.Certificate, caKey *rsa.PrivateKey, isServer bool, flowAggregatorAddress string, podIP net.IP) ([]byte, []byte, error) {
 	var cert *x509.Certificate
+	notBefore := validFrom()
 	if isServer {
 		cert = &x509.Certificate{
 			SerialNumber: big.NewInt(2),
 			Subject: pkix.Name{
 				CommonName: fmt.Sprintf("flow-aggregator-server-certificate@%d", time.Now().Unix()),
 			},
-			NotBefore:   validFrom,
-			NotAfter:    validFrom.Add(maxAge),
+			NotBefore:   notBefore,
+			NotAfter:    notBefore.Add(maxAge),
 			ExtKeyUsage: []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth},
 			KeyUsage:    x509.KeyUsageDigitalSignature,
 		}
@@ -104,14 +215,19 @@ func generateCertKey(caCert *x509.Certificate, caKey *rsa.PrivateKey, isServer b
 			}
 			cert.IPAddresses = flowAggregatorIPs
 		}
+		// Flow Exporters connect to each replica using its Pod IP when the
+		// Flow Aggregator is deployed with multiple replicas.
+		if podIP != nil {
+			cert.IPAddresses = append(cert.IPAddresses, podIP)
+		}
 	} else {
 		cert = &x509.Certificate{
 			SerialNumber: big.NewInt(3),
 			Subject: pkix.Name{
 				CommonName: fmt.Sprintf("flow-aggregator-client-certificate@%d", time.Now().Unix()),
 			},
-			NotBefore:   validFrom,
-			NotAfter:    validFrom.Add(maxAge),
+			NotBefore:   notBefore,
+			NotAfter:    notBefore.Add(maxAge),
 			ExtKeyUsage: []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth},
 			KeyUsage:    x509.KeyUsageDigitalSignature,
 		}
@@ -142,6 +258,56 @@ func generateCertKey(caCert *x509.Certificate, caKey *rsa.PrivateKey, isServer b
 	return certPEM.Bytes(), certKeyPEM.Bytes(), nil
 }
 
+// publishCACert publishes the CA shared by all the Flow Aggregator replicas to
+// the Flow Exporters, in the CA ConfigMap, together with a client certificate
+// signed by this CA. The CA is renewed first if it is about to expire. It is run
+// periodically by the leader of the replicas, and does nothing if the published
+// CA and client certificate are up-to-date.
+func publishCACert(k8sClient kubernetes.Interface) error {
+	caCert, caKey, caPEM, err := getOrCreateCACertKey(k8sClient)
+	if err != nil {
+		return fmt.Errorf("error when getting CA certificate: %v", err)
+	}
+	if isCACertPublished(k8sClient, caPEM) {
+		return nil
+	}
+	clientCert, clientKey, err := generateCertKey(caCert, caKey, false, "", nil)
+	if err != nil {
+		return fmt.Errorf("error when creating client certificate: %v", err)
+	}
+	return syncCAAndClientCert(caPEM, clientCert, clientKey, k8sClient)
+}
+
+// isCACertPublished returns true if the CA ConfigMap contains the given CA
+// certificate, and the client certificate is signed by this CA.
+func isCACertPublished(k8sClient kubernetes.Interface, caPEM []byte) bool {
+	caConfigMap, err := k8sClient.CoreV1().ConfigMaps(CAConfigMapNamespace).Get(context.TODO(), CAConfigMapName, metav1.GetOptions{})
+	if err != nil || caConfigMap.Data[CAConfigMapKey] != string(caPEM) {
+		return false
+	}
+	secret, err := k8sClient.CoreV1().Secrets(ClientSecretNamespace).Get(context.TODO(), ClientSecretName, metav1.GetOptions{})
+	if err != nil {
+		return false
+	}
+	block, _ := pem.Decode(secret.Data[v1.TLSCertKey])
+	if block == nil {
+		return false
+	}
+	clientCert, err := x509.ParseCertificate(block.Bytes)
+	if err != nil {
+		return false
+	}
+	roots := x509.NewCertPool()
+	if !roots.AppendCertsFromPEM(caPEM) {
+		return false
+	}
+	_, err = clientCert.Verify(x509.VerifyOptions{
+		Roots:     roots,
+		KeyUsages: []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth},
+	})
+	return err == nil
+}
+
 func syncCAAndClientCert(caCert, clientCert, clientKey []byte, k8sClient kubernetes.Interface) error {
 	klog.Info("Syncing CA certificate, client certificate and client key with ConfigMap")
 	caConfigMap, err := k8sClient.CoreV1().ConfigMaps(CAConfigMapNamespace).Get(context.TODO(), CAConfigMapName, metav1.GetOptions{})
@@ -0,0 +1,105 @@
+// Copyright 2022 Antrea Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package flowaggregator
+
+import (
+	"context"
+	"crypto/x509"
+	"encoding/pem"
+	"net"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+func TestGetOrCreateCACertKey(t *testing.T) {
+	client := fake.NewSimpleClientset()
+	caCert, caKey, caPEM, err := getOrCreateCACertKey(client)
+	require.NoError(t, err)
+	secret, err := client.CoreV1().Secrets(CASecretNamespace).Get(context.TODO(), CASecretName, metav1.GetOptions{})
+	require.NoError(t, err)
+	assert.Equal(t, v1.SecretTypeTLS, secret.Type)
+	assert.Equal(t, caPEM, secret.Data[v1.TLSCertKey])
+
+	// Other replicas use the CA stored in the Secret.
+	caCert2, caKey2, caPEM2, err := getOrCreateCACertKey(client)
+	require.NoError(t, err)
+	assert.Equal(t, caPEM, caPEM2)
+	assert.True(t, caKey.Equal(caKey2))
+	assert.Equal(t, caCert.Subject.CommonName, caCert2.Subject.CommonName)
+
+	// Certificates signed by any replica are trusted with the shared CA.
+	serverCert, _, err := generateCertKey(caCert2, caKey2, true, "192.168.1.10", net.ParseIP("10.10.0.5"))
+	require.NoError(t, err)
+	block, _ := pem.Decode(serverCert)
+	require.NotNil(t, block)
+	cert, err := x509.ParseCertificate(block.Bytes)
+	require.NoError(t, err)
+	roots := x509.NewCertPool()
+	require.True(t, roots.AppendCertsFromPEM(caPEM))
+	_, err = cert.Verify(x509.VerifyOptions{Roots: roots, DNSName: "10.10.0.5"})
+	assert.NoError(t, err)
+	_, err = cert.Verify(x509.VerifyOptions{Roots: roots, DNSName: "192.168.1.10"})
+	assert.NoError(t, err)
+
+	// An invalid CA is replaced.
+	secret.Data[v1.TLSPrivateKeyKey] = []byte("invalid")
+	_, err = client.CoreV1().Secrets(CASecretNamespace).Update(context.TODO(), secret, metav1.UpdateOptions{})
+	require.NoError(t, err)
+	_, _, caPEM3, err := getOrCreateCACertKey(client)
+	require.NoError(t, err)
+	assert.NotEqual(t, caPEM, caPEM3)
+	secret, err = client.CoreV1().Secrets(CASecretNamespace).Get(context.TODO(), CASecretName, metav1.GetOptions{})
+	require.NoError(t, err)
+	assert.Equal(t, caPEM3, secret.Data[v1.TLSCertKey])
+}
+
+func TestPublishCACert(t *testing.T) {
+	client := fake.NewSimpleClientset()
+	require.NoError(t, publishCACert(client))
+	caSecret, err := client.CoreV1().Secrets(CASecretNamespace).Get(context.TODO(), CASecretName, metav1.GetOptions{})
+	require.NoError(t, err)
+	caPEM := caSecret.Data[v1.TLSCertKey]
+	assert.True(t, isCACertPublished(client, caPEM))
+	clientSecret, err := client.CoreV1().Secrets(ClientSecretNamespace).Get(context.TODO(), ClientSecretName, metav1.GetOptions{})
+	require.NoError(t, err)
+
+	// The client certificate is not reissued if the CA has not changed.
+	require.NoError(t, publishCACert(client))
+	clientSecret2, err := client.CoreV1().Secrets(ClientSecretNamespace).Get(context.TODO(), ClientSecretName, metav1.GetOptions{})
+	require.NoError(t, err)
+	assert.Equal(t, clientSecret.Data, clientSecret2.Data)
+
+	// A new CA, e.g. renewed by another replica, is published with a new
+	// client certificate.
+	_, caKey, newCAPEM, err := generateCACertKey()
+	require.NoError(t, err)
+	caSecret.Data = map[string][]byte{
+		v1.TLSCertKey:       newCAPEM,
+		v1.TLSPrivateKeyKey: encodeCAKey(caKey),
+	}
+	_, err = client.CoreV1().Secrets(CASecretNamespace).Update(context.TODO(), caSecret, metav1.UpdateOptions{})
+	require.NoError(t, err)
+	assert.False(t, isCACertPublished(client, newCAPEM))
+	require.NoError(t, publishCACert(client))
+	assert.True(t, isCACertPublished(client, newCAPEM))
+	caConfigMap, err := client.CoreV1().ConfigMaps(CAConfigMapNamespace).Get(context.TODO(), CAConfigMapName, metav1.GetOptions{})
+	require.NoError(t, err)
+	assert.Equal(t, string(newCAPEM), caConfigMap.Data[CAConfigMapKey])
+}
@@ -24,7 +24,6 @@ import (
 
 	"github.com/ClickHouse/clickhouse-go"
 	"github.com/gammazero/deque"
-	ipfixentities "github.com/vmware/go-ipfix/pkg/entities"
 	"k8s.io/apimachinery/pkg/util/wait"
 	"k8s.io/klog/v2"
 
@@ -82,9 +81,19 @@ const (
                    throughputFromDestinationNode,
                    reverseThroughputFromSourceNode,
                    reverseThroughputFromDestinationNode,
-                   clusterUUID)
+                   clusterUUID,
+                   sourceEndpointKind,
+                   destinationEndpointKind,
+                   egressName,
+                   egressIP,
+                   egressNodeName,
+                   appProtocolName,
+                   httpVals,
+                   dnsQueryName,
+                   dnsResponseCode)
                    VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, 
-                           ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`
+                           ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?,
+                           ?, ?, ?, ?, ?, ?, ?)`
 )
 
 // PrepareClickHouseConnection is used for unit testing
@@ -171,9 +180,7 @@ func NewClickHouseClient(input ClickHouseInput, clusterUUID string) (*ClickHouse
 	return chClient, nil
 }
 
-func (ch *ClickHouseExportProcess) CacheRecord(record ipfixentities.Record) {
-	chRow := flowrecord.GetFlowRecord(record)
-
+func (ch *ClickHouseExportProcess) CacheRecord(chRow *flowrecord.FlowRecord) {
 	ch.dequeMutex.Lock()
 	defer ch.dequeMutex.Unlock()
 	for ch.deque.Len() >= ch.queueSize {
@@ -343,7 +350,16 @@ func (ch *ClickHouseExportProcess) batchCommitAll(ctx context.Context) (int, err
 			record.ThroughputFromDestinationNode,
 			record.ReverseThroughputFromSourceNode,
 			record.ReverseThroughputFromDestinationNode,
-			ch.clusterUUID)
+			ch.clusterUUID,
+			string(record.SourceEndpointKind),
+			string(record.DestinationEndpointKind),
+			record.EgressName,
+			record.EgressIP,
+			record.EgressNodeName,
+			record.AppProtocolName,
+			record.HttpVals,
+			record.DNSQueryName,
+			record.DNSResponseCode)
 
 		if err != nil {
 			klog.ErrorS(err, "Error when adding record")
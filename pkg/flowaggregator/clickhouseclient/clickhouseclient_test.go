@@ -24,21 +24,21 @@ import (
 
 	"github.com/DATA-DOG/go-sqlmock"
 	"github.com/gammazero/deque"
-	"github.com/golang/mock/gomock"
 	"github.com/google/uuid"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 	ipfixentitiestesting "github.com/vmware/go-ipfix/pkg/entities/testing"
-	"github.com/vmware/go-ipfix/pkg/registry"
+	"go.uber.org/mock/gomock"
 	"k8s.io/apimachinery/pkg/util/wait"
 
 	"antrea.io/antrea/pkg/flowaggregator/flowrecord"
 	flowrecordtesting "antrea.io/antrea/pkg/flowaggregator/flowrecord/testing"
 	flowaggregatortesting "antrea.io/antrea/pkg/flowaggregator/testing"
+	"antrea.io/antrea/pkg/ipfix"
 )
 
 func init() {
-	registry.LoadRegistry()
+	ipfix.LoadRegistry()
 }
 
 var fakeClusterUUID = uuid.New().String()
@@ -88,14 +88,14 @@ func TestCacheRecord(t *testing.T) {
 	// First call. only populate row.
 	mockRecord := ipfixentitiestesting.NewMockRecord(ctrl)
 	flowaggregatortesting.PrepareMockIpfixRecord(mockRecord, true)
-	chExportProc.CacheRecord(mockRecord)
+	chExportProc.CacheRecord(flowrecord.GetFlowRecord(mockRecord))
 	assert.Equal(t, 1, chExportProc.deque.Len())
 	assert.Equal(t, "10.10.0.79", chExportProc.deque.At(0).(*flowrecord.FlowRecord).SourceIP)
 
 	// Second call. discard prev row and add new row.
 	mockRecord = ipfixentitiestesting.NewMockRecord(ctrl)
 	flowaggregatortesting.PrepareMockIpfixRecord(mockRecord, false)
-	chExportProc.CacheRecord(mockRecord)
+	chExportProc.CacheRecord(flowrecord.GetFlowRecord(mockRecord))
 	assert.Equal(t, 1, chExportProc.deque.Len())
 	assert.Equal(t, "2001:0:3238:dfe1:63::fefb", chExportProc.deque.At(0).(*flowrecord.FlowRecord).SourceIP)
 }
@@ -166,7 +166,16 @@ func TestBatchCommitAll(t *testing.T) {
 			15902813474,
 			12381345,
 			12381346,
-			fakeClusterUUID).
+			fakeClusterUUID,
+			"Pod",
+			"Pod",
+			"",
+			"",
+			"",
+			"http",
+			`{"0":"{\"hostname\":\"perftest-b\",\"url\":\"/\",\"http_method\":\"GET\",\"protocol\":\"HTTP/1.1\",\"status\":200}"}`,
+			"",
+			0).
 		WillReturnResult(sqlmock.NewResult(0, 1))
 	mock.ExpectCommit()
 
@@ -22,6 +22,7 @@ import (
 	"k8s.io/klog/v2"
 
 	"antrea.io/antrea/pkg/flowaggregator/clickhouseclient"
+	"antrea.io/antrea/pkg/flowaggregator/flowrecord"
 	"antrea.io/antrea/pkg/flowaggregator/options"
 )
 
@@ -59,8 +60,8 @@ func NewClickHouseExporter(k8sClient kubernetes.Interface, opt *options.Options)
 	}, nil
 }
 
-func (e *ClickHouseExporter) AddRecord(record ipfixentities.Record, isRecordIPv6 bool) error {
-	e.chExportProcess.CacheRecord(record)
+func (e *ClickHouseExporter) AddRecord(record ipfixentities.Record, flowRecord *flowrecord.FlowRecord, isRecordIPv6 bool) error {
+	e.chExportProcess.CacheRecord(flowRecord)
 	return nil
 }
 
@@ -0,0 +1,182 @@
+// Copyright 2022 Antrea Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package exporter
+
+import (
+	"fmt"
+	"path/filepath"
+	"reflect"
+	"sync"
+	"time"
+
+	ipfixentities "github.com/vmware/go-ipfix/pkg/entities"
+	"gopkg.in/natefinch/lumberjack.v2"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/klog/v2"
+
+	flowaggregatorconfig "antrea.io/antrea/pkg/config/flowaggregator"
+	"antrea.io/antrea/pkg/flowaggregator/flowrecord"
+	"antrea.io/antrea/pkg/flowaggregator/options"
+)
+
+// FileExporter writes flow records to local files, which are rotated based on
+// their size and optionally on their age. Rotated files are managed by
+// lumberjack, which also takes care of compressing and removing them.
+type FileExporter struct {
+	config           flowaggregatorconfig.FileExporterConfig
+	rotationInterval time.Duration
+	clusterUUID      string
+	// mutex protects logger and written, as time-based rotations are
+	// triggered from a separate goroutine.
+	mutex  sync.Mutex
+	logger *lumberjack.Logger
+	// written indicates whether records have been written to the current file,
+	// to avoid rotating empty files.
+	written bool
+	buf     []byte
+	stopCh  chan struct{}
+	wg      sync.WaitGroup
+}
+
+func fileExporterFileName(config *flowaggregatorconfig.FileExporterConfig) string {
+	extension := "csv"
+	if config.RecordFormat == "JSON" {
+		extension = "json"
+	}
+	return filepath.Join(config.Path, "flows."+extension)
+}
+
+func newFileLogger(config *flowaggregatorconfig.FileExporterConfig) *lumberjack.Logger {
+	return &lumberjack.Logger{
+		Filename:   fileExporterFileName(config),
+		MaxSize:    int(config.MaxFileSize),
+		MaxBackups: int(config.MaxBackups),
+		MaxAge:     int(config.MaxAge),
+		Compress:   *config.Compress,
+	}
+}
+
+func logFileExporterConfig(msg string, config *flowaggregatorconfig.FileExporterConfig, rotationInterval time.Duration) {
+	klog.InfoS(msg, "path", config.Path, "recordFormat", config.RecordFormat, "compress", *config.Compress, "maxFileSize", config.MaxFileSize, "rotationInterval", rotationInterval, "maxBackups", config.MaxBackups, "maxAge", config.MaxAge)
+}
+
+func NewFileExporter(k8sClient kubernetes.Interface, opt *options.Options) (*FileExporter, error) {
+	config := opt.Config.FileExporter
+	logFileExporterConfig("File exporter configuration", &config, opt.FileExporterRotationInterval)
+	clusterUUID, err := getClusterUUID(k8sClient)
+	if err != nil {
+		return nil, err
+	}
+	return newFileExporter(&config, opt.FileExporterRotationInterval, clusterUUID.String()), nil
+}
+
+func newFileExporter(config *flowaggregatorconfig.FileExporterConfig, rotationInterval time.Duration, clusterUUID string) *FileExporter {
+	return &FileExporter{
+		config:           *config,
+		rotationInterval: rotationInterval,
+		clusterUUID:      clusterUUID,
+		logger:           newFileLogger(config),
+	}
+}
+
+func (e *FileExporter) AddRecord(record ipfixentities.Record, flowRecord *flowrecord.FlowRecord, isRecordIPv6 bool) error {
+	r := &flowrecord.Row{
+		FlowRecord:   flowRecord,
+		ClusterUUID:  e.clusterUUID,
+		TimeInserted: time.Now(),
+	}
+	if e.config.RecordFormat == "JSON" {
+		e.buf = flowrecord.AppendJSON(e.buf[:0], r)
+	} else {
+		e.buf = flowrecord.AppendCSV(e.buf[:0], r)
+	}
+	e.mutex.Lock()
+	defer e.mutex.Unlock()
+	if _, err := e.logger.Write(e.buf); err != nil {
+		return fmt.Errorf("error when writing flow record to file: %v", err)
+	}
+	e.written = true
+	return nil
+}
+
+func (e *FileExporter) Start() {
+	if e.rotationInterval == 0 {
+		return
+	}
+	e.stopCh = make(chan struct{})
+	e.wg.Add(1)
+	go func() {
+		defer e.wg.Done()
+		e.rotateFilePeriodically(e.rotationInterval, e.stopCh)
+	}()
+}
+
+func (e *FileExporter) Stop() {
+	if e.stopCh != nil {
+		close(e.stopCh)
+		e.wg.Wait()
+		e.stopCh = nil
+	}
+	e.mutex.Lock()
+	defer e.mutex.Unlock()
+	if err := e.logger.Close(); err != nil {
+		klog.ErrorS(err, "Error when closing flow records file")
+	}
+}
+
+func (e *FileExporter) rotateFilePeriodically(rotationInterval time.Duration, stopCh <-chan struct{}) {
+	ticker := time.NewTicker(rotationInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-stopCh:
+			return
+		case <-ticker.C:
+			e.rotateFile()
+		}
+	}
+}
+
+func (e *FileExporter) rotateFile() {
+	e.mutex.Lock()
+	defer e.mutex.Unlock()
+	if !e.written {
+		return
+	}
+	if err := e.logger.Rotate(); err != nil {
+		klog.ErrorS(err, "Error when rotating flow records file")
+		return
+	}
+	e.written = false
+}
+
+func (e *FileExporter) UpdateOptions(opt *options.Options) {
+	config := opt.Config.FileExporter
+	if reflect.DeepEqual(config, e.config) && opt.FileExporterRotationInterval == e.rotationInterval {
+		return
+	}
+	klog.InfoS("Updating file exporter")
+	// The current file is closed and records are written to a new file
+	// (or appended to an existing one) with the new configuration.
+	e.Stop()
+	e.config = config
+	e.rotationInterval = opt.FileExporterRotationInterval
+	e.mutex.Lock()
+	e.logger = newFileLogger(&config)
+	e.written = false
+	e.mutex.Unlock()
+	e.Start()
+	logFileExporterConfig("New file exporter configuration", &config, e.rotationInterval)
+}
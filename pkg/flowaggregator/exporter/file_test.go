@@ -0,0 +1,136 @@
+// Copyright 2022 Antrea Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package exporter
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"antrea.io/antrea/pkg/config/flowaggregator"
+	flowrecordtesting "antrea.io/antrea/pkg/flowaggregator/flowrecord/testing"
+	"antrea.io/antrea/pkg/flowaggregator/options"
+)
+
+const testClusterUUID = "fd2a5e2e-f5b1-4fd6-ab2d-1b7c5e7b5e4a"
+
+func newTestFileExporterConfig(path string, recordFormat string) *flowaggregator.FileExporterConfig {
+	compress := false
+	return &flowaggregator.FileExporterConfig{
+		Enable:       true,
+		Path:         path,
+		RecordFormat: recordFormat,
+		Compress:     &compress,
+		MaxFileSize:  100,
+		MaxBackups:   10,
+	}
+}
+
+func readLines(t *testing.T, fileName string) []string {
+	data, err := os.ReadFile(fileName)
+	require.NoError(t, err)
+	return strings.Split(strings.TrimSuffix(string(data), "\n"), "\n")
+}
+
+func TestFileExporter_AddRecord(t *testing.T) {
+	for _, recordFormat := range []string{"CSV", "JSON"} {
+		t.Run(recordFormat, func(t *testing.T) {
+			dir := t.TempDir()
+			fileExporter := newFileExporter(newTestFileExporterConfig(dir, recordFormat), 0, testClusterUUID)
+			fileExporter.Start()
+			require.NoError(t, fileExporter.AddRecord(nil, flowrecordtesting.PrepareTestFlowRecord(), false))
+			require.NoError(t, fileExporter.AddRecord(nil, flowrecordtesting.PrepareTestFlowRecord(), false))
+			fileExporter.Stop()
+
+			lines := readLines(t, filepath.Join(dir, "flows."+strings.ToLower(recordFormat)))
+			require.Len(t, lines, 2)
+			if recordFormat == "CSV" {
+				assert.True(t, strings.HasPrefix(lines[0], "1637706961,1637706973,1637706974,1637706975,3,10.10.0.79,10.10.0.80,"))
+				assert.Contains(t, lines[0], ","+testClusterUUID+",")
+			} else {
+				var fields map[string]interface{}
+				require.NoError(t, json.Unmarshal([]byte(lines[0]), &fields))
+				assert.Equal(t, "10.10.0.79", fields["sourceIP"])
+				assert.Equal(t, testClusterUUID, fields["clusterUUID"])
+			}
+		})
+	}
+}
+
+func TestFileExporter_Rotation(t *testing.T) {
+	dir := t.TempDir()
+	fileExporter := newFileExporter(newTestFileExporterConfig(dir, "CSV"), 100*time.Millisecond, testClusterUUID)
+	fileExporter.Start()
+	defer fileExporter.Stop()
+	listFiles := func() []string {
+		entries, err := os.ReadDir(dir)
+		require.NoError(t, err)
+		var names []string
+		for _, entry := range entries {
+			names = append(names, entry.Name())
+		}
+		return names
+	}
+
+	require.NoError(t, fileExporter.AddRecord(nil, flowrecordtesting.PrepareTestFlowRecord(), false))
+	assert.Eventually(t, func() bool {
+		return len(listFiles()) == 2
+	}, 2*time.Second, 50*time.Millisecond)
+	// Empty files are not rotated.
+	time.Sleep(300 * time.Millisecond)
+	files := listFiles()
+	require.Len(t, files, 2)
+	for _, name := range files {
+		if name == "flows.csv" {
+			info, err := os.Stat(filepath.Join(dir, name))
+			require.NoError(t, err)
+			assert.Zero(t, info.Size())
+		} else {
+			assert.True(t, strings.HasPrefix(name, "flows-"))
+			assert.Len(t, readLines(t, filepath.Join(dir, name)), 1)
+		}
+	}
+}
+
+func TestFileExporter_UpdateOptions(t *testing.T) {
+	dir := t.TempDir()
+	config := newTestFileExporterConfig(dir, "CSV")
+	fileExporter := newFileExporter(config, 0, testClusterUUID)
+	fileExporter.Start()
+	require.NoError(t, fileExporter.AddRecord(nil, flowrecordtesting.PrepareTestFlowRecord(), false))
+
+	newConfig := *config
+	newConfig.RecordFormat = "JSON"
+	fileExporter.UpdateOptions(&options.Options{
+		Config: &flowaggregator.FlowAggregatorConfig{
+			FileExporter: newConfig,
+		},
+		FileExporterRotationInterval: time.Hour,
+	})
+	assert.Equal(t, "JSON", fileExporter.config.RecordFormat)
+	assert.Equal(t, time.Hour, fileExporter.rotationInterval)
+	assert.Equal(t, filepath.Join(dir, "flows.json"), fileExporter.logger.Filename)
+	require.NoError(t, fileExporter.AddRecord(nil, flowrecordtesting.PrepareTestFlowRecord(), false))
+	fileExporter.Stop()
+
+	assert.Len(t, readLines(t, filepath.Join(dir, "flows.csv")), 1)
+	assert.Len(t, readLines(t, filepath.Join(dir, "flows.json")), 1)
+}
@@ -0,0 +1,146 @@
+// Copyright 2022 Antrea Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package exporter
+
+import (
+	"reflect"
+	"sync"
+	"time"
+
+	ipfixentities "github.com/vmware/go-ipfix/pkg/entities"
+	"k8s.io/component-base/metrics/legacyregistry"
+	"k8s.io/klog/v2"
+
+	flowaggregatorconfig "antrea.io/antrea/pkg/config/flowaggregator"
+	"antrea.io/antrea/pkg/flowaggregator/flowmetrics"
+	"antrea.io/antrea/pkg/flowaggregator/flowrecord"
+	"antrea.io/antrea/pkg/flowaggregator/options"
+)
+
+var (
+	// The collector is registered once, as collectors cannot be removed from
+	// the legacy registry, and it is shared by successive exporters. The
+	// legacy registry is served by the Flow Aggregator API server.
+	flowMetricsCollector         = &flowmetrics.Collector{}
+	registerFlowMetricsCollector sync.Once
+)
+
+// FlowMetricsExporter maintains Prometheus counters computed from flow records.
+type FlowMetricsExporter struct {
+	config    flowaggregatorconfig.FlowMetricsConfig
+	collector *flowmetrics.Collector
+	// staleConnectionTimeout is the duration after which a connection for
+	// which no record has been received is not tracked anymore.
+	staleConnectionTimeout time.Duration
+	stopCh                 chan struct{}
+	wg                     sync.WaitGroup
+}
+
+func flowMetricsStaleConnectionTimeout(opt *options.Options) time.Duration {
+	// Records for active connections are received every activeFlowRecordTimeout,
+	// unless they are lost in which case their aggregated flow expires after
+	// inactiveFlowRecordTimeout.
+	return 2*opt.ActiveFlowRecordTimeout + opt.InactiveFlowRecordTimeout
+}
+
+func logFlowMetricsConfig(msg string, config *flowaggregatorconfig.FlowMetricsConfig) {
+	klog.InfoS(msg, "dimensions", config.Dimensions, "maxSeries", config.MaxSeries)
+}
+
+func NewFlowMetricsExporter(opt *options.Options) (*FlowMetricsExporter, error) {
+	config := opt.Config.FlowMetrics
+	logFlowMetricsConfig("Flow metrics configuration", &config)
+	registerFlowMetricsCollector.Do(func() {
+		legacyregistry.CustomMustRegister(flowMetricsCollector)
+	})
+	return newFlowMetricsExporter(&config, flowMetricsStaleConnectionTimeout(opt), flowMetricsCollector)
+}
+
+func newFlowMetricsExporter(config *flowaggregatorconfig.FlowMetricsConfig, staleConnectionTimeout time.Duration, collector *flowmetrics.Collector) (*FlowMetricsExporter, error) {
+	if err := collector.Configure(config.Dimensions, int(config.MaxSeries)); err != nil {
+		return nil, err
+	}
+	return &FlowMetricsExporter{
+		config:                 *config,
+		collector:              collector,
+		staleConnectionTimeout: staleConnectionTimeout,
+	}, nil
+}
+
+func (e *FlowMetricsExporter) AddRecord(record ipfixentities.Record, flowRecord *flowrecord.FlowRecord, isRecordIPv6 bool) error {
+	e.collector.AddRecord(flowRecord, time.Now())
+	return nil
+}
+
+func (e *FlowMetricsExporter) Start() {
+	if e.staleConnectionTimeout == 0 {
+		return
+	}
+	e.stopCh = make(chan struct{})
+	e.wg.Add(1)
+	go func() {
+		defer e.wg.Done()
+		e.removeStaleConnectionsPeriodically(e.staleConnectionTimeout, e.stopCh)
+	}()
+}
+
+func (e *FlowMetricsExporter) stop() {
+	if e.stopCh != nil {
+		close(e.stopCh)
+		e.wg.Wait()
+		e.stopCh = nil
+	}
+}
+
+func (e *FlowMetricsExporter) Stop() {
+	e.stop()
+	// The metrics are not reported anymore once the exporter is disabled.
+	e.collector.Reset()
+}
+
+func (e *FlowMetricsExporter) removeStaleConnectionsPeriodically(staleConnectionTimeout time.Duration, stopCh <-chan struct{}) {
+	ticker := time.NewTicker(staleConnectionTimeout)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-stopCh:
+			return
+		case now := <-ticker.C:
+			e.collector.RemoveStaleConnections(now, staleConnectionTimeout)
+		}
+	}
+}
+
+func (e *FlowMetricsExporter) UpdateOptions(opt *options.Options) {
+	config := opt.Config.FlowMetrics
+	staleConnectionTimeout := flowMetricsStaleConnectionTimeout(opt)
+	if reflect.DeepEqual(config, e.config) && staleConnectionTimeout == e.staleConnectionTimeout {
+		return
+	}
+	klog.InfoS("Updating flow metrics exporter")
+	// Counters are reset if the dimensions or the maximum number of series
+	// change, and kept otherwise.
+	if err := e.collector.Configure(config.Dimensions, int(config.MaxSeries)); err != nil {
+		klog.ErrorS(err, "Error when updating flow metrics configuration")
+		return
+	}
+	e.config = config
+	if staleConnectionTimeout != e.staleConnectionTimeout {
+		e.stop()
+		e.staleConnectionTimeout = staleConnectionTimeout
+		e.Start()
+	}
+	logFlowMetricsConfig("New flow metrics configuration", &config)
+}
@@ -0,0 +1,87 @@
+// Copyright 2022 Antrea Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package exporter
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"k8s.io/component-base/metrics"
+	"k8s.io/component-base/metrics/testutil"
+
+	"antrea.io/antrea/pkg/config/flowaggregator"
+	"antrea.io/antrea/pkg/flowaggregator/flowmetrics"
+	flowrecordtesting "antrea.io/antrea/pkg/flowaggregator/flowrecord/testing"
+	"antrea.io/antrea/pkg/flowaggregator/options"
+)
+
+func TestFlowMetricsExporter(t *testing.T) {
+	collector := &flowmetrics.Collector{}
+	registry := metrics.NewKubeRegistry()
+	registry.CustomMustRegister(collector)
+	config := &flowaggregator.FlowMetricsConfig{
+		Enable:     true,
+		Dimensions: []string{"sourcePodNamespace", "ingressNetworkPolicyRuleAction"},
+		MaxSeries:  10,
+	}
+	flowMetricsExporter, err := newFlowMetricsExporter(config, time.Minute, collector)
+	require.NoError(t, err)
+	flowMetricsExporter.Start()
+	require.NoError(t, flowMetricsExporter.AddRecord(nil, flowrecordtesting.PrepareTestFlowRecord(), false))
+	require.NoError(t, flowMetricsExporter.AddRecord(nil, flowrecordtesting.PrepareTestFlowRecord(), false))
+
+	assert.NoError(t, testutil.GatherAndCompare(registry, strings.NewReader(`
+# HELP antrea_flow_aggregator_flow_bytes_total [ALPHA] Number of bytes sent from the source to the destination of flows.
+# TYPE antrea_flow_aggregator_flow_bytes_total counter
+antrea_flow_aggregator_flow_bytes_total{destination_pod_namespace="",destination_service_port_name="",egress_network_policy_rule_action="",flow_type="",ingress_network_policy_rule_action="Drop",source_pod_namespace="antrea-test"} 1.7965249876e+10
+# HELP antrea_flow_aggregator_denied_connections_total [ALPHA] Number of connections denied by an Antrea-native policy rule.
+# TYPE antrea_flow_aggregator_denied_connections_total counter
+antrea_flow_aggregator_denied_connections_total{destination_pod_namespace="",destination_service_port_name="",egress_network_policy_rule_action="",flow_type="",ingress_network_policy_rule_action="Drop",source_pod_namespace="antrea-test"} 2
+`), "antrea_flow_aggregator_flow_bytes_total", "antrea_flow_aggregator_denied_connections_total"))
+
+	// Counters are kept when only the filters are updated.
+	newConfig := *config
+	newConfig.Filters = []flowaggregator.FlowFilterRule{{Namespaces: []string{"antrea-test"}}}
+	opt := &options.Options{
+		Config:                    &flowaggregator.FlowAggregatorConfig{FlowMetrics: newConfig},
+		ActiveFlowRecordTimeout:   10 * time.Second,
+		InactiveFlowRecordTimeout: 20 * time.Second,
+	}
+	flowMetricsExporter.UpdateOptions(opt)
+	assert.Equal(t, 40*time.Second, flowMetricsExporter.staleConnectionTimeout)
+	assert.NoError(t, testutil.GatherAndCompare(registry, strings.NewReader(`
+# HELP antrea_flow_aggregator_connections_total [ALPHA] Number of connections.
+# TYPE antrea_flow_aggregator_connections_total counter
+antrea_flow_aggregator_connections_total{destination_pod_namespace="",destination_service_port_name="",egress_network_policy_rule_action="",flow_type="",ingress_network_policy_rule_action="Drop",source_pod_namespace="antrea-test"} 2
+`), "antrea_flow_aggregator_connections_total"))
+
+	// Counters are reset when the dimensions are updated.
+	newConfig.Dimensions = []string{"destinationPodNamespace"}
+	opt.Config.FlowMetrics = newConfig
+	flowMetricsExporter.UpdateOptions(opt)
+	require.NoError(t, flowMetricsExporter.AddRecord(nil, flowrecordtesting.PrepareTestFlowRecord(), false))
+	assert.NoError(t, testutil.GatherAndCompare(registry, strings.NewReader(`
+# HELP antrea_flow_aggregator_connections_total [ALPHA] Number of connections.
+# TYPE antrea_flow_aggregator_connections_total counter
+antrea_flow_aggregator_connections_total{destination_pod_namespace="antrea-test-b",destination_service_port_name="",egress_network_policy_rule_action="",flow_type="",ingress_network_policy_rule_action="",source_pod_namespace=""} 1
+`), "antrea_flow_aggregator_connections_total"))
+
+	// No metric is reported once the exporter is stopped.
+	flowMetricsExporter.Stop()
+	assert.NoError(t, testutil.GatherAndCompare(registry, strings.NewReader(""), "antrea_flow_aggregator_connections_total"))
+}
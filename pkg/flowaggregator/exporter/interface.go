@@ -17,6 +17,7 @@ package exporter
 import (
 	ipfixentities "github.com/vmware/go-ipfix/pkg/entities"
 
+	"antrea.io/antrea/pkg/flowaggregator/flowrecord"
 	"antrea.io/antrea/pkg/flowaggregator/options"
 )
 
@@ -26,6 +27,9 @@ import (
 type Interface interface {
 	Start()
 	Stop()
-	AddRecord(record ipfixentities.Record, isRecordIPv6 bool) error
+	// AddRecord exports a flow record. flowRecord holds the same information
+	// as record, as well as the information computed by the Flow Aggregator
+	// which is not part of IPFIX records, such as the endpoint kinds.
+	AddRecord(record ipfixentities.Record, flowRecord *flowrecord.FlowRecord, isRecordIPv6 bool) error
 	UpdateOptions(opt *options.Options)
 }
@@ -23,12 +23,15 @@ import (
 	ipfixentities "github.com/vmware/go-ipfix/pkg/entities"
 	"github.com/vmware/go-ipfix/pkg/exporter"
 	ipfixregistry "github.com/vmware/go-ipfix/pkg/registry"
+	"k8s.io/apimachinery/pkg/util/sets"
 	"k8s.io/client-go/kubernetes"
 	"k8s.io/klog/v2"
 
+	"antrea.io/antrea/pkg/flowaggregator/flowrecord"
 	"antrea.io/antrea/pkg/flowaggregator/infoelements"
 	"antrea.io/antrea/pkg/flowaggregator/options"
 	"antrea.io/antrea/pkg/ipfix"
+	"antrea.io/antrea/pkg/util/env"
 )
 
 // this is used for unit testing
@@ -38,6 +41,8 @@ var (
 	}
 )
 
+var dnsElements = sets.NewString(infoelements.AntreaDNSElementList...)
+
 type IPFIXExporter struct {
 	externalFlowCollectorAddr  string
 	externalFlowCollectorProto string
@@ -49,6 +54,9 @@ type IPFIXExporter struct {
 	templateIDv6               uint16
 	set                        ipfixentities.Set
 	registry                   ipfix.IPFIXRegistry
+	// templateRegistry is nil if the Pod name is unknown, in which case
+	// the template IDs are assigned by the exporting process.
+	templateRegistry *ipfixTemplateRegistry
 	// mutex protects configuration state from concurrent access
 	mutex sync.Mutex
 }
@@ -98,6 +106,12 @@ func NewIPFIXExporter(
 		registry:                   registry,
 		set:                        ipfixentities.NewSet(false),
 	}
+	if podName := env.GetPodName(); podName != "" {
+		exporter.templateRegistry = &ipfixTemplateRegistry{
+			k8sClient: k8sClient,
+			podName:   podName,
+		}
+	}
 
 	return exporter
 }
@@ -110,7 +124,7 @@ func (e *IPFIXExporter) Stop() {
 	// no-op
 }
 
-func (e *IPFIXExporter) AddRecord(record ipfixentities.Record, isRecordIPv6 bool) error {
+func (e *IPFIXExporter) AddRecord(record ipfixentities.Record, flowRecord *flowrecord.FlowRecord, isRecordIPv6 bool) error {
 	if err := e.sendRecord(record, isRecordIPv6); err != nil {
 		if e.exportingProcess != nil {
 			e.exportingProcess.CloseConnToCollector()
@@ -158,7 +172,7 @@ func (e *IPFIXExporter) sendRecord(record ipfixentities.Record, isRecordIPv6 boo
 	if err := e.set.PrepareSet(ipfixentities.Data, templateID); err != nil {
 		return err
 	}
-	if err := e.set.AddRecord(record.GetOrderedElementList(), templateID); err != nil {
+	if err := e.set.AddRecord(filterDNSElements(record.GetOrderedElementList()), templateID); err != nil {
 		return err
 	}
 	sentBytes, err := e.exportingProcess.SendSet(e.set)
@@ -169,6 +183,19 @@ func (e *IPFIXExporter) sendRecord(record ipfixentities.Record, isRecordIPv6 boo
 	return nil
 }
 
+// filterDNSElements removes the elements of infoelements.AntreaDNSElementList,
+// which are not in the template sent to the external flow collector.
+func filterDNSElements(elements []ipfixentities.InfoElementWithValue) []ipfixentities.InfoElementWithValue {
+	filtered := make([]ipfixentities.InfoElementWithValue, 0, len(elements))
+	for _, element := range elements {
+		if dnsElements.Has(element.GetInfoElement().Name) {
+			continue
+		}
+		filtered = append(filtered, element)
+	}
+	return filtered
+}
+
 func (e *IPFIXExporter) initExportingProcess() error {
 	// TODO: This code can be further simplified by changing the go-ipfix API to accept
 	// externalFlowCollectorAddr and externalFlowCollectorProto instead of net.Addr input.
@@ -180,7 +207,6 @@ func (e *IPFIXExporter) initExportingProcess() error {
 			CollectorProtocol:   e.externalFlowCollectorProto,
 			ObservationDomainID: e.observationDomainID,
 			TempRefTimeout:      0,
-			IsEncrypted:         false,
 			SendJSONRecord:      e.sendJSONRecord,
 		}
 	} else {
@@ -190,7 +216,6 @@ func (e *IPFIXExporter) initExportingProcess() error {
 			CollectorProtocol:   e.externalFlowCollectorProto,
 			ObservationDomainID: e.observationDomainID,
 			TempRefTimeout:      1800,
-			IsEncrypted:         false,
 			SendJSONRecord:      e.sendJSONRecord,
 		}
 	}
@@ -211,11 +236,16 @@ func (e *IPFIXExporter) initExportingProcess() error {
 }
 
 func (e *IPFIXExporter) createAndSendTemplate(isRecordIPv6 bool) error {
-	templateID := e.exportingProcess.NewTemplateID()
 	recordIPFamily := "IPv4"
 	if isRecordIPv6 {
 		recordIPFamily = "IPv6"
 	}
+	templateID, err := e.newTemplateID(isRecordIPv6)
+	if err != nil {
+		e.exportingProcess.CloseConnToCollector()
+		e.exportingProcess = nil
+		return fmt.Errorf("error when getting ID of %s template: %v", recordIPFamily, err)
+	}
 	if isRecordIPv6 {
 		e.templateIDv6 = templateID
 	} else {
@@ -232,34 +262,68 @@ func (e *IPFIXExporter) createAndSendTemplate(isRecordIPv6 bool) error {
 	return nil
 }
 
+// newTemplateID returns the ID of the template for the given IP family. The ID
+// is assigned through the template registry shared by all the Flow Aggregator
+// replicas, so that different replicas never use the same ID for different
+// templates.
+func (e *IPFIXExporter) newTemplateID(isIPv6 bool) (uint16, error) {
+	if e.templateRegistry == nil {
+		return e.exportingProcess.NewTemplateID(), nil
+	}
+	elements, err := e.getTemplateElements(isIPv6)
+	if err != nil {
+		return 0, err
+	}
+	return e.templateRegistry.getTemplateID(templateFingerprint(elements))
+}
+
 func (e *IPFIXExporter) sendTemplateSet(isIPv6 bool) (int, error) {
+	templateID := e.templateIDv4
+	if isIPv6 {
+		templateID = e.templateIDv6
+	}
+	elements, err := e.getTemplateElements(isIPv6)
+	if err != nil {
+		return 0, err
+	}
+	e.set.ResetSet()
+	if err := e.set.PrepareSet(ipfixentities.Template, templateID); err != nil {
+		return 0, err
+	}
+	err = e.set.AddRecord(elements, templateID)
+	if err != nil {
+		return 0, fmt.Errorf("error when adding record to set, error: %v", err)
+	}
+	bytesSent, err := e.exportingProcess.SendSet(e.set)
+	return bytesSent, err
+}
+
+func (e *IPFIXExporter) getTemplateElements(isIPv6 bool) ([]ipfixentities.InfoElementWithValue, error) {
 	elements := make([]ipfixentities.InfoElementWithValue, 0)
 	ianaInfoElements := infoelements.IANAInfoElementsIPv4
 	antreaInfoElements := infoelements.AntreaInfoElementsIPv4
-	templateID := e.templateIDv4
 	if isIPv6 {
 		ianaInfoElements = infoelements.IANAInfoElementsIPv6
 		antreaInfoElements = infoelements.AntreaInfoElementsIPv6
-		templateID = e.templateIDv6
 	}
 	for _, ie := range ianaInfoElements {
 		ie, err := e.createInfoElementForTemplateSet(ie, ipfixregistry.IANAEnterpriseID)
 		if err != nil {
-			return 0, err
+			return nil, err
 		}
 		elements = append(elements, ie)
 	}
 	for _, ie := range infoelements.IANAReverseInfoElements {
 		ie, err := e.createInfoElementForTemplateSet(ie, ipfixregistry.IANAReversedEnterpriseID)
 		if err != nil {
-			return 0, err
+			return nil, err
 		}
 		elements = append(elements, ie)
 	}
 	for _, ie := range antreaInfoElements {
 		ie, err := e.createInfoElementForTemplateSet(ie, ipfixregistry.AntreaEnterpriseID)
 		if err != nil {
-			return 0, err
+			return nil, err
 		}
 		elements = append(elements, ie)
 	}
@@ -270,21 +334,21 @@ func (e *IPFIXExporter) sendTemplateSet(isIPv6 bool) (int, error) {
 		ieName := infoelements.AntreaSourceStatsElementList[i]
 		ie, err := e.createInfoElementForTemplateSet(ieName, ipfixregistry.AntreaEnterpriseID)
 		if err != nil {
-			return 0, err
+			return nil, err
 		}
 		elements = append(elements, ie)
 		// Add Antrea destination stats fields
 		ieName = infoelements.AntreaDestinationStatsElementList[i]
 		ie, err = e.createInfoElementForTemplateSet(ieName, ipfixregistry.AntreaEnterpriseID)
 		if err != nil {
-			return 0, err
+			return nil, err
 		}
 		elements = append(elements, ie)
 	}
 	for _, ie := range infoelements.AntreaFlowEndSecondsElementList {
 		ie, err := e.createInfoElementForTemplateSet(ie, ipfixregistry.AntreaEnterpriseID)
 		if err != nil {
-			return 0, err
+			return nil, err
 		}
 		elements = append(elements, ie)
 	}
@@ -293,21 +357,21 @@ func (e *IPFIXExporter) sendTemplateSet(isIPv6 bool) (int, error) {
 		ieName := infoelements.AntreaThroughputElementList[i]
 		ie, err := e.createInfoElementForTemplateSet(ieName, ipfixregistry.AntreaEnterpriseID)
 		if err != nil {
-			return 0, err
+			return nil, err
 		}
 		elements = append(elements, ie)
 		// Add source node specific throughput fields
 		ieName = infoelements.AntreaSourceThroughputElementList[i]
 		ie, err = e.createInfoElementForTemplateSet(ieName, ipfixregistry.AntreaEnterpriseID)
 		if err != nil {
-			return 0, err
+			return nil, err
 		}
 		elements = append(elements, ie)
 		// Add destination node specific throughput fields
 		ieName = infoelements.AntreaDestinationThroughputElementList[i]
 		ie, err = e.createInfoElementForTemplateSet(ieName, ipfixregistry.AntreaEnterpriseID)
 		if err != nil {
-			return 0, err
+			return nil, err
 		}
 		elements = append(elements, ie)
 	}
@@ -315,21 +379,12 @@ func (e *IPFIXExporter) sendTemplateSet(isIPv6 bool) (int, error) {
 		for _, ie := range infoelements.AntreaLabelsElementList {
 			ie, err := e.createInfoElementForTemplateSet(ie, ipfixregistry.AntreaEnterpriseID)
 			if err != nil {
-				return 0, err
+				return nil, err
 			}
 			elements = append(elements, ie)
 		}
 	}
-	e.set.ResetSet()
-	if err := e.set.PrepareSet(ipfixentities.Template, templateID); err != nil {
-		return 0, err
-	}
-	err := e.set.AddRecord(elements, templateID)
-	if err != nil {
-		return 0, fmt.Errorf("error when adding record to set, error: %v", err)
-	}
-	bytesSent, err := e.exportingProcess.SendSet(e.set)
-	return bytesSent, err
+	return elements, nil
 }
 
 func (e *IPFIXExporter) createInfoElementForTemplateSet(ieName string, enterpriseID uint32) (ipfixentities.InfoElementWithValue, error) {
@@ -0,0 +1,229 @@
+// Copyright 2022 Antrea Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package exporter
+
+import (
+	"context"
+	"encoding/binary"
+	"encoding/json"
+	"fmt"
+	"hash/fnv"
+	"math"
+
+	ipfixentities "github.com/vmware/go-ipfix/pkg/entities"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/klog/v2"
+)
+
+const (
+	// IPFIXTemplateConfigMapName is the name of the ConfigMap which stores
+	// the IDs of the IPFIX templates exported by the Flow Aggregator replicas.
+	IPFIXTemplateConfigMapName      = "flow-aggregator-ipfix-templates"
+	IPFIXTemplateConfigMapNamespace = "flow-aggregator"
+	// Template IDs 0-255 are reserved by RFC 7011.
+	minTemplateID = 256
+)
+
+// ipfixTemplate is the value stored in the template ConfigMap for each template,
+// which is identified by its fingerprint.
+type ipfixTemplate struct {
+	ID uint16 `json:"id"`
+	// Pods are the Flow Aggregator replicas which export this template.
+	Pods []string `json:"pods"`
+}
+
+// ipfixTemplateRegistry assigns the IDs of the IPFIX templates exported by the
+// Flow Aggregator replicas. All the replicas use the same Observation Domain ID,
+// so the same template ID must not be used for different templates, e.g. when
+// replicas running different versions coexist during an upgrade. Replicas
+// exporting the same template use the same ID. A replica can register a new
+// template at any time, and the leader of the replicas releases the IDs which
+// are no longer used, see PruneIPFIXTemplates.
+type ipfixTemplateRegistry struct {
+	k8sClient kubernetes.Interface
+	podName   string
+}
+
+// templateFingerprint identifies a template by the list of its Information
+// Elements.
+func templateFingerprint(elements []ipfixentities.InfoElementWithValue) string {
+	h := fnv.New64a()
+	b := make([]byte, 8)
+	for _, element := range elements {
+		ie := element.GetInfoElement()
+		binary.BigEndian.PutUint32(b[0:4], ie.EnterpriseId)
+		binary.BigEndian.PutUint16(b[4:6], ie.ElementId)
+		binary.BigEndian.PutUint16(b[6:8], ie.Len)
+		h.Write(b)
+	}
+	return fmt.Sprintf("%016x", h.Sum64())
+}
+
+// getTemplateID returns the ID of the template with the given fingerprint,
+// assigning a new ID if no replica has registered this template yet. The API
+// server arbitrates concurrent registrations by different replicas.
+func (r *ipfixTemplateRegistry) getTemplateID(fingerprint string) (uint16, error) {
+	const maxAttempts = 3
+	for i := 0; i < maxAttempts; i++ {
+		configMap, err := r.k8sClient.CoreV1().ConfigMaps(IPFIXTemplateConfigMapNamespace).Get(context.TODO(), IPFIXTemplateConfigMapName, metav1.GetOptions{})
+		exists := true
+		if err != nil {
+			if !errors.IsNotFound(err) {
+				return 0, fmt.Errorf("error getting ConfigMap %s: %v", IPFIXTemplateConfigMapName, err)
+			}
+			exists = false
+			configMap = &corev1.ConfigMap{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      IPFIXTemplateConfigMapName,
+					Namespace: IPFIXTemplateConfigMapNamespace,
+					Labels: map[string]string{
+						"app": "flow-aggregator",
+					},
+				},
+			}
+		}
+		if configMap.Data == nil {
+			configMap.Data = make(map[string]string)
+		}
+		templates := parseIPFIXTemplates(configMap)
+		template, ok := templates[fingerprint]
+		if ok && sets.NewString(template.Pods...).Has(r.podName) {
+			return template.ID, nil
+		}
+		if !ok {
+			id, err := allocateTemplateID(templates)
+			if err != nil {
+				return 0, err
+			}
+			template = &ipfixTemplate{ID: id}
+		}
+		template.Pods = append(template.Pods, r.podName)
+		value, err := json.Marshal(template)
+		if err != nil {
+			return 0, err
+		}
+		configMap.Data[fingerprint] = string(value)
+		if exists {
+			_, err = r.k8sClient.CoreV1().ConfigMaps(IPFIXTemplateConfigMapNamespace).Update(context.TODO(), configMap, metav1.UpdateOptions{})
+		} else {
+			_, err = r.k8sClient.CoreV1().ConfigMaps(IPFIXTemplateConfigMapNamespace).Create(context.TODO(), configMap, metav1.CreateOptions{})
+		}
+		if err == nil {
+			klog.InfoS("Registered IPFIX template", "fingerprint", fingerprint, "templateID", template.ID)
+			return template.ID, nil
+		}
+		// Another replica created or updated the ConfigMap concurrently.
+		if errors.IsAlreadyExists(err) || errors.IsConflict(err) {
+			continue
+		}
+		return 0, fmt.Errorf("error storing IPFIX template in ConfigMap %s: %v", IPFIXTemplateConfigMapName, err)
+	}
+	return 0, fmt.Errorf("failed to store IPFIX template in ConfigMap %s after %d attempts", IPFIXTemplateConfigMapName, maxAttempts)
+}
+
+func parseIPFIXTemplates(configMap *corev1.ConfigMap) map[string]*ipfixTemplate {
+	templates := make(map[string]*ipfixTemplate, len(configMap.Data))
+	for fingerprint, value := range configMap.Data {
+		template := &ipfixTemplate{}
+		if err := json.Unmarshal([]byte(value), template); err != nil {
+			klog.ErrorS(err, "Invalid IPFIX template in ConfigMap", "configMap", klog.KObj(configMap), "fingerprint", fingerprint)
+			continue
+		}
+		templates[fingerprint] = template
+	}
+	return templates
+}
+
+func allocateTemplateID(templates map[string]*ipfixTemplate) (uint16, error) {
+	usedIDs := make(map[uint16]bool, len(templates))
+	for _, template := range templates {
+		usedIDs[template.ID] = true
+	}
+	for id := minTemplateID; id <= math.MaxUint16; id++ {
+		if !usedIDs[uint16(id)] {
+			return uint16(id), nil
+		}
+	}
+	return 0, fmt.Errorf("no IPFIX template ID available")
+}
+
+// PruneIPFIXTemplates removes the Flow Aggregator replicas which are no longer
+// running from the templates stored in the template ConfigMap, and releases the
+// IDs of the templates which are no longer exported by any replica. It is called
+// by the leader of the replicas.
+func PruneIPFIXTemplates(k8sClient kubernetes.Interface) error {
+	configMap, err := k8sClient.CoreV1().ConfigMaps(IPFIXTemplateConfigMapNamespace).Get(context.TODO(), IPFIXTemplateConfigMapName, metav1.GetOptions{})
+	if err != nil {
+		if errors.IsNotFound(err) {
+			return nil
+		}
+		return fmt.Errorf("error getting ConfigMap %s: %v", IPFIXTemplateConfigMapName, err)
+	}
+	pods, err := k8sClient.CoreV1().Pods(IPFIXTemplateConfigMapNamespace).List(context.TODO(), metav1.ListOptions{LabelSelector: "app=flow-aggregator"})
+	if err != nil {
+		return fmt.Errorf("error listing Flow Aggregator Pods: %v", err)
+	}
+	runningPods := sets.NewString()
+	for i := range pods.Items {
+		pod := &pods.Items[i]
+		if pod.Status.Phase != corev1.PodSucceeded && pod.Status.Phase != corev1.PodFailed {
+			runningPods.Insert(pod.Name)
+		}
+	}
+	updated := false
+	templates := parseIPFIXTemplates(configMap)
+	for fingerprint := range configMap.Data {
+		template, ok := templates[fingerprint]
+		if !ok {
+			delete(configMap.Data, fingerprint)
+			updated = true
+			continue
+		}
+		var templatePods []string
+		for _, pod := range template.Pods {
+			if runningPods.Has(pod) {
+				templatePods = append(templatePods, pod)
+			}
+		}
+		if len(templatePods) == len(template.Pods) {
+			continue
+		}
+		updated = true
+		if len(templatePods) == 0 {
+			klog.InfoS("Releasing unused IPFIX template ID", "fingerprint", fingerprint, "templateID", template.ID)
+			delete(configMap.Data, fingerprint)
+			continue
+		}
+		template.Pods = templatePods
+		value, err := json.Marshal(template)
+		if err != nil {
+			return err
+		}
+		configMap.Data[fingerprint] = string(value)
+	}
+	if !updated {
+		return nil
+	}
+	// In case of conflict, the ConfigMap will be pruned at the next
+	// synchronization.
+	if _, err := k8sClient.CoreV1().ConfigMaps(IPFIXTemplateConfigMapNamespace).Update(context.TODO(), configMap, metav1.UpdateOptions{}); err != nil {
+		return fmt.Errorf("error updating ConfigMap %s: %v", IPFIXTemplateConfigMapName, err)
+	}
+	return nil
+}
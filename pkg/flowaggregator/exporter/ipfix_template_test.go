@@ -0,0 +1,104 @@
+// Copyright 2022 Antrea Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package exporter
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	ipfixentities "github.com/vmware/go-ipfix/pkg/entities"
+	ipfixregistry "github.com/vmware/go-ipfix/pkg/registry"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+func newFlowAggregatorPod(name string) *corev1.Pod {
+	return &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      name,
+			Namespace: IPFIXTemplateConfigMapNamespace,
+			Labels:    map[string]string{"app": "flow-aggregator"},
+		},
+		Status: corev1.PodStatus{Phase: corev1.PodRunning},
+	}
+}
+
+func TestTemplateFingerprint(t *testing.T) {
+	elements := []ipfixentities.InfoElementWithValue{
+		createElement("sourceIPv4Address", ipfixregistry.IANAEnterpriseID),
+		createElement("destinationIPv4Address", ipfixregistry.IANAEnterpriseID),
+	}
+	fingerprint := templateFingerprint(elements)
+	assert.Equal(t, fingerprint, templateFingerprint(elements))
+	assert.NotEqual(t, fingerprint, templateFingerprint(elements[:1]))
+	assert.NotEqual(t, fingerprint, templateFingerprint([]ipfixentities.InfoElementWithValue{elements[1], elements[0]}))
+}
+
+func TestIPFIXTemplateRegistry(t *testing.T) {
+	client := fake.NewSimpleClientset(newFlowAggregatorPod("flow-aggregator-a"), newFlowAggregatorPod("flow-aggregator-b"))
+	registryA := &ipfixTemplateRegistry{k8sClient: client, podName: "flow-aggregator-a"}
+	registryB := &ipfixTemplateRegistry{k8sClient: client, podName: "flow-aggregator-b"}
+
+	id, err := registryA.getTemplateID("fingerprint1")
+	require.NoError(t, err)
+	assert.Equal(t, uint16(256), id)
+	id, err = registryA.getTemplateID("fingerprint2")
+	require.NoError(t, err)
+	assert.Equal(t, uint16(257), id)
+	// Replicas exporting the same template use the same ID.
+	id, err = registryB.getTemplateID("fingerprint1")
+	require.NoError(t, err)
+	assert.Equal(t, uint16(256), id)
+	// A different template, e.g. exported by a replica running a different
+	// version, gets a different ID.
+	id, err = registryB.getTemplateID("fingerprint3")
+	require.NoError(t, err)
+	assert.Equal(t, uint16(258), id)
+
+	getTemplates := func() map[string]*ipfixTemplate {
+		configMap, err := client.CoreV1().ConfigMaps(IPFIXTemplateConfigMapNamespace).Get(context.TODO(), IPFIXTemplateConfigMapName, metav1.GetOptions{})
+		require.NoError(t, err)
+		return parseIPFIXTemplates(configMap)
+	}
+	assert.Equal(t, map[string]*ipfixTemplate{
+		"fingerprint1": {ID: 256, Pods: []string{"flow-aggregator-a", "flow-aggregator-b"}},
+		"fingerprint2": {ID: 257, Pods: []string{"flow-aggregator-a"}},
+		"fingerprint3": {ID: 258, Pods: []string{"flow-aggregator-b"}},
+	}, getTemplates())
+
+	// Nothing is released while all the replicas are running.
+	require.NoError(t, PruneIPFIXTemplates(client))
+	assert.Len(t, getTemplates(), 3)
+
+	// The IDs which are only used by a replica which is no longer running
+	// are released, and can be assigned to new templates.
+	require.NoError(t, client.CoreV1().Pods(IPFIXTemplateConfigMapNamespace).Delete(context.TODO(), "flow-aggregator-a", metav1.DeleteOptions{}))
+	require.NoError(t, PruneIPFIXTemplates(client))
+	assert.Equal(t, map[string]*ipfixTemplate{
+		"fingerprint1": {ID: 256, Pods: []string{"flow-aggregator-b"}},
+		"fingerprint3": {ID: 258, Pods: []string{"flow-aggregator-b"}},
+	}, getTemplates())
+	registryC := &ipfixTemplateRegistry{k8sClient: client, podName: "flow-aggregator-c"}
+	id, err = registryC.getTemplateID("fingerprint4")
+	require.NoError(t, err)
+	assert.Equal(t, uint16(257), id)
+}
+
+func TestPruneIPFIXTemplatesNoConfigMap(t *testing.T) {
+	assert.NoError(t, PruneIPFIXTemplates(fake.NewSimpleClientset()))
+}
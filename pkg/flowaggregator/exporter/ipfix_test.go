@@ -18,15 +18,16 @@ import (
 	"fmt"
 	"testing"
 
-	"github.com/golang/mock/gomock"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 	ipfixentities "github.com/vmware/go-ipfix/pkg/entities"
 	ipfixentitiestesting "github.com/vmware/go-ipfix/pkg/entities/testing"
 	ipfixregistry "github.com/vmware/go-ipfix/pkg/registry"
+	"go.uber.org/mock/gomock"
 
 	"antrea.io/antrea/pkg/flowaggregator/infoelements"
 	"antrea.io/antrea/pkg/flowaggregator/options"
+	"antrea.io/antrea/pkg/ipfix"
 	ipfixtesting "antrea.io/antrea/pkg/ipfix/testing"
 )
 
@@ -37,7 +38,7 @@ const (
 )
 
 func init() {
-	ipfixregistry.LoadRegistry()
+	ipfix.LoadRegistry()
 }
 
 func createElement(name string, enterpriseID uint32) ipfixentities.InfoElementWithValue {
@@ -182,7 +183,7 @@ func TestIPFIXExporter_UpdateOptions(t *testing.T) {
 	// connection will be closed when updating the external flow collector address
 	mockIPFIXExpProc.EXPECT().CloseConnToCollector()
 
-	require.NoError(t, ipfixExporter.AddRecord(mockRecord, false))
+	require.NoError(t, ipfixExporter.AddRecord(mockRecord, nil, false))
 	assert.Equal(t, 1, setCount, "Invalid number of flow sets sent by exporter")
 
 	const newAddr = "newAddr"
@@ -196,7 +197,7 @@ func TestIPFIXExporter_UpdateOptions(t *testing.T) {
 	assert.Equal(t, newAddr, ipfixExporter.externalFlowCollectorAddr)
 	assert.Equal(t, newProto, ipfixExporter.externalFlowCollectorProto)
 
-	require.NoError(t, ipfixExporter.AddRecord(mockRecord, false))
+	require.NoError(t, ipfixExporter.AddRecord(mockRecord, nil, false))
 	assert.Equal(t, 2, setCount, "Invalid number of flow sets sent by exporter")
 }
 
@@ -233,7 +234,7 @@ func TestIPFIXExporter_AddRecord(t *testing.T) {
 	mockTempSet.EXPECT().AddRecord(gomock.Any(), testTemplateID).Return(nil)
 	mockIPFIXExpProc.EXPECT().SendSet(mockTempSet).Return(0, nil)
 
-	assert.NoError(t, ipfixExporter.AddRecord(mockRecord, false))
+	assert.NoError(t, ipfixExporter.AddRecord(mockRecord, nil, false))
 }
 
 func TestIPFIXExporter_initIPFIXExportingProcess_Error(t *testing.T) {
@@ -257,7 +258,7 @@ func TestIPFIXExporter_initIPFIXExportingProcess_Error(t *testing.T) {
 		externalFlowCollectorProto: "",
 	}
 
-	assert.Error(t, ipfixExporter.AddRecord(mockRecord, false))
+	assert.Error(t, ipfixExporter.AddRecord(mockRecord, nil, false))
 }
 
 func TestIPFIXExporter_sendRecord_Error(t *testing.T) {
@@ -286,5 +287,5 @@ func TestIPFIXExporter_sendRecord_Error(t *testing.T) {
 	mockIPFIXExpProc.EXPECT().SendSet(mockTempSet).Return(0, fmt.Errorf("send error"))
 	mockIPFIXExpProc.EXPECT().CloseConnToCollector()
 
-	assert.Error(t, ipfixExporter.AddRecord(mockRecord, false))
+	assert.Error(t, ipfixExporter.AddRecord(mockRecord, nil, false))
 }
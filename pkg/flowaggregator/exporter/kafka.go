@@ -0,0 +1,97 @@
+// Copyright 2022 Antrea Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package exporter
+
+import (
+	"os"
+	"reflect"
+
+	ipfixentities "github.com/vmware/go-ipfix/pkg/entities"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/klog/v2"
+
+	"antrea.io/antrea/pkg/flowaggregator/flowrecord"
+	"antrea.io/antrea/pkg/flowaggregator/kafkaproducer"
+	"antrea.io/antrea/pkg/flowaggregator/options"
+)
+
+type KafkaExporter struct {
+	kafkaInput           *kafkaproducer.KafkaInput
+	kafkaProducerProcess *kafkaproducer.KafkaProducerProcess
+}
+
+func buildKafkaInput(opt *options.Options) kafkaproducer.KafkaInput {
+	return kafkaproducer.KafkaInput{
+		Config:        opt.Config.Kafka,
+		FlushInterval: opt.KafkaFlushInterval,
+		SASLUsername:  os.Getenv("KAFKA_SASL_USERNAME"),
+		SASLPassword:  os.Getenv("KAFKA_SASL_PASSWORD"),
+		CACert:        os.Getenv("KAFKA_CA_CERT"),
+	}
+}
+
+func logKafkaConfig(msg string, kafkaInput *kafkaproducer.KafkaInput) {
+	config := kafkaInput.Config
+	klog.InfoS(msg, "brokers", config.Brokers, "topic", config.Topic, "encoding", config.Encoding, "partitionBy", config.PartitionBy, "maxBatchSize", config.MaxBatchSize, "flushInterval", kafkaInput.FlushInterval, "maxBufferedRecords", config.MaxBufferedRecords, "tls", config.TLS.Enable, "saslMechanism", config.SASL.Mechanism)
+}
+
+func NewKafkaExporter(k8sClient kubernetes.Interface, opt *options.Options) (*KafkaExporter, error) {
+	kafkaInput := buildKafkaInput(opt)
+	logKafkaConfig("Kafka configuration", &kafkaInput)
+	clusterUUID, err := getClusterUUID(k8sClient)
+	if err != nil {
+		return nil, err
+	}
+	kafkaProducerProcess, err := kafkaproducer.NewKafkaProducerProcess(kafkaInput, clusterUUID.String())
+	if err != nil {
+		return nil, err
+	}
+	return &KafkaExporter{
+		kafkaInput:           &kafkaInput,
+		kafkaProducerProcess: kafkaProducerProcess,
+	}, nil
+}
+
+func (e *KafkaExporter) AddRecord(record ipfixentities.Record, flowRecord *flowrecord.FlowRecord, isRecordIPv6 bool) error {
+	return e.kafkaProducerProcess.CacheRecord(flowRecord)
+}
+
+func (e *KafkaExporter) Start() {
+	e.kafkaProducerProcess.Start()
+}
+
+func (e *KafkaExporter) Stop() {
+	e.kafkaProducerProcess.Stop()
+}
+
+func (e *KafkaExporter) UpdateOptions(opt *options.Options) {
+	kafkaInput := buildKafkaInput(opt)
+	if reflect.DeepEqual(kafkaInput, *e.kafkaInput) {
+		return
+	}
+	klog.InfoS("Updating Kafka producer")
+	oldInput := *e.kafkaInput
+	oldInput.FlushInterval = kafkaInput.FlushInterval
+	if reflect.DeepEqual(kafkaInput, oldInput) {
+		// Only the flush interval has changed, there is no need to re-create
+		// the Kafka client.
+		e.kafkaProducerProcess.SetFlushInterval(kafkaInput.FlushInterval)
+	} else if err := e.kafkaProducerProcess.UpdateKafkaProducer(kafkaInput); err != nil {
+		klog.ErrorS(err, "Error when updating Kafka producer config")
+		return
+	}
+	e.kafkaInput = &kafkaInput
+	logKafkaConfig("New Kafka configuration", &kafkaInput)
+}
@@ -0,0 +1,82 @@
+// Copyright 2022 Antrea Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package exporter
+
+import (
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"antrea.io/antrea/pkg/config/flowaggregator"
+	"antrea.io/antrea/pkg/flowaggregator/kafkaproducer"
+	"antrea.io/antrea/pkg/flowaggregator/options"
+)
+
+func TestKafka_UpdateOptions(t *testing.T) {
+	opt := &options.Options{
+		Config: &flowaggregator.FlowAggregatorConfig{
+			Kafka: flowaggregator.KafkaConfig{
+				Enable:             true,
+				Brokers:            []string{"127.0.0.1:9092"},
+				Topic:              "defaultTopic",
+				Encoding:           kafkaproducer.EncodingJSON,
+				PartitionBy:        kafkaproducer.PartitionByNone,
+				MaxBatchSize:       1000,
+				MaxBufferedRecords: 100000,
+			},
+		},
+		KafkaFlushInterval: 1 * time.Second,
+	}
+	kafkaInput := buildKafkaInput(opt)
+	kafkaProducerProcess, err := kafkaproducer.NewKafkaProducerProcess(kafkaInput, uuid.New().String())
+	require.NoError(t, err)
+	kafkaExporter := KafkaExporter{kafkaInput: &kafkaInput, kafkaProducerProcess: kafkaProducerProcess}
+	kafkaExporter.Start()
+	assert.Equal(t, "defaultTopic", kafkaExporter.kafkaProducerProcess.GetTopic())
+	assert.Equal(t, "1s", kafkaExporter.kafkaProducerProcess.GetFlushInterval().String())
+
+	// Only the flush interval is updated.
+	newOpt := &options.Options{
+		Config: &flowaggregator.FlowAggregatorConfig{
+			Kafka: opt.Config.Kafka,
+		},
+		KafkaFlushInterval: 5 * time.Second,
+	}
+	kafkaExporter.UpdateOptions(newOpt)
+	assert.Equal(t, kafkaProducerProcess, kafkaExporter.kafkaProducerProcess)
+	assert.Equal(t, "5s", kafkaExporter.kafkaProducerProcess.GetFlushInterval().String())
+
+	newOpt = &options.Options{
+		Config: &flowaggregator.FlowAggregatorConfig{
+			Kafka: flowaggregator.KafkaConfig{
+				Enable:             true,
+				Brokers:            []string{"127.0.0.1:9093"},
+				Topic:              "testTopic",
+				Encoding:           kafkaproducer.EncodingProtobuf,
+				PartitionBy:        kafkaproducer.PartitionBySourceNode,
+				MaxBatchSize:       1000,
+				MaxBufferedRecords: 100000,
+			},
+		},
+		KafkaFlushInterval: 5 * time.Second,
+	}
+	kafkaExporter.UpdateOptions(newOpt)
+	assert.Equal(t, "testTopic", kafkaExporter.kafkaProducerProcess.GetTopic())
+	assert.Equal(t, "testTopic", kafkaExporter.kafkaInput.Config.Topic)
+	kafkaExporter.Stop()
+}
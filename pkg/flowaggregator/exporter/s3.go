@@ -19,6 +19,7 @@ import (
 	"k8s.io/client-go/kubernetes"
 	"k8s.io/klog/v2"
 
+	"antrea.io/antrea/pkg/flowaggregator/flowrecord"
 	"antrea.io/antrea/pkg/flowaggregator/options"
 	"antrea.io/antrea/pkg/flowaggregator/s3uploader"
 )
@@ -35,9 +36,14 @@ func buildS3Input(opt *options.Options) s3uploader.S3Input {
 	}
 }
 
+func logS3Config(msg string, s3Input *s3uploader.S3Input) {
+	config := s3Input.Config
+	klog.InfoS(msg, "bucketName", config.BucketName, "bucketPrefix", config.BucketPrefix, "region", config.Region, "recordFormat", config.RecordFormat, "compress", *config.Compress, "maxRecordsPerFile", config.MaxRecordsPerFile, "keyTemplate", config.KeyTemplate, "uploadInterval", s3Input.UploadInterval)
+}
+
 func NewS3Exporter(k8sClient kubernetes.Interface, opt *options.Options) (*S3Exporter, error) {
 	s3Input := buildS3Input(opt)
-	klog.InfoS("S3Uploader configuration", "bucketName", s3Input.Config.BucketName, "bucketPrefix", s3Input.Config.BucketPrefix, "region", s3Input.Config.Region, "recordFormat", s3Input.Config.RecordFormat, "compress", *s3Input.Config.Compress, "maxRecordsPerFile", s3Input.Config.MaxRecordsPerFile, "uploadInterval", s3Input.UploadInterval)
+	logS3Config("S3Uploader configuration", &s3Input)
 	clusterUUID, err := getClusterUUID(k8sClient)
 	if err != nil {
 		return nil, err
@@ -52,8 +58,8 @@ func NewS3Exporter(k8sClient kubernetes.Interface, opt *options.Options) (*S3Exp
 	}, nil
 }
 
-func (e *S3Exporter) AddRecord(record ipfixentities.Record, isRecordIPv6 bool) error {
-	e.s3UploadProcess.CacheRecord(record)
+func (e *S3Exporter) AddRecord(record ipfixentities.Record, flowRecord *flowrecord.FlowRecord, isRecordIPv6 bool) error {
+	e.s3UploadProcess.CacheRecord(flowRecord)
 	return nil
 }
 
@@ -68,13 +74,26 @@ func (e *S3Exporter) Stop() {
 func (e *S3Exporter) UpdateOptions(opt *options.Options) {
 	s3Input := buildS3Input(opt)
 	config := s3Input.Config
+	oldConfig := e.s3Input.Config
+	fileFormatChanged := config.RecordFormat != oldConfig.RecordFormat ||
+		*config.Compress != *oldConfig.Compress ||
+		config.MaxRecordsPerFile != oldConfig.MaxRecordsPerFile ||
+		config.KeyTemplate != oldConfig.KeyTemplate
 	if config.BucketName == e.s3UploadProcess.GetBucketName() &&
 		config.BucketPrefix == e.s3UploadProcess.GetBucketPrefix() &&
 		config.Region == e.s3UploadProcess.GetRegion() &&
-		s3Input.UploadInterval == e.s3UploadProcess.GetUploadInterval() {
+		s3Input.UploadInterval == e.s3UploadProcess.GetUploadInterval() &&
+		!fileFormatChanged {
 		return
 	}
 	klog.InfoS("Updating S3Uploader")
+	if fileFormatChanged {
+		err := e.s3UploadProcess.UpdateFileFormat(config.RecordFormat, *config.Compress, config.MaxRecordsPerFile, config.KeyTemplate)
+		if err != nil {
+			klog.ErrorS(err, "Error when updating S3Uploader file format")
+			return
+		}
+	}
 	if s3Input.UploadInterval != e.s3UploadProcess.GetUploadInterval() {
 		e.s3UploadProcess.SetUploadInterval(s3Input.UploadInterval)
 	}
@@ -87,5 +106,6 @@ func (e *S3Exporter) UpdateOptions(opt *options.Options) {
 			return
 		}
 	}
-	klog.InfoS("New S3Uploader configuration", "bucketName", s3Input.Config.BucketName, "bucketPrefix", s3Input.Config.BucketPrefix, "region", s3Input.Config.Region, "recordFormat", s3Input.Config.RecordFormat, "compress", *s3Input.Config.Compress, "maxRecordsPerFile", s3Input.Config.MaxRecordsPerFile, "uploadInterval", s3Input.Config.UploadInterval)
+	e.s3Input = &s3Input
+	logS3Config("New S3Uploader configuration", &s3Input)
 }
@@ -46,6 +46,7 @@ func TestS3_UpdateOptions(t *testing.T) {
 				RecordFormat:      "CSV",
 				Compress:          &compress,
 				MaxRecordsPerFile: 0,
+				KeyTemplate:       flowaggregator.DefaultS3KeyTemplate,
 			},
 		},
 		S3UploadInterval: 8 * time.Second,
@@ -67,9 +68,10 @@ func TestS3_UpdateOptions(t *testing.T) {
 				BucketName:        "testBucketName",
 				BucketPrefix:      "testBucketPrefix",
 				Region:            "us-west-1",
-				RecordFormat:      "CSV",
+				RecordFormat:      "Parquet",
 				Compress:          &compress,
 				MaxRecordsPerFile: 0,
+				KeyTemplate:       "year={{.Year}}/month={{.Month}}/day={{.Day}}/records-{{.Random}}.{{.Extension}}",
 			},
 		},
 		S3UploadInterval: 5 * time.Second,
@@ -79,5 +81,6 @@ func TestS3_UpdateOptions(t *testing.T) {
 	assert.Equal(t, s3Exporter.s3UploadProcess.GetBucketPrefix(), "testBucketPrefix")
 	assert.Equal(t, s3Exporter.s3UploadProcess.GetRegion(), "us-west-1")
 	assert.Equal(t, s3Exporter.s3UploadProcess.GetUploadInterval().String(), "5s")
+	assert.Equal(t, "Parquet", s3Exporter.s3Input.Config.RecordFormat)
 	s3Exporter.Stop()
 }
@@ -20,9 +20,10 @@
 package testing
 
 import (
+	flowrecord "antrea.io/antrea/pkg/flowaggregator/flowrecord"
 	options "antrea.io/antrea/pkg/flowaggregator/options"
-	gomock "github.com/golang/mock/gomock"
 	entities "github.com/vmware/go-ipfix/pkg/entities"
+	gomock "go.uber.org/mock/gomock"
 	reflect "reflect"
 )
 
@@ -50,17 +51,17 @@ func (m *MockInterface) EXPECT() *MockInterfaceMockRecorder {
 }
 
 // AddRecord mocks base method
-func (m *MockInterface) AddRecord(arg0 entities.Record, arg1 bool) error {
+func (m *MockInterface) AddRecord(arg0 entities.Record, arg1 *flowrecord.FlowRecord, arg2 bool) error {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "AddRecord", arg0, arg1)
+	ret := m.ctrl.Call(m, "AddRecord", arg0, arg1, arg2)
 	ret0, _ := ret[0].(error)
 	return ret0
 }
 
 // AddRecord indicates an expected call of AddRecord
-func (mr *MockInterfaceMockRecorder) AddRecord(arg0, arg1 interface{}) *gomock.Call {
+func (mr *MockInterfaceMockRecorder) AddRecord(arg0, arg1, arg2 interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AddRecord", reflect.TypeOf((*MockInterface)(nil).AddRecord), arg0, arg1)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AddRecord", reflect.TypeOf((*MockInterface)(nil).AddRecord), arg0, arg1, arg2)
 }
 
 // Start mocks base method
@@ -29,17 +29,27 @@ import (
 	ipfixintermediate "github.com/vmware/go-ipfix/pkg/intermediate"
 	ipfixregistry "github.com/vmware/go-ipfix/pkg/registry"
 	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/fields"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/util/wait"
 	coreinformers "k8s.io/client-go/informers/core/v1"
 	"k8s.io/client-go/kubernetes"
+	corelisters "k8s.io/client-go/listers/core/v1"
 	"k8s.io/client-go/tools/cache"
 	"k8s.io/klog/v2"
 
+	crdv1alpha2 "antrea.io/antrea/pkg/apis/crd/v1alpha2"
+	crdinformers "antrea.io/antrea/pkg/client/informers/externalversions/crd/v1alpha2"
 	flowaggregatorconfig "antrea.io/antrea/pkg/config/flowaggregator"
 	"antrea.io/antrea/pkg/flowaggregator/exporter"
+	"antrea.io/antrea/pkg/flowaggregator/flowfilter"
+	"antrea.io/antrea/pkg/flowaggregator/flowrecord"
 	"antrea.io/antrea/pkg/flowaggregator/infoelements"
 	"antrea.io/antrea/pkg/flowaggregator/options"
 	"antrea.io/antrea/pkg/flowaggregator/querier"
 	"antrea.io/antrea/pkg/ipfix"
+	"antrea.io/antrea/pkg/util/env"
 )
 
 var (
@@ -75,6 +85,10 @@ var (
 		"egressNetworkPolicyRuleAction",
 		"egressNetworkPolicyType",
 		"egressNetworkPolicyRuleName",
+		"appProtocolName",
+		"httpVals",
+		"dnsQueryName",
+		"dnsResponseCode",
 	}
 )
 
@@ -86,6 +100,10 @@ const (
 
 	// PodInfo index name for Pod cache.
 	podInfoIndex = "podInfo"
+	// Endpoint IP index name for ExternalEntity cache.
+	externalEntityIPIndex = "externalEntityIP"
+
+	drainPollInterval = 100 * time.Millisecond
 )
 
 // these are used for unit testing
@@ -99,6 +117,21 @@ var (
 	newS3Exporter = func(k8sClient kubernetes.Interface, opt *options.Options) (exporter.Interface, error) {
 		return exporter.NewS3Exporter(k8sClient, opt)
 	}
+	newKafkaExporter = func(k8sClient kubernetes.Interface, opt *options.Options) (exporter.Interface, error) {
+		return exporter.NewKafkaExporter(k8sClient, opt)
+	}
+	newFileExporter = func(k8sClient kubernetes.Interface, opt *options.Options) (exporter.Interface, error) {
+		return exporter.NewFileExporter(k8sClient, opt)
+	}
+	newFlowMetricsExporter = func(opt *options.Options) (exporter.Interface, error) {
+		return exporter.NewFlowMetricsExporter(opt)
+	}
+	newIPFIXCollectingProcess = func(input collector.CollectorInput) (ipfix.IPFIXCollectingProcess, error) {
+		return ipfix.NewIPFIXCollectingProcess(input)
+	}
+	// drainGracePeriod is the maximum time the Flow Aggregator waits for the
+	// correlation of the pending flow records when it is stopped.
+	drainGracePeriod = 5 * time.Second
 )
 
 type flowAggregator struct {
@@ -112,6 +145,9 @@ type flowAggregator struct {
 	includePodLabels            bool
 	k8sClient                   kubernetes.Interface
 	podInformer                 coreinformers.PodInformer
+	namespaceInformer           coreinformers.NamespaceInformer
+	externalEntityInformer      crdinformers.ExternalEntityInformer
+	egressInformer              crdinformers.EgressInformer
 	numRecordsExported          int64
 	numRecordsReceived          int64
 	updateCh                    chan *options.Options
@@ -122,12 +158,40 @@ type flowAggregator struct {
 	ipfixExporter               exporter.Interface
 	clickHouseExporter          exporter.Interface
 	s3Exporter                  exporter.Interface
+	kafkaExporter               exporter.Interface
+	fileExporter                exporter.Interface
+	flowMetricsExporter         exporter.Interface
+	ipfixFilter                 *flowfilter.Filter
+	clickHouseFilter            *flowfilter.Filter
+	s3Filter                    *flowfilter.Filter
+	kafkaFilter                 *flowfilter.Filter
+	fileFilter                  *flowfilter.Filter
+	flowMetricsFilter           *flowfilter.Filter
 	logTickerDuration           time.Duration
+
+	// collectingProcessMutex protects collectingProcess and
+	// numRecordsReceived, as the collecting process is restarted when the
+	// server certificate is reissued.
+	collectingProcessMutex sync.RWMutex
+	collectorInput         collector.CollectorInput
+	// aggregationMsgCh is the channel from which the aggregation process
+	// reads the messages received by the collecting process.
+	aggregationMsgCh chan *ipfixentities.Message
+	stopForwardingCh chan struct{}
+	// caSecretInformer watches the CA Secret shared by all the replicas when
+	// TLS is used between the Flow Exporters and the Flow Aggregator.
+	caSecretInformer cache.SharedIndexInformer
+	caSecretLister   corelisters.SecretLister
+	caUpdateCh       chan struct{}
+	podName          string
 }
 
 func NewFlowAggregator(
 	k8sClient kubernetes.Interface,
 	podInformer coreinformers.PodInformer,
+	namespaceInformer coreinformers.NamespaceInformer,
+	externalEntityInformer crdinformers.ExternalEntityInformer,
+	egressInformer crdinformers.EgressInformer,
 	configFile string,
 ) (*flowAggregator, error) {
 	if len(configFile) == 0 {
@@ -166,12 +230,20 @@ func NewFlowAggregator(
 		includePodLabels:            opt.Config.RecordContents.PodLabels,
 		k8sClient:                   k8sClient,
 		podInformer:                 podInformer,
+		namespaceInformer:           namespaceInformer,
+		externalEntityInformer:      externalEntityInformer,
+		egressInformer:              egressInformer,
 		updateCh:                    make(chan *options.Options),
 		configFile:                  configFile,
 		configWatcher:               configWatcher,
 		configData:                  data,
 		APIServer:                   opt.Config.APIServer,
 		logTickerDuration:           time.Minute,
+		podName:                     env.GetPodName(),
+	}
+	fa.setFilters(opt)
+	if fa.aggregatorTransportProtocol == flowaggregatorconfig.AggregatorTransportProtocolTLS {
+		fa.initCASecretInformer()
 	}
 	err = fa.InitCollectingProcess()
 	if err != nil {
@@ -195,10 +267,32 @@ func NewFlowAggregator(
 			return nil, fmt.Errorf("error when creating S3 export process: %v", err)
 		}
 	}
+	if opt.Config.Kafka.Enable {
+		var err error
+		fa.kafkaExporter, err = newKafkaExporter(k8sClient, opt)
+		if err != nil {
+			return nil, fmt.Errorf("error when creating Kafka export process: %v", err)
+		}
+	}
+	if opt.Config.FileExporter.Enable {
+		var err error
+		fa.fileExporter, err = newFileExporter(k8sClient, opt)
+		if err != nil {
+			return nil, fmt.Errorf("error when creating file export process: %v", err)
+		}
+	}
+	if opt.Config.FlowMetrics.Enable {
+		var err error
+		fa.flowMetricsExporter, err = newFlowMetricsExporter(opt)
+		if err != nil {
+			return nil, fmt.Errorf("error when creating flow metrics export process: %v", err)
+		}
+	}
 	if opt.Config.FlowCollector.Enable {
 		fa.ipfixExporter = newIPFIXExporter(k8sClient, opt, registry)
 	}
 	podInformer.Informer().AddIndexers(cache.Indexers{podInfoIndex: podInfoIndexFunc})
+	externalEntityInformer.Informer().AddIndexers(cache.Indexers{externalEntityIPIndex: externalEntityIPIndexFunc})
 	return fa, nil
 }
 
@@ -217,26 +311,34 @@ func podInfoIndexFunc(obj interface{}) ([]string, error) {
 	return nil, nil
 }
 
+func externalEntityIPIndexFunc(obj interface{}) ([]string, error) {
+	ee, ok := obj.(*crdv1alpha2.ExternalEntity)
+	if !ok {
+		return nil, fmt.Errorf("obj is not ExternalEntity: %+v", obj)
+	}
+	var indexes []string
+	for _, endpoint := range ee.Spec.Endpoints {
+		if endpoint.IP != "" {
+			indexes = append(indexes, endpoint.IP)
+		}
+	}
+	return indexes, nil
+}
+
 func (fa *flowAggregator) InitCollectingProcess() error {
 	var cpInput collector.CollectorInput
 	if fa.aggregatorTransportProtocol == flowaggregatorconfig.AggregatorTransportProtocolTLS {
-		parentCert, privateKey, caCert, err := generateCACertKey()
+		// The CA certificate and the client certificate are published to
+		// the Flow Exporters by the leader of the replicas, see
+		// runLeaderElection.
+		parentCert, privateKey, caCert, err := getOrCreateCACertKey(fa.k8sClient)
 		if err != nil {
-			return fmt.Errorf("error when generating CA certificate: %v", err)
+			return fmt.Errorf("error when getting CA certificate: %v", err)
 		}
-		serverCert, serverKey, err := generateCertKey(parentCert, privateKey, true, fa.flowAggregatorAddress)
+		serverCert, serverKey, err := generateCertKey(parentCert, privateKey, true, fa.flowAggregatorAddress, env.GetPodIP())
 		if err != nil {
 			return fmt.Errorf("error when creating server certificate: %v", err)
 		}
-
-		clientCert, clientKey, err := generateCertKey(parentCert, privateKey, false, "")
-		if err != nil {
-			return fmt.Errorf("error when creating client certificate: %v", err)
-		}
-		err = syncCAAndClientCert(caCert, clientCert, clientKey, fa.k8sClient)
-		if err != nil {
-			return fmt.Errorf("error when synchronizing client certificate: %v", err)
-		}
 		cpInput = collector.CollectorInput{
 			Address:       collectorAddress,
 			Protocol:      tcpTransport,
@@ -267,14 +369,125 @@ func (fa *flowAggregator) InitCollectingProcess() error {
 	cpInput.NumExtraElements = len(infoelements.AntreaSourceStatsElementList) + len(infoelements.AntreaDestinationStatsElementList) + len(infoelements.AntreaLabelsElementList) +
 		len(infoelements.AntreaFlowEndSecondsElementList) + len(infoelements.AntreaThroughputElementList) + len(infoelements.AntreaSourceThroughputElementList) + len(infoelements.AntreaDestinationThroughputElementList)
 	var err error
-	fa.collectingProcess, err = ipfix.NewIPFIXCollectingProcess(cpInput)
+	fa.collectingProcess, err = newIPFIXCollectingProcess(cpInput)
+	fa.collectorInput = cpInput
 	return err
 }
 
+func (fa *flowAggregator) initCASecretInformer() {
+	fa.caSecretInformer = coreinformers.NewFilteredSecretInformer(
+		fa.k8sClient,
+		CASecretNamespace,
+		0,
+		cache.Indexers{},
+		func(options *metav1.ListOptions) {
+			options.FieldSelector = fields.OneTermEqualSelector("metadata.name", CASecretName).String()
+		},
+	)
+	fa.caSecretLister = corelisters.NewSecretLister(fa.caSecretInformer.GetIndexer())
+	fa.caUpdateCh = make(chan struct{}, 1)
+	enqueueCAUpdate := func() {
+		select {
+		case fa.caUpdateCh <- struct{}{}:
+		default:
+		}
+	}
+	fa.caSecretInformer.AddEventHandler(cache.ResourceEventHandlerFuncs{
+		AddFunc: func(obj interface{}) {
+			enqueueCAUpdate()
+		},
+		UpdateFunc: func(oldObj, newObj interface{}) {
+			enqueueCAUpdate()
+		},
+	})
+}
+
+// reloadServerCertificate reissues the server certificate of this replica when
+// the CA shared by all the replicas has changed, e.g. after it has been renewed
+// by the leader. The collecting process is then restarted with the new server
+// certificate, and the Flow Exporters reconnect to it using the new CA.
+func (fa *flowAggregator) reloadServerCertificate(stopCh <-chan struct{}) error {
+	secret, err := fa.caSecretLister.Secrets(CASecretNamespace).Get(CASecretName)
+	if err != nil {
+		return fmt.Errorf("error when getting Secret %s: %v", CASecretName, err)
+	}
+	if bytes.Equal(secret.Data[corev1.TLSCertKey], fa.collectorInput.CACert) {
+		return nil
+	}
+	caCert, caKey, caPEM, err := parseCACertKey(secret)
+	if err != nil {
+		// The leader replaces an invalid CA.
+		return fmt.Errorf("invalid CA certificate in Secret %s: %v", CASecretName, err)
+	}
+	serverCert, serverKey, err := generateCertKey(caCert, caKey, true, fa.flowAggregatorAddress, env.GetPodIP())
+	if err != nil {
+		return fmt.Errorf("error when creating server certificate: %v", err)
+	}
+	klog.InfoS("CA certificate has changed, restarting collecting process with a new server certificate", "secret", klog.KObj(secret))
+	cpInput := fa.collectorInput
+	cpInput.CACert = caPEM
+	cpInput.ServerCert = serverCert
+	cpInput.ServerKey = serverKey
+	return fa.restartCollectingProcess(cpInput, stopCh)
+}
+
+// restartCollectingProcess replaces the collecting process with a new one
+// created from cpInput. The aggregation process, and therefore the flow records
+// which are being aggregated, are preserved: the messages received by the new
+// collecting process are forwarded to the aggregation process.
+func (fa *flowAggregator) restartCollectingProcess(cpInput collector.CollectorInput, stopCh <-chan struct{}) error {
+	cp, err := newIPFIXCollectingProcess(cpInput)
+	if err != nil {
+		return fmt.Errorf("error when creating collecting process: %v", err)
+	}
+	// The new collecting process listens on the same address, so the
+	// current one has to be stopped first. Its messages keep being forwarded
+	// until it is stopped.
+	fa.collectingProcess.Stop()
+	if fa.stopForwardingCh != nil {
+		close(fa.stopForwardingCh)
+	}
+	fa.collectingProcessMutex.Lock()
+	fa.numRecordsReceived += fa.collectingProcess.GetNumRecordsReceived()
+	fa.collectingProcess = cp
+	fa.collectingProcessMutex.Unlock()
+	fa.collectorInput = cpInput
+	fa.stopForwardingCh = make(chan struct{})
+	go fa.forwardMessages(cp.GetMsgChan(), fa.stopForwardingCh, stopCh)
+	go cp.Start()
+	return nil
+}
+
+func (fa *flowAggregator) forwardMessages(msgCh <-chan *ipfixentities.Message, stopForwardingCh, stopCh <-chan struct{}) {
+	for {
+		select {
+		case msg := <-msgCh:
+			select {
+			case fa.aggregationMsgCh <- msg:
+			case <-stopCh:
+				return
+			}
+		case <-stopForwardingCh:
+			return
+		case <-stopCh:
+			return
+		}
+	}
+}
+
+func (fa *flowAggregator) getCollectingProcess() ipfix.IPFIXCollectingProcess {
+	fa.collectingProcessMutex.RLock()
+	defer fa.collectingProcessMutex.RUnlock()
+	return fa.collectingProcess
+}
+
 func (fa *flowAggregator) InitAggregationProcess() error {
 	var err error
+	// The aggregation process keeps reading from the message channel of the
+	// initial collecting process if the collecting process is restarted.
+	fa.aggregationMsgCh = fa.collectingProcess.GetMsgChan()
 	apInput := ipfixintermediate.AggregationInput{
-		MessageChan:           fa.collectingProcess.GetMsgChan(),
+		MessageChan:           fa.aggregationMsgCh,
 		WorkerNum:             aggregationWorkerNum,
 		CorrelateFields:       correlateFields,
 		ActiveExpiryTimeout:   fa.activeFlowRecordTimeout,
@@ -286,8 +499,9 @@ func (fa *flowAggregator) InitAggregationProcess() error {
 }
 
 func (fa *flowAggregator) Run(stopCh <-chan struct{}) {
+	// The collecting process is stopped by flowExportLoop, before the flow
+	// records are drained.
 	go fa.collectingProcess.Start()
-	defer fa.collectingProcess.Stop()
 	go fa.aggregationProcess.Start()
 	defer fa.aggregationProcess.Stop()
 	if fa.ipfixExporter != nil {
@@ -299,6 +513,15 @@ func (fa *flowAggregator) Run(stopCh <-chan struct{}) {
 	if fa.s3Exporter != nil {
 		fa.s3Exporter.Start()
 	}
+	if fa.kafkaExporter != nil {
+		fa.kafkaExporter.Start()
+	}
+	if fa.fileExporter != nil {
+		fa.fileExporter.Start()
+	}
+	if fa.flowMetricsExporter != nil {
+		fa.flowMetricsExporter.Start()
+	}
 	var wg sync.WaitGroup
 	wg.Add(1)
 	go func() {
@@ -309,6 +532,12 @@ func (fa *flowAggregator) Run(stopCh <-chan struct{}) {
 		fa.flowExportLoop(stopCh)
 	}()
 	go fa.watchConfiguration(stopCh)
+	if fa.caSecretInformer != nil {
+		go fa.caSecretInformer.Run(stopCh)
+	}
+	if fa.k8sClient != nil && fa.podName != "" {
+		go fa.runLeaderElection(stopCh)
+	}
 	<-stopCh
 	wg.Wait()
 }
@@ -334,11 +563,21 @@ func (fa *flowAggregator) flowExportLoop(stopCh <-chan struct{}) {
 		if fa.s3Exporter != nil {
 			fa.s3Exporter.Stop()
 		}
+		if fa.kafkaExporter != nil {
+			fa.kafkaExporter.Stop()
+		}
+		if fa.fileExporter != nil {
+			fa.fileExporter.Stop()
+		}
+		if fa.flowMetricsExporter != nil {
+			fa.flowMetricsExporter.Stop()
+		}
 	}()
 	updateCh := fa.updateCh
 	for {
 		select {
 		case <-stopCh:
+			fa.drainFlowRecords()
 			return
 		case <-expireTimer.C:
 			// Pop the flow record item from expire priority queue in the Aggregation
@@ -352,10 +591,14 @@ func (fa *flowAggregator) flowExportLoop(stopCh <-chan struct{}) {
 			expireTimer.Reset(fa.aggregationProcess.GetExpiryFromExpirePriorityQueue())
 		case <-logTicker.C:
 			// Add visibility of processing stats of Flow Aggregator
-			klog.V(4).InfoS("Total number of records received", "count", fa.collectingProcess.GetNumRecordsReceived())
+			klog.V(4).InfoS("Total number of records received", "count", fa.numRecordsReceived+fa.collectingProcess.GetNumRecordsReceived())
 			klog.V(4).InfoS("Total number of records exported by each active exporter", "count", fa.numRecordsExported)
 			klog.V(4).InfoS("Total number of flows stored in Flow Aggregator", "count", fa.aggregationProcess.GetNumFlows())
 			klog.V(4).InfoS("Number of exporters connected with Flow Aggregator", "count", fa.collectingProcess.GetNumConnToCollector())
+		case <-fa.caUpdateCh:
+			if err := fa.reloadServerCertificate(stopCh); err != nil {
+				klog.ErrorS(err, "Error when reloading server certificate")
+			}
 		case opt, ok := <-updateCh:
 			if !ok {
 				// set the channel to nil and essentially disable this select case.
@@ -370,6 +613,40 @@ func (fa *flowAggregator) flowExportLoop(stopCh <-chan struct{}) {
 	}
 }
 
+// drainFlowRecords exports all the flow records before the Flow Aggregator
+// stops, so that the statistics aggregated since the last export are not lost
+// when a replica is stopped, e.g. during an upgrade. The collecting process is
+// stopped first, so that no new record is received. Then the records which are
+// waiting for the record from the other Node are given up to drainGracePeriod
+// to be correlated, and the ones which are still not correlated after that are
+// exported as they are.
+func (fa *flowAggregator) drainFlowRecords() {
+	// The collecting process may have been restarted by flowExportLoop.
+	fa.getCollectingProcess().Stop()
+	if err := wait.PollImmediate(drainPollInterval, drainGracePeriod, func() (bool, error) {
+		return len(fa.aggregationMsgCh) == 0 && fa.numUncorrelatedRecords() == 0, nil
+	}); err != nil {
+		klog.InfoS("Exporting flow records which are not correlated yet", "count", fa.numUncorrelatedRecords())
+	}
+	klog.InfoS("Exporting flow records before stopping", "count", fa.aggregationProcess.GetNumFlows())
+	if err := fa.aggregationProcess.ForAllRecordsDo(fa.sendFlowKeyRecord); err != nil {
+		klog.ErrorS(err, "Error when exporting flow records before stopping")
+	}
+}
+
+// numUncorrelatedRecords returns the number of flow records which are waiting
+// for the record from the other Node.
+func (fa *flowAggregator) numUncorrelatedRecords() int {
+	count := 0
+	fa.aggregationProcess.ForAllRecordsDo(func(key ipfixintermediate.FlowKey, record *ipfixintermediate.AggregationFlowRecord) error {
+		if !record.ReadyToSend {
+			count++
+		}
+		return nil
+	})
+	return count
+}
+
 func (fa *flowAggregator) sendFlowKeyRecord(key ipfixintermediate.FlowKey, record *ipfixintermediate.AggregationFlowRecord) error {
 	isRecordIPv4 := fa.aggregationProcess.IsAggregatedRecordIPv4(*record)
 	if !fa.aggregationProcess.AreCorrelatedFieldsFilled(*record) {
@@ -380,18 +657,51 @@ func (fa *flowAggregator) sendFlowKeyRecord(key ipfixintermediate.FlowKey, recor
 		fa.fillPodLabels(key, record.Record)
 		fa.aggregationProcess.SetExternalFieldsFilled(record)
 	}
-	if fa.ipfixExporter != nil {
-		if err := fa.ipfixExporter.AddRecord(record.Record, !isRecordIPv4); err != nil {
+	// The flow record is built once, and shared by the filters and the
+	// exporters.
+	flowRecord := flowrecord.GetFlowRecord(record.Record)
+	flowRecord.SourceEndpointKind = fa.getEndpointKind(key.SourceAddress, flowRecord.SourcePodName, flowRecord.SourceNodeName)
+	flowRecord.DestinationEndpointKind = fa.getEndpointKind(key.DestinationAddress, flowRecord.DestinationPodName, flowRecord.DestinationNodeName)
+	if flowRecord.FlowType == ipfixregistry.FlowTypeToExternal {
+		if egress := fa.lookupEgressForPod(flowRecord.SourcePodNamespace, flowRecord.SourcePodName); egress != nil {
+			flowRecord.EgressName = egress.Name
+			flowRecord.EgressIP = egress.Spec.EgressIP
+			flowRecord.EgressNodeName = egress.Status.EgressNode
+		}
+	}
+	selected := func(filter *flowfilter.Filter) bool {
+		if filter == nil {
+			return true
+		}
+		return filter.Match(flowRecord)
+	}
+	if fa.ipfixExporter != nil && selected(fa.ipfixFilter) {
+		if err := fa.ipfixExporter.AddRecord(record.Record, flowRecord, !isRecordIPv4); err != nil {
 			return err
 		}
 	}
-	if fa.clickHouseExporter != nil {
-		if err := fa.clickHouseExporter.AddRecord(record.Record, !isRecordIPv4); err != nil {
+	if fa.clickHouseExporter != nil && selected(fa.clickHouseFilter) {
+		if err := fa.clickHouseExporter.AddRecord(record.Record, flowRecord, !isRecordIPv4); err != nil {
 			return err
 		}
 	}
-	if fa.s3Exporter != nil {
-		if err := fa.s3Exporter.AddRecord(record.Record, !isRecordIPv4); err != nil {
+	if fa.s3Exporter != nil && selected(fa.s3Filter) {
+		if err := fa.s3Exporter.AddRecord(record.Record, flowRecord, !isRecordIPv4); err != nil {
+			return err
+		}
+	}
+	if fa.kafkaExporter != nil && selected(fa.kafkaFilter) {
+		if err := fa.kafkaExporter.AddRecord(record.Record, flowRecord, !isRecordIPv4); err != nil {
+			return err
+		}
+	}
+	if fa.fileExporter != nil && selected(fa.fileFilter) {
+		if err := fa.fileExporter.AddRecord(record.Record, flowRecord, !isRecordIPv4); err != nil {
+			return err
+		}
+	}
+	if fa.flowMetricsExporter != nil && selected(fa.flowMetricsFilter) {
+		if err := fa.flowMetricsExporter.AddRecord(record.Record, flowRecord, !isRecordIPv4); err != nil {
 			return err
 		}
 	}
@@ -408,59 +718,200 @@ func (fa *flowAggregator) fillK8sMetadata(key ipfixintermediate.FlowKey, record
 	// fill source Pod info when sourcePodName is empty
 	if sourcePodName, _, exist := record.GetInfoElementWithValue("sourcePodName"); exist {
 		if sourcePodName.GetStringValue() == "" {
-			pods, err := fa.podInformer.Informer().GetIndexer().ByIndex(podInfoIndex, key.SourceAddress)
-			if err == nil && len(pods) > 0 {
-				pod, ok := pods[0].(*corev1.Pod)
-				if !ok {
-					klog.Warningf("Invalid Pod obj in cache")
-				}
+			pod, nodeName := fa.lookupPodByIP(key.SourceAddress)
+			if nodeName == "" {
+				nodeName = fa.lookupExternalNodeByIP(key.SourceAddress)
+			}
+			if pod != nil {
 				sourcePodName.SetStringValue(pod.Name)
 				if sourcePodNamespace, _, exist := record.GetInfoElementWithValue("sourcePodNamespace"); exist {
 					sourcePodNamespace.SetStringValue(pod.Namespace)
 				}
-				if sourceNodeName, _, exist := record.GetInfoElementWithValue("sourceNodeName"); exist {
-					sourceNodeName.SetStringValue(pod.Spec.NodeName)
-				}
-			} else {
-				klog.Warning(err)
+			}
+			if sourceNodeName, _, exist := record.GetInfoElementWithValue("sourceNodeName"); exist && nodeName != "" {
+				sourceNodeName.SetStringValue(nodeName)
 			}
 		}
 	}
 	// fill destination Pod info when destinationPodName is empty
 	if destinationPodName, _, exist := record.GetInfoElementWithValue("destinationPodName"); exist {
 		if destinationPodName.GetStringValue() == "" {
-			pods, err := fa.podInformer.Informer().GetIndexer().ByIndex(podInfoIndex, key.DestinationAddress)
-			if len(pods) > 0 && err == nil {
-				pod, ok := pods[0].(*corev1.Pod)
-				if !ok {
-					klog.Warningf("Invalid Pod obj in cache")
-				}
+			pod, nodeName := fa.lookupPodByIP(key.DestinationAddress)
+			if nodeName == "" {
+				nodeName = fa.lookupExternalNodeByIP(key.DestinationAddress)
+			}
+			if pod != nil {
 				destinationPodName.SetStringValue(pod.Name)
 				if destinationPodNamespace, _, exist := record.GetInfoElementWithValue("destinationPodNamespace"); exist {
 					destinationPodNamespace.SetStringValue(pod.Namespace)
 				}
-				if destinationNodeName, _, exist := record.GetInfoElementWithValue("destinationNodeName"); exist {
-					destinationNodeName.SetStringValue(pod.Spec.NodeName)
-				}
-			} else {
-				klog.Warning(err)
+			}
+			if destinationNodeName, _, exist := record.GetInfoElementWithValue("destinationNodeName"); exist && nodeName != "" {
+				destinationNodeName.SetStringValue(nodeName)
 			}
 		}
 	}
 }
 
-func (fa *flowAggregator) fetchPodLabels(podAddress string) string {
-	pods, err := fa.podInformer.Informer().GetIndexer().ByIndex(podInfoIndex, podAddress)
+// lookupPodByIP returns the Pod with the provided IP and the name of its Node.
+// hostNetwork Pods share the IP of their Node, so when several Pods running on
+// the same Node have the IP, the flow cannot be attributed to one of them: no
+// Pod is returned, but the Node name still is.
+func (fa *flowAggregator) lookupPodByIP(ip string) (*corev1.Pod, string) {
+	objs, err := fa.podInformer.Informer().GetIndexer().ByIndex(podInfoIndex, ip)
 	if err != nil {
-		klog.Warning(err)
-		return ""
-	} else if len(pods) == 0 {
-		klog.InfoS("No Pod objects found for Pod Address", "podAddress", podAddress)
-		return ""
+		klog.ErrorS(err, "Error when looking up Pods by IP", "ip", ip)
+		return nil, ""
+	}
+	var pod *corev1.Pod
+	for _, obj := range objs {
+		p, ok := obj.(*corev1.Pod)
+		if !ok {
+			klog.Warningf("Invalid Pod obj in cache")
+			return nil, ""
+		}
+		if pod != nil && p.Spec.NodeName != pod.Spec.NodeName {
+			klog.V(4).InfoS("IP is used by Pods running on different Nodes", "ip", ip)
+			return nil, ""
+		}
+		pod = p
+	}
+	if pod == nil {
+		return nil, ""
+	}
+	if len(objs) > 1 {
+		return nil, pod.Spec.NodeName
+	}
+	return pod, pod.Spec.NodeName
+}
+
+// lookupExternalEntityByIP returns the ExternalEntity with the provided endpoint
+// IP, or nil if there is none.
+func (fa *flowAggregator) lookupExternalEntityByIP(ip string) *crdv1alpha2.ExternalEntity {
+	objs, err := fa.externalEntityInformer.Informer().GetIndexer().ByIndex(externalEntityIPIndex, ip)
+	if err != nil {
+		klog.ErrorS(err, "Error when looking up ExternalEntities by IP", "ip", ip)
+		return nil
+	}
+	if len(objs) == 0 {
+		return nil
 	}
-	pod, ok := pods[0].(*corev1.Pod)
+	ee, ok := objs[0].(*crdv1alpha2.ExternalEntity)
 	if !ok {
-		klog.Warningf("Invalid Pod obj in cache")
+		klog.Warningf("Invalid ExternalEntity obj in cache")
+		return nil
+	}
+	return ee
+}
+
+// lookupExternalNodeByIP returns the name of the ExternalNode of the
+// ExternalEntity with the provided endpoint IP.
+func (fa *flowAggregator) lookupExternalNodeByIP(ip string) string {
+	ee := fa.lookupExternalEntityByIP(ip)
+	if ee == nil {
+		return ""
+	}
+	return ee.Spec.ExternalNode
+}
+
+// getEndpointKind returns the kind of the endpoint of a flow with the provided
+// IP. podName and nodeName are the Pod name and the Node name of the endpoint
+// in the flow record.
+func (fa *flowAggregator) getEndpointKind(ip, podName, nodeName string) flowrecord.EndpointKind {
+	pod, podNodeName := fa.lookupPodByIP(ip)
+	if pod != nil {
+		if pod.Spec.HostNetwork {
+			return flowrecord.EndpointKindNode
+		}
+		return flowrecord.EndpointKindPod
+	}
+	// The IP is shared by several hostNetwork Pods running on the same
+	// Node.
+	if podNodeName != "" {
+		return flowrecord.EndpointKindNode
+	}
+	// The Pod may have been deleted since the flow was exported.
+	if podName != "" {
+		return flowrecord.EndpointKindPod
+	}
+	if fa.lookupExternalEntityByIP(ip) != nil {
+		return flowrecord.EndpointKindExternalEntity
+	}
+	// The Flow Exporter only sets the Node name of endpoints which are not
+	// Pods when they are local to the Node, e.g. the gateway IP.
+	if nodeName != "" {
+		return flowrecord.EndpointKindNode
+	}
+	return flowrecord.EndpointKindExternal
+}
+
+// lookupEgressForPod returns the Egress applied to the Pod with the provided
+// Namespace and name, or nil if there is none. When several Egresses are applied
+// to the Pod, the Antrea Agent picks one of them, and the oldest one is returned,
+// which may not be the one picked by the Agent. The Egresses whose Egress IP is
+// not allocated yet are ignored.
+func (fa *flowAggregator) lookupEgressForPod(namespace, name string) *crdv1alpha2.Egress {
+	if name == "" {
+		return nil
+	}
+	pod, err := fa.podInformer.Lister().Pods(namespace).Get(name)
+	if err != nil {
+		klog.V(4).InfoS("Pod not found when looking up its Egress", "pod", klog.KRef(namespace, name))
+		return nil
+	}
+	// The Egress does not apply to hostNetwork Pods.
+	if pod.Spec.HostNetwork {
+		return nil
+	}
+	var namespaceLabels labels.Set
+	if ns, err := fa.namespaceInformer.Lister().Get(namespace); err == nil {
+		namespaceLabels = ns.Labels
+	}
+	egresses, err := fa.egressInformer.Lister().List(labels.Everything())
+	if err != nil {
+		klog.ErrorS(err, "Error when listing Egresses")
+		return nil
+	}
+	var selected *crdv1alpha2.Egress
+	for _, egress := range egresses {
+		if egress.Spec.EgressIP == "" || !egressAppliesTo(egress, pod.Labels, namespaceLabels) {
+			continue
+		}
+		if selected == nil || egress.CreationTimestamp.Before(&selected.CreationTimestamp) ||
+			(egress.CreationTimestamp.Equal(&selected.CreationTimestamp) && egress.Name < selected.Name) {
+			selected = egress
+		}
+	}
+	return selected
+}
+
+// egressAppliesTo returns whether the appliedTo of the Egress selects the Pod with
+// the provided labels, in the Namespace with the provided labels.
+func egressAppliesTo(egress *crdv1alpha2.Egress, podLabels, namespaceLabels labels.Set) bool {
+	appliedTo := egress.Spec.AppliedTo
+	if appliedTo.PodSelector == nil && appliedTo.NamespaceSelector == nil {
+		return false
+	}
+	if appliedTo.PodSelector != nil {
+		selector, err := metav1.LabelSelectorAsSelector(appliedTo.PodSelector)
+		if err != nil || !selector.Matches(podLabels) {
+			return false
+		}
+	}
+	if appliedTo.NamespaceSelector != nil {
+		selector, err := metav1.LabelSelectorAsSelector(appliedTo.NamespaceSelector)
+		if err != nil || !selector.Matches(namespaceLabels) {
+			return false
+		}
+	}
+	return true
+}
+
+func (fa *flowAggregator) fetchPodLabels(podAddress string) string {
+	pod, _ := fa.lookupPodByIP(podAddress)
+	if pod == nil {
+		klog.V(4).InfoS("No single Pod object found for Pod Address", "podAddress", podAddress)
+		return ""
 	}
 	labelsJSON, err := json.Marshal(pod.GetLabels())
 	if err != nil {
@@ -506,9 +957,11 @@ func (fa *flowAggregator) GetFlowRecords(flowKey *ipfixintermediate.FlowKey) []m
 }
 
 func (fa *flowAggregator) GetRecordMetrics() querier.Metrics {
+	fa.collectingProcessMutex.RLock()
+	defer fa.collectingProcessMutex.RUnlock()
 	return querier.Metrics{
 		NumRecordsExported: fa.numRecordsExported,
-		NumRecordsReceived: fa.collectingProcess.GetNumRecordsReceived(),
+		NumRecordsReceived: fa.numRecordsReceived + fa.collectingProcess.GetNumRecordsReceived(),
 		NumFlows:           fa.aggregationProcess.GetNumFlows(),
 		NumConnToCollector: fa.collectingProcess.GetNumConnToCollector(),
 	}
@@ -564,7 +1017,18 @@ func (fa *flowAggregator) handleWatcherEvent() error {
 	return nil
 }
 
+// setFilters sets the filters selecting the flow records sent to each exporter.
+func (fa *flowAggregator) setFilters(opt *options.Options) {
+	fa.ipfixFilter = opt.FlowCollectorFilter
+	fa.clickHouseFilter = opt.ClickHouseFilter
+	fa.s3Filter = opt.S3UploaderFilter
+	fa.kafkaFilter = opt.KafkaFilter
+	fa.fileFilter = opt.FileExporterFilter
+	fa.flowMetricsFilter = opt.FlowMetricsFilter
+}
+
 func (fa *flowAggregator) updateFlowAggregator(opt *options.Options) {
+	fa.setFilters(opt)
 	if opt.Config.FlowCollector.Enable {
 		if fa.ipfixExporter == nil {
 			klog.InfoS("Enabling Flow-Collector")
@@ -626,4 +1090,70 @@ func (fa *flowAggregator) updateFlowAggregator(opt *options.Options) {
 			klog.InfoS("Disabled S3Uploader")
 		}
 	}
+	if opt.Config.Kafka.Enable {
+		if fa.kafkaExporter == nil {
+			klog.InfoS("Enabling Kafka")
+			var err error
+			fa.kafkaExporter, err = newKafkaExporter(fa.k8sClient, opt)
+			if err != nil {
+				klog.ErrorS(err, "Error when creating Kafka export process")
+				return
+			}
+			fa.kafkaExporter.Start()
+			klog.InfoS("Enabled Kafka")
+		} else {
+			fa.kafkaExporter.UpdateOptions(opt)
+		}
+	} else {
+		if fa.kafkaExporter != nil {
+			klog.InfoS("Disabling Kafka")
+			fa.kafkaExporter.Stop()
+			fa.kafkaExporter = nil
+			klog.InfoS("Disabled Kafka")
+		}
+	}
+	if opt.Config.FileExporter.Enable {
+		if fa.fileExporter == nil {
+			klog.InfoS("Enabling file exporter")
+			var err error
+			fa.fileExporter, err = newFileExporter(fa.k8sClient, opt)
+			if err != nil {
+				klog.ErrorS(err, "Error when creating file export process")
+				return
+			}
+			fa.fileExporter.Start()
+			klog.InfoS("Enabled file exporter")
+		} else {
+			fa.fileExporter.UpdateOptions(opt)
+		}
+	} else {
+		if fa.fileExporter != nil {
+			klog.InfoS("Disabling file exporter")
+			fa.fileExporter.Stop()
+			fa.fileExporter = nil
+			klog.InfoS("Disabled file exporter")
+		}
+	}
+	if opt.Config.FlowMetrics.Enable {
+		if fa.flowMetricsExporter == nil {
+			klog.InfoS("Enabling flow metrics")
+			var err error
+			fa.flowMetricsExporter, err = newFlowMetricsExporter(opt)
+			if err != nil {
+				klog.ErrorS(err, "Error when creating flow metrics export process")
+				return
+			}
+			fa.flowMetricsExporter.Start()
+			klog.InfoS("Enabled flow metrics")
+		} else {
+			fa.flowMetricsExporter.UpdateOptions(opt)
+		}
+	} else {
+		if fa.flowMetricsExporter != nil {
+			klog.InfoS("Disabling flow metrics")
+			fa.flowMetricsExporter.Stop()
+			fa.flowMetricsExporter = nil
+			klog.InfoS("Disabled flow metrics")
+		}
+	}
 }
@@ -16,6 +16,8 @@ package flowaggregator
 
 import (
 	"bytes"
+	"crypto/x509"
+	"encoding/pem"
 	"os"
 	"path/filepath"
 	"sync"
@@ -23,22 +25,34 @@ import (
 	"time"
 
 	"github.com/fsnotify/fsnotify"
-	"github.com/golang/mock/gomock"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
+	"github.com/vmware/go-ipfix/pkg/collector"
 	ipfixentities "github.com/vmware/go-ipfix/pkg/entities"
 	ipfixentitiestesting "github.com/vmware/go-ipfix/pkg/entities/testing"
 	ipfixintermediate "github.com/vmware/go-ipfix/pkg/intermediate"
 	ipfixregistry "github.com/vmware/go-ipfix/pkg/registry"
+	"go.uber.org/mock/gomock"
 	"gopkg.in/yaml.v2"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/client-go/informers"
+	coreinformers "k8s.io/client-go/informers/core/v1"
 	"k8s.io/client-go/kubernetes"
 	"k8s.io/client-go/kubernetes/fake"
+	"k8s.io/client-go/tools/cache"
 
+	crdv1alpha2 "antrea.io/antrea/pkg/apis/crd/v1alpha2"
+	fakeversioned "antrea.io/antrea/pkg/client/clientset/versioned/fake"
+	crdinformers "antrea.io/antrea/pkg/client/informers/externalversions"
+	crdv1alpha2informers "antrea.io/antrea/pkg/client/informers/externalversions/crd/v1alpha2"
 	flowaggregatorconfig "antrea.io/antrea/pkg/config/flowaggregator"
 	"antrea.io/antrea/pkg/flowaggregator/exporter"
 	exportertesting "antrea.io/antrea/pkg/flowaggregator/exporter/testing"
+	"antrea.io/antrea/pkg/flowaggregator/flowfilter"
+	"antrea.io/antrea/pkg/flowaggregator/flowrecord"
 	"antrea.io/antrea/pkg/flowaggregator/options"
+	flowaggregatortesting "antrea.io/antrea/pkg/flowaggregator/testing"
 	"antrea.io/antrea/pkg/ipfix"
 	ipfixtesting "antrea.io/antrea/pkg/ipfix/testing"
 )
@@ -50,7 +64,17 @@ const (
 )
 
 func init() {
-	ipfixregistry.LoadRegistry()
+	ipfix.LoadRegistry()
+}
+
+func newTestInformers(t *testing.T) (coreinformers.PodInformer, crdv1alpha2informers.ExternalEntityInformer) {
+	informerFactory := informers.NewSharedInformerFactory(fake.NewSimpleClientset(), informerDefaultResync)
+	podInformer := informerFactory.Core().V1().Pods()
+	require.NoError(t, podInformer.Informer().AddIndexers(cache.Indexers{podInfoIndex: podInfoIndexFunc}))
+	crdInformerFactory := crdinformers.NewSharedInformerFactory(fakeversioned.NewSimpleClientset(), informerDefaultResync)
+	externalEntityInformer := crdInformerFactory.Crd().V1alpha2().ExternalEntities()
+	require.NoError(t, externalEntityInformer.Informer().AddIndexers(cache.Indexers{externalEntityIPIndex: externalEntityIPIndexFunc}))
+	return podInformer, externalEntityInformer
 }
 
 func TestFlowAggregator_sendFlowKeyRecord(t *testing.T) {
@@ -60,11 +84,9 @@ func TestFlowAggregator_sendFlowKeyRecord(t *testing.T) {
 	mockIPFIXExporter := exportertesting.NewMockInterface(ctrl)
 	mockClickHouseExporter := exportertesting.NewMockInterface(ctrl)
 	mockIPFIXRegistry := ipfixtesting.NewMockIPFIXRegistry(ctrl)
-	mockRecord := ipfixentitiestesting.NewMockRecord(ctrl)
 	mockAggregationProcess := ipfixtesting.NewMockIPFIXAggregationProcess(ctrl)
 
-	client := fake.NewSimpleClientset()
-	informerFactory := informers.NewSharedInformerFactory(client, informerDefaultResync)
+	podInformer, externalEntityInformer := newTestInformers(t)
 
 	newFlowAggregator := func(includePodLabels bool) *flowAggregator {
 		return &flowAggregator{
@@ -77,7 +99,8 @@ func TestFlowAggregator_sendFlowKeyRecord(t *testing.T) {
 			registry:                    mockIPFIXRegistry,
 			flowAggregatorAddress:       "",
 			includePodLabels:            includePodLabels,
-			podInformer:                 informerFactory.Core().V1().Pods(),
+			podInformer:                 podInformer,
+			externalEntityInformer:      externalEntityInformer,
 		}
 	}
 
@@ -99,63 +122,65 @@ func TestFlowAggregator_sendFlowKeyRecord(t *testing.T) {
 		DestinationPort:    5678,
 	}
 
-	readyRecord := &ipfixintermediate.AggregationFlowRecord{
-		Record:      mockRecord,
-		ReadyToSend: true,
+	// The record has no Pod name, and none of the IPs is known to the
+	// Flow Aggregator.
+	expectedFlowRecord := &flowrecord.FlowRecord{
+		SourceEndpointKind:      flowrecord.EndpointKindExternal,
+		DestinationEndpointKind: flowrecord.EndpointKindExternal,
 	}
 
 	testcases := []struct {
 		name             string
 		isIPv6           bool
 		flowKey          ipfixintermediate.FlowKey
-		flowRecord       *ipfixintermediate.AggregationFlowRecord
 		includePodLabels bool
 	}{
 		{
 			"IPv4_ready_to_send_with_pod_labels",
 			false,
 			ipv4Key,
-			readyRecord,
 			true,
 		},
 		{
 			"IPv6_ready_to_send_with_pod_labels",
 			true,
 			ipv6Key,
-			readyRecord,
 			true,
 		},
 		{
 			"IPv4_ready_to_send_without_pod_labels",
 			false,
 			ipv4Key,
-			readyRecord,
 			false,
 		},
 		{
 			"IPv6_ready_to_send_without_pod_labels",
 			true,
 			ipv6Key,
-			readyRecord,
 			false,
 		},
 	}
 
 	for _, tc := range testcases {
 		fa := newFlowAggregator(tc.includePodLabels)
+		mockRecord := ipfixentitiestesting.NewMockRecord(ctrl)
+		flowRecord := &ipfixintermediate.AggregationFlowRecord{
+			Record:      mockRecord,
+			ReadyToSend: true,
+		}
 		for _, exporter := range mockExporters {
-			exporter.EXPECT().AddRecord(mockRecord, tc.isIPv6)
+			exporter.EXPECT().AddRecord(mockRecord, expectedFlowRecord, tc.isIPv6)
 		}
 		mockAggregationProcess.EXPECT().ResetStatAndThroughputElementsInRecord(mockRecord).Return(nil)
-		mockAggregationProcess.EXPECT().AreCorrelatedFieldsFilled(*tc.flowRecord).Return(false)
+		mockAggregationProcess.EXPECT().AreCorrelatedFieldsFilled(*flowRecord).Return(false)
 		emptyStr := make([]byte, 0)
 		sourcePodNameElem, _ := ipfixentities.DecodeAndCreateInfoElementWithValue(ipfixentities.NewInfoElement("sourcePodName", 0, 0, ipfixregistry.AntreaEnterpriseID, 0), emptyStr)
 		mockRecord.EXPECT().GetInfoElementWithValue("sourcePodName").Return(sourcePodNameElem, 0, false)
 		destPodNameElem, _ := ipfixentities.DecodeAndCreateInfoElementWithValue(ipfixentities.NewInfoElement("destinationPodName", 0, 0, ipfixregistry.AntreaEnterpriseID, 0), emptyStr)
 		mockRecord.EXPECT().GetInfoElementWithValue("destinationPodName").Return(destPodNameElem, 0, false)
-		mockAggregationProcess.EXPECT().SetCorrelatedFieldsFilled(tc.flowRecord)
+		mockAggregationProcess.EXPECT().SetCorrelatedFieldsFilled(flowRecord)
 		if tc.includePodLabels {
-			mockAggregationProcess.EXPECT().AreExternalFieldsFilled(*tc.flowRecord).Return(false)
+			mockAggregationProcess.EXPECT().AreExternalFieldsFilled(*flowRecord).Return(false)
 			sourcePodLabelsElement := ipfixentities.NewInfoElement("sourcePodLabels", 0, 0, ipfixregistry.AntreaEnterpriseID, 0)
 			mockIPFIXRegistry.EXPECT().GetInfoElement("sourcePodLabels", ipfixregistry.AntreaEnterpriseID).Return(sourcePodLabelsElement, nil)
 			sourcePodLabelsIE, _ := ipfixentities.DecodeAndCreateInfoElementWithValue(sourcePodLabelsElement, bytes.NewBufferString("").Bytes())
@@ -164,12 +189,434 @@ func TestFlowAggregator_sendFlowKeyRecord(t *testing.T) {
 			mockIPFIXRegistry.EXPECT().GetInfoElement("destinationPodLabels", ipfixregistry.AntreaEnterpriseID).Return(ipfixentities.NewInfoElement("destinationPodLabels", 0, 0, ipfixregistry.AntreaEnterpriseID, 0), nil)
 			destinationPodLabelsIE, _ := ipfixentities.DecodeAndCreateInfoElementWithValue(destinationPodLabelsElement, bytes.NewBufferString("").Bytes())
 			mockRecord.EXPECT().AddInfoElement(destinationPodLabelsIE).Return(nil)
-			mockAggregationProcess.EXPECT().SetExternalFieldsFilled(tc.flowRecord)
+			mockAggregationProcess.EXPECT().SetExternalFieldsFilled(flowRecord)
+		}
+		// The other information elements are only read to build the flow
+		// record passed to the exporters.
+		mockRecord.EXPECT().GetInfoElementWithValue(gomock.Any()).Return(nil, 0, false).AnyTimes()
+		mockAggregationProcess.EXPECT().IsAggregatedRecordIPv4(*flowRecord).Return(!tc.isIPv6)
+
+		err := fa.sendFlowKeyRecord(tc.flowKey, flowRecord)
+		assert.NoError(t, err, "Error in sending flow key record: %v, key: %v, record: %v", err, tc.flowKey, flowRecord)
+	}
+}
+
+func TestFlowAggregator_sendFlowKeyRecordWithFilters(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockIPFIXExporter := exportertesting.NewMockInterface(ctrl)
+	mockClickHouseExporter := exportertesting.NewMockInterface(ctrl)
+	mockS3Exporter := exportertesting.NewMockInterface(ctrl)
+	mockAggregationProcess := ipfixtesting.NewMockIPFIXAggregationProcess(ctrl)
+	mockRecord := ipfixentitiestesting.NewMockRecord(ctrl)
+	// The expectations of the mock record can only be met once, which
+	// guarantees that the flow record is only built once for all filters.
+	flowaggregatortesting.PrepareMockIpfixRecord(mockRecord, true)
+
+	newFilter := func(rules ...flowaggregatorconfig.FlowFilterRule) *flowfilter.Filter {
+		filter, err := flowfilter.New(rules)
+		require.NoError(t, err)
+		return filter
+	}
+	podInformer, externalEntityInformer := newTestInformers(t)
+	fa := &flowAggregator{
+		aggregationProcess:     mockAggregationProcess,
+		podInformer:            podInformer,
+		externalEntityInformer: externalEntityInformer,
+		ipfixExporter:          mockIPFIXExporter,
+		clickHouseExporter:     mockClickHouseExporter,
+		s3Exporter:             mockS3Exporter,
+		// The test flow record is a TCP flow.
+		ipfixFilter: newFilter(flowaggregatorconfig.FlowFilterRule{
+			Action:    flowfilter.ActionExclude,
+			Protocols: []string{"TCP"},
+		}),
+		clickHouseFilter: newFilter(flowaggregatorconfig.FlowFilterRule{
+			Action:    flowfilter.ActionExclude,
+			Protocols: []string{"UDP"},
+		}),
+	}
+	flowKey := ipfixintermediate.FlowKey{
+		SourceAddress:      "10.10.0.79",
+		DestinationAddress: "10.10.0.80",
+		Protocol:           6,
+		SourcePort:         44752,
+		DestinationPort:    5201,
+	}
+	record := &ipfixintermediate.AggregationFlowRecord{
+		Record:      mockRecord,
+		ReadyToSend: true,
+	}
+
+	mockAggregationProcess.EXPECT().IsAggregatedRecordIPv4(*record).Return(true)
+	mockAggregationProcess.EXPECT().AreCorrelatedFieldsFilled(*record).Return(true)
+	mockClickHouseExporter.EXPECT().AddRecord(mockRecord, gomock.Any(), false)
+	mockS3Exporter.EXPECT().AddRecord(mockRecord, gomock.Any(), false)
+	mockAggregationProcess.EXPECT().ResetStatAndThroughputElementsInRecord(mockRecord).Return(nil)
+	require.NoError(t, fa.sendFlowKeyRecord(flowKey, record))
+}
+
+func TestFlowAggregator_drainFlowRecords(t *testing.T) {
+	drainGracePeriodSaved := drainGracePeriod
+	drainGracePeriod = 500 * time.Millisecond
+	defer func() {
+		drainGracePeriod = drainGracePeriodSaved
+	}()
+	flowKey := ipfixintermediate.FlowKey{
+		SourceAddress:      "10.10.0.79",
+		DestinationAddress: "10.10.0.80",
+		Protocol:           6,
+		SourcePort:         44752,
+		DestinationPort:    5201,
+	}
+
+	for _, tc := range []struct {
+		name string
+		// correlated indicates whether the record from the other Node is
+		// received during the grace period.
+		correlated bool
+	}{
+		{
+			name:       "record correlated during the grace period",
+			correlated: true,
+		},
+		{
+			name:       "uncorrelated record exported as it is",
+			correlated: false,
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			mockIPFIXExporter := exportertesting.NewMockInterface(ctrl)
+			mockCollectingProcess := ipfixtesting.NewMockIPFIXCollectingProcess(ctrl)
+			mockAggregationProcess := ipfixtesting.NewMockIPFIXAggregationProcess(ctrl)
+			readyMockRecord := ipfixentitiestesting.NewMockRecord(ctrl)
+			readyMockRecord.EXPECT().GetInfoElementWithValue(gomock.Any()).Return(nil, 0, false).AnyTimes()
+			notReadyMockRecord := ipfixentitiestesting.NewMockRecord(ctrl)
+			notReadyMockRecord.EXPECT().GetInfoElementWithValue(gomock.Any()).Return(nil, 0, false).AnyTimes()
+			podInformer, externalEntityInformer := newTestInformers(t)
+			fa := &flowAggregator{
+				collectingProcess:      mockCollectingProcess,
+				aggregationProcess:     mockAggregationProcess,
+				podInformer:            podInformer,
+				externalEntityInformer: externalEntityInformer,
+				ipfixExporter:          mockIPFIXExporter,
+			}
+			readyRecord := &ipfixintermediate.AggregationFlowRecord{
+				Record:      readyMockRecord,
+				ReadyToSend: true,
+			}
+			// This record is waiting for the record from the other Node.
+			notReadyRecord := &ipfixintermediate.AggregationFlowRecord{
+				Record:      notReadyMockRecord,
+				ReadyToSend: false,
+			}
+
+			// The collecting process is stopped before the records are drained.
+			stopCall := mockCollectingProcess.EXPECT().Stop()
+			numCalls := 0
+			mockAggregationProcess.EXPECT().ForAllRecordsDo(gomock.Any()).DoAndReturn(func(callback ipfixintermediate.FlowKeyRecordMapCallBack) error {
+				numCalls++
+				if tc.correlated && numCalls == 2 {
+					notReadyRecord.ReadyToSend = true
+				}
+				if err := callback(flowKey, notReadyRecord); err != nil {
+					return err
+				}
+				return callback(flowKey, readyRecord)
+			}).After(stopCall).MinTimes(2)
+			mockAggregationProcess.EXPECT().GetNumFlows().Return(int64(2))
+			mockAggregationProcess.EXPECT().IsAggregatedRecordIPv4(gomock.Any()).Return(true).Times(2)
+			mockAggregationProcess.EXPECT().AreCorrelatedFieldsFilled(gomock.Any()).DoAndReturn(func(record ipfixintermediate.AggregationFlowRecord) bool {
+				return record.ReadyToSend
+			}).Times(2)
+			if !tc.correlated {
+				mockAggregationProcess.EXPECT().SetCorrelatedFieldsFilled(notReadyRecord)
+			}
+			mockIPFIXExporter.EXPECT().AddRecord(readyMockRecord, gomock.Any(), false)
+			mockIPFIXExporter.EXPECT().AddRecord(notReadyMockRecord, gomock.Any(), false)
+			mockAggregationProcess.EXPECT().ResetStatAndThroughputElementsInRecord(readyMockRecord).Return(nil)
+			mockAggregationProcess.EXPECT().ResetStatAndThroughputElementsInRecord(notReadyMockRecord).Return(nil)
+
+			start := time.Now()
+			fa.drainFlowRecords()
+			if tc.correlated {
+				assert.Less(t, time.Since(start), drainGracePeriod)
+			} else {
+				assert.GreaterOrEqual(t, time.Since(start), drainGracePeriod)
+			}
+		})
+	}
+}
+
+func TestFlowAggregator_reloadServerCertificate(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	mockCollectingProcess := ipfixtesting.NewMockIPFIXCollectingProcess(ctrl)
+	mockNewCollectingProcess := ipfixtesting.NewMockIPFIXCollectingProcess(ctrl)
+
+	var newCPInput collector.CollectorInput
+	newIPFIXCollectingProcessSaved := newIPFIXCollectingProcess
+	newIPFIXCollectingProcess = func(input collector.CollectorInput) (ipfix.IPFIXCollectingProcess, error) {
+		newCPInput = input
+		return mockNewCollectingProcess, nil
+	}
+	defer func() {
+		newIPFIXCollectingProcess = newIPFIXCollectingProcessSaved
+	}()
+
+	caCert, caKey, caPEM, err := generateCACertKey()
+	require.NoError(t, err)
+	fa := &flowAggregator{
+		k8sClient:             fake.NewSimpleClientset(),
+		flowAggregatorAddress: "192.168.1.10",
+		collectingProcess:     mockCollectingProcess,
+		collectorInput: collector.CollectorInput{
+			Address:     collectorAddress,
+			Protocol:    tcpTransport,
+			IsEncrypted: true,
+			CACert:      caPEM,
+		},
+		aggregationMsgCh: make(chan *ipfixentities.Message),
+	}
+	fa.initCASecretInformer()
+	caSecret := &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Name: CASecretName, Namespace: CASecretNamespace},
+		Data: map[string][]byte{
+			corev1.TLSCertKey:       caPEM,
+			corev1.TLSPrivateKeyKey: encodeCAKey(caKey),
+		},
+	}
+	require.NoError(t, fa.caSecretInformer.GetIndexer().Add(caSecret))
+	stopCh := make(chan struct{})
+	defer close(stopCh)
+
+	// The collecting process is not restarted if the CA has not changed.
+	require.NoError(t, fa.reloadServerCertificate(stopCh))
+	assert.Equal(t, mockCollectingProcess, fa.collectingProcess)
+
+	_, newCAKey, newCAPEM, err := generateCACertKey()
+	require.NoError(t, err)
+	newCASecret := caSecret.DeepCopy()
+	newCASecret.Data = map[string][]byte{
+		corev1.TLSCertKey:       newCAPEM,
+		corev1.TLSPrivateKeyKey: encodeCAKey(newCAKey),
+	}
+	require.NoError(t, fa.caSecretInformer.GetIndexer().Update(newCASecret))
+	msgCh := make(chan *ipfixentities.Message)
+	started := make(chan struct{})
+	mockCollectingProcess.EXPECT().Stop()
+	mockCollectingProcess.EXPECT().GetNumRecordsReceived().Return(int64(10))
+	mockNewCollectingProcess.EXPECT().GetMsgChan().Return(msgCh)
+	mockNewCollectingProcess.EXPECT().Start().Do(func() {
+		close(started)
+	})
+	require.NoError(t, fa.reloadServerCertificate(stopCh))
+	<-started
+	assert.Equal(t, mockNewCollectingProcess, fa.collectingProcess)
+	assert.Equal(t, int64(10), fa.numRecordsReceived)
+	assert.Equal(t, newCAPEM, newCPInput.CACert)
+	assert.Equal(t, collectorAddress, newCPInput.Address)
+	assert.True(t, newCPInput.IsEncrypted)
+	// The new server certificate is signed by the new CA.
+	block, _ := pem.Decode(newCPInput.ServerCert)
+	require.NotNil(t, block)
+	serverCert, err := x509.ParseCertificate(block.Bytes)
+	require.NoError(t, err)
+	roots := x509.NewCertPool()
+	require.True(t, roots.AppendCertsFromPEM(newCAPEM))
+	_, err = serverCert.Verify(x509.VerifyOptions{Roots: roots, DNSName: "192.168.1.10"})
+	assert.NoError(t, err)
+	oldRoots := x509.NewCertPool()
+	oldRoots.AddCert(caCert)
+	_, err = serverCert.Verify(x509.VerifyOptions{Roots: oldRoots, DNSName: "192.168.1.10"})
+	assert.Error(t, err)
+
+	// The messages received by the new collecting process are forwarded to
+	// the aggregation process.
+	msg := ipfixentities.NewMessage(true)
+	msgCh <- msg
+	select {
+	case forwardedMsg := <-fa.aggregationMsgCh:
+		assert.Same(t, msg, forwardedMsg)
+	case <-time.After(5 * time.Second):
+		t.Fatalf("Message was not forwarded to the aggregation process")
+	}
+}
+
+func TestFlowAggregator_lookupPodByIP(t *testing.T) {
+	client := fake.NewSimpleClientset()
+	informerFactory := informers.NewSharedInformerFactory(client, informerDefaultResync)
+	podInformer := informerFactory.Core().V1().Pods()
+	require.NoError(t, podInformer.Informer().AddIndexers(cache.Indexers{podInfoIndex: podInfoIndexFunc}))
+	newPod := func(name, nodeName string, hostNetwork bool, ip string) *corev1.Pod {
+		return &corev1.Pod{
+			ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: name},
+			Spec:       corev1.PodSpec{NodeName: nodeName, HostNetwork: hostNetwork},
+			Status: corev1.PodStatus{
+				Phase:  corev1.PodRunning,
+				PodIPs: []corev1.PodIP{{IP: ip}},
+			},
+		}
+	}
+	pod := newPod("pod", "node-1", false, "10.10.0.10")
+	for _, p := range []*corev1.Pod{
+		pod,
+		newPod("host-pod-a", "node-1", true, "172.18.0.2"),
+		newPod("host-pod-b", "node-1", true, "172.18.0.2"),
+		newPod("host-pod-c", "node-2", true, "172.18.0.3"),
+	} {
+		require.NoError(t, podInformer.Informer().GetIndexer().Add(p))
+	}
+	fa := &flowAggregator{podInformer: podInformer}
+
+	for _, tc := range []struct {
+		name             string
+		ip               string
+		expectedPod      *corev1.Pod
+		expectedNodeName string
+	}{
+		{"Pod", "10.10.0.10", pod, "node-1"},
+		{"single hostNetwork Pod", "172.18.0.3", newPod("host-pod-c", "node-2", true, "172.18.0.3"), "node-2"},
+		// A flow from or to a Node IP cannot be attributed to one of the
+		// hostNetwork Pods running on the Node.
+		{"multiple hostNetwork Pods", "172.18.0.2", nil, "node-1"},
+		{"external IP", "192.168.1.1", nil, ""},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			pod, nodeName := fa.lookupPodByIP(tc.ip)
+			assert.Equal(t, tc.expectedPod, pod)
+			assert.Equal(t, tc.expectedNodeName, nodeName)
+		})
+	}
+}
+
+func TestFlowAggregator_getEndpointKind(t *testing.T) {
+	podInformer, externalEntityInformer := newTestInformers(t)
+	newPod := func(name, nodeName string, hostNetwork bool, ip string) *corev1.Pod {
+		return &corev1.Pod{
+			ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: name},
+			Spec:       corev1.PodSpec{NodeName: nodeName, HostNetwork: hostNetwork},
+			Status: corev1.PodStatus{
+				Phase:  corev1.PodRunning,
+				PodIPs: []corev1.PodIP{{IP: ip}},
+			},
+		}
+	}
+	for _, p := range []*corev1.Pod{
+		newPod("pod", "node-1", false, "10.10.0.10"),
+		newPod("host-pod-a", "node-1", true, "172.18.0.2"),
+		newPod("host-pod-b", "node-1", true, "172.18.0.2"),
+		newPod("host-pod-c", "node-2", true, "172.18.0.3"),
+	} {
+		require.NoError(t, podInformer.Informer().GetIndexer().Add(p))
+	}
+	ee := &crdv1alpha2.ExternalEntity{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "vm-1-eth0"},
+		Spec: crdv1alpha2.ExternalEntitySpec{
+			Endpoints:    []crdv1alpha2.Endpoint{{IP: "192.168.10.10"}},
+			ExternalNode: "vm-1",
+		},
+	}
+	require.NoError(t, externalEntityInformer.Informer().GetIndexer().Add(ee))
+	fa := &flowAggregator{podInformer: podInformer, externalEntityInformer: externalEntityInformer}
+
+	for _, tc := range []struct {
+		name         string
+		ip           string
+		podName      string
+		nodeName     string
+		expectedKind flowrecord.EndpointKind
+	}{
+		{"Pod", "10.10.0.10", "pod", "node-1", flowrecord.EndpointKindPod},
+		{"deleted Pod", "10.10.0.11", "deleted-pod", "node-1", flowrecord.EndpointKindPod},
+		{"single hostNetwork Pod", "172.18.0.3", "", "", flowrecord.EndpointKindNode},
+		{"multiple hostNetwork Pods", "172.18.0.2", "", "", flowrecord.EndpointKindNode},
+		{"gateway", "10.10.0.1", "", "node-1", flowrecord.EndpointKindNode},
+		{"ExternalEntity", "192.168.10.10", "", "vm-1", flowrecord.EndpointKindExternalEntity},
+		{"external IP", "192.168.1.1", "", "", flowrecord.EndpointKindExternal},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.Equal(t, tc.expectedKind, fa.getEndpointKind(tc.ip, tc.podName, tc.nodeName))
+			if tc.expectedKind == flowrecord.EndpointKindExternalEntity {
+				assert.Equal(t, "vm-1", fa.lookupExternalNodeByIP(tc.ip))
+			}
+		})
+	}
+}
+
+func TestFlowAggregator_lookupEgressForPod(t *testing.T) {
+	podInformer, _ := newTestInformers(t)
+	informerFactory := informers.NewSharedInformerFactory(fake.NewSimpleClientset(), informerDefaultResync)
+	namespaceInformer := informerFactory.Core().V1().Namespaces()
+	crdInformerFactory := crdinformers.NewSharedInformerFactory(fakeversioned.NewSimpleClientset(), informerDefaultResync)
+	egressInformer := crdInformerFactory.Crd().V1alpha2().Egresses()
+
+	for _, ns := range []*corev1.Namespace{
+		{ObjectMeta: metav1.ObjectMeta{Name: "ns-a", Labels: map[string]string{"env": "prod"}}},
+		{ObjectMeta: metav1.ObjectMeta{Name: "ns-b", Labels: map[string]string{"env": "dev"}}},
+	} {
+		require.NoError(t, namespaceInformer.Informer().GetIndexer().Add(ns))
+	}
+	for _, p := range []*corev1.Pod{
+		{ObjectMeta: metav1.ObjectMeta{Namespace: "ns-a", Name: "web", Labels: map[string]string{"app": "web"}}},
+		{ObjectMeta: metav1.ObjectMeta{Namespace: "ns-a", Name: "db", Labels: map[string]string{"app": "db"}}},
+		{ObjectMeta: metav1.ObjectMeta{Namespace: "ns-b", Name: "web", Labels: map[string]string{"app": "web"}}},
+		{ObjectMeta: metav1.ObjectMeta{Namespace: "ns-b", Name: "client", Labels: map[string]string{"app": "client"}}},
+		{ObjectMeta: metav1.ObjectMeta{Namespace: "ns-b", Name: "host", Labels: map[string]string{"app": "web"}}, Spec: corev1.PodSpec{HostNetwork: true}},
+	} {
+		require.NoError(t, podInformer.Informer().GetIndexer().Add(p))
+	}
+	now := time.Now()
+	newEgress := func(name string, creationTime time.Time, podSelector, namespaceSelector *metav1.LabelSelector, egressIP, egressNode string) *crdv1alpha2.Egress {
+		return &crdv1alpha2.Egress{
+			ObjectMeta: metav1.ObjectMeta{Name: name, CreationTimestamp: metav1.NewTime(creationTime)},
+			Spec: crdv1alpha2.EgressSpec{
+				AppliedTo: crdv1alpha2.AppliedTo{PodSelector: podSelector, NamespaceSelector: namespaceSelector},
+				EgressIP:  egressIP,
+			},
+			Status: crdv1alpha2.EgressStatus{EgressNode: egressNode},
 		}
-		mockAggregationProcess.EXPECT().IsAggregatedRecordIPv4(*tc.flowRecord).Return(!tc.isIPv6)
+	}
+	webSelector := &metav1.LabelSelector{MatchLabels: map[string]string{"app": "web"}}
+	prodSelector := &metav1.LabelSelector{MatchLabels: map[string]string{"env": "prod"}}
+	for _, egress := range []*crdv1alpha2.Egress{
+		newEgress("egress-web", now.Add(-time.Hour), webSelector, nil, "172.18.0.100", "node-1"),
+		// egress-web is older, so it is the one returned for the web Pods.
+		newEgress("egress-web-new", now, webSelector, nil, "172.18.0.101", "node-2"),
+		newEgress("egress-prod", now, nil, prodSelector, "172.18.0.102", "node-2"),
+		// The Egress IP has not been allocated yet.
+		newEgress("egress-client", now.Add(-time.Hour), &metav1.LabelSelector{MatchLabels: map[string]string{"app": "client"}}, nil, "", ""),
+	} {
+		require.NoError(t, egressInformer.Informer().GetIndexer().Add(egress))
+	}
+	fa := &flowAggregator{podInformer: podInformer, namespaceInformer: namespaceInformer, egressInformer: egressInformer}
 
-		err := fa.sendFlowKeyRecord(tc.flowKey, tc.flowRecord)
-		assert.NoError(t, err, "Error in sending flow key record: %v, key: %v, record: %v", err, tc.flowKey, tc.flowRecord)
+	for _, tc := range []struct {
+		name           string
+		namespace      string
+		podName        string
+		expectedEgress string
+	}{
+		{"Pod selected by several Egresses", "ns-b", "web", "egress-web"},
+		{"Pod selected by Namespace", "ns-a", "db", "egress-prod"},
+		{"Pod selected by Pod and Namespace", "ns-a", "web", "egress-web"},
+		{"Egress IP not allocated", "ns-b", "client", ""},
+		{"hostNetwork Pod", "ns-b", "host", ""},
+		{"deleted Pod", "ns-a", "deleted", ""},
+		{"no Pod", "", "", ""},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			egress := fa.lookupEgressForPod(tc.namespace, tc.podName)
+			if tc.expectedEgress == "" {
+				assert.Nil(t, egress)
+				return
+			}
+			require.NotNil(t, egress)
+			assert.Equal(t, tc.expectedEgress, egress.Name)
+		})
 	}
 }
 
@@ -252,14 +699,23 @@ func TestFlowAggregator_updateFlowAggregator(t *testing.T) {
 	mockIPFIXExporter := exportertesting.NewMockInterface(ctrl)
 	mockClickHouseExporter := exportertesting.NewMockInterface(ctrl)
 	mockS3Exporter := exportertesting.NewMockInterface(ctrl)
+	mockKafkaExporter := exportertesting.NewMockInterface(ctrl)
+	mockFileExporter := exportertesting.NewMockInterface(ctrl)
+	mockFlowMetricsExporter := exportertesting.NewMockInterface(ctrl)
 
 	newIPFIXExporterSaved := newIPFIXExporter
 	newClickHouseExporterSaved := newClickHouseExporter
 	newS3ExporterSaved := newS3Exporter
+	newKafkaExporterSaved := newKafkaExporter
+	newFileExporterSaved := newFileExporter
+	newFlowMetricsExporterSaved := newFlowMetricsExporter
 	defer func() {
 		newIPFIXExporter = newIPFIXExporterSaved
 		newClickHouseExporter = newClickHouseExporterSaved
 		newS3Exporter = newS3ExporterSaved
+		newKafkaExporter = newKafkaExporterSaved
+		newFileExporter = newFileExporterSaved
+		newFlowMetricsExporter = newFlowMetricsExporterSaved
 	}()
 	newIPFIXExporter = func(kubernetes.Interface, *options.Options, ipfix.IPFIXRegistry) exporter.Interface {
 		return mockIPFIXExporter
@@ -270,6 +726,15 @@ func TestFlowAggregator_updateFlowAggregator(t *testing.T) {
 	newS3Exporter = func(kubernetes.Interface, *options.Options) (exporter.Interface, error) {
 		return mockS3Exporter, nil
 	}
+	newKafkaExporter = func(kubernetes.Interface, *options.Options) (exporter.Interface, error) {
+		return mockKafkaExporter, nil
+	}
+	newFileExporter = func(kubernetes.Interface, *options.Options) (exporter.Interface, error) {
+		return mockFileExporter, nil
+	}
+	newFlowMetricsExporter = func(*options.Options) (exporter.Interface, error) {
+		return mockFlowMetricsExporter, nil
+	}
 
 	t.Run("updateIPFIX", func(t *testing.T) {
 		flowAggregator := &flowAggregator{
@@ -382,6 +847,146 @@ func TestFlowAggregator_updateFlowAggregator(t *testing.T) {
 		mockS3Exporter.EXPECT().UpdateOptions(opt)
 		flowAggregator.updateFlowAggregator(opt)
 	})
+	t.Run("enableKafka", func(t *testing.T) {
+		flowAggregator := &flowAggregator{}
+		opt := &options.Options{
+			Config: &flowaggregatorconfig.FlowAggregatorConfig{
+				Kafka: flowaggregatorconfig.KafkaConfig{
+					Enable:  true,
+					Brokers: []string{"kafka:9092"},
+					Topic:   "flows",
+				},
+			},
+		}
+		mockKafkaExporter.EXPECT().Start()
+		flowAggregator.updateFlowAggregator(opt)
+	})
+	t.Run("disableKafka", func(t *testing.T) {
+		flowAggregator := &flowAggregator{
+			kafkaExporter: mockKafkaExporter,
+		}
+		opt := &options.Options{
+			Config: &flowaggregatorconfig.FlowAggregatorConfig{
+				Kafka: flowaggregatorconfig.KafkaConfig{
+					Enable: false,
+				},
+			},
+		}
+		mockKafkaExporter.EXPECT().Stop()
+		flowAggregator.updateFlowAggregator(opt)
+	})
+	t.Run("updateKafka", func(t *testing.T) {
+		flowAggregator := &flowAggregator{
+			kafkaExporter: mockKafkaExporter,
+		}
+		opt := &options.Options{
+			Config: &flowaggregatorconfig.FlowAggregatorConfig{
+				Kafka: flowaggregatorconfig.KafkaConfig{
+					Enable:  true,
+					Brokers: []string{"kafka:9092"},
+					Topic:   "flows",
+				},
+			},
+		}
+		mockKafkaExporter.EXPECT().UpdateOptions(opt)
+		flowAggregator.updateFlowAggregator(opt)
+	})
+	t.Run("enableFileExporter", func(t *testing.T) {
+		flowAggregator := &flowAggregator{}
+		opt := &options.Options{
+			Config: &flowaggregatorconfig.FlowAggregatorConfig{
+				FileExporter: flowaggregatorconfig.FileExporterConfig{
+					Enable: true,
+				},
+			},
+		}
+		mockFileExporter.EXPECT().Start()
+		flowAggregator.updateFlowAggregator(opt)
+	})
+	t.Run("disableFileExporter", func(t *testing.T) {
+		flowAggregator := &flowAggregator{
+			fileExporter: mockFileExporter,
+		}
+		opt := &options.Options{
+			Config: &flowaggregatorconfig.FlowAggregatorConfig{
+				FileExporter: flowaggregatorconfig.FileExporterConfig{
+					Enable: false,
+				},
+			},
+		}
+		mockFileExporter.EXPECT().Stop()
+		flowAggregator.updateFlowAggregator(opt)
+	})
+	t.Run("updateFileExporter", func(t *testing.T) {
+		flowAggregator := &flowAggregator{
+			fileExporter: mockFileExporter,
+		}
+		opt := &options.Options{
+			Config: &flowaggregatorconfig.FlowAggregatorConfig{
+				FileExporter: flowaggregatorconfig.FileExporterConfig{
+					Enable: true,
+					Path:   "/tmp/flows",
+				},
+			},
+		}
+		mockFileExporter.EXPECT().UpdateOptions(opt)
+		flowAggregator.updateFlowAggregator(opt)
+	})
+	t.Run("enableFlowMetrics", func(t *testing.T) {
+		flowAggregator := &flowAggregator{}
+		opt := &options.Options{
+			Config: &flowaggregatorconfig.FlowAggregatorConfig{
+				FlowMetrics: flowaggregatorconfig.FlowMetricsConfig{
+					Enable: true,
+				},
+			},
+		}
+		mockFlowMetricsExporter.EXPECT().Start()
+		flowAggregator.updateFlowAggregator(opt)
+	})
+	t.Run("disableFlowMetrics", func(t *testing.T) {
+		flowAggregator := &flowAggregator{
+			flowMetricsExporter: mockFlowMetricsExporter,
+		}
+		opt := &options.Options{
+			Config: &flowaggregatorconfig.FlowAggregatorConfig{
+				FlowMetrics: flowaggregatorconfig.FlowMetricsConfig{
+					Enable: false,
+				},
+			},
+		}
+		mockFlowMetricsExporter.EXPECT().Stop()
+		flowAggregator.updateFlowAggregator(opt)
+	})
+	t.Run("updateFlowMetrics", func(t *testing.T) {
+		flowAggregator := &flowAggregator{
+			flowMetricsExporter: mockFlowMetricsExporter,
+		}
+		opt := &options.Options{
+			Config: &flowaggregatorconfig.FlowAggregatorConfig{
+				FlowMetrics: flowaggregatorconfig.FlowMetricsConfig{
+					Enable:     true,
+					Dimensions: []string{"flowType"},
+				},
+			},
+		}
+		mockFlowMetricsExporter.EXPECT().UpdateOptions(opt)
+		flowAggregator.updateFlowAggregator(opt)
+	})
+	t.Run("updateFilters", func(t *testing.T) {
+		filter, err := flowfilter.New([]flowaggregatorconfig.FlowFilterRule{{Action: flowfilter.ActionExclude}})
+		require.NoError(t, err)
+		flowAggregator := &flowAggregator{
+			ipfixFilter: filter,
+		}
+		opt := &options.Options{
+			Config:           &flowaggregatorconfig.FlowAggregatorConfig{},
+			ClickHouseFilter: filter,
+		}
+		flowAggregator.updateFlowAggregator(opt)
+		assert.Nil(t, flowAggregator.ipfixFilter)
+		assert.Equal(t, filter, flowAggregator.clickHouseFilter)
+	})
 }
 
 func TestFlowAggregator_Run(t *testing.T) {
@@ -442,6 +1047,9 @@ func TestFlowAggregator_Run(t *testing.T) {
 	mockCollectingProcess.EXPECT().Stop()
 	mockAggregationProcess.EXPECT().Start()
 	mockAggregationProcess.EXPECT().Stop()
+	// The flow records are drained when Run() is stopped.
+	mockAggregationProcess.EXPECT().GetNumFlows().Return(int64(0))
+	mockAggregationProcess.EXPECT().ForAllRecordsDo(gomock.Any()).Return(nil).Times(2)
 
 	// this is not really relevant; but in practice there will be one call
 	// to mockClickHouseExporter.UpdateOptions because of the hack used to
@@ -535,6 +1143,14 @@ func TestFlowAggregator_Run(t *testing.T) {
 // "first" and the updateCh is closed, we need to make sure that flowExportLoop
 // (which reads from updateCh) can handle correctly the channel closing.
 func TestFlowAggregator_closeUpdateChBeforeFlowExportLoopReturns(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	mockCollectingProcess := ipfixtesting.NewMockIPFIXCollectingProcess(ctrl)
+	mockCollectingProcess.EXPECT().Stop()
+	mockAggregationProcess := ipfixtesting.NewMockIPFIXAggregationProcess(ctrl)
+	mockAggregationProcess.EXPECT().GetNumFlows().Return(int64(0))
+	mockAggregationProcess.EXPECT().ForAllRecordsDo(gomock.Any()).Return(nil).Times(2)
+
 	wd, err := os.Getwd()
 	require.NoError(t, err)
 	// fsnotify does not seem to work when using the default tempdir on MacOS, which is why we
@@ -548,6 +1164,8 @@ func TestFlowAggregator_closeUpdateChBeforeFlowExportLoopReturns(t *testing.T) {
 	require.NoError(t, err)
 	defer configWatcher.Close()
 	flowAggregator := &flowAggregator{
+		collectingProcess:       mockCollectingProcess,
+		aggregationProcess:      mockAggregationProcess,
 		updateCh:                make(chan *options.Options),
 		configFile:              fileName,
 		configWatcher:           configWatcher,
@@ -0,0 +1,272 @@
+// Copyright 2022 Antrea Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package flowfilter
+
+import (
+	"encoding/binary"
+	"encoding/json"
+	"fmt"
+	"hash/fnv"
+	"math"
+	"strings"
+
+	ipfixregistry "github.com/vmware/go-ipfix/pkg/registry"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/klog/v2"
+
+	flowaggregatorconfig "antrea.io/antrea/pkg/config/flowaggregator"
+	"antrea.io/antrea/pkg/flowaggregator/flowrecord"
+)
+
+const (
+	ActionInclude = "Include"
+	ActionExclude = "Exclude"
+)
+
+var protocols = map[string]uint8{
+	"ICMP":   1,
+	"TCP":    6,
+	"UDP":    17,
+	"ICMPv6": 58,
+	"SCTP":   132,
+}
+
+var policyActions = map[string]uint8{
+	"Allow":  ipfixregistry.NetworkPolicyRuleActionAllow,
+	"Drop":   ipfixregistry.NetworkPolicyRuleActionDrop,
+	"Reject": ipfixregistry.NetworkPolicyRuleActionReject,
+}
+
+var flowTypes = map[string]uint8{
+	"IntraNode":    ipfixregistry.FlowTypeIntraNode,
+	"InterNode":    ipfixregistry.FlowTypeInterNode,
+	"ToExternal":   ipfixregistry.FlowTypeToExternal,
+	"FromExternal": ipfixregistry.FlowTypeFromExternal,
+}
+
+// Filter selects the flow records sent to an exporter, according to a list of
+// rules. A nil Filter selects all flow records.
+type Filter struct {
+	rules []rule
+}
+
+type rule struct {
+	exclude bool
+	// sampleThreshold is compared to the hash of the flow 5-tuple to sample
+	// flows. All flows are selected if it is math.MaxUint64.
+	sampleThreshold uint64
+	namespaces      map[string]bool
+	podSelector     labels.Selector
+	services        map[string]bool
+	ports           map[uint16]bool
+	protocols       map[uint8]bool
+	policyActions   map[uint8]bool
+	flowTypes       map[uint8]bool
+}
+
+// New creates a Filter from rules. It returns nil if there is no rule, i.e. if
+// all flow records are selected.
+func New(rules []flowaggregatorconfig.FlowFilterRule) (*Filter, error) {
+	if len(rules) == 0 {
+		return nil, nil
+	}
+	f := &Filter{rules: make([]rule, 0, len(rules))}
+	for i := range rules {
+		r, err := newRule(&rules[i])
+		if err != nil {
+			return nil, fmt.Errorf("invalid filter rule %d: %v", i, err)
+		}
+		f.rules = append(f.rules, r)
+	}
+	return f, nil
+}
+
+// UsesPodLabels returns whether the rules of the filter match Pod labels, which
+// requires Pod labels to be included in flow records.
+func UsesPodLabels(rules []flowaggregatorconfig.FlowFilterRule) bool {
+	for i := range rules {
+		if rules[i].PodSelector != "" {
+			return true
+		}
+	}
+	return false
+}
+
+func newRule(config *flowaggregatorconfig.FlowFilterRule) (rule, error) {
+	r := rule{sampleThreshold: math.MaxUint64}
+	switch config.Action {
+	case "", ActionInclude:
+	case ActionExclude:
+		r.exclude = true
+	default:
+		return r, fmt.Errorf("action %s is not supported", config.Action)
+	}
+	if config.SampleRatio < 0 || config.SampleRatio > 1 {
+		return r, fmt.Errorf("sampleRatio %v must be between 0 and 1", config.SampleRatio)
+	}
+	if config.SampleRatio != 0 {
+		if r.exclude {
+			return r, fmt.Errorf("sampleRatio is only supported with the %s action", ActionInclude)
+		}
+		if config.SampleRatio < 1 {
+			r.sampleThreshold = uint64(config.SampleRatio * math.MaxUint64)
+		}
+	}
+	if len(config.Namespaces) > 0 {
+		r.namespaces = make(map[string]bool, len(config.Namespaces))
+		for _, namespace := range config.Namespaces {
+			r.namespaces[namespace] = true
+		}
+	}
+	if config.PodSelector != "" {
+		selector, err := labels.Parse(config.PodSelector)
+		if err != nil {
+			return r, fmt.Errorf("invalid podSelector: %v", err)
+		}
+		r.podSelector = selector
+	}
+	if len(config.Services) > 0 {
+		r.services = make(map[string]bool, len(config.Services))
+		for _, service := range config.Services {
+			if !strings.Contains(service, "/") {
+				return r, fmt.Errorf("service %s must be in the format <namespace>/<name>[:<port name>]", service)
+			}
+			r.services[service] = true
+		}
+	}
+	if len(config.Ports) > 0 {
+		r.ports = make(map[uint16]bool, len(config.Ports))
+		for _, port := range config.Ports {
+			if port <= 0 || port > math.MaxUint16 {
+				return r, fmt.Errorf("port %d is invalid", port)
+			}
+			r.ports[uint16(port)] = true
+		}
+	}
+	var err error
+	if r.protocols, err = parseEnum(config.Protocols, protocols, "protocol"); err != nil {
+		return r, err
+	}
+	if r.policyActions, err = parseEnum(config.PolicyActions, policyActions, "policy action"); err != nil {
+		return r, err
+	}
+	if r.flowTypes, err = parseEnum(config.FlowTypes, flowTypes, "flow type"); err != nil {
+		return r, err
+	}
+	return r, nil
+}
+
+func parseEnum(names []string, values map[string]uint8, kind string) (map[uint8]bool, error) {
+	if len(names) == 0 {
+		return nil, nil
+	}
+	m := make(map[uint8]bool, len(names))
+	for _, name := range names {
+		value, ok := values[name]
+		if !ok {
+			return nil, fmt.Errorf("%s %s is not supported", kind, name)
+		}
+		m[value] = true
+	}
+	return m, nil
+}
+
+// Match returns whether a flow record is selected by the filter.
+func (f *Filter) Match(record *flowrecord.FlowRecord) bool {
+	if f == nil {
+		return true
+	}
+	for i := range f.rules {
+		r := &f.rules[i]
+		if !r.match(record) {
+			continue
+		}
+		if r.exclude {
+			return false
+		}
+		return r.sampleThreshold == math.MaxUint64 || flowHash(record) < r.sampleThreshold
+	}
+	return true
+}
+
+func (r *rule) match(record *flowrecord.FlowRecord) bool {
+	if r.namespaces != nil && !r.namespaces[record.SourcePodNamespace] && !r.namespaces[record.DestinationPodNamespace] {
+		return false
+	}
+	if r.services != nil && !r.matchService(record.DestinationServicePortName) {
+		return false
+	}
+	if r.ports != nil && !r.ports[record.DestinationTransportPort] && !r.ports[record.DestinationServicePort] {
+		return false
+	}
+	if r.protocols != nil && !r.protocols[record.ProtocolIdentifier] {
+		return false
+	}
+	if r.policyActions != nil && !r.policyActions[record.IngressNetworkPolicyRuleAction] && !r.policyActions[record.EgressNetworkPolicyRuleAction] {
+		return false
+	}
+	if r.flowTypes != nil && !r.flowTypes[record.FlowType] {
+		return false
+	}
+	// Label matching is the most expensive check, so it is done last.
+	if r.podSelector != nil && !matchPodLabels(r.podSelector, record.SourcePodLabels) && !matchPodLabels(r.podSelector, record.DestinationPodLabels) {
+		return false
+	}
+	return true
+}
+
+// matchService matches the destination Service port name of a flow record, in
+// the format "<namespace>/<name>:<port name>".
+func (r *rule) matchService(servicePortName string) bool {
+	if servicePortName == "" {
+		return false
+	}
+	if r.services[servicePortName] {
+		return true
+	}
+	if i := strings.LastIndex(servicePortName, ":"); i >= 0 {
+		return r.services[servicePortName[:i]]
+	}
+	return false
+}
+
+// matchPodLabels matches the labels of a Pod, in JSON format as they appear in
+// flow records. Flow records with no Pod (e.g. to external destinations) never
+// match.
+func matchPodLabels(selector labels.Selector, podLabels string) bool {
+	if podLabels == "" {
+		return false
+	}
+	var m map[string]string
+	if err := json.Unmarshal([]byte(podLabels), &m); err != nil {
+		klog.V(4).InfoS("Invalid Pod labels in flow record", "labels", podLabels, "err", err)
+		return false
+	}
+	return selector.Matches(labels.Set(m))
+}
+
+// flowHash returns the FNV-1a hash of the 5-tuple of a flow record, which is
+// used to sample all the records of a flow consistently.
+func flowHash(record *flowrecord.FlowRecord) uint64 {
+	h := fnv.New64a()
+	h.Write([]byte(record.SourceIP))
+	h.Write([]byte(record.DestinationIP))
+	var b [5]byte
+	binary.BigEndian.PutUint16(b[0:2], record.SourceTransportPort)
+	binary.BigEndian.PutUint16(b[2:4], record.DestinationTransportPort)
+	b[4] = record.ProtocolIdentifier
+	h.Write(b[:])
+	return h.Sum64()
+}
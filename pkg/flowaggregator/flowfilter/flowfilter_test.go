@@ -0,0 +1,182 @@
+// Copyright 2022 Antrea Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package flowfilter
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	ipfixregistry "github.com/vmware/go-ipfix/pkg/registry"
+
+	flowaggregatorconfig "antrea.io/antrea/pkg/config/flowaggregator"
+	"antrea.io/antrea/pkg/flowaggregator/flowrecord"
+)
+
+func newTestFlowRecord() *flowrecord.FlowRecord {
+	return &flowrecord.FlowRecord{
+		SourceIP:                       "10.10.0.79",
+		DestinationIP:                  "10.10.0.80",
+		SourceTransportPort:            44752,
+		DestinationTransportPort:       8080,
+		ProtocolIdentifier:             6,
+		SourcePodName:                  "client",
+		SourcePodNamespace:             "ns-a",
+		DestinationPodName:             "server",
+		DestinationPodNamespace:        "ns-b",
+		DestinationClusterIP:           "10.96.0.10",
+		DestinationServicePort:         80,
+		DestinationServicePortName:     "ns-b/web:http",
+		IngressNetworkPolicyRuleAction: ipfixregistry.NetworkPolicyRuleActionAllow,
+		FlowType:                       ipfixregistry.FlowTypeInterNode,
+		SourcePodLabels:                `{"app":"client"}`,
+		DestinationPodLabels:           `{"app":"web","tier":"frontend"}`,
+	}
+}
+
+func TestNew(t *testing.T) {
+	f, err := New(nil)
+	require.NoError(t, err)
+	assert.Nil(t, f)
+	assert.True(t, f.Match(newTestFlowRecord()))
+
+	for _, tc := range []struct {
+		rule        flowaggregatorconfig.FlowFilterRule
+		expectedErr string
+	}{
+		{flowaggregatorconfig.FlowFilterRule{Action: "Drop"}, "action Drop is not supported"},
+		{flowaggregatorconfig.FlowFilterRule{SampleRatio: 1.5}, "sampleRatio 1.5 must be between 0 and 1"},
+		{flowaggregatorconfig.FlowFilterRule{Action: ActionExclude, SampleRatio: 0.5}, "sampleRatio is only supported with the Include action"},
+		{flowaggregatorconfig.FlowFilterRule{PodSelector: "app in (a"}, "invalid podSelector"},
+		{flowaggregatorconfig.FlowFilterRule{Services: []string{"web"}}, "service web must be in the format"},
+		{flowaggregatorconfig.FlowFilterRule{Ports: []int32{70000}}, "port 70000 is invalid"},
+		{flowaggregatorconfig.FlowFilterRule{Protocols: []string{"tcp"}}, "protocol tcp is not supported"},
+		{flowaggregatorconfig.FlowFilterRule{PolicyActions: []string{"Pass"}}, "policy action Pass is not supported"},
+		{flowaggregatorconfig.FlowFilterRule{FlowTypes: []string{"External"}}, "flow type External is not supported"},
+	} {
+		_, err := New([]flowaggregatorconfig.FlowFilterRule{{}, tc.rule})
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "invalid filter rule 1: "+tc.expectedErr)
+	}
+}
+
+func TestMatch(t *testing.T) {
+	for _, tc := range []struct {
+		name    string
+		rule    flowaggregatorconfig.FlowFilterRule
+		matches bool
+	}{
+		{"all", flowaggregatorconfig.FlowFilterRule{}, true},
+		{"source Namespace", flowaggregatorconfig.FlowFilterRule{Namespaces: []string{"ns-a"}}, true},
+		{"destination Namespace", flowaggregatorconfig.FlowFilterRule{Namespaces: []string{"ns-c", "ns-b"}}, true},
+		{"other Namespace", flowaggregatorconfig.FlowFilterRule{Namespaces: []string{"ns-c"}}, false},
+		{"destination Pod labels", flowaggregatorconfig.FlowFilterRule{PodSelector: "app=web,tier in (frontend,backend)"}, true},
+		{"source Pod labels", flowaggregatorconfig.FlowFilterRule{PodSelector: "app=client"}, true},
+		{"other Pod labels", flowaggregatorconfig.FlowFilterRule{PodSelector: "app=db"}, false},
+		{"Service", flowaggregatorconfig.FlowFilterRule{Services: []string{"ns-b/web"}}, true},
+		{"Service port", flowaggregatorconfig.FlowFilterRule{Services: []string{"ns-b/web:http"}}, true},
+		{"other Service port", flowaggregatorconfig.FlowFilterRule{Services: []string{"ns-b/web:https"}}, false},
+		{"other Service", flowaggregatorconfig.FlowFilterRule{Services: []string{"ns-a/web"}}, false},
+		{"destination port", flowaggregatorconfig.FlowFilterRule{Ports: []int32{8080}}, true},
+		{"Service port number", flowaggregatorconfig.FlowFilterRule{Ports: []int32{80}}, true},
+		{"other port", flowaggregatorconfig.FlowFilterRule{Ports: []int32{44752}}, false},
+		{"protocol", flowaggregatorconfig.FlowFilterRule{Protocols: []string{"UDP", "TCP"}}, true},
+		{"other protocol", flowaggregatorconfig.FlowFilterRule{Protocols: []string{"UDP"}}, false},
+		{"policy action", flowaggregatorconfig.FlowFilterRule{PolicyActions: []string{"Allow"}}, true},
+		{"other policy action", flowaggregatorconfig.FlowFilterRule{PolicyActions: []string{"Drop", "Reject"}}, false},
+		{"flow type", flowaggregatorconfig.FlowFilterRule{FlowTypes: []string{"InterNode"}}, true},
+		{"other flow type", flowaggregatorconfig.FlowFilterRule{FlowTypes: []string{"IntraNode"}}, false},
+		{"all fields", flowaggregatorconfig.FlowFilterRule{
+			Namespaces:    []string{"ns-b"},
+			PodSelector:   "app=web",
+			Services:      []string{"ns-b/web"},
+			Ports:         []int32{80},
+			Protocols:     []string{"TCP"},
+			PolicyActions: []string{"Allow"},
+			FlowTypes:     []string{"InterNode"},
+		}, true},
+		{"one field not matching", flowaggregatorconfig.FlowFilterRule{
+			Namespaces: []string{"ns-b"},
+			Protocols:  []string{"UDP"},
+		}, false},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			tc.rule.Action = ActionExclude
+			f, err := New([]flowaggregatorconfig.FlowFilterRule{tc.rule})
+			require.NoError(t, err)
+			// Flow records matching the exclude rule are not selected,
+			// while flow records which do not match any rule are.
+			assert.Equal(t, !tc.matches, f.Match(newTestFlowRecord()))
+		})
+	}
+}
+
+func TestMatchNoPod(t *testing.T) {
+	f, err := New([]flowaggregatorconfig.FlowFilterRule{{Action: ActionExclude, PodSelector: "app!=web"}})
+	require.NoError(t, err)
+	record := newTestFlowRecord()
+	record.SourcePodLabels = ""
+	record.DestinationPodLabels = ""
+	// A selector never matches flow records without Pod labels, even if it
+	// would match empty labels.
+	assert.True(t, f.Match(record))
+}
+
+func TestMatchFirstRule(t *testing.T) {
+	f, err := New([]flowaggregatorconfig.FlowFilterRule{
+		{Action: ActionInclude, Namespaces: []string{"ns-a"}},
+		{Action: ActionExclude},
+	})
+	require.NoError(t, err)
+	record := newTestFlowRecord()
+	assert.True(t, f.Match(record))
+	record.SourcePodNamespace = "ns-c"
+	record.DestinationPodNamespace = "ns-c"
+	assert.False(t, f.Match(record))
+}
+
+func TestMatchSampling(t *testing.T) {
+	f, err := New([]flowaggregatorconfig.FlowFilterRule{
+		{Action: ActionInclude, SampleRatio: 0.2},
+	})
+	require.NoError(t, err)
+	const numFlows = 10000
+	selected := 0
+	for i := 0; i < numFlows; i++ {
+		record := newTestFlowRecord()
+		record.SourceIP = fmt.Sprintf("10.10.%d.%d", i/256, i%256)
+		record.SourceTransportPort = uint16(30000 + i%1000)
+		matched := f.Match(record)
+		// All the records of a flow are sampled consistently.
+		assert.Equal(t, matched, f.Match(record))
+		if matched {
+			selected++
+		}
+	}
+	assert.InDelta(t, 0.2*numFlows, selected, 0.03*numFlows)
+
+	f, err = New([]flowaggregatorconfig.FlowFilterRule{
+		{Action: ActionInclude, SampleRatio: 1},
+	})
+	require.NoError(t, err)
+	assert.True(t, f.Match(newTestFlowRecord()))
+}
+
+func TestUsesPodLabels(t *testing.T) {
+	assert.False(t, UsesPodLabels(nil))
+	assert.False(t, UsesPodLabels([]flowaggregatorconfig.FlowFilterRule{{Namespaces: []string{"ns-a"}}}))
+	assert.True(t, UsesPodLabels([]flowaggregatorconfig.FlowFilterRule{{Namespaces: []string{"ns-a"}}, {PodSelector: "app=web"}}))
+}
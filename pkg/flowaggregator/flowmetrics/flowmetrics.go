@@ -0,0 +1,328 @@
+// Copyright 2022 Antrea Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package flowmetrics
+
+import (
+	"fmt"
+	"sync"
+	"time"
+
+	ipfixregistry "github.com/vmware/go-ipfix/pkg/registry"
+	"k8s.io/component-base/metrics"
+	"k8s.io/klog/v2"
+
+	"antrea.io/antrea/pkg/flowaggregator/flowrecord"
+)
+
+const (
+	metricNamespaceAntrea         = "antrea"
+	metricSubsystemFlowAggregator = "flow_aggregator"
+	numDimensions                 = 6
+	overflowLabelValue            = "_other"
+)
+
+// dimension is a flow record field which can be used as a metric label.
+type dimension struct {
+	name  string
+	label string
+	value func(record *flowrecord.FlowRecord) string
+}
+
+var dimensions = [numDimensions]dimension{
+	{"sourcePodNamespace", "source_pod_namespace", func(record *flowrecord.FlowRecord) string {
+		return record.SourcePodNamespace
+	}},
+	{"destinationPodNamespace", "destination_pod_namespace", func(record *flowrecord.FlowRecord) string {
+		return record.DestinationPodNamespace
+	}},
+	{"destinationServicePortName", "destination_service_port_name", func(record *flowrecord.FlowRecord) string {
+		return record.DestinationServicePortName
+	}},
+	{"ingressNetworkPolicyRuleAction", "ingress_network_policy_rule_action", func(record *flowrecord.FlowRecord) string {
+		return policyRuleActionName(record.IngressNetworkPolicyRuleAction)
+	}},
+	{"egressNetworkPolicyRuleAction", "egress_network_policy_rule_action", func(record *flowrecord.FlowRecord) string {
+		return policyRuleActionName(record.EgressNetworkPolicyRuleAction)
+	}},
+	{"flowType", "flow_type", func(record *flowrecord.FlowRecord) string {
+		return flowTypeName(record.FlowType)
+	}},
+}
+
+func policyRuleActionName(action uint8) string {
+	switch action {
+	case ipfixregistry.NetworkPolicyRuleActionAllow:
+		return "Allow"
+	case ipfixregistry.NetworkPolicyRuleActionDrop:
+		return "Drop"
+	case ipfixregistry.NetworkPolicyRuleActionReject:
+		return "Reject"
+	}
+	return ""
+}
+
+func flowTypeName(flowType uint8) string {
+	switch flowType {
+	case ipfixregistry.FlowTypeIntraNode:
+		return "IntraNode"
+	case ipfixregistry.FlowTypeInterNode:
+		return "InterNode"
+	case ipfixregistry.FlowTypeToExternal:
+		return "ToExternal"
+	case ipfixregistry.FlowTypeFromExternal:
+		return "FromExternal"
+	}
+	return ""
+}
+
+func newDesc(name, help string) *metrics.Desc {
+	labels := make([]string, numDimensions)
+	for i := range dimensions {
+		labels[i] = dimensions[i].label
+	}
+	return metrics.NewDesc(metrics.BuildFQName(metricNamespaceAntrea, metricSubsystemFlowAggregator, name),
+		help, labels, nil, metrics.ALPHA, "")
+}
+
+var (
+	bytesDesc             = newDesc("flow_bytes_total", "Number of bytes sent from the source to the destination of flows.")
+	reverseBytesDesc      = newDesc("flow_reverse_bytes_total", "Number of bytes sent from the destination to the source of flows.")
+	packetsDesc           = newDesc("flow_packets_total", "Number of packets sent from the source to the destination of flows.")
+	reversePacketsDesc    = newDesc("flow_reverse_packets_total", "Number of packets sent from the destination to the source of flows.")
+	connectionsDesc       = newDesc("connections_total", "Number of connections.")
+	deniedConnectionsDesc = newDesc("denied_connections_total", "Number of connections denied by an Antrea-native policy rule.")
+)
+
+// labelValues are the values of the labels of a series. All the dimensions are
+// always used as labels, so that the metrics do not change when the selected
+// dimensions are updated, but the labels of the dimensions which are not selected
+// are empty, which Prometheus handles as missing labels.
+type labelValues [numDimensions]string
+
+type counters struct {
+	bytes             uint64
+	reverseBytes      uint64
+	packets           uint64
+	reversePackets    uint64
+	connections       uint64
+	deniedConnections uint64
+}
+
+type connectionKey struct {
+	sourceIP           string
+	destinationIP      string
+	sourcePort         uint16
+	destinationPort    uint16
+	protocol           uint8
+	flowStartTimestamp int64
+}
+
+// Collector maintains Prometheus counters computed from flow records, with one
+// series per combination of values of the selected dimensions. At most
+// maxSeries series are maintained, and flow records which would create more
+// series are accounted for in a single overflow series.
+type Collector struct {
+	metrics.BaseStableCollector
+
+	mutex     sync.Mutex
+	selected  [numDimensions]bool
+	maxSeries int
+	series    map[labelValues]*counters
+	overflow  *counters
+	// connections stores the last time a record was received for each active
+	// connection, so that each connection is counted once.
+	connections map[connectionKey]time.Time
+}
+
+// ValidateDimensions returns an error if one of the dimensions is not supported
+// or is duplicated.
+func ValidateDimensions(names []string) error {
+	_, err := parseDimensions(names)
+	return err
+}
+
+func parseDimensions(names []string) ([numDimensions]bool, error) {
+	var selected [numDimensions]bool
+	for _, name := range names {
+		found := false
+		for i := range dimensions {
+			if dimensions[i].name == name {
+				if selected[i] {
+					return selected, fmt.Errorf("dimension %s is duplicated", name)
+				}
+				selected[i] = true
+				found = true
+				break
+			}
+		}
+		if !found {
+			return selected, fmt.Errorf("dimension %s is not supported", name)
+		}
+	}
+	return selected, nil
+}
+
+func NewCollector(dimensionNames []string, maxSeries int) (*Collector, error) {
+	c := &Collector{}
+	if err := c.Configure(dimensionNames, maxSeries); err != nil {
+		return nil, err
+	}
+	return c, nil
+}
+
+// Configure sets the dimensions and the maximum number of series of the
+// collector. All the counters are reset if they change.
+func (c *Collector) Configure(dimensionNames []string, maxSeries int) error {
+	selected, err := parseDimensions(dimensionNames)
+	if err != nil {
+		return err
+	}
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	if selected == c.selected && maxSeries == c.maxSeries && c.series != nil {
+		return nil
+	}
+	c.selected = selected
+	c.maxSeries = maxSeries
+	c.reset()
+	return nil
+}
+
+// Reset resets all the counters.
+func (c *Collector) Reset() {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	c.reset()
+}
+
+func (c *Collector) reset() {
+	c.series = make(map[labelValues]*counters)
+	c.overflow = nil
+	c.connections = make(map[connectionKey]time.Time)
+}
+
+// AddRecord accounts for a flow record in the counters.
+func (c *Collector) AddRecord(record *flowrecord.FlowRecord, now time.Time) {
+	key := connectionKey{
+		sourceIP:           record.SourceIP,
+		destinationIP:      record.DestinationIP,
+		sourcePort:         record.SourceTransportPort,
+		destinationPort:    record.DestinationTransportPort,
+		protocol:           record.ProtocolIdentifier,
+		flowStartTimestamp: record.FlowStartSeconds.Unix(),
+	}
+
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	var values labelValues
+	for i := range dimensions {
+		if c.selected[i] {
+			values[i] = dimensions[i].value(record)
+		}
+	}
+	s := c.getCounters(values)
+	s.bytes += record.OctetDeltaCount
+	s.reverseBytes += record.ReverseOctetDeltaCount
+	s.packets += record.PacketDeltaCount
+	s.reversePackets += record.ReversePacketDeltaCount
+	if _, ok := c.connections[key]; !ok {
+		s.connections++
+		if isDenied(record.IngressNetworkPolicyRuleAction) || isDenied(record.EgressNetworkPolicyRuleAction) {
+			s.deniedConnections++
+		}
+	}
+	// Records are exported with the active timeout end reason until the
+	// connection ends, in which case it does not need to be tracked anymore.
+	if record.FlowEndReason == ipfixregistry.ActiveTimeoutReason {
+		c.connections[key] = now
+	} else {
+		delete(c.connections, key)
+	}
+}
+
+func isDenied(action uint8) bool {
+	return action == ipfixregistry.NetworkPolicyRuleActionDrop || action == ipfixregistry.NetworkPolicyRuleActionReject
+}
+
+func (c *Collector) getCounters(values labelValues) *counters {
+	if s, ok := c.series[values]; ok {
+		return s
+	}
+	if len(c.series) < c.maxSeries {
+		s := &counters{}
+		c.series[values] = s
+		return s
+	}
+	if c.overflow == nil {
+		klog.InfoS("Maximum number of flow metrics series reached, new series are accounted for in the overflow series", "maxSeries", c.maxSeries)
+		c.overflow = &counters{}
+	}
+	return c.overflow
+}
+
+// RemoveStaleConnections stops tracking the connections for which no record has
+// been received since staleTimeout, e.g. because the record reporting their end
+// was not received.
+func (c *Collector) RemoveStaleConnections(now time.Time, staleTimeout time.Duration) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	for key, lastSeen := range c.connections {
+		if now.Sub(lastSeen) > staleTimeout {
+			delete(c.connections, key)
+		}
+	}
+}
+
+func (c *Collector) DescribeWithStability(ch chan<- *metrics.Desc) {
+	ch <- bytesDesc
+	ch <- reverseBytesDesc
+	ch <- packetsDesc
+	ch <- reversePacketsDesc
+	ch <- connectionsDesc
+	ch <- deniedConnectionsDesc
+}
+
+func (c *Collector) CollectWithStability(ch chan<- metrics.Metric) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	for values, s := range c.series {
+		collectCounters(ch, s, values)
+	}
+	if c.overflow != nil {
+		var values labelValues
+		for i := range values {
+			if c.selected[i] {
+				values[i] = overflowLabelValue
+			}
+		}
+		collectCounters(ch, c.overflow, values)
+	}
+}
+
+func collectCounters(ch chan<- metrics.Metric, s *counters, values labelValues) {
+	for _, m := range []struct {
+		desc  *metrics.Desc
+		value uint64
+	}{
+		{bytesDesc, s.bytes},
+		{reverseBytesDesc, s.reverseBytes},
+		{packetsDesc, s.packets},
+		{reversePacketsDesc, s.reversePackets},
+		{connectionsDesc, s.connections},
+		{deniedConnectionsDesc, s.deniedConnections},
+	} {
+		ch <- metrics.NewLazyConstMetric(m.desc, metrics.CounterValue, float64(m.value), values[:]...)
+	}
+}
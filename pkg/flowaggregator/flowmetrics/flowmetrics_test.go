@@ -0,0 +1,158 @@
+// Copyright 2022 Antrea Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package flowmetrics
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	ipfixregistry "github.com/vmware/go-ipfix/pkg/registry"
+	"k8s.io/component-base/metrics/testutil"
+
+	"antrea.io/antrea/pkg/flowaggregator/flowrecord"
+)
+
+func newTestFlowRecord() *flowrecord.FlowRecord {
+	return &flowrecord.FlowRecord{
+		FlowStartSeconds:               time.Unix(1637706961, 0),
+		FlowEndReason:                  ipfixregistry.ActiveTimeoutReason,
+		SourceIP:                       "10.10.0.79",
+		DestinationIP:                  "10.10.0.80",
+		SourceTransportPort:            44752,
+		DestinationTransportPort:       8080,
+		ProtocolIdentifier:             6,
+		PacketDeltaCount:               10,
+		OctetDeltaCount:                1000,
+		ReversePacketDeltaCount:        5,
+		ReverseOctetDeltaCount:         500,
+		SourcePodNamespace:             "ns-a",
+		DestinationPodNamespace:        "ns-b",
+		DestinationServicePortName:     "ns-b/web:http",
+		IngressNetworkPolicyRuleAction: ipfixregistry.NetworkPolicyRuleActionAllow,
+		FlowType:                       ipfixregistry.FlowTypeInterNode,
+	}
+}
+
+func compareMetrics(t *testing.T, c *Collector, expected string, metricNames ...string) {
+	assert.NoError(t, testutil.CustomCollectAndCompare(c, strings.NewReader(expected), metricNames...))
+}
+
+func TestValidateDimensions(t *testing.T) {
+	assert.NoError(t, ValidateDimensions(nil))
+	assert.NoError(t, ValidateDimensions([]string{"flowType", "sourcePodNamespace"}))
+	assert.EqualError(t, ValidateDimensions([]string{"sourcePodName"}), "dimension sourcePodName is not supported")
+	assert.EqualError(t, ValidateDimensions([]string{"flowType", "flowType"}), "dimension flowType is duplicated")
+}
+
+func TestCollector(t *testing.T) {
+	c, err := NewCollector([]string{"sourcePodNamespace", "destinationServicePortName", "ingressNetworkPolicyRuleAction"}, 10)
+	require.NoError(t, err)
+	now := time.Now()
+	c.AddRecord(newTestFlowRecord(), now)
+	// A record for the same connection is not counted as a new connection.
+	c.AddRecord(newTestFlowRecord(), now)
+	record := newTestFlowRecord()
+	record.SourceTransportPort = 44753
+	record.DestinationServicePortName = ""
+	record.IngressNetworkPolicyRuleAction = ipfixregistry.NetworkPolicyRuleActionDrop
+	record.FlowEndReason = ipfixregistry.EndOfFlowReason
+	c.AddRecord(record, now)
+
+	compareMetrics(t, c, `
+# HELP antrea_flow_aggregator_flow_bytes_total [ALPHA] Number of bytes sent from the source to the destination of flows.
+# TYPE antrea_flow_aggregator_flow_bytes_total counter
+antrea_flow_aggregator_flow_bytes_total{destination_pod_namespace="",destination_service_port_name="",egress_network_policy_rule_action="",flow_type="",ingress_network_policy_rule_action="Drop",source_pod_namespace="ns-a"} 1000
+antrea_flow_aggregator_flow_bytes_total{destination_pod_namespace="",destination_service_port_name="ns-b/web:http",egress_network_policy_rule_action="",flow_type="",ingress_network_policy_rule_action="Allow",source_pod_namespace="ns-a"} 2000
+# HELP antrea_flow_aggregator_flow_reverse_packets_total [ALPHA] Number of packets sent from the destination to the source of flows.
+# TYPE antrea_flow_aggregator_flow_reverse_packets_total counter
+antrea_flow_aggregator_flow_reverse_packets_total{destination_pod_namespace="",destination_service_port_name="",egress_network_policy_rule_action="",flow_type="",ingress_network_policy_rule_action="Drop",source_pod_namespace="ns-a"} 5
+antrea_flow_aggregator_flow_reverse_packets_total{destination_pod_namespace="",destination_service_port_name="ns-b/web:http",egress_network_policy_rule_action="",flow_type="",ingress_network_policy_rule_action="Allow",source_pod_namespace="ns-a"} 10
+# HELP antrea_flow_aggregator_connections_total [ALPHA] Number of connections.
+# TYPE antrea_flow_aggregator_connections_total counter
+antrea_flow_aggregator_connections_total{destination_pod_namespace="",destination_service_port_name="",egress_network_policy_rule_action="",flow_type="",ingress_network_policy_rule_action="Drop",source_pod_namespace="ns-a"} 1
+antrea_flow_aggregator_connections_total{destination_pod_namespace="",destination_service_port_name="ns-b/web:http",egress_network_policy_rule_action="",flow_type="",ingress_network_policy_rule_action="Allow",source_pod_namespace="ns-a"} 1
+# HELP antrea_flow_aggregator_denied_connections_total [ALPHA] Number of connections denied by an Antrea-native policy rule.
+# TYPE antrea_flow_aggregator_denied_connections_total counter
+antrea_flow_aggregator_denied_connections_total{destination_pod_namespace="",destination_service_port_name="",egress_network_policy_rule_action="",flow_type="",ingress_network_policy_rule_action="Drop",source_pod_namespace="ns-a"} 1
+antrea_flow_aggregator_denied_connections_total{destination_pod_namespace="",destination_service_port_name="ns-b/web:http",egress_network_policy_rule_action="",flow_type="",ingress_network_policy_rule_action="Allow",source_pod_namespace="ns-a"} 0
+`, "antrea_flow_aggregator_flow_bytes_total", "antrea_flow_aggregator_flow_reverse_packets_total",
+		"antrea_flow_aggregator_connections_total", "antrea_flow_aggregator_denied_connections_total")
+	// Ended connections are not tracked anymore.
+	assert.Len(t, c.connections, 1)
+}
+
+func TestCollectorMaxSeries(t *testing.T) {
+	c, err := NewCollector([]string{"sourcePodNamespace", "destinationPodNamespace"}, 1)
+	require.NoError(t, err)
+	now := time.Now()
+	c.AddRecord(newTestFlowRecord(), now)
+	for i, namespace := range []string{"ns-c", "ns-d"} {
+		record := newTestFlowRecord()
+		record.SourceTransportPort = uint16(44760 + i)
+		record.DestinationPodNamespace = namespace
+		c.AddRecord(record, now)
+	}
+	// Existing series are still updated once the limit is reached.
+	record := newTestFlowRecord()
+	record.SourceTransportPort = 44753
+	c.AddRecord(record, now)
+
+	compareMetrics(t, c, `
+# HELP antrea_flow_aggregator_connections_total [ALPHA] Number of connections.
+# TYPE antrea_flow_aggregator_connections_total counter
+antrea_flow_aggregator_connections_total{destination_pod_namespace="_other",destination_service_port_name="",egress_network_policy_rule_action="",flow_type="",ingress_network_policy_rule_action="",source_pod_namespace="_other"} 2
+antrea_flow_aggregator_connections_total{destination_pod_namespace="ns-b",destination_service_port_name="",egress_network_policy_rule_action="",flow_type="",ingress_network_policy_rule_action="",source_pod_namespace="ns-a"} 2
+`, "antrea_flow_aggregator_connections_total")
+}
+
+func TestCollectorRemoveStaleConnections(t *testing.T) {
+	c, err := NewCollector([]string{"flowType"}, 10)
+	require.NoError(t, err)
+	now := time.Now()
+	c.AddRecord(newTestFlowRecord(), now.Add(-2*time.Minute))
+	record := newTestFlowRecord()
+	record.SourceTransportPort = 44753
+	c.AddRecord(record, now)
+	c.RemoveStaleConnections(now, time.Minute)
+	require.Len(t, c.connections, 1)
+	// A record received after the connection was removed is counted again.
+	c.AddRecord(newTestFlowRecord(), now)
+
+	compareMetrics(t, c, `
+# HELP antrea_flow_aggregator_connections_total [ALPHA] Number of connections.
+# TYPE antrea_flow_aggregator_connections_total counter
+antrea_flow_aggregator_connections_total{destination_pod_namespace="",destination_service_port_name="",egress_network_policy_rule_action="",flow_type="InterNode",ingress_network_policy_rule_action="",source_pod_namespace=""} 3
+`, "antrea_flow_aggregator_connections_total")
+}
+
+func TestCollectorConfigure(t *testing.T) {
+	c, err := NewCollector([]string{"flowType"}, 10)
+	require.NoError(t, err)
+	c.AddRecord(newTestFlowRecord(), time.Now())
+	require.NoError(t, c.Configure([]string{"flowType"}, 10))
+	assert.Len(t, c.series, 1)
+	assert.Error(t, c.Configure([]string{"sourcePodName"}, 10))
+	assert.Len(t, c.series, 1)
+	// Counters are reset when the dimensions change.
+	require.NoError(t, c.Configure([]string{"sourcePodNamespace"}, 10))
+	assert.Empty(t, c.series)
+	assert.Empty(t, c.connections)
+	c.AddRecord(newTestFlowRecord(), time.Now())
+	c.Reset()
+	assert.Empty(t, c.series)
+}
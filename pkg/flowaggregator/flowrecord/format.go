@@ -0,0 +1,65 @@
+// Copyright 2022 Antrea Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package flowrecord
+
+import (
+	"encoding/json"
+	"strconv"
+)
+
+// AppendCSV appends a row to b as a line of comma-separated values, in the
+// order of Columns. Values are not escaped.
+func AppendCSV(b []byte, r *Row) []byte {
+	for i := range Columns {
+		if i > 0 {
+			b = append(b, ',')
+		}
+		c := &Columns[i]
+		switch c.Kind {
+		case ColumnKindTimestamp:
+			b = strconv.AppendInt(b, c.TimeValue(r).Unix(), 10)
+		case ColumnKindInt32, ColumnKindInt64:
+			b = strconv.AppendInt(b, c.IntValue(r), 10)
+		case ColumnKindString:
+			b = append(b, c.StringValue(r)...)
+		}
+	}
+	return append(b, '\n')
+}
+
+// AppendJSON appends a row to b as a line containing a JSON object keyed by
+// column names ("JSON lines").
+func AppendJSON(b []byte, r *Row) []byte {
+	b = append(b, '{')
+	for i := range Columns {
+		if i > 0 {
+			b = append(b, ',')
+		}
+		c := &Columns[i]
+		b = strconv.AppendQuote(b, c.Name)
+		b = append(b, ':')
+		switch c.Kind {
+		case ColumnKindTimestamp:
+			b = strconv.AppendInt(b, c.TimeValue(r).Unix(), 10)
+		case ColumnKindInt32, ColumnKindInt64:
+			b = strconv.AppendInt(b, c.IntValue(r), 10)
+		case ColumnKindString:
+			// Marshalling a string never fails.
+			value, _ := json.Marshal(c.StringValue(r))
+			b = append(b, value...)
+		}
+	}
+	return append(b, '}', '\n')
+}
@@ -0,0 +1,109 @@
+// Copyright 2022 Antrea Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package flowrecord
+
+import (
+	"encoding/json"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestRow() *Row {
+	return &Row{
+		FlowRecord: &FlowRecord{
+			FlowStartSeconds:        time.Unix(1637706961, 0),
+			FlowEndSeconds:          time.Unix(1637706973, 0),
+			SourceIP:                "10.10.0.79",
+			DestinationIP:           "10.10.0.80",
+			ProtocolIdentifier:      6,
+			OctetTotalCount:         30472817041,
+			SourcePodLabels:         `{"antrea-e2e":"perftest-a","app":"perftool"}`,
+			SourceEndpointKind:      EndpointKindPod,
+			DestinationEndpointKind: EndpointKindExternal,
+			EgressName:              "egress-web",
+			EgressIP:                "172.18.0.100",
+			EgressNodeName:          "k8s-node-2",
+			AppProtocolName:         "dns",
+			DNSQueryName:            "www.example.com",
+			DNSResponseCode:         3,
+		},
+		ClusterUUID:  "fd2a5e2e-f5b1-4fd6-ab2d-1b7c5e7b5e4a",
+		TimeInserted: time.Unix(1637706980, 0),
+	}
+}
+
+// TestColumns checks that all the fields of FlowRecord are written, so that a
+// column is added when a field is added to FlowRecord.
+func TestColumns(t *testing.T) {
+	columnNames := map[string]bool{}
+	for _, c := range Columns {
+		assert.False(t, columnNames[c.Name], "Duplicate column %s", c.Name)
+		columnNames[c.Name] = true
+	}
+	recordType := reflect.TypeOf(FlowRecord{})
+	for i := 0; i < recordType.NumField(); i++ {
+		field := recordType.Field(i)
+		name := field.Tag.Get("json")
+		assert.True(t, columnNames[name], "No column for FlowRecord field %s", field.Name)
+	}
+	assert.Equal(t, recordType.NumField()+2, len(Columns))
+}
+
+func TestAppendCSV(t *testing.T) {
+	line := string(AppendCSV(nil, newTestRow()))
+	require.True(t, strings.HasSuffix(line, "\n"))
+	fields := strings.Split(strings.TrimSuffix(line, "\n"), ",")
+	// The Pod labels contain a comma, as values are not escaped.
+	require.Len(t, fields, len(Columns)+1)
+	assert.Equal(t, "1637706961", fields[0])
+	assert.Equal(t, "10.10.0.79", fields[5])
+	assert.Equal(t, "30472817041", fields[11])
+	assert.Equal(t, "fd2a5e2e-f5b1-4fd6-ab2d-1b7c5e7b5e4a", fields[len(fields)-11])
+	assert.Equal(t, "1637706980", fields[len(fields)-10])
+	assert.Equal(t, "Pod", fields[len(fields)-9])
+	assert.Equal(t, "External", fields[len(fields)-8])
+	assert.Equal(t, "egress-web", fields[len(fields)-7])
+	assert.Equal(t, "172.18.0.100", fields[len(fields)-6])
+	assert.Equal(t, "k8s-node-2", fields[len(fields)-5])
+	assert.Equal(t, "dns", fields[len(fields)-4])
+	assert.Equal(t, "", fields[len(fields)-3])
+	assert.Equal(t, "www.example.com", fields[len(fields)-2])
+	assert.Equal(t, "3", fields[len(fields)-1])
+}
+
+func TestAppendJSON(t *testing.T) {
+	line := AppendJSON([]byte("prefix\n"), newTestRow())
+	require.True(t, strings.HasPrefix(string(line), "prefix\n"))
+	line = line[len("prefix\n"):]
+	var fields map[string]interface{}
+	require.NoError(t, json.Unmarshal(line, &fields))
+	assert.Len(t, fields, len(Columns))
+	assert.Equal(t, float64(1637706961), fields["flowStartSeconds"])
+	assert.Equal(t, "10.10.0.79", fields["sourceIP"])
+	assert.Equal(t, float64(30472817041), fields["octetTotalCount"])
+	assert.Equal(t, `{"antrea-e2e":"perftest-a","app":"perftool"}`, fields["sourcePodLabels"])
+	assert.Equal(t, "fd2a5e2e-f5b1-4fd6-ab2d-1b7c5e7b5e4a", fields["clusterUUID"])
+	assert.Equal(t, float64(1637706980), fields["timeInserted"])
+	assert.Equal(t, "External", fields["destinationEndpointKind"])
+	assert.Equal(t, "172.18.0.100", fields["egressIP"])
+	// Fields are written in the order of columns.
+	assert.True(t, strings.HasPrefix(string(line), `{"flowStartSeconds":1637706961,"flowEndSeconds":1637706973,`))
+	assert.True(t, strings.HasSuffix(string(line), "}\n"))
+}
@@ -20,54 +20,89 @@ import (
 	ipfixentities "github.com/vmware/go-ipfix/pkg/entities"
 )
 
+// EndpointKind is the kind of the source or destination endpoint of a flow.
+type EndpointKind string
+
+const (
+	// EndpointKindPod is a Pod which is not a hostNetwork Pod.
+	EndpointKindPod EndpointKind = "Pod"
+	// EndpointKindNode is a Node, or a hostNetwork Pod which shares the IPs of
+	// its Node.
+	EndpointKindNode EndpointKind = "Node"
+	// EndpointKindExternalEntity is an ExternalEntity, e.g. an interface of an
+	// ExternalNode.
+	EndpointKindExternalEntity EndpointKind = "ExternalEntity"
+	// EndpointKindExternal is an endpoint outside of the cluster, e.g. the
+	// client of a NodePort or LoadBalancer Service.
+	EndpointKindExternal EndpointKind = "External"
+)
+
 type FlowRecord struct {
-	FlowStartSeconds                     time.Time
-	FlowEndSeconds                       time.Time
-	FlowEndSecondsFromSourceNode         time.Time
-	FlowEndSecondsFromDestinationNode    time.Time
-	FlowEndReason                        uint8
-	SourceIP                             string
-	DestinationIP                        string
-	SourceTransportPort                  uint16
-	DestinationTransportPort             uint16
-	ProtocolIdentifier                   uint8
-	PacketTotalCount                     uint64
-	OctetTotalCount                      uint64
-	PacketDeltaCount                     uint64
-	OctetDeltaCount                      uint64
-	ReversePacketTotalCount              uint64
-	ReverseOctetTotalCount               uint64
-	ReversePacketDeltaCount              uint64
-	ReverseOctetDeltaCount               uint64
-	SourcePodName                        string
-	SourcePodNamespace                   string
-	SourceNodeName                       string
-	DestinationPodName                   string
-	DestinationPodNamespace              string
-	DestinationNodeName                  string
-	DestinationClusterIP                 string
-	DestinationServicePort               uint16
-	DestinationServicePortName           string
-	IngressNetworkPolicyName             string
-	IngressNetworkPolicyNamespace        string
-	IngressNetworkPolicyRuleName         string
-	IngressNetworkPolicyRuleAction       uint8
-	IngressNetworkPolicyType             uint8
-	EgressNetworkPolicyName              string
-	EgressNetworkPolicyNamespace         string
-	EgressNetworkPolicyRuleName          string
-	EgressNetworkPolicyRuleAction        uint8
-	EgressNetworkPolicyType              uint8
-	TcpState                             string
-	FlowType                             uint8
-	SourcePodLabels                      string
-	DestinationPodLabels                 string
-	Throughput                           uint64
-	ReverseThroughput                    uint64
-	ThroughputFromSourceNode             uint64
-	ThroughputFromDestinationNode        uint64
-	ReverseThroughputFromSourceNode      uint64
-	ReverseThroughputFromDestinationNode uint64
+	FlowStartSeconds                     time.Time `json:"flowStartSeconds"`
+	FlowEndSeconds                       time.Time `json:"flowEndSeconds"`
+	FlowEndSecondsFromSourceNode         time.Time `json:"flowEndSecondsFromSourceNode"`
+	FlowEndSecondsFromDestinationNode    time.Time `json:"flowEndSecondsFromDestinationNode"`
+	FlowEndReason                        uint8     `json:"flowEndReason"`
+	SourceIP                             string    `json:"sourceIP"`
+	DestinationIP                        string    `json:"destinationIP"`
+	SourceTransportPort                  uint16    `json:"sourceTransportPort"`
+	DestinationTransportPort             uint16    `json:"destinationTransportPort"`
+	ProtocolIdentifier                   uint8     `json:"protocolIdentifier"`
+	PacketTotalCount                     uint64    `json:"packetTotalCount"`
+	OctetTotalCount                      uint64    `json:"octetTotalCount"`
+	PacketDeltaCount                     uint64    `json:"packetDeltaCount"`
+	OctetDeltaCount                      uint64    `json:"octetDeltaCount"`
+	ReversePacketTotalCount              uint64    `json:"reversePacketTotalCount"`
+	ReverseOctetTotalCount               uint64    `json:"reverseOctetTotalCount"`
+	ReversePacketDeltaCount              uint64    `json:"reversePacketDeltaCount"`
+	ReverseOctetDeltaCount               uint64    `json:"reverseOctetDeltaCount"`
+	SourcePodName                        string    `json:"sourcePodName"`
+	SourcePodNamespace                   string    `json:"sourcePodNamespace"`
+	SourceNodeName                       string    `json:"sourceNodeName"`
+	DestinationPodName                   string    `json:"destinationPodName"`
+	DestinationPodNamespace              string    `json:"destinationPodNamespace"`
+	DestinationNodeName                  string    `json:"destinationNodeName"`
+	DestinationClusterIP                 string    `json:"destinationClusterIP"`
+	DestinationServicePort               uint16    `json:"destinationServicePort"`
+	DestinationServicePortName           string    `json:"destinationServicePortName"`
+	IngressNetworkPolicyName             string    `json:"ingressNetworkPolicyName"`
+	IngressNetworkPolicyNamespace        string    `json:"ingressNetworkPolicyNamespace"`
+	IngressNetworkPolicyRuleName         string    `json:"ingressNetworkPolicyRuleName"`
+	IngressNetworkPolicyRuleAction       uint8     `json:"ingressNetworkPolicyRuleAction"`
+	IngressNetworkPolicyType             uint8     `json:"ingressNetworkPolicyType"`
+	EgressNetworkPolicyName              string    `json:"egressNetworkPolicyName"`
+	EgressNetworkPolicyNamespace         string    `json:"egressNetworkPolicyNamespace"`
+	EgressNetworkPolicyRuleName          string    `json:"egressNetworkPolicyRuleName"`
+	EgressNetworkPolicyRuleAction        uint8     `json:"egressNetworkPolicyRuleAction"`
+	EgressNetworkPolicyType              uint8     `json:"egressNetworkPolicyType"`
+	TcpState                             string    `json:"tcpState"`
+	FlowType                             uint8     `json:"flowType"`
+	SourcePodLabels                      string    `json:"sourcePodLabels"`
+	DestinationPodLabels                 string    `json:"destinationPodLabels"`
+	Throughput                           uint64    `json:"throughput"`
+	ReverseThroughput                    uint64    `json:"reverseThroughput"`
+	ThroughputFromSourceNode             uint64    `json:"throughputFromSourceNode"`
+	ThroughputFromDestinationNode        uint64    `json:"throughputFromDestinationNode"`
+	ReverseThroughputFromSourceNode      uint64    `json:"reverseThroughputFromSourceNode"`
+	ReverseThroughputFromDestinationNode uint64    `json:"reverseThroughputFromDestinationNode"`
+	// SourceEndpointKind and DestinationEndpointKind are not part of the
+	// IPFIX records: they are set by the Flow Aggregator.
+	SourceEndpointKind      EndpointKind `json:"sourceEndpointKind"`
+	DestinationEndpointKind EndpointKind `json:"destinationEndpointKind"`
+	// EgressName, EgressIP and EgressNodeName describe the Egress which SNATs
+	// a Pod-to-External flow. They are not part of the IPFIX records either:
+	// they are set by the Flow Aggregator.
+	EgressName     string `json:"egressName"`
+	EgressIP       string `json:"egressIP"`
+	EgressNodeName string `json:"egressNodeName"`
+	// AppProtocolName, HttpVals, DNSQueryName and DNSResponseCode are the L7
+	// information of the connections of the Pods selected for L7 flow
+	// export. HttpVals is a JSON map from the IDs of the HTTP transactions to
+	// the transactions.
+	AppProtocolName string `json:"appProtocolName"`
+	HttpVals        string `json:"httpVals"`
+	DNSQueryName    string `json:"dnsQueryName"`
+	DNSResponseCode uint8  `json:"dnsResponseCode"`
 }
 
 // GetFlowRecord converts ipfixentities.Record to FlowRecord
@@ -220,6 +255,18 @@ func GetFlowRecord(record ipfixentities.Record) *FlowRecord {
 	if revTputFromDstNode, _, ok := record.GetInfoElementWithValue("reverseThroughputFromDestinationNode"); ok {
 		r.ReverseThroughputFromDestinationNode = revTputFromDstNode.GetUnsigned64Value()
 	}
+	if appProtocolName, _, ok := record.GetInfoElementWithValue("appProtocolName"); ok {
+		r.AppProtocolName = appProtocolName.GetStringValue()
+	}
+	if httpVals, _, ok := record.GetInfoElementWithValue("httpVals"); ok {
+		r.HttpVals = httpVals.GetStringValue()
+	}
+	if dnsQueryName, _, ok := record.GetInfoElementWithValue("dnsQueryName"); ok {
+		r.DNSQueryName = dnsQueryName.GetStringValue()
+	}
+	if dnsResponseCode, _, ok := record.GetInfoElementWithValue("dnsResponseCode"); ok {
+		r.DNSResponseCode = dnsResponseCode.GetUnsigned8Value()
+	}
 	return r
 }
 
@@ -272,5 +319,7 @@ func GetTestFlowRecord() *FlowRecord {
 		ThroughputFromDestinationNode:        15902813474,
 		ReverseThroughputFromSourceNode:      12381345,
 		ReverseThroughputFromDestinationNode: 12381346,
+		SourceEndpointKind:                   EndpointKindPod,
+		DestinationEndpointKind:              EndpointKindPod,
 	}
 }
@@ -18,16 +18,16 @@ import (
 	"testing"
 	"time"
 
-	"github.com/golang/mock/gomock"
 	"github.com/stretchr/testify/assert"
 	ipfixentitiestesting "github.com/vmware/go-ipfix/pkg/entities/testing"
-	"github.com/vmware/go-ipfix/pkg/registry"
+	"go.uber.org/mock/gomock"
 
 	flowaggregatortesting "antrea.io/antrea/pkg/flowaggregator/testing"
+	"antrea.io/antrea/pkg/ipfix"
 )
 
 func init() {
-	registry.LoadRegistry()
+	ipfix.LoadRegistry()
 }
 
 func TestGetFlowRecord(t *testing.T) {
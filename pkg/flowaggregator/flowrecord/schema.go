@@ -0,0 +1,130 @@
+// Copyright 2022 Antrea Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package flowrecord
+
+import (
+	"time"
+)
+
+type ColumnKind int
+
+const (
+	// ColumnKindTimestamp columns are written as seconds since the Unix epoch
+	// in CSV and JSON files, and as timestamps in Parquet files.
+	ColumnKindTimestamp ColumnKind = iota
+	ColumnKindInt32
+	ColumnKindInt64
+	ColumnKindString
+)
+
+// Row is a flow record with the additional information written to files.
+type Row struct {
+	*FlowRecord
+	ClusterUUID  string
+	TimeInserted time.Time
+}
+
+// Column is a column of the files to which flow records are written. Only the
+// getter matching the kind of the column is set.
+type Column struct {
+	Name        string
+	Kind        ColumnKind
+	TimeValue   func(r *Row) time.Time
+	IntValue    func(r *Row) int64
+	StringValue func(r *Row) string
+}
+
+func timestampColumn(name string, value func(r *Row) time.Time) Column {
+	return Column{Name: name, Kind: ColumnKindTimestamp, TimeValue: value}
+}
+
+func int32Column(name string, value func(r *Row) int64) Column {
+	return Column{Name: name, Kind: ColumnKindInt32, IntValue: value}
+}
+
+func int64Column(name string, value func(r *Row) int64) Column {
+	return Column{Name: name, Kind: ColumnKindInt64, IntValue: value}
+}
+
+func stringColumn(name string, value func(r *Row) string) Column {
+	return Column{Name: name, Kind: ColumnKindString, StringValue: value}
+}
+
+// Columns defines the schema of the files to which flow records are written
+// (e.g. by the S3 uploader), for all record formats. CSV files rely on the
+// position of the columns, while JSON and Parquet files rely on their names. To
+// keep the files written by different versions of the Flow Aggregator readable
+// with the same table definition, new columns must only be appended at the end,
+// and existing columns must never be removed, renamed or change kind.
+var Columns = []Column{
+	timestampColumn("flowStartSeconds", func(r *Row) time.Time { return r.FlowStartSeconds }),
+	timestampColumn("flowEndSeconds", func(r *Row) time.Time { return r.FlowEndSeconds }),
+	timestampColumn("flowEndSecondsFromSourceNode", func(r *Row) time.Time { return r.FlowEndSecondsFromSourceNode }),
+	timestampColumn("flowEndSecondsFromDestinationNode", func(r *Row) time.Time { return r.FlowEndSecondsFromDestinationNode }),
+	int32Column("flowEndReason", func(r *Row) int64 { return int64(r.FlowEndReason) }),
+	stringColumn("sourceIP", func(r *Row) string { return r.SourceIP }),
+	stringColumn("destinationIP", func(r *Row) string { return r.DestinationIP }),
+	int32Column("sourceTransportPort", func(r *Row) int64 { return int64(r.SourceTransportPort) }),
+	int32Column("destinationTransportPort", func(r *Row) int64 { return int64(r.DestinationTransportPort) }),
+	int32Column("protocolIdentifier", func(r *Row) int64 { return int64(r.ProtocolIdentifier) }),
+	int64Column("packetTotalCount", func(r *Row) int64 { return int64(r.PacketTotalCount) }),
+	int64Column("octetTotalCount", func(r *Row) int64 { return int64(r.OctetTotalCount) }),
+	int64Column("packetDeltaCount", func(r *Row) int64 { return int64(r.PacketDeltaCount) }),
+	int64Column("octetDeltaCount", func(r *Row) int64 { return int64(r.OctetDeltaCount) }),
+	int64Column("reversePacketTotalCount", func(r *Row) int64 { return int64(r.ReversePacketTotalCount) }),
+	int64Column("reverseOctetTotalCount", func(r *Row) int64 { return int64(r.ReverseOctetTotalCount) }),
+	int64Column("reversePacketDeltaCount", func(r *Row) int64 { return int64(r.ReversePacketDeltaCount) }),
+	int64Column("reverseOctetDeltaCount", func(r *Row) int64 { return int64(r.ReverseOctetDeltaCount) }),
+	stringColumn("sourcePodName", func(r *Row) string { return r.SourcePodName }),
+	stringColumn("sourcePodNamespace", func(r *Row) string { return r.SourcePodNamespace }),
+	stringColumn("sourceNodeName", func(r *Row) string { return r.SourceNodeName }),
+	stringColumn("destinationPodName", func(r *Row) string { return r.DestinationPodName }),
+	stringColumn("destinationPodNamespace", func(r *Row) string { return r.DestinationPodNamespace }),
+	stringColumn("destinationNodeName", func(r *Row) string { return r.DestinationNodeName }),
+	stringColumn("destinationClusterIP", func(r *Row) string { return r.DestinationClusterIP }),
+	int32Column("destinationServicePort", func(r *Row) int64 { return int64(r.DestinationServicePort) }),
+	stringColumn("destinationServicePortName", func(r *Row) string { return r.DestinationServicePortName }),
+	stringColumn("ingressNetworkPolicyName", func(r *Row) string { return r.IngressNetworkPolicyName }),
+	stringColumn("ingressNetworkPolicyNamespace", func(r *Row) string { return r.IngressNetworkPolicyNamespace }),
+	stringColumn("ingressNetworkPolicyRuleName", func(r *Row) string { return r.IngressNetworkPolicyRuleName }),
+	int32Column("ingressNetworkPolicyRuleAction", func(r *Row) int64 { return int64(r.IngressNetworkPolicyRuleAction) }),
+	int32Column("ingressNetworkPolicyType", func(r *Row) int64 { return int64(r.IngressNetworkPolicyType) }),
+	stringColumn("egressNetworkPolicyName", func(r *Row) string { return r.EgressNetworkPolicyName }),
+	stringColumn("egressNetworkPolicyNamespace", func(r *Row) string { return r.EgressNetworkPolicyNamespace }),
+	stringColumn("egressNetworkPolicyRuleName", func(r *Row) string { return r.EgressNetworkPolicyRuleName }),
+	int32Column("egressNetworkPolicyRuleAction", func(r *Row) int64 { return int64(r.EgressNetworkPolicyRuleAction) }),
+	int32Column("egressNetworkPolicyType", func(r *Row) int64 { return int64(r.EgressNetworkPolicyType) }),
+	stringColumn("tcpState", func(r *Row) string { return r.TcpState }),
+	int32Column("flowType", func(r *Row) int64 { return int64(r.FlowType) }),
+	stringColumn("sourcePodLabels", func(r *Row) string { return r.SourcePodLabels }),
+	stringColumn("destinationPodLabels", func(r *Row) string { return r.DestinationPodLabels }),
+	int64Column("throughput", func(r *Row) int64 { return int64(r.Throughput) }),
+	int64Column("reverseThroughput", func(r *Row) int64 { return int64(r.ReverseThroughput) }),
+	int64Column("throughputFromSourceNode", func(r *Row) int64 { return int64(r.ThroughputFromSourceNode) }),
+	int64Column("throughputFromDestinationNode", func(r *Row) int64 { return int64(r.ThroughputFromDestinationNode) }),
+	int64Column("reverseThroughputFromSourceNode", func(r *Row) int64 { return int64(r.ReverseThroughputFromSourceNode) }),
+	int64Column("reverseThroughputFromDestinationNode", func(r *Row) int64 { return int64(r.ReverseThroughputFromDestinationNode) }),
+	stringColumn("clusterUUID", func(r *Row) string { return r.ClusterUUID }),
+	timestampColumn("timeInserted", func(r *Row) time.Time { return r.TimeInserted }),
+	stringColumn("sourceEndpointKind", func(r *Row) string { return string(r.SourceEndpointKind) }),
+	stringColumn("destinationEndpointKind", func(r *Row) string { return string(r.DestinationEndpointKind) }),
+	stringColumn("egressName", func(r *Row) string { return r.EgressName }),
+	stringColumn("egressIP", func(r *Row) string { return r.EgressIP }),
+	stringColumn("egressNodeName", func(r *Row) string { return r.EgressNodeName }),
+	stringColumn("appProtocolName", func(r *Row) string { return r.AppProtocolName }),
+	stringColumn("httpVals", func(r *Row) string { return r.HttpVals }),
+	stringColumn("dnsQueryName", func(r *Row) string { return r.DNSQueryName }),
+	int32Column("dnsResponseCode", func(r *Row) int64 { return int64(r.DNSResponseCode) }),
+}
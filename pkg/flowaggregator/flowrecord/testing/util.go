@@ -70,5 +70,9 @@ func PrepareTestFlowRecord() *flowrecord.FlowRecord {
 		ThroughputFromDestinationNode:        15902813474,
 		ReverseThroughputFromSourceNode:      12381345,
 		ReverseThroughputFromDestinationNode: 12381346,
+		SourceEndpointKind:                   flowrecord.EndpointKindPod,
+		DestinationEndpointKind:              flowrecord.EndpointKindPod,
+		AppProtocolName:                      "http",
+		HttpVals:                             `{"0":"{\"hostname\":\"perftest-b\",\"url\":\"/\",\"http_method\":\"GET\",\"protocol\":\"HTTP/1.1\",\"status\":200}"}`,
 	}
 }
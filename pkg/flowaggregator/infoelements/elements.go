@@ -58,13 +58,25 @@ var (
 		"tcpState",
 		"flowType",
 	}
-	AntreaInfoElementsIPv4 = append(AntreaInfoElementsCommon, []string{"destinationClusterIPv4"}...)
-	AntreaInfoElementsIPv6 = append(AntreaInfoElementsCommon, []string{"destinationClusterIPv6"}...)
+	AntreaL7ElementList = []string{
+		"appProtocolName",
+		"httpVals",
+	}
+	AntreaInfoElementsIPv4 = append(AntreaInfoElementsCommon, append([]string{"destinationClusterIPv4"}, AntreaL7ElementList...)...)
+	AntreaInfoElementsIPv6 = append(AntreaInfoElementsCommon, append([]string{"destinationClusterIPv6"}, AntreaL7ElementList...)...)
+	// AntreaDNSElementList are sent by the Agents after AntreaL7ElementList.
+	// They are registered by Antrea and not by go-ipfix, so they are not sent
+	// to the external flow collector.
+	AntreaDNSElementList = []string{
+		"dnsQueryName",
+		"dnsResponseCode",
+	}
 
 	NonStatsElementList = []string{
 		"flowEndSeconds",
 		"flowEndReason",
 		"tcpState",
+		"httpVals",
 	}
 	StatsElementList = []string{
 		"octetDeltaCount",
@@ -0,0 +1,201 @@
+// Copyright 2022 Antrea Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package kafkaproducer
+
+import (
+	"crypto/sha512"
+	"crypto/tls"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/Shopify/sarama"
+	"github.com/xdg/scram"
+	"k8s.io/klog/v2"
+)
+
+const (
+	SASLMechanismPlain       = string(sarama.SASLTypePlaintext)
+	SASLMechanismSCRAMSHA256 = string(sarama.SASLTypeSCRAMSHA256)
+	SASLMechanismSCRAMSHA512 = string(sarama.SASLTypeSCRAMSHA512)
+
+	clientID = "flow-aggregator"
+
+	dialTimeout = 10 * time.Second
+	// requestTimeout is the maximum time to wait for the response to a request.
+	requestTimeout = 30 * time.Second
+	// produceTimeout is the time the broker waits for the replicas to
+	// acknowledge produced records. It must be smaller than requestTimeout.
+	produceTimeout = 10 * time.Second
+)
+
+// Message is a message produced to Kafka.
+type Message struct {
+	Key       []byte
+	Value     []byte
+	Timestamp time.Time
+}
+
+// ClientConfig is the configuration of a Kafka client.
+type ClientConfig struct {
+	// Brokers is the list of bootstrap brokers, used to discover the Kafka
+	// cluster.
+	Brokers []string
+	// TLSConfig is the configuration used to connect to brokers over TLS. If
+	// nil, plaintext connections are used.
+	TLSConfig *tls.Config
+	// SASLMechanism is the SASL mechanism used to authenticate to brokers. If
+	// empty, SASL authentication is disabled.
+	SASLMechanism string
+	SASLUsername  string
+	SASLPassword  string
+}
+
+// scramClient implements sarama.SCRAMClient with the xdg/scram library.
+type scramClient struct {
+	hashGeneratorFcn scram.HashGeneratorFcn
+	conversation     *scram.ClientConversation
+}
+
+func (c *scramClient) Begin(userName, password, authzID string) error {
+	client, err := c.hashGeneratorFcn.NewClient(userName, password, authzID)
+	if err != nil {
+		return err
+	}
+	c.conversation = client.NewConversation()
+	return nil
+}
+
+func (c *scramClient) Step(challenge string) (string, error) {
+	return c.conversation.Step(challenge)
+}
+
+func (c *scramClient) Done() bool {
+	return c.conversation.Done()
+}
+
+var sha512HashGeneratorFcn scram.HashGeneratorFcn = sha512.New
+
+// newSaramaConfig returns the sarama configuration corresponding to config.
+func newSaramaConfig(config ClientConfig) *sarama.Config {
+	saramaConfig := sarama.NewConfig()
+	saramaConfig.ClientID = clientID
+	// Record timestamps and SASL handshake v1, required by SCRAM, are supported
+	// since Kafka 1.0.
+	saramaConfig.Version = sarama.V1_0_0_0
+	saramaConfig.Net.DialTimeout = dialTimeout
+	saramaConfig.Net.ReadTimeout = requestTimeout
+	saramaConfig.Net.WriteTimeout = requestTimeout
+	saramaConfig.Producer.RequiredAcks = sarama.WaitForAll
+	saramaConfig.Producer.Timeout = produceTimeout
+	saramaConfig.Producer.Partitioner = sarama.NewHashPartitioner
+	// Both are required by the SyncProducer.
+	saramaConfig.Producer.Return.Successes = true
+	saramaConfig.Producer.Return.Errors = true
+	if config.TLSConfig != nil {
+		saramaConfig.Net.TLS.Enable = true
+		saramaConfig.Net.TLS.Config = config.TLSConfig
+	}
+	if config.SASLMechanism != "" {
+		saramaConfig.Net.SASL.Enable = true
+		saramaConfig.Net.SASL.Version = sarama.SASLHandshakeV1
+		saramaConfig.Net.SASL.Mechanism = sarama.SASLMechanism(config.SASLMechanism)
+		saramaConfig.Net.SASL.User = config.SASLUsername
+		saramaConfig.Net.SASL.Password = config.SASLPassword
+		switch config.SASLMechanism {
+		case SASLMechanismSCRAMSHA256:
+			saramaConfig.Net.SASL.SCRAMClientGeneratorFunc = func() sarama.SCRAMClient {
+				return &scramClient{hashGeneratorFcn: scram.SHA256}
+			}
+		case SASLMechanismSCRAMSHA512:
+			saramaConfig.Net.SASL.SCRAMClientGeneratorFunc = func() sarama.SCRAMClient {
+				return &scramClient{hashGeneratorFcn: sha512HashGeneratorFcn}
+			}
+		}
+	}
+	return saramaConfig
+}
+
+// client produces messages to Kafka with a sarama SyncProducer. The producer is
+// created on first use, so that the Flow Aggregator can start while the Kafka
+// cluster is unavailable, and it is created again after it is closed.
+type client struct {
+	config ClientConfig
+	// mutex protects producer, as Close can be called concurrently with
+	// SendMessages.
+	mutex    sync.Mutex
+	producer sarama.SyncProducer
+}
+
+func newClient(config ClientConfig) *client {
+	return &client{config: config}
+}
+
+// SendMessages produces messages to topic. It returns the messages which could
+// not be produced, with the corresponding error. Messages produced to different
+// partitions may succeed or fail independently, so that the caller only needs
+// to retry the failed ones.
+func (c *client) SendMessages(topic string, messages []*Message) ([]*Message, error) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	if c.producer == nil {
+		producer, err := sarama.NewSyncProducer(c.config.Brokers, newSaramaConfig(c.config))
+		if err != nil {
+			return messages, fmt.Errorf("error when creating Kafka producer: %v", err)
+		}
+		c.producer = producer
+	}
+	producerMessages := make([]*sarama.ProducerMessage, 0, len(messages))
+	for _, msg := range messages {
+		producerMessage := &sarama.ProducerMessage{
+			Topic:     topic,
+			Value:     sarama.ByteEncoder(msg.Value),
+			Timestamp: msg.Timestamp,
+			Metadata:  msg,
+		}
+		// Messages without key are distributed randomly across partitions.
+		if msg.Key != nil {
+			producerMessage.Key = sarama.ByteEncoder(msg.Key)
+		}
+		producerMessages = append(producerMessages, producerMessage)
+	}
+	err := c.producer.SendMessages(producerMessages)
+	if err == nil {
+		return nil, nil
+	}
+	producerErrors, ok := err.(sarama.ProducerErrors)
+	if !ok {
+		return messages, err
+	}
+	failed := make([]*Message, 0, len(producerErrors))
+	for _, producerError := range producerErrors {
+		failed = append(failed, producerError.Msg.Metadata.(*Message))
+	}
+	klog.V(2).InfoS("Failed to produce messages to Kafka", "topic", topic, "failed", len(failed), "total", len(messages))
+	return failed, producerErrors[len(producerErrors)-1].Err
+}
+
+// Close closes the producer and its connections to brokers.
+func (c *client) Close() {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	if c.producer == nil {
+		return
+	}
+	if err := c.producer.Close(); err != nil {
+		klog.ErrorS(err, "Error when closing Kafka producer")
+	}
+	c.producer = nil
+}
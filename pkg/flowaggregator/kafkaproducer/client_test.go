@@ -0,0 +1,271 @@
+// Copyright 2022 Antrea Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package kafkaproducer
+
+import (
+	"crypto/tls"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/Shopify/sarama"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/xdg/scram"
+	certutil "k8s.io/client-go/util/cert"
+)
+
+// newMockProduceResponse returns a MockProduceResponse matching the version of
+// the Produce requests sent for Kafka 1.0.
+func newMockProduceResponse(t *testing.T) *sarama.MockProduceResponse {
+	return sarama.NewMockProduceResponse(t).SetVersion(3)
+}
+
+// newMockBroker returns a sarama MockBroker which is the leader of all the
+// partitions of topic, and which returns produceResponse to Produce requests.
+func newMockBroker(t *testing.T, topic string, numPartitions int32, produceResponse *sarama.MockProduceResponse) *sarama.MockBroker {
+	broker := sarama.NewMockBroker(t, 1)
+	t.Cleanup(broker.Close)
+	setMockBrokerHandlers(t, broker, topic, numPartitions, produceResponse, nil)
+	return broker
+}
+
+func setMockBrokerHandlers(t *testing.T, broker *sarama.MockBroker, topic string, numPartitions int32, produceResponse *sarama.MockProduceResponse, extraHandlers map[string]sarama.MockResponse) {
+	metadataResponse := sarama.NewMockMetadataResponse(t).SetBroker(broker.Addr(), broker.BrokerID())
+	for partition := int32(0); partition < numPartitions; partition++ {
+		metadataResponse.SetLeader(topic, partition, broker.BrokerID())
+	}
+	if produceResponse == nil {
+		produceResponse = newMockProduceResponse(t)
+	}
+	handlers := map[string]sarama.MockResponse{
+		"MetadataRequest": metadataResponse,
+		"ProduceRequest":  produceResponse,
+	}
+	for name, handler := range extraHandlers {
+		handlers[name] = handler
+	}
+	broker.SetHandlerByMap(handlers)
+}
+
+// countProduceRequests returns the number of Produce requests received by
+// broker.
+func countProduceRequests(broker *sarama.MockBroker) int {
+	count := 0
+	for _, rr := range broker.History() {
+		if _, ok := rr.Request.(*sarama.ProduceRequest); ok {
+			count++
+		}
+	}
+	return count
+}
+
+func newTestMessages(keys ...string) []*Message {
+	var messages []*Message
+	for i, key := range keys {
+		msg := &Message{Value: []byte(fmt.Sprintf("record%d", i)), Timestamp: time.UnixMilli(1637706961000)}
+		if key != "" {
+			msg.Key = []byte(key)
+		}
+		messages = append(messages, msg)
+	}
+	return messages
+}
+
+func TestClientSendMessages(t *testing.T) {
+	broker := newMockBroker(t, "flows", 3, nil)
+	c := newClient(ClientConfig{Brokers: []string{broker.Addr()}})
+	defer c.Close()
+
+	failed, err := c.SendMessages("flows", newTestMessages("", "ns1", "ns1"))
+	require.NoError(t, err)
+	assert.Empty(t, failed)
+	assert.Greater(t, countProduceRequests(broker), 0)
+
+	// The producer is created again after the client is closed.
+	c.Close()
+	failed, err = c.SendMessages("flows", newTestMessages("ns2"))
+	require.NoError(t, err)
+	assert.Empty(t, failed)
+
+	messages := newTestMessages("")
+	failed, err = c.SendMessages("unknown", messages)
+	assert.Error(t, err)
+	assert.Equal(t, messages, failed)
+}
+
+func TestClientPartitionError(t *testing.T) {
+	messages := newTestMessages("ns1", "ns2", "ns3", "ns4")
+	// Find which messages are produced to partition 0 with the hash partitioner.
+	partitioner := sarama.NewHashPartitioner("flows")
+	var expectedFailed []*Message
+	for _, msg := range messages {
+		partition, err := partitioner.Partition(&sarama.ProducerMessage{Key: sarama.ByteEncoder(msg.Key)}, 2)
+		require.NoError(t, err)
+		if partition == 0 {
+			expectedFailed = append(expectedFailed, msg)
+		}
+	}
+	require.NotEmpty(t, expectedFailed)
+	require.Less(t, len(expectedFailed), len(messages))
+
+	// ErrInvalidMessage is not retried by the producer.
+	broker := newMockBroker(t, "flows", 2, newMockProduceResponse(t).SetError("flows", 0, sarama.ErrInvalidMessage))
+	c := newClient(ClientConfig{Brokers: []string{broker.Addr()}})
+	defer c.Close()
+	failed, err := c.SendMessages("flows", messages)
+	assert.Equal(t, sarama.ErrInvalidMessage, err)
+	assert.ElementsMatch(t, expectedFailed, failed)
+}
+
+func TestClientBrokerUnavailable(t *testing.T) {
+	broker := sarama.NewMockBroker(t, 1)
+	addr := broker.Addr()
+	broker.Close()
+	c := newClient(ClientConfig{Brokers: []string{addr}})
+	defer c.Close()
+	messages := newTestMessages("")
+	failed, err := c.SendMessages("flows", messages)
+	assert.ErrorContains(t, err, "error when creating Kafka producer")
+	assert.Equal(t, messages, failed)
+}
+
+func TestClientSASLPlain(t *testing.T) {
+	for _, tc := range []struct {
+		name          string
+		authError     sarama.KError
+		expectedError string
+	}{
+		{name: "success", authError: sarama.ErrNoError},
+		{name: "failure", authError: sarama.ErrSASLAuthenticationFailed, expectedError: "error when creating Kafka producer"},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			broker := sarama.NewMockBroker(t, 1)
+			defer broker.Close()
+			setMockBrokerHandlers(t, broker, "flows", 1, nil, map[string]sarama.MockResponse{
+				"SaslHandshakeRequest":    sarama.NewMockSaslHandshakeResponse(t).SetEnabledMechanisms([]string{SASLMechanismPlain}),
+				"SaslAuthenticateRequest": sarama.NewMockSaslAuthenticateResponse(t).SetError(tc.authError),
+			})
+			c := newClient(ClientConfig{Brokers: []string{broker.Addr()}, SASLMechanism: SASLMechanismPlain, SASLUsername: "user", SASLPassword: "password"})
+			defer c.Close()
+			_, err := c.SendMessages("flows", newTestMessages(""))
+			if tc.expectedError == "" {
+				assert.NoError(t, err)
+			} else {
+				assert.ErrorContains(t, err, tc.expectedError)
+			}
+		})
+	}
+}
+
+// TestSCRAMClient runs the SCRAM conversation of the client generated for each
+// mechanism against a SCRAM server.
+func TestSCRAMClient(t *testing.T) {
+	for _, tc := range []struct {
+		mechanism        string
+		hashGeneratorFcn scram.HashGeneratorFcn
+	}{
+		{mechanism: SASLMechanismSCRAMSHA256, hashGeneratorFcn: scram.SHA256},
+		{mechanism: SASLMechanismSCRAMSHA512, hashGeneratorFcn: sha512HashGeneratorFcn},
+	} {
+		t.Run(tc.mechanism, func(t *testing.T) {
+			saramaConfig := newSaramaConfig(ClientConfig{SASLMechanism: tc.mechanism, SASLUsername: "user", SASLPassword: "password"})
+			require.NoError(t, saramaConfig.Validate())
+			require.NotNil(t, saramaConfig.Net.SASL.SCRAMClientGeneratorFunc)
+
+			credentialsClient, err := tc.hashGeneratorFcn.NewClient("user", "password", "")
+			require.NoError(t, err)
+			credentials := credentialsClient.GetStoredCredentials(scram.KeyFactors{Salt: "salt", Iters: 4096})
+			server, err := tc.hashGeneratorFcn.NewServer(func(username string) (scram.StoredCredentials, error) {
+				if username != "user" {
+					return scram.StoredCredentials{}, fmt.Errorf("unknown user %s", username)
+				}
+				return credentials, nil
+			})
+			require.NoError(t, err)
+
+			runConversation := func(password string) error {
+				client := saramaConfig.Net.SASL.SCRAMClientGeneratorFunc()
+				require.NoError(t, client.Begin("user", password, ""))
+				serverConversation := server.NewConversation()
+				challenge := ""
+				for !client.Done() {
+					response, err := client.Step(challenge)
+					if err != nil {
+						return err
+					}
+					if response == "" {
+						break
+					}
+					challenge, err = serverConversation.Step(response)
+					if err != nil {
+						return err
+					}
+				}
+				return nil
+			}
+			assert.NoError(t, runConversation("password"))
+			assert.Error(t, runConversation("wrong"))
+		})
+	}
+}
+
+func TestClientTLS(t *testing.T) {
+	certPEM, keyPEM, err := certutil.GenerateSelfSignedCertKey("127.0.0.1", nil, nil)
+	require.NoError(t, err)
+	cert, err := tls.X509KeyPair(certPEM, keyPEM)
+	require.NoError(t, err)
+	listener, err := tls.Listen("tcp", "127.0.0.1:0", &tls.Config{Certificates: []tls.Certificate{cert}})
+	require.NoError(t, err)
+	broker := sarama.NewMockBrokerListener(t, 1, listener)
+	defer broker.Close()
+	setMockBrokerHandlers(t, broker, "flows", 1, nil, nil)
+
+	input := KafkaInput{CACert: string(certPEM)}
+	input.Config.Brokers = []string{broker.Addr()}
+	input.Config.TLS.Enable = true
+	clientConfig, err := buildClientConfig(input)
+	require.NoError(t, err)
+	c := newClient(clientConfig)
+	defer c.Close()
+	failed, err := c.SendMessages("flows", newTestMessages(""))
+	require.NoError(t, err)
+	assert.Empty(t, failed)
+
+	// The broker's certificate cannot be verified without the CA certificate.
+	// A plain TLS listener is used, as the MockBroker fails the test when a
+	// handshake fails.
+	listener, err = tls.Listen("tcp", "127.0.0.1:0", &tls.Config{Certificates: []tls.Certificate{cert}})
+	require.NoError(t, err)
+	defer listener.Close()
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			conn.(*tls.Conn).Handshake()
+			conn.Close()
+		}
+	}()
+	input.CACert = ""
+	input.Config.Brokers = []string{listener.Addr().String()}
+	clientConfig, err = buildClientConfig(input)
+	require.NoError(t, err)
+	c = newClient(clientConfig)
+	defer c.Close()
+	_, err = c.SendMessages("flows", newTestMessages(""))
+	assert.Error(t, err)
+}
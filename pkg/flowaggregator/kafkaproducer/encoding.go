@@ -0,0 +1,122 @@
+// Copyright 2022 Antrea Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package kafkaproducer
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"google.golang.org/protobuf/proto"
+
+	"antrea.io/antrea/pkg/flowaggregator/flowrecord"
+	flowpb "antrea.io/antrea/pkg/flowaggregator/kafkaproducer/protobuf"
+)
+
+const (
+	EncodingJSON     = "JSON"
+	EncodingProtobuf = "Protobuf"
+)
+
+// jsonFlowRecord is the JSON representation of a flow record produced to Kafka.
+type jsonFlowRecord struct {
+	*flowrecord.FlowRecord
+	ClusterUUID string `json:"clusterUUID"`
+}
+
+// encodeFlowRecord serializes a flow record with the given encoding.
+func encodeFlowRecord(r *flowrecord.FlowRecord, clusterUUID string, encoding string) ([]byte, error) {
+	switch encoding {
+	case EncodingJSON:
+		return json.Marshal(&jsonFlowRecord{FlowRecord: r, ClusterUUID: clusterUUID})
+	case EncodingProtobuf:
+		return encodeProtobufFlowRecord(r, clusterUUID)
+	}
+	return nil, fmt.Errorf("unsupported encoding %s", encoding)
+}
+
+// encodeProtobufFlowRecord serializes a flow record as a FlowRecord message
+// defined in protobuf/flowrecord.proto.
+func encodeProtobufFlowRecord(r *flowrecord.FlowRecord, clusterUUID string) ([]byte, error) {
+	return proto.Marshal(&flowpb.FlowRecord{
+		FlowStartSeconds:                     unixSeconds(r.FlowStartSeconds),
+		FlowEndSeconds:                       unixSeconds(r.FlowEndSeconds),
+		FlowEndSecondsFromSourceNode:         unixSeconds(r.FlowEndSecondsFromSourceNode),
+		FlowEndSecondsFromDestinationNode:    unixSeconds(r.FlowEndSecondsFromDestinationNode),
+		FlowEndReason:                        uint32(r.FlowEndReason),
+		SourceIp:                             r.SourceIP,
+		DestinationIp:                        r.DestinationIP,
+		SourceTransportPort:                  uint32(r.SourceTransportPort),
+		DestinationTransportPort:             uint32(r.DestinationTransportPort),
+		ProtocolIdentifier:                   uint32(r.ProtocolIdentifier),
+		PacketTotalCount:                     r.PacketTotalCount,
+		OctetTotalCount:                      r.OctetTotalCount,
+		PacketDeltaCount:                     r.PacketDeltaCount,
+		OctetDeltaCount:                      r.OctetDeltaCount,
+		ReversePacketTotalCount:              r.ReversePacketTotalCount,
+		ReverseOctetTotalCount:               r.ReverseOctetTotalCount,
+		ReversePacketDeltaCount:              r.ReversePacketDeltaCount,
+		ReverseOctetDeltaCount:               r.ReverseOctetDeltaCount,
+		SourcePodName:                        r.SourcePodName,
+		SourcePodNamespace:                   r.SourcePodNamespace,
+		SourceNodeName:                       r.SourceNodeName,
+		DestinationPodName:                   r.DestinationPodName,
+		DestinationPodNamespace:              r.DestinationPodNamespace,
+		DestinationNodeName:                  r.DestinationNodeName,
+		DestinationClusterIp:                 r.DestinationClusterIP,
+		DestinationServicePort:               uint32(r.DestinationServicePort),
+		DestinationServicePortName:           r.DestinationServicePortName,
+		IngressNetworkPolicyName:             r.IngressNetworkPolicyName,
+		IngressNetworkPolicyNamespace:        r.IngressNetworkPolicyNamespace,
+		IngressNetworkPolicyRuleName:         r.IngressNetworkPolicyRuleName,
+		IngressNetworkPolicyRuleAction:       uint32(r.IngressNetworkPolicyRuleAction),
+		IngressNetworkPolicyType:             uint32(r.IngressNetworkPolicyType),
+		EgressNetworkPolicyName:              r.EgressNetworkPolicyName,
+		EgressNetworkPolicyNamespace:         r.EgressNetworkPolicyNamespace,
+		EgressNetworkPolicyRuleName:          r.EgressNetworkPolicyRuleName,
+		EgressNetworkPolicyRuleAction:        uint32(r.EgressNetworkPolicyRuleAction),
+		EgressNetworkPolicyType:              uint32(r.EgressNetworkPolicyType),
+		TcpState:                             r.TcpState,
+		FlowType:                             uint32(r.FlowType),
+		SourcePodLabels:                      r.SourcePodLabels,
+		DestinationPodLabels:                 r.DestinationPodLabels,
+		Throughput:                           r.Throughput,
+		ReverseThroughput:                    r.ReverseThroughput,
+		ThroughputFromSourceNode:             r.ThroughputFromSourceNode,
+		ThroughputFromDestinationNode:        r.ThroughputFromDestinationNode,
+		ReverseThroughputFromSourceNode:      r.ReverseThroughputFromSourceNode,
+		ReverseThroughputFromDestinationNode: r.ReverseThroughputFromDestinationNode,
+		ClusterUuid:                          clusterUUID,
+		SourceEndpointKind:                   string(r.SourceEndpointKind),
+		DestinationEndpointKind:              string(r.DestinationEndpointKind),
+		EgressName:                           r.EgressName,
+		EgressIp:                             r.EgressIP,
+		EgressNodeName:                       r.EgressNodeName,
+		AppProtocolName:                      r.AppProtocolName,
+		HttpVals:                             r.HttpVals,
+		DnsQueryName:                         r.DNSQueryName,
+		DnsResponseCode:                      uint32(r.DNSResponseCode),
+	})
+}
+
+// unixSeconds returns a timestamp as seconds since the Unix epoch. The zero
+// time, i.e. a timestamp missing from the IPFIX record, is encoded as 0 so that
+// the field is omitted.
+func unixSeconds(t time.Time) int64 {
+	if t.IsZero() {
+		return 0
+	}
+	return t.Unix()
+}
@@ -0,0 +1,85 @@
+// Copyright 2022 Antrea Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package kafkaproducer
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/protobuf/proto"
+
+	"antrea.io/antrea/pkg/flowaggregator/flowrecord"
+	flowrecordtesting "antrea.io/antrea/pkg/flowaggregator/flowrecord/testing"
+	flowpb "antrea.io/antrea/pkg/flowaggregator/kafkaproducer/protobuf"
+)
+
+var fakeClusterUUID = uuid.New().String()
+
+func TestEncodeFlowRecordJSON(t *testing.T) {
+	record := flowrecordtesting.PrepareTestFlowRecord()
+	data, err := encodeFlowRecord(record, fakeClusterUUID, EncodingJSON)
+	require.NoError(t, err)
+
+	var fields map[string]interface{}
+	require.NoError(t, json.Unmarshal(data, &fields))
+	assert.Len(t, fields, 57)
+	assert.Equal(t, "10.10.0.79", fields["sourceIP"])
+	assert.Equal(t, "antrea-test", fields["sourcePodNamespace"])
+	assert.Equal(t, float64(5201), fields["destinationTransportPort"])
+	assert.Equal(t, fakeClusterUUID, fields["clusterUUID"])
+
+	var decoded flowrecord.FlowRecord
+	require.NoError(t, json.Unmarshal(data, &decoded))
+	assert.True(t, record.FlowStartSeconds.Equal(decoded.FlowStartSeconds))
+	decoded.FlowStartSeconds = record.FlowStartSeconds
+	decoded.FlowEndSeconds = record.FlowEndSeconds
+	decoded.FlowEndSecondsFromSourceNode = record.FlowEndSecondsFromSourceNode
+	decoded.FlowEndSecondsFromDestinationNode = record.FlowEndSecondsFromDestinationNode
+	assert.Equal(t, *record, decoded)
+}
+
+func TestEncodeFlowRecordProtobuf(t *testing.T) {
+	record := flowrecordtesting.PrepareTestFlowRecord()
+	data, err := encodeFlowRecord(record, fakeClusterUUID, EncodingProtobuf)
+	require.NoError(t, err)
+	var decoded flowpb.FlowRecord
+	require.NoError(t, proto.Unmarshal(data, &decoded))
+	assert.Equal(t, int64(1637706961), decoded.FlowStartSeconds)
+	assert.Equal(t, uint32(3), decoded.FlowEndReason)
+	assert.Equal(t, "10.10.0.79", decoded.SourceIp)
+	assert.Equal(t, uint64(30472817041), decoded.OctetTotalCount)
+	assert.Equal(t, "antrea-test", decoded.SourcePodNamespace)
+	assert.Equal(t, "TIME_WAIT", decoded.TcpState)
+	assert.Equal(t, uint64(12381346), decoded.ReverseThroughputFromDestinationNode)
+	assert.Equal(t, fakeClusterUUID, decoded.ClusterUuid)
+	assert.Equal(t, "Pod", decoded.SourceEndpointKind)
+	assert.Equal(t, "http", decoded.AppProtocolName)
+	assert.Equal(t, record.HttpVals, decoded.HttpVals)
+
+	// Fields with the default value, including missing timestamps, are omitted.
+	data, err = encodeFlowRecord(&flowrecord.FlowRecord{SourceIP: "10.10.0.79"}, "", EncodingProtobuf)
+	require.NoError(t, err)
+	expected, err := proto.Marshal(&flowpb.FlowRecord{SourceIp: "10.10.0.79"})
+	require.NoError(t, err)
+	assert.Equal(t, expected, data)
+}
+
+func TestEncodeFlowRecordUnsupported(t *testing.T) {
+	_, err := encodeFlowRecord(flowrecordtesting.PrepareTestFlowRecord(), fakeClusterUUID, "Avro")
+	assert.EqualError(t, err, "unsupported encoding Avro")
+}
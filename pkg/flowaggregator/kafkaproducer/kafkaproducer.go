@@ -0,0 +1,336 @@
+// Copyright 2022 Antrea Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package kafkaproducer
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"sync"
+	"time"
+
+	"k8s.io/klog/v2"
+
+	config "antrea.io/antrea/pkg/config/flowaggregator"
+	"antrea.io/antrea/pkg/flowaggregator/flowrecord"
+)
+
+const (
+	PartitionByNone            = "None"
+	PartitionBySourceNamespace = "SourceNamespace"
+	PartitionBySourceNode      = "SourceNode"
+)
+
+// newProducer is used for unit testing.
+var newProducer = func(clientConfig ClientConfig) KafkaProducerAPI {
+	return newClient(clientConfig)
+}
+
+type stopPayload struct {
+	flushQueue bool
+}
+
+type KafkaInput struct {
+	Config        config.KafkaConfig
+	FlushInterval time.Duration
+	// SASLUsername and SASLPassword are the credentials used for SASL
+	// authentication, if enabled.
+	SASLUsername string
+	SASLPassword string
+	// CACert is the PEM-encoded CA certificate used to verify the brokers'
+	// certificates when TLS is enabled. If empty, the system CAs are used.
+	CACert string
+}
+
+// KafkaProducerAPI is the interface of the client producing messages to
+// Kafka, defined to assist unit testing.
+type KafkaProducerAPI interface {
+	// SendMessages produces messages to topic, and returns the messages which
+	// could not be produced.
+	SendMessages(topic string, messages []*Message) ([]*Message, error)
+	Close()
+}
+
+type KafkaProducerProcess struct {
+	topic              string
+	encoding           string
+	partitionBy        string
+	maxBatchSize       int
+	maxBufferedRecords int
+	// flushInterval is the interval between two flushes of the pending records
+	flushInterval time.Duration
+	// flushTicker is a ticker, containing a channel used to trigger flush() for every flushInterval period
+	flushTicker *time.Ticker
+	// flushCh is used to trigger flush() when a full batch of records is pending
+	flushCh chan struct{}
+	// stopCh is the channel to receive stop message
+	stopCh chan stopPayload
+	// exportWg is to ensure that all messages have been flushed from the queue when we stop
+	exportWg             sync.WaitGroup
+	exportProcessRunning bool
+	// mutex protects configuration state from concurrent access
+	mutex sync.Mutex
+	// queueMutex protects pendingMessages from concurrent access
+	queueMutex sync.Mutex
+	// pendingMessages stores the encoded records which have not been produced
+	// yet, including the ones which failed to be produced and will be retried.
+	pendingMessages []*Message
+	producer        KafkaProducerAPI
+	clusterUUID     string
+}
+
+func buildClientConfig(input KafkaInput) (ClientConfig, error) {
+	kafkaConfig := input.Config
+	clientConfig := ClientConfig{
+		Brokers: kafkaConfig.Brokers,
+	}
+	if kafkaConfig.TLS.Enable {
+		clientConfig.TLSConfig = &tls.Config{
+			MinVersion: tls.VersionTLS12,
+			// #nosec G402: only set by users who explicitly opt out of certificate verification
+			InsecureSkipVerify: kafkaConfig.TLS.InsecureSkipVerify,
+		}
+		if input.CACert != "" {
+			caPool := x509.NewCertPool()
+			if !caPool.AppendCertsFromPEM([]byte(input.CACert)) {
+				return clientConfig, fmt.Errorf("no valid certificate found in Kafka CA certificate")
+			}
+			clientConfig.TLSConfig.RootCAs = caPool
+		}
+	}
+	if kafkaConfig.SASL.Mechanism != "" {
+		clientConfig.SASLMechanism = kafkaConfig.SASL.Mechanism
+		clientConfig.SASLUsername = input.SASLUsername
+		clientConfig.SASLPassword = input.SASLPassword
+	}
+	return clientConfig, nil
+}
+
+func NewKafkaProducerProcess(input KafkaInput, clusterUUID string) (*KafkaProducerProcess, error) {
+	clientConfig, err := buildClientConfig(input)
+	if err != nil {
+		return nil, err
+	}
+	kafkaConfig := input.Config
+	return &KafkaProducerProcess{
+		topic:              kafkaConfig.Topic,
+		encoding:           kafkaConfig.Encoding,
+		partitionBy:        kafkaConfig.PartitionBy,
+		maxBatchSize:       int(kafkaConfig.MaxBatchSize),
+		maxBufferedRecords: int(kafkaConfig.MaxBufferedRecords),
+		flushInterval:      input.FlushInterval,
+		flushCh:            make(chan struct{}, 1),
+		producer:           newProducer(clientConfig),
+		clusterUUID:        clusterUUID,
+	}, nil
+}
+
+func (p *KafkaProducerProcess) GetTopic() string {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+	return p.topic
+}
+
+func (p *KafkaProducerProcess) GetFlushInterval() time.Duration {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+	return p.flushInterval
+}
+
+func (p *KafkaProducerProcess) SetFlushInterval(flushInterval time.Duration) {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+	p.flushInterval = flushInterval
+	if p.flushTicker != nil {
+		p.flushTicker.Reset(p.flushInterval)
+	}
+}
+
+// UpdateKafkaProducer applies a new configuration. The records which have not
+// been produced yet are kept and will be produced with the new configuration.
+func (p *KafkaProducerProcess) UpdateKafkaProducer(input KafkaInput) error {
+	clientConfig, err := buildClientConfig(input)
+	if err != nil {
+		return err
+	}
+	p.stopExportProcess(false)
+	defer p.startExportProcess()
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+	p.producer.Close()
+	p.producer = newProducer(clientConfig)
+	kafkaConfig := input.Config
+	p.topic = kafkaConfig.Topic
+	p.encoding = kafkaConfig.Encoding
+	p.partitionBy = kafkaConfig.PartitionBy
+	p.maxBatchSize = int(kafkaConfig.MaxBatchSize)
+	p.maxBufferedRecords = int(kafkaConfig.MaxBufferedRecords)
+	p.flushInterval = input.FlushInterval
+	return nil
+}
+
+// CacheRecord encodes a flow record and adds it to the records pending to be
+// produced. A flush is triggered when a full batch of records is pending.
+func (p *KafkaProducerProcess) CacheRecord(r *flowrecord.FlowRecord) error {
+	p.mutex.Lock()
+	encoding, partitionBy, maxBatchSize, maxBufferedRecords := p.encoding, p.partitionBy, p.maxBatchSize, p.maxBufferedRecords
+	p.mutex.Unlock()
+	value, err := encodeFlowRecord(r, p.clusterUUID, encoding)
+	if err != nil {
+		return err
+	}
+	msg := &Message{
+		Key:       partitionKey(r, partitionBy),
+		Value:     value,
+		Timestamp: time.Now(),
+	}
+	p.queueMutex.Lock()
+	defer p.queueMutex.Unlock()
+	p.pendingMessages = append(p.pendingMessages, msg)
+	if len(p.pendingMessages) > maxBufferedRecords {
+		// Drop the oldest records, which are the most likely to be stale,
+		// when Kafka has been unavailable for too long.
+		dropped := len(p.pendingMessages) - maxBufferedRecords
+		p.pendingMessages = p.pendingMessages[dropped:]
+		klog.V(2).InfoS("Kafka record buffer is full, dropping oldest records", "count", dropped)
+	}
+	if len(p.pendingMessages) >= maxBatchSize {
+		select {
+		case p.flushCh <- struct{}{}:
+		default:
+		}
+	}
+	return nil
+}
+
+// partitionKey returns the key used to choose the partition of a flow record.
+// Records with the same key are produced to the same partition, which
+// preserves their order.
+func partitionKey(r *flowrecord.FlowRecord, partitionBy string) []byte {
+	switch partitionBy {
+	case PartitionBySourceNamespace:
+		return []byte(r.SourcePodNamespace)
+	case PartitionBySourceNode:
+		return []byte(r.SourceNodeName)
+	}
+	return nil
+}
+
+func (p *KafkaProducerProcess) Start() {
+	p.startExportProcess()
+}
+
+func (p *KafkaProducerProcess) Stop() {
+	p.stopExportProcess(true)
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+	p.producer.Close()
+}
+
+func (p *KafkaProducerProcess) startExportProcess() {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+	if p.exportProcessRunning {
+		return
+	}
+	p.exportProcessRunning = true
+	p.flushTicker = time.NewTicker(p.flushInterval)
+	p.stopCh = make(chan stopPayload, 1)
+	p.exportWg.Add(1)
+	go func() {
+		defer p.exportWg.Done()
+		p.flowRecordPeriodicCommit()
+	}()
+}
+
+func (p *KafkaProducerProcess) stopExportProcess(flushQueue bool) {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+	if !p.exportProcessRunning {
+		return
+	}
+	p.exportProcessRunning = false
+	defer p.flushTicker.Stop()
+	p.stopCh <- stopPayload{
+		flushQueue: flushQueue,
+	}
+	p.exportWg.Wait()
+}
+
+func (p *KafkaProducerProcess) flowRecordPeriodicCommit() {
+	klog.InfoS("Starting Kafka exporting process")
+	for {
+		select {
+		case stop := <-p.stopCh:
+			klog.InfoS("Stopping Kafka exporting process")
+			if !stop.flushQueue {
+				return
+			}
+			if err := p.flush(); err != nil {
+				klog.ErrorS(err, "Error when flushing records to Kafka on stop")
+			}
+			return
+		case <-p.flushTicker.C:
+			if err := p.flush(); err != nil {
+				klog.ErrorS(err, "Error when flushing records to Kafka on triggered timer")
+			}
+		case <-p.flushCh:
+			if err := p.flush(); err != nil {
+				klog.ErrorS(err, "Error when flushing a full batch of records to Kafka")
+			}
+		}
+	}
+}
+
+// flush produces all pending records to Kafka, in batches of at most
+// maxBatchSize records. It stops at the first batch which cannot be fully
+// produced, and the records which failed are kept to be retried by the next
+// flush.
+func (p *KafkaProducerProcess) flush() error {
+	// flush is only called from flowRecordPeriodicCommit, so the configuration
+	// cannot change while it is running.
+	topic, maxBatchSize, producer := p.topic, p.maxBatchSize, p.producer
+	for {
+		p.queueMutex.Lock()
+		batchSize := len(p.pendingMessages)
+		if batchSize > maxBatchSize {
+			batchSize = maxBatchSize
+		}
+		batch := p.pendingMessages[:batchSize:batchSize]
+		p.queueMutex.Unlock()
+		if batchSize == 0 {
+			return nil
+		}
+		failed, err := producer.SendMessages(topic, batch)
+		p.queueMutex.Lock()
+		// The oldest records may have been dropped by CacheRecord while the
+		// batch was being produced.
+		remaining := p.pendingMessages
+		for i := range batch {
+			if len(remaining) == 0 || remaining[0] != batch[i] {
+				continue
+			}
+			remaining = remaining[1:]
+		}
+		// Allocate a new slice, as failed may share its backing array with batch.
+		pendingMessages := make([]*Message, 0, len(failed)+len(remaining))
+		pendingMessages = append(pendingMessages, failed...)
+		p.pendingMessages = append(pendingMessages, remaining...)
+		p.queueMutex.Unlock()
+		if err != nil {
+			return fmt.Errorf("error when producing %d records to Kafka topic %s: %v", len(failed), topic, err)
+		}
+	}
+}
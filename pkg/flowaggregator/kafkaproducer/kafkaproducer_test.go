@@ -0,0 +1,244 @@
+// Copyright 2022 Antrea Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package kafkaproducer
+
+import (
+	"encoding/json"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	ipfixentitiestesting "github.com/vmware/go-ipfix/pkg/entities/testing"
+	"go.uber.org/mock/gomock"
+	"k8s.io/apimachinery/pkg/util/wait"
+
+	config "antrea.io/antrea/pkg/config/flowaggregator"
+	"antrea.io/antrea/pkg/flowaggregator/flowrecord"
+	flowaggregatortesting "antrea.io/antrea/pkg/flowaggregator/testing"
+	"antrea.io/antrea/pkg/ipfix"
+)
+
+func init() {
+	ipfix.LoadRegistry()
+}
+
+type fakeProducer struct {
+	mutex sync.Mutex
+	// batches are the batches of messages produced successfully.
+	batches [][]*Message
+	// failCount is the number of messages to fail in each batch.
+	failCount int
+	closed    bool
+}
+
+func (p *fakeProducer) SendMessages(topic string, messages []*Message) ([]*Message, error) {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+	if p.failCount > 0 {
+		failCount := p.failCount
+		if failCount > len(messages) {
+			failCount = len(messages)
+		}
+		p.batches = append(p.batches, messages[failCount:])
+		return messages[:failCount], fmt.Errorf("broker unavailable")
+	}
+	p.batches = append(p.batches, messages)
+	return nil, nil
+}
+
+func (p *fakeProducer) Close() {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+	p.closed = true
+}
+
+func (p *fakeProducer) getBatches() [][]*Message {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+	return p.batches
+}
+
+func newTestKafkaInput(brokers ...string) KafkaInput {
+	return KafkaInput{
+		Config: config.KafkaConfig{
+			Enable:             true,
+			Brokers:            brokers,
+			Topic:              "flows",
+			Encoding:           EncodingJSON,
+			PartitionBy:        PartitionBySourceNamespace,
+			MaxBatchSize:       2,
+			MaxBufferedRecords: 3,
+		},
+		FlushInterval: time.Hour,
+	}
+}
+
+func cacheMockRecords(t *testing.T, p *KafkaProducerProcess, count int) {
+	ctrl := gomock.NewController(t)
+	for i := 0; i < count; i++ {
+		mockRecord := ipfixentitiestesting.NewMockRecord(ctrl)
+		flowaggregatortesting.PrepareMockIpfixRecord(mockRecord, true)
+		require.NoError(t, p.CacheRecord(flowrecord.GetFlowRecord(mockRecord)))
+	}
+}
+
+func TestCacheRecord(t *testing.T) {
+	p, err := NewKafkaProducerProcess(newTestKafkaInput("127.0.0.1:9092"), fakeClusterUUID)
+	require.NoError(t, err)
+
+	cacheMockRecords(t, p, 1)
+	require.Len(t, p.pendingMessages, 1)
+	msg := p.pendingMessages[0]
+	assert.Equal(t, []byte("antrea-test"), msg.Key)
+	var fields map[string]interface{}
+	require.NoError(t, json.Unmarshal(msg.Value, &fields))
+	assert.Equal(t, "10.10.0.79", fields["sourceIP"])
+	assert.Equal(t, fakeClusterUUID, fields["clusterUUID"])
+	assert.Empty(t, p.flushCh)
+
+	// A flush is triggered when a full batch is pending.
+	cacheMockRecords(t, p, 1)
+	assert.Len(t, p.flushCh, 1)
+
+	// The oldest records are dropped when the buffer is full.
+	cacheMockRecords(t, p, 2)
+	require.Len(t, p.pendingMessages, 3)
+	assert.NotContains(t, p.pendingMessages, msg)
+}
+
+func TestPartitionKey(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	for _, tc := range []struct {
+		partitionBy string
+		expectedKey []byte
+	}{
+		{PartitionByNone, nil},
+		{PartitionBySourceNamespace, []byte("antrea-test")},
+		{PartitionBySourceNode, []byte("k8s-node-control-plane")},
+	} {
+		input := newTestKafkaInput("127.0.0.1:9092")
+		input.Config.PartitionBy = tc.partitionBy
+		p, err := NewKafkaProducerProcess(input, fakeClusterUUID)
+		require.NoError(t, err)
+		mockRecord := ipfixentitiestesting.NewMockRecord(ctrl)
+		flowaggregatortesting.PrepareMockIpfixRecord(mockRecord, true)
+		require.NoError(t, p.CacheRecord(flowrecord.GetFlowRecord(mockRecord)))
+		assert.Equal(t, tc.expectedKey, p.pendingMessages[0].Key, "Unexpected key when partitioning by %s", tc.partitionBy)
+	}
+}
+
+func TestFlush(t *testing.T) {
+	producer := &fakeProducer{}
+	p := &KafkaProducerProcess{
+		topic:              "flows",
+		encoding:           EncodingJSON,
+		maxBatchSize:       2,
+		maxBufferedRecords: 10,
+		producer:           producer,
+		clusterUUID:        fakeClusterUUID,
+	}
+	messages := newTestMessages("", "", "", "", "")
+	p.pendingMessages = append([]*Message{}, messages...)
+	require.NoError(t, p.flush())
+	assert.Equal(t, [][]*Message{messages[0:2], messages[2:4], messages[4:5]}, producer.getBatches())
+	assert.Empty(t, p.pendingMessages)
+}
+
+func TestFlushError(t *testing.T) {
+	producer := &fakeProducer{failCount: 1}
+	p := &KafkaProducerProcess{
+		topic:              "flows",
+		encoding:           EncodingJSON,
+		maxBatchSize:       2,
+		maxBufferedRecords: 10,
+		producer:           producer,
+		clusterUUID:        fakeClusterUUID,
+	}
+	messages := newTestMessages("", "", "")
+	p.pendingMessages = append([]*Message{}, messages...)
+	// The flush stops at the first error, and the failed messages are retried
+	// first on the next flush.
+	assert.EqualError(t, p.flush(), "error when producing 1 records to Kafka topic flows: broker unavailable")
+	assert.Equal(t, [][]*Message{messages[1:2]}, producer.getBatches())
+	assert.Equal(t, []*Message{messages[0], messages[2]}, p.pendingMessages)
+
+	producer.failCount = 0
+	require.NoError(t, p.flush())
+	assert.Equal(t, [][]*Message{messages[1:2], {messages[0], messages[2]}}, producer.getBatches())
+	assert.Empty(t, p.pendingMessages)
+}
+
+func TestKafkaProducerProcess(t *testing.T) {
+	broker := newMockBroker(t, "flows", 2, nil)
+	input := newTestKafkaInput(broker.Addr())
+	input.Config.MaxBufferedRecords = 10
+	input.Config.Encoding = EncodingProtobuf
+	p, err := NewKafkaProducerProcess(input, fakeClusterUUID)
+	require.NoError(t, err)
+	p.Start()
+
+	// A full batch is flushed immediately.
+	cacheMockRecords(t, p, 2)
+	err = wait.PollImmediate(10*time.Millisecond, 2*time.Second, func() (bool, error) {
+		return countProduceRequests(broker) > 0, nil
+	})
+	require.NoError(t, err)
+
+	// Pending records are flushed on stop.
+	cacheMockRecords(t, p, 1)
+	p.Stop()
+	assert.Empty(t, p.pendingMessages)
+}
+
+func TestUpdateKafkaProducer(t *testing.T) {
+	producers := []*fakeProducer{}
+	originalNewProducer := newProducer
+	defer func() {
+		newProducer = originalNewProducer
+	}()
+	newProducer = func(clientConfig ClientConfig) KafkaProducerAPI {
+		producer := &fakeProducer{}
+		producers = append(producers, producer)
+		return producer
+	}
+
+	p, err := NewKafkaProducerProcess(newTestKafkaInput("127.0.0.1:9092"), fakeClusterUUID)
+	require.NoError(t, err)
+	p.Start()
+	defer p.Stop()
+	cacheMockRecords(t, p, 1)
+
+	input := newTestKafkaInput("127.0.0.1:9093")
+	input.Config.Topic = "flows-new"
+	input.Config.SASL.Mechanism = SASLMechanismPlain
+	require.NoError(t, p.UpdateKafkaProducer(input))
+	assert.Equal(t, "flows-new", p.GetTopic())
+	require.Len(t, producers, 2)
+	assert.True(t, producers[0].closed)
+	assert.Empty(t, producers[0].getBatches())
+	// Pending records are kept.
+	assert.Len(t, p.pendingMessages, 1)
+
+	input.Config.TLS.Enable = true
+	input.CACert = "invalid"
+	assert.Error(t, p.UpdateKafkaProducer(input))
+	assert.Equal(t, "flows-new", p.GetTopic())
+
+	p.SetFlushInterval(time.Minute)
+	assert.Equal(t, time.Minute, p.GetFlushInterval())
+}
@@ -0,0 +1,111 @@
+// Copyright 2022 Antrea Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package flowaggregator
+
+import (
+	"context"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"k8s.io/client-go/tools/leaderelection"
+	"k8s.io/client-go/tools/leaderelection/resourcelock"
+	"k8s.io/klog/v2"
+
+	flowaggregatorconfig "antrea.io/antrea/pkg/config/flowaggregator"
+	"antrea.io/antrea/pkg/flowaggregator/exporter"
+)
+
+const (
+	// The leader of the Flow Aggregator replicas holds this Lease.
+	leaderElectionLeaseName      = "flow-aggregator"
+	leaderElectionLeaseNamespace = "flow-aggregator"
+	leaderElectionLeaseDuration  = 15 * time.Second
+	leaderElectionRenewDeadline  = 10 * time.Second
+	leaderElectionRetryPeriod    = 2 * time.Second
+	// leaderSyncInterval is the interval at which the leader synchronizes the
+	// state shared by all the replicas.
+	leaderSyncInterval = 10 * time.Minute
+)
+
+// runLeaderElection elects a leader among the Flow Aggregator replicas. The
+// leader is in charge of the state shared by all the replicas:
+//   - it renews the CA before it expires, and publishes the CA certificate and
+//     the client certificate to the Flow Exporters. All the replicas watch the
+//     CA and reissue their server certificate when it changes.
+//   - it releases the IPFIX template IDs which are no longer used by any
+//     replica, so that they can be reused for new templates.
+//
+// A replica which loses the leadership becomes a candidate again, until stopCh
+// is closed.
+func (fa *flowAggregator) runLeaderElection(stopCh <-chan struct{}) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go func() {
+		<-stopCh
+		cancel()
+	}()
+	le, err := leaderelection.NewLeaderElector(leaderelection.LeaderElectionConfig{
+		Lock: &resourcelock.LeaseLock{
+			LeaseMeta: metav1.ObjectMeta{
+				Name:      leaderElectionLeaseName,
+				Namespace: leaderElectionLeaseNamespace,
+			},
+			Client: fa.k8sClient.CoordinationV1(),
+			LockConfig: resourcelock.ResourceLockConfig{
+				Identity: fa.podName,
+			},
+		},
+		LeaseDuration:   leaderElectionLeaseDuration,
+		RenewDeadline:   leaderElectionRenewDeadline,
+		RetryPeriod:     leaderElectionRetryPeriod,
+		ReleaseOnCancel: true,
+		Callbacks: leaderelection.LeaderCallbacks{
+			OnStartedLeading: func(ctx context.Context) {
+				klog.InfoS("Started leading Flow Aggregator replicas", "identity", fa.podName)
+				wait.UntilWithContext(ctx, fa.syncSharedState, leaderSyncInterval)
+			},
+			OnStoppedLeading: func() {
+				klog.InfoS("Stopped leading Flow Aggregator replicas", "identity", fa.podName)
+			},
+			OnNewLeader: func(identity string) {
+				klog.InfoS("New leader elected for Flow Aggregator replicas", "identity", identity)
+			},
+		},
+	})
+	if err != nil {
+		klog.ErrorS(err, "Error when creating leader elector")
+		return
+	}
+	for {
+		le.Run(ctx)
+		select {
+		case <-ctx.Done():
+			return
+		default:
+		}
+	}
+}
+
+func (fa *flowAggregator) syncSharedState(ctx context.Context) {
+	if fa.aggregatorTransportProtocol == flowaggregatorconfig.AggregatorTransportProtocolTLS {
+		if err := publishCACert(fa.k8sClient); err != nil {
+			klog.ErrorS(err, "Error when publishing CA certificate")
+		}
+	}
+	if err := exporter.PruneIPFIXTemplates(fa.k8sClient); err != nil {
+		klog.ErrorS(err, "Error when releasing unused IPFIX template IDs")
+	}
+}
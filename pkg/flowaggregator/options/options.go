@@ -22,6 +22,8 @@ import (
 	"gopkg.in/yaml.v2"
 
 	flowaggregatorconfig "antrea.io/antrea/pkg/config/flowaggregator"
+	"antrea.io/antrea/pkg/flowaggregator/flowfilter"
+	"antrea.io/antrea/pkg/flowaggregator/flowmetrics"
 	"antrea.io/antrea/pkg/util/flowexport"
 )
 
@@ -42,6 +44,19 @@ type Options struct {
 	ClickHouseCommitInterval time.Duration
 	// Flow records batch upload interval from flow aggregator to S3 bucket
 	S3UploadInterval time.Duration
+	// Maximum interval between two flushes of flow records to Kafka
+	KafkaFlushInterval time.Duration
+	// Maximum duration during which flow records are written to the same local file, 0 if
+	// time-based rotation is disabled
+	FileExporterRotationInterval time.Duration
+	// Filters selecting the flow records sent to each exporter, nil if all flow records are
+	// sent
+	FlowCollectorFilter *flowfilter.Filter
+	ClickHouseFilter    *flowfilter.Filter
+	S3UploaderFilter    *flowfilter.Filter
+	KafkaFilter         *flowfilter.Filter
+	FileExporterFilter  *flowfilter.Filter
+	FlowMetricsFilter   *flowfilter.Filter
 }
 
 func LoadConfig(configBytes []byte) (*Options, error) {
@@ -57,8 +72,14 @@ func LoadConfig(configBytes []byte) (*Options, error) {
 	if opt.Config.S3Uploader.Enable && opt.Config.S3Uploader.BucketName == "" {
 		return nil, fmt.Errorf("s3Uploader enabled without specifying bucket name")
 	}
-	if !opt.Config.FlowCollector.Enable && !opt.Config.ClickHouse.Enable && !opt.Config.S3Uploader.Enable {
-		return nil, fmt.Errorf("external flow collector or ClickHouse or S3Uploader should be configured")
+	if opt.Config.Kafka.Enable && len(opt.Config.Kafka.Brokers) == 0 {
+		return nil, fmt.Errorf("kafka enabled without specifying brokers")
+	}
+	if opt.Config.Kafka.Enable && opt.Config.Kafka.Topic == "" {
+		return nil, fmt.Errorf("kafka enabled without specifying topic")
+	}
+	if !opt.Config.FlowCollector.Enable && !opt.Config.ClickHouse.Enable && !opt.Config.S3Uploader.Enable && !opt.Config.Kafka.Enable && !opt.Config.FileExporter.Enable && !opt.Config.FlowMetrics.Enable {
+		return nil, fmt.Errorf("external flow collector or ClickHouse or S3Uploader or Kafka or file exporter or flow metrics should be configured")
 	}
 	// Validate common parameters
 	var err error
@@ -102,9 +123,14 @@ func LoadConfig(configBytes []byte) (*Options, error) {
 	}
 	// Validate S3Uploader specific parameters
 	if opt.Config.S3Uploader.Enable {
-		if opt.Config.S3Uploader.RecordFormat != "CSV" {
+		switch opt.Config.S3Uploader.RecordFormat {
+		case "CSV", "JSON", "Parquet":
+		default:
 			return nil, fmt.Errorf("record format %s is not supported", opt.Config.S3Uploader.RecordFormat)
 		}
+		if opt.Config.S3Uploader.MaxRecordsPerFile < 0 {
+			return nil, fmt.Errorf("maxRecordsPerFile %d is invalid: it must be positive", opt.Config.S3Uploader.MaxRecordsPerFile)
+		}
 		opt.S3UploadInterval, err = time.ParseDuration(opt.Config.S3Uploader.UploadInterval)
 		if err != nil {
 			return nil, err
@@ -114,5 +140,95 @@ func LoadConfig(configBytes []byte) (*Options, error) {
 				opt.Config.S3Uploader.UploadInterval, flowaggregatorconfig.MinS3CommitInterval)
 		}
 	}
+	// Validate Kafka specific parameters
+	if opt.Config.Kafka.Enable {
+		if opt.Config.Kafka.Encoding != "JSON" && opt.Config.Kafka.Encoding != "Protobuf" {
+			return nil, fmt.Errorf("kafka encoding %s is not supported", opt.Config.Kafka.Encoding)
+		}
+		switch opt.Config.Kafka.PartitionBy {
+		case "None", "SourceNamespace", "SourceNode":
+		default:
+			return nil, fmt.Errorf("kafka partitionBy %s is not supported", opt.Config.Kafka.PartitionBy)
+		}
+		switch opt.Config.Kafka.SASL.Mechanism {
+		case "", "PLAIN", "SCRAM-SHA-256", "SCRAM-SHA-512":
+		default:
+			return nil, fmt.Errorf("kafka SASL mechanism %s is not supported", opt.Config.Kafka.SASL.Mechanism)
+		}
+		if opt.Config.Kafka.MaxBatchSize < 0 {
+			return nil, fmt.Errorf("kafka maxBatchSize %d must be positive", opt.Config.Kafka.MaxBatchSize)
+		}
+		if opt.Config.Kafka.MaxBufferedRecords < opt.Config.Kafka.MaxBatchSize {
+			return nil, fmt.Errorf("kafka maxBufferedRecords %d must not be smaller than maxBatchSize %d",
+				opt.Config.Kafka.MaxBufferedRecords, opt.Config.Kafka.MaxBatchSize)
+		}
+		opt.KafkaFlushInterval, err = time.ParseDuration(opt.Config.Kafka.FlushInterval)
+		if err != nil {
+			return nil, err
+		}
+		if opt.KafkaFlushInterval < flowaggregatorconfig.MinKafkaFlushInterval {
+			return nil, fmt.Errorf("flushInterval %s is too small: shortest supported interval is %v",
+				opt.Config.Kafka.FlushInterval, flowaggregatorconfig.MinKafkaFlushInterval)
+		}
+	}
+	// Validate file exporter specific parameters
+	if opt.Config.FileExporter.Enable {
+		if opt.Config.FileExporter.RecordFormat != "CSV" && opt.Config.FileExporter.RecordFormat != "JSON" {
+			return nil, fmt.Errorf("record format %s is not supported", opt.Config.FileExporter.RecordFormat)
+		}
+		if opt.Config.FileExporter.MaxFileSize < 0 {
+			return nil, fmt.Errorf("maxFileSize %d is invalid: it must be positive", opt.Config.FileExporter.MaxFileSize)
+		}
+		if opt.Config.FileExporter.MaxBackups < 0 {
+			return nil, fmt.Errorf("maxBackups %d is invalid: it must be positive", opt.Config.FileExporter.MaxBackups)
+		}
+		if opt.Config.FileExporter.MaxAge < 0 {
+			return nil, fmt.Errorf("maxAge %d is invalid: it must not be negative", opt.Config.FileExporter.MaxAge)
+		}
+		if opt.Config.FileExporter.RotationInterval != "" {
+			opt.FileExporterRotationInterval, err = time.ParseDuration(opt.Config.FileExporter.RotationInterval)
+			if err != nil {
+				return nil, err
+			}
+			if opt.FileExporterRotationInterval < flowaggregatorconfig.MinFileExporterRotationInterval {
+				return nil, fmt.Errorf("rotationInterval %s is too small: shortest supported interval is %v",
+					opt.Config.FileExporter.RotationInterval, flowaggregatorconfig.MinFileExporterRotationInterval)
+			}
+		}
+	}
+	// Validate flow metrics specific parameters
+	if opt.Config.FlowMetrics.Enable {
+		if err := flowmetrics.ValidateDimensions(opt.Config.FlowMetrics.Dimensions); err != nil {
+			return nil, fmt.Errorf("invalid flowMetrics dimensions: %v", err)
+		}
+		if opt.Config.FlowMetrics.MaxSeries < 0 {
+			return nil, fmt.Errorf("maxSeries %d is invalid: it must be positive", opt.Config.FlowMetrics.MaxSeries)
+		}
+	}
+	// Validate filters
+	for _, f := range []struct {
+		name   string
+		enable bool
+		rules  []flowaggregatorconfig.FlowFilterRule
+		filter **flowfilter.Filter
+	}{
+		{"flowCollector", opt.Config.FlowCollector.Enable, opt.Config.FlowCollector.Filters, &opt.FlowCollectorFilter},
+		{"clickHouse", opt.Config.ClickHouse.Enable, opt.Config.ClickHouse.Filters, &opt.ClickHouseFilter},
+		{"s3Uploader", opt.Config.S3Uploader.Enable, opt.Config.S3Uploader.Filters, &opt.S3UploaderFilter},
+		{"kafka", opt.Config.Kafka.Enable, opt.Config.Kafka.Filters, &opt.KafkaFilter},
+		{"fileExporter", opt.Config.FileExporter.Enable, opt.Config.FileExporter.Filters, &opt.FileExporterFilter},
+		{"flowMetrics", opt.Config.FlowMetrics.Enable, opt.Config.FlowMetrics.Filters, &opt.FlowMetricsFilter},
+	} {
+		if !f.enable {
+			continue
+		}
+		if flowfilter.UsesPodLabels(f.rules) && !opt.Config.RecordContents.PodLabels {
+			return nil, fmt.Errorf("%s filters use podSelector but recordContents.podLabels is not enabled", f.name)
+		}
+		*f.filter, err = flowfilter.New(f.rules)
+		if err != nil {
+			return nil, fmt.Errorf("invalid %s filters: %v", f.name, err)
+		}
+	}
 	return &opt, nil
 }
@@ -21,8 +21,8 @@ package testing
 
 import (
 	querier "antrea.io/antrea/pkg/flowaggregator/querier"
-	gomock "github.com/golang/mock/gomock"
 	intermediate "github.com/vmware/go-ipfix/pkg/intermediate"
+	gomock "go.uber.org/mock/gomock"
 	reflect "reflect"
 )
 
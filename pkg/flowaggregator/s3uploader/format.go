@@ -0,0 +1,77 @@
+// Copyright 2022 Antrea Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package s3uploader
+
+import (
+	"io"
+
+	"antrea.io/antrea/pkg/flowaggregator/flowrecord"
+)
+
+const (
+	RecordFormatCSV     = "CSV"
+	RecordFormatJSON    = "JSON"
+	RecordFormatParquet = "Parquet"
+)
+
+// recordWriter serializes flow records into the content of a file.
+type recordWriter interface {
+	// writeRecord writes a record to the current file. Writers may buffer
+	// records until finish is called.
+	writeRecord(w io.Writer, r *flowrecord.Row)
+	// finish writes the data buffered for the current file, and resets the
+	// writer for the next file.
+	finish(w io.Writer)
+	// extension returns the extension of the files, without compression.
+	extension() string
+}
+
+func newRecordWriter(recordFormat string, compress bool) recordWriter {
+	switch recordFormat {
+	case RecordFormatJSON:
+		return &jsonWriter{}
+	case RecordFormatParquet:
+		return newParquetWriter(compress)
+	}
+	return &csvWriter{}
+}
+
+// csvWriter writes one line per record, with comma-separated values in the
+// order of columns.
+type csvWriter struct{}
+
+func (cw *csvWriter) writeRecord(w io.Writer, r *flowrecord.Row) {
+	w.Write(flowrecord.AppendCSV(make([]byte, 0, 512), r))
+}
+
+func (cw *csvWriter) finish(w io.Writer) {}
+
+func (cw *csvWriter) extension() string {
+	return "csv"
+}
+
+// jsonWriter writes one JSON object per line ("JSON lines"), keyed by column
+// names.
+type jsonWriter struct{}
+
+func (jw *jsonWriter) writeRecord(w io.Writer, r *flowrecord.Row) {
+	w.Write(flowrecord.AppendJSON(make([]byte, 0, 1024), r))
+}
+
+func (jw *jsonWriter) finish(w io.Writer) {}
+
+func (jw *jsonWriter) extension() string {
+	return "json"
+}
@@ -0,0 +1,64 @@
+// Copyright 2022 Antrea Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package s3uploader
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"antrea.io/antrea/pkg/flowaggregator/flowrecord"
+	flowrecordtesting "antrea.io/antrea/pkg/flowaggregator/flowrecord/testing"
+)
+
+func newTestRow() *flowrecord.Row {
+	return &flowrecord.Row{
+		FlowRecord:   flowrecordtesting.PrepareTestFlowRecord(),
+		ClusterUUID:  fakeClusterUUID,
+		TimeInserted: time.Unix(1637706980, 0),
+	}
+}
+
+func TestCSVWriter(t *testing.T) {
+	var buf bytes.Buffer
+	w := newRecordWriter(RecordFormatCSV, false)
+	w.writeRecord(&buf, newTestRow())
+	w.finish(&buf)
+	assert.Equal(t, recordStrIPv4+",1637706980,Pod,Pod,,,,http,"+flowrecordtesting.PrepareTestFlowRecord().HttpVals+",,0\n", buf.String())
+	assert.Equal(t, "csv", w.extension())
+}
+
+func TestJSONWriter(t *testing.T) {
+	var buf bytes.Buffer
+	w := newRecordWriter(RecordFormatJSON, false)
+	w.writeRecord(&buf, newTestRow())
+	w.writeRecord(&buf, newTestRow())
+	w.finish(&buf)
+	assert.Equal(t, "json", w.extension())
+
+	lines := strings.Split(strings.TrimSuffix(buf.String(), "\n"), "\n")
+	require.Len(t, lines, 2)
+	var fields map[string]interface{}
+	require.NoError(t, json.Unmarshal([]byte(lines[0]), &fields))
+	assert.Len(t, fields, len(flowrecord.Columns))
+	assert.Equal(t, "10.10.0.79", fields["sourceIP"])
+	assert.Equal(t, fakeClusterUUID, fields["clusterUUID"])
+	assert.Equal(t, float64(1637706980), fields["timeInserted"])
+}
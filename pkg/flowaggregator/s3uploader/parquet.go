@@ -0,0 +1,109 @@
+// Copyright 2022 Antrea Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package s3uploader
+
+import (
+	"fmt"
+	"io"
+
+	"github.com/xitongsys/parquet-go/parquet"
+	"github.com/xitongsys/parquet-go/writer"
+	"k8s.io/klog/v2"
+
+	"antrea.io/antrea/pkg/flowaggregator/flowrecord"
+)
+
+// parquetSchema is the schema of the Parquet files, with one required column
+// per flow record column, in the same order.
+var parquetSchema = func() []string {
+	schema := make([]string, 0, len(flowrecord.Columns))
+	for _, c := range flowrecord.Columns {
+		var columnType string
+		switch c.Kind {
+		case flowrecord.ColumnKindTimestamp:
+			columnType = "type=INT64, convertedtype=TIMESTAMP_MILLIS"
+		case flowrecord.ColumnKindInt32:
+			columnType = "type=INT32"
+		case flowrecord.ColumnKindInt64:
+			columnType = "type=INT64"
+		case flowrecord.ColumnKindString:
+			columnType = "type=BYTE_ARRAY, convertedtype=UTF8"
+		}
+		schema = append(schema, fmt.Sprintf("name=%s, %s, repetitiontype=REQUIRED", c.Name, columnType))
+	}
+	return schema
+}()
+
+// parquetWriter writes flow records as a Parquet file, using the parquet-go
+// library. Pages are optionally compressed with gzip. The library buffers the
+// records of a row group in memory, and the whole file fits in a single row
+// group with the default maximum number of records per file.
+type parquetWriter struct {
+	compress bool
+	// writer is the writer of the current file. It is created when the
+	// first record of the file is written.
+	writer *writer.CSVWriter
+}
+
+func newParquetWriter(compress bool) *parquetWriter {
+	return &parquetWriter{compress: compress}
+}
+
+func (pw *parquetWriter) writeRecord(w io.Writer, r *flowrecord.Row) {
+	if pw.writer == nil {
+		fileWriter, err := writer.NewCSVWriterFromWriter(parquetSchema, w, 1)
+		if err != nil {
+			klog.ErrorS(err, "Error when creating Parquet writer")
+			return
+		}
+		if pw.compress {
+			fileWriter.CompressionType = parquet.CompressionCodec_GZIP
+		} else {
+			fileWriter.CompressionType = parquet.CompressionCodec_UNCOMPRESSED
+		}
+		pw.writer = fileWriter
+	}
+	values := make([]interface{}, len(flowrecord.Columns))
+	for i := range flowrecord.Columns {
+		c := &flowrecord.Columns[i]
+		switch c.Kind {
+		case flowrecord.ColumnKindTimestamp:
+			values[i] = c.TimeValue(r).UnixMilli()
+		case flowrecord.ColumnKindInt32:
+			values[i] = int32(c.IntValue(r))
+		case flowrecord.ColumnKindInt64:
+			values[i] = c.IntValue(r)
+		case flowrecord.ColumnKindString:
+			values[i] = c.StringValue(r)
+		}
+	}
+	if err := pw.writer.Write(values); err != nil {
+		klog.ErrorS(err, "Error when writing record to Parquet file")
+	}
+}
+
+func (pw *parquetWriter) finish(w io.Writer) {
+	if pw.writer == nil {
+		return
+	}
+	if err := pw.writer.WriteStop(); err != nil {
+		klog.ErrorS(err, "Error when writing Parquet file footer")
+	}
+	pw.writer = nil
+}
+
+func (pw *parquetWriter) extension() string {
+	return "parquet"
+}
@@ -0,0 +1,84 @@
+// Copyright 2022 Antrea Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package s3uploader
+
+import (
+	"bytes"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/xitongsys/parquet-go-source/buffer"
+	"github.com/xitongsys/parquet-go/reader"
+
+	"antrea.io/antrea/pkg/flowaggregator/flowrecord"
+)
+
+// readParquetFile reads a file written by parquetWriter, and returns the
+// names of its columns and the values of each column.
+func readParquetFile(t *testing.T, data []byte) ([]string, [][]interface{}) {
+	file, err := buffer.NewBufferFile(data)
+	require.NoError(t, err)
+	pr, err := reader.NewParquetColumnReader(file, 1)
+	require.NoError(t, err)
+	defer pr.ReadStop()
+	numRows := pr.GetNumRows()
+	var names []string
+	var values [][]interface{}
+	// The first schema element is the root of the schema. The reader renames
+	// columns, so the names written in the file are the external names.
+	for i, info := range pr.SchemaHandler.Infos[1:] {
+		names = append(names, info.ExName)
+		columnValues, _, _, err := pr.ReadColumnByIndex(int64(i), numRows)
+		require.NoError(t, err)
+		values = append(values, columnValues)
+	}
+	return names, values
+}
+
+func TestParquetWriter(t *testing.T) {
+	for _, compress := range []bool{false, true} {
+		var buf bytes.Buffer
+		w := newRecordWriter(RecordFormatParquet, compress)
+		assert.Equal(t, "parquet", w.extension())
+		w.writeRecord(&buf, newTestRow())
+		r := newTestRow()
+		r.SourceIP = "10.10.0.81"
+		r.FlowStartSeconds = time.UnixMilli(1637706962500)
+		w.writeRecord(&buf, r)
+		w.finish(&buf)
+
+		names, values := readParquetFile(t, buf.Bytes())
+		require.Len(t, names, len(flowrecord.Columns))
+		for i, c := range flowrecord.Columns {
+			assert.Equal(t, c.Name, names[i])
+		}
+		assert.Equal(t, []interface{}{int64(1637706961000), int64(1637706962500)}, values[0])
+		assert.Equal(t, []interface{}{"10.10.0.79", "10.10.0.81"}, values[5])
+		assert.Equal(t, []interface{}{int32(5201), int32(5201)}, values[8])
+		assert.Equal(t, []interface{}{int64(30472817041), int64(30472817041)}, values[11])
+		assert.Equal(t, []interface{}{fakeClusterUUID, fakeClusterUUID}, values[len(values)-11])
+		assert.Equal(t, []interface{}{"Pod", "Pod"}, values[len(values)-8])
+		assert.Equal(t, []interface{}{"http", "http"}, values[len(values)-4])
+
+		// The writer is reset for the next file.
+		buf.Reset()
+		w.writeRecord(&buf, newTestRow())
+		w.finish(&buf)
+		_, values = readParquetFile(t, buf.Bytes())
+		assert.Equal(t, []interface{}{"10.10.0.79"}, values[5])
+	}
+}
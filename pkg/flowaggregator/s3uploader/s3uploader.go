@@ -21,14 +21,15 @@ import (
 	"fmt"
 	"io"
 	"math/rand"
+	"strings"
 	"sync"
+	"text/template"
 	"time"
 
 	"github.com/aws/aws-sdk-go-v2/aws"
 	awsconfig "github.com/aws/aws-sdk-go-v2/config"
 	s3manager "github.com/aws/aws-sdk-go-v2/feature/s3/manager"
 	"github.com/aws/aws-sdk-go-v2/service/s3"
-	ipfixentities "github.com/vmware/go-ipfix/pkg/entities"
 	"k8s.io/klog/v2"
 
 	config "antrea.io/antrea/pkg/config/flowaggregator"
@@ -48,11 +49,16 @@ type stopPayload struct {
 }
 
 type S3UploadProcess struct {
-	bucketName       string
-	bucketPrefix     string
-	region           string
+	bucketName   string
+	bucketPrefix string
+	region       string
+	recordFormat string
+	// compress enables gzip compression of whole files. For Parquet files, it
+	// is false and pages are compressed by the recordWriter instead.
 	compress         bool
 	maxRecordPerFile int32
+	// keyTemplate is used to generate the key of each file under bucketPrefix.
+	keyTemplate *template.Template
 	// uploadInterval is the interval between batch uploads
 	uploadInterval time.Duration
 	// uploadTicker is a ticker, containing a channel used to trigger batchUploadAll() for every uploadInterval period
@@ -70,11 +76,20 @@ type S3UploadProcess struct {
 	currentBuffer *bytes.Buffer
 	// cachedRecordCount keeps track of the number of flow records written into currentBuffer
 	cachedRecordCount int
+	// currentBufferStartTime is the time the first record was written into currentBuffer. It
+	// determines the time partition of the file.
+	currentBufferStartTime time.Time
+	// currentPartition is the key of the file, without the random part. A new file is started
+	// when it changes, so that each file belongs to a single time partition.
+	currentPartition string
+	// partitionCheckedAt is the last minute at which currentPartition was checked.
+	partitionCheckedAt time.Time
 	// bufferQueue caches currentBuffer when it is full
-	bufferQueue []*bytes.Buffer
+	bufferQueue []*uploadBuffer
 	// buffersToUpload stores all the buffers to be uploaded for the current uploadFile() call
-	buffersToUpload []*bytes.Buffer
+	buffersToUpload []*uploadBuffer
 	gzipWriter      *gzip.Writer
+	recordWriter    recordWriter
 	// awsS3Client is used to initialize awsS3Uploader
 	awsS3Client *s3.Client
 	// awsS3Uploader makes the real call to aws-sdk Upload() method to upload an object to S3
@@ -90,6 +105,48 @@ type S3Input struct {
 	UploadInterval time.Duration
 }
 
+// uploadBuffer is the content of a file to upload, with its key.
+type uploadBuffer struct {
+	*bytes.Buffer
+	key string
+}
+
+// keyTemplateData is the data available to the key template. Time fields are
+// zero-padded and in UTC.
+type keyTemplateData struct {
+	Year        string
+	Month       string
+	Day         string
+	Hour        string
+	Minute      string
+	ClusterUUID string
+	// Random is a random string which makes keys unique.
+	Random string
+	// Extension is the extension of the file, e.g. "csv.gz".
+	Extension string
+}
+
+// parseKeyTemplate parses the template used to generate the keys of the files,
+// and checks that it generates unique keys.
+func parseKeyTemplate(keyTemplate string) (*template.Template, error) {
+	tmpl, err := template.New("key").Option("missingkey=error").Parse(keyTemplate)
+	if err != nil {
+		return nil, fmt.Errorf("invalid key template: %w", err)
+	}
+	keys := make([]string, 2)
+	for i := range keys {
+		var b strings.Builder
+		if err := tmpl.Execute(&b, &keyTemplateData{Random: fmt.Sprintf("random%d", i)}); err != nil {
+			return nil, fmt.Errorf("invalid key template: %w", err)
+		}
+		keys[i] = b.String()
+	}
+	if keys[0] == keys[1] {
+		return nil, fmt.Errorf("invalid key template %q: it must include {{.Random}} to generate unique keys", keyTemplate)
+	}
+	return tmpl, nil
+}
+
 // Define a wrapper interface S3UploaderAPI to assist unit testing.
 type S3UploaderAPI interface {
 	Upload(ctx context.Context, input *s3.PutObjectInput, awsS3Uploader *s3manager.Uploader, opts ...func(*s3manager.Uploader)) (
@@ -133,6 +190,10 @@ func NewS3UploadProcess(input S3Input, clusterUUID string) (*S3UploadProcess, er
 	}
 	awsS3Client := s3.NewFromConfig(awsCfg)
 	awsS3Uploader := s3manager.NewUploader(awsS3Client)
+	keyTemplate, err := parseKeyTemplate(config.KeyTemplate)
+	if err != nil {
+		return nil, err
+	}
 
 	buf := &bytes.Buffer{}
 	// #nosec G404: random number generator not used for security purposes
@@ -142,13 +203,16 @@ func NewS3UploadProcess(input S3Input, clusterUUID string) (*S3UploadProcess, er
 		bucketName:       config.BucketName,
 		bucketPrefix:     config.BucketPrefix,
 		region:           region,
-		compress:         *config.Compress,
+		recordFormat:     config.RecordFormat,
+		compress:         *config.Compress && config.RecordFormat != RecordFormatParquet,
 		maxRecordPerFile: config.MaxRecordsPerFile,
+		keyTemplate:      keyTemplate,
 		uploadInterval:   input.UploadInterval,
 		currentBuffer:    buf,
-		bufferQueue:      make([]*bytes.Buffer, 0),
-		buffersToUpload:  make([]*bytes.Buffer, 0, maxNumBuffersPendingUpload),
+		bufferQueue:      make([]*uploadBuffer, 0),
+		buffersToUpload:  make([]*uploadBuffer, 0, maxNumBuffersPendingUpload),
 		gzipWriter:       gzip.NewWriter(buf),
+		recordWriter:     newRecordWriter(config.RecordFormat, *config.Compress),
 		awsS3Client:      awsS3Client,
 		awsS3Uploader:    awsS3Uploader,
 		s3UploaderAPI:    &S3Uploader{},
@@ -205,6 +269,30 @@ func (p *S3UploadProcess) UpdateS3Uploader(bucketName, bucketPrefix, region stri
 	return nil
 }
 
+// UpdateFileFormat changes the format and the keys of the files. The records
+// cached with the previous format are uploaded first.
+func (p *S3UploadProcess) UpdateFileFormat(recordFormat string, compress bool, maxRecordsPerFile int32, keyTemplate string) error {
+	tmpl, err := parseKeyTemplate(keyTemplate)
+	if err != nil {
+		return err
+	}
+	p.stopExportProcess(true)
+	defer p.startExportProcess()
+	p.queueMutex.Lock()
+	defer p.queueMutex.Unlock()
+	if p.cachedRecordCount != 0 {
+		p.appendBufferToQueue()
+	}
+	p.recordFormat = recordFormat
+	p.compress = compress && recordFormat != RecordFormatParquet
+	p.maxRecordPerFile = maxRecordsPerFile
+	p.keyTemplate = tmpl
+	p.recordWriter = newRecordWriter(recordFormat, compress)
+	p.gzipWriter.Reset(p.currentBuffer)
+	p.partitionCheckedAt = time.Time{}
+	return nil
+}
+
 func (p *S3UploadProcess) SetUploadInterval(uploadInterval time.Duration) {
 	p.mutex.Lock()
 	defer p.mutex.Unlock()
@@ -214,10 +302,12 @@ func (p *S3UploadProcess) SetUploadInterval(uploadInterval time.Duration) {
 	}
 }
 
-func (p *S3UploadProcess) CacheRecord(record ipfixentities.Record) {
-	r := flowrecord.GetFlowRecord(record)
+func (p *S3UploadProcess) CacheRecord(r *flowrecord.FlowRecord) {
 	p.queueMutex.Lock()
 	defer p.queueMutex.Unlock()
+	if p.cachedRecordCount != 0 && p.partitionChanged(time.Now()) {
+		p.appendBufferToQueue()
+	}
 	p.writeRecordToBuffer(r)
 	// If the number of pending records in the buffer reaches maxRecordPerFile,
 	// add the buffer to bufferQueue.
@@ -313,10 +403,9 @@ func (p *S3UploadProcess) batchUploadAll(ctx context.Context) error {
 
 	uploaded := 0
 	for _, buf := range p.buffersToUpload {
-		reader := bytes.NewReader(buf.Bytes())
-		err := p.uploadFile(ctx, reader)
+		err := p.uploadFile(ctx, buf)
 		if err != nil {
-			p.bufferQueue = p.bufferQueue[uploaded:]
+			p.buffersToUpload = p.buffersToUpload[uploaded:]
 			return err
 		}
 		uploaded += 1
@@ -326,29 +415,67 @@ func (p *S3UploadProcess) batchUploadAll(ctx context.Context) error {
 }
 
 func (p *S3UploadProcess) writeRecordToBuffer(record *flowrecord.FlowRecord) {
+	now := time.Now()
+	if p.cachedRecordCount == 0 {
+		p.currentBufferStartTime = now
+		p.currentPartition = p.generateKey(now, "")
+		p.partitionCheckedAt = now.Truncate(time.Minute)
+	}
 	var writer io.Writer
 	writer = p.currentBuffer
 	if p.compress {
 		writer = p.gzipWriter
 	}
-	writeRecord(writer, record, p.clusterUUID)
-	io.WriteString(writer, "\n")
+	p.recordWriter.writeRecord(writer, &flowrecord.Row{FlowRecord: record, ClusterUUID: p.clusterUUID, TimeInserted: now})
 	p.cachedRecordCount += 1
 }
 
-func (p *S3UploadProcess) uploadFile(ctx context.Context, reader *bytes.Reader) error {
-	fileName := fmt.Sprintf("records-%s.csv", randSeq(p.nameRand, 12))
+// partitionChanged returns whether the key of a file started at time t would
+// belong to a different partition than currentBuffer, e.g. because t is in a
+// different hour with an hourly partitioned key template. As keys have at most
+// a minute granularity, it is only checked once per minute. Caller of this
+// function should acquire queueMutex.
+func (p *S3UploadProcess) partitionChanged(t time.Time) bool {
+	minute := t.Truncate(time.Minute)
+	if minute.Equal(p.partitionCheckedAt) {
+		return false
+	}
+	p.partitionCheckedAt = minute
+	return p.generateKey(t, "") != p.currentPartition
+}
+
+// generateKey generates the key of a file started at time t, under
+// bucketPrefix.
+func (p *S3UploadProcess) generateKey(t time.Time, random string) string {
+	t = t.UTC()
+	extension := p.recordWriter.extension()
 	if p.compress {
-		fileName += ".gz"
+		extension += ".gz"
 	}
-	key := fileName
+	var b strings.Builder
+	// The template was validated by parseKeyTemplate, so it cannot fail.
+	p.keyTemplate.Execute(&b, &keyTemplateData{
+		Year:        fmt.Sprintf("%04d", t.Year()),
+		Month:       fmt.Sprintf("%02d", t.Month()),
+		Day:         fmt.Sprintf("%02d", t.Day()),
+		Hour:        fmt.Sprintf("%02d", t.Hour()),
+		Minute:      fmt.Sprintf("%02d", t.Minute()),
+		ClusterUUID: p.clusterUUID,
+		Random:      random,
+		Extension:   extension,
+	})
+	return b.String()
+}
+
+func (p *S3UploadProcess) uploadFile(ctx context.Context, buf *uploadBuffer) error {
+	key := buf.key
 	if p.bucketPrefix != "" {
-		key = fmt.Sprintf("%s/%s", p.bucketPrefix, fileName)
+		key = fmt.Sprintf("%s/%s", p.bucketPrefix, buf.key)
 	}
 	if _, err := p.s3UploaderAPI.Upload(ctx, &s3.PutObjectInput{
 		Bucket: aws.String(p.bucketName),
 		Key:    aws.String(key),
-		Body:   reader,
+		Body:   bytes.NewReader(buf.Bytes()),
 	}, p.awsS3Uploader); err != nil {
 		return fmt.Errorf("error when uploading file to S3: %v", err)
 	}
@@ -358,7 +485,11 @@ func (p *S3UploadProcess) uploadFile(ctx context.Context, reader *bytes.Reader)
 // appendBufferToQueue appends currentBuffer to bufferQueue, and reset
 // currentBuffer. Caller of this function should acquire queueMutex.
 func (p *S3UploadProcess) appendBufferToQueue() {
-	p.bufferQueue = append(p.bufferQueue, p.currentBuffer)
+	p.recordWriter.finish(p.currentBuffer)
+	p.bufferQueue = append(p.bufferQueue, &uploadBuffer{
+		Buffer: p.currentBuffer,
+		key:    p.generateKey(p.currentBufferStartTime, randSeq(p.nameRand, 12)),
+	})
 	newBuffer := &bytes.Buffer{}
 	// avoid too many memory allocations
 	newBuffer.Grow(p.currentBuffer.Cap())
@@ -379,103 +510,3 @@ func randSeq(randSrc *rand.Rand, n int) string {
 	}
 	return string(b)
 }
-
-func writeRecord(w io.Writer, r *flowrecord.FlowRecord, clusterUUID string) {
-	io.WriteString(w, fmt.Sprintf("%d", r.FlowStartSeconds.Unix()))
-	io.WriteString(w, ",")
-	io.WriteString(w, fmt.Sprintf("%d", r.FlowEndSeconds.Unix()))
-	io.WriteString(w, ",")
-	io.WriteString(w, fmt.Sprintf("%d", r.FlowEndSecondsFromSourceNode.Unix()))
-	io.WriteString(w, ",")
-	io.WriteString(w, fmt.Sprintf("%d", r.FlowEndSecondsFromDestinationNode.Unix()))
-	io.WriteString(w, ",")
-	io.WriteString(w, fmt.Sprintf("%d", r.FlowEndReason))
-	io.WriteString(w, ",")
-	io.WriteString(w, r.SourceIP)
-	io.WriteString(w, ",")
-	io.WriteString(w, r.DestinationIP)
-	io.WriteString(w, ",")
-	io.WriteString(w, fmt.Sprintf("%d", r.SourceTransportPort))
-	io.WriteString(w, ",")
-	io.WriteString(w, fmt.Sprintf("%d", r.DestinationTransportPort))
-	io.WriteString(w, ",")
-	io.WriteString(w, fmt.Sprintf("%d", r.ProtocolIdentifier))
-	io.WriteString(w, ",")
-	io.WriteString(w, fmt.Sprintf("%d", r.PacketTotalCount))
-	io.WriteString(w, ",")
-	io.WriteString(w, fmt.Sprintf("%d", r.OctetTotalCount))
-	io.WriteString(w, ",")
-	io.WriteString(w, fmt.Sprintf("%d", r.PacketDeltaCount))
-	io.WriteString(w, ",")
-	io.WriteString(w, fmt.Sprintf("%d", r.OctetDeltaCount))
-	io.WriteString(w, ",")
-	io.WriteString(w, fmt.Sprintf("%d", r.ReversePacketTotalCount))
-	io.WriteString(w, ",")
-	io.WriteString(w, fmt.Sprintf("%d", r.ReverseOctetTotalCount))
-	io.WriteString(w, ",")
-	io.WriteString(w, fmt.Sprintf("%d", r.ReversePacketDeltaCount))
-	io.WriteString(w, ",")
-	io.WriteString(w, fmt.Sprintf("%d", r.ReverseOctetDeltaCount))
-	io.WriteString(w, ",")
-	io.WriteString(w, r.SourcePodName)
-	io.WriteString(w, ",")
-	io.WriteString(w, r.SourcePodNamespace)
-	io.WriteString(w, ",")
-	io.WriteString(w, r.SourceNodeName)
-	io.WriteString(w, ",")
-	io.WriteString(w, r.DestinationPodName)
-	io.WriteString(w, ",")
-	io.WriteString(w, r.DestinationPodNamespace)
-	io.WriteString(w, ",")
-	io.WriteString(w, r.DestinationNodeName)
-	io.WriteString(w, ",")
-	io.WriteString(w, r.DestinationClusterIP)
-	io.WriteString(w, ",")
-	io.WriteString(w, fmt.Sprintf("%d", r.DestinationServicePort))
-	io.WriteString(w, ",")
-	io.WriteString(w, r.DestinationServicePortName)
-	io.WriteString(w, ",")
-	io.WriteString(w, r.IngressNetworkPolicyName)
-	io.WriteString(w, ",")
-	io.WriteString(w, r.IngressNetworkPolicyNamespace)
-	io.WriteString(w, ",")
-	io.WriteString(w, r.IngressNetworkPolicyRuleName)
-	io.WriteString(w, ",")
-	io.WriteString(w, fmt.Sprintf("%d", r.IngressNetworkPolicyRuleAction))
-	io.WriteString(w, ",")
-	io.WriteString(w, fmt.Sprintf("%d", r.IngressNetworkPolicyType))
-	io.WriteString(w, ",")
-	io.WriteString(w, r.EgressNetworkPolicyName)
-	io.WriteString(w, ",")
-	io.WriteString(w, r.EgressNetworkPolicyNamespace)
-	io.WriteString(w, ",")
-	io.WriteString(w, r.EgressNetworkPolicyRuleName)
-	io.WriteString(w, ",")
-	io.WriteString(w, fmt.Sprintf("%d", r.EgressNetworkPolicyRuleAction))
-	io.WriteString(w, ",")
-	io.WriteString(w, fmt.Sprintf("%d", r.EgressNetworkPolicyType))
-	io.WriteString(w, ",")
-	io.WriteString(w, r.TcpState)
-	io.WriteString(w, ",")
-	io.WriteString(w, fmt.Sprintf("%d", r.FlowType))
-	io.WriteString(w, ",")
-	io.WriteString(w, r.SourcePodLabels)
-	io.WriteString(w, ",")
-	io.WriteString(w, r.DestinationPodLabels)
-	io.WriteString(w, ",")
-	io.WriteString(w, fmt.Sprintf("%d", r.Throughput))
-	io.WriteString(w, ",")
-	io.WriteString(w, fmt.Sprintf("%d", r.ReverseThroughput))
-	io.WriteString(w, ",")
-	io.WriteString(w, fmt.Sprintf("%d", r.ThroughputFromSourceNode))
-	io.WriteString(w, ",")
-	io.WriteString(w, fmt.Sprintf("%d", r.ThroughputFromDestinationNode))
-	io.WriteString(w, ",")
-	io.WriteString(w, fmt.Sprintf("%d", r.ReverseThroughputFromSourceNode))
-	io.WriteString(w, ",")
-	io.WriteString(w, fmt.Sprintf("%d", r.ReverseThroughputFromDestinationNode))
-	io.WriteString(w, ",")
-	io.WriteString(w, clusterUUID)
-	io.WriteString(w, ",")
-	io.WriteString(w, fmt.Sprintf("%d", time.Now().Unix()))
-}
@@ -16,24 +16,30 @@ package s3uploader
 
 import (
 	"bytes"
+	"compress/gzip"
 	"context"
+	"fmt"
 	"math/rand"
 	"sync"
 	"testing"
+	"text/template"
 	"time"
 
 	"github.com/aws/aws-sdk-go-v2/config"
 	s3manager "github.com/aws/aws-sdk-go-v2/feature/s3/manager"
 	"github.com/aws/aws-sdk-go-v2/service/s3"
-	"github.com/golang/mock/gomock"
 	"github.com/google/uuid"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 	ipfixentitiestesting "github.com/vmware/go-ipfix/pkg/entities/testing"
-	"github.com/vmware/go-ipfix/pkg/registry"
+	"go.uber.org/mock/gomock"
 	"k8s.io/apimachinery/pkg/util/wait"
 
+	flowaggregatorconfig "antrea.io/antrea/pkg/config/flowaggregator"
+	"antrea.io/antrea/pkg/flowaggregator/flowrecord"
 	flowrecordtesting "antrea.io/antrea/pkg/flowaggregator/flowrecord/testing"
 	flowaggregatortesting "antrea.io/antrea/pkg/flowaggregator/testing"
+	"antrea.io/antrea/pkg/ipfix"
 )
 
 var (
@@ -44,20 +50,24 @@ var (
 
 const seed = 1
 
+var testKeyTemplate = template.Must(parseKeyTemplate(flowaggregatorconfig.DefaultS3KeyTemplate))
+
 type mockS3Uploader struct {
 	testReader      *bytes.Buffer
 	testReaderMutex sync.Mutex
+	keys            []string
 }
 
 func (m *mockS3Uploader) Upload(ctx context.Context, input *s3.PutObjectInput, awsS3Uploader *s3manager.Uploader, opts ...func(*s3manager.Uploader)) (*s3manager.UploadOutput, error) {
 	m.testReaderMutex.Lock()
 	defer m.testReaderMutex.Unlock()
 	m.testReader.ReadFrom(input.Body)
+	m.keys = append(m.keys, *input.Key)
 	return nil, nil
 }
 
 func init() {
-	registry.LoadRegistry()
+	ipfix.LoadRegistry()
 }
 
 func TestUpdateS3Uploader(t *testing.T) {
@@ -81,25 +91,30 @@ func TestUpdateS3Uploader(t *testing.T) {
 func TestCacheRecord(t *testing.T) {
 	ctrl := gomock.NewController(t)
 	defer ctrl.Finish()
+	// #nosec G404: random number generator not used for security purposes
+	nameRand := rand.New(rand.NewSource(seed))
 	s3UploadProc := S3UploadProcess{
 		compress:         false,
 		maxRecordPerFile: 2,
 		currentBuffer:    &bytes.Buffer{},
-		bufferQueue:      make([]*bytes.Buffer, 0, maxNumBuffersPendingUpload),
+		recordWriter:     &csvWriter{},
+		keyTemplate:      testKeyTemplate,
+		bufferQueue:      make([]*uploadBuffer, 0, maxNumBuffersPendingUpload),
+		nameRand:         nameRand,
 		clusterUUID:      fakeClusterUUID,
 	}
 
 	// First call, cache the record in currentBuffer.
 	mockRecord := ipfixentitiestesting.NewMockRecord(ctrl)
 	flowaggregatortesting.PrepareMockIpfixRecord(mockRecord, true)
-	s3UploadProc.CacheRecord(mockRecord)
+	s3UploadProc.CacheRecord(flowrecord.GetFlowRecord(mockRecord))
 	assert.Equal(t, 1, s3UploadProc.cachedRecordCount)
 	assert.Contains(t, s3UploadProc.currentBuffer.String(), recordStrIPv4)
 
 	// Second call, reach currentBuffer max size, add the currentBuffer to bufferQueue.
 	mockRecord = ipfixentitiestesting.NewMockRecord(ctrl)
 	flowaggregatortesting.PrepareMockIpfixRecord(mockRecord, false)
-	s3UploadProc.CacheRecord(mockRecord)
+	s3UploadProc.CacheRecord(flowrecord.GetFlowRecord(mockRecord))
 	assert.Equal(t, 1, len(s3UploadProc.bufferQueue))
 	buf := s3UploadProc.bufferQueue[0]
 	assert.Contains(t, buf.String(), recordStrIPv6)
@@ -115,8 +130,10 @@ func TestBatchUploadAll(t *testing.T) {
 		compress:         false,
 		maxRecordPerFile: 10,
 		currentBuffer:    &bytes.Buffer{},
-		bufferQueue:      make([]*bytes.Buffer, 0),
-		buffersToUpload:  make([]*bytes.Buffer, 0, maxNumBuffersPendingUpload),
+		recordWriter:     &csvWriter{},
+		keyTemplate:      testKeyTemplate,
+		bufferQueue:      make([]*uploadBuffer, 0),
+		buffersToUpload:  make([]*uploadBuffer, 0, maxNumBuffersPendingUpload),
 		s3UploaderAPI:    mockS3Uploader,
 		nameRand:         nameRand,
 		clusterUUID:      fakeClusterUUID,
@@ -142,8 +159,10 @@ func TestBatchUploadAllError(t *testing.T) {
 		compress:         false,
 		maxRecordPerFile: 10,
 		currentBuffer:    &bytes.Buffer{},
-		bufferQueue:      make([]*bytes.Buffer, 0),
-		buffersToUpload:  make([]*bytes.Buffer, 0, maxNumBuffersPendingUpload),
+		recordWriter:     &csvWriter{},
+		keyTemplate:      testKeyTemplate,
+		bufferQueue:      make([]*uploadBuffer, 0),
+		buffersToUpload:  make([]*uploadBuffer, 0, maxNumBuffersPendingUpload),
 		s3UploaderAPI:    s3uploader,
 		nameRand:         nameRand,
 	}
@@ -174,8 +193,10 @@ func TestFlowRecordPeriodicCommit(t *testing.T) {
 		maxRecordPerFile: 10,
 		uploadInterval:   100 * time.Millisecond,
 		currentBuffer:    &bytes.Buffer{},
-		bufferQueue:      make([]*bytes.Buffer, 0),
-		buffersToUpload:  make([]*bytes.Buffer, 0, maxNumBuffersPendingUpload),
+		recordWriter:     &csvWriter{},
+		keyTemplate:      testKeyTemplate,
+		bufferQueue:      make([]*uploadBuffer, 0),
+		buffersToUpload:  make([]*uploadBuffer, 0, maxNumBuffersPendingUpload),
 		s3UploaderAPI:    mockS3Uploader,
 		nameRand:         nameRand,
 		clusterUUID:      fakeClusterUUID,
@@ -211,8 +232,10 @@ func TestFlushCacheOnStop(t *testing.T) {
 		maxRecordPerFile: 10,
 		uploadInterval:   100 * time.Second,
 		currentBuffer:    &bytes.Buffer{},
-		bufferQueue:      make([]*bytes.Buffer, 0),
-		buffersToUpload:  make([]*bytes.Buffer, 0, maxNumBuffersPendingUpload),
+		recordWriter:     &csvWriter{},
+		keyTemplate:      testKeyTemplate,
+		bufferQueue:      make([]*uploadBuffer, 0),
+		buffersToUpload:  make([]*uploadBuffer, 0, maxNumBuffersPendingUpload),
 		s3UploaderAPI:    mockS3Uploader,
 		nameRand:         nameRand,
 		clusterUUID:      fakeClusterUUID,
@@ -228,3 +251,123 @@ func TestFlushCacheOnStop(t *testing.T) {
 	assert.Equal(t, 0, s3UploadProc.cachedRecordCount)
 	assert.Contains(t, mockS3Uploader.testReader.String(), recordStrIPv4)
 }
+
+func TestParseKeyTemplate(t *testing.T) {
+	for _, tc := range []struct {
+		keyTemplate   string
+		expectedError string
+	}{
+		{keyTemplate: "records-{{.Random}}.{{.Extension}}"},
+		{keyTemplate: "year={{.Year}}/month={{.Month}}/day={{.Day}}/hour={{.Hour}}/{{.ClusterUUID}}-{{.Random}}.{{.Extension}}"},
+		{keyTemplate: "records-{{.Random}.{{.Extension}}", expectedError: "invalid key template"},
+		{keyTemplate: "records-{{.Unknown}}-{{.Random}}", expectedError: "invalid key template"},
+		{keyTemplate: "year={{.Year}}/records.{{.Extension}}", expectedError: "it must include {{.Random}}"},
+	} {
+		_, err := parseKeyTemplate(tc.keyTemplate)
+		if tc.expectedError == "" {
+			assert.NoError(t, err, tc.keyTemplate)
+		} else {
+			assert.ErrorContains(t, err, tc.expectedError, tc.keyTemplate)
+		}
+	}
+}
+
+func TestGenerateKey(t *testing.T) {
+	keyTemplate, err := parseKeyTemplate("year={{.Year}}/month={{.Month}}/day={{.Day}}/hour={{.Hour}}/minute={{.Minute}}/{{.ClusterUUID}}-{{.Random}}.{{.Extension}}")
+	require.NoError(t, err)
+	s3UploadProc := S3UploadProcess{
+		compress:     true,
+		keyTemplate:  keyTemplate,
+		recordWriter: &jsonWriter{},
+		clusterUUID:  fakeClusterUUID,
+	}
+	fileTime := time.Date(2022, 3, 4, 5, 6, 7, 0, time.FixedZone("UTC-8", -8*3600))
+	assert.Equal(t, "year=2022/month=03/day=04/hour=13/minute=06/"+fakeClusterUUID+"-abc.json.gz", s3UploadProc.generateKey(fileTime, "abc"))
+}
+
+func TestCacheRecordPartitionChanged(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	keyTemplate, err := parseKeyTemplate("hour={{.Hour}}/records-{{.Random}}.{{.Extension}}")
+	require.NoError(t, err)
+	// #nosec G404: random number generator not used for security purposes
+	nameRand := rand.New(rand.NewSource(seed))
+	s3UploadProc := S3UploadProcess{
+		maxRecordPerFile: 10,
+		currentBuffer:    &bytes.Buffer{},
+		recordWriter:     &csvWriter{},
+		keyTemplate:      keyTemplate,
+		bufferQueue:      make([]*uploadBuffer, 0, maxNumBuffersPendingUpload),
+		nameRand:         nameRand,
+		clusterUUID:      fakeClusterUUID,
+	}
+	mockRecord := ipfixentitiestesting.NewMockRecord(ctrl)
+	flowaggregatortesting.PrepareMockIpfixRecord(mockRecord, true)
+	s3UploadProc.CacheRecord(flowrecord.GetFlowRecord(mockRecord))
+	assert.Equal(t, 1, s3UploadProc.cachedRecordCount)
+
+	// Simulate a file started during the previous hour.
+	startTime := time.Now().Add(-time.Hour)
+	s3UploadProc.currentBufferStartTime = startTime
+	s3UploadProc.currentPartition = s3UploadProc.generateKey(startTime, "")
+	s3UploadProc.partitionCheckedAt = startTime.Truncate(time.Minute)
+	mockRecord = ipfixentitiestesting.NewMockRecord(ctrl)
+	flowaggregatortesting.PrepareMockIpfixRecord(mockRecord, false)
+	s3UploadProc.CacheRecord(flowrecord.GetFlowRecord(mockRecord))
+
+	// The file of the previous hour is queued, and the new record is written
+	// to a new file.
+	require.Equal(t, 1, len(s3UploadProc.bufferQueue))
+	assert.Contains(t, s3UploadProc.bufferQueue[0].String(), recordStrIPv4)
+	assert.Regexp(t, fmt.Sprintf("^hour=%02d/records-[a-z0-9]{12}\\.csv$", startTime.UTC().Hour()), s3UploadProc.bufferQueue[0].key)
+	assert.Equal(t, 1, s3UploadProc.cachedRecordCount)
+	assert.Contains(t, s3UploadProc.currentBuffer.String(), recordStrIPv6)
+}
+
+func TestUpdateFileFormat(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	mockS3Uploader := &mockS3Uploader{testReader: &bytes.Buffer{}}
+	// #nosec G404: random number generator not used for security purposes
+	nameRand := rand.New(rand.NewSource(seed))
+	buf := &bytes.Buffer{}
+	s3UploadProc := S3UploadProcess{
+		bucketPrefix:     "flows",
+		recordFormat:     RecordFormatCSV,
+		maxRecordPerFile: 10,
+		uploadInterval:   100 * time.Second,
+		currentBuffer:    buf,
+		gzipWriter:       gzip.NewWriter(buf),
+		recordWriter:     &csvWriter{},
+		keyTemplate:      testKeyTemplate,
+		bufferQueue:      make([]*uploadBuffer, 0),
+		buffersToUpload:  make([]*uploadBuffer, 0, maxNumBuffersPendingUpload),
+		s3UploaderAPI:    mockS3Uploader,
+		nameRand:         nameRand,
+		clusterUUID:      fakeClusterUUID,
+	}
+	s3UploadProc.startExportProcess()
+	mockRecord := ipfixentitiestesting.NewMockRecord(ctrl)
+	flowaggregatortesting.PrepareMockIpfixRecord(mockRecord, true)
+	s3UploadProc.CacheRecord(flowrecord.GetFlowRecord(mockRecord))
+
+	assert.Error(t, s3UploadProc.UpdateFileFormat(RecordFormatParquet, true, 100, "records.{{.Extension}}"))
+	require.NoError(t, s3UploadProc.UpdateFileFormat(RecordFormatParquet, true, 100, "{{.Year}}/records-{{.Random}}.{{.Extension}}"))
+	// Records cached with the previous format are uploaded first.
+	require.Len(t, mockS3Uploader.keys, 1)
+	assert.Regexp(t, "^flows/records-[a-z0-9]{12}\\.csv$", mockS3Uploader.keys[0])
+	assert.Contains(t, mockS3Uploader.testReader.String(), recordStrIPv4)
+	assert.False(t, s3UploadProc.compress)
+	assert.Equal(t, int32(100), s3UploadProc.maxRecordPerFile)
+
+	mockRecord = ipfixentitiestesting.NewMockRecord(ctrl)
+	flowaggregatortesting.PrepareMockIpfixRecord(mockRecord, true)
+	s3UploadProc.CacheRecord(flowrecord.GetFlowRecord(mockRecord))
+	mockS3Uploader.testReader.Reset()
+	s3UploadProc.stopExportProcess(true)
+	require.Len(t, mockS3Uploader.keys, 2)
+	assert.Regexp(t, fmt.Sprintf("^flows/%d/records-[a-z0-9]{12}\\.parquet$", time.Now().UTC().Year()), mockS3Uploader.keys[1])
+	names, values := readParquetFile(t, mockS3Uploader.testReader.Bytes())
+	assert.Equal(t, "sourceIP", names[5])
+	assert.Equal(t, []interface{}{"10.10.0.79"}, values[5])
+}
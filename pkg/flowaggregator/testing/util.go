@@ -207,6 +207,22 @@ func PrepareMockIpfixRecord(mockRecord *ipfixentitiestesting.MockRecord, isIPv4
 	reverseThroughputFromDestinationNodeElem.SetUnsigned64Value(uint64(12381346))
 	mockRecord.EXPECT().GetInfoElementWithValue("reverseThroughputFromDestinationNode").Return(reverseThroughputFromDestinationNodeElem, 0, true)
 
+	appProtocolNameElem := createElement("appProtocolName", ipfixregistry.AntreaEnterpriseID)
+	appProtocolNameElem.SetStringValue("http")
+	mockRecord.EXPECT().GetInfoElementWithValue("appProtocolName").Return(appProtocolNameElem, 0, true)
+
+	httpValsElem := createElement("httpVals", ipfixregistry.AntreaEnterpriseID)
+	httpValsElem.SetStringValue(`{"0":"{\"hostname\":\"perftest-b\",\"url\":\"/\",\"http_method\":\"GET\",\"protocol\":\"HTTP/1.1\",\"status\":200}"}`)
+	mockRecord.EXPECT().GetInfoElementWithValue("httpVals").Return(httpValsElem, 0, true)
+
+	dnsQueryNameElem := createElement("dnsQueryName", ipfixregistry.AntreaEnterpriseID)
+	dnsQueryNameElem.SetStringValue("")
+	mockRecord.EXPECT().GetInfoElementWithValue("dnsQueryName").Return(dnsQueryNameElem, 0, true)
+
+	dnsResponseCodeElem := createElement("dnsResponseCode", ipfixregistry.AntreaEnterpriseID)
+	dnsResponseCodeElem.SetUnsigned8Value(uint8(0))
+	mockRecord.EXPECT().GetInfoElementWithValue("dnsResponseCode").Return(dnsResponseCodeElem, 0, true)
+
 	if isIPv4 {
 		sourceIPv4Elem := createElement("sourceIPv4Address", ipfixregistry.IANAEnterpriseID)
 		sourceIPv4Elem.SetIPAddressValue(net.ParseIP("10.10.0.79"))
@@ -16,6 +16,7 @@ package graphviz
 
 import (
 	"fmt"
+	"sort"
 	"strconv"
 	"strings"
 
@@ -39,6 +40,11 @@ const (
 var (
 	clusterSrcName = "cluster_source"
 	clusterDstName = "cluster_destination"
+	// Clusters of the reply packet of bidirectional Traceflow. The reply
+	// source is the destination of the Traceflow, and the reply destination
+	// is the source of the Traceflow.
+	clusterReplySrcName = "cluster_reply_source"
+	clusterReplyDstName = "cluster_reply_destination"
 )
 
 // createNodeWithDefaultStyle creates a node with default style (usually used to represent a component in traceflow) .
@@ -68,6 +74,24 @@ func createEndpointNodeWithDefaultStyle(graph *gographviz.Graph, parentGraph str
 	return graph.Nodes.Lookup[name], nil
 }
 
+// createUniqueEndpointNode creates an endpoint node with default style. The
+// endpoints of the reply packet of bidirectional Traceflow are also drawn for the
+// request packet, so if a node already exists for the endpoint, a node with
+// another name is created and labeled with the endpoint.
+func createUniqueEndpointNode(graph *gographviz.Graph, parentGraph string, name string) (*gographviz.Node, error) {
+	if _, ok := graph.Nodes.Lookup[name]; !ok {
+		return createEndpointNodeWithDefaultStyle(graph, parentGraph, name)
+	}
+	node, err := createEndpointNodeWithDefaultStyle(graph, parentGraph, fmt.Sprintf("%s_endpoint_%d", parentGraph, len(graph.Nodes.Nodes)))
+	if err != nil {
+		return nil, err
+	}
+	if len(name) > 0 {
+		node.Attrs[gographviz.Label] = name
+	}
+	return node, nil
+}
+
 // createDirectedEdgeWithDefaultStyle creates a directed edge with default style.
 // It is allowed to create duplicate edges.
 func createDirectedEdgeWithDefaultStyle(graph *gographviz.Graph, start *gographviz.Node, end *gographviz.Node, isForwardDir bool) (*gographviz.Edge, error) {
@@ -136,9 +160,9 @@ func getWrappedStr(str string) string {
 	return `"` + wStr + `"`
 }
 
-func getNodeResult(tf *crdv1alpha1.Traceflow, fn func(result *crdv1alpha1.NodeResult) bool) *crdv1alpha1.NodeResult {
-	for i := range tf.Status.Results {
-		result := tf.Status.Results[i]
+func getNodeResult(results []crdv1alpha1.NodeResult, fn func(result *crdv1alpha1.NodeResult) bool) *crdv1alpha1.NodeResult {
+	for i := range results {
+		result := results[i]
 		if fn(&result) {
 			return &result
 		}
@@ -150,6 +174,12 @@ func getSrcNodeName(tf *crdv1alpha1.Traceflow) string {
 	if len(tf.Spec.Source.Namespace) > 0 && len(tf.Spec.Source.Pod) > 0 {
 		return getWrappedStr(tf.Spec.Source.Namespace + "/" + tf.Spec.Source.Pod)
 	}
+	if len(tf.Spec.Source.Node) > 0 {
+		if len(tf.Spec.Source.IP) > 0 {
+			return getWrappedStr(tf.Spec.Source.IP)
+		}
+		return getWrappedStr("Node: " + tf.Spec.Source.Node)
+	}
 	if tf.Spec.LiveTraffic {
 		if len(tf.Spec.Source.IP) > 0 {
 			return getWrappedStr(tf.Spec.Source.IP)
@@ -235,13 +265,39 @@ func genOutput(graph *gographviz.Graph, isSingleCluster bool) string {
 	if isSingleCluster {
 		return str
 	}
-	// Swap source and destination cluster if destination cluster appears before source cluster.
-	srcStartIdx, srcEndIdx := findClusterString(str, clusterSrcName)
-	dstStartIdx, dstEndIdx := findClusterString(str, clusterDstName)
-	if dstEndIdx <= srcStartIdx {
-		return str[:dstStartIdx] + str[srcStartIdx:srcEndIdx] + str[dstEndIdx:srcStartIdx] + str[dstStartIdx:dstEndIdx] + str[srcEndIdx:]
+	// Source cluster should appear before destination cluster, and the clusters of the reply packet after them.
+	return orderClusters(graph, str, clusterSrcName, clusterDstName, clusterReplySrcName, clusterReplyDstName)
+}
+
+// orderClusters reorders the existing clusters in the graph string according to the provided names, as Graphviz
+// tends to draw clusters from left to right in the order in which they appear.
+func orderClusters(graph *gographviz.Graph, graphStr string, names ...string) string {
+	type clusterString struct {
+		startIndex int
+		endIndex   int
+	}
+	var positions []clusterString
+	var clusters []string
+	for _, name := range names {
+		if !graph.IsSubGraph(name) {
+			continue
+		}
+		startIndex, endIndex := findClusterString(graphStr, name)
+		positions = append(positions, clusterString{startIndex, endIndex})
+		clusters = append(clusters, graphStr[startIndex:endIndex])
 	}
-	return str
+	sort.Slice(positions, func(i, j int) bool {
+		return positions[i].startIndex < positions[j].startIndex
+	})
+	var b strings.Builder
+	lastIndex := 0
+	for i, position := range positions {
+		b.WriteString(graphStr[lastIndex:position.startIndex])
+		b.WriteString(clusters[i])
+		lastIndex = position.endIndex
+	}
+	b.WriteString(graphStr[lastIndex:])
+	return b.String()
 }
 
 func getTraceflowStatusMessage(tf *crdv1alpha1.Traceflow) string {
@@ -272,7 +328,7 @@ func genSubGraph(graph *gographviz.Graph, cluster *gographviz.SubGraph, result *
 	}
 
 	// Construct the first node. Show it only if we know the name of it.
-	node, err := createEndpointNodeWithDefaultStyle(graph, cluster.Name, endpointNodeName)
+	node, err := createUniqueEndpointNode(graph, cluster.Name, endpointNodeName)
 	if err != nil {
 		return nil, err
 	}
@@ -346,8 +402,8 @@ func GenGraph(tf *crdv1alpha1.Traceflow) (string, error) {
 		return "", err
 	}
 
-	senderRst := getNodeResult(tf, isSender)
-	receiverRst := getNodeResult(tf, isReceiver)
+	senderRst := getNodeResult(tf.Status.Results, isSender)
+	receiverRst := getNodeResult(tf.Status.Results, isReceiver)
 	if tf.Status.Phase != crdv1alpha1.Succeeded {
 		graph.Attrs[gographviz.Label] = getTraceflowStatusMessage(tf)
 	}
@@ -419,31 +475,8 @@ func GenGraph(tf *crdv1alpha1.Traceflow) (string, error) {
 		if len(nodes) == 0 {
 			return genOutput(graph, true), nil
 		}
-		switch senderRst.Observations[len(senderRst.Observations)-1].Action {
-		// If the last action of the sender is FORWARDED,
-		// then the packet has been sent out by sender, implying that there is a disconnection.
-		case crdv1alpha1.ActionForwarded:
-			lastNode, err := createEndpointNodeWithDefaultStyle(graph, graph.Name, getDstNodeName(tf))
-			if err != nil {
-				return "", err
-			}
-			err = graph.AddEdge(nodes[len(nodes)-1].Name, lastNode.Name, true, map[string]string{
-				"penwidth": "2.0",
-				"color":    darkRed,
-				"style":    `"dashed"`,
-			})
-			if err != nil {
-				return "", err
-			}
-		case crdv1alpha1.ActionDelivered:
-			lastNode, err := createEndpointNodeWithDefaultStyle(graph, cluster1.Name, getDstNodeName(tf))
-			if err != nil {
-				return "", err
-			}
-			_, err = createDirectedEdgeWithDefaultStyle(graph, nodes[len(nodes)-1], lastNode, true)
-			if err != nil {
-				return "", err
-			}
+		if err := genSingleNodeDestination(graph, cluster1, nodes, senderRst, getDstNodeName(tf)); err != nil {
+			return "", err
 		}
 		if tf.Spec.LiveTraffic && tf.Status.Phase == crdv1alpha1.Succeeded {
 			err = createCapturedPacketNode(graph, cluster1.Name, tf)
@@ -451,7 +484,10 @@ func GenGraph(tf *crdv1alpha1.Traceflow) (string, error) {
 				return "", err
 			}
 		}
-		return genOutput(graph, true), nil
+		if err := genReplyGraph(graph, tf); err != nil {
+			return "", err
+		}
+		return genOutput(graph, len(tf.Status.ReplyResults) == 0), nil
 	}
 
 	// Make the graph centered by balancing the difference of node numbers on two sides with the length of first edge.
@@ -492,9 +528,100 @@ func GenGraph(tf *crdv1alpha1.Traceflow) (string, error) {
 			return "", err
 		}
 	}
+	if err := genReplyGraph(graph, tf); err != nil {
+		return "", err
+	}
 	return genOutput(graph, false), nil
 }
 
+// genSingleNodeDestination draws the destination of a packet which is only
+// observed on the Node of the sender, after the nodes of the sender.
+func genSingleNodeDestination(graph *gographviz.Graph, cluster *gographviz.SubGraph, nodes []*gographviz.Node, result *crdv1alpha1.NodeResult, dstNodeName string) error {
+	switch result.Observations[len(result.Observations)-1].Action {
+	// If the last action of the sender is FORWARDED,
+	// then the packet has been sent out by sender, implying that there is a disconnection.
+	case crdv1alpha1.ActionForwarded:
+		lastNode, err := createUniqueEndpointNode(graph, graph.Name, dstNodeName)
+		if err != nil {
+			return err
+		}
+		err = graph.AddEdge(nodes[len(nodes)-1].Name, lastNode.Name, true, map[string]string{
+			"penwidth": "2.0",
+			"color":    darkRed,
+			"style":    `"dashed"`,
+		})
+		if err != nil {
+			return err
+		}
+	case crdv1alpha1.ActionDelivered:
+		lastNode, err := createUniqueEndpointNode(graph, cluster.Name, dstNodeName)
+		if err != nil {
+			return err
+		}
+		_, err = createDirectedEdgeWithDefaultStyle(graph, nodes[len(nodes)-1], lastNode, true)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// genReplyGraph draws the observations of the reply packet of bidirectional
+// Traceflow, in their own clusters next to the ones of the request packet. The
+// reply packet is sent by the destination of the Traceflow to its source.
+func genReplyGraph(graph *gographviz.Graph, tf *crdv1alpha1.Traceflow) error {
+	senderRst := getNodeResult(tf.Status.ReplyResults, isSender)
+	receiverRst := getNodeResult(tf.Status.ReplyResults, isReceiver)
+	if senderRst == nil || len(senderRst.Observations) == 0 {
+		return nil
+	}
+
+	cluster1, err := createClusterWithDefaultStyle(graph, clusterReplySrcName)
+	if err != nil {
+		return err
+	}
+	// Handle single node reply.
+	if receiverRst == nil {
+		nodes, err := genSubGraph(graph, cluster1, senderRst, &tf.Spec, getDstNodeName(tf), true, 0)
+		if err != nil {
+			return err
+		}
+		cluster1.Attrs[gographviz.Label] = getWrappedStr("Reply: " + senderRst.Node)
+		return genSingleNodeDestination(graph, cluster1, nodes, senderRst, getSrcNodeName(tf))
+	}
+
+	var nodeNum int
+	if len(senderRst.Observations) > len(receiverRst.Observations) {
+		nodeNum = len(senderRst.Observations)
+	} else {
+		nodeNum = len(receiverRst.Observations)
+	}
+	nodes1, err := genSubGraph(graph, cluster1, senderRst, &tf.Spec, getDstNodeName(tf), true, nodeNum-len(senderRst.Observations))
+	if err != nil {
+		return err
+	}
+	cluster1.Attrs[gographviz.Label] = getWrappedStr("Reply: " + senderRst.Node)
+	cluster2, err := createClusterWithDefaultStyle(graph, clusterReplyDstName)
+	if err != nil {
+		return err
+	}
+	nodes2, err := genSubGraph(graph, cluster2, receiverRst, &tf.Spec, getSrcNodeName(tf), false, nodeNum-len(receiverRst.Observations))
+	if err != nil {
+		return err
+	}
+	cluster2.Attrs[gographviz.Label] = getWrappedStr("Reply: " + receiverRst.Node)
+
+	// Draw the cross-cluster edge.
+	if len(nodes1) > 0 && len(nodes2) > 0 {
+		edge, err := createDirectedEdgeWithDefaultStyle(graph, nodes1[len(nodes1)-1], nodes2[len(nodes2)-1], true)
+		if err != nil {
+			return err
+		}
+		edge.Attrs[gographviz.Constraint] = "false"
+	}
+	return nil
+}
+
 func getCapturedPacketLabel(tf *crdv1alpha1.Traceflow) string {
 	label := "Captured Packet:\\lSource IP: " + tf.Status.CapturedPacket.SrcIP + "\\l" +
 		"Destination IP: " + tf.Status.CapturedPacket.DstIP + "\\l" +
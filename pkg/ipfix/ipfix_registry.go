@@ -17,10 +17,19 @@ package ipfix
 import (
 	ipfixentities "github.com/vmware/go-ipfix/pkg/entities"
 	ipfixregistry "github.com/vmware/go-ipfix/pkg/registry"
+	"k8s.io/klog/v2"
 )
 
 var _ IPFIXRegistry = new(ipfixRegistry)
 
+// antreaInfoElements are the Antrea information elements which are not in the
+// go-ipfix registry. Their IDs start at 200 to leave room for the elements
+// added to the go-ipfix registry.
+var antreaInfoElements = []*ipfixentities.InfoElement{
+	ipfixentities.NewInfoElement("dnsQueryName", 200, ipfixentities.String, ipfixregistry.AntreaEnterpriseID, 65535),
+	ipfixentities.NewInfoElement("dnsResponseCode", 201, ipfixentities.Unsigned8, ipfixregistry.AntreaEnterpriseID, 1),
+}
+
 // IPFIXRegistry interface is added to facilitate unit testing without involving the code from go-ipfix library.
 type IPFIXRegistry interface {
 	LoadRegistry()
@@ -34,7 +43,18 @@ func NewIPFIXRegistry() *ipfixRegistry {
 }
 
 func (reg *ipfixRegistry) LoadRegistry() {
+	LoadRegistry()
+}
+
+// LoadRegistry loads the go-ipfix registry and adds the Antrea information
+// elements which are not in it.
+func LoadRegistry() {
 	ipfixregistry.LoadRegistry()
+	for _, ie := range antreaInfoElements {
+		if err := ipfixregistry.PutInfoElement(*ie, ipfixregistry.AntreaEnterpriseID); err != nil {
+			klog.ErrorS(err, "Failed to add information element to the IPFIX registry", "name", ie.Name)
+		}
+	}
 }
 
 func (reg *ipfixRegistry) GetInfoElement(name string, enterpriseID uint32) (*ipfixentities.InfoElement, error) {
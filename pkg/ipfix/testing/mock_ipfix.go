@@ -20,9 +20,9 @@
 package testing
 
 import (
-	gomock "github.com/golang/mock/gomock"
 	entities "github.com/vmware/go-ipfix/pkg/entities"
 	intermediate "github.com/vmware/go-ipfix/pkg/intermediate"
+	gomock "go.uber.org/mock/gomock"
 	reflect "reflect"
 	time "time"
 )
@@ -295,6 +295,20 @@ func (mr *MockIPFIXAggregationProcessMockRecorder) ForAllExpiredFlowRecordsDo(ar
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ForAllExpiredFlowRecordsDo", reflect.TypeOf((*MockIPFIXAggregationProcess)(nil).ForAllExpiredFlowRecordsDo), arg0)
 }
 
+// ForAllRecordsDo mocks base method
+func (m *MockIPFIXAggregationProcess) ForAllRecordsDo(arg0 intermediate.FlowKeyRecordMapCallBack) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ForAllRecordsDo", arg0)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// ForAllRecordsDo indicates an expected call of ForAllRecordsDo
+func (mr *MockIPFIXAggregationProcessMockRecorder) ForAllRecordsDo(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ForAllRecordsDo", reflect.TypeOf((*MockIPFIXAggregationProcess)(nil).ForAllRecordsDo), arg0)
+}
+
 // GetExpiryFromExpirePriorityQueue mocks base method
 func (m *MockIPFIXAggregationProcess) GetExpiryFromExpirePriorityQueue() time.Duration {
 	m.ctrl.T.Helper()
@@ -310,6 +310,7 @@ type LearnAction interface {
 	DeleteLearned() LearnAction
 	MatchEthernetProtocolIP(isIPv6 bool) LearnAction
 	MatchTransportDst(protocol Protocol) LearnAction
+	MatchTransportSrcAndDst(protocol Protocol) LearnAction
 	MatchLearnedTCPDstPort() LearnAction
 	MatchLearnedUDPDstPort() LearnAction
 	MatchLearnedSCTPDstPort() LearnAction
@@ -328,10 +329,26 @@ type LearnAction interface {
 	Done() FlowBuilder
 }
 
+// HashFields is the set of packet fields used to select a bucket of a group with the hash selection method.
+type HashFields uint8
+
+const (
+	HashFieldSrcIP HashFields = 1 << iota
+	HashFieldDstIP
+	HashFieldIPProto
+	HashFieldSrcPort
+	HashFieldDstPort
+	// HashFieldsFiveTuple is the 5-tuple of the packet.
+	HashFieldsFiveTuple = HashFieldSrcIP | HashFieldDstIP | HashFieldIPProto | HashFieldSrcPort | HashFieldDstPort
+)
+
 type Group interface {
 	OFEntry
 	ResetBuckets() Group
 	Bucket() BucketBuilder
+	// SelectionMethodHash makes the group select a bucket with the hash of the provided fields of the packet, so that
+	// the selection is consistent across OVS instances.
+	SelectionMethodHash(ipProtocol Protocol, fields HashFields) Group
 }
 
 type BucketBuilder interface {
@@ -344,6 +361,8 @@ type BucketBuilder interface {
 	LoadToRegField(field *RegField, data uint32) BucketBuilder
 	ResubmitToTable(tableID uint8) BucketBuilder
 	SetTunnelDst(addr net.IP) BucketBuilder
+	Meter(meterID uint32) BucketBuilder
+	SendToController(reason uint8) BucketBuilder
 	Done() Group
 }
 
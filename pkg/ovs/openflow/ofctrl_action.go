@@ -512,6 +512,18 @@ func (a *ofLearnAction) MatchEthernetProtocolIP(isIPv6 bool) LearnAction {
 // currently being processed. It only accepts ProtocolTCP, ProtocolUDP, or
 // ProtocolSCTP, otherwise this does nothing.
 func (a *ofLearnAction) MatchTransportDst(protocol Protocol) LearnAction {
+	return a.matchTransport(protocol, false)
+}
+
+// MatchTransportSrcAndDst specifies that both the transport layer source field
+// {tcp|udp}_src and destination field {tcp|udp}_dst in the learned flow must
+// match the same fields of the packet currently being processed. It only accepts
+// ProtocolTCP, ProtocolUDP, or ProtocolSCTP, otherwise this does nothing.
+func (a *ofLearnAction) MatchTransportSrcAndDst(protocol Protocol) LearnAction {
+	return a.matchTransport(protocol, true)
+}
+
+func (a *ofLearnAction) matchTransport(protocol Protocol, matchSrc bool) LearnAction {
 	var ipProtoValue int
 	isIPv6 := false
 	switch protocol {
@@ -541,8 +553,12 @@ func (a *ofLearnAction) MatchTransportDst(protocol Protocol) LearnAction {
 	a.nxLearn.AddMatch(&ofctrl.LearnField{Name: "NXM_OF_IP_PROTO"}, 1*8, nil, ipTypeVal)
 	// OXM_OF fields support TCP, UDP and SCTP, but NXM_OF fields only support TCP and UDP. So here using "OXM_OF_" to
 	// generate the field name.
-	trimProtocol := strings.ReplaceAll(string(protocol), "v6", "")
-	fieldName := fmt.Sprintf("OXM_OF_%s_DST", strings.ToUpper(trimProtocol))
+	trimProtocol := strings.ToUpper(strings.ReplaceAll(string(protocol), "v6", ""))
+	if matchSrc {
+		srcFieldName := fmt.Sprintf("OXM_OF_%s_SRC", trimProtocol)
+		a.nxLearn.AddMatch(&ofctrl.LearnField{Name: srcFieldName}, 2*8, &ofctrl.LearnField{Name: srcFieldName}, nil)
+	}
+	fieldName := fmt.Sprintf("OXM_OF_%s_DST", trimProtocol)
 	a.nxLearn.AddMatch(&ofctrl.LearnField{Name: fieldName}, 2*8, &ofctrl.LearnField{Name: fieldName}, nil)
 	return a
 }
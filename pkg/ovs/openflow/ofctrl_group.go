@@ -15,6 +15,7 @@
 package openflow
 
 import (
+	"encoding/binary"
 	"fmt"
 	"net"
 
@@ -40,6 +41,7 @@ func (g *ofGroup) Reset() {
 	// and the Agent will crash later.
 	newGroup, _ := g.bridge.ofSwitch.NewGroup(g.ofctrl.ID, g.ofctrl.GroupType)
 	newGroup.Buckets = g.ofctrl.Buckets
+	newGroup.Properties = g.ofctrl.Properties
 	g.ofctrl = newGroup
 }
 
@@ -85,11 +87,133 @@ func (g *ofGroup) GetBundleMessage(entryOper OFOperation) (ofctrl.OpenFlowModMes
 	return message, nil
 }
 
+// ResetBuckets removes all buckets of the group. As the group is expected to be built again, the selection method is
+// also reset to the default one.
 func (g *ofGroup) ResetBuckets() Group {
 	g.ofctrl.Buckets = nil
+	g.ofctrl.Properties = nil
 	return g
 }
 
+// SelectionMethodHash makes the group select a bucket with the hash of the provided fields of the packet, instead of
+// the hash computed by the datapath. As the hash doesn't depend on the datapath, the same bucket is selected for a connection
+// by all OVS instances which have the same buckets in the group. The fields are not wildcarded in the datapath flows,
+// so ovs-vswitchd selects the bucket for the first packet of every connection, in time linear with the number of
+// buckets.
+func (g *ofGroup) SelectionMethodHash(ipProtocol Protocol, fields HashFields) Group {
+	g.ofctrl.Properties = []util.Message{&selectionMethodProperty{method: "hash", fields: selectionMethodHashFields(ipProtocol, fields)}}
+	return g
+}
+
+// selectionMethodHashFields returns the OXM headers of the provided fields. OVS ignores the transport fields which
+// don't apply to the packet, so the ports of all transport protocols are included.
+func selectionMethodHashFields(ipProtocol Protocol, fields HashFields) []uint32 {
+	var headers []uint32
+	if fields&HashFieldSrcIP != 0 {
+		if ipProtocol == ProtocolIPv6 {
+			headers = append(headers, oxmHeaderIPv6Src)
+		} else {
+			headers = append(headers, oxmHeaderIPSrc)
+		}
+	}
+	if fields&HashFieldDstIP != 0 {
+		if ipProtocol == ProtocolIPv6 {
+			headers = append(headers, oxmHeaderIPv6Dst)
+		} else {
+			headers = append(headers, oxmHeaderIPDst)
+		}
+	}
+	if fields&HashFieldIPProto != 0 {
+		headers = append(headers, oxmHeaderIPProto)
+	}
+	if fields&HashFieldSrcPort != 0 {
+		headers = append(headers, oxmHeaderTCPSrc, oxmHeaderUDPSrc, oxmHeaderSCTPSrc)
+	}
+	if fields&HashFieldDstPort != 0 {
+		headers = append(headers, oxmHeaderTCPDst, oxmHeaderUDPDst, oxmHeaderSCTPDst)
+	}
+	return headers
+}
+
+const (
+	// ntrVendorID is the experimenter ID used by OVS for the group selection method extension.
+	ntrVendorID = 0x0000154d
+	// ntrSelectionMethod is the experimenter type of the group selection method property.
+	ntrSelectionMethod = 1
+	// ntrMaxSelectionMethodLen is the length of the selection method name in the property.
+	ntrMaxSelectionMethodLen = 16
+	// selectionMethodPropertyLen is the length of the property without the OXM headers of the hash fields.
+	selectionMethodPropertyLen = 40
+)
+
+// The OXM headers of the fields which can be used to compute the hash.
+const (
+	oxmHeaderIPSrc   = 0x80001604
+	oxmHeaderIPDst   = 0x80001804
+	oxmHeaderIPProto = 0x80001401
+	oxmHeaderTCPSrc  = 0x80001a02
+	oxmHeaderTCPDst  = 0x80001c02
+	oxmHeaderUDPSrc  = 0x80001e02
+	oxmHeaderUDPDst  = 0x80002002
+	oxmHeaderSCTPSrc = 0x80002202
+	oxmHeaderSCTPDst = 0x80002402
+	oxmHeaderIPv6Src = 0x80003410
+	oxmHeaderIPv6Dst = 0x80003610
+)
+
+// selectionMethodProperty is the OVS extension group property "ntr_group_prop_selection_method", which specifies the
+// method and the fields used to select a bucket of a select group.
+type selectionMethodProperty struct {
+	method string
+	param  uint64
+	fields []uint32
+}
+
+func (p *selectionMethodProperty) Len() uint16 {
+	return uint16(selectionMethodPropertyLen + 4*len(p.fields))
+}
+
+// MarshalBinary encodes the property. The encoded data is padded to a multiple of 8 bytes, and the padding is not
+// included in the length of the property.
+func (p *selectionMethodProperty) MarshalBinary() ([]byte, error) {
+	length := int(p.Len())
+	data := make([]byte, (length+7)/8*8)
+	binary.BigEndian.PutUint16(data[0:], 0xffff)
+	binary.BigEndian.PutUint16(data[2:], uint16(length))
+	binary.BigEndian.PutUint32(data[4:], ntrVendorID)
+	binary.BigEndian.PutUint32(data[8:], ntrSelectionMethod)
+	copy(data[16:16+ntrMaxSelectionMethodLen-1], p.method)
+	binary.BigEndian.PutUint64(data[32:], p.param)
+	for i, field := range p.fields {
+		binary.BigEndian.PutUint32(data[selectionMethodPropertyLen+4*i:], field)
+	}
+	return data, nil
+}
+
+func (p *selectionMethodProperty) UnmarshalBinary(data []byte) error {
+	if len(data) < selectionMethodPropertyLen {
+		return fmt.Errorf("the [%d] bytes are too short to decode a selection method property", len(data))
+	}
+	length := int(binary.BigEndian.Uint16(data[2:]))
+	if length < selectionMethodPropertyLen || len(data) < length {
+		return fmt.Errorf("invalid length %d of selection method property", length)
+	}
+	method := data[16 : 16+ntrMaxSelectionMethodLen]
+	for i, c := range method {
+		if c == 0 {
+			method = method[:i]
+			break
+		}
+	}
+	p.method = string(method)
+	p.param = binary.BigEndian.Uint64(data[32:])
+	p.fields = nil
+	for i := selectionMethodPropertyLen; i+4 <= length; i += 4 {
+		p.fields = append(p.fields, binary.BigEndian.Uint32(data[i:]))
+	}
+	return nil
+}
+
 type bucketBuilder struct {
 	group  *ofGroup
 	bucket *openflow15.Bucket
@@ -150,6 +274,26 @@ func (b *bucketBuilder) SetTunnelDst(addr net.IP) BucketBuilder {
 	return b
 }
 
+// Meter is an action to process the packet with the specified meter when the bucket is selected.
+func (b *bucketBuilder) Meter(meterID uint32) BucketBuilder {
+	meterAct := ofctrl.NewMeterAction(meterID)
+	b.bucket.AddAction(meterAct.GetActionMessage())
+	return b
+}
+
+// SendToController is an action to send the packet to the controller with the specified reason when the bucket is
+// selected.
+func (b *bucketBuilder) SendToController(reason uint8) BucketBuilder {
+	if b.group.ofctrl.Switch != nil {
+		controllerAct := &ofctrl.NXController{
+			ControllerID: b.group.ofctrl.Switch.GetControllerID(),
+			Reason:       reason,
+		}
+		b.bucket.AddAction(controllerAct.GetActionMessage())
+	}
+	return b
+}
+
 // Weight sets the weight of a bucket.
 func (b *bucketBuilder) Weight(val uint16) BucketBuilder {
 	weight := openflow15.NewGroupBucketPropWeight(val)
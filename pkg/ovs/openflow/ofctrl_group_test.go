@@ -0,0 +1,46 @@
+// Copyright 2022 Antrea Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package openflow
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSelectionMethodProperty(t *testing.T) {
+	prop := &selectionMethodProperty{method: "hash", fields: selectionMethodHashFields(ProtocolIP, HashFieldsFiveTuple)}
+	assert.Equal(t, uint16(76), prop.Len())
+	data, err := prop.MarshalBinary()
+	require.NoError(t, err)
+	// The encoded data is padded to a multiple of 8 bytes.
+	assert.Len(t, data, 80)
+	assert.Equal(t, []byte{0xff, 0xff, 0x00, 0x4c, 0x00, 0x00, 0x15, 0x4d, 0x00, 0x00, 0x00, 0x01}, data[:12])
+	assert.Equal(t, []byte("hash\x00"), data[16:21])
+	assert.Equal(t, []byte{0x80, 0x00, 0x16, 0x04}, data[40:44])
+
+	decoded := &selectionMethodProperty{}
+	require.NoError(t, decoded.UnmarshalBinary(data))
+	assert.Equal(t, prop, decoded)
+
+	assert.Error(t, decoded.UnmarshalBinary(data[:20]))
+}
+
+func TestSelectionMethodHashFields(t *testing.T) {
+	assert.Equal(t, []uint32{oxmHeaderIPSrc}, selectionMethodHashFields(ProtocolIP, HashFieldSrcIP))
+	assert.Equal(t, []uint32{oxmHeaderIPv6Src, oxmHeaderTCPSrc, oxmHeaderUDPSrc, oxmHeaderSCTPSrc}, selectionMethodHashFields(ProtocolIPv6, HashFieldSrcIP|HashFieldSrcPort))
+	assert.Len(t, selectionMethodHashFields(ProtocolIPv6, HashFieldsFiveTuple), 9)
+}
@@ -21,6 +21,7 @@ import (
 	"testing"
 
 	"antrea.io/libOpenflow/protocol"
+	"antrea.io/libOpenflow/util"
 	"antrea.io/ofnet/ofctrl"
 	"github.com/stretchr/testify/assert"
 )
@@ -611,6 +612,8 @@ func Test_ofPacketOutBuilder_Done(t *testing.T) {
 	}
 	icmpID := uint16(1)
 	icmpSeq := uint16(2)
+	l4Packet := new(util.Buffer)
+	l4Packet.UnmarshalBinary(make([]byte, 12))
 	tests := []struct {
 		name   string
 		fields fields
@@ -788,6 +791,42 @@ func Test_ofPacketOutBuilder_Done(t *testing.T) {
 				},
 			},
 		},
+		{
+			name: "IPv4 L4 packet",
+			fields: fields{
+				pktOut: &ofctrl.PacketOut{
+					IPHeader: &protocol.IPv4{
+						Data: l4Packet,
+					},
+				},
+			},
+			want: &ofctrl.PacketOut{
+				IPHeader: &protocol.IPv4{
+					Version:  0x4,
+					Length:   32,
+					Checksum: 46749,
+					Id:       1090,
+					Data:     l4Packet,
+				},
+			},
+		},
+		{
+			name: "IPv6 L4 packet",
+			fields: fields{
+				pktOut: &ofctrl.PacketOut{
+					IPv6Header: &protocol.IPv6{
+						Data: l4Packet,
+					},
+				},
+			},
+			want: &ofctrl.PacketOut{
+				IPv6Header: &protocol.IPv6{
+					Version: 0x6,
+					Length:  12,
+					Data:    l4Packet,
+				},
+			},
+		},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
@@ -22,7 +22,7 @@ package testing
 import (
 	openflow "antrea.io/antrea/pkg/ovs/openflow"
 	ofctrl "antrea.io/ofnet/ofctrl"
-	gomock "github.com/golang/mock/gomock"
+	gomock "go.uber.org/mock/gomock"
 	net "net"
 	reflect "reflect"
 )
@@ -2303,6 +2303,20 @@ func (mr *MockGroupMockRecorder) ResetBuckets() *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ResetBuckets", reflect.TypeOf((*MockGroup)(nil).ResetBuckets))
 }
 
+// SelectionMethodHash mocks base method
+func (m *MockGroup) SelectionMethodHash(arg0 openflow.Protocol, arg1 openflow.HashFields) openflow.Group {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SelectionMethodHash", arg0, arg1)
+	ret0, _ := ret[0].(openflow.Group)
+	return ret0
+}
+
+// SelectionMethodHash indicates an expected call of SelectionMethodHash
+func (mr *MockGroupMockRecorder) SelectionMethodHash(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SelectionMethodHash", reflect.TypeOf((*MockGroup)(nil).SelectionMethodHash), arg0, arg1)
+}
+
 // Type mocks base method
 func (m *MockGroup) Type() openflow.EntryType {
 	m.ctrl.T.Helper()
@@ -2410,6 +2424,20 @@ func (mr *MockBucketBuilderMockRecorder) LoadXXReg(arg0, arg1 interface{}) *gomo
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "LoadXXReg", reflect.TypeOf((*MockBucketBuilder)(nil).LoadXXReg), arg0, arg1)
 }
 
+// Meter mocks base method
+func (m *MockBucketBuilder) Meter(arg0 uint32) openflow.BucketBuilder {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Meter", arg0)
+	ret0, _ := ret[0].(openflow.BucketBuilder)
+	return ret0
+}
+
+// Meter indicates an expected call of Meter
+func (mr *MockBucketBuilderMockRecorder) Meter(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Meter", reflect.TypeOf((*MockBucketBuilder)(nil).Meter), arg0)
+}
+
 // ResubmitToTable mocks base method
 func (m *MockBucketBuilder) ResubmitToTable(arg0 byte) openflow.BucketBuilder {
 	m.ctrl.T.Helper()
@@ -2424,6 +2452,20 @@ func (mr *MockBucketBuilderMockRecorder) ResubmitToTable(arg0 interface{}) *gomo
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ResubmitToTable", reflect.TypeOf((*MockBucketBuilder)(nil).ResubmitToTable), arg0)
 }
 
+// SendToController mocks base method
+func (m *MockBucketBuilder) SendToController(arg0 byte) openflow.BucketBuilder {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SendToController", arg0)
+	ret0, _ := ret[0].(openflow.BucketBuilder)
+	return ret0
+}
+
+// SendToController indicates an expected call of SendToController
+func (mr *MockBucketBuilderMockRecorder) SendToController(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SendToController", reflect.TypeOf((*MockBucketBuilder)(nil).SendToController), arg0)
+}
+
 // SetTunnelDst mocks base method
 func (m *MockBucketBuilder) SetTunnelDst(arg0 net.IP) openflow.BucketBuilder {
 	m.ctrl.T.Helper()
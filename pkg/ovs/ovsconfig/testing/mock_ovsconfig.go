@@ -21,7 +21,7 @@ package testing
 
 import (
 	ovsconfig "antrea.io/antrea/pkg/ovs/ovsconfig"
-	gomock "github.com/golang/mock/gomock"
+	gomock "go.uber.org/mock/gomock"
 	reflect "reflect"
 )
 
@@ -31,6 +31,25 @@ type TracingRequest struct {
 	AllowOverrideInPort bool
 }
 
+// MeterStats defines the statistics of an OpenFlow meter.
+type MeterStats struct {
+	ID uint32
+	// PacketCount is the number of packets processed by the meter.
+	PacketCount uint64
+	// BandPacketCounts are the numbers of packets processed by each band of the meter. For a drop band, it is the
+	// number of packets dropped by the band.
+	BandPacketCounts []uint64
+}
+
+// GroupStats defines the statistics of an OpenFlow group.
+type GroupStats struct {
+	ID uint32
+	// PacketCount is the number of packets processed by the group.
+	PacketCount uint64
+	// BucketPacketCounts are the numbers of packets processed by each bucket of the group.
+	BucketPacketCounts []uint64
+}
+
 // OVSCtlClient is an interface for executing OVS "ovs-ofctl" and "ovs-appctl"
 // commands.
 type OVSCtlClient interface {
@@ -47,6 +66,10 @@ type OVSCtlClient interface {
 	DumpGroup(groupID uint32) (string, error)
 	// DumpGroups returns OpenFlow groups of the bridge.
 	DumpGroups() ([]string, error)
+	// DumpGroupStats returns the statistics of all OpenFlow groups of the bridge.
+	DumpGroupStats() ([]GroupStats, error)
+	// DumpMeterStats returns the statistics of all OpenFlow meters of the bridge.
+	DumpMeterStats() ([]MeterStats, error)
 	// DumpPortsDesc returns OpenFlow ports descriptions of the bridge.
 	DumpPortsDesc() ([][]string, error)
 	// RunOfctlCmd executes "ovs-ofctl" command and returns the outputs.
@@ -119,6 +119,128 @@ func (c *ovsCtlClient) DumpGroups() ([]string, error) {
 	return groupList, nil
 }
 
+func (c *ovsCtlClient) DumpMeterStats() ([]MeterStats, error) {
+	meterStatsDump, err := c.RunOfctlCmd("meter-stats")
+	if err != nil {
+		return nil, err
+	}
+	return parseMeterStats(meterStatsDump)
+}
+
+// parseMeterStats parses the output of "ovs-ofctl meter-stats", in which the statistics of a meter are printed as:
+//
+//	meter:256 flow_count:2 packet_in_count:150 byte_in_count:9000 duration:25.532s bands:
+//	0: packet_count:50 byte_count:3000
+func parseMeterStats(meterStatsDump []byte) ([]MeterStats, error) {
+	scanner := bufio.NewScanner(strings.NewReader(string(meterStatsDump)))
+	scanner.Split(bufio.ScanLines)
+	var meterStatsList []MeterStats
+	var current *MeterStats
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if strings.HasPrefix(line, "meter:") {
+			stats := MeterStats{}
+			for _, field := range strings.Fields(line) {
+				kv := strings.SplitN(field, ":", 2)
+				if len(kv) != 2 {
+					continue
+				}
+				switch kv[0] {
+				case "meter":
+					id, err := strconv.ParseUint(kv[1], 10, 32)
+					if err != nil {
+						return nil, fmt.Errorf("invalid meter ID in line %q: %v", line, err)
+					}
+					stats.ID = uint32(id)
+				case "packet_in_count":
+					count, err := strconv.ParseUint(kv[1], 10, 64)
+					if err != nil {
+						return nil, fmt.Errorf("invalid packet count in line %q: %v", line, err)
+					}
+					stats.PacketCount = count
+				}
+			}
+			meterStatsList = append(meterStatsList, stats)
+			current = &meterStatsList[len(meterStatsList)-1]
+			continue
+		}
+		// The band statistics follow the line of the meter they belong to.
+		if current == nil || !strings.Contains(line, "packet_count:") {
+			continue
+		}
+		for _, field := range strings.Fields(line) {
+			if !strings.HasPrefix(field, "packet_count:") {
+				continue
+			}
+			count, err := strconv.ParseUint(strings.TrimPrefix(field, "packet_count:"), 10, 64)
+			if err != nil {
+				return nil, fmt.Errorf("invalid band packet count in line %q: %v", line, err)
+			}
+			current.BandPacketCounts = append(current.BandPacketCounts, count)
+		}
+	}
+	return meterStatsList, nil
+}
+
+func (c *ovsCtlClient) DumpGroupStats() ([]GroupStats, error) {
+	groupStatsDump, err := c.RunOfctlCmd("dump-group-stats")
+	if err != nil {
+		return nil, err
+	}
+	return parseGroupStats(groupStatsDump)
+}
+
+// parseGroupStats parses the output of "ovs-ofctl dump-group-stats", in which the statistics of a group are printed
+// in a single line as:
+//
+//	group_id=1,duration=25.532s,ref_count=2,packet_count=150,byte_count=9000,bucket0:packet_count=50,byte_count=3000,bucket1:packet_count=100,byte_count=6000
+func parseGroupStats(groupStatsDump []byte) ([]GroupStats, error) {
+	scanner := bufio.NewScanner(strings.NewReader(string(groupStatsDump)))
+	scanner.Split(bufio.ScanLines)
+	var groupStatsList []GroupStats
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if !strings.HasPrefix(line, "group_id=") {
+			continue
+		}
+		stats := GroupStats{}
+		inBucket := false
+		for _, field := range strings.Split(line, ",") {
+			// The statistics of a bucket start with its index, e.g. "bucket0:packet_count=50".
+			if strings.HasPrefix(field, "bucket") {
+				if i := strings.Index(field, ":"); i >= 0 {
+					field = field[i+1:]
+					inBucket = true
+				}
+			}
+			kv := strings.SplitN(field, "=", 2)
+			if len(kv) != 2 {
+				continue
+			}
+			switch kv[0] {
+			case "group_id":
+				id, err := strconv.ParseUint(kv[1], 10, 32)
+				if err != nil {
+					return nil, fmt.Errorf("invalid group ID in line %q: %v", line, err)
+				}
+				stats.ID = uint32(id)
+			case "packet_count":
+				count, err := strconv.ParseUint(kv[1], 10, 64)
+				if err != nil {
+					return nil, fmt.Errorf("invalid packet count in line %q: %v", line, err)
+				}
+				if inBucket {
+					stats.BucketPacketCounts = append(stats.BucketPacketCounts, count)
+				} else {
+					stats.PacketCount = count
+				}
+			}
+		}
+		groupStatsList = append(groupStatsList, stats)
+	}
+	return groupStatsList, nil
+}
+
 func (c *ovsCtlClient) DumpPortsDesc() ([][]string, error) {
 	portsDescDump, err := c.RunOfctlCmd("dump-ports-desc")
 	if err != nil {
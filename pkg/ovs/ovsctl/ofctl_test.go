@@ -0,0 +1,113 @@
+// Copyright 2022 Antrea Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ovsctl
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseMeterStats(t *testing.T) {
+	tests := []struct {
+		name      string
+		output    string
+		want      []MeterStats
+		expectErr bool
+	}{
+		{
+			name: "multiple meters",
+			output: `OFPST_METER reply (OF1.5) (xid=0x2):
+meter:256 flow_count:3 packet_in_count:150 byte_in_count:9000 duration:25.532s bands:
+0: packet_count:50 byte_count:3000
+
+meter:16777217 flow_count:1 packet_in_count:20 byte_in_count:1200 duration:3.001s bands:
+0: packet_count:0 byte_count:0
+`,
+			want: []MeterStats{
+				{ID: 256, PacketCount: 150, BandPacketCounts: []uint64{50}},
+				{ID: 16777217, PacketCount: 20, BandPacketCounts: []uint64{0}},
+			},
+		},
+		{
+			name:   "no meter",
+			output: "OFPST_METER reply (OF1.5) (xid=0x2):\n",
+			want:   nil,
+		},
+		{
+			name: "invalid meter ID",
+			output: `OFPST_METER reply (OF1.5) (xid=0x2):
+meter:foo flow_count:3 packet_in_count:150 byte_in_count:9000 duration:25.532s bands:
+`,
+			expectErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := parseMeterStats([]byte(tt.output))
+			if tt.expectErr {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestParseGroupStats(t *testing.T) {
+	tests := []struct {
+		name      string
+		output    string
+		want      []GroupStats
+		expectErr bool
+	}{
+		{
+			name: "multiple groups",
+			output: `OFPST_GROUP reply (OF1.5) (xid=0x2):
+ group_id=1,duration=25.532s,ref_count=2,packet_count=150,byte_count=9000,bucket0:packet_count=150,byte_count=9000
+ group_id=536870913,duration=3.001s,ref_count=1,packet_count=20,byte_count=1200,bucket0:packet_count=2,byte_count=120,bucket1:packet_count=18,byte_count=1080
+`,
+			want: []GroupStats{
+				{ID: 1, PacketCount: 150, BucketPacketCounts: []uint64{150}},
+				{ID: 536870913, PacketCount: 20, BucketPacketCounts: []uint64{2, 18}},
+			},
+		},
+		{
+			name:   "no group",
+			output: "OFPST_GROUP reply (OF1.5) (xid=0x2):\n",
+			want:   nil,
+		},
+		{
+			name: "invalid packet count",
+			output: `OFPST_GROUP reply (OF1.5) (xid=0x2):
+ group_id=1,duration=25.532s,ref_count=2,packet_count=150,byte_count=9000,bucket0:packet_count=foo,byte_count=9000
+`,
+			expectErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := parseGroupStats([]byte(tt.output))
+			if tt.expectErr {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
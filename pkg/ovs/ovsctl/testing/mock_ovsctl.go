@@ -21,7 +21,7 @@ package testing
 
 import (
 	ovsctl "antrea.io/antrea/pkg/ovs/ovsctl"
-	gomock "github.com/golang/mock/gomock"
+	gomock "go.uber.org/mock/gomock"
 	reflect "reflect"
 )
 
@@ -115,6 +115,21 @@ func (mr *MockOVSCtlClientMockRecorder) DumpGroup(arg0 interface{}) *gomock.Call
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DumpGroup", reflect.TypeOf((*MockOVSCtlClient)(nil).DumpGroup), arg0)
 }
 
+// DumpGroupStats mocks base method
+func (m *MockOVSCtlClient) DumpGroupStats() ([]ovsctl.GroupStats, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DumpGroupStats")
+	ret0, _ := ret[0].([]ovsctl.GroupStats)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// DumpGroupStats indicates an expected call of DumpGroupStats
+func (mr *MockOVSCtlClientMockRecorder) DumpGroupStats() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DumpGroupStats", reflect.TypeOf((*MockOVSCtlClient)(nil).DumpGroupStats))
+}
+
 // DumpGroups mocks base method
 func (m *MockOVSCtlClient) DumpGroups() ([]string, error) {
 	m.ctrl.T.Helper()
@@ -145,6 +160,21 @@ func (mr *MockOVSCtlClientMockRecorder) DumpMatchedFlow(arg0 interface{}) *gomoc
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DumpMatchedFlow", reflect.TypeOf((*MockOVSCtlClient)(nil).DumpMatchedFlow), arg0)
 }
 
+// DumpMeterStats mocks base method
+func (m *MockOVSCtlClient) DumpMeterStats() ([]ovsctl.MeterStats, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DumpMeterStats")
+	ret0, _ := ret[0].([]ovsctl.MeterStats)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// DumpMeterStats indicates an expected call of DumpMeterStats
+func (mr *MockOVSCtlClientMockRecorder) DumpMeterStats() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DumpMeterStats", reflect.TypeOf((*MockOVSCtlClient)(nil).DumpMeterStats))
+}
+
 // DumpPortsDesc mocks base method
 func (m *MockOVSCtlClient) DumpPortsDesc() ([][]string, error) {
 	m.ctrl.T.Helper()
@@ -15,6 +15,9 @@
 package querier
 
 import (
+	"net"
+	"time"
+
 	v1 "k8s.io/api/core/v1"
 	apitypes "k8s.io/apimachinery/pkg/types"
 
@@ -41,6 +44,9 @@ type AgentNetworkPolicyInfoQuerier interface {
 	GetAppliedNetworkPolicies(pod, namespace string, npFilter *NetworkPolicyQueryFilter) []cpv1beta.NetworkPolicy
 	GetNetworkPolicyByRuleFlowID(ruleFlowID uint32) *cpv1beta.NetworkPolicyReference
 	GetRuleByFlowID(ruleFlowID uint32) *types.PolicyRule
+	// GetFQDNCache returns the FQDN selectors of FQDN policy rules, with the FQDNs they match
+	// and the IP addresses resolved for these FQDNs.
+	GetFQDNCache(fqdnFilter *FQDNCacheFilter) ([]FQDNSelectorInfo, error)
 }
 
 type AgentMulticastInfoQuerier interface {
@@ -106,6 +112,38 @@ type NetworkPolicyQueryFilter struct {
 	SourceType cpv1beta.NetworkPolicyType
 }
 
+// FQDNCacheEntry is an IP address resolved for a FQDN, as cached by antrea-agent to enforce
+// FQDN policy rules.
+type FQDNCacheEntry struct {
+	FQDN           string
+	IPAddress      net.IP
+	ExpirationTime time.Time
+}
+
+// FQDNSelectorInfo describes a FQDN selector of FQDN policy rules, the FQDNs it matches and the
+// rules using it.
+type FQDNSelectorInfo struct {
+	// Selector is "matchName:<FQDN>" for exact FQDNs, or "matchRegex:<regex>" for wildcard expressions.
+	Selector string
+	// RuleIDs are the IDs of the rules using the selector.
+	RuleIDs []string
+	// MatchedFQDNs are the FQDNs matched by the selector.
+	MatchedFQDNs []string
+	// CacheEntries are the IP addresses cached for MatchedFQDNs.
+	CacheEntries []FQDNCacheEntry
+}
+
+// FQDNCacheFilter is used to filter the result of GetFQDNCache. An empty attribute means match all.
+type FQDNCacheFilter struct {
+	// The name and Namespace of a local Pod. Only the selectors used by the rules applied to the
+	// Pod are returned.
+	PodName      string
+	PodNamespace string
+	// An exact domain name or a wildcard expression, e.g. "*.example.com". Only the FQDNs
+	// matching it are returned, and selectors which don't match any of them are omitted.
+	DomainPattern string
+}
+
 // ServiceExternalIPStatusQuerier queries the Service external IP status for debugging purposes.
 // Ideally, every Node should have consistent results eventually. This should only be used when
 // ServiceExternalIP feature is enabled.
@@ -25,7 +25,7 @@ import (
 	types "antrea.io/antrea/pkg/agent/types"
 	v1beta2 "antrea.io/antrea/pkg/apis/controlplane/v1beta2"
 	querier "antrea.io/antrea/pkg/querier"
-	gomock "github.com/golang/mock/gomock"
+	gomock "go.uber.org/mock/gomock"
 	types0 "k8s.io/apimachinery/pkg/types"
 	reflect "reflect"
 )
@@ -137,6 +137,21 @@ func (mr *MockAgentNetworkPolicyInfoQuerierMockRecorder) GetControllerConnection
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetControllerConnectionStatus", reflect.TypeOf((*MockAgentNetworkPolicyInfoQuerier)(nil).GetControllerConnectionStatus))
 }
 
+// GetFQDNCache mocks base method
+func (m *MockAgentNetworkPolicyInfoQuerier) GetFQDNCache(arg0 *querier.FQDNCacheFilter) ([]querier.FQDNSelectorInfo, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetFQDNCache", arg0)
+	ret0, _ := ret[0].([]querier.FQDNSelectorInfo)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetFQDNCache indicates an expected call of GetFQDNCache
+func (mr *MockAgentNetworkPolicyInfoQuerierMockRecorder) GetFQDNCache(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetFQDNCache", reflect.TypeOf((*MockAgentNetworkPolicyInfoQuerier)(nil).GetFQDNCache), arg0)
+}
+
 // GetNetworkPolicies mocks base method
 func (m *MockAgentNetworkPolicyInfoQuerier) GetNetworkPolicies(arg0 *querier.NetworkPolicyQueryFilter) []v1beta2.NetworkPolicy {
 	m.ctrl.T.Helper()
@@ -0,0 +1,64 @@
+// Copyright 2022 Antrea Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package egress
+
+import (
+	crdv1a2 "antrea.io/antrea/pkg/apis/crd/v1alpha2"
+)
+
+// GetCondition returns the condition of the provided type, or nil if it doesn't exist.
+func GetCondition(conditions []crdv1a2.EgressCondition, conditionType crdv1a2.EgressConditionType) *crdv1a2.EgressCondition {
+	for i := range conditions {
+		if conditions[i].Type == conditionType {
+			return &conditions[i]
+		}
+	}
+	return nil
+}
+
+// SetCondition adds the condition to the provided conditions, or updates the existing one of the same type. The
+// LastTransitionTime of an existing condition is only updated when its status changes. It returns the new conditions
+// and whether they are changed.
+func SetCondition(conditions []crdv1a2.EgressCondition, condition crdv1a2.EgressCondition) ([]crdv1a2.EgressCondition, bool) {
+	existing := GetCondition(conditions, condition.Type)
+	if existing == nil {
+		return append(conditions, condition), true
+	}
+	if existing.Status == condition.Status && existing.Reason == condition.Reason && existing.Message == condition.Message {
+		return conditions, false
+	}
+	newConditions := make([]crdv1a2.EgressCondition, 0, len(conditions))
+	for _, c := range conditions {
+		if c.Type != condition.Type {
+			newConditions = append(newConditions, c)
+			continue
+		}
+		if c.Status == condition.Status {
+			condition.LastTransitionTime = c.LastTransitionTime
+		}
+		newConditions = append(newConditions, condition)
+	}
+	return newConditions, true
+}
+
+// AppendFailover appends the transition to the provided failover history and drops the oldest ones if the history
+// exceeds MaxEgressFailoverHistory.
+func AppendFailover(history []crdv1a2.EgressFailover, failover crdv1a2.EgressFailover) []crdv1a2.EgressFailover {
+	history = append(history, failover)
+	if len(history) > crdv1a2.MaxEgressFailoverHistory {
+		history = history[len(history)-crdv1a2.MaxEgressFailoverHistory:]
+	}
+	return history
+}
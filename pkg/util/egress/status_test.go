@@ -0,0 +1,98 @@
+// Copyright 2022 Antrea Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package egress
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	crdv1a2 "antrea.io/antrea/pkg/apis/crd/v1alpha2"
+)
+
+func TestSetCondition(t *testing.T) {
+	t1 := metav1.NewTime(time.Unix(1000, 0))
+	t2 := metav1.NewTime(time.Unix(2000, 0))
+	tests := []struct {
+		name               string
+		conditions         []crdv1a2.EgressCondition
+		condition          crdv1a2.EgressCondition
+		expectedConditions []crdv1a2.EgressCondition
+		expectedChanged    bool
+	}{
+		{
+			name:      "add condition",
+			condition: crdv1a2.EgressCondition{Type: crdv1a2.IPAssigned, Status: metav1.ConditionTrue, LastTransitionTime: t1},
+			expectedConditions: []crdv1a2.EgressCondition{
+				{Type: crdv1a2.IPAssigned, Status: metav1.ConditionTrue, LastTransitionTime: t1},
+			},
+			expectedChanged: true,
+		},
+		{
+			name: "unchanged condition",
+			conditions: []crdv1a2.EgressCondition{
+				{Type: crdv1a2.IPAssigned, Status: metav1.ConditionTrue, LastTransitionTime: t1, Reason: "Assigned"},
+			},
+			condition: crdv1a2.EgressCondition{Type: crdv1a2.IPAssigned, Status: metav1.ConditionTrue, LastTransitionTime: t2, Reason: "Assigned"},
+			expectedConditions: []crdv1a2.EgressCondition{
+				{Type: crdv1a2.IPAssigned, Status: metav1.ConditionTrue, LastTransitionTime: t1, Reason: "Assigned"},
+			},
+		},
+		{
+			name: "update message only",
+			conditions: []crdv1a2.EgressCondition{
+				{Type: crdv1a2.IPAllocated, Status: metav1.ConditionTrue, LastTransitionTime: t1},
+				{Type: crdv1a2.IPAssigned, Status: metav1.ConditionTrue, LastTransitionTime: t1, Message: "foo"},
+			},
+			condition: crdv1a2.EgressCondition{Type: crdv1a2.IPAssigned, Status: metav1.ConditionTrue, LastTransitionTime: t2, Message: "bar"},
+			expectedConditions: []crdv1a2.EgressCondition{
+				{Type: crdv1a2.IPAllocated, Status: metav1.ConditionTrue, LastTransitionTime: t1},
+				{Type: crdv1a2.IPAssigned, Status: metav1.ConditionTrue, LastTransitionTime: t1, Message: "bar"},
+			},
+			expectedChanged: true,
+		},
+		{
+			name: "update status",
+			conditions: []crdv1a2.EgressCondition{
+				{Type: crdv1a2.IPAssigned, Status: metav1.ConditionTrue, LastTransitionTime: t1},
+			},
+			condition: crdv1a2.EgressCondition{Type: crdv1a2.IPAssigned, Status: metav1.ConditionFalse, LastTransitionTime: t2},
+			expectedConditions: []crdv1a2.EgressCondition{
+				{Type: crdv1a2.IPAssigned, Status: metav1.ConditionFalse, LastTransitionTime: t2},
+			},
+			expectedChanged: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			conditions, changed := SetCondition(tt.conditions, tt.condition)
+			assert.Equal(t, tt.expectedConditions, conditions)
+			assert.Equal(t, tt.expectedChanged, changed)
+		})
+	}
+}
+
+func TestAppendFailover(t *testing.T) {
+	var history []crdv1a2.EgressFailover
+	for i := 0; i < crdv1a2.MaxEgressFailoverHistory+2; i++ {
+		history = AppendFailover(history, crdv1a2.EgressFailover{ToNode: fmt.Sprintf("node%d", i)})
+	}
+	assert.Len(t, history, crdv1a2.MaxEgressFailoverHistory)
+	assert.Equal(t, "node2", history[0].ToNode)
+	assert.Equal(t, fmt.Sprintf("node%d", crdv1a2.MaxEgressFailoverHistory+1), history[len(history)-1].ToNode)
+}
@@ -15,6 +15,7 @@
 package env
 
 import (
+	"net"
 	"os"
 	"strconv"
 	"strings"
@@ -28,6 +29,7 @@ const (
 	NodeNameEnvKey        = "NODE_NAME"
 	podNameEnvKey         = "POD_NAME"
 	podNamespaceEnvKey    = "POD_NAMESPACE"
+	podIPEnvKey           = "POD_IP"
 	svcAcctNameEnvKey     = "SERVICEACCOUNT_NAME"
 	antreaConfigMapEnvKey = "ANTREA_CONFIG_MAP_NAME"
 
@@ -72,6 +74,12 @@ func GetPodName() string {
 	return podName
 }
 
+// GetPodIP returns the IP of the Pod where the code executes, or nil if it is
+// not provided.
+func GetPodIP() net.IP {
+	return net.ParseIP(os.Getenv(podIPEnvKey))
+}
+
 // GetAntreaConfigMapName returns the configMap name of Antrea config.
 func GetAntreaConfigMapName() string {
 	configMapName := os.Getenv(antreaConfigMapEnvKey)
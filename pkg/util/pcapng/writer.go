@@ -0,0 +1,112 @@
+// Copyright 2022 Antrea Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package pcapng implements a minimal writer of the pcapng file format, which
+// is enough to store the Ethernet frames captured on a single interface. See
+// https://www.ietf.org/archive/id/draft-tuexen-opsawg-pcapng-05.html.
+package pcapng
+
+import (
+	"encoding/binary"
+	"io"
+	"time"
+)
+
+const (
+	blockTypeSectionHeader        uint32 = 0x0A0D0D0A
+	blockTypeInterfaceDescription uint32 = 0x00000001
+	blockTypeEnhancedPacket       uint32 = 0x00000006
+
+	byteOrderMagic uint32 = 0x1A2B3C4D
+
+	linkTypeEthernet uint16 = 1
+
+	// MaxSnapLength is the maximum number of bytes of a packet stored in
+	// the file. The remaining bytes of larger packets are not stored.
+	MaxSnapLength = 65535
+)
+
+// byteOrder is the byte order of the written blocks. Readers detect it from
+// the byte order magic of the Section Header Block.
+var byteOrder = binary.LittleEndian
+
+// Writer writes packets to a pcapng file. The timestamps of the packets are
+// stored with a microsecond resolution, which is the default resolution of
+// the format.
+type Writer struct {
+	w io.Writer
+}
+
+// NewWriter writes the Section Header Block and the Interface Description
+// Block of the file, and returns a Writer to write the packets captured on the
+// interface.
+func NewWriter(w io.Writer) (*Writer, error) {
+	writer := &Writer{w: w}
+	shb := make([]byte, 16)
+	byteOrder.PutUint32(shb[0:], byteOrderMagic)
+	// Major and minor versions of the format.
+	byteOrder.PutUint16(shb[4:], 1)
+	byteOrder.PutUint16(shb[6:], 0)
+	// The section length is not specified.
+	byteOrder.PutUint64(shb[8:], 0xFFFFFFFFFFFFFFFF)
+	if err := writer.writeBlock(blockTypeSectionHeader, shb); err != nil {
+		return nil, err
+	}
+	idb := make([]byte, 8)
+	byteOrder.PutUint16(idb[0:], linkTypeEthernet)
+	byteOrder.PutUint32(idb[4:], MaxSnapLength)
+	if err := writer.writeBlock(blockTypeInterfaceDescription, idb); err != nil {
+		return nil, err
+	}
+	return writer, nil
+}
+
+// WritePacket writes a packet captured at the provided time as an Enhanced
+// Packet Block. originalLength is the length of the packet on the wire, which
+// can be larger than the length of data if the packet was truncated.
+func (w *Writer) WritePacket(ts time.Time, data []byte, originalLength int) error {
+	if len(data) > MaxSnapLength {
+		data = data[:MaxSnapLength]
+	}
+	if originalLength < len(data) {
+		originalLength = len(data)
+	}
+	epb := make([]byte, 20+pad(len(data)))
+	micros := uint64(ts.UnixNano() / int64(time.Microsecond))
+	// The interface ID is 0, the only interface of the file.
+	byteOrder.PutUint32(epb[4:], uint32(micros>>32))
+	byteOrder.PutUint32(epb[8:], uint32(micros))
+	byteOrder.PutUint32(epb[12:], uint32(len(data)))
+	byteOrder.PutUint32(epb[16:], uint32(originalLength))
+	copy(epb[20:], data)
+	return w.writeBlock(blockTypeEnhancedPacket, epb)
+}
+
+// writeBlock writes a block with the provided body, whose length must be a
+// multiple of 4.
+func (w *Writer) writeBlock(blockType uint32, body []byte) error {
+	length := uint32(12 + len(body))
+	block := make([]byte, length)
+	byteOrder.PutUint32(block[0:], blockType)
+	byteOrder.PutUint32(block[4:], length)
+	copy(block[8:], body)
+	byteOrder.PutUint32(block[length-4:], length)
+	_, err := w.w.Write(block)
+	return err
+}
+
+// pad returns the length rounded up to a multiple of 4.
+func pad(length int) int {
+	return (length + 3) &^ 3
+}
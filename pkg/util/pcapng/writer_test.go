@@ -0,0 +1,90 @@
+// Copyright 2022 Antrea Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package pcapng
+
+import (
+	"bytes"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type block struct {
+	blockType uint32
+	body      []byte
+}
+
+// readBlocks parses the blocks of a pcapng file, and checks that the lengths
+// at the start and at the end of each block match.
+func readBlocks(t *testing.T, data []byte) []block {
+	var blocks []block
+	for len(data) > 0 {
+		require.GreaterOrEqual(t, len(data), 12)
+		length := int(byteOrder.Uint32(data[4:]))
+		require.Zero(t, length%4)
+		require.GreaterOrEqual(t, len(data), length)
+		assert.Equal(t, uint32(length), byteOrder.Uint32(data[length-4:]))
+		blocks = append(blocks, block{blockType: byteOrder.Uint32(data), body: data[8 : length-4]})
+		data = data[length:]
+	}
+	return blocks
+}
+
+func TestWriter(t *testing.T) {
+	var buf bytes.Buffer
+	w, err := NewWriter(&buf)
+	require.NoError(t, err)
+	ts := time.Unix(1660000000, 123456789)
+	packet1 := []byte{0, 0, 0, 0, 0, 1, 0, 0, 0, 0, 0, 2, 0x08, 0x00, 0x45}
+	packet2 := bytes.Repeat([]byte{0xff}, MaxSnapLength+10)
+	require.NoError(t, w.WritePacket(ts, packet1, len(packet1)))
+	require.NoError(t, w.WritePacket(ts.Add(time.Second), packet2, len(packet2)))
+
+	blocks := readBlocks(t, buf.Bytes())
+	require.Len(t, blocks, 4)
+
+	shb := blocks[0]
+	assert.Equal(t, blockTypeSectionHeader, shb.blockType)
+	assert.Equal(t, byteOrderMagic, byteOrder.Uint32(shb.body))
+	assert.Equal(t, uint16(1), byteOrder.Uint16(shb.body[4:]))
+
+	idb := blocks[1]
+	assert.Equal(t, blockTypeInterfaceDescription, idb.blockType)
+	assert.Equal(t, linkTypeEthernet, byteOrder.Uint16(idb.body))
+	assert.Equal(t, uint32(MaxSnapLength), byteOrder.Uint32(idb.body[4:]))
+
+	for i, tc := range []struct {
+		ts             time.Time
+		data           []byte
+		originalLength int
+	}{
+		{ts: ts, data: packet1, originalLength: len(packet1)},
+		// The packet larger than the snap length is truncated.
+		{ts: ts.Add(time.Second), data: packet2[:MaxSnapLength], originalLength: len(packet2)},
+	} {
+		epb := blocks[2+i]
+		assert.Equal(t, blockTypeEnhancedPacket, epb.blockType)
+		assert.Zero(t, byteOrder.Uint32(epb.body))
+		micros := int64(byteOrder.Uint32(epb.body[4:]))<<32 | int64(byteOrder.Uint32(epb.body[8:]))
+		assert.Equal(t, tc.ts.UnixMicro(), micros)
+		capturedLength := int(byteOrder.Uint32(epb.body[12:]))
+		assert.Equal(t, len(tc.data), capturedLength)
+		assert.Equal(t, tc.originalLength, int(byteOrder.Uint32(epb.body[16:])))
+		assert.Equal(t, tc.data, epb.body[20:20+capturedLength])
+		assert.Len(t, epb.body, 20+pad(capturedLength))
+	}
+}
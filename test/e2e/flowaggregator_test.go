@@ -968,6 +968,7 @@ func checkPodAndNodeDataClickHouse(data *TestData, t *testing.T, record *ClickHo
 	assert.Equal(record.SourcePodName, srcPod, "Record with srcIP does not have Pod name: %s", srcPod)
 	assert.Equal(record.SourcePodNamespace, data.testNamespace, "Record does not have correct sourcePodNamespace: %s", data.testNamespace)
 	assert.Equal(record.SourceNodeName, srcNode, "Record does not have correct sourceNodeName: %s", srcNode)
+	assert.Equal("Pod", record.SourceEndpointKind, "Record does not have correct sourceEndpointKind")
 	// For Pod-To-External flow type, we send traffic to an external address,
 	// so we skip the verification of destination Pod info.
 	// Also, source Pod labels are different for Pod-To-External flow test.
@@ -975,9 +976,11 @@ func checkPodAndNodeDataClickHouse(data *TestData, t *testing.T, record *ClickHo
 		assert.Equal(record.DestinationPodName, dstPod, "Record with dstIP does not have Pod name: %s", dstPod)
 		assert.Equal(record.DestinationPodNamespace, data.testNamespace, "Record does not have correct destinationPodNamespace: %s", data.testNamespace)
 		assert.Equal(record.DestinationNodeName, dstNode, "Record does not have correct destinationNodeName: %s", dstNode)
+		assert.Equal("Pod", record.DestinationEndpointKind, "Record does not have correct destinationEndpointKind")
 		assert.Equal(record.SourcePodLabels, fmt.Sprintf("{\"antrea-e2e\":\"%s\",\"app\":\"perftool\"}", srcPod), "Record does not have correct label for source Pod")
 		assert.Equal(record.DestinationPodLabels, fmt.Sprintf("{\"antrea-e2e\":\"%s\",\"app\":\"perftool\"}", dstPod), "Record does not have correct label for destination Pod")
 	} else {
+		assert.Equal("External", record.DestinationEndpointKind, "Record does not have correct destinationEndpointKind")
 		assert.Equal(record.SourcePodLabels, fmt.Sprintf("{\"antrea-e2e\":\"%s\",\"app\":\"busybox\"}", srcPod), "Record does not have correct label for source Pod")
 	}
 }
@@ -1454,5 +1457,14 @@ type ClickHouseFullRow struct {
 	ReverseThroughputFromSourceNode      uint64    `json:"reverseThroughputFromSourceNode,string"`
 	ReverseThroughputFromDestinationNode uint64    `json:"reverseThroughputFromDestinationNode,string"`
 	ClusterUUID                          string    `json:"clusterUUID"`
+	SourceEndpointKind                   string    `json:"sourceEndpointKind"`
+	DestinationEndpointKind              string    `json:"destinationEndpointKind"`
+	EgressName                           string    `json:"egressName"`
+	EgressIP                             string    `json:"egressIP"`
+	EgressNodeName                       string    `json:"egressNodeName"`
+	AppProtocolName                      string    `json:"appProtocolName"`
+	HttpVals                             string    `json:"httpVals"`
+	DNSQueryName                         string    `json:"dnsQueryName"`
+	DNSResponseCode                      uint8     `json:"dnsResponseCode"`
 	Trusted                              uint8     `json:"trusted"`
 }
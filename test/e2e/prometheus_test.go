@@ -49,6 +49,9 @@ var antreaAgentMetrics = []string{
 	"antrea_agent_conntrack_max_connection_count",
 	"antrea_agent_denied_connection_count",
 	"antrea_agent_flow_collector_reconnection_count",
+	"antrea_agent_flow_exporter_spool_dropped_record_count",
+	"antrea_agent_flow_exporter_spool_record_count",
+	"antrea_agent_flow_exporter_spool_size_bytes",
 	"antrea_proxy_sync_proxy_rules_duration_seconds",
 	"antrea_proxy_total_endpoints_installed",
 	"antrea_proxy_total_endpoints_updates",
@@ -608,7 +608,7 @@ func testTraceflowIntraNode(t *testing.T, data *TestData) {
 			ipVersion: 4,
 			tf: &v1alpha1.Traceflow{
 				ObjectMeta: metav1.ObjectMeta{
-					Name: randName(fmt.Sprintf("%s-%s-to-%s-%s-", antreaNamespace, agentPod, data.testNamespace, node1Pods[1])),
+					Name: randName(fmt.Sprintf("%s-%s-to-%s-%s-", antreaNamespace, agentPod, data.testNamespace, node1Pods[2])),
 				},
 				Spec: v1alpha1.TraceflowSpec{
 					Source: v1alpha1.Source{
@@ -617,12 +617,62 @@ func testTraceflowIntraNode(t *testing.T, data *TestData) {
 					},
 					Destination: v1alpha1.Destination{
 						Namespace: data.testNamespace,
-						Pod:       node1Pods[1],
+						Pod:       node1Pods[2],
+					},
+				},
+			},
+			expectedPhase: v1alpha1.Succeeded,
+			expectedResults: []v1alpha1.NodeResult{
+				{
+					Node: node1,
+					Observations: []v1alpha1.Observation{
+						{
+							Component: v1alpha1.ComponentSpoofGuard,
+							Action:    v1alpha1.ActionForwarded,
+						},
+						{
+							Component:     v1alpha1.ComponentForwarding,
+							ComponentInfo: "Output",
+							Action:        v1alpha1.ActionDelivered,
+						},
+					},
+				},
+			},
+		},
+		{
+			name:      "nodeSrcIPv4",
+			ipVersion: 4,
+			tf: &v1alpha1.Traceflow{
+				ObjectMeta: metav1.ObjectMeta{
+					Name: randName(fmt.Sprintf("%s-to-%s-%s-", node1, data.testNamespace, node1Pods[2])),
+				},
+				Spec: v1alpha1.TraceflowSpec{
+					Source: v1alpha1.Source{
+						Node: node1,
+					},
+					Destination: v1alpha1.Destination{
+						Namespace: data.testNamespace,
+						Pod:       node1Pods[2],
+					},
+				},
+			},
+			expectedPhase: v1alpha1.Succeeded,
+			expectedResults: []v1alpha1.NodeResult{
+				{
+					Node: node1,
+					Observations: []v1alpha1.Observation{
+						{
+							Component: v1alpha1.ComponentSpoofGuard,
+							Action:    v1alpha1.ActionForwarded,
+						},
+						{
+							Component:     v1alpha1.ComponentForwarding,
+							ComponentInfo: "Output",
+							Action:        v1alpha1.ActionDelivered,
+						},
 					},
 				},
 			},
-			expectedPhase:   v1alpha1.Failed,
-			expectedReasons: []string{fmt.Sprintf("Invalid Traceflow request, err: %+v", fmt.Errorf("using hostNetwork Pod as source in non-live-traffic Traceflow is not supported"))},
 		},
 		{
 			name:      "intraNodeICMPDstIPLiveTraceflowIPv4",
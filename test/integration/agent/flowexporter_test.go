@@ -23,9 +23,9 @@ import (
 	"testing"
 	"time"
 
-	mock "github.com/golang/mock/gomock"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
+	mock "go.uber.org/mock/gomock"
 
 	"antrea.io/antrea/pkg/agent/flowexporter"
 	"antrea.io/antrea/pkg/agent/flowexporter/connections"
@@ -700,7 +700,7 @@ func installServiceFlows(t *testing.T, gid uint32, svc svcConfig, endpointList [
 	assert.NoError(t, err, "no error should return when installing flows for Endpoints")
 	err = c.InstallServiceGroup(groupID, svc.withSessionAffinity, endpointList)
 	assert.NoError(t, err, "no error should return when installing groups for Service")
-	err = c.InstallServiceFlows(groupID, svc.ip, svc.port, svc.protocol, stickyMaxAgeSeconds, false, v1.ServiceTypeClusterIP)
+	err = c.InstallServiceFlows(groupID, svc.ip, svc.port, svc.protocol, stickyMaxAgeSeconds, types.DefaultSessionAffinityOptions, false, v1.ServiceTypeClusterIP)
 	assert.NoError(t, err, "no error should return when installing flows for Service")
 }
 
@@ -1615,12 +1615,12 @@ func prepareEgressMarkFlows(snatIP net.IP, mark, podOFPort, podOFPortRemote uint
 			"EgressMark",
 			[]*ofTestUtils.ExpectFlow{
 				{
-					MatchStr: fmt.Sprintf("priority=200,ct_state=+new+trk,%s,%s=%s", ipProtoStr, tunDstFieldName, snatIP),
-					ActStr:   fmt.Sprintf("set_field:0x%x/0xff->pkt_mark,set_field:0x20/0xf0->reg0,goto_table:L2ForwardingCalc", mark),
+					MatchStr: fmt.Sprintf("priority=200,ct_state=+trk,%s,%s=%s", ipProtoStr, tunDstFieldName, snatIP),
+					ActStr:   fmt.Sprintf("set_field:0x%x/0xff->pkt_mark,set_field:0x20/0xf0->reg0,goto_table:EgressQoS", mark),
 				},
 				{
-					MatchStr: fmt.Sprintf("priority=200,ct_state=+new+trk,%s,in_port=%d", ipProtoStr, podOFPort),
-					ActStr:   fmt.Sprintf("set_field:0x%x/0xff->pkt_mark,set_field:0x20/0xf0->reg0,goto_table:L2ForwardingCalc", mark),
+					MatchStr: fmt.Sprintf("priority=200,ct_state=+trk,%s,in_port=%d", ipProtoStr, podOFPort),
+					ActStr:   fmt.Sprintf("set_field:0x%x/0xff->pkt_mark,set_field:0x20/0xf0->reg0,goto_table:EgressQoS", mark),
 				},
 				{
 					MatchStr: fmt.Sprintf("priority=200,%s,in_port=%d", ipProtoStr, podOFPortRemote),
@@ -1628,6 +1628,15 @@ func prepareEgressMarkFlows(snatIP net.IP, mark, podOFPort, podOFPortRemote uint
 				},
 			},
 		},
+		{
+			"EgressQoS",
+			[]*ofTestUtils.ExpectFlow{
+				{
+					MatchStr: fmt.Sprintf("priority=200,pkt_mark=0x%x/0xff", mark),
+					ActStr:   fmt.Sprintf("meter:%d,goto_table:L2ForwardingCalc", mark),
+				},
+			},
+		},
 	}
 }
 
@@ -1723,6 +1732,8 @@ func TestEgressMarkFlows(t *testing.T) {
 	c.InstallPodSNATFlows(podOFPortRemote, snatIP, 0)
 	c.InstallPodSNATFlows(podOFPortV6, snatIPV6, snatMarkV6)
 	c.InstallPodSNATFlows(podOFPortRemoteV6, snatIPV6, 0)
+	c.InstallEgressQoS(snatMark, 10000, 20000)
+	c.InstallEgressQoS(snatMarkV6, 10000, 20000)
 	for _, tableFlow := range expectedFlows {
 		ofTestUtils.CheckFlowExists(t, ovsCtlClient, tableFlow.tableName, 0, true, tableFlow.flows)
 	}
@@ -1733,6 +1744,8 @@ func TestEgressMarkFlows(t *testing.T) {
 	c.UninstallPodSNATFlows(podOFPortRemoteV6)
 	c.UninstallSNATMarkFlows(snatMark)
 	c.UninstallSNATMarkFlows(snatMarkV6)
+	c.UninstallEgressQoS(snatMark)
+	c.UninstallEgressQoS(snatMarkV6)
 	for _, tableFlow := range expectedFlows {
 		ofTestUtils.CheckFlowExists(t, ovsCtlClient, tableFlow.tableName, 0, false, tableFlow.flows)
 	}